@@ -0,0 +1,187 @@
+// Package bloom provides a standalone Bloom filter with configurable false positive rate.
+//
+// The filter can be saved to and loaded from files by the lcache serializers, and
+// adapted to lcache.MissFilter for the miss filter of lcache.Cache.
+//
+// Usage:
+//
+//	f := bloom.New(100000, 0.01)
+//	f.AddString("user:1001")
+//	f.TestString("user:1001") // true
+//	f.TestString("user:1002") // false, or true at the rate 0.01
+//
+//	// remember the missing keys of the cache across restarts
+//	_ = f.LoadFile("miss.bloom")
+//	c := lcache.New(lcache.WithCustomMissFilter(f.AsMissFilter()))
+//	defer f.SaveFile("miss.bloom")
+package bloom
+
+import (
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/gookit/ext/internal/bloombits"
+)
+
+// ErrIncompatible the filters have different size or hash functions, can not be merged.
+var ErrIncompatible = errors.New("bloom: incompatible filters")
+
+// Options for the filter
+type Options struct {
+	// Serializer name of the registered lcache serializer for SaveFile and LoadFile. default is "json"
+	Serializer string
+	// SaveSync call fsync on the file before rename it on SaveFile
+	SaveSync bool
+}
+
+// OptionFn option func for New
+type OptionFn func(o *Options)
+
+// WithSerializer set the serializer for SaveFile and LoadFile, it must be registered
+// in lcache. see lcache.SetSerializer
+func WithSerializer(name string) OptionFn {
+	return func(o *Options) { o.Serializer = name }
+}
+
+// WithSaveSync set whether to fsync the file on SaveFile, then the saved file
+// is complete after a system crash.
+func WithSaveSync(sync bool) OptionFn {
+	return func(o *Options) { o.SaveSync = sync }
+}
+
+// Filter a Bloom filter, it is goroutine-safe.
+//
+// Test returns false if the data is definitely not added, true if it is probably added.
+type Filter struct {
+	opt Options
+	mu  sync.RWMutex
+	// capacity 预期的数据数量; m 位数; k hash 函数数量; count 已添加的数量
+	capacity int
+	m, k     uint64
+	count    int
+	bits     bloombits.Bits
+}
+
+// New create a filter for the expected number of the elements n, with the false positive
+// rate fpRate. Panics if n <= 0 or fpRate not in range (0, 1).
+func New(n int, fpRate float64, optFns ...OptionFn) *Filter {
+	if n <= 0 {
+		panic("bloom: expected number of elements must be greater than 0")
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		panic("bloom: false positive rate must be in range (0, 1)")
+	}
+
+	m, k := Estimate(n, fpRate)
+	f := &Filter{
+		opt:      Options{Serializer: "json"},
+		capacity: n,
+		m:        m,
+		k:        k,
+		bits:     bloombits.Make(m),
+	}
+	for _, fn := range optFns {
+		fn(&f.opt)
+	}
+	return f
+}
+
+// Estimate the number of bits m and hash functions k for n elements with the false positive rate.
+func Estimate(n int, fpRate float64) (m, k uint64) {
+	return bloombits.Estimate(n, fpRate)
+}
+
+// Add the data to the filter
+func (f *Filter) Add(data []byte) {
+	h1, h2 := bloombits.Hash(data)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.add(h1, h2)
+}
+
+// add 设置 hash 对应的位. 需持有锁
+func (f *Filter) add(h1, h2 uint64) {
+	f.bits.Set(f.m, f.k, h1, h2)
+	f.count++
+}
+
+// AddString add the string to the filter
+func (f *Filter) AddString(s string) {
+	h1, h2 := bloombits.Hash(s)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.add(h1, h2)
+}
+
+// Test check the data is probably added, false means it is definitely not added.
+func (f *Filter) Test(data []byte) bool {
+	h1, h2 := bloombits.Hash(data)
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.bits.Test(f.m, f.k, h1, h2)
+}
+
+// TestString check the string is probably added
+func (f *Filter) TestString(s string) bool {
+	h1, h2 := bloombits.Hash(s)
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.bits.Test(f.m, f.k, h1, h2)
+}
+
+// Merge add all elements of other to the filter. Returns ErrIncompatible if the filters
+// have different number of bits or hash functions, eg: not created with the same n and fpRate.
+func (f *Filter) Merge(other *Filter) error {
+	other.mu.RLock()
+	m, k, count := other.m, other.k, other.count
+	bits := make([]uint64, len(other.bits))
+	copy(bits, other.bits)
+	other.mu.RUnlock()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if m != f.m || k != f.k {
+		return ErrIncompatible
+	}
+
+	for i, b := range bits {
+		f.bits[i] |= b
+	}
+	f.count += count
+	return nil
+}
+
+// Count get the number of the added elements, includes the duplicates.
+func (f *Filter) Count() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.count
+}
+
+// Cap get the expected number of elements the filter created for
+func (f *Filter) Cap() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.capacity
+}
+
+// FPRate estimate the current false positive rate by the number of added elements.
+// It exceeds the configured rate after more than Cap elements are added.
+func (f *Filter) FPRate() float64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return math.Pow(1-math.Exp(-float64(f.k)*float64(f.count)/float64(f.m)), float64(f.k))
+}
+
+// Reset remove all elements
+func (f *Filter) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	clear(f.bits)
+	f.count = 0
+}
@@ -0,0 +1,179 @@
+package bloom_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/bloom"
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestFilter_AddTest(t *testing.T) {
+	f := bloom.New(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.AddString("key" + strconv.Itoa(i))
+	}
+	assert.Eq(t, 1000, f.Count())
+	assert.Eq(t, 1000, f.Cap())
+
+	// no false negatives
+	for i := 0; i < 1000; i++ {
+		assert.True(t, f.TestString("key"+strconv.Itoa(i)))
+	}
+	assert.True(t, f.Test([]byte("key1")))
+
+	// false positives near the configured rate
+	var fp int
+	for i := 0; i < 10000; i++ {
+		if f.TestString("other" + strconv.Itoa(i)) {
+			fp++
+		}
+	}
+	assert.Lt(t, fp, 300)
+	assert.Lt(t, f.FPRate(), 0.02)
+
+	f.Reset()
+	assert.Eq(t, 0, f.Count())
+	assert.False(t, f.TestString("key1"))
+	assert.Eq(t, float64(0), f.FPRate())
+
+	assert.Panics(t, func() { bloom.New(0, 0.01) })
+	assert.Panics(t, func() { bloom.New(10, 1) })
+}
+
+func TestEstimate(t *testing.T) {
+	m, k := bloom.Estimate(1000, 0.01)
+	assert.Eq(t, uint64(9586), m)
+	assert.Eq(t, uint64(7), k)
+
+	m, k = bloom.Estimate(1, 0.5)
+	assert.Eq(t, uint64(64), m)
+	assert.Gt(t, k, uint64(0))
+}
+
+func TestFilter_Merge(t *testing.T) {
+	f1 := bloom.New(100, 0.01)
+	f2 := bloom.New(100, 0.01)
+	f1.AddString("a")
+	f2.AddString("b")
+
+	assert.NoErr(t, f1.Merge(f2))
+	assert.True(t, f1.TestString("a"))
+	assert.True(t, f1.TestString("b"))
+	assert.Eq(t, 2, f1.Count())
+	assert.False(t, f2.TestString("a"))
+
+	assert.ErrIs(t, f1.Merge(bloom.New(200, 0.01)), bloom.ErrIncompatible)
+	assert.ErrIs(t, f1.Merge(bloom.New(100, 0.001)), bloom.ErrIncompatible)
+}
+
+func TestFilter_SaveLoadFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.bloom")
+
+	f := bloom.New(100, 0.01)
+	f.AddString("a")
+	f.AddString("b")
+	assert.NoErr(t, f.SaveFile(file))
+
+	// replaces the size and elements
+	f2 := bloom.New(10, 0.1)
+	f2.AddString("c")
+	assert.NoErr(t, f2.LoadFile(file))
+	assert.True(t, f2.TestString("a"))
+	assert.True(t, f2.TestString("b"))
+	assert.False(t, f2.TestString("c"))
+	assert.Eq(t, 2, f2.Count())
+	assert.Eq(t, 100, f2.Cap())
+	assert.NoErr(t, f2.Merge(f))
+
+	// gob serializer
+	gf := bloom.New(100, 0.01, bloom.WithSerializer("gob"))
+	gf.AddString("a")
+	assert.NoErr(t, gf.SaveFile(file))
+	gf2 := bloom.New(100, 0.01, bloom.WithSerializer("gob"))
+	assert.NoErr(t, gf2.LoadFile(file))
+	assert.True(t, gf2.TestString("a"))
+
+	// errors
+	assert.Err(t, f.LoadFile(file+".notexist"))
+	// the gob data
+	assert.Err(t, f.LoadFile(file))
+	assert.NoErr(t, os.WriteFile(file, []byte(`{"m": 64}`), 0644))
+	assert.ErrMsgContains(t, f.LoadFile(file), "invalid filter data")
+	bad := bloom.New(100, 0.01, bloom.WithSerializer("notexist"))
+	assert.ErrMsgContains(t, bad.SaveFile(file), "not registered serializer")
+	assert.ErrMsgContains(t, bad.LoadFile(file), "not registered serializer")
+}
+
+func TestFilter_concurrent(t *testing.T) {
+	f := bloom.New(10000, 0.01)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := strconv.Itoa(g) + ":" + strconv.Itoa(i)
+				f.AddString(key)
+				f.TestString(key)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	assert.Eq(t, 4000, f.Count())
+	for g := 0; g < 8; g++ {
+		assert.True(t, f.TestString(strconv.Itoa(g)+":499"))
+	}
+}
+
+func TestMissFilter(t *testing.T) {
+	f := bloom.New(3, 0.01)
+	mf := f.AsMissFilter()
+	assert.Eq(t, f, mf.Filter())
+
+	mf.Add("a")
+	mf.Add("b")
+	mf.Add("c")
+	assert.True(t, mf.Test("a"))
+	assert.Eq(t, 3, f.Count())
+
+	// reset on full
+	mf.Add("d")
+	assert.True(t, mf.Test("d"))
+	assert.Eq(t, 1, f.Count())
+
+	mf.Reset()
+	assert.False(t, mf.Test("d"))
+}
+
+func TestMissFilter_lcache(t *testing.T) {
+	var calls int32
+	f := bloom.New(100, 0.01)
+	c := lcache.New(
+		lcache.WithCustomMissFilter(f.AsMissFilter()),
+		lcache.WithLoader(func(ctx context.Context, key string) (any, time.Duration, error) {
+			atomic.AddInt32(&calls, 1)
+			return nil, 0, lcache.ErrNotFound
+		}),
+	)
+
+	for i := 0; i < 3; i++ {
+		_, ok := c.Get("garbage")
+		assert.False(t, ok)
+	}
+	assert.Eq(t, int32(1), atomic.LoadInt32(&calls))
+	assert.True(t, f.TestString("garbage"))
+
+	c.ResetMissFilter()
+	c.Get("garbage")
+	assert.Eq(t, int32(2), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,40 @@
+package bloom
+
+import "github.com/gookit/ext/internal/bloombits"
+
+// MissFilter adapts a Filter to lcache.MissFilter, for the lcache.WithCustomMissFilter.
+//
+// The filter is reset when the number of the added keys reaches its Cap, to keep
+// the false positive rate. Remembered keys are lost on reset.
+type MissFilter struct {
+	f *Filter
+}
+
+// AsMissFilter adapt the filter to lcache.MissFilter
+func (f *Filter) AsMissFilter() *MissFilter {
+	return &MissFilter{f: f}
+}
+
+// Add record a missing key
+func (mf *MissFilter) Add(key string) {
+	f := mf.f
+	h1, h2 := bloombits.Hash(key)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// 已满时清空，避免误判率持续升高
+	if f.count >= f.capacity {
+		clear(f.bits)
+		f.count = 0
+	}
+	f.add(h1, h2)
+}
+
+// Test check the key may be missing
+func (mf *MissFilter) Test(key string) bool { return mf.f.TestString(key) }
+
+// Reset clear the recorded keys
+func (mf *MissFilter) Reset() { mf.f.Reset() }
+
+// Filter get the underlying filter
+func (mf *MissFilter) Filter() *Filter { return mf.f }
@@ -0,0 +1,67 @@
+package bloom
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/x/stdio"
+)
+
+// snapshot 持久化到文件的数据结构
+type snapshot struct {
+	Cap   int      `json:"cap"`
+	M     uint64   `json:"m"`
+	K     uint64   `json:"k"`
+	Count int      `json:"count"`
+	Bits  []uint64 `json:"bits"`
+}
+
+// SaveFile save the filter to a file.
+//
+// It writes through lcache.FileProvider, see WithSaveSync for the durability.
+func (f *Filter) SaveFile(filename string) error {
+	s, err := lcache.LookupSerializer(f.opt.Serializer)
+	if err != nil {
+		return err
+	}
+
+	f.mu.RLock()
+	data := &snapshot{Cap: f.capacity, M: f.m, K: f.k, Count: f.count, Bits: make([]uint64, len(f.bits))}
+	copy(data.Bits, f.bits)
+	f.mu.RUnlock()
+
+	return lcache.FileProvider{Sync: f.opt.SaveSync}.Snapshot(filename, func(w io.Writer) error {
+		return s.EncodeTo(w, data)
+	})
+}
+
+// LoadFile load the filter from a file saved by SaveFile, it replaces the current
+// elements, size and hash functions of the filter.
+func (f *Filter) LoadFile(filename string) error {
+	s, err := lcache.LookupSerializer(f.opt.Serializer)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer stdio.SafeClose(file)
+
+	var data snapshot
+	if err = s.DecodeFrom(file, &data); err != nil {
+		return err
+	}
+	if data.Cap <= 0 || data.M == 0 || data.K == 0 || uint64(len(data.Bits)) != (data.M+63)/64 {
+		return errors.New("bloom: invalid filter data in file: " + filename)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.capacity, f.m, f.k = data.Cap, data.M, data.K
+	f.count, f.bits = data.Count, data.Bits
+	return nil
+}
@@ -0,0 +1,270 @@
+package circuit
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// 窗口中记录的调用结果标记
+const (
+	resultFailed uint8 = 1 << iota
+	resultSlow
+)
+
+// Counts the calls in the window of a closed breaker
+type Counts struct {
+	Calls     int
+	Failures  int
+	SlowCalls int
+}
+
+// transition 状态变更，解锁后回调
+type transition struct{ from, to State }
+
+// Breaker a circuit breaker, it is goroutine-safe.
+type Breaker struct {
+	name string
+	opt  *Options
+
+	mu    sync.Mutex
+	state State
+	// gen 状态变更时递增，忽略旧状态下开始的调用结果
+	gen       uint64
+	openUntil time.Time
+	// ring 最近调用结果的环形窗口
+	ring   []uint8
+	pos    int
+	counts Counts
+	// halfCalls 半开状态已允许的调用数, halfOK 成功数
+	halfCalls, halfOK int
+	// pending 待回调的状态变更
+	pending []transition
+}
+
+// New create a breaker
+func New(name string, optFns ...OptionFn) *Breaker {
+	return newBreaker(name, newOptions(optFns))
+}
+
+func newBreaker(name string, opt *Options) *Breaker {
+	return &Breaker{name: name, opt: opt, ring: make([]uint8, 0, opt.WindowSize)}
+}
+
+// Name get the breaker name
+func (b *Breaker) Name() string { return b.name }
+
+// State get the current state
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.unlock()
+	return b.current(time.Now())
+}
+
+// Counts get the calls in the window
+func (b *Breaker) Counts() Counts {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.counts
+}
+
+// Do run fn if the breaker permits, and record the result. Returns ErrOpen or
+// ErrTooManyCalls if rejected. A panic of fn is recorded as a failure and re-panics.
+func (b *Breaker) Do(fn func() error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+
+	ok := false
+	defer func() {
+		if !ok {
+			done(errPanic)
+		}
+	}()
+
+	err = fn()
+	ok = true
+	done(err)
+	return err
+}
+
+// errPanic 记录 panic 为失败
+var errPanic = errors.New("circuit: call panicked")
+
+// Call run fn by the breaker and returns its result. see Breaker.Do
+func Call[T any](b *Breaker, fn func() (T, error)) (val T, err error) {
+	err = b.Do(func() error {
+		val, err = fn()
+		return err
+	})
+	return val, err
+}
+
+// Allow check the breaker permits a call, the caller must call done with the result
+// of the call. Use it when the call can not wrap in a func.
+//
+//	done, err := b.Allow()
+//	if err != nil {
+//		return err
+//	}
+//	err = call()
+//	done(err)
+func (b *Breaker) Allow() (done func(err error), err error) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	start := time.Now()
+	switch b.current(start) {
+	case StateOpen:
+		return nil, ErrOpen
+	case StateHalfOpen:
+		if b.halfCalls >= b.opt.HalfOpenCalls {
+			return nil, ErrTooManyCalls
+		}
+		b.halfCalls++
+	}
+
+	gen := b.gen
+	return func(err error) { b.record(gen, start, err) }, nil
+}
+
+// record 记录调用结果并更新状态
+func (b *Breaker) record(gen uint64, start time.Time, err error) {
+	now := time.Now()
+	var result uint8
+	if err == errPanic || b.opt.IsFailure(err) {
+		result |= resultFailed
+	}
+	if b.opt.SlowCallDuration > 0 && now.Sub(start) >= b.opt.SlowCallDuration {
+		result |= resultSlow
+	}
+
+	b.mu.Lock()
+	defer b.unlock()
+	if gen != b.gen {
+		return
+	}
+
+	if b.state == StateHalfOpen {
+		if result != 0 {
+			b.open(now)
+		} else if b.halfOK++; b.halfOK >= b.opt.HalfOpenCalls {
+			b.setState(StateClosed, now)
+		}
+		return
+	}
+
+	b.add(result)
+	n := b.counts.Calls
+	if n < b.opt.MinCalls {
+		return
+	}
+	if float64(b.counts.Failures)/float64(n) >= b.opt.FailureRate ||
+		(b.opt.SlowCallDuration > 0 && float64(b.counts.SlowCalls)/float64(n) >= b.opt.SlowCallRate) {
+		b.open(now)
+	}
+}
+
+// add 添加结果到窗口，移出最旧的结果
+func (b *Breaker) add(result uint8) {
+	if len(b.ring) < cap(b.ring) {
+		b.ring = append(b.ring, result)
+		b.counts.Calls++
+	} else {
+		b.count(b.ring[b.pos], -1)
+		b.ring[b.pos] = result
+		b.pos = (b.pos + 1) % len(b.ring)
+	}
+	b.count(result, 1)
+}
+
+func (b *Breaker) count(result uint8, delta int) {
+	if result&resultFailed != 0 {
+		b.counts.Failures += delta
+	}
+	if result&resultSlow != 0 {
+		b.counts.SlowCalls += delta
+	}
+}
+
+// Reset the breaker to closed state, clear the window and the shared state.
+func (b *Breaker) Reset() {
+	b.mu.Lock()
+	defer b.unlock()
+	b.setState(StateClosed, time.Now())
+}
+
+// current 获取当前状态. 打开超时后转为半开; 关闭状态下检查共享的打开状态
+func (b *Breaker) current(now time.Time) State {
+	switch b.state {
+	case StateOpen:
+		if now.Before(b.openUntil) {
+			break
+		}
+		if ttl, ok := b.sharedTTL(); ok {
+			b.openUntil = now.Add(ttl)
+			break
+		}
+		b.setState(StateHalfOpen, now)
+	case StateClosed:
+		if ttl, ok := b.sharedTTL(); ok {
+			b.setState(StateOpen, now)
+			b.openUntil = now.Add(ttl)
+		}
+	}
+	return b.state
+}
+
+// sharedTTL 获取共享的打开状态的剩余时间
+func (b *Breaker) sharedTTL() (time.Duration, bool) {
+	if b.opt.Cache == nil {
+		return 0, false
+	}
+	ttl, ok := b.opt.Cache.TTL(b.opt.KeyPrefix + b.name)
+	if ok && ttl <= 0 {
+		ttl = b.opt.OpenTimeout
+	}
+	return ttl, ok
+}
+
+// open 打开熔断器，并设置共享的打开状态
+func (b *Breaker) open(now time.Time) {
+	b.setState(StateOpen, now)
+	if b.opt.Cache != nil {
+		b.opt.Cache.Set(b.opt.KeyPrefix+b.name, now.UnixMilli(), b.opt.OpenTimeout)
+	}
+}
+
+// setState 变更状态. 需持有锁
+func (b *Breaker) setState(to State, now time.Time) {
+	from := b.state
+	b.state = to
+	b.gen++
+	b.halfCalls, b.halfOK = 0, 0
+
+	switch to {
+	case StateOpen:
+		b.openUntil = now.Add(b.opt.OpenTimeout)
+	case StateClosed:
+		b.ring, b.pos, b.counts = b.ring[:0], 0, Counts{}
+		if b.opt.Cache != nil {
+			b.opt.Cache.Delete(b.opt.KeyPrefix + b.name)
+		}
+	}
+
+	if from != to && b.opt.OnStateChange != nil {
+		b.pending = append(b.pending, transition{from: from, to: to})
+	}
+}
+
+// unlock 解锁并执行状态变更回调
+func (b *Breaker) unlock() {
+	pending := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	for _, t := range pending {
+		b.opt.OnStateChange(b.name, t.from, t.to)
+	}
+}
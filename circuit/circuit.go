@@ -0,0 +1,156 @@
+// Package circuit provides the circuit breakers for protecting the callers of the
+// flaky backends, with failure-rate and slow-call thresholds.
+//
+// A breaker is closed at first, it opens when the failure rate or the slow call rate
+// of the recent calls reaches the threshold, and all calls are rejected with ErrOpen.
+// After the open timeout, it turns half-open and permits a few trial calls: closed
+// again if all succeed, otherwise opened again.
+//
+// Usage:
+//
+//	g := circuit.NewGroup(circuit.WithFailureRate(0.5), circuit.WithOpenTimeout(10*time.Second))
+//	err := g.Do("user-api", func() error {
+//		return callUserAPI()
+//	})
+//	if errors.Is(err, circuit.ErrOpen) {
+//		// fallback
+//	}
+//
+// The open state can be shared by the breakers with the same name via a lcache.Cache,
+// eg: a cache synced between instances. see WithSharedCache
+package circuit
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+)
+
+var (
+	// ErrOpen the breaker is open, the call is rejected
+	ErrOpen = errors.New("circuit: breaker is open")
+	// ErrTooManyCalls the trial calls of the half-open breaker are exhausted
+	ErrTooManyCalls = errors.New("circuit: too many calls in half-open state")
+)
+
+// State of the breaker
+type State uint8
+
+const (
+	// StateClosed the calls are permitted, the results are recorded.
+	StateClosed State = iota
+	// StateOpen the calls are rejected until the open timeout.
+	StateOpen
+	// StateHalfOpen a limited number of trial calls are permitted.
+	StateHalfOpen
+)
+
+var stateNames = []string{"closed", "open", "half-open"}
+
+// String get state name
+func (s State) String() string {
+	if int(s) < len(stateNames) {
+		return stateNames[s]
+	}
+	return fmt.Sprintf("State(%d)", s)
+}
+
+// Options for the breakers
+type Options struct {
+	// WindowSize the number of the recent calls to calculate the rates. default is 20
+	WindowSize int
+	// MinCalls the minimum number of calls before calculating the rates. default is 10
+	MinCalls int
+	// FailureRate the breaker opens when the failure rate >= it. default is 0.5
+	FailureRate float64
+	// SlowCallDuration the calls take longer than it are slow calls, 0 to disable.
+	SlowCallDuration time.Duration
+	// SlowCallRate the breaker opens when the slow call rate >= it. default is 0.5
+	SlowCallRate float64
+	// OpenTimeout the duration of the open state before half-open. default is 30s
+	OpenTimeout time.Duration
+	// HalfOpenCalls the number of the trial calls in half-open state. default is 3
+	HalfOpenCalls int
+	// IsFailure check the error is a failure. default is err != nil
+	IsFailure func(err error) bool
+	// OnStateChange callback on the state of a breaker changed
+	OnStateChange func(name string, from, to State)
+	// Cache share the open state of the breakers by the cache. see WithSharedCache
+	Cache *lcache.Cache
+	// KeyPrefix the key prefix of the shared state in the cache. default is "circuit:"
+	KeyPrefix string
+}
+
+// OptionFn option func for New and NewGroup
+type OptionFn func(o *Options)
+
+// WithWindow set the window size and the minimum number of calls to calculate the rates
+func WithWindow(size, minCalls int) OptionFn {
+	return func(o *Options) { o.WindowSize, o.MinCalls = size, minCalls }
+}
+
+// WithFailureRate set the failure rate threshold, in (0, 1]
+func WithFailureRate(rate float64) OptionFn {
+	return func(o *Options) { o.FailureRate = rate }
+}
+
+// WithSlowCall set the slow call duration and rate threshold
+func WithSlowCall(d time.Duration, rate float64) OptionFn {
+	return func(o *Options) { o.SlowCallDuration, o.SlowCallRate = d, rate }
+}
+
+// WithOpenTimeout set the duration of the open state before half-open
+func WithOpenTimeout(d time.Duration) OptionFn {
+	return func(o *Options) { o.OpenTimeout = d }
+}
+
+// WithHalfOpenCalls set the number of the trial calls in half-open state
+func WithHalfOpenCalls(n int) OptionFn {
+	return func(o *Options) { o.HalfOpenCalls = n }
+}
+
+// WithIsFailure set the func to check the error is a failure. eg: ignore the not found errors
+func WithIsFailure(fn func(err error) bool) OptionFn {
+	return func(o *Options) { o.IsFailure = fn }
+}
+
+// WithOnStateChange set the callback on the state of a breaker changed.
+// It is called after the breaker is unlocked, can call the breaker methods.
+func WithOnStateChange(fn func(name string, from, to State)) OptionFn {
+	return func(o *Options) { o.OnStateChange = fn }
+}
+
+// WithSharedCache share the open state of the breakers by the cache.
+//
+// When a breaker opens, the key "<KeyPrefix><name>" is set with the open timeout as TTL.
+// The breakers with the same name opens when found the key exists, eg: in other groups
+// or other instances syncing the cache.
+func WithSharedCache(c *lcache.Cache) OptionFn {
+	return func(o *Options) { o.Cache = c }
+}
+
+// newOptions 创建选项并设置默认值
+func newOptions(optFns []OptionFn) *Options {
+	opt := &Options{
+		WindowSize:    20,
+		MinCalls:      10,
+		FailureRate:   0.5,
+		SlowCallRate:  0.5,
+		OpenTimeout:   30 * time.Second,
+		HalfOpenCalls: 3,
+		KeyPrefix:     "circuit:",
+	}
+	for _, fn := range optFns {
+		fn(opt)
+	}
+
+	opt.WindowSize = max(opt.WindowSize, 1)
+	opt.MinCalls = min(max(opt.MinCalls, 1), opt.WindowSize)
+	opt.HalfOpenCalls = max(opt.HalfOpenCalls, 1)
+	if opt.IsFailure == nil {
+		opt.IsFailure = func(err error) bool { return err != nil }
+	}
+	return opt
+}
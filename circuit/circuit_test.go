@@ -0,0 +1,151 @@
+package circuit_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/circuit"
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+var errFail = errors.New("fail")
+
+func TestBreaker_states(t *testing.T) {
+	var mu sync.Mutex
+	var changes []string
+	b := circuit.New("api", circuit.WithWindow(10, 4), circuit.WithFailureRate(0.5),
+		circuit.WithOpenTimeout(20*time.Millisecond), circuit.WithHalfOpenCalls(2),
+		circuit.WithOnStateChange(func(name string, from, to circuit.State) {
+			mu.Lock()
+			changes = append(changes, name+":"+from.String()+"->"+to.String())
+			mu.Unlock()
+		}))
+	assert.Eq(t, "api", b.Name())
+	assert.Eq(t, circuit.StateClosed, b.State())
+
+	// not enough calls
+	assert.ErrIs(t, b.Do(func() error { return errFail }), errFail)
+	assert.ErrIs(t, b.Do(func() error { return errFail }), errFail)
+	assert.NoErr(t, b.Do(func() error { return nil }))
+	assert.Eq(t, circuit.StateClosed, b.State())
+	assert.Eq(t, circuit.Counts{Calls: 3, Failures: 2}, b.Counts())
+
+	// failure rate 3/4 >= 0.5
+	assert.Panics(t, func() {
+		_ = b.Do(func() error { panic("oops") })
+	})
+	assert.Eq(t, circuit.StateOpen, b.State())
+	assert.ErrIs(t, b.Do(func() error { return nil }), circuit.ErrOpen)
+
+	// half-open, a failed trial opens again
+	time.Sleep(25 * time.Millisecond)
+	assert.Eq(t, circuit.StateHalfOpen, b.State())
+	assert.ErrIs(t, b.Do(func() error { return errFail }), errFail)
+	assert.Eq(t, circuit.StateOpen, b.State())
+
+	// half-open, the trial calls are limited
+	time.Sleep(25 * time.Millisecond)
+	done1, err := b.Allow()
+	assert.NoErr(t, err)
+	done2, err := b.Allow()
+	assert.NoErr(t, err)
+	_, err = b.Allow()
+	assert.ErrIs(t, err, circuit.ErrTooManyCalls)
+	done1(nil)
+	assert.Eq(t, circuit.StateHalfOpen, b.State())
+	done2(nil)
+	assert.Eq(t, circuit.StateClosed, b.State())
+	assert.Eq(t, circuit.Counts{}, b.Counts())
+
+	mu.Lock()
+	assert.Eq(t, []string{
+		"api:closed->open", "api:open->half-open", "api:half-open->open",
+		"api:open->half-open", "api:half-open->closed",
+	}, changes)
+	mu.Unlock()
+}
+
+func TestBreaker_window(t *testing.T) {
+	b := circuit.New("db", circuit.WithWindow(4, 4), circuit.WithFailureRate(0.75),
+		circuit.WithIsFailure(func(err error) bool {
+			return err != nil && !errors.Is(err, lcache.ErrNotFound)
+		}))
+
+	// ignored errors are not failures
+	for i := 0; i < 4; i++ {
+		assert.ErrIs(t, b.Do(func() error { return lcache.ErrNotFound }), lcache.ErrNotFound)
+	}
+	assert.Eq(t, circuit.Counts{Calls: 4}, b.Counts())
+
+	// the oldest results are dropped from the window
+	for i := 0; i < 2; i++ {
+		_ = b.Do(func() error { return errFail })
+	}
+	assert.Eq(t, circuit.Counts{Calls: 4, Failures: 2}, b.Counts())
+	assert.Eq(t, circuit.StateClosed, b.State())
+	_ = b.Do(func() error { return errFail })
+	assert.Eq(t, circuit.StateOpen, b.State())
+
+	// the results started before state changed are ignored
+	b.Reset()
+	done, err := b.Allow()
+	assert.NoErr(t, err)
+	b.Reset()
+	done(errFail)
+	assert.Eq(t, circuit.Counts{}, b.Counts())
+
+	// generic call
+	val, err := circuit.Call(b, func() (int, error) { return 23, nil })
+	assert.NoErr(t, err)
+	assert.Eq(t, 23, val)
+}
+
+func TestBreaker_slowCall(t *testing.T) {
+	b := circuit.New("slow", circuit.WithWindow(2, 2), circuit.WithSlowCall(5*time.Millisecond, 1))
+
+	slow := func() error {
+		time.Sleep(6 * time.Millisecond)
+		return nil
+	}
+	assert.NoErr(t, b.Do(slow))
+	assert.NoErr(t, b.Do(func() error { return nil }))
+	assert.Eq(t, circuit.Counts{Calls: 2, SlowCalls: 1}, b.Counts())
+	assert.Eq(t, circuit.StateClosed, b.State())
+
+	assert.NoErr(t, b.Do(slow))
+	assert.NoErr(t, b.Do(slow))
+	assert.Eq(t, circuit.StateOpen, b.State())
+}
+
+func TestGroup(t *testing.T) {
+	c := lcache.New()
+	opts := []circuit.OptionFn{circuit.WithWindow(2, 2), circuit.WithOpenTimeout(30 * time.Millisecond),
+		circuit.WithHalfOpenCalls(1), circuit.WithSharedCache(c)}
+	g1 := circuit.NewGroup(opts...)
+	g2 := circuit.NewGroup(opts...)
+
+	assert.Eq(t, circuit.StateClosed, g2.Get("api").State())
+	for i := 0; i < 2; i++ {
+		_ = g1.Do("api", func() error { return errFail })
+	}
+	assert.NoErr(t, g1.Do("other", func() error { return nil }))
+	assert.Eq(t, []string{"api", "other"}, g1.Names())
+	assert.Eq(t, map[string]circuit.State{"api": circuit.StateOpen, "other": circuit.StateClosed}, g1.States())
+	assert.True(t, c.Has("circuit:api"))
+
+	// the open state is shared by the cache
+	assert.ErrIs(t, g2.Do("api", func() error { return nil }), circuit.ErrOpen)
+	assert.Eq(t, circuit.StateOpen, g2.Get("api").State())
+
+	// closed by a successful trial, the shared state is removed
+	time.Sleep(35 * time.Millisecond)
+	assert.NoErr(t, g2.Do("api", func() error { return nil }))
+	assert.Eq(t, circuit.StateClosed, g2.Get("api").State())
+	assert.False(t, c.Has("circuit:api"))
+	assert.Eq(t, circuit.StateHalfOpen, g1.Get("api").State())
+
+	assert.Eq(t, "State(9)", circuit.State(9).String())
+}
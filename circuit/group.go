@@ -0,0 +1,71 @@
+package circuit
+
+import (
+	"sort"
+	"sync"
+)
+
+// Group manage the breakers by name, the breakers are created on first use with
+// the same options.
+type Group struct {
+	opt      *Options
+	mu       sync.RWMutex
+	breakers map[string]*Breaker
+}
+
+// NewGroup create a breaker group
+func NewGroup(optFns ...OptionFn) *Group {
+	return &Group{opt: newOptions(optFns), breakers: make(map[string]*Breaker)}
+}
+
+// Get the breaker by name, create it if not exists.
+func (g *Group) Get(name string) *Breaker {
+	g.mu.RLock()
+	b, ok := g.breakers[name]
+	g.mu.RUnlock()
+	if ok {
+		return b
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if b, ok = g.breakers[name]; !ok {
+		b = newBreaker(name, g.opt)
+		g.breakers[name] = b
+	}
+	return b
+}
+
+// Do run fn by the breaker of the name. see Breaker.Do
+func (g *Group) Do(name string, fn func() error) error {
+	return g.Get(name).Do(fn)
+}
+
+// Names get the names of the created breakers, sorted.
+func (g *Group) Names() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	names := make([]string, 0, len(g.breakers))
+	for name := range g.breakers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// States get the current states of the created breakers
+func (g *Group) States() map[string]State {
+	g.mu.RLock()
+	bs := make([]*Breaker, 0, len(g.breakers))
+	for _, b := range g.breakers {
+		bs = append(bs, b)
+	}
+	g.mu.RUnlock()
+
+	states := make(map[string]State, len(bs))
+	for _, b := range bs {
+		states[b.name] = b.State()
+	}
+	return states
+}
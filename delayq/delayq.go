@@ -0,0 +1,347 @@
+// Package delayq provides a generic delayed queue, the items become available only
+// after the per-item delay. The delays are tracked by a hashed timer wheel, so adding
+// and canceling an item is O(1).
+//
+// It is the companion to the TTL caching for the "do X when Y expires" workflows,
+// the pending items can be saved to and loaded from files by the lcache serializers.
+//
+// Usage:
+//
+//	q := delayq.New[string]()
+//	defer q.Close()
+//
+//	id, err := q.Put("order:1001", 30*time.Minute)
+//	// the order is paid
+//	q.Cancel(id)
+//
+//	// handle the ready items by 4 workers, until ctx is done or the queue is closed
+//	go q.Run(ctx, 4, func(ctx context.Context, orderID string) {
+//		closeUnpaidOrder(orderID)
+//	})
+package delayq
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrClosed the queue is closed. see Queue.Close
+var ErrClosed = errors.New("delayq: queue is closed")
+
+// Options for the queue
+type Options struct {
+	// Tick the precision of the delays. default is 10ms
+	Tick time.Duration
+	// Slots number of the slots of the timer wheel. default is 512
+	Slots int
+	// Serializer name of the registered lcache serializer for SaveFile and LoadFile. default is "json"
+	Serializer string
+	// SaveSync call fsync on the file before rename it on SaveFile
+	SaveSync bool
+}
+
+// OptionFn option func for New
+type OptionFn func(o *Options)
+
+// WithTick set the precision of the delays, the items become ready at most one tick late.
+func WithTick(tick time.Duration) OptionFn {
+	return func(o *Options) { o.Tick = tick }
+}
+
+// WithSlots set the number of the slots of the timer wheel, a round of the wheel is
+// Tick * Slots. The delays longer than a round are supported by counting the rounds.
+func WithSlots(n int) OptionFn {
+	return func(o *Options) { o.Slots = n }
+}
+
+// WithSerializer set the serializer for SaveFile and LoadFile, it must be registered
+// in lcache. see lcache.SetSerializer
+func WithSerializer(name string) OptionFn {
+	return func(o *Options) { o.Serializer = name }
+}
+
+// WithSaveSync set whether to fsync the file on SaveFile, then the saved file
+// is complete after a system crash.
+func WithSaveSync(sync bool) OptionFn {
+	return func(o *Options) { o.SaveSync = sync }
+}
+
+// item 延迟的数据项. at 到期时间 unix nano; rounds 剩余的轮数
+type item[T any] struct {
+	id     uint64
+	val    T
+	at     int64
+	slot   int
+	rounds int
+}
+
+// Queue a generic delayed queue, it is goroutine-safe.
+type Queue[T any] struct {
+	opt Options
+	mu  sync.Mutex
+	seq uint64
+	// slots 时间轮的槽; cursor 当前槽; wheelAt 当前槽对应的时间 unix nano
+	slots   []map[uint64]*item[T]
+	cursor  int
+	wheelAt int64
+	// pending 等待中的数据项
+	pending map[uint64]*item[T]
+	// ready 已到期的数据项，按到期的先后顺序
+	ready []*item[T]
+
+	closed bool
+	// wake 有新的到期数据项或关闭时关闭，唤醒所有等待者
+	wake    chan struct{}
+	waiters int
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// New create a queue and start the timer wheel
+func New[T any](optFns ...OptionFn) *Queue[T] {
+	q := &Queue[T]{
+		opt:     Options{Tick: 10 * time.Millisecond, Slots: 512, Serializer: "json"},
+		pending: make(map[uint64]*item[T]),
+		wake:    make(chan struct{}),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	for _, fn := range optFns {
+		fn(&q.opt)
+	}
+	if q.opt.Tick <= 0 {
+		q.opt.Tick = 10 * time.Millisecond
+	}
+	q.opt.Slots = max(q.opt.Slots, 1)
+
+	q.slots = make([]map[uint64]*item[T], q.opt.Slots)
+	for i := range q.slots {
+		q.slots[i] = make(map[uint64]*item[T])
+	}
+	q.wheelAt = time.Now().UnixNano()
+
+	go q.run()
+	return q
+}
+
+// Options get the options of the queue
+func (q *Queue[T]) Options() Options { return q.opt }
+
+// Put an item ready after the delay, delay <= 0 for ready now. Returns the item id
+// for Cancel, or ErrClosed if the queue is closed.
+func (q *Queue[T]) Put(val T, delay time.Duration) (id uint64, err error) {
+	return q.PutAt(val, time.Now().Add(delay))
+}
+
+// PutAt put an item ready at the time
+func (q *Queue[T]) PutAt(val T, at time.Time) (id uint64, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return 0, ErrClosed
+	}
+
+	q.seq++
+	q.add(&item[T]{id: q.seq, val: val, at: at.UnixNano()})
+	return q.seq, nil
+}
+
+// add 按到期时间加入时间轮，已到期的直接加入 ready. 需持有锁
+func (q *Queue[T]) add(it *item[T]) {
+	if it.at <= time.Now().UnixNano() {
+		q.pushReady([]*item[T]{it})
+		return
+	}
+
+	// wheelAt 不晚于当前时间，ticks >= 1
+	ticks := (it.at - q.wheelAt + int64(q.opt.Tick) - 1) / int64(q.opt.Tick)
+
+	n := int64(len(q.slots))
+	it.slot = int((int64(q.cursor) + ticks) % n)
+	it.rounds = int((ticks - 1) / n)
+	q.slots[it.slot][it.id] = it
+	q.pending[it.id] = it
+}
+
+// Cancel the pending item by id, returns false if it is not pending(eg: already ready).
+func (q *Queue[T]) Cancel(id uint64) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	it, ok := q.pending[id]
+	if ok {
+		delete(q.pending, id)
+		delete(q.slots[it.slot], id)
+	}
+	return ok
+}
+
+// TryTake get and remove a ready item without blocking, returns false if no ready item.
+func (q *Queue[T]) TryTake() (val T, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.ready) == 0 {
+		return val, false
+	}
+	return q.popReady(), true
+}
+
+// Take get and remove a ready item, blocks until an item is ready.
+// Returns ctx.Err() if ctx is done, ErrClosed if the queue is closed and no ready item.
+func (q *Queue[T]) Take(ctx context.Context) (val T, err error) {
+	q.mu.Lock()
+	for len(q.ready) == 0 {
+		if q.closed {
+			q.mu.Unlock()
+			return val, ErrClosed
+		}
+
+		ch := q.wake
+		q.waiters++
+		q.mu.Unlock()
+
+		select {
+		case <-ch:
+			q.mu.Lock()
+			q.waiters--
+		case <-ctx.Done():
+			q.mu.Lock()
+			q.waiters--
+			q.mu.Unlock()
+			return val, ctx.Err()
+		}
+	}
+
+	val = q.popReady()
+	q.mu.Unlock()
+	return val, nil
+}
+
+// Run start the workers to handle the ready items by fn, blocks until ctx is done or
+// the queue is closed and no ready item. fn is called with ctx.
+func (q *Queue[T]) Run(ctx context.Context, workers int, fn func(ctx context.Context, val T)) {
+	var wg sync.WaitGroup
+	wg.Add(max(workers, 1))
+	for range max(workers, 1) {
+		go func() {
+			defer wg.Done()
+			for {
+				val, err := q.Take(ctx)
+				if err != nil {
+					return
+				}
+				fn(ctx, val)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Len get the number of the pending and ready items
+func (q *Queue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending) + len(q.ready)
+}
+
+// Pending get the number of the pending items
+func (q *Queue[T]) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// Ready get the number of the ready items
+func (q *Queue[T]) Ready() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.ready)
+}
+
+// Close stop the timer wheel, the blocked Take calls return ErrClosed. The ready items
+// can still be taken, the pending items are kept for SaveFile but never become ready.
+func (q *Queue[T]) Close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	q.broadcast()
+	q.mu.Unlock()
+
+	close(q.stop)
+	<-q.done
+}
+
+// run 时间轮的后台 goroutine
+func (q *Queue[T]) run() {
+	defer close(q.done)
+	ticker := time.NewTicker(q.opt.Tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.stop:
+			return
+		case now := <-ticker.C:
+			q.advance(now.UnixNano())
+		}
+	}
+}
+
+// advance 推进时间轮到 now，ticker 延迟时补齐跳过的槽
+func (q *Queue[T]) advance(now int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var due []*item[T]
+	tick := int64(q.opt.Tick)
+	for q.wheelAt+tick <= now {
+		q.wheelAt += tick
+		q.cursor = (q.cursor + 1) % len(q.slots)
+		for id, it := range q.slots[q.cursor] {
+			if it.rounds > 0 {
+				it.rounds--
+				continue
+			}
+			delete(q.slots[q.cursor], id)
+			delete(q.pending, id)
+			due = append(due, it)
+		}
+	}
+	q.pushReady(due)
+}
+
+// pushReady 按到期时间加入 ready 并唤醒等待者. 需持有锁
+func (q *Queue[T]) pushReady(items []*item[T]) {
+	if len(items) == 0 {
+		return
+	}
+
+	slices.SortFunc(items, func(a, b *item[T]) int {
+		return cmp.Or(cmp.Compare(a.at, b.at), cmp.Compare(a.id, b.id))
+	})
+	q.ready = append(q.ready, items...)
+	q.broadcast()
+}
+
+// popReady 取出最早到期的数据项. 需持有锁且 ready 不为空
+func (q *Queue[T]) popReady() T {
+	it := q.ready[0]
+	q.ready[0] = nil
+	q.ready = q.ready[1:]
+	return it.val
+}
+
+// broadcast 唤醒所有等待者. 需持有锁
+func (q *Queue[T]) broadcast() {
+	if q.waiters > 0 {
+		close(q.wake)
+		q.wake = make(chan struct{})
+	}
+}
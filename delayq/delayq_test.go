@@ -0,0 +1,183 @@
+package delayq_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/delayq"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestQueue_Put(t *testing.T) {
+	q := delayq.New[string](delayq.WithTick(5 * time.Millisecond))
+	defer q.Close()
+
+	start := time.Now()
+	_, err := q.Put("b", 60*time.Millisecond)
+	assert.NoErr(t, err)
+	_, err = q.Put("a", 30*time.Millisecond)
+	assert.NoErr(t, err)
+	_, err = q.Put("now", 0)
+	assert.NoErr(t, err)
+	assert.Eq(t, 3, q.Len())
+	assert.Eq(t, 2, q.Pending())
+	assert.Eq(t, 1, q.Ready())
+
+	val, ok := q.TryTake()
+	assert.True(t, ok)
+	assert.Eq(t, "now", val)
+	_, ok = q.TryTake()
+	assert.False(t, ok)
+
+	ctx := context.Background()
+	val, err = q.Take(ctx)
+	assert.NoErr(t, err)
+	assert.Eq(t, "a", val)
+	assert.True(t, time.Since(start) >= 30*time.Millisecond)
+
+	val, err = q.Take(ctx)
+	assert.NoErr(t, err)
+	assert.Eq(t, "b", val)
+	assert.True(t, time.Since(start) >= 60*time.Millisecond)
+	assert.Eq(t, 0, q.Len())
+
+	// timeout
+	tctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = q.Take(tctx)
+	assert.ErrIs(t, err, context.DeadlineExceeded)
+}
+
+func TestQueue_rounds(t *testing.T) {
+	// a round is 20ms
+	q := delayq.New[int](delayq.WithTick(5*time.Millisecond), delayq.WithSlots(4))
+	defer q.Close()
+
+	start := time.Now()
+	q.Put(2, 70*time.Millisecond)
+	q.Put(1, 25*time.Millisecond)
+	q.PutAt(0, start.Add(-time.Second))
+
+	for want := 0; want < 3; want++ {
+		val, err := q.Take(context.Background())
+		assert.NoErr(t, err)
+		assert.Eq(t, want, val)
+	}
+	assert.True(t, time.Since(start) >= 70*time.Millisecond)
+}
+
+func TestQueue_Cancel(t *testing.T) {
+	q := delayq.New[string]()
+	defer q.Close()
+
+	id, err := q.Put("a", 20*time.Millisecond)
+	assert.NoErr(t, err)
+	q.Put("b", 40*time.Millisecond)
+	assert.True(t, q.Cancel(id))
+	assert.False(t, q.Cancel(id))
+	assert.Eq(t, 1, q.Pending())
+
+	val, err := q.Take(context.Background())
+	assert.NoErr(t, err)
+	assert.Eq(t, "b", val)
+}
+
+func TestQueue_Run(t *testing.T) {
+	q := delayq.New[int]()
+	for i := 0; i < 100; i++ {
+		q.Put(i, time.Duration(i%10)*time.Millisecond)
+	}
+
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.Run(context.Background(), 4, func(ctx context.Context, val int) {
+			mu.Lock()
+			seen[val] = true
+			mu.Unlock()
+		})
+	}()
+
+	for q.Len() > 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	// Run returns after closed
+	q.Close()
+	<-done
+	assert.Len(t, seen, 100)
+
+	_, err := q.Put(1, 0)
+	assert.ErrIs(t, err, delayq.ErrClosed)
+	_, err = q.Take(context.Background())
+	assert.ErrIs(t, err, delayq.ErrClosed)
+}
+
+func TestQueue_Close(t *testing.T) {
+	q := delayq.New[string]()
+	q.Put("ready", 0)
+	q.Put("pending", time.Hour)
+
+	errCh := make(chan error, 1)
+	q2 := delayq.New[string]()
+	go func() {
+		_, err := q2.Take(context.Background())
+		errCh <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	q2.Close()
+	assert.ErrIs(t, <-errCh, delayq.ErrClosed)
+
+	q.Close()
+	q.Close()
+	// the ready items can still be taken
+	val, err := q.Take(context.Background())
+	assert.NoErr(t, err)
+	assert.Eq(t, "ready", val)
+	_, err = q.Take(context.Background())
+	assert.ErrIs(t, err, delayq.ErrClosed)
+	assert.Eq(t, 1, q.Pending())
+}
+
+type task struct {
+	Name string `json:"name"`
+	N    int    `json:"n"`
+}
+
+func TestQueue_SaveLoadFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "delayq.json")
+
+	q := delayq.New[task]()
+	q.Put(task{Name: "ready", N: 1}, 0)
+	q.Put(task{Name: "later", N: 2}, 50*time.Millisecond)
+	q.Put(task{Name: "pending", N: 3}, time.Hour)
+	q.Close()
+	assert.NoErr(t, q.SaveFile(file))
+
+	q2 := delayq.New[task]()
+	defer q2.Close()
+	assert.NoErr(t, q2.LoadFile(file))
+	assert.Eq(t, 3, q2.Len())
+	assert.Eq(t, 1, q2.Ready())
+
+	val, err := q2.Take(context.Background())
+	assert.NoErr(t, err)
+	assert.Eq(t, task{Name: "ready", N: 1}, val)
+	val, err = q2.Take(context.Background())
+	assert.NoErr(t, err)
+	assert.Eq(t, "later", val.Name)
+	assert.Eq(t, 1, q2.Pending())
+
+	// errors
+	assert.Err(t, q2.LoadFile(file+".notexist"))
+	q3 := delayq.New[task](delayq.WithSerializer("notexist"))
+	defer q3.Close()
+	assert.ErrMsgContains(t, q3.SaveFile(file), "not registered serializer")
+	assert.ErrMsgContains(t, q3.LoadFile(file), "not registered serializer")
+	q.Close()
+	assert.ErrIs(t, q.LoadFile(file), delayq.ErrClosed)
+}
@@ -0,0 +1,81 @@
+package delayq
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/x/stdio"
+)
+
+// record 持久化的数据项. At 到期时间 unix milli
+type record[T any] struct {
+	At  int64 `json:"at"`
+	Val T     `json:"val"`
+}
+
+// snapshot 持久化到文件的数据结构
+type snapshot[T any] struct {
+	Items []*record[T] `json:"items"`
+}
+
+// SaveFile save the pending and ready items to a file, with their ready time.
+// It can be called after Close, eg: save the pending items on shutdown.
+// The previous file is replaced only after the write succeeded, and the new file is
+// fsynced before it with WithSaveSync. see lcache.FileProvider
+func (q *Queue[T]) SaveFile(filename string) error {
+	s, err := lcache.LookupSerializer(q.opt.Serializer)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	data := &snapshot[T]{Items: make([]*record[T], 0, len(q.ready)+len(q.pending))}
+	for _, it := range q.ready {
+		data.Items = append(data.Items, &record[T]{At: time.Unix(0, it.at).UnixMilli(), Val: it.val})
+	}
+	for _, it := range q.pending {
+		data.Items = append(data.Items, &record[T]{At: time.Unix(0, it.at).UnixMilli(), Val: it.val})
+	}
+	q.mu.Unlock()
+
+	return lcache.FileProvider{Sync: q.opt.SaveSync}.Snapshot(filename, func(w io.Writer) error {
+		return s.EncodeTo(w, data)
+	})
+}
+
+// LoadFile load the items from a file saved by SaveFile and put them with the saved
+// ready time, the past due items are ready now.
+func (q *Queue[T]) LoadFile(filename string) error {
+	s, err := lcache.LookupSerializer(q.opt.Serializer)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer stdio.SafeClose(file)
+
+	var data snapshot[T]
+	if err = s.DecodeFrom(file, &data); err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+
+	for _, r := range data.Items {
+		if r == nil {
+			continue
+		}
+		q.seq++
+		q.add(&item[T]{id: q.seq, val: r.Val, at: time.UnixMilli(r.At).UnixNano()})
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ go 1.23
 require github.com/gookit/goutil v0.8.0
 
 require (
+	golang.org/x/sync v0.11.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/term v0.29.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
@@ -0,0 +1,69 @@
+// Package bloombits provides the bitset and hashing shared by the Bloom filters
+// of the bloom package and the miss filter of lcache.
+package bloombits
+
+import "math"
+
+// FNV-1a 64 位参数, 与 hash/fnv 一致
+const (
+	offset64 = 14695981039346656037
+	prime64  = 1099511628211
+)
+
+// Estimate the number of bits m and hash functions k for n elements with the false positive rate.
+func Estimate(n int, fpRate float64) (m, k uint64) {
+	m = uint64(math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	m = max(m, 64)
+	k = uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	return m, max(k, 1)
+}
+
+// Hash the data for the double hashing: the i-th location is h1 + i*h2. h2 is odd,
+// so the locations do not repeat.
+func Hash[T string | []byte](data T) (h1, h2 uint64) {
+	h := uint64(offset64)
+	for i := 0; i < len(data); i++ {
+		h ^= uint64(data[i])
+		h *= prime64
+	}
+
+	h = mix64(h)
+	return h & math.MaxUint32, h>>32 | 1
+}
+
+// mix64 打散 FNV 结果的高位，使 h1, h2 分布更均匀 (splitmix64 finalizer)
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+// Bits a bitset of the Bloom filter, the methods are not goroutine-safe.
+type Bits []uint64
+
+// Make create the bitset for m bits
+func Make(m uint64) Bits {
+	return make(Bits, (m+63)/64)
+}
+
+// Set the k locations of the hashes h1, h2 in the m bits
+func (b Bits) Set(m, k, h1, h2 uint64) {
+	for i := uint64(0); i < k; i++ {
+		loc := (h1 + i*h2) % m
+		b[loc/64] |= 1 << (loc % 64)
+	}
+}
+
+// Test all the k locations of the hashes h1, h2 in the m bits are set
+func (b Bits) Test(m, k, h1, h2 uint64) bool {
+	for i := uint64(0); i < k; i++ {
+		loc := (h1 + i*h2) % m
+		if b[loc/64]&(1<<(loc%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,36 @@
+package bloombits_test
+
+import (
+	"testing"
+
+	"github.com/gookit/ext/internal/bloombits"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestHash(t *testing.T) {
+	h1, h2 := bloombits.Hash("user:1001")
+	b1, b2 := bloombits.Hash([]byte("user:1001"))
+	assert.Eq(t, h1, b1)
+	assert.Eq(t, h2, b2)
+	assert.Eq(t, uint64(1), h2&1)
+
+	o1, _ := bloombits.Hash("user:1002")
+	assert.NotEq(t, h1, o1)
+}
+
+func TestBits(t *testing.T) {
+	m, k := bloombits.Estimate(1000, 0.01)
+	assert.Eq(t, uint64(9586), m)
+	assert.Eq(t, uint64(7), k)
+
+	b := bloombits.Make(m)
+	assert.Len(t, b, 150)
+
+	h1, h2 := bloombits.Hash("a")
+	assert.False(t, b.Test(m, k, h1, h2))
+	b.Set(m, k, h1, h2)
+	assert.True(t, b.Test(m, k, h1, h2))
+
+	h1, h2 = bloombits.Hash("b")
+	assert.False(t, b.Test(m, k, h1, h2))
+}
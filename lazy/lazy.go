@@ -0,0 +1,208 @@
+// Package lazy provides the lazy values computed once on demand, and optionally
+// re-computed after a TTL or on Invalidate.
+//
+// Concurrent calls on a missing or expired value only compute it once(singleflight).
+// The errors are not cached, the next call computes it again.
+//
+// Usage:
+//
+//	cfg := lazy.New(loadConfig, lazy.WithTTL(time.Minute))
+//	c, err := cfg.Get()
+//
+//	// on config file changed
+//	cfg.Invalidate()
+//
+//	// a singleton
+//	var client = lazy.New(func() (*Client, error) { return NewClient(addr) })
+package lazy
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Options for the lazy value
+type Options struct {
+	// TTL re-compute the value after it, 0 to never expire.
+	TTL time.Duration
+	// StaleWhileRefresh return the expired value and re-compute it in the background.
+	// The first computing still blocks.
+	StaleWhileRefresh bool
+	// OnError callback on the background re-computing failed. see StaleWhileRefresh
+	OnError func(err error)
+}
+
+// OptionFn option func for New
+type OptionFn func(o *Options)
+
+// WithTTL set the TTL of the value
+func WithTTL(ttl time.Duration) OptionFn {
+	return func(o *Options) { o.TTL = ttl }
+}
+
+// WithStaleWhileRefresh return the expired value and re-compute it in the background
+func WithStaleWhileRefresh(fn func(err error)) OptionFn {
+	return func(o *Options) {
+		o.StaleWhileRefresh = true
+		o.OnError = fn
+	}
+}
+
+// state 已计算的值. exp 过期时间 unix nano, 0 表示永不过期
+type state[T any] struct {
+	val T
+	exp int64
+}
+
+func (s *state[T]) expired(now int64) bool { return s.exp > 0 && s.exp <= now }
+
+// call 进行中的计算
+type call[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// Value a lazy value, it is goroutine-safe.
+type Value[T any] struct {
+	fn  func() (T, error)
+	opt Options
+
+	cur atomic.Pointer[state[T]]
+	mu  sync.Mutex
+	// call 进行中的计算; gen Invalidate 时递增，丢弃之前开始的计算结果
+	call *call[T]
+	gen  uint64
+}
+
+// New create a lazy value computed by fn
+func New[T any](fn func() (T, error), optFns ...OptionFn) *Value[T] {
+	v := &Value[T]{fn: fn}
+	for _, f := range optFns {
+		f(&v.opt)
+	}
+	return v
+}
+
+// Get the value, compute it if not computed or expired.
+// A panic of fn is returned as an error.
+func (v *Value[T]) Get() (T, error) {
+	if s := v.cur.Load(); s != nil {
+		if !s.expired(time.Now().UnixNano()) {
+			return s.val, nil
+		}
+		if v.opt.StaleWhileRefresh {
+			v.refresh()
+			return s.val, nil
+		}
+	}
+
+	v.mu.Lock()
+	if s := v.cur.Load(); s != nil && !s.expired(time.Now().UnixNano()) {
+		v.mu.Unlock()
+		return s.val, nil
+	}
+	c := v.start()
+	v.mu.Unlock()
+
+	<-c.done
+	return c.val, c.err
+}
+
+// MustGet get the value, panics on error
+func (v *Value[T]) MustGet() T {
+	val, err := v.Get()
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// Peek get the computed value without computing, returns false if not computed or expired.
+func (v *Value[T]) Peek() (val T, ok bool) {
+	if s := v.cur.Load(); s != nil && !s.expired(time.Now().UnixNano()) {
+		return s.val, true
+	}
+	return val, false
+}
+
+// Set the value directly, with the TTL
+func (v *Value[T]) Set(val T) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.gen++
+	v.cur.Store(v.newState(val))
+	v.call = nil
+}
+
+// Invalidate the computed value, the next Get computes it again.
+// The result of the computing in progress is not stored.
+func (v *Value[T]) Invalidate() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.gen++
+	v.cur.Store(nil)
+	// 之后的 Get 不再等待进行中的计算
+	v.call = nil
+}
+
+// refresh 在后台重新计算
+func (v *Value[T]) refresh() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.call != nil {
+		return
+	}
+
+	c := v.start()
+	go func() {
+		<-c.done
+		if c.err != nil && v.opt.OnError != nil {
+			v.opt.OnError(c.err)
+		}
+	}()
+}
+
+// start 开始计算，已有进行中的计算时返回它. 需持有锁
+func (v *Value[T]) start() *call[T] {
+	if v.call != nil {
+		return v.call
+	}
+
+	c := &call[T]{done: make(chan struct{})}
+	v.call = c
+	gen := v.gen
+	go v.compute(c, gen)
+	return c
+}
+
+// compute 执行计算并保存结果
+func (v *Value[T]) compute(c *call[T], gen uint64) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.err = fmt.Errorf("lazy: compute panic: %v", r)
+		}
+
+		v.mu.Lock()
+		if c.err == nil && gen == v.gen {
+			v.cur.Store(v.newState(c.val))
+		}
+		if v.call == c {
+			v.call = nil
+		}
+		v.mu.Unlock()
+		close(c.done)
+	}()
+
+	c.val, c.err = v.fn()
+}
+
+func (v *Value[T]) newState(val T) *state[T] {
+	s := &state[T]{val: val}
+	if v.opt.TTL > 0 {
+		s.exp = time.Now().Add(v.opt.TTL).UnixNano()
+	}
+	return s
+}
@@ -0,0 +1,146 @@
+package lazy_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lazy"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestValue(t *testing.T) {
+	var calls atomic.Int32
+	v := lazy.New(func() (int, error) {
+		time.Sleep(10 * time.Millisecond)
+		return int(calls.Add(1)), nil
+	})
+
+	_, ok := v.Peek()
+	assert.False(t, ok)
+
+	// concurrent calls only compute once
+	var wg sync.WaitGroup
+	results := make([]int, 50)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = v.Get()
+		}()
+	}
+	wg.Wait()
+	for _, r := range results {
+		assert.Eq(t, 1, r)
+	}
+	assert.Eq(t, 1, v.MustGet())
+
+	val, ok := v.Peek()
+	assert.True(t, ok)
+	assert.Eq(t, 1, val)
+
+	// invalidate
+	v.Invalidate()
+	assert.Eq(t, 2, v.MustGet())
+	v.Set(100)
+	assert.Eq(t, 100, v.MustGet())
+	assert.Eq(t, int32(2), calls.Load())
+}
+
+func TestValue_TTL(t *testing.T) {
+	var calls atomic.Int32
+	v := lazy.New(func() (int, error) {
+		return int(calls.Add(1)), nil
+	}, lazy.WithTTL(20*time.Millisecond))
+
+	assert.Eq(t, 1, v.MustGet())
+	assert.Eq(t, 1, v.MustGet())
+	time.Sleep(25 * time.Millisecond)
+	_, ok := v.Peek()
+	assert.False(t, ok)
+	assert.Eq(t, 2, v.MustGet())
+}
+
+func TestValue_error(t *testing.T) {
+	errLoad := errors.New("load error")
+	var fail atomic.Bool
+	fail.Store(true)
+	v := lazy.New(func() (string, error) {
+		if fail.Load() {
+			return "", errLoad
+		}
+		return "ok", nil
+	})
+
+	// errors are not cached
+	_, err := v.Get()
+	assert.ErrIs(t, err, errLoad)
+	assert.Panics(t, func() {
+		v.MustGet()
+	})
+	fail.Store(false)
+	assert.Eq(t, "ok", v.MustGet())
+
+	// panic
+	v2 := lazy.New(func() (int, error) { panic("oops") })
+	_, err = v2.Get()
+	assert.ErrMsgContains(t, err, "oops")
+}
+
+func TestValue_StaleWhileRefresh(t *testing.T) {
+	var calls atomic.Int32
+	errCh := make(chan error, 1)
+	v := lazy.New(func() (int, error) {
+		n := int(calls.Add(1))
+		time.Sleep(5 * time.Millisecond)
+		if n == 3 {
+			return 0, errors.New("refresh error")
+		}
+		return n, nil
+	}, lazy.WithTTL(10*time.Millisecond), lazy.WithStaleWhileRefresh(func(err error) {
+		errCh <- err
+	}))
+
+	assert.Eq(t, 1, v.MustGet())
+	time.Sleep(15 * time.Millisecond)
+
+	// returns the expired value, refresh in background
+	assert.Eq(t, 1, v.MustGet())
+	time.Sleep(10 * time.Millisecond)
+	assert.Eq(t, 2, v.MustGet())
+
+	// refresh failed, keep the old value
+	time.Sleep(15 * time.Millisecond)
+	assert.Eq(t, 2, v.MustGet())
+	assert.ErrMsg(t, <-errCh, "refresh error")
+	assert.Eq(t, 2, v.MustGet())
+}
+
+func TestValue_invalidateLoading(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int32
+	v := lazy.New(func() (int, error) {
+		if calls.Add(1) == 1 {
+			<-release
+		}
+		return int(calls.Load()), nil
+	})
+
+	done := make(chan int)
+	go func() {
+		val, _ := v.Get()
+		done <- val
+	}()
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the result of the computing in progress is not stored
+	v.Invalidate()
+	assert.Eq(t, 2, v.MustGet())
+	close(release)
+	assert.Eq(t, 2, <-done)
+	assert.Eq(t, 2, v.MustGet())
+}
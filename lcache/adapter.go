@@ -0,0 +1,120 @@
+package lcache
+
+import "time"
+
+// Adapter adapt the Cache to the gookit/cache driver interface, so lcache can be
+// used as a memory driver of gookit/cache.
+//
+// Usage:
+//
+//	cache.Register("lcache", lcache.NewAdapter(lcache.New()))
+//	cache.DefaultUse("lcache")
+type Adapter struct {
+	c *Cache
+	// Serializer optional, encode the values to bytes on Set and decode them on Get.
+	// default store the values as is.
+	Serializer Serializer
+}
+
+// NewAdapter create an adapter for the cache
+func NewAdapter(c *Cache) *Adapter {
+	return &Adapter{c: c}
+}
+
+// Cache get the adapted cache instance
+func (a *Adapter) Cache() *Cache { return a.c }
+
+// Has check the key exists
+func (a *Adapter) Has(key string) bool {
+	_, ok := a.c.Get(key)
+	return ok
+}
+
+// Get value by key, returns nil if not exists or decode failed
+func (a *Adapter) Get(key string) any {
+	val, ok := a.c.Get(key)
+	if !ok {
+		return nil
+	}
+	return a.decode(val)
+}
+
+// Set value by key
+func (a *Adapter) Set(key string, val any, ttl time.Duration) error {
+	val, err := a.encode(val)
+	if err != nil {
+		return err
+	}
+	return a.c.SetE(key, val, ttl)
+}
+
+// Del delete the key
+func (a *Adapter) Del(key string) error {
+	a.c.Delete(key)
+	return nil
+}
+
+// GetMulti get multiple values, the missing keys are mapped to nil
+func (a *Adapter) GetMulti(keys []string) map[string]any {
+	values := a.c.MGet(keys...)
+	for k, v := range values {
+		if v != nil {
+			values[k] = a.decode(v)
+		}
+	}
+	return values
+}
+
+// SetMulti set multiple values
+func (a *Adapter) SetMulti(values map[string]any, ttl time.Duration) error {
+	if a.Serializer == nil {
+		a.c.MSet(values, ttl)
+		return nil
+	}
+
+	encoded := make(map[string]any, len(values))
+	for k, v := range values {
+		bs, err := a.encode(v)
+		if err != nil {
+			return err
+		}
+		encoded[k] = bs
+	}
+	a.c.MSet(encoded, ttl)
+	return nil
+}
+
+// DelMulti delete multiple keys
+func (a *Adapter) DelMulti(keys []string) error {
+	a.c.MDelete(keys...)
+	return nil
+}
+
+// Clear all data
+func (a *Adapter) Clear() error {
+	a.c.Clear()
+	return nil
+}
+
+// Close the cache. see Cache.Close
+func (a *Adapter) Close() error { return a.c.Close() }
+
+func (a *Adapter) encode(val any) (any, error) {
+	if a.Serializer == nil {
+		return val, nil
+	}
+	return a.Serializer.Encode(val)
+}
+
+func (a *Adapter) decode(val any) any {
+	bs, ok := val.([]byte)
+	if a.Serializer == nil || !ok {
+		return val
+	}
+
+	var v any
+	if err := a.Serializer.Decode(bs, &v); err != nil {
+		return nil
+	}
+	return v
+}
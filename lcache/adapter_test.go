@@ -0,0 +1,50 @@
+package lcache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+// gookitCache the gookit/cache.Cache interface
+type gookitCache interface {
+	Has(key string) bool
+	Get(key string) any
+	Set(key string, val any, ttl time.Duration) (err error)
+	Del(key string) error
+	GetMulti(keys []string) map[string]any
+	SetMulti(values map[string]any, ttl time.Duration) (err error)
+	DelMulti(keys []string) error
+	Clear() error
+	Close() error
+}
+
+func TestAdapter(t *testing.T) {
+	var gc gookitCache = lcache.NewAdapter(lcache.New())
+
+	assert.NoErr(t, gc.Set("key1", "val1", time.Minute))
+	assert.True(t, gc.Has("key1"))
+	assert.Eq(t, "val1", gc.Get("key1"))
+	assert.Nil(t, gc.Get("not-exists"))
+
+	assert.NoErr(t, gc.SetMulti(map[string]any{"key2": 2, "key3": 3}, 0))
+	assert.Eq(t, map[string]any{"key2": 2, "key4": nil}, gc.GetMulti([]string{"key2", "key4"}))
+	assert.NoErr(t, gc.DelMulti([]string{"key2", "key3"}))
+	assert.NoErr(t, gc.Del("key1"))
+	assert.False(t, gc.Has("key1"))
+
+	// with byte marshaling
+	a := lcache.NewAdapter(lcache.New())
+	a.Serializer = lcache.JSONSerializer{}
+	assert.NoErr(t, a.Set("key1", map[string]any{"a": "b"}, 0))
+	assert.Eq(t, []byte(`{"a":"b"}`), a.Cache().Val("key1"))
+	assert.Eq(t, map[string]any{"a": "b"}, a.Get("key1"))
+
+	assert.NoErr(t, a.SetMulti(map[string]any{"key2": "val2"}, 0))
+	assert.Eq(t, map[string]any{"key2": "val2"}, a.GetMulti([]string{"key2"}))
+
+	assert.NoErr(t, a.Clear())
+	assert.NoErr(t, a.Close())
+}
@@ -0,0 +1,153 @@
+package lcache
+
+import (
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+	"time"
+)
+
+// MemGauge report the memory usage and the memory limit of the process in bytes,
+// limit 0 for unknown. eg: read from the cgroup. see WithAdaptiveCapacity
+type MemGauge func() (used, limit uint64)
+
+// RuntimeMemGauge the default MemGauge, the used memory is the live heap marked by the last GC
+// (runtime/metrics "/gc/heap/live:bytes"), and the limit is the GOMEMLIMIT, 0 if not set.
+//
+// The live heap does not count the garbage and the memory not yet returned to the OS,
+// so the memory freed by the evictions is observed after the next GC.
+func RuntimeMemGauge() (used, limit uint64) {
+	if n := debug.SetMemoryLimit(-1); n > 0 && n < math.MaxInt64 {
+		limit = uint64(n)
+	}
+	return readMetric("/gc/heap/live:bytes"), limit
+}
+
+// readMetric 读取 runtime/metrics 中的 uint64 指标，不支持时返回 0
+func readMetric(name string) uint64 {
+	sample := []metrics.Sample{{Name: name}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+// AdaptiveOptions the options of the adaptive capacity controller. see WithAdaptiveCapacity
+type AdaptiveOptions struct {
+	// Min, Max the bounds of the effective capacity, required.
+	Min, Max int
+	// Interval for check the memory usage. default is 10 seconds
+	Interval time.Duration
+	// High shrink the capacity when the usage ratio(used/limit) is at or above it. default is 0.9
+	High float64
+	// Low grow the capacity when the usage ratio is below it. default is 0.7
+	Low float64
+	// Step the fraction of the capacity to shrink or grow on each check, at least 1. default is 0.1
+	Step float64
+	// Gauge report the memory usage and limit. default is RuntimeMemGauge
+	Gauge MemGauge
+}
+
+// WithAdaptiveCapacity start a controller to adjust the capacity by the memory pressure of the process.
+//
+// On every interval, when the memory usage ratio reaches High, the capacity is shrunk by Step and
+// the least recently used items are evicted proactively to the new capacity. The evicted items
+// are freed by the GC, so it does not shrink again until a GC cycle completed after the last shrink.
+// When the ratio drops below Low, the capacity grows back by Step. The capacity is kept in [Min, Max], the initial
+// Capacity is clamped into it. Use Stats().Capacity to get the effective capacity.
+//
+// The controller does nothing if the limit is unknown(eg: GOMEMLIMIT not set) or LRU is disabled.
+//
+// Usage:
+//
+//	// GOMEMLIMIT=1GiB
+//	c := lcache.New(lcache.WithAdaptiveCapacity(lcache.AdaptiveOptions{Min: 1000, Max: 100000}))
+func WithAdaptiveCapacity(ao AdaptiveOptions) OptionFn {
+	if ao.Min <= 0 || ao.Max < ao.Min {
+		panic("adaptive capacity bounds must be 0 < Min <= Max")
+	}
+
+	if ao.Interval <= 0 {
+		ao.Interval = 10 * time.Second
+	}
+	if ao.High <= 0 {
+		ao.High = 0.9
+	}
+	if ao.Low <= 0 || ao.Low > ao.High {
+		ao.Low = min(0.7, ao.High)
+	}
+	if ao.Step <= 0 {
+		ao.Step = 0.1
+	}
+	if ao.Gauge == nil {
+		ao.Gauge = RuntimeMemGauge
+	}
+
+	return func(o *Options) {
+		o.Adaptive = &ao
+	}
+}
+
+// startAdaptive 根据配置(重新)启动容量调整任务
+func (c *Cache) startAdaptive() {
+	c.stopAdaptive()
+	ao := c.opt.Adaptive
+	if ao == nil || c.opt.DisableLRU {
+		return
+	}
+
+	c.mu.Lock()
+	c.resize(min(max(c.opt.Capacity, ao.Min), ao.Max))
+	c.mu.Unlock()
+	c.adaptStop = c.scheduler().Every(ao.Interval, func() { c.adaptCapacity(ao) })
+}
+
+// stopAdaptive 停止容量调整任务
+func (c *Cache) stopAdaptive() {
+	if c.adaptStop != nil {
+		c.adaptStop()
+		c.adaptStop = nil
+	}
+}
+
+// adaptCapacity 根据内存使用率缩小或扩大容量
+func (c *Cache) adaptCapacity(ao *AdaptiveOptions) {
+	used, limit := ao.Gauge()
+	if limit == 0 {
+		return
+	}
+
+	ratio := float64(used) / float64(limit)
+	if ratio >= ao.Low && ratio < ao.High {
+		return
+	}
+	if !c.lock() {
+		return
+	}
+	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return
+	}
+
+	capacity := c.opt.Capacity
+	step := max(int(float64(capacity)*ao.Step), 1)
+	if ratio >= ao.High {
+		// 上次淘汰的数据项在 GC 后才释放，之前的用量不能反映缩小的效果
+		gcN := readMetric("/gc/cycles/total:gc-cycles")
+		if gcN < c.adaptGC {
+			return
+		}
+		c.adaptGC = gcN + 1
+		c.resize(max(capacity-step, ao.Min))
+	} else {
+		c.resize(min(capacity+step, ao.Max))
+	}
+}
+
+// resize 设置容量，超出时淘汰最久未使用的项 (需持有写锁). 固定的项不会被淘汰
+func (c *Cache) resize(capacity int) {
+	c.opt.Capacity = capacity
+	for c.lruList.Len() > capacity && c.evict() {
+	}
+}
@@ -0,0 +1,69 @@
+package lcache
+
+import (
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestWithAdaptiveCapacity(t *testing.T) {
+	var used atomic.Uint64
+	gauge := func() (uint64, uint64) { return used.Load(), 1000 }
+	ao := AdaptiveOptions{Min: 50, Max: 120, Step: 0.5, Gauge: gauge}
+
+	// the initial capacity is clamped
+	c := New(WithCapacity(200), WithAdaptiveCapacity(ao), WithScheduler(idleScheduler{}))
+	defer c.Close()
+	assert.Eq(t, 120, c.Stats().Capacity)
+	for i := 0; i < 150; i++ {
+		c.Set("key"+strconv.Itoa(i), i, 0)
+	}
+	c.Pin("key30")
+	assert.Eq(t, 120, c.Len())
+
+	// high pressure, shrink and evict
+	used.Store(950)
+	c.adaptCapacity(c.opt.Adaptive)
+	assert.Eq(t, 60, c.Stats().Capacity)
+	assert.Eq(t, 60, c.Len())
+	assert.True(t, c.Has("key30"))
+	assert.True(t, c.Has("key149"))
+	// wait for a GC cycle before shrink again
+	c.adaptCapacity(c.opt.Adaptive)
+	assert.Eq(t, 60, c.Stats().Capacity)
+	runtime.GC()
+	c.adaptCapacity(c.opt.Adaptive)
+	assert.Eq(t, 50, c.Stats().Capacity)
+	assert.Eq(t, uint64(100), c.Stats().Evictions)
+
+	// normal, keep
+	used.Store(800)
+	c.adaptCapacity(c.opt.Adaptive)
+	assert.Eq(t, 50, c.Stats().Capacity)
+
+	// low pressure, grow
+	used.Store(100)
+	c.adaptCapacity(c.opt.Adaptive)
+	assert.Eq(t, 75, c.Stats().Capacity)
+	c.adaptCapacity(c.opt.Adaptive)
+	c.adaptCapacity(c.opt.Adaptive)
+	assert.Eq(t, 120, c.Stats().Capacity)
+	assert.True(t, c.HealthCheck().Healthy())
+
+	// unknown limit
+	c2 := New(WithAdaptiveCapacity(AdaptiveOptions{Min: 1, Max: 10, Interval: time.Millisecond,
+		Gauge: func() (uint64, uint64) { return 100, 0 }}))
+	defer c2.Close()
+	time.Sleep(5 * time.Millisecond)
+	assert.Eq(t, 10, c2.Stats().Capacity)
+
+	assert.Panics(t, func() {
+		WithAdaptiveCapacity(AdaptiveOptions{Min: 10, Max: 5})
+	})
+	u, _ := RuntimeMemGauge()
+	assert.Gt(t, u, uint64(0))
+}
@@ -0,0 +1,280 @@
+package lcache
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gookit/goutil/fsutil"
+	"github.com/gookit/goutil/x/stdio"
+)
+
+// AOF 操作类型
+const (
+	aofOpSet   = string(OpSet)
+	aofOpDel   = string(OpDel)
+	aofOpClear = string(OpClear)
+	// rename namespace: Key is old namespace, Val is new namespace
+	aofOpRename = string(OpRename)
+	// bump generation: Val is the new generation
+	aofOpGen = string(OpGen)
+)
+
+// aofRecord AOF 日志中的一条记录. 每条记录为一行 JSON
+type aofRecord struct {
+	Op  string `json:"op"`
+	Key string `json:"k,omitempty"`
+	Val any    `json:"v,omitempty"`
+	Exp int64  `json:"e,omitempty"`
+	// Grp 分组数据所属分组的名称
+	Grp string `json:"gp,omitempty"`
+}
+
+// openAOF 根据配置打开 AOF 文件. 文件已打开时不重复打开
+func (c *Cache) openAOF() {
+	if c.aofFile != nil && c.aofName == c.opt.AOFFile {
+		return
+	}
+
+	c.closeAOF()
+	if c.opt.AOFFile == "" {
+		return
+	}
+
+	file, err := fsutil.OpenAppendFile(c.opt.AOFFile, 0644)
+	if err != nil {
+		c.aofErr = err
+		return
+	}
+	c.setAOFFile(file, c.opt.AOFFile)
+}
+
+// setAOFFile 使用打开的 AOF 文件，以当前大小作为重写的基准大小. name 为 AOF 文件路径
+func (c *Cache) setAOFFile(file *os.File, name string) {
+	var size int64
+	if fi, err := file.Stat(); err == nil {
+		size = fi.Size()
+	}
+
+	c.aofFile, c.aofName = file, name
+	c.aofSize = &countWriter{w: file, n: size}
+	c.aofBase = size
+	c.aofEnc = json.NewEncoder(c.aofSize)
+}
+
+// closeAOF 关闭 AOF 文件 (不加锁)
+func (c *Cache) closeAOF() {
+	if c.aofFile != nil {
+		stdio.SafeClose(c.aofFile)
+		c.aofFile, c.aofName, c.aofEnc, c.aofSize = nil, "", nil, nil
+	}
+}
+
+// aofLen 获取 AOF 文件的大小 (不加锁)
+func (c *Cache) aofLen() int64 {
+	if c.aofSize == nil {
+		return 0
+	}
+	return c.aofSize.n
+}
+
+// appendAOF 追加一条记录到 AOF 文件和 OpAppender (不加锁). 都未开启时直接返回
+func (c *Cache) appendAOF(op, key string, val any, exp int64) error {
+	return c.appendRecord(&aofRecord{Op: op, Key: key, Val: val, Exp: exp})
+}
+
+// appendRecord 追加记录到 AOF 文件和 OpAppender (不加锁)
+func (c *Cache) appendRecord(rec *aofRecord) error {
+	var err error
+	if c.aofEnc != nil {
+		err = c.aofEnc.Encode(rec)
+		c.checkAOFRewrite()
+	}
+	if err1 := c.appendOp(rec.Op, rec.Key, rec.Val, rec.Exp); err == nil {
+		err = err1
+	}
+
+	if err != nil {
+		c.aofErr = err
+	}
+	return err
+}
+
+// LoadAOF replay the append-only log file, apply the records on the current data.
+//
+// Usually used on startup after LoadFile. Replayed records will not be appended to the AOF again.
+func (c *Cache) LoadAOF(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer stdio.SafeClose(file)
+
+	if !c.lock() {
+		return ErrBusy
+	}
+	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return ErrFrozen
+	}
+
+	dec := json.NewDecoder(file)
+	nowUm := time.Now().UnixMilli()
+	for {
+		var rec aofRecord
+		if err = dec.Decode(&rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			// 最后一条记录可能因进程崩溃而写入不完整
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return err
+		}
+
+		switch rec.Op {
+		case aofOpSet:
+			if rec.Exp > 0 && rec.Exp < nowUm {
+				c.removeKey(rec.Key, ReasonExpired)
+			} else if it := c.set(rec.Key, rec.Val, rec.Exp); it != nil && rec.Grp != "" {
+				it.grp = c.loadGroup(rec.Grp)
+			}
+		case aofOpDel:
+			c.removeKey(rec.Key, ReasonDeleted)
+		case aofOpClear:
+			c.reset()
+		case aofOpRename:
+			if newNs, ok := rec.Val.(string); ok {
+				c.renameNamespace(rec.Key, newNs)
+			}
+		case aofOpGen:
+			if gen, ok := rec.Val.(float64); ok && uint64(gen) > c.gen {
+				c.setGen(uint64(gen))
+			}
+		}
+	}
+}
+
+// CompactAOF rewrite the AOF file with the set records of the valid items only, the records of
+// the overwritten, deleted and expired items are dropped. The new file is written to
+// "<AOFFile>.tmp" then renamed, the AOF file is unchanged if it fails.
+//
+// Returns ErrNoAOF if the AOF is not enabled. see WithAOF, WithAOFRewrite
+//
+// NOTE: it holds the lock while rewriting, the time complexity is O(N).
+// The hashed keys without WithKeyHashCheck are written as the hashed keys.
+func (c *Cache) CompactAOF() error {
+	if !c.lock() {
+		return ErrBusy
+	}
+	defer c.mu.Unlock()
+
+	if c.aofFile == nil {
+		return ErrNoAOF
+	}
+	if err := c.rewriteAOF(); err != nil {
+		c.aofErr = err
+		return err
+	}
+	return nil
+}
+
+// rewriteAOF 将有效数据写入临时文件，重命名为 AOF 文件后重新打开 (不加锁)
+func (c *Cache) rewriteAOF() error {
+	filename := c.aofName
+	tmpFile := filename + ".tmp"
+	file, err := fsutil.OpenTruncFile(tmpFile, 0644)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(file)
+	enc := json.NewEncoder(bw)
+	if c.gen > 0 {
+		err = enc.Encode(&aofRecord{Op: aofOpGen, Val: c.gen})
+	}
+
+	nowUm := time.Now().UnixMilli()
+	for key, it := range c.items {
+		if err != nil {
+			break
+		}
+		if c.invalid(it, nowUm) {
+			continue
+		}
+		// 开启 key 冲突检查时记录了原始 key
+		if it.Key != "" {
+			key = it.Key
+		}
+		err = enc.Encode(&aofRecord{Op: aofOpSet, Key: key, Val: it.Val, Exp: it.Exp, Grp: it.groupName()})
+	}
+
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err == nil && c.opt.SaveSync {
+		err = file.Sync()
+	}
+	if err != nil {
+		stdio.SafeClose(file)
+		_ = os.Remove(tmpFile)
+		return err
+	}
+
+	// 重命名后文件句柄仍指向新文件，直接作为追加写入的文件
+	if err = os.Rename(tmpFile, filename); err != nil {
+		stdio.SafeClose(file)
+		_ = os.Remove(tmpFile)
+		return err
+	}
+
+	c.closeAOF()
+	c.setAOFFile(file, filename)
+	return nil
+}
+
+// checkAOFRewrite 检查 AOF 大小，超出阈值时在后台重写 (不加锁)
+func (c *Cache) checkAOFRewrite() {
+	ratio := c.opt.AOFRewriteRatio
+	size := c.aofLen()
+	if ratio <= 0 || size <= c.aofBase || size < c.opt.AOFRewriteMinSize || float64(size) < ratio*float64(c.aofBase) {
+		return
+	}
+
+	if c.aofRewriting.CompareAndSwap(false, true) {
+		go c.rewriteAOFAsync()
+	}
+}
+
+// rewriteAOFAsync 后台重写 AOF 文件，错误记录在 Stats 中. 在锁内重置重写标记，
+// 重写期间跳过检查的追加在解锁后可再次触发重写
+func (c *Cache) rewriteAOFAsync() {
+	if !c.lock() {
+		c.aofRewriting.Store(false)
+		return
+	}
+	defer c.mu.Unlock()
+	defer c.aofRewriting.Store(false)
+
+	if c.aofFile != nil {
+		if err := c.rewriteAOF(); err != nil {
+			c.aofErr = err
+		}
+	}
+}
+
+// countWriter 统计写入的字节数
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
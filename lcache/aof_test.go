@@ -0,0 +1,118 @@
+package lcache_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_LoadAOF(t *testing.T) {
+	filename := t.TempDir() + "/cache.aof"
+	c := lcache.New(lcache.WithAOF(filename))
+	assert.NoErr(t, c.SetE("key1", "value1", 0))
+	c.Set("key2", "value2", time.Minute)
+	c.MSet(map[string]any{"key3": "value3"}, 0)
+	c.Delete("key2")
+	assert.NoErr(t, c.Close())
+
+	c2 := lcache.New()
+	assert.NoErr(t, c2.LoadAOF(filename))
+	assert.Eq(t, "value1", c2.Val("key1"))
+	assert.Eq(t, "value3", c2.Val("key3"))
+	assert.False(t, c2.Has("key2"))
+	assert.Eq(t, 2, c2.Len())
+
+	// clear is replayed
+	c3 := lcache.New(lcache.WithAOF(filename))
+	c3.Clear()
+	c3.Set("key4", "value4", 0)
+	assert.NoErr(t, c3.Close())
+
+	c4 := lcache.New()
+	assert.NoErr(t, c4.LoadAOF(filename))
+	assert.Eq(t, 1, c4.Len())
+	assert.Eq(t, "value4", c4.Val("key4"))
+
+	// the generation bump is replayed
+	c5 := lcache.New(lcache.WithAOF(filename))
+	assert.NoErr(t, c5.LoadAOF(filename))
+	assert.Eq(t, uint64(1), c5.BumpGeneration())
+	c5.Set("key5", "value5", 0)
+
+	for _, compact := range []bool{false, true} {
+		if compact {
+			assert.NoErr(t, c5.CompactAOF())
+		}
+		c6 := lcache.New()
+		assert.NoErr(t, c6.LoadAOF(filename))
+		assert.Eq(t, uint64(1), c6.Generation())
+		assert.False(t, c6.Has("key4"))
+		assert.Eq(t, "value5", c6.Val("key5"))
+	}
+	assert.NoErr(t, c5.Close())
+}
+
+func TestCache_CompactAOF(t *testing.T) {
+	assert.ErrIs(t, lcache.New().CompactAOF(), lcache.ErrNoAOF)
+
+	filename := t.TempDir() + "/cache.aof"
+	c := lcache.New(lcache.WithAOF(filename))
+	for i := 0; i < 10; i++ {
+		c.Set("key1", i, 0)
+	}
+	c.Set("key2", "value2", 0)
+	c.Set("expired", "value", time.Millisecond)
+	c.Delete("key2")
+	time.Sleep(5 * time.Millisecond)
+
+	before := c.Stats().AOFSize
+	assert.NoErr(t, c.CompactAOF())
+	after := c.Stats().AOFSize
+	assert.Lt(t, after, before)
+	assert.Eq(t, after, fileSize(t, filename))
+
+	// append to the rewritten file, rewrite again
+	c.Set("key3", "value3", time.Hour)
+	assert.NoErr(t, c.CompactAOF())
+	assert.Eq(t, c.Stats().AOFSize, fileSize(t, filename))
+	assert.NoErr(t, c.Close())
+	assert.NoErr(t, c.Stats().AOFErr)
+
+	c2 := lcache.New()
+	assert.NoErr(t, c2.LoadAOF(filename))
+	assert.Eq(t, 2, c2.Len())
+	assert.Eq(t, 9.0, c2.Val("key1"))
+	assert.Eq(t, "value3", c2.Val("key3"))
+}
+
+func TestWithAOFRewrite(t *testing.T) {
+	filename := t.TempDir() + "/cache.aof"
+	c := lcache.New(lcache.WithAOF(filename), lcache.WithAOFRewrite(2, 1024))
+	defer c.Close()
+
+	for i := 0; i < 500; i++ {
+		c.Set("key", i, 0)
+	}
+	c.Set("key", "last", 0)
+
+	// rewritten in the background
+	deadline := time.Now().Add(time.Second)
+	for c.Stats().AOFSize >= 2*1024 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Lt(t, c.Stats().AOFSize, int64(2*1024))
+	assert.NoErr(t, c.Stats().AOFErr)
+
+	c2 := lcache.New()
+	assert.NoErr(t, c2.LoadAOF(filename))
+	assert.Eq(t, "last", c2.Val("key"))
+}
+
+func fileSize(t *testing.T, filename string) int64 {
+	fi, err := os.Stat(filename)
+	assert.NoErr(t, err)
+	return fi.Size()
+}
@@ -0,0 +1,53 @@
+package lcache
+
+// Append atomically append s to the string or []byte value stored at key, and returns the
+// length of the new value. eg: build per-key logs or buffers.
+//
+// The missing or expired key is set to s(never expire), the TTL of the existing key is kept.
+// The value type is kept, appending to a []byte value creates a new slice, so the value read
+// before is not changed. Returns 0 if the value is not a string or []byte, or it cannot be
+// written(ErrBusy, ErrFrozen, ErrCacheFull, or the new value exceeds WithMaxValueBytes).
+func (c *Cache) Append(key string, s string) int {
+	return c.appendValue(key, s, nil)
+}
+
+// AppendBytes like Append, but appends bs. The missing key is set to a copy of bs. see Append
+func (c *Cache) AppendBytes(key string, bs []byte) int {
+	return c.appendValue(key, "", bs)
+}
+
+// appendValue 追加字符串或字节到已有的值. bs 为 nil 时追加 s
+func (c *Cache) appendValue(key, s string, bs []byte) (n int) {
+	err := c.updateValue(key, func(val any, exists bool) (any, error) {
+		if !exists {
+			if bs != nil {
+				val = []byte{}
+			} else {
+				val = ""
+			}
+		}
+
+		switch v := val.(type) {
+		case string:
+			if bs != nil {
+				v += string(bs)
+			} else {
+				v += s
+			}
+			n = len(v)
+			return v, nil
+		case []byte:
+			// 总是创建新的切片
+			nv := make([]byte, 0, len(v)+len(s)+len(bs))
+			nv = append(append(append(nv, v...), s...), bs...)
+			n = len(nv)
+			return nv, nil
+		}
+		return nil, ErrNotString
+	})
+
+	if err != nil {
+		return 0
+	}
+	return n
+}
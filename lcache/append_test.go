@@ -0,0 +1,54 @@
+package lcache_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_Append(t *testing.T) {
+	c := lcache.New()
+
+	assert.Eq(t, 5, c.Append("log", "hello"))
+	assert.Eq(t, 11, c.Append("log", " world"))
+	assert.Eq(t, "hello world", c.Val("log"))
+	assert.Eq(t, 12, c.AppendBytes("log", []byte("!")))
+	assert.Eq(t, "hello world!", c.Val("log"))
+
+	// bytes value
+	src := []byte("ab")
+	assert.Eq(t, 2, c.AppendBytes("buf", src))
+	src[0] = 'x'
+	old := c.Val("buf").([]byte)
+	assert.Eq(t, 4, c.Append("buf", "cd"))
+	assert.Eq(t, []byte("abcd"), c.Val("buf"))
+	assert.Eq(t, []byte("ab"), old)
+
+	// keep TTL
+	c.Set("ttl", "a", time.Minute)
+	c.Append("ttl", "b")
+	ttl, ok := c.TTL("ttl")
+	assert.True(t, ok)
+	assert.Gt(t, ttl, time.Duration(0))
+
+	// not a string
+	c.Set("int", 1, 0)
+	assert.Eq(t, 0, c.Append("int", "a"))
+	assert.Eq(t, 1, c.Val("int"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				c.Append("concurrent", "x")
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, c.Val("concurrent").(string), 100)
+}
@@ -0,0 +1,86 @@
+package lcache
+
+import "time"
+
+// Close stop the background goroutines(auto-save, write-behind, janitor, adaptive capacity) of the cache, close the AOF file,
+// flush the write-behind queue and performs a final save if auto-save is configured.
+// The queued async callbacks are called before return.
+// see WithAutoSave, WithWriteBehind, WithAsyncCallbacks
+func (c *Cache) Close() error {
+	c.stopTasks()
+	if c.isWriteBehind() {
+		if err := c.Flush(); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	c.closeAOF()
+	c.mu.Unlock()
+
+	if c.opt.AutoSaveFile != "" && c.opt.AutoSaveInterval > 0 {
+		return c.SaveFile(c.opt.AutoSaveFile)
+	}
+	return nil
+}
+
+// stopTasks 停止所有后台任务，不刷新队列也不保存
+func (c *Cache) stopTasks() {
+	c.stopAutoSave()
+	c.stopWriteBehind()
+	c.stopCallbacks()
+	c.stopJanitor()
+	c.stopAdaptive()
+}
+
+// startAutoSave 根据配置(重新)启动自动保存任务. 文件和间隔未变化时保持运行中的任务，不重置计时
+func (c *Cache) startAutoSave() {
+	filename, interval := c.opt.AutoSaveFile, c.opt.AutoSaveInterval
+	if filename == "" || interval <= 0 {
+		filename, interval = "", 0
+	}
+
+	c.saveTaskMu.Lock()
+	defer c.saveTaskMu.Unlock()
+	if c.saveStop != nil && c.saveTo == filename && c.saveEvery == interval {
+		return
+	}
+
+	c.stopAutoSaveLocked()
+	if filename == "" {
+		return
+	}
+
+	c.saveMu.Lock()
+	c.autoSaveStartAt = time.Now()
+	c.saveMu.Unlock()
+
+	c.saveTo, c.saveEvery = filename, interval
+	c.saveStop = c.scheduler().Every(interval, func() {
+		// 错误记录在 Stats 中
+		_ = c.SaveFile(filename)
+	})
+}
+
+// stopAutoSave 停止自动保存任务
+func (c *Cache) stopAutoSave() {
+	c.saveTaskMu.Lock()
+	defer c.saveTaskMu.Unlock()
+	c.stopAutoSaveLocked()
+}
+
+// stopAutoSaveLocked 停止自动保存任务 (需持有 saveTaskMu)
+func (c *Cache) stopAutoSaveLocked() {
+	if c.saveStop != nil {
+		c.saveStop()
+		c.saveStop = nil
+	}
+}
+
+// scheduler 获取配置的调度器，默认为每个任务启动一个 goroutine
+func (c *Cache) scheduler() Scheduler {
+	if c.opt.Scheduler != nil {
+		return c.opt.Scheduler
+	}
+	return goScheduler{}
+}
@@ -0,0 +1,91 @@
+package lcache_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_WithAutoSave(t *testing.T) {
+	filename := t.TempDir() + "/auto_cache.json"
+	c := lcache.New(lcache.WithAutoSave(filename, 50*time.Millisecond))
+	c.Set("key1", "value1", 0)
+	assert.True(t, c.Stats().LastSaveAt.IsZero())
+
+	time.Sleep(120 * time.Millisecond)
+	st := c.Stats()
+	assert.False(t, st.LastSaveAt.IsZero())
+	assert.NoErr(t, st.LastSaveErr)
+	assert.True(t, fsutil.FileExists(filename))
+
+	// stop auto-save
+	c.Configure(lcache.WithAutoSave("", 0))
+	c2 := lcache.New()
+	assert.NoErr(t, c2.LoadFile(filename))
+	assert.Eq(t, "value1", c2.Val("key1"))
+}
+
+func TestCache_Close(t *testing.T) {
+	filename := t.TempDir() + "/close_cache.json"
+	c := lcache.New(lcache.WithAutoSave(filename, time.Hour))
+	c.Set("key1", "value1", 0)
+	assert.False(t, fsutil.FileExists(filename))
+
+	// final flush on close
+	assert.NoErr(t, c.Close())
+	assert.True(t, fsutil.FileExists(filename))
+	assert.False(t, c.Stats().LastSaveAt.IsZero())
+
+	// without auto-save
+	assert.NoErr(t, lcache.New().Close())
+}
+
+// countScheduler counts the started and stopped jobs, never runs them
+type countScheduler struct {
+	mu      sync.Mutex
+	started int
+	stopped int
+}
+
+func (s *countScheduler) Every(time.Duration, func()) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started++
+	return func() {
+		s.mu.Lock()
+		s.stopped++
+		s.mu.Unlock()
+	}
+}
+
+func (s *countScheduler) After(time.Duration, func()) func() { return func() {} }
+
+func TestCache_WithAutoSave_restart(t *testing.T) {
+	s := &countScheduler{}
+	filename := t.TempDir() + "/auto_cache.json"
+	c := lcache.New(lcache.WithScheduler(s), lcache.WithAutoSave(filename, time.Minute))
+	assert.Eq(t, 1, s.started)
+
+	// not restart if the file and interval are not changed
+	c.Configure(lcache.WithCapacity(100))
+	c.Configure(lcache.WithAutoSave(filename, time.Minute))
+	assert.Eq(t, 1, s.started)
+	assert.Eq(t, 0, s.stopped)
+
+	c.Configure(lcache.WithAutoSave(filename, time.Hour))
+	assert.Eq(t, 2, s.started)
+	assert.Eq(t, 1, s.stopped)
+
+	// stop the tasks of the old default instance
+	lcache.SetStd(c)
+	assert.Eq(t, 1, s.stopped)
+	old := lcache.SetStd(lcache.New())
+	assert.True(t, old == c)
+	assert.Eq(t, 2, s.stopped)
+	lcache.Reset()
+	assert.NotEq(t, c, lcache.Std())
+}
@@ -0,0 +1,446 @@
+// Package boltstore provides a bbolt backed lcache.Store, for persist the cache
+// incrementally to a single file with transactions, instead of rewriting the full
+// snapshot by SaveFile.
+//
+// It is a separate module to avoid adding the bbolt dependency to lcache.
+//
+// Usage:
+//
+//	s, err := boltstore.Open("data/cache.db")
+//	defer s.Close()
+//
+//	c := lcache.New(lcache.WithStore(s), lcache.WithWriteBehind(time.Second, 1000, nil))
+//	// warm up the cache by the valid items in the file
+//	n, err := s.RestoreTo(c)
+//
+// It also implements the lcache.PersistProvider and lcache.OpAppender, the writes of the
+// cache are mirrored to the file, and the snapshots of SaveFile are saved in it:
+//
+//	c := lcache.New(lcache.WithPersistProvider(s))
+//	n, err := s.RestoreTo(c)
+package boltstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Options for the store
+type Options struct {
+	// Bucket name of the bucket to save the items. default is "lcache"
+	//
+	// The snapshots of SaveFile are saved in the bucket "<Bucket>.snapshots"
+	Bucket string
+	// Serializer name of the registered lcache serializer to encode the values. default is "json"
+	Serializer string
+	// Timeout wait for the file lock on Open, 0 to wait indefinitely. default is 3s
+	Timeout time.Duration
+	// NoSync skip fsync after each commit, faster but may lose the recent writes on
+	// system crash. see bolt.DB.NoSync
+	NoSync bool
+}
+
+// OptionFn option func for Open and New
+type OptionFn func(o *Options)
+
+// WithBucket set the bucket name, the caches can share a file by different buckets.
+func WithBucket(name string) OptionFn {
+	return func(o *Options) { o.Bucket = name }
+}
+
+// WithSerializer set the serializer to encode the values, it must be registered in
+// lcache. see lcache.SetSerializer
+func WithSerializer(name string) OptionFn {
+	return func(o *Options) { o.Serializer = name }
+}
+
+// WithTimeout set the timeout to wait for the file lock on Open
+func WithTimeout(timeout time.Duration) OptionFn {
+	return func(o *Options) { o.Timeout = timeout }
+}
+
+// WithNoSync skip fsync after each commit
+func WithNoSync() OptionFn {
+	return func(o *Options) { o.NoSync = true }
+}
+
+// record 保存的数据. E 过期时间 unix 毫秒，0 为永不过期; T 注册的类型名称
+type record struct {
+	V any    `json:"v"`
+	E int64  `json:"e,omitempty"`
+	T string `json:"t,omitempty"`
+}
+
+// Store the bbolt backed store, implements the lcache.Store, lcache.PersistProvider and
+// lcache.OpAppender. It is goroutine-safe.
+type Store struct {
+	db     *bolt.DB
+	opt    Options
+	bucket []byte
+	// snapBucket 保存快照的 bucket
+	snapBucket []byte
+	ser        lcache.Serializer
+	// ownDB 由 Open 打开的 db，Close 时关闭
+	ownDB bool
+}
+
+// Open the bbolt file and create the store, the file is created if not exists.
+// The file is locked until Close, only one process can open it.
+func Open(path string, optFns ...OptionFn) (*Store, error) {
+	opt := newOptions(optFns)
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: opt.Timeout, NoSync: opt.NoSync})
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := newStore(db, opt)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	s.ownDB = true
+	return s, nil
+}
+
+// New create the store by an opened db, the db is not closed by Close.
+// The options Timeout and NoSync are ignored.
+func New(db *bolt.DB, optFns ...OptionFn) (*Store, error) {
+	return newStore(db, newOptions(optFns))
+}
+
+func newOptions(optFns []OptionFn) Options {
+	opt := Options{Bucket: "lcache", Serializer: "json", Timeout: 3 * time.Second}
+	for _, fn := range optFns {
+		fn(&opt)
+	}
+	return opt
+}
+
+func newStore(db *bolt.DB, opt Options) (*Store, error) {
+	ser, ok := lcache.GetSerializer(opt.Serializer)
+	if !ok {
+		return nil, fmt.Errorf("boltstore: not registered serializer: %s", opt.Serializer)
+	}
+
+	s := &Store{db: db, opt: opt, bucket: []byte(opt.Bucket), snapBucket: []byte(opt.Bucket + ".snapshots"), ser: ser}
+	err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(s.bucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(s.snapBucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// DB get the bbolt db
+func (s *Store) DB() *bolt.DB { return s.db }
+
+// Options get the options of the store
+func (s *Store) Options() Options { return s.opt }
+
+// Load value by key, returns lcache.ErrNotFound if the key does not exist or expired.
+// implements lcache.Store
+func (s *Store) Load(_ context.Context, key string) (any, error) {
+	var data []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		// 数据仅在事务内有效，需要复制
+		data = bytes.Clone(tx.Bucket(s.bucket).Get([]byte(key)))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, lcache.ErrNotFound
+	}
+
+	rec, err := s.decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if rec.expired(time.Now().UnixMilli()) {
+		return nil, lcache.ErrNotFound
+	}
+	return rec.V, nil
+}
+
+// Save value by key, ttl <= 0 means never expire. implements lcache.Store
+func (s *Store) Save(_ context.Context, key string, val any, ttl time.Duration) error {
+	data, err := s.encode(val, ttl)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).Put([]byte(key), data)
+	})
+}
+
+// SaveMany save the items with the same ttl in one transaction
+func (s *Store) SaveMany(_ context.Context, items map[string]any, ttl time.Duration) error {
+	encoded := make(map[string][]byte, len(items))
+	for key, val := range items {
+		data, err := s.encode(val, ttl)
+		if err != nil {
+			return fmt.Errorf("boltstore: encode key %s: %w", key, err)
+		}
+		encoded[key] = data
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		for key, data := range encoded {
+			if err := b.Put([]byte(key), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete value by key, returns lcache.ErrNotFound if the key does not exist.
+// implements lcache.Store
+func (s *Store) Delete(_ context.Context, key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		if b.Get([]byte(key)) == nil {
+			return lcache.ErrNotFound
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+// Len get the number of the saved items, include the expired but not purged.
+func (s *Store) Len() (n int) {
+	_ = s.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(s.bucket).Stats().KeyN
+		return nil
+	})
+	return
+}
+
+// Range iterate the valid items in key order, stop if fn returns false.
+// exp is zero for never expire.
+//
+// NOTE: fn is called in a read transaction, should not write to the store in it.
+func (s *Store) Range(fn func(key string, val any, exp time.Time) bool) error {
+	nowUm := time.Now().UnixMilli()
+	return s.db.View(func(tx *bolt.Tx) error {
+		cur := tx.Bucket(s.bucket).Cursor()
+		for k, v := cur.First(); k != nil; k, v = cur.Next() {
+			rec, err := s.decode(v)
+			if err != nil {
+				return fmt.Errorf("boltstore: decode key %s: %w", k, err)
+			}
+			if rec.expired(nowUm) {
+				continue
+			}
+
+			var exp time.Time
+			if rec.E > 0 {
+				exp = time.UnixMilli(rec.E)
+			}
+			if !fn(string(k), rec.V, exp) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// RestoreTo import the valid items to the cache with their original expiration, returns the
+// number of the imported items. The existing valid items in the cache are kept, and the items
+// are written to the local cache only, not to the Store. see lcache.Cache.Import
+//
+// The keys contain the namespace prefix, so restore to the root cache, not a namespace view.
+func (s *Store) RestoreTo(c *lcache.Cache) (int, error) {
+	entries := make(map[string]lcache.Entry)
+	err := s.Range(func(key string, val any, exp time.Time) bool {
+		entries[key] = lcache.Entry{Value: val, ExpiresAt: exp}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	return c.Import(entries, false), nil
+}
+
+// Purge delete the expired items, returns the number of the deleted items.
+// eg: call it periodically by lcache.Scheduler
+func (s *Store) Purge() (n int, err error) {
+	nowUm := time.Now().UnixMilli()
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		// 遍历时删除会使游标跳过数据，先收集再删除
+		var keys [][]byte
+		err := b.ForEach(func(k, v []byte) error {
+			if rec, err := s.decode(v); err == nil && rec.expired(nowUm) {
+				keys = append(keys, bytes.Clone(k))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range keys {
+			if err = b.Delete(k); err != nil {
+				return err
+			}
+		}
+		n = len(keys)
+		return nil
+	})
+	return n, err
+}
+
+// Snapshot save the snapshot of the name. implements lcache.PersistProvider
+func (s *Store) Snapshot(name string, write func(w io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.snapBucket).Put([]byte(name), buf.Bytes())
+	})
+}
+
+// Restore read the snapshot of the name, returns an error matched fs.ErrNotExist if
+// it does not exist. implements lcache.PersistProvider
+func (s *Store) Restore(name string, read func(r io.Reader) error) error {
+	var data []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data = bytes.Clone(tx.Bucket(s.snapBucket).Get([]byte(name)))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return fmt.Errorf("boltstore: snapshot %s: %w", name, fs.ErrNotExist)
+	}
+	return read(bytes.NewReader(data))
+}
+
+// AppendOp apply the write operation of the cache to the items. implements lcache.OpAppender
+//
+// NOTE: each operation is committed in a transaction under the cache lock, consider
+// WithNoSync for the write-heavy caches.
+func (s *Store) AppendOp(op lcache.PersistOp) error {
+	var data []byte
+	if op.Kind == lcache.OpSet {
+		var err error
+		if data, err = s.ser.Encode(&record{V: op.Val, E: op.Exp, T: lcache.TypeName(op.Val)}); err != nil {
+			return err
+		}
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		switch op.Kind {
+		case lcache.OpSet:
+			return tx.Bucket(s.bucket).Put([]byte(op.Key), data)
+		case lcache.OpDel:
+			return tx.Bucket(s.bucket).Delete([]byte(op.Key))
+		case lcache.OpClear, lcache.OpGen:
+			// 代数递增后之前写入的数据全部失效
+			return s.clear(tx)
+		case lcache.OpRename:
+			newNs, _ := op.Val.(string)
+			return s.rename(tx, op.Key, newNs)
+		}
+		return nil
+	})
+}
+
+// rename 将前缀为 oldNs 的 key 改为 newNs 前缀，与缓存一致先删除 newNs 中已有的 key
+func (s *Store) rename(tx *bolt.Tx, oldNs, newNs string) error {
+	b := tx.Bucket(s.bucket)
+	oldKeys, vals := prefixed(b, []byte(oldNs), true)
+	newKeys, _ := prefixed(b, []byte(newNs), false)
+
+	for _, k := range append(newKeys, oldKeys...) {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	for i, k := range oldKeys {
+		newKey := newNs + strings.TrimPrefix(string(k), oldNs)
+		if err := b.Put([]byte(newKey), vals[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prefixed 获取前缀为 prefix 的 key 和值(withVal 为 true 时)的副本
+func prefixed(b *bolt.Bucket, prefix []byte, withVal bool) (keys, vals [][]byte) {
+	cur := b.Cursor()
+	for k, v := cur.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cur.Next() {
+		keys = append(keys, bytes.Clone(k))
+		if withVal {
+			vals = append(vals, bytes.Clone(v))
+		}
+	}
+	return
+}
+
+// Clear delete all items in the bucket
+func (s *Store) Clear() error {
+	return s.db.Update(s.clear)
+}
+
+func (s *Store) clear(tx *bolt.Tx) error {
+	if err := tx.DeleteBucket(s.bucket); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+		return err
+	}
+	_, err := tx.CreateBucket(s.bucket)
+	return err
+}
+
+// Close the store, the db is closed if it is opened by Open.
+func (s *Store) Close() error {
+	if s.ownDB {
+		return s.db.Close()
+	}
+	return nil
+}
+
+func (s *Store) encode(val any, ttl time.Duration) ([]byte, error) {
+	var exp int64
+	if ttl > 0 {
+		exp = time.Now().Add(ttl).UnixMilli()
+	}
+	return s.ser.Encode(&record{V: val, E: exp, T: lcache.TypeName(val)})
+}
+
+func (s *Store) decode(data []byte) (*record, error) {
+	rec := &record{}
+	if err := s.ser.Decode(data, rec); err != nil {
+		return nil, err
+	}
+
+	val, err := lcache.RestoreType(rec.T, rec.V)
+	if err != nil {
+		return nil, err
+	}
+	rec.V = val
+	return rec, nil
+}
+
+func (r *record) expired(nowUm int64) bool {
+	return r.E > 0 && r.E <= nowUm
+}
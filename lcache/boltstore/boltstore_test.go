@@ -0,0 +1,162 @@
+package boltstore_test
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/lcache/boltstore"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+var (
+	_ lcache.Store      = (*boltstore.Store)(nil)
+	_ lcache.RangeStore = (*boltstore.Store)(nil)
+)
+
+type user struct {
+	ID   int
+	Name string
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	s, err := boltstore.Open(filepath.Join(t.TempDir(), "cache.db"))
+	assert.NoErr(t, err)
+	defer s.Close()
+
+	_, err = s.Load(ctx, "none")
+	assert.ErrIs(t, err, lcache.ErrNotFound)
+	assert.ErrIs(t, s.Delete(ctx, "none"), lcache.ErrNotFound)
+
+	assert.NoErr(t, s.Save(ctx, "key1", "val1", 0))
+	assert.NoErr(t, s.Save(ctx, "key2", "val2", 20*time.Millisecond))
+	val, err := s.Load(ctx, "key1")
+	assert.NoErr(t, err)
+	assert.Eq(t, "val1", val)
+	assert.Eq(t, 2, s.Len())
+
+	time.Sleep(30 * time.Millisecond)
+	_, err = s.Load(ctx, "key2")
+	assert.ErrIs(t, err, lcache.ErrNotFound)
+
+	var keys []string
+	assert.NoErr(t, s.Range(func(key string, val any, exp time.Time) bool {
+		keys = append(keys, key)
+		return true
+	}))
+	assert.Eq(t, []string{"key1"}, keys)
+
+	n, err := s.Purge()
+	assert.NoErr(t, err)
+	assert.Eq(t, 1, n)
+	assert.Eq(t, 1, s.Len())
+
+	assert.NoErr(t, s.Delete(ctx, "key1"))
+	assert.Eq(t, 0, s.Len())
+
+	assert.NoErr(t, s.SaveMany(ctx, map[string]any{"a": 1, "b": 2}, time.Hour))
+	assert.Eq(t, 2, s.Len())
+	assert.NoErr(t, s.Clear())
+	assert.Eq(t, 0, s.Len())
+}
+
+func TestStore_cache(t *testing.T) {
+	lcache.RegisterType[user]("boltstore_test.user")
+	file := filepath.Join(t.TempDir(), "cache.db")
+
+	s, err := boltstore.Open(file, boltstore.WithBucket("users"))
+	assert.NoErr(t, err)
+	c := lcache.New(lcache.WithStore(s))
+	users := c.Namespace("users")
+	users.Set("1", user{ID: 1, Name: "tom"}, time.Hour)
+	c.Set("count", 2, 0)
+	users.Delete("2")
+	c.Close()
+	assert.NoErr(t, s.Close())
+
+	// reopen and restore
+	s, err = boltstore.Open(file, boltstore.WithBucket("users"))
+	assert.NoErr(t, err)
+	defer s.Close()
+
+	c = lcache.New(lcache.WithStore(s))
+	defer c.Close()
+	n, err := s.RestoreTo(c)
+	assert.NoErr(t, err)
+	assert.Eq(t, 2, n)
+
+	assert.Eq(t, user{ID: 1, Name: "tom"}, c.Namespace("users").Val("1"))
+	assert.Eq(t, 2.0, c.Val("count"))
+	ttl, ok := c.Namespace("users").TTL("1")
+	assert.True(t, ok)
+	assert.Gt(t, ttl, 50*time.Minute)
+
+	// read-through from the store
+	c.Clear()
+	assert.Eq(t, user{ID: 1, Name: "tom"}, c.Namespace("users").Val("1"))
+}
+
+func TestOpen_error(t *testing.T) {
+	_, err := boltstore.Open(filepath.Join(t.TempDir(), "cache.db"), boltstore.WithSerializer("none"))
+	assert.ErrMsg(t, err, "boltstore: not registered serializer: none")
+
+	file := filepath.Join(t.TempDir(), "cache.db")
+	s, err := boltstore.Open(file)
+	assert.NoErr(t, err)
+	defer s.Close()
+
+	// the file is locked
+	_, err = boltstore.Open(file, boltstore.WithTimeout(50*time.Millisecond))
+	assert.Err(t, err)
+}
+
+var (
+	_ lcache.PersistProvider = (*boltstore.Store)(nil)
+	_ lcache.OpAppender      = (*boltstore.Store)(nil)
+)
+
+func TestStore_persistProvider(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cache.db")
+	s, err := boltstore.Open(file, boltstore.WithNoSync())
+	assert.NoErr(t, err)
+	defer s.Close()
+
+	c := lcache.New(lcache.WithPersistProvider(s))
+	c.Set("key1", "val1", 0)
+	c.Set("key2", "val2", time.Hour)
+	c.Namespace("users").Set("1", "tom", 0)
+	c.Namespace("members").Set("2", "old", 0)
+	c.Delete("key2")
+	n, err := c.RenameNamespace("users", "members")
+	assert.NoErr(t, err)
+	assert.Eq(t, 1, n)
+	assert.NoErr(t, c.SaveFile("snap1"))
+
+	// the writes are mirrored to the items
+	var keys []string
+	assert.NoErr(t, s.Range(func(key string, val any, exp time.Time) bool {
+		keys = append(keys, key)
+		return true
+	}))
+	assert.Eq(t, []string{"key1", "members:1"}, keys)
+
+	c2 := lcache.New()
+	n, err = s.RestoreTo(c2)
+	assert.NoErr(t, err)
+	assert.Eq(t, 2, n)
+	assert.Eq(t, "tom", c2.Namespace("members").Val("1"))
+
+	// load the snapshot
+	c3 := lcache.New(lcache.WithPersistProvider(s))
+	assert.NoErr(t, c3.LoadFile("snap1"))
+	assert.Eq(t, "val1", c3.Val("key1"))
+	assert.Eq(t, "tom", c3.Val("members:1"))
+	assert.ErrIs(t, c3.LoadFile("none"), fs.ErrNotExist)
+
+	c.Clear()
+	assert.Eq(t, 0, s.Len())
+}
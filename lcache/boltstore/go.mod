@@ -0,0 +1,17 @@
+module github.com/gookit/ext/lcache/boltstore
+
+go 1.23
+
+require (
+	github.com/gookit/ext v0.0.0
+	github.com/gookit/goutil v0.8.0
+	go.etcd.io/bbolt v1.3.11
+)
+
+require (
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/term v0.29.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+)
+
+replace github.com/gookit/ext => ../..
@@ -2,13 +2,14 @@ package lcache
 
 import (
 	"container/list"
-	"errors"
+	"context"
+	"encoding/json"
+	"math"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
-
-	"github.com/gookit/goutil/fsutil"
-	"github.com/gookit/goutil/x/stdio"
 )
 
 // Item represents a cached item with expiration
@@ -16,6 +17,36 @@ type Item struct {
 	Val any `json:"v"`
 	// 过期时间 millitime. 0表示永不过期
 	Exp int64 `json:"e"`
+	// Gen 写入时缓存的代数(generation)，低于当前代数的数据视为失效
+	Gen uint64 `json:"g,omitempty"`
+	// Key 原始 key. 仅在开启 key hashing 冲突检查时记录
+	Key string `json:"k,omitempty"`
+	// Typ 值的类型名称. 仅在值的类型已通过 RegisterType 注册时，保存快照时记录
+	Typ string `json:"t,omitempty"`
+	// Hits 保存快照时记录的命中次数，加载时恢复到 hits
+	Hits uint64 `json:"h,omitempty"`
+	// Grp 所属分组的名称，保存快照时记录，加载时恢复到 grp. see DefineGroup
+	Grp string `json:"gp,omitempty"`
+	// grp 所属的分组
+	grp *Group
+	// ttl 写入时的 TTL(毫秒)，仅在开启后台刷新时记录，不持久化
+	ttl int64
+	// hits 写入后的命中次数，保存快照时记录到 Hits. see HotKeys
+	//
+	// NOTE: 关闭 LRU 时会在读锁下更新，需要使用原子操作读写
+	hits uint64
+	// onEvict 数据项自身的淘汰回调，不持久化. see SetWithOnEvict
+	onEvict func(key string, value any)
+	// tags 标签, cost 成本, prio 淘汰优先级. 仅通过 SetX 设置，不持久化. see WithTags, WithCost, WithPriority
+	tags []string
+	cost int64
+	prio Priority
+	// created 写入时间 millitime, access 最后命中时间 millitime(原子操作读写). 不持久化. see Inspect
+	created, access int64
+	// live 是否已计入有效数量，不持久化. see WithAccurateLen
+	live bool
+	// ref CLOCK 模式下被 Get 访问的标记(原子操作读写)，淘汰时清除. see LRUClock
+	ref uint32
 }
 
 // isExpired 检查是否已过期
@@ -35,6 +66,13 @@ func (i *Item) isExpired1(nowUm int64) bool {
 
 // Cache represents a thread-safe local cache with TTL support
 type Cache struct {
+	*core
+	// ns 命名空间视图的 key 前缀. see Namespace
+	ns string
+}
+
+// core 缓存的共享数据，命名空间视图与根缓存共用
+type core struct {
 	opt Options
 	mu  sync.RWMutex // 读写锁
 	// 存储 key-value map
@@ -42,59 +80,615 @@ type Cache struct {
 	// LRU 链表管理访问顺序
 	lruList *list.List
 	lruMap  map[string]*list.Element // LRU 链表节点索引，用于快速删除
+	// 当前缓存代数. 通过 BumpGeneration 递增，使之前写入的数据全部失效
+	gen uint64
+	// 逻辑分组. see DefineGroup
+	groups map[string]*Group
+	// 二级索引: index name -> index value -> keys. see WithIndex
+	indexes map[string]map[string]map[string]struct{}
+	// 每个 key 已建立的索引值: key -> index name -> index value. 用于删除时更新索引
+	idxVals map[string]map[string]string
+	// 按写入顺序排列的 key 槽位，用于 Scan 游标迭代. 删除的 key 留下空洞，空洞过多时压缩
+	slots   []string
+	slotIdx map[string]int
+	// 空洞数量和压缩次数
+	slotHoles int
+	slotEpoch uint64
+	// 最近几次压缩的槽位映射，用于将旧的游标转换到压缩后的位置
+	slotRemaps []slotRemap
+
+	// 按 key 加锁的锁分片. see LockKey
+	keyLocks [keyLockStripes]sync.Mutex
+
+	// write-behind 队列: store key -> 待写入数据. see WithWriteBehind
+	wbMu       sync.Mutex
+	wbQueue    map[string]*wbEntry
+	wbFlight   map[string]*wbEntry // 正在刷新，尚未写入完成的数据
+	wbStop     func()
+	wbFlushing atomic.Bool
+	wbFlushMu  sync.Mutex
+
+	// 正在进行中的加载调用，按 key 去重. see GetOrLoad
+	flightMu sync.Mutex
+	flights  map[string]*flight
+	// 加载调用的统计. see LoadStats
+	loadSt loadStats
+
+	// 使用 GobSerializer 时在 Set 自动注册值的类型. see GobRegister
+	gobAuto bool
+
+	// 自动保存任务: saveStop 停止任务，saveTo 和 saveEvery 为运行中任务的配置. 使用 saveTaskMu 保护
+	saveTaskMu sync.Mutex
+	saveStop   func()
+	saveTo     string
+	saveEvery  time.Duration
+	// setEvents 持有写锁期间的写入，释放锁后调用 OnSet 回调. see unlock
+	setEvents []setEvent
+	// append-only 日志文件. see WithAOF
+	// aofName 为文件路径，重写后文件句柄的 Name() 为临时文件名
+	aofFile *os.File
+	aofName string
+	aofEnc  *json.Encoder
+	aofErr  error
+	// aofSize AOF 文件的大小; aofBase 上次重写(或打开)后的大小; aofRewriting 是否正在后台重写
+	aofSize      *countWriter
+	aofBase      int64
+	aofRewriting atomic.Bool
+
+	// Get 命中统计
+	hits, misses atomic.Uint64
+	// 容量或成本超出限制时淘汰的数据项数量
+	evictions atomic.Uint64
+	// 固定的数据项的 key，不会被容量或成本限制淘汰. 删除数据项时一并删除. see Pin
+	pinned map[string]struct{}
+	// 各优先级的数据项数量，淘汰时跳过没有数据的优先级. see WithPriority
+	prioLen [len(evictOrder)]int
+	// 获取锁需要等待的次数和总时间(纳秒)
+	lockWaits  atomic.Uint64
+	lockWaitNs atomic.Int64
+	// 获取锁超时的次数. see WithLockTimeout
+	lockTimeouts atomic.Uint64
+	// 所有数据项的成本总和. see WithCost
+	totalCost int64
+	// 是否统计有效数量; liveN 有效数据的数量; expHeap 有效数据的过期时间堆. see WithAccurateLen
+	liveOn  bool
+	liveN   int
+	expHeap expHeap
+	// pruneNext 最早可能有数据过期的时间 millitime，此前无需为腾出空间扫描过期数据. 0 为未知. see pruneForRoom
+	pruneNext int64
+	// 是否已冻结为只读. see Freeze
+	frozen atomic.Bool
+	// Get 是否只标记访问，淘汰时给予第二次机会. 在加锁前读取，Configure 时更新. see LRUClock
+	clock atomic.Bool
+	// 获取锁的超时时间，在加锁前读取，Configure 时更新. see WithLockTimeout
+	lockTimeout atomic.Int64
+	// 记录不存在的 key，在加载前检查. see WithMissFilter
+	missFilter atomic.Pointer[missFilter]
+
+	// 异步调用的删除回调队列. see WithAsyncCallbacks
+	cbMu      sync.Mutex
+	cbRunning bool
+	cbQueue   chan removedEvent
+	cbStop    chan struct{}
+	cbDone    chan struct{}
+	// 队列已满丢弃的回调数量
+	cbDrops atomic.Uint64
+	// 停止定时清理任务，使用 janTaskMu 保护. see WithJanitor, Ring.StartJanitors
+	janTaskMu   sync.Mutex
+	janitorStop func()
+	// 停止容量调整任务. see WithAdaptiveCapacity
+	adaptStop func()
+	// 再次缩小容量前需要完成的 GC 次数，等待上次淘汰的效果体现到内存用量. see adaptCapacity
+	adaptGC uint64
+	// 清理任务上次执行的时间 millitime，启动时设置为预计首次执行的时间. see HealthCheck
+	janRunAt atomic.Int64
+	// 上次执行清理任务时的统计，用于计算告警指标
+	janMu     sync.Mutex
+	janLast   janitorSample
+	janAlerts [alertKindCount]bool
+	// 保存状态信息，使用独立的锁. SaveFile 只持有读锁
+	saveMu      sync.Mutex
+	lastSaveAt  time.Time
+	lastSaveErr error
+	// 自动保存任务的启动时间. see HealthCheck
+	autoSaveStartAt time.Time
+}
+
+// Stats represents a snapshot of the cache statistics
+type Stats struct {
+	// Len number of items in the cache, see Cache.Len
+	Len int
+	// Capacity the effective capacity, adjusted by WithAdaptiveCapacity
+	Capacity int
+	// ValidLen number of valid items in the cache, see Cache.ValidLen
+	ValidLen int
+	// Generation current cache generation
+	Generation uint64
+	// LastSaveAt last time of SaveFile called(manual or auto-save)
+	LastSaveAt time.Time
+	// LastSaveErr error of the last SaveFile call
+	LastSaveErr error
+	// AOFErr the last error of append to AOF file, open or rewrite it
+	AOFErr error
+	// AOFSize the size of the AOF file in bytes. see WithAOF
+	AOFSize int64
+	// Hits number of Get calls that found the item(include stale)
+	Hits uint64
+	// Misses number of Get calls that missed the item
+	Misses uint64
+	// Cost total cost of the items set by SetX with WithCost
+	Cost int64
+	// Evictions number of items evicted by the Capacity or MaxCost limit
+	Evictions uint64
+	// Pinned number of the items pinned by Cache.Pin
+	Pinned int
+	// LockWaits number of the cache operations that had to wait for the lock
+	LockWaits uint64
+	// LockWaitTime total time of waiting for the lock, see AvgLockWait
+	LockWaitTime time.Duration
+	// LockTimeouts number of the cache operations that gave up by the lock timeout.
+	// The writes without an error result(eg: Set, Delete) are dropped. see WithLockTimeout
+	LockTimeouts uint64
+	// CallbackDrops number of the removed items whose callbacks were dropped by the full
+	// async callbacks queue. see WithAsyncCallbacks
+	CallbackDrops uint64
+}
+
+// AvgLockWait get the average time of waiting for the lock when contended, 0 if never waited.
+func (s Stats) AvgLockWait() time.Duration {
+	if s.LockWaits > 0 {
+		return s.LockWaitTime / time.Duration(s.LockWaits)
+	}
+	return 0
+}
+
+// HitRatio get the ratio of hits in all Get calls, 0 if no calls.
+func (s Stats) HitRatio() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+	return 0
 }
 
 // New create a new cache instance with options
 func New(optFns ...OptionFn) *Cache {
-	c := &Cache{
+	return newCache().Configure(optFns...)
+}
+
+// NewE create a new cache instance with options, returns an error if the options are invalid.
+// eg: capacity <= 0 with LRU enabled, or the serializer is not registered.
+func NewE(optFns ...OptionFn) (*Cache, error) {
+	c := newCache()
+	for _, optFn := range optFns {
+		optFn(&c.opt)
+	}
+	if err := c.opt.validate(); err != nil {
+		return nil, err
+	}
+	return c.Configure(), nil
+}
+
+// newCache 创建使用默认选项的缓存实例
+func newCache() *Cache {
+	return &Cache{core: &core{
 		items:   make(map[string]*Item),
 		lruList: list.New(),
 		lruMap:  make(map[string]*list.Element),
+		slotIdx: make(map[string]int),
 		// options
 		opt: Options{
 			Capacity:   1000,
 			Serializer: "json",
 		},
+	}}
+}
+
+// Configure the cache instance with options, it is safe to call while the cache is in use.
+//
+// The options are applied holding the write lock, so they do not race with in-flight operations.
+//
+// NOTE: the options called outside the lock(eg: KeyFunc, Store, OnSet and the other callbacks)
+// should be set before the cache is in use. It panics on a namespace view, the options are shared
+// with the parent cache, configure the parent instead.
+func (c *Cache) Configure(optFns ...OptionFn) *Cache {
+	if c.ns != "" {
+		panic("lcache: can not configure the namespace view: " + c.ns)
 	}
 
-	return c.Configure(optFns...)
+	c.configure(optFns)
+	c.startAutoSave()
+	c.startWriteBehind()
+	c.startCallbacks()
+	c.startJanitor()
+	c.startAdaptive()
+	if c.opt.Frozen {
+		c.Freeze()
+	}
+	return c
 }
 
-// Configure the cache instance with options.
-func (c *Cache) Configure(optFns ...OptionFn) *Cache {
+// configure 加锁应用选项
+func (c *Cache) configure(optFns []OptionFn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	for _, optFn := range optFns {
 		optFn(&c.opt)
 	}
-	return c
+	if c.opt.Generation > c.gen {
+		c.gen = c.opt.Generation
+	}
+
+	// StaleGrace 可能已变化
+	c.pruneNext = 0
+	c.gobAuto = c.isGob()
+	c.lockTimeout.Store(int64(c.opt.LockTimeout))
+	c.clock.Store(!c.opt.DisableLRU && c.opt.LRUMode.clock())
+	if c.opt.AccurateLen != c.liveOn {
+		c.liveOn = c.opt.AccurateLen
+		c.rebuildLive()
+	}
+	// 过滤器的配置变更时重新创建
+	if mf := c.missFilter.Load(); mf == nil || mf.capacity != c.opt.MissFilterSize ||
+		mf.fpRate != c.opt.MissFilterFPRate || mf.rotate != c.opt.MissFilterRotate {
+		c.initMissFilter()
+	}
+	c.openAOF()
+}
+
+// Stats get the statistics snapshot of the cache.
+//
+// NOTE: count the ValidLen will traverse all data, the time complexity is O(N)
+func (c *Cache) Stats() Stats {
+	c.mu.RLock()
+	st := Stats{
+		Len:        len(c.items),
+		Capacity:   c.opt.Capacity,
+		ValidLen:   c.validLen(""),
+		Generation: c.gen,
+		AOFErr:     c.aofErr,
+		AOFSize:    c.aofLen(),
+		Hits:       c.hits.Load(),
+		Misses:     c.misses.Load(),
+		Cost:       c.totalCost,
+		Evictions:  c.evictions.Load(),
+		Pinned:     len(c.pinned),
+		LockWaits:  c.lockWaits.Load(),
+	}
+	st.LockWaitTime = time.Duration(c.lockWaitNs.Load())
+	st.LockTimeouts = c.lockTimeouts.Load()
+	st.CallbackDrops = c.cbDrops.Load()
+	c.mu.RUnlock()
+
+	c.saveMu.Lock()
+	st.LastSaveAt, st.LastSaveErr = c.lastSaveAt, c.lastSaveErr
+	c.saveMu.Unlock()
+	return st
+}
+
+// Generation get the current cache generation
+func (c *Cache) Generation() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.gen
+}
+
+// BumpGeneration increments the cache generation and returns the new value.
+//
+// All items written before the call are treated as misses from now on, the new
+// generation is persisted by SaveFile and recorded in the AOF, so the invalidation
+// also survives restarts.
+func (c *Cache) BumpGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return c.gen
+	}
+	c.setGen(c.gen + 1)
+	_ = c.appendAOF(aofOpGen, "", c.gen, 0)
+	return c.gen
+}
+
+// setGen 设置缓存代数，之前写入的数据全部失效 (不加锁)
+func (c *Cache) setGen(gen uint64) {
+	c.gen, c.pruneNext = gen, 0
+	if c.liveOn {
+		c.rebuildLive()
+	}
+}
+
+// invalid 检查数据是否已过期或属于旧的代数
+func (c *Cache) invalid(it *Item, nowUm int64) bool {
+	return it.Gen < c.gen || it.isExpired1(nowUm)
 }
 
 // Set adds an item to the cache with a specified duration.
 // If duration <= 0, the item will never Exp.
+//
+// The errors are ignored, use SetE to get them. eg: the write is dropped if the lock
+// cannot be acquired in time, it is counted in Stats.LockTimeouts. see WithLockTimeout
 func (c *Cache) Set(key string, value any, ttl time.Duration) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	_ = c.SetE(key, value, ttl)
+}
+
+// SetE like Set, but returns an error if the item cannot be written.
+//
+// Returns ErrBusy if the lock cannot be acquired in time. see WithLockTimeout
+// Returns ErrFrozen if the cache is frozen. see Freeze
+// Returns a *SizeError if the key or value exceeds the size limit. see WithMaxValueBytes
+//
+// With Store configured, writes through to the store first, returns the store error. see WithStore
+func (c *Cache) SetE(key string, value any, ttl time.Duration) error {
+	if c.frozen.Load() {
+		return ErrFrozen
+	}
+	key, err := c.normKey(key)
+	if err != nil {
+		return err
+	}
+	if err = c.checkSize(key, value); err != nil {
+		return err
+	}
+	if err := c.storeSave(key, value, ttl); err != nil {
+		return err
+	}
+	return c.setLocal(key, value, ttl, nil)
+}
 
-	var exp int64
+// SetWithOnEvict like Set, but the item carries its own eviction callback, eg: to close
+// the file handle or connection owned by the value. The fn overrides the OnEvicted callback
+// for the item, it is called on the item removed(any reason) or replaced by a new value.
+//
+// The fn is not persisted by SaveFile and not copied by Clone.
+func (c *Cache) SetWithOnEvict(key string, value any, ttl time.Duration, fn func(key string, value any)) {
+	if c.frozen.Load() {
+		return
+	}
+	key, err := c.normKey(key)
+	if err != nil || c.checkSize(key, value) != nil {
+		return
+	}
+	if err = c.storeSave(key, value, ttl); err == nil {
+		_ = c.setLocal(key, value, ttl, fn)
+	}
+}
+
+// setLocal 写入本地缓存，不写入 Store. onEvict 为数据项自身的淘汰回调
+func (c *Cache) setLocal(key string, value any, ttl time.Duration, onEvict func(key string, value any)) error {
+	if !c.lock() {
+		return ErrBusy
+	}
+	if c.frozen.Load() {
+		c.mu.Unlock()
+		return ErrFrozen
+	}
+
+	key = c.nsKey(key)
+	exp := ttlToExp(ttl)
+	it := c.set(key, value, exp)
+	if it == nil {
+		c.mu.Unlock()
+		return ErrCacheFull
+	}
+	it.onEvict = onEvict
+	err := c.appendAOF(aofOpSet, key, value, exp)
+	c.mu.Unlock()
+
+	c.onSet(key, value, ttl)
+	return err
+}
+
+// setEvent 等待释放锁后调用 OnSet 回调的写入
+type setEvent struct {
+	key string
+	val any
+	ttl time.Duration
+}
+
+// appendSet 记录数据结构操作、Touch 等原地修改的写入: 追加 AOF 记录，并在释放锁后
+// 调用 OnSet 回调 (需持有写锁，使用 unlock 释放). ttl 为剩余的存活时间
+func (c *Cache) appendSet(key string, val any, exp int64) error {
+	if c.opt.OnSet != nil {
+		var ttl time.Duration
+		if exp > 0 {
+			ttl = time.Until(time.UnixMilli(exp))
+		}
+		c.setEvents = append(c.setEvents, setEvent{key: key, val: val, ttl: ttl})
+	}
+	return c.appendAOF(aofOpSet, key, val, exp)
+}
+
+// onSet 写入成功后调用 OnSet 回调 (不加锁，回调中可以调用缓存的方法)
+func (c *Cache) onSet(key string, value any, ttl time.Duration) {
+	if c.opt.OnSet != nil {
+		c.opt.OnSet(key, value, ttl)
+	}
+}
+
+// ttlToExp 将 TTL 转换为过期时间 millitime. ttl <= 0 返回 0 表示永不过期
+func ttlToExp(ttl time.Duration) int64 {
 	if ttl > 0 {
-		exp = time.Now().Add(ttl).UnixMilli()
+		return time.Now().Add(ttl).UnixMilli()
+	}
+	return 0
+}
+
+// set 内部写入方法 (不加锁). 返回写入的数据项，缓存已满拒绝写入时返回 nil
+func (c *Cache) set(key string, value any, exp int64) *Item {
+	hk, it := c.newItem(key, value, exp)
+	if !c.putItem(hk, it) {
+		return nil
+	}
+	return it
+}
+
+// newItem 创建数据项 (不加锁). 返回实际存储的 key
+func (c *Cache) newItem(key string, value any, exp int64) (string, *Item) {
+	if c.gobAuto {
+		GobRegister(value)
+	}
+
+	it := &Item{Val: value, Exp: exp, Gen: c.gen}
+	if exp > 0 && c.opt.RefreshLoader != nil {
+		it.ttl = exp - time.Now().UnixMilli()
+	}
+	if hk := c.hashKey(key); hk != key {
+		if c.opt.KeyHashCheck {
+			it.Key = key
+		}
+		key = hk
+	}
+	return key, it
+}
+
+// putItem 内部写入数据项 (不加锁). key 为实际存储的 key.
+// 溢出策略为 RejectNew 且缓存已满时不写入，返回 false
+func (c *Cache) putItem(key string, it *Item) bool {
+	if c.opt.Overflow == RejectNew && !c.hasRoom(key, it) {
+		return false
+	}
+
+	now := time.Now().UnixMilli()
+	if it.created == 0 {
+		it.created = now
+	}
+	if old, ok := c.items[key]; ok {
+		c.totalCost -= old.cost
+		c.prioLen[old.prio]--
+		c.untrackLive(old)
+		// 覆盖已过期但未清理的项，按过期删除处理
+		if c.invalid(old, now) {
+			c.notifyRemoved(key, old, ReasonExpired)
+		} else {
+			c.notifyReplaced(key, old)
+		}
+	}
+	c.totalCost += it.cost
+	c.prioLen[it.prio]++
+	c.updateIndexes(key, it)
+
+	// 关闭 LRU 时不维护链表，也不限制容量
+	if c.opt.DisableLRU {
+		if _, ok := c.items[key]; !ok {
+			c.addSlot(key)
+		}
+		c.items[key] = it
+		c.trackLive(it)
+		return true
 	}
 
 	// 如果 key 已存在，更新值并移动到 LRU 头部
 	if elem, ok := c.lruMap[key]; ok {
 		c.lruList.MoveToFront(elem)
-		c.items[key] = &Item{Val: value, Exp: exp}
-		return
+		c.items[key] = it
+		c.trackLive(it)
+		c.evictCost()
+		return true
 	}
 
 	// 检查容量并执行淘汰
 	if c.lruList.Len() >= c.opt.Capacity {
-		c.evict()
+		c.evictFor(it)
 	}
 
 	// 添加新项
-	c.items[key] = &Item{Val: value, Exp: exp}
+	c.items[key] = it
+	c.trackLive(it)
 	elem := c.lruList.PushFront(key)
 	c.lruMap[key] = elem
+	c.addSlot(key)
+	c.evictCost()
+	return true
+}
+
+// hasRoom 检查是否有空间写入数据项，已满时先删除已过期的数据再检查 (不加锁)
+func (c *Cache) hasRoom(key string, it *Item) bool {
+	full := func() bool {
+		old, exists := c.items[key]
+		if !exists && !c.opt.DisableLRU && len(c.items) >= c.opt.Capacity {
+			return true
+		}
+
+		cost := c.totalCost + it.cost
+		if exists {
+			cost -= old.cost
+		}
+		return c.opt.MaxCost > 0 && it.cost > 0 && cost > c.opt.MaxCost
+	}
+
+	if !full() {
+		return true
+	}
+	return c.pruneForRoom() && !full()
+}
+
+// pruneForRoom 缓存已满时删除已过期的数据腾出空间，返回是否删除了数据 (不加锁).
+// 全量扫描为 O(N)，在 pruneNext 之前不会有数据过期，直接跳过，避免每次写入都扫描
+func (c *Cache) pruneForRoom() bool {
+	if time.Now().UnixMilli() <= c.pruneNext {
+		return false
+	}
+	return c.pruneExpired("") > 0
+}
+
+// notePrune 数据项写入或更新 TTL 后，更新最早可能过期的时间 (不加锁)
+func (c *Cache) notePrune(exp int64) {
+	if exp > 0 && c.pruneNext != 0 {
+		c.pruneNext = min(c.pruneNext, exp+c.opt.StaleGrace.Milliseconds())
+	}
+}
+
+// evictCost 总成本超过 MaxCost 时淘汰最久未使用的项，保留最新写入的项 (不加锁). see WithCost
+func (c *Cache) evictCost() {
+	for c.opt.MaxCost > 0 && c.totalCost > c.opt.MaxCost && c.lruList.Len() > 1 {
+		if !c.evict() {
+			break
+		}
+	}
+}
+
+// find 内部查找方法 (不加锁). 返回实际存储的 key 和数据项，不存在时 it 为 nil
+func (c *Cache) find(key string) (string, *Item) {
+	hk := c.hashKey(key)
+	it, ok := c.items[hk]
+	if !ok {
+		return hk, nil
+	}
+
+	// 开启了冲突检查，原始 key 不一致视为不存在
+	if it.Key != "" && it.Key != key {
+		return hk, nil
+	}
+	return hk, it
+}
+
+// touch 访问数据项时更新 LRU 位置 (不加锁). FIFO 策略的分组数据不更新
+func (c *Cache) touch(key string, it *Item) {
+	// 冻结后只读，不再更新 LRU 顺序. see lockRead
+	if c.opt.DisableLRU || c.frozen.Load() || (it.grp != nil && it.grp.opt.Policy == PolicyFIFO) {
+		return
+	}
+
+	if elem, ok := c.lruMap[key]; ok {
+		c.lruList.MoveToFront(elem)
+	}
+}
+
+// lockRead 读取数据项并更新 LRU 顺序前加锁. 冻结后只读，只加读锁
+func (c *Cache) lockRead() bool {
+	if c.frozen.Load() {
+		return c.rlock()
+	}
+	return c.lock()
+}
+
+// unlockRead 释放 lockRead 获取的锁. 冻结时持有写锁并且不能解冻，持有锁期间状态不变
+func (c *Cache) unlockRead() {
+	if c.frozen.Load() {
+		c.mu.RUnlock()
+	} else {
+		c.mu.Unlock()
+	}
 }
 
 // Val get value by key, not return exists
@@ -105,96 +699,338 @@ func (c *Cache) Val(key string) any {
 
 // Get retrieves an item from the cache.
 // Returns the Val and true if found and not expired, otherwise nil and false.
+//
+// With stale-while-revalidate enabled, also returns the stale value and true. see GetState
+//
+// With read-through loader configured, loads the missing item by it. see WithLoader
 func (c *Cache) Get(key string) (any, bool) {
-	c.mu.Lock()
+	val, st := c.GetState(key)
+	if st == StateMissing && (c.opt.Loader != nil || c.opt.Store != nil) {
+		var err error
+		if val, err = c.loadThrough(context.Background(), key); err != nil {
+			return nil, false
+		}
+		return val, true
+	}
+	return val, st != StateMissing
+}
+
+// GetState like Get, but returns the item state: StateValid, StateStale or StateMissing.
+//
+// StateStale means the value is expired but in the stale grace window, it is
+// reloading in the background. see WithStaleWhileRevalidate
+func (c *Cache) GetState(key string) (any, ItemState) {
+	val, st := c.getState(key)
+	if st != StateMissing {
+		var err error
+		if val, err = c.readCopy(val); err != nil {
+			return nil, StateMissing
+		}
+	}
+	return val, st
+}
+
+// getState 获取数据及其状态，不复制值
+func (c *Cache) getState(key string) (any, ItemState) {
+	key, err := c.normKey(key)
+	if err != nil {
+		c.misses.Add(1)
+		return nil, StateMissing
+	}
+	// 在缓存锁之外记录访问
+	if c.opt.KeyTracker != nil {
+		c.opt.KeyTracker.Add(c.nsKey(key))
+	}
+
+	if c.frozen.Load() {
+		return c.getFrozen(key)
+	}
+	if c.opt.DisableLRU || c.clock.Load() {
+		if val, st, ok := c.getFast(key); ok {
+			return val, st
+		}
+	}
+
+	if !c.lock() {
+		return nil, StateMissing
+	}
+	// 释放锁之后再提交刷新任务
+	var refresh func()
+	defer func() { c.runRefresh(refresh) }()
 	defer c.mu.Unlock()
 
-	it, ok := c.items[key]
-	if !ok {
+	hk, it := c.find(c.nsKey(key))
+	if it == nil {
+		c.misses.Add(1)
+		return nil, StateMissing
+	}
+
+	// 检查过期. 宽限期内返回旧数据并在后台重新加载
+	nowUm := time.Now().UnixMilli()
+	if c.invalid(it, nowUm) {
+		if c.isStale(it, nowUm) {
+			c.hit(it, nowUm)
+			refresh = c.refreshTask(key, it)
+			return it.Val, StateStale
+		}
+
+		c.misses.Add(1)
+		c.removeElement(hk, ReasonExpired)
+		return nil, StateMissing
+	}
+
+	c.hit(it, nowUm)
+	c.touch(hk, it)
+	refresh = c.checkRefreshAhead(key, it, nowUm)
+	return it.Val, StateValid
+}
+
+// getFast 关闭 LRU 或 CLOCK 模式时，只使用读锁获取有效的数据. 数据已过期时返回 false，由调用方加写锁处理
+func (c *Cache) getFast(key string) (val any, st ItemState, ok bool) {
+	if !c.rlock() {
+		return nil, StateMissing, true
+	}
+	var refresh func()
+	defer func() { c.runRefresh(refresh) }()
+	defer c.mu.RUnlock()
+
+	_, it := c.find(c.nsKey(key))
+	if it == nil {
+		c.misses.Add(1)
+		return nil, StateMissing, true
+	}
+
+	nowUm := time.Now().UnixMilli()
+	if c.invalid(it, nowUm) {
+		return nil, StateMissing, false
+	}
+
+	c.hit(it, nowUm)
+	if atomic.LoadUint32(&it.ref) == 0 && c.clock.Load() {
+		atomic.StoreUint32(&it.ref, 1)
+	}
+	refresh = c.checkRefreshAhead(key, it, nowUm)
+	return it.Val, StateValid, true
+}
+
+// Peek get the value of a valid item without side effects, eg: for the debugging tools.
+//
+// It does not update the LRU order and the hit statistics, does not load the missing
+// item by loader or Store, and does not trigger the refresh-ahead.
+func (c *Cache) Peek(key string) (any, bool) {
+	key, err := c.normKey(key)
+	if err != nil || !c.rlock() {
 		return nil, false
 	}
+	defer c.mu.RUnlock()
 
-	// 检查过期
-	if it.isExpired() {
-		c.removeElement(key)
+	_, it := c.find(c.nsKey(key))
+	if it == nil || c.invalid(it, time.Now().UnixMilli()) {
 		return nil, false
 	}
+	val, err := c.readCopy(it.Val)
+	return val, err == nil
+}
 
-	// 更新 LRU 位置
-	if elem, ok := c.lruMap[key]; ok {
-		c.lruList.MoveToFront(elem)
+// GetStale get the value even if it is expired but not yet removed, stale is true for the
+// expired value. eg: serve the stale data when the upstream is down.
+//
+// It does not update the LRU order, the hit statistics and does not load the missing item
+// by loader. The expired items are removed on Get or by PruneExpired, so GetStale returns
+// false for them after that. The items invalidated by BumpGeneration are treated as missing.
+func (c *Cache) GetStale(key string) (val any, stale bool, ok bool) {
+	key, err := c.normKey(key)
+	if err != nil || !c.rlock() {
+		return nil, false, false
 	}
-	return it.Val, true
+	defer c.mu.RUnlock()
+
+	_, it := c.find(c.nsKey(key))
+	if it == nil || it.Gen < c.gen {
+		return nil, false, false
+	}
+	if val, err = c.readCopy(it.Val); err != nil {
+		return nil, false, false
+	}
+	return val, it.isExpired1(time.Now().UnixMilli()), true
+}
+
+// hit 记录命中次数和访问时间
+func (c *Cache) hit(it *Item, nowUm int64) {
+	c.hits.Add(1)
+	atomic.AddUint64(&it.hits, 1)
+	atomic.StoreInt64(&it.access, nowUm)
+}
+
+// TTL get the remaining TTL of the key, 0 for never expire.
+// returns false if the key does not exist or expired.
+func (c *Cache) TTL(key string) (time.Duration, bool) {
+	key, err := c.normKey(key)
+	if err != nil || !c.rlock() {
+		return 0, false
+	}
+	defer c.mu.RUnlock()
+
+	_, it := c.find(c.nsKey(key))
+	nowUm := time.Now().UnixMilli()
+	if it == nil || c.invalid(it, nowUm) {
+		return 0, false
+	}
+
+	if it.Exp == 0 {
+		return 0, true
+	}
+	return time.Duration(it.Exp-nowUm) * time.Millisecond, true
+}
+
+// Touch update the TTL of the key without changing its value, ttl <= 0 for never expire.
+// returns false if the key does not exist or expired.
+func (c *Cache) Touch(key string, ttl time.Duration) bool {
+	key, err := c.normKey(key)
+	if err != nil || !c.lock() {
+		return false
+	}
+	defer c.unlock()
+	if c.frozen.Load() {
+		return false
+	}
+
+	hk, it := c.find(c.nsKey(key))
+	if it == nil || c.invalid(it, time.Now().UnixMilli()) {
+		return false
+	}
+
+	it.Exp = ttlToExp(ttl)
+	c.pushExp(it)
+	c.touch(hk, it)
+	_ = c.appendSet(c.nsKey(key), it.Val, it.Exp)
+	return true
 }
 
 // MGet get the values corresponding to multiple keys in batches
 func (c *Cache) MGet(keys ...string) map[string]any {
-	c.mu.Lock()
+	result := make(map[string]any, len(keys))
+	if c.frozen.Load() {
+		for _, key := range keys {
+			result[key], _ = c.GetState(key)
+		}
+		return result
+	}
+
+	if !c.lock() {
+		for _, key := range keys {
+			result[key] = nil
+		}
+		return result
+	}
 	defer c.mu.Unlock()
 
-	result := make(map[string]any, len(keys))
 	nowUm := time.Now().UnixMilli()
 
 	for _, key := range keys {
-		it, ok := c.items[key]
-		if !ok || it.isExpired1(nowUm) {
-			result[key] = nil
+		result[key] = nil
+		nk, err := c.normKey(key)
+		if err != nil {
 			continue
 		}
 
-		// 更新 LRU 位置
-		if elem, ok := c.lruMap[key]; ok {
-			c.lruList.MoveToFront(elem)
+		hk, it := c.find(c.nsKey(nk))
+		if it == nil || c.invalid(it, nowUm) {
+			continue
 		}
-		result[key] = it.Val
+
+		c.touch(hk, it)
+		result[key], _ = c.readCopy(it.Val)
 	}
 
 	return result
 }
 
-// MSet set multiple key-value pairs in bulk
+// MSet set multiple key-value pairs in bulk.
+//
+// With Store configured, writes through to the store first, the items failed to save are skipped.
+// The items are not written to the cache if the lock cannot be acquired in time. see Stats.LockTimeouts
 func (c *Cache) MSet(items map[string]any, ttl time.Duration) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return
+	}
 
-	var exp int64
-	if ttl > 0 {
-		exp = time.Now().Add(ttl).UnixMilli()
+	if c.opt.KeyFunc != nil {
+		normed := make(map[string]any, len(items))
+		for key, val := range items {
+			if key, err := c.normKey(key); err == nil {
+				normed[key] = val
+			}
+		}
+		items = normed
 	}
+	items = c.sizeFilter(items)
+	c.msetLocal(c.storeSaveAll(items, ttl), ttl)
+}
 
+// msetLocal 批量写入本地缓存，不写入 Store
+func (c *Cache) msetLocal(items map[string]any, ttl time.Duration) {
+	if !c.lock() {
+		return
+	}
+	if c.frozen.Load() {
+		c.mu.Unlock()
+		return
+	}
+
+	exp := ttlToExp(ttl)
+	saved := make(map[string]any, len(items))
 	for key, value := range items {
-		// 如果 key 已存在，更新值并移动到 LRU 头部
-		if elem, ok := c.lruMap[key]; ok {
-			c.lruList.MoveToFront(elem)
-			c.items[key] = &Item{Val: value, Exp: exp}
+		key = c.nsKey(key)
+		if c.set(key, value, exp) == nil {
 			continue
 		}
+		saved[key] = value
+		_ = c.appendAOF(aofOpSet, key, value, exp)
+	}
+	c.mu.Unlock()
 
-		// 检查容量并执行淘汰
-		if c.lruList.Len() >= c.opt.Capacity {
-			c.evict()
+	if c.opt.OnSet != nil {
+		for key, value := range saved {
+			c.opt.OnSet(key, value, ttl)
 		}
-
-		// 添加新项
-		c.items[key] = &Item{Val: value, Exp: exp}
-		elem := c.lruList.PushFront(key)
-		c.lruMap[key] = elem
 	}
 }
 
-// Has checks if an item exists in the cache.
+// Has checks if a valid item exists in the cache, consistent with Get: returns false
+// for the expired items, true for the stale items in the grace window.
+//
+// It does not update the LRU order and does not load the missing item by loader.
+// The expired items are not removed, see PruneExpired
 func (c *Cache) Has(key string) bool {
-	c.mu.RLock()
+	key, err := c.normKey(key)
+	if err != nil || !c.rlock() {
+		return false
+	}
 	defer c.mu.RUnlock()
-	return c.items[key] != nil
+
+	_, it := c.find(c.nsKey(key))
+	if it == nil {
+		return false
+	}
+	nowUm := time.Now().UnixMilli()
+	return !c.invalid(it, nowUm) || c.isStale(it, nowUm)
 }
 
-// Keys Get a list of all valid keys in the current cache
+// Keys Get a list of all valid keys in the current cache.
+// For a namespace view, returns the keys in the namespace without prefix.
 //
 // 注意：此操作会遍历所有数据，时间复杂度为 O(N)
 // 如果数据量巨大，可能会短暂阻塞写操作
 func (c *Cache) Keys() []string {
-	c.mu.RLock()
+	return c.keys(nil)
+}
+
+// keys 获取所有有效的 key，match 不为空时只返回匹配的 key. 命名空间视图返回去除前缀的 key
+func (c *Cache) keys(match func(key string, it *Item) bool) []string {
+	if !c.rlock() {
+		return nil
+	}
 	defer c.mu.RUnlock()
 
 	keys := make([]string, 0, len(c.items))
@@ -202,7 +1038,18 @@ func (c *Cache) Keys() []string {
 
 	// 遍历 map 过滤掉已过期的 key
 	for k, v := range c.items {
-		if !v.isExpired1(nowUm) {
+		if c.invalid(v, nowUm) {
+			continue
+		}
+
+		if c.ns != "" {
+			if !strings.HasPrefix(k, c.ns) {
+				continue
+			}
+			k = k[len(c.ns):]
+		}
+
+		if match == nil || match(k, v) {
 			keys = append(keys, k)
 		}
 	}
@@ -210,52 +1057,305 @@ func (c *Cache) Keys() []string {
 	return keys
 }
 
-// Len get the number of items in the cache
+// Len get the number of items in the cache.
+// For a namespace view, returns the number of items in the namespace.
+//
+// NOTE: for a namespace view, it traverses all keys to match the prefix, the time complexity is O(N)
 //
 // 返回的是 map 的大小，包含可能已过期但尚未被清理的“僵尸”数据
-// 为了保证 O(1) 的高性能，这里不进行遍历去重
+// 为了保证 O(1) 的高性能，这里不进行遍历去重. 需要准确的数量时使用 WithAccurateLen
 func (c *Cache) Len() int {
-	c.mu.RLock()
+	if c.liveOn && c.ns == "" {
+		if !c.lock() {
+			return 0
+		}
+		defer c.mu.Unlock()
+		return c.liveLen(time.Now().UnixMilli())
+	}
+
+	if !c.rlock() {
+		return 0
+	}
+	defer c.mu.RUnlock()
+
+	if c.ns == "" {
+		return len(c.items)
+	}
+	return len(c.nsKeys())
+}
+
+// ValidLen get the number of valid items, exclude the expired items not yet removed.
+// For a namespace view, returns the number of valid items in the namespace.
+//
+// NOTE: it will traverse all data, the time complexity is O(N)
+func (c *Cache) ValidLen() int {
+	if !c.rlock() {
+		return 0
+	}
+	defer c.mu.RUnlock()
+	return c.validLen(c.ns)
+}
+
+// validLen 统计前缀为 prefix 的有效数据数量 (不加锁)
+func (c *Cache) validLen(prefix string) (n int) {
+	nowUm := time.Now().UnixMilli()
+	for key, it := range c.items {
+		if prefix != "" && !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if !c.invalid(it, nowUm) {
+			n++
+		}
+	}
+	return n
+}
+
+// CountPrefix get the number of valid items with the key prefix, without copying the keys out.
+// eg: monitor the per-tenant usage of a multi-tenant cache. For a namespace view, the prefix
+// is relative to the namespace.
+//
+// NOTE: it will traverse all data, the time complexity is O(N)
+func (c *Cache) CountPrefix(prefix string) int {
+	if !c.rlock() {
+		return 0
+	}
 	defer c.mu.RUnlock()
-	return len(c.items)
+	return c.validLen(c.nsKey(prefix))
 }
 
-// Clear removes all items from the cache
+// PrefixStats get the number and the approximate size in bytes(keys and values) of the valid
+// items with the key prefix. The size of the values is calculated by ApproxSize.
+//
+// NOTE: it will traverse all data and may encode the values to JSON, it is slower than CountPrefix
+func (c *Cache) PrefixStats(prefix string) (count int, bytes int64) {
+	if !c.rlock() {
+		return 0, 0
+	}
+	defer c.mu.RUnlock()
+
+	prefix = c.nsKey(prefix)
+	nowUm := time.Now().UnixMilli()
+	for key, it := range c.items {
+		if !strings.HasPrefix(key, prefix) || c.invalid(it, nowUm) {
+			continue
+		}
+		count++
+		bytes += int64(len(key) + ApproxSize(it.Val))
+	}
+	return
+}
+
+// Clear removes all items from the cache.
+// For a namespace view, only removes the items in the namespace.
+//
+// Nothing is cleared if the lock cannot be acquired in time. see Stats.LockTimeouts
 //
 // 这会重置底层的 map 和 list，释放内存引用
-// 注意：这不会触发 onEvicted 回调函数，因为那是针对单个元素淘汰的
+// 注意：这不会触发 onEvicted 回调函数，因为那是针对单个元素淘汰的. 需要回调时使用 ClearNotify
 func (c *Cache) Clear() {
-	c.mu.Lock()
+	if !c.lock() {
+		return
+	}
+	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return
+	}
+
+	// 命名空间视图只逐个删除其中的数据
+	if c.ns != "" {
+		for _, key := range c.nsKeys() {
+			c.removeElement(key, ReasonDeleted)
+			_ = c.appendAOF(aofOpDel, key, nil, 0)
+		}
+		return
+	}
+
+	c.reset()
+	_ = c.appendAOF(aofOpClear, "", nil, 0)
+}
+
+// PruneExpired removes all expired items on demand, returns the number of removed items.
+// Items in the stale grace window are kept. see WithStaleWhileRevalidate
+//
+// Useful to trigger the cleanup at known quiet moments. For a namespace view,
+// only removes the items in the namespace.
+//
+// NOTE: it will traverse all data, the time complexity is O(N)
+func (c *Cache) PruneExpired() int {
+	if !c.lock() {
+		return 0
+	}
+	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return 0
+	}
+	return c.pruneExpired(c.ns)
+}
+
+// pruneExpired 删除前缀为 prefix 的已过期数据 (不加锁). 删除全部数据时记录剩余数据最早过期的时间
+func (c *Cache) pruneExpired(prefix string) (n int) {
+	nowUm := time.Now().UnixMilli()
+	next := int64(math.MaxInt64)
+	for key, it := range c.items {
+		if prefix != "" && !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if c.invalid(it, nowUm) && !c.isStale(it, nowUm) {
+			c.removeElement(key, ReasonExpired)
+			n++
+		} else if it.Exp > 0 {
+			next = min(next, it.Exp+c.opt.StaleGrace.Milliseconds())
+		}
+	}
+
+	if prefix == "" {
+		c.pruneNext = next
+	}
+	return n
+}
+
+// ClearNotify like Clear, but calls the OnEvicted and OnRemoved callbacks for each
+// removed item with ReasonCleared, eg: to release the resources held by the values.
+//
+// NOTE: it will traverse all data, the time complexity is O(N)
+func (c *Cache) ClearNotify() {
+	if !c.lock() {
+		return
+	}
 	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return
+	}
+
+	if c.ns != "" {
+		for _, key := range c.nsKeys() {
+			c.removeElement(key, ReasonCleared)
+			_ = c.appendAOF(aofOpDel, key, nil, 0)
+		}
+		return
+	}
+
+	for key := range c.items {
+		c.removeElement(key, ReasonCleared)
+	}
 	c.reset()
+	_ = c.appendAOF(aofOpClear, "", nil, 0)
 }
 
 // 直接重新初始化，比逐个 Delete 效率高得多
 func (c *Cache) reset() {
+	c.totalCost = 0
+	c.prioLen = [len(evictOrder)]int{}
+	c.liveN, c.expHeap, c.pruneNext = 0, nil, 0
 	c.items = make(map[string]*Item)
 	c.lruMap = make(map[string]*list.Element)
 	c.lruList.Init()
+	c.resetIndexes()
+	c.resetSlots()
+	c.pinned = nil
+}
+
+// swapData 使用 src 的数据替换当前的数据，之后不能再使用 src (不加锁). see loadStage
+func (c *Cache) swapData(src *core) {
+	c.items, c.lruList, c.lruMap = src.items, src.lruList, src.lruMap
+	c.totalCost, c.prioLen = src.totalCost, src.prioLen
+	c.liveN, c.expHeap, c.pruneNext = src.liveN, src.expHeap, src.pruneNext
+	c.indexes, c.idxVals = src.indexes, src.idxVals
+	c.slots, c.slotIdx, c.slotHoles = src.slots, src.slotIdx, src.slotHoles
+	// 所有的 key 已替换，旧的游标都转换到开头
+	c.addSlotRemap(nil)
+	c.pinned = nil
 }
 
-// MDelete removes multiple items from the cache
+// eachOrdered 按从最久未使用到最近使用的顺序遍历数据项 (不加锁). 关闭 LRU 时按写入的顺序
+func (c *Cache) eachOrdered(fn func(key string, it *Item)) {
+	if c.opt.DisableLRU {
+		for i, key := range c.slots {
+			if idx, ok := c.slotIdx[key]; ok && idx == i {
+				fn(key, c.items[key])
+			}
+		}
+		return
+	}
+
+	for elem := c.lruList.Back(); elem != nil; elem = elem.Prev() {
+		key := elem.Value.(string)
+		fn(key, c.items[key])
+	}
+}
+
+// MDelete removes multiple items from the cache, also deletes them from the Store if configured.
+//
+// The items are kept in the cache if the lock cannot be acquired in time. see Stats.LockTimeouts
 func (c *Cache) MDelete(keys ...string) {
-	c.mu.Lock()
+	if c.frozen.Load() {
+		return
+	}
+
+	if c.opt.KeyFunc != nil {
+		normed := make([]string, 0, len(keys))
+		for _, key := range keys {
+			if key, err := c.normKey(key); err == nil {
+				normed = append(normed, key)
+			}
+		}
+		keys = normed
+	}
+	for _, key := range keys {
+		_ = c.storeDelete(key)
+	}
+
+	if !c.lock() {
+		return
+	}
 	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return
+	}
 
 	for _, key := range keys {
-		c.removeElement(key)
+		key = c.nsKey(key)
+		c.removeKey(key, ReasonDeleted)
+		_ = c.appendAOF(aofOpDel, key, nil, 0)
 	}
 }
 
-// Delete removes an item from the cache
+// Delete removes an item from the cache, also deletes it from the Store if configured.
+//
+// The store error is ignored, use DeleteE to get it. Returns false and keeps the item
+// if the lock cannot be acquired in time. see Stats.LockTimeouts
 func (c *Cache) Delete(key string) bool {
-	c.mu.Lock()
+	key, err := c.normKey(key)
+	if err != nil || c.frozen.Load() {
+		return false
+	}
+	_ = c.storeDelete(key)
+
+	if !c.lock() {
+		return false
+	}
 	defer c.mu.Unlock()
-	return c.removeElement(key)
+	if c.frozen.Load() {
+		return false
+	}
+
+	key = c.nsKey(key)
+	_ = c.appendAOF(aofOpDel, key, nil, 0)
+	return c.removeKey(key, ReasonDeleted)
 }
 
-// removeElement 内部删除方法 (不加锁)
-func (c *Cache) removeElement(key string) (exists bool) {
+// removeKey 按 key(含命名空间前缀)删除数据项 (不加锁). 开启 KeyHashCheck 时，
+// 只删除原始 key 一致的数据项，不会误删 hash 冲突的其他 key
+func (c *Cache) removeKey(key string, reason RemoveReason) bool {
+	hk, it := c.find(key)
+	if it == nil {
+		return false
+	}
+	return c.removeElement(hk, reason)
+}
+
+// removeElement 内部删除方法 (不加锁). reason 为删除原因
+func (c *Cache) removeElement(key string, reason RemoveReason) (exists bool) {
 	if elem, ok := c.lruMap[key]; ok {
 		c.lruList.Remove(elem)
 		delete(c.lruMap, key)
@@ -265,96 +1365,136 @@ func (c *Cache) removeElement(key string) (exists bool) {
 	if it, ok := c.items[key]; ok {
 		exists = true
 		delete(c.items, key)
-		if c.opt.OnEvicted != nil {
-			c.opt.OnEvicted(key, it.Val)
+		c.totalCost -= it.cost
+		c.prioLen[it.prio]--
+		c.untrackLive(it)
+		c.removeIndexes(key)
+		c.delSlot(key)
+		delete(c.pinned, key)
+		if reason == ReasonEvicted {
+			c.evictions.Add(1)
 		}
+		c.notifyRemoved(key, it, reason)
 	}
 	return
 }
 
-// evict 淘汰最久未使用的项
-func (c *Cache) evict() {
-	elem := c.lruList.Back()
-	if elem != nil {
-		key := elem.Value.(string)
-		c.removeElement(key)
-	}
-}
+// evict 淘汰最久未使用且未固定的项，没有可淘汰的项时返回 false
+func (c *Cache) evict() bool { return c.evictOne("") }
 
-// getSerializer 获取序列化器
-func (c *Cache) serializer() (Serializer, error) {
-	if serializer, ok := serializers[c.opt.Serializer]; ok {
-		return serializer, nil
+// evictFor 为写入的数据项淘汰一个数据项. 分组的数据先按分组的策略淘汰分组内的项，
+// 分组内没有可淘汰的项时再淘汰其他项 (不加锁)
+func (c *Cache) evictFor(it *Item) bool {
+	if it.grp != nil && c.evictOne(it.grp.prefix()) {
+		return true
 	}
-	return nil, errors.New("not registered serializer: " + c.opt.Serializer)
+	return c.evict()
 }
 
-// SaveFile Save the cache data to a file.
-func (c *Cache) SaveFile(filename string) error {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+// evictOne 淘汰 key 前缀为 prefix 的一个数据项. 按优先级从低到高，
+// 同一优先级中淘汰最久未使用的项，跳过固定的项. 已过期的项按过期删除
+func (c *Cache) evictOne(prefix string) bool {
+	now := time.Now().UnixMilli()
+	for _, p := range evictOrder {
+		if c.prioLen[p] == 0 {
+			continue
+		}
 
-	// 准备序列化数据，剔除已过期的
-	data := make(map[string]any)
-	nowUm := time.Now().UnixMilli()
-	for k, v := range c.items {
-		if !v.isExpired1(nowUm) {
-			data[k] = v
+		for elem := c.lruList.Back(); elem != nil; {
+			key := elem.Value.(string)
+			it, ok := c.items[key]
+			prev := elem.Prev()
+			if ok && it.prio != p {
+				elem = prev
+				continue
+			}
+			if _, ok := c.pinned[key]; ok || !strings.HasPrefix(key, prefix) {
+				elem = prev
+				continue
+			}
+
+			// CLOCK: 被访问过的项移动到头部，给予第二次机会. 移动后会再次遍历到
+			if ok && atomic.SwapUint32(&it.ref, 0) == 1 && (it.grp == nil || it.grp.opt.Policy != PolicyFIFO) {
+				c.lruList.MoveToFront(elem)
+				if elem = prev; elem == nil {
+					elem = c.lruList.Back()
+				}
+				continue
+			}
+
+			if ok && c.invalid(it, now) {
+				c.removeElement(key, ReasonExpired)
+			} else {
+				c.removeElement(key, ReasonEvicted)
+			}
+			return true
 		}
 	}
+	return false
+}
 
-	if len(data) == 0 {
-		return nil
+// EvictN evicts the n least recently used items, returns the number of evicted items.
+// Useful to shrink the cache proactively on memory pressure. For a namespace view,
+// only evicts the items in the namespace. The pinned items are skipped, and the
+// lower priority items are evicted first. see Pin, WithPriority
+//
+// NOTE: it does nothing if LRU is disabled. see WithLRU
+func (c *Cache) EvictN(n int) int {
+	if n <= 0 || !c.lock() {
+		return 0
 	}
-
-	file, err := fsutil.OpenTruncFile(filename, 0644)
-	if err != nil {
-		return err
+	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return 0
 	}
-	defer stdio.SafeClose(file)
 
-	serializer, err1 := c.serializer()
-	if err1 != nil {
-		return err1
+	var count int
+	for count < n && c.evictOne(c.ns) {
+		count++
 	}
-
-	return serializer.EncodeTo(file, data)
+	return count
 }
 
-// LoadFile Recover cache data from file load
-func (c *Cache) LoadFile(filename string) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// OldestKey get the least recently used key, it will be evicted first when the cache is full.
+// For a namespace view, returns the oldest key in the namespace without prefix.
+//
+// NOTE: with LRUClock, the reads do not change the order, the key is the tail of the clock.
+func (c *Cache) OldestKey() (string, bool) {
+	return c.lruKey(false)
+}
 
-	file, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer stdio.SafeClose(file)
+// NewestKey get the most recently used key.
+// For a namespace view, returns the newest key in the namespace without prefix.
+func (c *Cache) NewestKey() (string, bool) {
+	return c.lruKey(true)
+}
 
-	serializer, err1 := c.serializer()
-	if err1 != nil {
-		return err1
+// lruKey 从 LRU 链表头部(newest)或尾部查找 key
+func (c *Cache) lruKey(newest bool) (string, bool) {
+	if !c.rlock() {
+		return "", false
 	}
+	defer c.mu.RUnlock()
 
-	var data map[string]Item
-	err = serializer.DecodeFrom(file, &data)
-	if err != nil {
-		return err
+	elem := c.lruList.Back()
+	if newest {
+		elem = c.lruList.Front()
 	}
 
-	// 恢复数据 (清空当前数据)
-	c.reset()
-	nowUm := time.Now().UnixMilli()
+	for elem != nil {
+		key := elem.Value.(string)
+		if c.ns == "" {
+			return key, true
+		}
+		if strings.HasPrefix(key, c.ns) {
+			return key[len(c.ns):], true
+		}
 
-	for k, v := range data {
-		// 加载时检查是否过期，避免加载即过期
-		if !v.isExpired1(nowUm) {
-			c.items[k] = &v
-			elem := c.lruList.PushFront(k)
-			c.lruMap[k] = elem
+		if newest {
+			elem = elem.Next()
+		} else {
+			elem = elem.Prev()
 		}
 	}
-
-	return nil
+	return "", false
 }
@@ -1,10 +1,15 @@
 package lcache_test
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/fsutil"
 	"github.com/gookit/goutil/testutil/assert"
 )
 
@@ -170,6 +175,20 @@ func TestCache_Has(t *testing.T) {
 	c.Set("key", "Val", 5*time.Minute)
 	assert.True(t, c.Has("key"))
 	assert.False(t, c.Has("non-existent"))
+
+	// expired item
+	c.Set("expired", "Val", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	assert.False(t, c.Has("expired"))
+	assert.Eq(t, 2, c.Len())
+
+	// stale item in the grace window
+	c2 := lcache.New(lcache.WithStaleWhileRevalidate(time.Minute, func(string) (any, error) {
+		return "new", nil
+	}))
+	c2.Set("stale", "Val", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	assert.True(t, c2.Has("stale"))
 }
 
 func TestCache_Keys(t *testing.T) {
@@ -239,3 +258,847 @@ func TestCache_Concurrent(t *testing.T) {
 	_, found := c.Get("key")
 	assert.True(t, found)
 }
+
+func TestCache_BumpGeneration(t *testing.T) {
+	c := lcache.New()
+	c.Set("key1", "value1", 0)
+	assert.Eq(t, uint64(0), c.Generation())
+
+	assert.Eq(t, uint64(1), c.BumpGeneration())
+	_, found := c.Get("key1")
+	assert.False(t, found)
+	assert.Empty(t, c.Keys())
+
+	c.Set("key2", "value2", 0)
+	assert.Eq(t, "value2", c.Val("key2"))
+
+	// generation is persisted to snapshot
+	filename := t.TempDir() + "/gen_cache.json"
+	assert.NoErr(t, c.SaveFile(filename))
+
+	c2 := lcache.New()
+	assert.NoErr(t, c2.LoadFile(filename))
+	assert.Eq(t, uint64(1), c2.Generation())
+	assert.Eq(t, "value2", c2.Val("key2"))
+
+	// newer generation configured, old snapshot entries are misses
+	c3 := lcache.New(lcache.WithGeneration(2))
+	assert.NoErr(t, c3.LoadFile(filename))
+	assert.Eq(t, uint64(2), c3.Generation())
+	assert.False(t, c3.Has("key2"))
+}
+
+func TestCache_SaveFile_atomic(t *testing.T) {
+	c := lcache.New(lcache.WithSaveSync(true))
+	c.Set("key1", "value1", 0)
+
+	filename := t.TempDir() + "/atomic_cache.json"
+	assert.NoErr(t, c.SaveFile(filename))
+	assert.False(t, fsutil.FileExists(filename+".tmp"))
+
+	// encode failed, the previous snapshot should be kept
+	c.Set("bad", func() {}, 0)
+	assert.Err(t, c.SaveFile(filename))
+	assert.False(t, fsutil.FileExists(filename+".tmp"))
+
+	c2 := lcache.New()
+	assert.NoErr(t, c2.LoadFile(filename))
+	assert.Eq(t, "value1", c2.Val("key1"))
+	assert.False(t, c2.Has("bad"))
+}
+
+func TestCache_SaveFile_savePolicy(t *testing.T) {
+	c := lcache.New(lcache.WithSavePolicy(time.Minute))
+	c.Set("short", "value1", 10*time.Second)
+	c.Set("long", "value2", time.Hour)
+	c.Set("forever", "value3", 0)
+
+	filename := t.TempDir() + "/policy_cache.json"
+	assert.NoErr(t, c.SaveFile(filename))
+
+	c2 := lcache.New()
+	assert.NoErr(t, c2.LoadFile(filename))
+	assert.False(t, c2.Has("short"))
+	assert.Eq(t, "value2", c2.Val("long"))
+	assert.Eq(t, "value3", c2.Val("forever"))
+}
+
+func TestCache_SaveFile_saveFilter(t *testing.T) {
+	var keys []string
+	c := lcache.New(lcache.WithSaveFilter(func(key string, it lcache.Item) bool {
+		keys = append(keys, key)
+		return !strings.HasPrefix(key, "session:")
+	}))
+	c.Set("key1", "value1", 0)
+	c.Namespace("session").Set("1", "token", time.Hour)
+
+	filename := t.TempDir() + "/filter_cache.json"
+	assert.NoErr(t, c.SaveFile(filename))
+	assert.Contains(t, keys, "session:1")
+
+	c2 := lcache.New()
+	assert.NoErr(t, c2.LoadFile(filename))
+	assert.Eq(t, "value1", c2.Val("key1"))
+	assert.False(t, c2.Has("session:1"))
+
+	// also applied to SaveDir
+	dir := t.TempDir()
+	assert.NoErr(t, c.SaveDir(dir, 2))
+	c3 := lcache.New()
+	assert.NoErr(t, c3.LoadDir(dir))
+	assert.Eq(t, 1, c3.Len())
+}
+
+func TestCache_OldestKey(t *testing.T) {
+	c := lcache.New(lcache.WithCapacity(3), lcache.WithLRUMode(lcache.LRUExact))
+	_, ok := c.OldestKey()
+	assert.False(t, ok)
+
+	c.Set("key1", 1, 0)
+	c.Set("key2", 2, 0)
+	c.Set("key3", 3, 0)
+	c.Get("key1")
+
+	key, ok := c.OldestKey()
+	assert.True(t, ok)
+	assert.Eq(t, "key2", key)
+	key, _ = c.NewestKey()
+	assert.Eq(t, "key1", key)
+
+	// the oldest key is evicted
+	c.Set("key4", 4, 0)
+	assert.False(t, c.Has("key2"))
+	key, _ = c.OldestKey()
+	assert.Eq(t, "key3", key)
+
+	// namespace view
+	ns := c.Namespace("ns")
+	_, ok = ns.NewestKey()
+	assert.False(t, ok)
+	ns.Set("a", 1, 0)
+	key, _ = ns.OldestKey()
+	assert.Eq(t, "a", key)
+}
+
+func TestCache_WithLRU(t *testing.T) {
+	c := lcache.New(lcache.WithCapacity(2), lcache.WithLRU(false))
+	c.Set("key1", 1, 0)
+	c.Set("key2", 2, 0)
+	c.Set("key3", 3, 0)
+	c.Set("expired", 4, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	// capacity is ignored
+	assert.Eq(t, 4, c.Len())
+	_, ok := c.OldestKey()
+	assert.False(t, ok)
+
+	// concurrent reads with the read lock
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Get("key1")
+			c.Get("none")
+		}()
+	}
+	wg.Wait()
+
+	_, ok = c.Get("expired")
+	assert.False(t, ok)
+	assert.Eq(t, 3, c.Len())
+
+	st := c.Stats()
+	assert.Eq(t, uint64(10), st.Hits)
+	assert.Eq(t, uint64(11), st.Misses)
+	assert.Eq(t, []lcache.KeyHits{{Key: "key1", Hits: 10}}, c.HotKeys(3))
+
+	c.Delete("key1")
+	assert.False(t, c.Has("key1"))
+	assert.Eq(t, 2, c.Len())
+}
+
+func TestCache_WithLRUMode(t *testing.T) {
+	c := lcache.New(lcache.WithCapacity(3), lcache.WithLRUMode(lcache.LRUClock))
+	c.Set("key1", 1, 0)
+	c.Set("key2", 2, 0)
+	c.Set("key3", 3, 0)
+
+	// the referenced key1 gets a second chance
+	assert.Eq(t, 1, c.Val("key1"))
+	c.Set("key4", 4, 0)
+	assert.True(t, c.Has("key1"))
+	assert.False(t, c.Has("key2"))
+
+	// all referenced, evict the tail after a full round
+	for _, key := range []string{"key1", "key3", "key4"} {
+		c.Get(key)
+	}
+	c.Set("key5", 5, 0)
+	assert.Eq(t, 3, c.Len())
+	assert.False(t, c.Has("key3"))
+
+	// concurrent reads with the read lock
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Get("key3")
+			c.Set(fmt.Sprint("key", i+6), i, 0)
+		}()
+	}
+	wg.Wait()
+	assert.Eq(t, 3, c.Len())
+
+	c1 := lcache.New(lcache.WithCapacity(1), lcache.WithLRUMode(lcache.LRUClock))
+	c1.Set("key1", 1, 0)
+	c1.Get("key1")
+	c1.Set("key2", 2, 0)
+	assert.Eq(t, []string{"key2"}, c1.Keys())
+}
+
+func TestCache_TTL(t *testing.T) {
+	c := lcache.New()
+	c.Set("key1", "val1", time.Minute)
+	c.Set("key2", "val2", 0)
+
+	ttl, ok := c.TTL("key1")
+	assert.True(t, ok)
+	assert.True(t, ttl > 59*time.Second && ttl <= time.Minute)
+	ttl, ok = c.TTL("key2")
+	assert.True(t, ok)
+	assert.Eq(t, time.Duration(0), ttl)
+	_, ok = c.TTL("not-exists")
+	assert.False(t, ok)
+
+	assert.True(t, c.Touch("key2", time.Hour))
+	ttl, _ = c.TTL("key2")
+	assert.True(t, ttl > 59*time.Minute)
+	assert.True(t, c.Touch("key1", 0))
+	ttl, _ = c.TTL("key1")
+	assert.Eq(t, time.Duration(0), ttl)
+	assert.False(t, c.Touch("not-exists", 0))
+}
+
+func TestCache_Freeze(t *testing.T) {
+	c := lcache.New()
+	c.Set("key1", "val1", 0)
+	c.Set("key2", "val2", time.Hour)
+	c.Set("expired", "val", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	c.Freeze()
+	assert.True(t, c.Frozen())
+
+	// writes are rejected
+	assert.ErrIs(t, c.SetE("key3", "val3", 0), lcache.ErrFrozen)
+	assert.ErrIs(t, c.DeleteE("key1"), lcache.ErrFrozen)
+	assert.ErrIs(t, c.LoadFile("testdata/none.json"), lcache.ErrFrozen)
+	assert.ErrIs(t, c.Update(func(tx *lcache.Tx) error { return nil }), lcache.ErrFrozen)
+	c.Set("key3", "val3", 0)
+	c.MSet(map[string]any{"key4": "val4"}, 0)
+	assert.False(t, c.Delete("key1"))
+	assert.False(t, c.Touch("key1", time.Hour))
+	c.Clear()
+	assert.Eq(t, 3, c.Len())
+	assert.False(t, c.Has("key3"))
+
+	// concurrent reads without locking
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Get("key1")
+			c.MGet("key2", "none")
+		}()
+	}
+	wg.Wait()
+
+	val, ok := c.Get("key1")
+	assert.True(t, ok)
+	assert.Eq(t, "val1", val)
+	_, ok = c.Get("expired")
+	assert.False(t, ok)
+	assert.Eq(t, uint64(21), c.Stats().Hits)
+
+	// loaded value is returned but not cached
+	val, err := c.GetOrLoad("key5", 0, func(string) (any, error) { return "val5", nil })
+	assert.NoErr(t, err)
+	assert.Eq(t, "val5", val)
+	assert.False(t, c.Has("key5"))
+
+	// by option
+	c2 := lcache.New(lcache.WithFrozen())
+	assert.True(t, c2.Frozen())
+	assert.ErrIs(t, c2.SetE("key", 1, 0), lcache.ErrFrozen)
+}
+
+func TestCache_Freeze_dataStructs(t *testing.T) {
+	c := lcache.New(lcache.WithLRUMode(lcache.LRUExact))
+	assert.NoErr(t, c.HSet("hash", "f1", 1))
+	_, err := c.RPush("list", 1, 2)
+	assert.NoErr(t, err)
+	_, err = c.SAdd("set", 0, "a")
+	assert.NoErr(t, err)
+	_, err = c.ZAdd("zset", 0, lcache.ZMember{Member: "a", Score: 1})
+	assert.NoErr(t, err)
+	c.Set("key1", "val1", 0)
+	c.Freeze()
+
+	// concurrent reads under the read lock, the LRU order is not updated
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.HGet("hash", "f1")
+			c.HGetAll("hash")
+			c.LRange("list", 0, -1)
+			c.SMembers("set")
+			c.ZTopN("zset", 1)
+		}()
+	}
+	wg.Wait()
+
+	val, ok := c.HGet("hash", "f1")
+	assert.True(t, ok)
+	assert.Eq(t, 1, val)
+	assert.Eq(t, []any{1, 2}, c.LRange("list", 0, -1))
+	assert.Eq(t, []string{"a"}, c.SMembers("set"))
+	assert.Len(t, c.ZTopN("zset", 0), 1)
+
+	key, _ := c.OldestKey()
+	assert.Eq(t, "hash", key)
+	key, _ = c.NewestKey()
+	assert.Eq(t, "key1", key)
+}
+
+func TestCache_PruneExpired(t *testing.T) {
+	var removed []string
+	c := lcache.New(lcache.WithOnRemovedFn(func(key string, _ any, reason lcache.RemoveReason) {
+		assert.Eq(t, lcache.ReasonExpired, reason)
+		removed = append(removed, key)
+	}))
+	assert.Eq(t, 0, c.PruneExpired())
+
+	c.Set("key1", 1, 0)
+	c.Set("key2", 2, time.Hour)
+	c.Set("expired", 3, time.Millisecond)
+	ns := c.Namespace("ns")
+	ns.Set("expired", 4, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	// namespace view
+	assert.Eq(t, 1, ns.PruneExpired())
+	assert.Eq(t, []string{"ns:expired"}, removed)
+	assert.Eq(t, 3, c.Len())
+
+	assert.Eq(t, 1, c.PruneExpired())
+	assert.Eq(t, 2, c.Len())
+	assert.Eq(t, 0, c.PruneExpired())
+}
+
+func TestWithOnExpiredFn(t *testing.T) {
+	type expired struct {
+		val any
+		at  time.Time
+	}
+	got := make(map[string]expired)
+	c := lcache.New(lcache.WithCapacity(2), lcache.WithOnExpiredFn(func(key string, val any, expiredAt time.Time) {
+		got[key] = expired{val: val, at: expiredAt}
+	}))
+
+	start := time.Now()
+	c.Set("key1", "val1", 10*time.Millisecond)
+	c.Set("key2", "val2", 0)
+	// evicted by the capacity limit, not expired
+	c.Set("key3", "val3", 0)
+	assert.Len(t, got, 0)
+
+	c.Set("key4", "val4", 10*time.Millisecond)
+	time.Sleep(15 * time.Millisecond)
+	assert.Eq(t, 1, c.PruneExpired())
+	assert.Eq(t, "val4", got["key4"].val)
+	assert.True(t, got["key4"].at.After(start))
+	assert.False(t, got["key4"].at.After(time.Now()))
+
+	// invalidated by generation
+	c.BumpGeneration()
+	assert.Nil(t, c.Val("key3"))
+	assert.Eq(t, "val3", got["key3"].val)
+	assert.True(t, got["key3"].at.IsZero())
+
+	// overwritten or evicted after expired, before pruned
+	var reasons []lcache.RemoveReason
+	c = lcache.New(lcache.WithCapacity(2), lcache.WithOnExpiredFn(func(key string, val any, expiredAt time.Time) {
+		got[key] = expired{val: val, at: expiredAt}
+	}), lcache.WithOnRemovedFn(func(_ string, _ any, reason lcache.RemoveReason) {
+		reasons = append(reasons, reason)
+	}))
+	c.Set("key5", "val5", 10*time.Millisecond)
+	c.Set("key6", "val6", 10*time.Millisecond)
+	time.Sleep(15 * time.Millisecond)
+	c.Set("key5", "new5", 0)
+	assert.Eq(t, "val5", got["key5"].val)
+	c.Set("key7", "val7", 0)
+	assert.Eq(t, "val6", got["key6"].val)
+	assert.Eq(t, []lcache.RemoveReason{lcache.ReasonExpired, lcache.ReasonExpired}, reasons)
+	assert.Eq(t, uint64(0), c.Stats().Evictions)
+}
+
+func TestCache_EvictN(t *testing.T) {
+	var evicted []string
+	c := lcache.New(lcache.WithOnEvictFn(func(key string, _ any) {
+		evicted = append(evicted, key)
+	}))
+	assert.Eq(t, 0, c.EvictN(2))
+
+	c.Set("key1", 1, 0)
+	c.Set("key2", 2, 0)
+	c.Set("key3", 3, 0)
+	c.Namespace("ns").Set("key4", 4, 0)
+	c.Get("key1")
+
+	assert.Eq(t, 2, c.EvictN(2))
+	assert.Eq(t, []string{"key2", "key3"}, evicted)
+	assert.Eq(t, 0, c.EvictN(0))
+
+	// namespace view
+	assert.Eq(t, 1, c.Namespace("ns").EvictN(5))
+	assert.Eq(t, []string{"key1"}, c.Keys())
+	assert.Eq(t, 1, c.EvictN(5))
+	assert.Eq(t, 0, c.Len())
+}
+
+func TestCache_ValidLen(t *testing.T) {
+	c := lcache.New()
+	c.Set("key1", 1, 0)
+	c.Set("expired", 2, time.Millisecond)
+	ns := c.Namespace("ns")
+	ns.Set("key2", 3, time.Hour)
+	ns.Set("expired", 4, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	assert.Eq(t, 4, c.Len())
+	assert.Eq(t, 2, c.ValidLen())
+	assert.Eq(t, 2, ns.Len())
+	assert.Eq(t, 1, ns.ValidLen())
+
+	st := c.Stats()
+	assert.Eq(t, 4, st.Len)
+	assert.Eq(t, 2, st.ValidLen)
+}
+
+func TestCache_CountPrefix(t *testing.T) {
+	c := lcache.New()
+	c.Set("t1:a", "abc", 0)
+	c.Set("t1:b", 12, 0)
+	c.Set("t1:expired", "val", time.Millisecond)
+	c.Set("t2:a", []string{"x"}, 0)
+	time.Sleep(5 * time.Millisecond)
+
+	assert.Eq(t, 2, c.CountPrefix("t1:"))
+	assert.Eq(t, 1, c.CountPrefix("t2:"))
+	assert.Eq(t, 0, c.CountPrefix("t3:"))
+	assert.Eq(t, 3, c.CountPrefix(""))
+
+	n, size := c.PrefixStats("t1:")
+	assert.Eq(t, 2, n)
+	// keys(4+4) + "abc"(3) + int(8)
+	assert.Eq(t, int64(19), size)
+	n, size = c.PrefixStats("t2:")
+	assert.Eq(t, 1, n)
+	assert.Eq(t, int64(4+len(`["x"]`)), size)
+
+	// namespace view
+	ns := c.Namespace("t1")
+	assert.Eq(t, 1, ns.CountPrefix("a"))
+	n, size = ns.PrefixStats("")
+	assert.Eq(t, 2, n)
+	assert.Eq(t, int64(19), size)
+}
+
+func TestCache_WithOnSetFn(t *testing.T) {
+	var keys []string
+	var c *lcache.Cache
+	c = lcache.New(lcache.WithOnSetFn(func(key string, value any, ttl time.Duration) {
+		// can call the cache methods in callback
+		assert.True(t, c.Has(key))
+		if key == "key1" {
+			assert.Eq(t, time.Minute, ttl)
+		}
+		keys = append(keys, key)
+	}))
+
+	c.Set("key1", 1, time.Minute)
+	c.Namespace("ns").Set("key2", 2, 0)
+	c.MSet(map[string]any{"key3": 3}, 0)
+	c.DefineGroup("grp", lcache.GroupOptions{}).Set("key4", 4)
+	assert.NoErr(t, c.Update(func(tx *lcache.Tx) error {
+		tx.Set("key5", 5, time.Hour)
+		tx.Delete("key3")
+		return nil
+	}))
+	_, err := c.GetOrLoad("key6", 0, func(string) (any, error) { return 6, nil })
+	assert.NoErr(t, err)
+	assert.Eq(t, []string{"key1", "ns:key2", "key3", "grp:key4", "key5", "key6"}, keys)
+
+	// not called on failed writes
+	assert.Err(t, c.Update(func(tx *lcache.Tx) error {
+		tx.Set("key7", 7, 0)
+		return errors.New("rollback")
+	}))
+	c.Freeze()
+	c.Set("key8", 8, 0)
+	assert.Len(t, keys, 6)
+
+	// the in-place writes and data structure operations
+	type event struct {
+		val any
+		ttl time.Duration
+	}
+	events := make(map[string]event)
+	c = lcache.New(lcache.WithOnSetFn(func(key string, value any, ttl time.Duration) {
+		assert.True(t, c.Has(key))
+		events[key] = event{val: value, ttl: ttl}
+	}))
+	_, _ = c.IncrBy("counter", 2)
+	assert.NoErr(t, c.HSet("hash", "f", 1))
+	_, _ = c.LPush("list", "a")
+	_, _ = c.SAdd("set", time.Hour, "m")
+	_, _ = c.ZAdd("zset", 0, lcache.ZMember{Member: "m", Score: 1})
+	_, _ = c.PFAdd("hll", 0, "a")
+	c.Import(map[string]lcache.Entry{"imported": {Value: "v"}}, true)
+	assert.True(t, c.Touch("counter", time.Minute))
+
+	assert.Eq(t, int64(2), events["counter"].val)
+	assert.Gt(t, events["counter"].ttl, 59*time.Second)
+	assert.Eq(t, map[string]any{"f": 1}, events["hash"].val)
+	assert.Eq(t, []any{"a"}, events["list"].val)
+	assert.Gt(t, events["set"].ttl, 59*time.Minute)
+	assert.Eq(t, "v", events["imported"].val)
+	assert.Len(t, events, 7)
+
+	// not called for the deletes
+	c.HDel("hash", "f")
+	c.LPop("list")
+	assert.Len(t, events, 7)
+}
+
+func TestCache_WithKeyFunc(t *testing.T) {
+	errTooLong := errors.New("key too long")
+	c := lcache.New(lcache.WithKeyFunc(func(key string) (string, error) {
+		if len(key) > 8 {
+			return "", errTooLong
+		}
+		return strings.ToLower(strings.TrimSpace(key)), nil
+	}))
+
+	c.Set(" Key1", 1, 0)
+	assert.Eq(t, 1, c.Val("key1"))
+	assert.Eq(t, 1, c.Val("KEY1 "))
+	assert.True(t, c.Has("Key1"))
+	assert.Eq(t, []string{"key1"}, c.Keys())
+
+	// invalid key
+	assert.ErrIs(t, c.SetE("long-key-1", 2, 0), errTooLong)
+	assert.ErrIs(t, c.DeleteE("long-key-1"), errTooLong)
+	_, err := c.GetOrLoad("long-key-1", 0, func(string) (any, error) { return 2, nil })
+	assert.ErrIs(t, err, errTooLong)
+	_, ok := c.Get("long-key-1")
+	assert.False(t, ok)
+	c.MSet(map[string]any{"KEY2": 2, "long-key-3": 3}, 0)
+	assert.Eq(t, map[string]any{"Key2": 2, "long-key-3": nil}, c.MGet("Key2", "long-key-3"))
+	assert.Eq(t, 2, c.Len())
+
+	// namespace view and group
+	ns := c.Namespace("ns")
+	ns.Set("KEY", "v", 0)
+	assert.True(t, ns.Has("key"))
+	assert.True(t, c.Has("ns:key"))
+	c.DefineGroup("G", lcache.GroupOptions{}).Set("KEY", "v")
+	assert.True(t, c.Has("g:key"))
+
+	assert.True(t, c.Delete("KEY1"))
+	assert.False(t, c.Has("key1"))
+}
+
+func TestCache_ClearNotify(t *testing.T) {
+	evicted := make(map[string]any)
+	c := lcache.New(
+		lcache.WithOnEvictFn(func(key string, value any) {
+			evicted[key] = value
+		}),
+		lcache.WithOnRemovedFn(func(_ string, _ any, reason lcache.RemoveReason) {
+			assert.Eq(t, lcache.ReasonCleared, reason)
+		}),
+	)
+	c.Set("key1", 1, 0)
+	c.Set("key2", 2, 0)
+	c.Namespace("ns").Set("key3", 3, 0)
+
+	// namespace view
+	c.Namespace("ns").ClearNotify()
+	assert.Eq(t, map[string]any{"ns:key3": 3}, evicted)
+	assert.Eq(t, 2, c.Len())
+
+	c.ClearNotify()
+	assert.Eq(t, 0, c.Len())
+	assert.Eq(t, map[string]any{"key1": 1, "key2": 2, "ns:key3": 3}, evicted)
+	assert.Eq(t, "cleared", lcache.ReasonCleared.String())
+
+	c.Set("key1", 1, 0)
+	assert.Eq(t, 1, c.Val("key1"))
+}
+
+func TestCache_WithAsyncCallbacks(t *testing.T) {
+	var mu sync.Mutex
+	var evicted []string
+	var c *lcache.Cache
+	c = lcache.New(
+		lcache.WithCapacity(2),
+		lcache.WithAsyncCallbacks(0),
+		lcache.WithOnEvictFn(func(key string, _ any) {
+			// can call the cache methods in callback
+			c.Has(key)
+			mu.Lock()
+			evicted = append(evicted, key)
+			mu.Unlock()
+		}),
+	)
+
+	c.Set("key1", 1, 0)
+	c.Set("key2", 2, 0)
+	c.Set("key3", 3, 0)
+	c.Delete("key2")
+	assert.NoErr(t, c.Close())
+	assert.Eq(t, []string{"key1", "key2"}, evicted)
+
+	// drop the callbacks when the queue is full
+	evicted = nil
+	started, release := make(chan struct{}), make(chan struct{})
+	c = lcache.New(
+		lcache.WithCapacity(1),
+		lcache.WithAsyncCallbacks(1),
+		lcache.WithOnEvictFn(func(key string, _ any) {
+			if key == "key0" {
+				close(started)
+				<-release
+			}
+			mu.Lock()
+			evicted = append(evicted, key)
+			mu.Unlock()
+		}),
+	)
+	c.Set("key0", 0, 0)
+	c.Set("key1", 1, 0)
+	<-started
+	c.Set("key2", 2, 0) // queued
+	c.Set("key3", 3, 0) // dropped
+	assert.Eq(t, uint64(1), c.Stats().CallbackDrops)
+	close(release)
+	assert.NoErr(t, c.Close())
+	assert.Eq(t, []string{"key0", "key1"}, evicted)
+}
+
+func TestCache_SetWithOnEvict(t *testing.T) {
+	var globals, closed []string
+	onClose := func(key string, value any) {
+		closed = append(closed, key+"="+value.(string))
+	}
+	c := lcache.New(lcache.WithCapacity(2), lcache.WithOnEvictFn(func(key string, _ any) {
+		globals = append(globals, key)
+	}))
+
+	c.SetWithOnEvict("key1", "v1", 0, onClose)
+	c.Set("key2", "v2", 0)
+	c.Set("key3", "v3", 0) // evict key1
+	assert.Eq(t, []string{"key1=v1"}, closed)
+	assert.Empty(t, globals)
+
+	// replaced by a new value
+	c.SetWithOnEvict("key2", "v2", 0, onClose)
+	c.Set("key2", "new", 0)
+	assert.Eq(t, []string{"key1=v1", "key2=v2"}, closed)
+	assert.Eq(t, "new", c.Val("key2"))
+
+	// deleted
+	c.SetWithOnEvict("key4", "v4", time.Hour, onClose)
+	c.Delete("key4")
+	assert.Eq(t, []string{"key1=v1", "key2=v2", "key4=v4"}, closed)
+	assert.Eq(t, []string{"key3"}, globals)
+}
+
+func TestCache_WithOverflowPolicy(t *testing.T) {
+	c := lcache.New(lcache.WithCapacity(2), lcache.WithOverflowPolicy(lcache.RejectNew))
+	assert.NoErr(t, c.SetE("key1", "v1", 0))
+	assert.NoErr(t, c.SetE("key2", "v2", 50*time.Millisecond))
+
+	err := c.SetE("key3", "v3", 0)
+	assert.True(t, errors.Is(err, lcache.ErrCacheFull))
+	assert.False(t, c.Has("key3"))
+	c.Set("key3", "v3", 0) // skipped
+	assert.False(t, c.Has("key3"))
+	assert.Eq(t, 2, c.Len())
+
+	// update the existing key
+	assert.NoErr(t, c.SetE("key1", "new", 0))
+	assert.Eq(t, "new", c.Val("key1"))
+
+	// transaction is rejected as a whole
+	err = c.Update(func(tx *lcache.Tx) error {
+		tx.Set("key1", "v1", 0)
+		tx.Set("key3", "v3", 0)
+		return nil
+	})
+	assert.True(t, errors.Is(err, lcache.ErrCacheFull))
+	assert.Eq(t, "new", c.Val("key1"))
+	assert.NoErr(t, c.Update(func(tx *lcache.Tx) error {
+		tx.Delete("key1")
+		tx.Set("key3", "v3", 0)
+		return nil
+	}))
+	assert.Eq(t, "v3", c.Val("key3"))
+
+	// expired items are removed to make room
+	time.Sleep(60 * time.Millisecond)
+	assert.NoErr(t, c.SetE("key4", "v4", 0))
+	assert.False(t, c.Has("key2"))
+	assert.True(t, c.Has("key4"))
+
+	// the scan is skipped until an item may expire, the TTL updates are tracked
+	assert.ErrIs(t, c.SetE("key5", "v5", 0), lcache.ErrCacheFull)
+	assert.True(t, c.Touch("key4", 20*time.Millisecond))
+	assert.ErrIs(t, c.SetE("key5", "v5", 0), lcache.ErrCacheFull)
+	time.Sleep(30 * time.Millisecond)
+	assert.NoErr(t, c.SetE("key5", "v5", 0))
+	assert.False(t, c.Has("key4"))
+
+	// max cost
+	c = lcache.New(lcache.WithMaxCost(10), lcache.WithOverflowPolicy(lcache.RejectNew))
+	assert.NoErr(t, c.SetX("key1", "v1", lcache.WithCost(6)))
+	err = c.SetX("key2", "v2", lcache.WithCost(6))
+	assert.True(t, errors.Is(err, lcache.ErrCacheFull))
+	assert.NoErr(t, c.SetX("key2", "v2", lcache.WithCost(4)))
+	assert.Eq(t, int64(10), c.Stats().Cost)
+}
+
+func TestCache_Configure_concurrent(t *testing.T) {
+	c := lcache.New()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				key := fmt.Sprintf("key%d-%d", i, j)
+				c.Set(key, j, time.Minute)
+				c.Get(key)
+				c.Has(key)
+				c.Delete(key)
+			}
+		}(i)
+	}
+	for i := 1; i <= 50; i++ {
+		c.Configure(lcache.WithCapacity(100+i), lcache.WithLockTimeout(time.Second))
+	}
+	wg.Wait()
+
+	for i := 0; i < 200; i++ {
+		c.Set(fmt.Sprint(i), i, 0)
+	}
+	assert.Eq(t, 150, c.Len())
+}
+
+func TestNewE(t *testing.T) {
+	c, err := lcache.NewE(lcache.WithCapacity(1))
+	assert.NoErr(t, err)
+	c.Set("key1", "v1", 0)
+	c.Set("key2", "v2", 0)
+	assert.Eq(t, 1, c.Len())
+
+	_, err = lcache.NewE(lcache.WithCapacity(0))
+	assert.ErrSubMsg(t, err, "invalid capacity 0")
+	c, err = lcache.NewE(lcache.WithCapacity(0), lcache.WithLRU(false))
+	assert.NoErr(t, err)
+	assert.NotNil(t, c)
+
+	_, err = lcache.NewE(func(o *lcache.Options) {
+		o.Serializer = "not-exists"
+	})
+	assert.ErrSubMsg(t, err, `not registered serializer name "not-exists"`)
+}
+
+func TestCache_GetStale(t *testing.T) {
+	c := lcache.New()
+	c.Set("key1", "v1", 0)
+	c.Set("key2", "v2", 20*time.Millisecond)
+
+	val, stale, ok := c.GetStale("key1")
+	assert.True(t, ok)
+	assert.False(t, stale)
+	assert.Eq(t, "v1", val)
+
+	time.Sleep(30 * time.Millisecond)
+	val, stale, ok = c.GetStale("key2")
+	assert.True(t, ok)
+	assert.True(t, stale)
+	assert.Eq(t, "v2", val)
+	assert.Eq(t, uint64(0), c.Stats().Hits)
+
+	// removed on Get
+	assert.Nil(t, c.Val("key2"))
+	_, _, ok = c.GetStale("key2")
+	assert.False(t, ok)
+	_, _, ok = c.GetStale("not-exists")
+	assert.False(t, ok)
+
+	c.BumpGeneration()
+	_, _, ok = c.GetStale("key1")
+	assert.False(t, ok)
+}
+
+func TestCache_Stats_lockWaits(t *testing.T) {
+	c := lcache.New()
+	assert.Eq(t, time.Duration(0), c.Stats().AvgLockWait())
+
+	started := make(chan struct{})
+	go func() {
+		_ = c.Update(func(tx *lcache.Tx) error {
+			close(started)
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		})
+	}()
+
+	<-started
+	c.Get("key")
+	st := c.Stats()
+	assert.Eq(t, uint64(1), st.LockWaits)
+	assert.Gt(t, st.AvgLockWait(), 10*time.Millisecond)
+}
+
+func TestCache_Peek(t *testing.T) {
+	c := lcache.New(lcache.WithCapacity(2))
+	c.Set("key1", "v1", 0)
+	c.Set("key2", "v2", 20*time.Millisecond)
+
+	val, ok := c.Peek("key1")
+	assert.True(t, ok)
+	assert.Eq(t, "v1", val)
+	assert.Eq(t, uint64(0), c.Stats().Hits)
+
+	// key1 is still the least recently used
+	c.Set("key3", "v3", 0)
+	assert.False(t, c.Has("key1"))
+
+	time.Sleep(30 * time.Millisecond)
+	_, ok = c.Peek("key2")
+	assert.False(t, ok)
+	_, ok = c.Peek("not-exists")
+	assert.False(t, ok)
+	assert.Eq(t, uint64(0), c.Stats().Misses)
+}
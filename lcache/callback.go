@@ -0,0 +1,128 @@
+package lcache
+
+import "time"
+
+// removedEvent 等待异步调用删除回调的事件. replaced 为 true 表示数据项被新值替换
+type removedEvent struct {
+	key      string
+	it       *Item
+	reason   RemoveReason
+	replaced bool
+}
+
+// hasRemovedFn 是否配置了删除回调
+func (c *Cache) hasRemovedFn(it *Item) bool {
+	return it.onEvict != nil || c.opt.OnEvicted != nil || c.opt.OnRemoved != nil ||
+		(it.grp != nil && it.grp.opt.OnEvicted != nil)
+}
+
+// notifyRemoved 调用删除回调. 开启异步回调时加入队列，由后台 goroutine 在锁外调用
+func (c *Cache) notifyRemoved(key string, it *Item, reason RemoveReason) {
+	if c.hasRemovedFn(it) || (reason == ReasonExpired && c.opt.OnExpired != nil) {
+		c.dispatch(removedEvent{key: key, it: it, reason: reason})
+	}
+}
+
+// notifyReplaced 数据项被新值替换时，调用其自身的淘汰回调. see SetWithOnEvict
+func (c *Cache) notifyReplaced(key string, it *Item) {
+	if it.onEvict != nil {
+		c.dispatch(removedEvent{key: key, it: it, replaced: true})
+	}
+}
+
+// dispatch 调用或异步调用删除回调. 调用方持有缓存的写锁，队列已满时不等待，丢弃并计数
+func (c *Cache) dispatch(e removedEvent) {
+	c.cbMu.Lock()
+	if c.cbRunning {
+		select {
+		case c.cbQueue <- e:
+		default:
+			c.cbDrops.Add(1)
+		}
+		c.cbMu.Unlock()
+		return
+	}
+	c.cbMu.Unlock()
+
+	c.callRemoved(e)
+}
+
+// callRemoved 调用删除回调. 数据项自身的回调优先，分组配置了回调时覆盖全局的回调
+func (c *Cache) callRemoved(e removedEvent) {
+	key, it := e.key, e.it
+	if it.onEvict != nil {
+		it.onEvict(key, it.Val)
+	} else if it.grp != nil && it.grp.opt.OnEvicted != nil {
+		it.grp.opt.OnEvicted(key, it.Val)
+	} else if c.opt.OnEvicted != nil {
+		c.opt.OnEvicted(key, it.Val)
+	}
+
+	if c.opt.OnRemoved != nil && !e.replaced {
+		c.opt.OnRemoved(key, it.Val, e.reason)
+	}
+
+	// 因世代失效的数据项 Exp 可能为 0，过期时间为零值
+	if c.opt.OnExpired != nil && e.reason == ReasonExpired && !e.replaced {
+		var expiredAt time.Time
+		if it.Exp > 0 {
+			expiredAt = time.UnixMilli(it.Exp)
+		}
+		c.opt.OnExpired(key, it.Val, expiredAt)
+	}
+}
+
+// startCallbacks 开启异步回调时启动后台 goroutine
+func (c *Cache) startCallbacks() {
+	c.cbMu.Lock()
+	defer c.cbMu.Unlock()
+	if !c.opt.AsyncCallbacks || c.cbRunning {
+		return
+	}
+
+	size := c.opt.CallbackQueueSize
+	if size <= 0 {
+		size = 1024
+	}
+
+	c.cbRunning = true
+	c.cbQueue = make(chan removedEvent, size)
+	c.cbStop = make(chan struct{})
+	c.cbDone = make(chan struct{})
+	go c.runCallbacks(c.cbQueue, c.cbStop, c.cbDone)
+}
+
+// runCallbacks 按删除顺序调用队列中的回调. 停止时调用完剩余的回调后退出
+func (c *Cache) runCallbacks(queue chan removedEvent, stop, done chan struct{}) {
+	defer close(done)
+	for {
+		select {
+		case e := <-queue:
+			c.callRemoved(e)
+		case <-stop:
+			for {
+				select {
+				case e := <-queue:
+					c.callRemoved(e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// stopCallbacks 停止后台 goroutine，等待队列中的回调全部调用完成. 之后的回调同步调用
+func (c *Cache) stopCallbacks() {
+	c.cbMu.Lock()
+	if !c.cbRunning {
+		c.cbMu.Unlock()
+		return
+	}
+	c.cbRunning = false
+	stop, done := c.cbStop, c.cbDone
+	c.cbMu.Unlock()
+
+	close(stop)
+	<-done
+}
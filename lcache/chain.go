@@ -0,0 +1,123 @@
+package lcache
+
+import "time"
+
+// CacheInterface the common cache API, implemented by Cache, Ring and Chain.
+type CacheInterface interface {
+	Get(key string) (any, bool)
+	Set(key string, value any, ttl time.Duration)
+	Has(key string) bool
+	Delete(key string) bool
+	Keys() []string
+	Len() int
+	Clear()
+}
+
+var (
+	_ CacheInterface = (*Cache)(nil)
+	_ CacheInterface = (*Ring)(nil)
+	_ CacheInterface = (*Chain)(nil)
+)
+
+// Chain a multi-level fallback cache. Get from the first level that has the key
+// and promotes the value to the previous levels, Set into all levels.
+//
+// eg: combine a tiny hot cache with a big compressed cache.
+type Chain struct {
+	levels []CacheInterface
+	// PromoteTTL TTL for promote the value to the previous levels. default is 1 minute
+	PromoteTTL time.Duration
+}
+
+// NewChain create a chained cache, the caches are ordered from fastest to slowest.
+//
+// Usage:
+//
+//	hot := lcache.New(lcache.WithCapacity(100))
+//	big := lcache.New(lcache.WithCapacity(100000))
+//	c := lcache.NewChain(hot, big)
+func NewChain(caches ...CacheInterface) *Chain {
+	if len(caches) == 0 {
+		panic("lcache: chain caches is empty")
+	}
+	return &Chain{levels: caches, PromoteTTL: time.Minute}
+}
+
+// Levels get the caches of the chain
+func (c *Chain) Levels() []CacheInterface { return c.levels }
+
+// Get value from the first level that has the key, and promote it to the previous levels.
+func (c *Chain) Get(key string) (any, bool) {
+	for i, level := range c.levels {
+		val, ok := level.Get(key)
+		if !ok {
+			continue
+		}
+
+		for j := 0; j < i; j++ {
+			c.levels[j].Set(key, val, c.PromoteTTL)
+		}
+		return val, true
+	}
+	return nil, false
+}
+
+// Val get value by key, not return exists
+func (c *Chain) Val(key string) any {
+	val, _ := c.Get(key)
+	return val
+}
+
+// Set value into all levels
+func (c *Chain) Set(key string, value any, ttl time.Duration) {
+	for _, level := range c.levels {
+		level.Set(key, value, ttl)
+	}
+}
+
+// Has check the key exists in any level
+func (c *Chain) Has(key string) bool {
+	for _, level := range c.levels {
+		if level.Has(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Delete the key from all levels, returns true if it exists in any level
+func (c *Chain) Delete(key string) (exists bool) {
+	for _, level := range c.levels {
+		if level.Delete(key) {
+			exists = true
+		}
+	}
+	return
+}
+
+// Keys get the unique keys of all levels
+func (c *Chain) Keys() []string {
+	seen := make(map[string]struct{})
+	var keys []string
+	for _, level := range c.levels {
+		for _, key := range level.Keys() {
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// Len get the number of unique keys of all levels.
+//
+// NOTE: it will traverse the keys of all levels
+func (c *Chain) Len() int { return len(c.Keys()) }
+
+// Clear all levels
+func (c *Chain) Clear() {
+	for _, level := range c.levels {
+		level.Clear()
+	}
+}
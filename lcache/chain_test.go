@@ -0,0 +1,47 @@
+package lcache_test
+
+import (
+	"testing"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestChain(t *testing.T) {
+	hot := lcache.New(lcache.WithCapacity(2))
+	big := lcache.New(lcache.WithCapacity(100))
+	c := lcache.NewChain(hot, big)
+
+	c.Set("key1", "val1", 0)
+	assert.True(t, hot.Has("key1"))
+	assert.True(t, big.Has("key1"))
+
+	// promote to hot level
+	big.Set("key2", "val2", 0)
+	assert.False(t, hot.Has("key2"))
+	assert.Eq(t, "val2", c.Val("key2"))
+	assert.True(t, hot.Has("key2"))
+
+	_, ok := c.Get("not-exists")
+	assert.False(t, ok)
+
+	big.Set("key3", "val3", 0)
+	assert.True(t, c.Has("key3"))
+	assert.Eq(t, 3, c.Len())
+
+	assert.True(t, c.Delete("key2"))
+	assert.False(t, hot.Has("key2"))
+	assert.False(t, big.Has("key2"))
+
+	c.Clear()
+	assert.Eq(t, 0, c.Len())
+
+	// chain of chain
+	c2 := lcache.NewChain(lcache.New(), c)
+	c2.Set("key4", "val4", 0)
+	assert.Eq(t, "val4", big.Val("key4"))
+
+	assert.Panics(t, func() {
+		lcache.NewChain()
+	})
+}
@@ -0,0 +1,145 @@
+package lcache
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Clone create an independent copy of the cache, with the valid items, expirations,
+// LRU order and groups. The values are shallow copied, use CloneDeep to copy them.
+//
+// The clone uses the same options, but without the background tasks and files:
+// auto-save, AOF and write-behind. It is not frozen even if the cache is frozen.
+// The Store, loaders and callbacks(eg: OnSet, OnEvicted) are also not copied,
+// so the writes to the clone never reach the backend of the cache.
+// For a namespace view, clones the whole cache and returns the same view of the clone.
+//
+// Returns nil if the lock cannot be acquired in time. see WithLockTimeout
+func (c *Cache) Clone() *Cache {
+	nc, _ := c.clone(false)
+	return nc
+}
+
+// CloneDeep like Clone, but the values are deep copied by a round-trip of the
+// configured serializer. So the value types are same as LoadFile, see RegisterType
+//
+// Returns ErrBusy if the lock cannot be acquired in time. see WithLockTimeout
+func (c *Cache) CloneDeep() (*Cache, error) {
+	return c.clone(true)
+}
+
+// cloneEntry 按 LRU 顺序复制的数据项
+type cloneEntry struct {
+	key string
+	it  *Item
+	grp string
+}
+
+func (c *Cache) clone(deep bool) (*Cache, error) {
+	if !c.rlock() {
+		return nil, ErrBusy
+	}
+	opt := c.opt
+	gen := c.gen
+	groups := make(map[string]GroupOptions, len(c.groups))
+	for name, g := range c.groups {
+		groups[name] = g.opt
+	}
+
+	entries := make([]cloneEntry, 0, len(c.items))
+	nowUm := time.Now().UnixMilli()
+	add := func(key string, v *Item) {
+		if c.invalid(v, nowUm) {
+			return
+		}
+
+		it := &Item{Val: v.Val, Exp: v.Exp, Gen: v.Gen, Key: v.Key, ttl: v.ttl, hits: atomic.LoadUint64(&v.hits),
+			created: v.created, access: atomic.LoadInt64(&v.access), tags: v.tags, cost: v.cost, prio: v.prio}
+		e := cloneEntry{key: key, it: it}
+		if v.grp != nil {
+			e.grp = v.grp.name
+		}
+		entries = append(entries, e)
+	}
+
+	// 从最久未使用的开始，按顺序写入后 LRU 顺序保持不变
+	if opt.DisableLRU {
+		for key, v := range c.items {
+			add(key, v)
+		}
+	} else {
+		for elem := c.lruList.Back(); elem != nil; elem = elem.Prev() {
+			key := elem.Value.(string)
+			add(key, c.items[key])
+		}
+	}
+	c.mu.RUnlock()
+
+	if deep {
+		if err := c.deepCopy(gen, entries); err != nil {
+			return nil, err
+		}
+	}
+
+	// 不启动后台任务，也不共用文件
+	opt.AOFFile, opt.AutoSaveFile, opt.AutoSaveInterval = "", "", 0
+	opt.WriteBehindInterval, opt.Frozen = 0, false
+	// 不写入同一个 Store，不共用加载函数、回调和外部组件
+	opt.Store, opt.Loader, opt.RefreshLoader = nil, nil, nil
+	opt.OnSet, opt.OnEvicted, opt.OnRemoved, opt.OnExpired = nil, nil, nil, nil
+	opt.AlertFn, opt.OnStoreErr, opt.KeyTracker = nil, nil, nil
+	opt.MissFilter, opt.Executor, opt.KeyLocker, opt.FlightGroup = nil, nil, nil, nil
+
+	nc := New(func(o *Options) { *o = opt })
+	nc.gen = gen
+	for name, gOpt := range groups {
+		nc.DefineGroup(name, gOpt)
+	}
+	for _, e := range entries {
+		if e.grp != "" {
+			e.it.grp = nc.groups[e.grp]
+		}
+		nc.putItem(e.key, e.it)
+	}
+
+	if c.ns != "" {
+		return &Cache{core: nc.core, ns: c.ns}, nil
+	}
+	return nc, nil
+}
+
+// deepCopy 使用序列化器编码再解码，替换 entries 中的值
+func (c *Cache) deepCopy(gen uint64, entries []cloneEntry) error {
+	serializer, err := c.serializer()
+	if err != nil {
+		return err
+	}
+
+	items := make(map[string]*Item, len(entries))
+	for _, e := range entries {
+		items[e.key] = &Item{Val: e.it.Val, Exp: e.it.Exp, Gen: e.it.Gen, Key: e.it.Key, Typ: typeName(e.it.Val)}
+	}
+
+	bs, err := serializer.Encode(&snapshot{Gen: gen, Items: items})
+	if err != nil {
+		return err
+	}
+
+	var data snapshot
+	if err = serializer.Decode(bs, &data); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		it, ok := data.Items[e.key]
+		if !ok {
+			return fmt.Errorf("lcache: clone the value of key %q failed", e.key)
+		}
+		if err = restoreType(it); err != nil {
+			return fmt.Errorf("lcache: restore value type for key %q: %w", e.key, err)
+		}
+		e.it.Val = it.Val
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package lcache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_Clone(t *testing.T) {
+	c := lcache.New(lcache.WithCapacity(4), lcache.WithLRUMode(lcache.LRUExact))
+	grp := c.DefineGroup("grp", lcache.GroupOptions{Policy: lcache.PolicyFIFO})
+	c.Set("key1", []int{1, 2}, 0)
+	c.Set("key2", 2, time.Hour)
+	grp.Set("key3", 3)
+	c.Set("expired", 4, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	c.Get("key1")
+
+	nc := c.Clone()
+	assert.Eq(t, 3, nc.Len())
+	assert.Eq(t, c.Export(), nc.Export())
+	key, _ := nc.OldestKey()
+	assert.Eq(t, "key2", key)
+	assert.NotNil(t, nc.Group("grp"))
+
+	// independent copy, the oldest key2 is evicted
+	nc.Set("key4", 4, 0)
+	nc.Set("key5", 5, 0)
+	assert.False(t, c.Has("key4"))
+	assert.False(t, nc.Has("key2"))
+	c.Delete("key1")
+	assert.True(t, nc.Has("key1"))
+
+	// values are shallow copied
+	c.Set("key1", []int{1, 2}, 0)
+	nc = c.Clone()
+	val, _ := c.Get("key1")
+	val.([]int)[0] = 10
+	assert.Eq(t, []int{10, 2}, nc.Val("key1"))
+
+	// namespace view
+	ns := c.Namespace("ns")
+	ns.Set("key", "val", 0)
+	nns := ns.Clone()
+	assert.Eq(t, "val", nns.Val("key"))
+	nns.Clear()
+	assert.True(t, ns.Has("key"))
+
+	// not write through to the store, not call the callbacks
+	s := newMemStore()
+	var sets int
+	c = lcache.New(lcache.WithStore(s), lcache.WithOnSetFn(func(string, any, time.Duration) {
+		sets++
+	}))
+	c.Set("key1", 1, 0)
+	nc = c.Clone()
+	nc.Set("key2", 2, 0)
+	nc.Delete("key1")
+	assert.Eq(t, 1, sets)
+	assert.Eq(t, map[string]any{"key1": 1}, s.data)
+	_, ok := nc.Get("key1")
+	assert.False(t, ok)
+	assert.Eq(t, 0, s.loads)
+}
+
+func TestCache_CloneDeep(t *testing.T) {
+	c := lcache.New(lcache.WithSerializer("gob"))
+	c.Set("key1", []int{1, 2}, 0)
+	c.Set("key2", "val", time.Hour)
+
+	nc, err := c.CloneDeep()
+	assert.NoErr(t, err)
+	assert.Eq(t, c.Export(), nc.Export())
+
+	val, _ := c.Get("key1")
+	val.([]int)[0] = 10
+	assert.Eq(t, []int{1, 2}, nc.Val("key1"))
+}
@@ -0,0 +1,263 @@
+// Command lcachectl inspect and convert the lcache snapshot files written by Cache.SaveFile.
+//
+// Usage:
+//
+//	lcachectl info cache.json
+//	lcachectl dump cache.json --match "user:*" --no-expired
+//	lcachectl convert cache.json cache.gob --to gob
+//	lcachectl merge all.json a.json b.json
+//	lcachectl strip cache.json
+//
+// Install:
+//
+//	go install github.com/gookit/ext/lcache/cmd/lcachectl@latest
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/cflag/capp"
+)
+
+// out 命令的输出，方便测试
+var out io.Writer = os.Stdout
+
+// globalOpts 全局选项
+type globalOpts struct {
+	serializer string
+	key        string
+	gzip       bool
+}
+
+// readOpts 读取快照文件的选项
+func (o *globalOpts) readOpts() []lcache.OptionFn {
+	fns := []lcache.OptionFn{lcache.WithSerializer(o.serializer)}
+	if o.key != "" {
+		fns = append(fns, lcache.WithSaveEncryption([]byte(o.key)))
+	}
+	return fns
+}
+
+// writeOpts 写入快照文件的选项
+func (o *globalOpts) writeOpts(serializer string) []lcache.OptionFn {
+	fns := []lcache.OptionFn{lcache.WithSerializer(serializer)}
+	if o.key != "" {
+		fns = append(fns, lcache.WithSaveEncryption([]byte(o.key)))
+	}
+	if o.gzip {
+		fns = append(fns, lcache.WithSaveCompression(lcache.CompressGzip))
+	}
+	return fns
+}
+
+func (o *globalOpts) read(filename string) (*lcache.Snapshot, error) {
+	snap, err := lcache.ReadSnapshot(filename, o.readOpts()...)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", filename, err)
+	}
+	return snap, nil
+}
+
+func newApp() *capp.App {
+	opts := &globalOpts{}
+	app := capp.NewWith("lcachectl", "0.1.0", "inspect and convert the lcache snapshot files")
+	app.StringVar(&opts.serializer, "serializer", "json", "serializer for the files without header(old format);;s")
+	app.StringVar(&opts.key, "key", "", "AES key of the encrypted snapshot files, 16/24/32 bytes;;k")
+	app.BoolVar(&opts.gzip, "gzip", false, "gzip compress the written snapshot files;;z")
+
+	app.Add(
+		infoCmd(opts),
+		dumpCmd(opts),
+		convertCmd(opts),
+		mergeCmd(opts),
+		stripCmd(opts),
+	)
+	return app
+}
+
+func infoCmd(opts *globalOpts) *capp.Cmd {
+	cmd := capp.NewCmd("info", "show the summary of a snapshot file")
+	cmd.AddArg("file", "the snapshot file", true)
+	cmd.Func = func(c *capp.Cmd) error {
+		snap, err := opts.read(c.Arg("file").String())
+		if err != nil {
+			return err
+		}
+
+		nowUm := time.Now().UnixMilli()
+		var expired, noExp int
+		for _, it := range snap.Items {
+			if it.Exp == 0 {
+				noExp++
+			} else if it.Exp < nowUm {
+				expired++
+			}
+		}
+
+		fmt.Fprintf(out, "serializer: %s\ngeneration: %d\nitems: %d\nexpired: %d\nno expiration: %d\n",
+			snap.Serializer, snap.Gen, len(snap.Items), expired, noExp)
+		return nil
+	}
+	return cmd
+}
+
+func dumpCmd(opts *globalOpts) *capp.Cmd {
+	var match string
+	var noExpired, asJSON bool
+
+	cmd := capp.NewCmd("dump", "pretty print the items of a snapshot file, sorted by key")
+	cmd.StringVar(&match, "match", "", "only print the keys matched the glob pattern;;m")
+	cmd.BoolVar(&noExpired, "no-expired", false, "do not print the expired items;;n")
+	cmd.BoolVar(&asJSON, "json", false, "print the items as a JSON object;;j")
+	cmd.AddArg("file", "the snapshot file", true)
+	cmd.Func = func(c *capp.Cmd) error {
+		snap, err := opts.read(c.Arg("file").String())
+		if err != nil {
+			return err
+		}
+
+		nowUm := time.Now().UnixMilli()
+		keys := make([]string, 0, len(snap.Items))
+		for key, it := range snap.Items {
+			if match != "" && !lcache.GlobMatch(match, key) {
+				continue
+			}
+			if noExpired && isExpired(it, nowUm) {
+				continue
+			}
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		if asJSON {
+			items := make(map[string]*lcache.Item, len(keys))
+			for _, key := range keys {
+				items[key] = snap.Items[key]
+			}
+
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			return enc.Encode(items)
+		}
+
+		for _, key := range keys {
+			it := snap.Items[key]
+			val, err := json.Marshal(it.Val)
+			if err != nil {
+				val = []byte(fmt.Sprintf("%#v", it.Val))
+			}
+
+			exp := "never"
+			if it.Exp > 0 {
+				exp = time.UnixMilli(it.Exp).Format(time.RFC3339)
+				if isExpired(it, nowUm) {
+					exp += "(expired)"
+				}
+			}
+			fmt.Fprintf(out, "%s\t%s\t%s\n", key, exp, val)
+		}
+		return nil
+	}
+	return cmd
+}
+
+func convertCmd(opts *globalOpts) *capp.Cmd {
+	var to string
+
+	cmd := capp.NewCmd("convert", "convert a snapshot file to another serializer. eg: json, gob, lcbin")
+	cmd.StringVar(&to, "to", "", "the serializer of the output file, default is same as the input;;t")
+	cmd.AddArg("src", "the input snapshot file", true)
+	cmd.AddArg("dst", "the output snapshot file", true)
+	cmd.Func = func(c *capp.Cmd) error {
+		snap, err := opts.read(c.Arg("src").String())
+		if err != nil {
+			return err
+		}
+
+		if to == "" {
+			to = snap.Serializer
+		}
+		if !lcache.HasSerializer(to) {
+			return fmt.Errorf("serializer %q is not registered, available: %v", to, lcache.Serializers())
+		}
+		return lcache.WriteSnapshot(c.Arg("dst").String(), snap, opts.writeOpts(to)...)
+	}
+	return cmd
+}
+
+func mergeCmd(opts *globalOpts) *capp.Cmd {
+	var keepFirst bool
+
+	cmd := capp.NewCmd("merge", "merge multiple snapshot files into one, later files overwrite the earlier on conflict")
+	cmd.BoolVar(&keepFirst, "keep-first", false, "keep the item of the earlier file on conflict;;f")
+	cmd.AddArg("dst", "the output snapshot file", true)
+	cmd.AddArg("src", "the input snapshot files", true, nil, true)
+	cmd.Func = func(c *capp.Cmd) error {
+		merged := &lcache.Snapshot{Items: make(map[string]*lcache.Item)}
+		for _, file := range c.Arg("src").Strings() {
+			snap, err := opts.read(file)
+			if err != nil {
+				return err
+			}
+
+			if merged.Serializer == "" {
+				merged.Serializer = snap.Serializer
+			}
+			merged.Gen = max(merged.Gen, snap.Gen)
+			for key, it := range snap.Items {
+				if _, ok := merged.Items[key]; ok && keepFirst {
+					continue
+				}
+				merged.Items[key] = it
+			}
+		}
+
+		fmt.Fprintf(out, "merged %d items\n", len(merged.Items))
+		return lcache.WriteSnapshot(c.Arg("dst").String(), merged, opts.writeOpts(merged.Serializer)...)
+	}
+	return cmd
+}
+
+func stripCmd(opts *globalOpts) *capp.Cmd {
+	cmd := capp.NewCmd("strip", "remove the expired items from a snapshot file")
+	cmd.AddArg("src", "the input snapshot file", true)
+	cmd.AddArg("dst", "the output snapshot file, default overwrite the input", false)
+	cmd.Func = func(c *capp.Cmd) error {
+		src := c.Arg("src").String()
+		snap, err := opts.read(src)
+		if err != nil {
+			return err
+		}
+
+		nowUm := time.Now().UnixMilli()
+		var n int
+		for key, it := range snap.Items {
+			if isExpired(it, nowUm) {
+				delete(snap.Items, key)
+				n++
+			}
+		}
+
+		dst := c.Arg("dst").String()
+		if dst == "" {
+			dst = src
+		}
+		fmt.Fprintf(out, "removed %d expired items\n", n)
+		return lcache.WriteSnapshot(dst, snap, opts.writeOpts(snap.Serializer)...)
+	}
+	return cmd
+}
+
+func isExpired(it *lcache.Item, nowUm int64) bool {
+	return it.Exp > 0 && it.Exp < nowUm
+}
+
+func main() {
+	newApp().Run()
+}
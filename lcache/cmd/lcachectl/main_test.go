@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func runCmd(t *testing.T, args ...string) string {
+	buf := new(bytes.Buffer)
+	out = buf
+	assert.NoErr(t, newApp().RunWithArgs(args))
+	return buf.String()
+}
+
+func TestCommands(t *testing.T) {
+	dir := t.TempDir()
+	file1, file2 := dir+"/a.json", dir+"/b.json"
+
+	c := lcache.New()
+	c.Set("user:1", "tom", 0)
+	c.Set("user:2", "jack", time.Hour)
+	c.Set("temp", 1, 20*time.Millisecond)
+	assert.NoErr(t, c.SaveFile(file1))
+
+	c2 := lcache.New(lcache.WithGeneration(5))
+	c2.Set("user:1", "inhere", 0)
+	c2.Set("post:1", "hi", 0)
+	assert.NoErr(t, c2.SaveFile(file2))
+	time.Sleep(30 * time.Millisecond)
+
+	s := runCmd(t, "info", file1)
+	assert.StrContains(t, s, "serializer: json\n")
+	assert.StrContains(t, s, "items: 3\nexpired: 1\n")
+
+	s = runCmd(t, "dump", file1, "--match", "user:*")
+	assert.StrContains(t, s, "user:1\tnever\t\"tom\"\n")
+	assert.NotContains(t, s, "temp")
+	assert.StrContains(t, runCmd(t, "dump", file1), "(expired)")
+	assert.NotContains(t, runCmd(t, "dump", "--no-expired", file1), "temp")
+	assert.StrContains(t, runCmd(t, "dump", "--json", file1), `"user:2": {`)
+
+	// convert
+	gobFile := dir + "/a.gob"
+	runCmd(t, "--gzip", "convert", "--to", "gob", file1, gobFile)
+	snap, err := lcache.ReadSnapshot(gobFile)
+	assert.NoErr(t, err)
+	assert.Eq(t, "gob", snap.Serializer)
+	assert.Eq(t, "tom", snap.Items["user:1"].Val)
+	assert.Err(t, newApp().RunWithArgs([]string{"convert", "--to", "yaml", file1, gobFile}))
+
+	// gob -> lcbin -> json
+	binFile, jsonFile := dir+"/a.lcbin", dir+"/a2.json"
+	runCmd(t, "convert", "--to", "lcbin", gobFile, binFile)
+	assert.StrContains(t, runCmd(t, "info", binFile), "serializer: lcbin\n")
+	runCmd(t, "convert", "--to", "json", binFile, jsonFile)
+	snap, err = lcache.ReadSnapshot(jsonFile)
+	assert.NoErr(t, err)
+	assert.Eq(t, "json", snap.Serializer)
+	assert.Len(t, snap.Items, 3)
+	assert.Eq(t, "jack", snap.Items["user:2"].Val)
+	assert.Gt(t, snap.Items["user:2"].Exp, int64(0))
+
+	// merge
+	merged := dir + "/all.json"
+	assert.Eq(t, "merged 4 items\n", runCmd(t, "merge", merged, file1, file2))
+	snap, err = lcache.ReadSnapshot(merged)
+	assert.NoErr(t, err)
+	assert.Eq(t, "inhere", snap.Items["user:1"].Val)
+	assert.Eq(t, uint64(5), snap.Gen)
+
+	runCmd(t, "merge", "--keep-first", merged, file1, file2)
+	snap, err = lcache.ReadSnapshot(merged)
+	assert.NoErr(t, err)
+	assert.Eq(t, "tom", snap.Items["user:1"].Val)
+
+	// strip
+	assert.Eq(t, "removed 1 expired items\n", runCmd(t, "strip", file1))
+	snap, err = lcache.ReadSnapshot(file1)
+	assert.NoErr(t, err)
+	assert.Len(t, snap.Items, 2)
+}
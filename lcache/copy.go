@@ -0,0 +1,120 @@
+package lcache
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// CopyFn deep copy the value, use for copy-on-read. see WithCopyOnRead
+type CopyFn func(val any) (any, error)
+
+// readCopy 开启 CopyOnRead 时复制读取的值，避免调用方修改缓存中的值.
+// 字符串、数值等不可变的值直接返回，复制失败时返回 ErrCopy，不返回共享的原值
+func (c *Cache) readCopy(val any) (any, error) {
+	if !c.opt.CopyOnRead || val == nil {
+		return val, nil
+	}
+
+	switch reflect.TypeOf(val).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Pointer, reflect.Struct, reflect.Array, reflect.Interface:
+	default:
+		return val, nil
+	}
+
+	if c.opt.CopyFn == nil {
+		return DeepCopy(val), nil
+	}
+
+	cp, err := c.opt.CopyFn(val)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCopy, err)
+	}
+	return cp, nil
+}
+
+// loadedCopy 复制加载的值. 复制失败时不返回加载的值，返回复制错误和加载错误
+func (c *Cache) loadedCopy(val any, err error) (any, error) {
+	cp, cerr := c.readCopy(val)
+	if cerr != nil {
+		return nil, errors.Join(cerr, err)
+	}
+	return cp, err
+}
+
+// DeepCopy returns a deep copy of the value with the same type, the default copy func
+// of WithCopyOnRead. The maps, slices, arrays, pointers and the exported struct fields
+// are copied recursively, the shared and cyclic pointers are kept shared in the copy.
+//
+// The unexported struct fields, channels and funcs are copied shallowly,
+// eg: the *time.Location of a time.Time is shared.
+func DeepCopy(val any) any {
+	if val == nil {
+		return nil
+	}
+
+	ptrs := make(map[uintptr]reflect.Value)
+	return deepCopy(reflect.ValueOf(val), ptrs).Interface()
+}
+
+// deepCopy 递归复制值. ptrs 记录已复制的指针，保持共享和循环引用
+func deepCopy(v reflect.Value, ptrs map[uintptr]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		if cp, ok := ptrs[v.Pointer()]; ok {
+			return cp
+		}
+
+		cp := reflect.New(v.Type().Elem())
+		ptrs[v.Pointer()] = cp
+		cp.Elem().Set(deepCopy(v.Elem(), ptrs))
+		return cp
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(deepCopy(v.Elem(), ptrs))
+		return cp
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			cp.SetMapIndex(iter.Key(), deepCopy(iter.Value(), ptrs))
+		}
+		return cp
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(deepCopy(v.Index(i), ptrs))
+		}
+		return cp
+	case reflect.Array:
+		cp := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(deepCopy(v.Index(i), ptrs))
+		}
+		return cp
+	case reflect.Struct:
+		// 先整体复制(含未导出字段)，再递归复制导出字段
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				cp.Field(i).Set(deepCopy(v.Field(i), ptrs))
+			}
+		}
+		return cp
+	default:
+		return v
+	}
+}
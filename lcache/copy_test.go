@@ -0,0 +1,104 @@
+package lcache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_WithCopyOnRead(t *testing.T) {
+	c := lcache.New(lcache.WithCopyOnRead(true))
+	c.Set("map", map[string]any{"name": "inhere"}, 0)
+	c.Set("str", "abc", 0)
+
+	m := c.Val("map").(map[string]any)
+	m["name"] = "changed"
+	assert.Eq(t, "inhere", c.Val("map").(map[string]any)["name"])
+	assert.Eq(t, "abc", c.Val("str"))
+
+	vals := c.MGet("map")
+	vals["map"].(map[string]any)["name"] = "changed"
+	val, stale, ok := c.GetStale("map")
+	assert.True(t, ok)
+	assert.False(t, stale)
+	assert.Eq(t, "inhere", val.(map[string]any)["name"])
+
+	// loaded value
+	val, err := c.GetOrLoad("loaded", 0, func(key string) (any, error) {
+		return map[string]any{"key": key}, nil
+	})
+	assert.NoErr(t, err)
+	val.(map[string]any)["key"] = "changed"
+	assert.Eq(t, "loaded", c.Val("loaded").(map[string]any)["key"])
+
+	// disabled
+	c = lcache.New()
+	c.Set("map", map[string]any{"name": "inhere"}, 0)
+	c.Val("map").(map[string]any)["name"] = "changed"
+	assert.Eq(t, "changed", c.Val("map").(map[string]any)["name"])
+}
+
+func TestCache_WithCopyFn(t *testing.T) {
+	c := lcache.New(lcache.WithCopyFn(func(val any) (any, error) {
+		src, ok := val.([]int)
+		if !ok {
+			return nil, errors.New("unsupported type")
+		}
+		return append([]int(nil), src...), nil
+	}))
+	c.Set("ints", []int{1, 2}, 0)
+	c.Set("map", map[string]int{"a": 1}, 0)
+
+	ints := c.Val("ints").([]int)
+	ints[0] = 100
+	assert.Eq(t, []int{1, 2}, c.Val("ints"))
+
+	// copy failed, a miss or ErrCopy, never the shared value
+	val, ok := c.Get("map")
+	assert.False(t, ok)
+	assert.Nil(t, val)
+	assert.Eq(t, map[string]any{"map": nil}, c.MGet("map"))
+	val, err := c.GetOrLoad("map", 0, func(string) (any, error) {
+		return map[string]int{"b": 1}, nil
+	})
+	assert.ErrIs(t, err, lcache.ErrCopy)
+	assert.Nil(t, val)
+}
+
+func TestDeepCopy(t *testing.T) {
+	type node struct {
+		Name string
+		Tags []string
+		Next *node
+		At   time.Time
+		note []int
+	}
+	n := &node{Name: "a", Tags: []string{"x"}, At: time.Now(), note: []int{1}}
+	n.Next = n
+
+	cp := lcache.DeepCopy(n).(*node)
+	assert.NotSame(t, n, cp)
+	assert.Same(t, cp, cp.Next)
+	assert.Eq(t, n.Tags, cp.Tags)
+	assert.True(t, n.At.Equal(cp.At))
+	cp.Tags[0] = "y"
+	assert.Eq(t, "x", n.Tags[0])
+	// the unexported fields are shallow copied
+	cp.note[0] = 2
+	assert.Eq(t, 2, n.note[0])
+
+	// the types are kept
+	c := lcache.New(lcache.WithCopyOnRead(true))
+	c.Set("node", n, 0)
+	c.Set("arr", [2][]int{{1}, {2}}, 0)
+	c.Set("nested", map[string]any{"list": []any{map[string]int{"a": 1}}}, 0)
+	assert.Eq(t, "a", c.Val("node").(*node).Name)
+	c.Val("arr").([2][]int)[0][0] = 100
+	assert.Eq(t, [2][]int{{1}, {2}}, c.Val("arr"))
+	c.Val("nested").(map[string]any)["list"].([]any)[0].(map[string]int)["a"] = 100
+	assert.Eq(t, 1, c.Val("nested").(map[string]any)["list"].([]any)[0].(map[string]int)["a"])
+	assert.Nil(t, lcache.DeepCopy(nil))
+}
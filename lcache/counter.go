@@ -0,0 +1,186 @@
+package lcache
+
+import (
+	"math"
+	"time"
+)
+
+// IncrBy add delta to the integer value stored at key and returns the new value, like Redis INCRBY.
+// The missing or expired key is set to delta(never expire), the TTL of the existing key is kept.
+//
+// The value is stored as int64, the existing value of any integer type or an integral float64
+// (eg: loaded from a JSON snapshot) is accepted. Returns ErrNotNumber if the value is not an
+// integer, ErrOverflow if the result overflows int64, ErrBusy, ErrFrozen or ErrCacheFull.
+func (c *Cache) IncrBy(key string, delta int64) (int64, error) {
+	var res int64
+	err := c.updateValue(key, func(val any, exists bool) (any, error) {
+		var cur int64
+		if exists {
+			var ok bool
+			if cur, ok = toInt64(val); !ok {
+				return nil, ErrNotNumber
+			}
+		}
+
+		if (delta > 0 && cur > math.MaxInt64-delta) || (delta < 0 && cur < math.MinInt64-delta) {
+			return nil, ErrOverflow
+		}
+		res = cur + delta
+		return res, nil
+	})
+	return res, err
+}
+
+// DecrBy subtract delta from the integer value stored at key and returns the new value. see IncrBy
+func (c *Cache) DecrBy(key string, delta int64) (int64, error) {
+	if delta == math.MinInt64 {
+		return 0, ErrOverflow
+	}
+	return c.IncrBy(key, -delta)
+}
+
+// IncrByFloat add delta to the float value stored at key and returns the new value,
+// like Redis INCRBYFLOAT. The value is stored as float64, the existing value of any
+// integer or float type is accepted.
+//
+// Returns ErrNotNumber if the value is not a number, ErrOverflow if the result is NaN or Infinity.
+func (c *Cache) IncrByFloat(key string, delta float64) (float64, error) {
+	var res float64
+	err := c.updateValue(key, func(val any, exists bool) (any, error) {
+		var cur float64
+		if exists {
+			var ok bool
+			if cur, ok = toFloat64(val); !ok {
+				return nil, ErrNotNumber
+			}
+		}
+
+		res = cur + delta
+		if math.IsNaN(res) || math.IsInf(res, 0) {
+			return nil, ErrOverflow
+		}
+		return res, nil
+	})
+	return res, err
+}
+
+// GetInt64 get the value as int64, converts the value of any integer type or an integral float64.
+// returns false if the key is missing or the value is not an integer.
+func (c *Cache) GetInt64(key string) (int64, bool) {
+	val, ok := c.Get(key)
+	if !ok {
+		return 0, false
+	}
+	return toInt64(val)
+}
+
+// GetFloat64 get the value as float64, converts the value of any integer or float type.
+// returns false if the key is missing or the value is not a number.
+func (c *Cache) GetFloat64(key string) (float64, bool) {
+	val, ok := c.Get(key)
+	if !ok {
+		return 0, false
+	}
+	return toFloat64(val)
+}
+
+// updateValue 加锁读取值并写入 fn 返回的新值. 保持已存在 key 的 TTL
+func (c *Cache) updateValue(key string, fn func(val any, exists bool) (any, error)) error {
+	key, err := c.normKey(key)
+	if err != nil {
+		return err
+	}
+	if !c.lock() {
+		return ErrBusy
+	}
+	defer c.unlock()
+	if c.frozen.Load() {
+		return ErrFrozen
+	}
+
+	nk := c.nsKey(key)
+	hk, it := c.find(nk)
+	if it != nil && c.invalid(it, time.Now().UnixMilli()) {
+		it = nil
+	}
+
+	var cur any
+	if it != nil {
+		cur = it.Val
+	}
+	val, err := fn(cur, it != nil)
+	if err != nil {
+		return err
+	}
+	if err = c.checkSize(key, val); err != nil {
+		return err
+	}
+
+	if it == nil {
+		if c.set(nk, val, 0) == nil {
+			return ErrCacheFull
+		}
+		return c.appendSet(nk, val, 0)
+	}
+
+	it.Val = val
+	c.updateIndexes(hk, it)
+	c.touch(hk, it)
+	return c.appendSet(nk, val, it.Exp)
+}
+
+// toInt64 转换整数类型或整数值的 float 为 int64
+func toInt64(val any) (int64, bool) {
+	switch v := val.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), uint64(v) <= math.MaxInt64
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), v <= math.MaxInt64
+	case float32:
+		return toInt64(float64(v))
+	case float64:
+		// 超出 int64 范围或有小数部分时不能转换
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// toFloat64 转换整数或 float 类型为 float64
+func toFloat64(val any) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	}
+
+	if n, ok := toInt64(val); ok {
+		return float64(n), true
+	}
+	if v, ok := val.(uint64); ok {
+		return float64(v), true
+	}
+	if v, ok := val.(uint); ok {
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,110 @@
+package lcache_test
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_IncrBy(t *testing.T) {
+	c := lcache.New()
+
+	n, err := c.IncrBy("counter", 5)
+	assert.NoErr(t, err)
+	assert.Eq(t, int64(5), n)
+	n, err = c.DecrBy("counter", 7)
+	assert.NoErr(t, err)
+	assert.Eq(t, int64(-2), n)
+
+	// existing values of other types
+	c.Set("int", 10, time.Minute)
+	n, err = c.IncrBy("int", 1)
+	assert.NoErr(t, err)
+	assert.Eq(t, int64(11), n)
+	ttl, ok := c.TTL("int")
+	assert.True(t, ok)
+	assert.Gt(t, ttl, time.Duration(0))
+
+	c.Set("float", 2.0, 0)
+	n, err = c.IncrBy("float", 1)
+	assert.NoErr(t, err)
+	assert.Eq(t, int64(3), n)
+
+	c.Set("float", 2.5, 0)
+	_, err = c.IncrBy("float", 1)
+	assert.True(t, errors.Is(err, lcache.ErrNotNumber))
+	c.Set("str", "abc", 0)
+	_, err = c.IncrBy("str", 1)
+	assert.True(t, errors.Is(err, lcache.ErrNotNumber))
+
+	// overflow
+	c.Set("max", int64(math.MaxInt64), 0)
+	_, err = c.IncrBy("max", 1)
+	assert.True(t, errors.Is(err, lcache.ErrOverflow))
+	c.Set("big", uint64(math.MaxUint64), 0)
+	_, err = c.IncrBy("big", 1)
+	assert.True(t, errors.Is(err, lcache.ErrNotNumber))
+	_, err = c.DecrBy("counter", math.MinInt64)
+	assert.True(t, errors.Is(err, lcache.ErrOverflow))
+	val, _ := c.GetInt64("max")
+	assert.Eq(t, int64(math.MaxInt64), val)
+
+	// concurrent
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, _ = c.IncrBy("hits", 1)
+			}
+		}()
+	}
+	wg.Wait()
+	val, ok = c.GetInt64("hits")
+	assert.True(t, ok)
+	assert.Eq(t, int64(1000), val)
+}
+
+func TestCache_IncrByFloat(t *testing.T) {
+	c := lcache.New()
+
+	f, err := c.IncrByFloat("sum", 1.5)
+	assert.NoErr(t, err)
+	assert.Eq(t, 1.5, f)
+
+	c.Set("int", 2, 0)
+	f, err = c.IncrByFloat("int", 0.25)
+	assert.NoErr(t, err)
+	assert.Eq(t, 2.25, f)
+
+	_, err = c.IncrByFloat("sum", math.Inf(1))
+	assert.True(t, errors.Is(err, lcache.ErrOverflow))
+	c.Set("str", "abc", 0)
+	_, err = c.IncrByFloat("str", 1)
+	assert.True(t, errors.Is(err, lcache.ErrNotNumber))
+
+	f, ok := c.GetFloat64("sum")
+	assert.True(t, ok)
+	assert.Eq(t, 1.5, f)
+	_, ok = c.GetFloat64("str")
+	assert.False(t, ok)
+	_, ok = c.GetInt64("sum")
+	assert.False(t, ok)
+
+	// snapshot round-trip, the int64 is loaded as float64
+	_, _ = c.IncrBy("counter", 3)
+	file := filepath.Join(t.TempDir(), "counter.json")
+	assert.NoErr(t, c.SaveFile(file))
+	nc := lcache.New()
+	assert.NoErr(t, nc.LoadFile(file))
+	n, err := nc.IncrBy("counter", 1)
+	assert.NoErr(t, err)
+	assert.Eq(t, int64(4), n)
+}
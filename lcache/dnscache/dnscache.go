@@ -0,0 +1,196 @@
+// Package dnscache provides a caching DNS resolver, the Go net.Resolver has no cache.
+//
+// Usage:
+//
+//	r := dnscache.New(dnscache.WithTTL(time.Minute, 5*time.Minute))
+//	addrs, err := r.LookupHost(ctx, "example.com")
+//
+//	// use in http.Transport
+//	dialer := &net.Dialer{}
+//	transport.DialContext = r.DialContext(dialer)
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+)
+
+const (
+	hostPrefix = "host:"
+	addrPrefix = "addr:"
+)
+
+// Option for the Resolver
+type Option func(r *Resolver)
+
+// WithResolver set the underlying resolver. default is net.DefaultResolver
+func WithResolver(res *net.Resolver) Option {
+	return func(r *Resolver) { r.res = res }
+}
+
+// WithTTL set the TTL bounds of the cached results. Each result is cached with a random
+// TTL in [min, max], to spread the expirations. default is [30s, 60s]
+func WithTTL(min, max time.Duration) Option {
+	if min <= 0 || max < min {
+		panic("dnscache: invalid TTL bounds")
+	}
+	return func(r *Resolver) { r.minTTL, r.maxTTL = min, max }
+}
+
+// WithNegativeTTL set the TTL for caching the not found results. 0 to disable. default is 5s
+func WithNegativeTTL(ttl time.Duration) Option {
+	return func(r *Resolver) { r.negTTL = ttl }
+}
+
+// WithCapacity set the max number of cached names. default is 1000
+func WithCapacity(capacity int) Option {
+	return func(r *Resolver) { r.capacity = capacity }
+}
+
+// WithRefreshAhead refresh the hot names in the background, when a lookup hits
+// a result past the fraction of its TTL. default is 0.8, 0 to disable.
+func WithRefreshAhead(fraction float64) Option {
+	return func(r *Resolver) { r.refresh = fraction }
+}
+
+// Resolver caches the LookupHost and LookupAddr results of a net.Resolver in a lcache.Cache.
+type Resolver struct {
+	c   *lcache.Cache
+	res *net.Resolver
+	// 实际的查找方法，默认使用 res. 方便测试
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+	lookupAddr func(ctx context.Context, addr string) ([]string, error)
+
+	minTTL, maxTTL time.Duration
+	negTTL         time.Duration
+	capacity       int
+	refresh        float64
+}
+
+// notFound 缓存的查找不到的结果
+type notFound struct {
+	Err *net.DNSError
+}
+
+// New create a caching resolver
+func New(opts ...Option) *Resolver {
+	r := &Resolver{
+		res:      net.DefaultResolver,
+		minTTL:   30 * time.Second,
+		maxTTL:   time.Minute,
+		negTTL:   5 * time.Second,
+		capacity: 1000,
+		refresh:  0.8,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.lookupHost, r.lookupAddr = r.res.LookupHost, r.res.LookupAddr
+
+	cacheOpts := []lcache.OptionFn{lcache.WithCapacity(r.capacity)}
+	if r.refresh > 0 {
+		cacheOpts = append(cacheOpts, lcache.WithRefreshAhead(r.refresh, r.reload))
+	}
+	r.c = lcache.New(cacheOpts...)
+	return r
+}
+
+// Cache get the underlying cache, eg: for Stats
+func (r *Resolver) Cache() *lcache.Cache { return r.c }
+
+// LookupHost looks up the given host, returns a slice of its addresses. see net.Resolver.LookupHost
+func (r *Resolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return r.lookup(hostPrefix+host, func() ([]string, error) {
+		return r.lookupHost(ctx, host)
+	})
+}
+
+// LookupAddr performs a reverse lookup for the given address. see net.Resolver.LookupAddr
+func (r *Resolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	return r.lookup(addrPrefix+addr, func() ([]string, error) {
+		return r.lookupAddr(ctx, addr)
+	})
+}
+
+// Invalidate remove the cached results of the host or address
+func (r *Resolver) Invalidate(name string) {
+	r.c.MDelete(hostPrefix+name, addrPrefix+name)
+}
+
+// DialContext returns a dial func that resolves the host by the cached resolver, and
+// tries the addresses in order. eg: for http.Transport.DialContext
+func (r *Resolver) DialContext(d *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if net.ParseIP(host) != nil {
+			return d.DialContext(ctx, network, addr)
+		}
+
+		ips, err := r.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		var conn net.Conn
+		for _, ip := range ips {
+			if conn, err = d.DialContext(ctx, network, net.JoinHostPort(ip, port)); err == nil {
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+}
+
+func (r *Resolver) lookup(key string, fn func() ([]string, error)) ([]string, error) {
+	val, err := r.c.GetOrLoad(key, r.ttl(), func(string) (any, error) {
+		return fn()
+	})
+	if err != nil {
+		// 查找不到时使用 negative TTL 缓存结果
+		var dnsErr *net.DNSError
+		if r.negTTL > 0 && errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			r.c.Set(key, notFound{Err: dnsErr}, r.negTTL)
+		}
+		return nil, err
+	}
+
+	switch v := val.(type) {
+	case []string:
+		return v, nil
+	case notFound:
+		return nil, v.Err
+	}
+
+	// 缓存中的数据类型错误(如从快照加载)，重新查找
+	r.c.Delete(key)
+	return fn()
+}
+
+// reload 后台刷新即将过期的结果
+func (r *Resolver) reload(key string) (any, error) {
+	ctx := context.Background()
+	if host, ok := strings.CutPrefix(key, hostPrefix); ok {
+		return r.lookupHost(ctx, host)
+	}
+	if addr, ok := strings.CutPrefix(key, addrPrefix); ok {
+		return r.lookupAddr(ctx, addr)
+	}
+	return nil, errors.New("dnscache: invalid key " + key)
+}
+
+// ttl 在 TTL 范围内随机选择，分散过期时间
+func (r *Resolver) ttl() time.Duration {
+	if r.maxTTL == r.minTTL {
+		return r.minTTL
+	}
+	return r.minTTL + rand.N(r.maxTTL-r.minTTL+1)
+}
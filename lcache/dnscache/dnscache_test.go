@@ -0,0 +1,67 @@
+package dnscache
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestResolver(t *testing.T) {
+	var calls atomic.Int32
+	r := New(WithTTL(40*time.Millisecond, 40*time.Millisecond), WithNegativeTTL(time.Minute), WithRefreshAhead(0.5))
+	r.lookupHost = func(_ context.Context, host string) ([]string, error) {
+		calls.Add(1)
+		if host == "none.test" {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return []string{"10.0.0.1"}, nil
+	}
+	r.lookupAddr = func(_ context.Context, addr string) ([]string, error) {
+		calls.Add(1)
+		return []string{"host.test."}, nil
+	}
+	ctx := context.Background()
+
+	addrs, err := r.LookupHost(ctx, "example.test")
+	assert.NoErr(t, err)
+	assert.Eq(t, []string{"10.0.0.1"}, addrs)
+	_, _ = r.LookupHost(ctx, "example.test")
+	assert.Eq(t, int32(1), calls.Load())
+
+	names, err := r.LookupAddr(ctx, "10.0.0.1")
+	assert.NoErr(t, err)
+	assert.Eq(t, []string{"host.test."}, names)
+	assert.Eq(t, int32(2), calls.Load())
+
+	// negative cache
+	_, err = r.LookupHost(ctx, "none.test")
+	assert.Err(t, err)
+	_, err = r.LookupHost(ctx, "none.test")
+	assert.ErrSubMsg(t, err, "no such host")
+	assert.Eq(t, int32(3), calls.Load())
+
+	// refresh ahead: past half of the TTL, refresh in background
+	time.Sleep(25 * time.Millisecond)
+	_, _ = r.LookupHost(ctx, "example.test")
+	time.Sleep(10 * time.Millisecond)
+	assert.Eq(t, int32(4), calls.Load())
+
+	// invalidate
+	r.Invalidate("example.test")
+	_, _ = r.LookupHost(ctx, "example.test")
+	assert.Eq(t, int32(5), calls.Load())
+}
+
+func TestResolver_ttl(t *testing.T) {
+	r := New(WithTTL(time.Second, 2*time.Second))
+	for i := 0; i < 10; i++ {
+		ttl := r.ttl()
+		assert.True(t, ttl >= time.Second && ttl <= 2*time.Second)
+	}
+
+	assert.Panics(t, func() { WithTTL(2*time.Second, time.Second) })
+}
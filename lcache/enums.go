@@ -0,0 +1,254 @@
+package lcache
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Policy eviction policy for cache items.
+type Policy uint8
+
+const (
+	// PolicyLRU evict the least recently used items first. it is default policy.
+	PolicyLRU Policy = iota
+	// PolicyFIFO evict the oldest written items first, reading an item does not change the order.
+	PolicyFIFO
+)
+
+var policyNames = []string{"lru", "fifo"}
+
+// String get policy name
+func (p Policy) String() string { return enumName(policyNames, uint8(p)) }
+
+// MarshalText implements encoding.TextMarshaler
+func (p Policy) MarshalText() ([]byte, error) { return []byte(p.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (p *Policy) UnmarshalText(text []byte) (err error) {
+	*p, err = ParsePolicy(string(text))
+	return
+}
+
+// ParsePolicy parse policy name(case-insensitive). eg: "lru", "fifo"
+func ParsePolicy(s string) (Policy, error) {
+	i, err := parseEnum("policy", policyNames, s)
+	return Policy(i), err
+}
+
+// OverflowPolicy the policy for writing a new item when the cache is full. see WithOverflowPolicy
+type OverflowPolicy uint8
+
+const (
+	// EvictOldest evict the least recently used items to make room. it is default policy.
+	EvictOldest OverflowPolicy = iota
+	// RejectNew reject the new item, SetE and SetX return ErrCacheFull.
+	RejectNew
+)
+
+var overflowNames = []string{"evict-oldest", "reject-new"}
+
+// String get overflow policy name
+func (p OverflowPolicy) String() string { return enumName(overflowNames, uint8(p)) }
+
+// ParseOverflowPolicy parse overflow policy name(case-insensitive). eg: "evict-oldest", "reject-new"
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	i, err := parseEnum("overflow policy", overflowNames, s)
+	return OverflowPolicy(i), err
+}
+
+// LRUMode the way Get maintains the LRU order. see WithLRUMode
+type LRUMode uint8
+
+const (
+	// LRUExact move the item to the front on each Get, under the write lock. it is default mode.
+	LRUExact LRUMode = iota
+	// LRUClock CLOCK(second chance): Get only marks the item as referenced under the read lock,
+	// the eviction moves the referenced items at the tail to the front instead of evicting them.
+	LRUClock
+)
+
+var lruModeNames = []string{"exact", "clock"}
+
+// String get LRU mode name
+func (m LRUMode) String() string { return enumName(lruModeNames, uint8(m)) }
+
+// ParseLRUMode parse LRU mode name(case-insensitive). eg: "exact", "clock"
+func ParseLRUMode(s string) (LRUMode, error) {
+	i, err := parseEnum("lru mode", lruModeNames, s)
+	return LRUMode(i), err
+}
+
+// clock 是否使用 CLOCK 维护 LRU 顺序
+func (m LRUMode) clock() bool { return m == LRUClock }
+
+// Priority the eviction priority of an item. see WithPriority
+type Priority uint8
+
+const (
+	// PriorityNormal the default priority of items.
+	PriorityNormal Priority = iota
+	// PriorityLow the items are evicted first, eg: cheap to recompute.
+	PriorityLow
+	// PriorityHigh the items are evicted only when there are no lower priority items.
+	PriorityHigh
+)
+
+var priorityNames = []string{"normal", "low", "high"}
+
+// evictOrder 按淘汰顺序排列的优先级
+var evictOrder = [...]Priority{PriorityLow, PriorityNormal, PriorityHigh}
+
+// String get priority name
+func (p Priority) String() string { return enumName(priorityNames, uint8(p)) }
+
+// ParsePriority parse priority name(case-insensitive). eg: "low", "normal", "high"
+func ParsePriority(s string) (Priority, error) {
+	i, err := parseEnum("priority", priorityNames, s)
+	return Priority(i), err
+}
+
+// AlertKind the kind of alert. see WithAlert
+type AlertKind uint8
+
+const (
+	// AlertLowHitRatio the hit ratio since the last janitor run is below AlertRules.MinHitRatio
+	AlertLowHitRatio AlertKind = iota
+	// AlertEvictionSpike the evictions per second since the last janitor run is above AlertRules.MaxEvictRate
+	AlertEvictionSpike
+	// AlertOverBudget the approximate memory size of the values is above AlertRules.MemoryBudget
+	AlertOverBudget
+	// alertKindCount 告警类型的数量
+	alertKindCount
+)
+
+var alertKindNames = []string{"low-hit-ratio", "eviction-spike", "over-budget"}
+
+// String get alert kind name
+func (k AlertKind) String() string { return enumName(alertKindNames, uint8(k)) }
+
+// ParseAlertKind parse alert kind name(case-insensitive). eg: "low-hit-ratio"
+func ParseAlertKind(s string) (AlertKind, error) {
+	i, err := parseEnum("alert kind", alertKindNames, s)
+	return AlertKind(i), err
+}
+
+// Compression for snapshot file.
+type Compression uint8
+
+const (
+	// CompressNone no compression. it is default.
+	CompressNone Compression = iota
+	// CompressGzip gzip compression
+	CompressGzip
+)
+
+var compressionNames = []string{"none", "gzip"}
+
+// String get compression name
+func (c Compression) String() string { return enumName(compressionNames, uint8(c)) }
+
+// MarshalText implements encoding.TextMarshaler
+func (c Compression) MarshalText() ([]byte, error) { return []byte(c.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (c *Compression) UnmarshalText(text []byte) (err error) {
+	*c, err = ParseCompression(string(text))
+	return
+}
+
+// ParseCompression parse compression name(case-insensitive). eg: "none", "gzip". empty string is "none".
+func ParseCompression(s string) (Compression, error) {
+	if s == "" {
+		return CompressNone, nil
+	}
+	i, err := parseEnum("compression", compressionNames, s)
+	return Compression(i), err
+}
+
+// RemoveReason the reason of an item removed from the cache.
+type RemoveReason uint8
+
+const (
+	// ReasonDeleted removed by Delete, MDelete or replaced by RenameNamespace
+	ReasonDeleted RemoveReason = iota
+	// ReasonEvicted evicted by the capacity limit or EvictN
+	ReasonEvicted
+	// ReasonExpired removed on expired
+	ReasonExpired
+	// ReasonCleared removed by ClearNotify
+	ReasonCleared
+)
+
+var reasonNames = []string{"deleted", "evicted", "expired", "cleared"}
+
+// String get reason name
+func (r RemoveReason) String() string { return enumName(reasonNames, uint8(r)) }
+
+// ParseRemoveReason parse reason name(case-insensitive). eg: "deleted", "evicted", "expired"
+func ParseRemoveReason(s string) (RemoveReason, error) {
+	i, err := parseEnum("remove reason", reasonNames, s)
+	return RemoveReason(i), err
+}
+
+// ItemState the state of an item in the cache. see Cache.State
+type ItemState uint8
+
+const (
+	// StateMissing the item does not exist
+	StateMissing ItemState = iota
+	// StateValid the item exists and not expired
+	StateValid
+	// StateExpired the item is expired but not yet removed
+	StateExpired
+	// StateStale the item is expired but still in the stale grace window. see WithStaleWhileRevalidate
+	StateStale
+)
+
+var stateNames = []string{"missing", "valid", "expired", "stale"}
+
+// String get state name
+func (s ItemState) String() string { return enumName(stateNames, uint8(s)) }
+
+// ParseItemState parse state name(case-insensitive). eg: "missing", "valid", "expired"
+func ParseItemState(s string) (ItemState, error) {
+	i, err := parseEnum("item state", stateNames, s)
+	return ItemState(i), err
+}
+
+// State get the state of the item by key, does not update the LRU order.
+func (c *Cache) State(key string) ItemState {
+	key, err := c.normKey(key)
+	if err != nil || !c.rlock() {
+		return StateMissing
+	}
+	defer c.mu.RUnlock()
+
+	_, it := c.find(c.nsKey(key))
+	if it == nil {
+		return StateMissing
+	}
+	if nowUm := time.Now().UnixMilli(); c.invalid(it, nowUm) {
+		if c.isStale(it, nowUm) {
+			return StateStale
+		}
+		return StateExpired
+	}
+	return StateValid
+}
+
+func enumName(names []string, i uint8) string {
+	if int(i) < len(names) {
+		return names[i]
+	}
+	return fmt.Sprintf("unknown(%d)", i)
+}
+
+func parseEnum(kind string, names []string, s string) (uint8, error) {
+	for i, name := range names {
+		if strings.EqualFold(name, s) {
+			return uint8(i), nil
+		}
+	}
+	return 0, fmt.Errorf("lcache: invalid %s %q", kind, s)
+}
@@ -0,0 +1,93 @@
+package lcache_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestEnums(t *testing.T) {
+	p, err := lcache.ParsePolicy("FIFO")
+	assert.NoErr(t, err)
+	assert.Eq(t, lcache.PolicyFIFO, p)
+	assert.Eq(t, "lru", lcache.PolicyLRU.String())
+	_, err = lcache.ParsePolicy("lfu")
+	assert.Err(t, err)
+
+	comp, err := lcache.ParseCompression("")
+	assert.NoErr(t, err)
+	assert.Eq(t, lcache.CompressNone, comp)
+	assert.Eq(t, "gzip", lcache.CompressGzip.String())
+
+	r, err := lcache.ParseRemoveReason("expired")
+	assert.NoErr(t, err)
+	assert.Eq(t, lcache.ReasonExpired, r)
+	assert.Eq(t, "unknown(9)", lcache.RemoveReason(9).String())
+
+	op, err := lcache.ParseOverflowPolicy("Reject-New")
+	assert.NoErr(t, err)
+	assert.Eq(t, lcache.RejectNew, op)
+	assert.Eq(t, "evict-oldest", lcache.EvictOldest.String())
+
+	pr, err := lcache.ParsePriority("HIGH")
+	assert.NoErr(t, err)
+	assert.Eq(t, lcache.PriorityHigh, pr)
+	assert.Eq(t, "low", lcache.PriorityLow.String())
+
+	lm, err := lcache.ParseLRUMode("Clock")
+	assert.NoErr(t, err)
+	assert.Eq(t, lcache.LRUClock, lm)
+	assert.Eq(t, "exact", lcache.LRUExact.String())
+	// the zero value is the default mode
+	assert.Eq(t, lcache.LRUExact, lcache.LRUMode(0))
+
+	ak, err := lcache.ParseAlertKind("low-hit-ratio")
+	assert.NoErr(t, err)
+	assert.Eq(t, lcache.AlertLowHitRatio, ak)
+	assert.Eq(t, "eviction-spike", lcache.AlertEvictionSpike.String())
+
+	st, err := lcache.ParseItemState("valid")
+	assert.NoErr(t, err)
+	assert.Eq(t, lcache.StateValid, st)
+
+	// used in config file
+	var cfg struct {
+		Policy      lcache.Policy      `json:"policy"`
+		Compression lcache.Compression `json:"compression"`
+	}
+	assert.NoErr(t, json.Unmarshal([]byte(`{"policy":"fifo","compression":"gzip"}`), &cfg))
+	assert.Eq(t, lcache.PolicyFIFO, cfg.Policy)
+	assert.Eq(t, lcache.CompressGzip, cfg.Compression)
+
+	bs, err := json.Marshal(cfg)
+	assert.NoErr(t, err)
+	assert.Eq(t, `{"policy":"fifo","compression":"gzip"}`, string(bs))
+}
+
+func TestCache_State(t *testing.T) {
+	var reasons []lcache.RemoveReason
+	c := lcache.New(
+		lcache.WithCapacity(2),
+		lcache.WithOnRemovedFn(func(_ string, _ any, reason lcache.RemoveReason) {
+			reasons = append(reasons, reason)
+		}),
+	)
+
+	c.Set("key1", "value1", 0)
+	c.Set("short", "value2", 20*time.Millisecond)
+	assert.Eq(t, lcache.StateValid, c.State("key1"))
+	assert.Eq(t, lcache.StateMissing, c.State("not-exists"))
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Eq(t, lcache.StateExpired, c.State("short"))
+	c.Get("short")
+	assert.Eq(t, lcache.StateMissing, c.State("short"))
+
+	c.Set("key2", "value2", 0)
+	c.Set("key3", "value3", 0)
+	c.Delete("key2")
+	assert.Eq(t, []lcache.RemoveReason{lcache.ReasonExpired, lcache.ReasonEvicted, lcache.ReasonDeleted}, reasons)
+}
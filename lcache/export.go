@@ -0,0 +1,91 @@
+package lcache
+
+import (
+	"bytes"
+	"time"
+)
+
+// Entry a cache item with its metadata. see Cache.Export
+type Entry struct {
+	Value any
+	// ExpiresAt absolute expiration time, zero time for never expire
+	ExpiresAt time.Time
+	// Hits number of hits since the item was written
+	Hits uint64
+}
+
+// Export get all valid items with their metadata, for backup, migration to
+// another cache instance or test assertions. For a namespace view, only exports
+// the items in it, the keys are without prefix.
+//
+// The []byte values are copied, other values are shallow copied.
+func (c *Cache) Export() map[string]Entry {
+	entries := c.rangeItems()
+	data := make(map[string]Entry, len(entries))
+	for _, e := range entries {
+		ent := Entry{Value: e.val, Hits: e.hits}
+		if e.exp > 0 {
+			ent.ExpiresAt = time.UnixMilli(e.exp)
+		}
+		if bs, ok := e.val.([]byte); ok {
+			ent.Value = bytes.Clone(bs)
+		}
+		data[e.key] = ent
+	}
+	return data
+}
+
+// Import write the entries into the cache with their original expiration, returns
+// the number of imported items. It is the counterpart of Export, eg: transfer the
+// items between cache instances. Expired entries are skipped, existing valid items
+// are kept if overwrite is false. For a namespace view, the keys are imported into it.
+//
+// The entries are written to local cache only, not to the Store.
+func (c *Cache) Import(entries map[string]Entry, overwrite bool) int {
+	if !c.lock() {
+		return 0
+	}
+	defer c.unlock()
+	if c.frozen.Load() {
+		return 0
+	}
+
+	var n int
+	nowUm := time.Now().UnixMilli()
+	for key, ent := range entries {
+		if ok, _ := c.importEntry(key, ent, overwrite, nowUm); ok {
+			n++
+		}
+	}
+	return n
+}
+
+// importEntry 写入一条数据，返回是否写入. 已过期、或 overwrite 为 false 时已存在有效数据则跳过 (不加锁)
+func (c *Cache) importEntry(key string, ent Entry, overwrite bool, nowUm int64) (bool, error) {
+	key, err := c.normKey(key)
+	if err != nil {
+		return false, err
+	}
+
+	var exp int64
+	if !ent.ExpiresAt.IsZero() {
+		if exp = ent.ExpiresAt.UnixMilli(); exp < nowUm {
+			return false, nil
+		}
+	}
+
+	key = c.nsKey(key)
+	if !overwrite {
+		if _, it := c.find(key); it != nil && !c.invalid(it, nowUm) {
+			return false, nil
+		}
+	}
+
+	it := c.set(key, ent.Value, exp)
+	if it == nil {
+		return false, ErrCacheFull
+	}
+	it.hits = ent.Hits
+	_ = c.appendSet(key, ent.Value, exp)
+	return true, nil
+}
@@ -0,0 +1,64 @@
+package lcache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_Export(t *testing.T) {
+	c := lcache.New()
+	bs := []byte("abc")
+	c.Set("key1", bs, 0)
+	c.Set("key2", 2, time.Hour)
+	c.Set("expired", 3, time.Millisecond)
+	c.Namespace("ns").Set("key3", 4, 0)
+	time.Sleep(5 * time.Millisecond)
+	c.Get("key2")
+
+	data := c.Export()
+	assert.Len(t, data, 3)
+	assert.Eq(t, []byte("abc"), data["key1"].Value)
+	assert.True(t, data["key1"].ExpiresAt.IsZero())
+	assert.Eq(t, 2, data["key2"].Value)
+	assert.Eq(t, uint64(1), data["key2"].Hits)
+	assert.True(t, data["key2"].ExpiresAt.After(time.Now()))
+
+	// []byte value is copied
+	bs[0] = 'x'
+	assert.Eq(t, []byte("abc"), data["key1"].Value)
+
+	// namespace view
+	data = c.Namespace("ns").Export()
+	assert.Eq(t, map[string]lcache.Entry{"key3": {Value: 4}}, data)
+}
+
+func TestCache_Import(t *testing.T) {
+	src := lcache.New()
+	src.Set("key1", 1, 0)
+	src.Set("key2", 2, time.Hour)
+	src.Get("key2")
+	data := src.Export()
+	data["expired"] = lcache.Entry{Value: 3, ExpiresAt: time.Now().Add(-time.Second)}
+
+	c := lcache.New(lcache.WithCapacity(10))
+	c.Set("key1", "old", 0)
+	assert.Eq(t, 1, c.Import(data, false))
+	assert.Eq(t, "old", c.Val("key1"))
+	assert.False(t, c.Has("expired"))
+	ent := c.Export()["key2"]
+	assert.Eq(t, data["key2"].ExpiresAt, ent.ExpiresAt)
+	assert.Eq(t, uint64(1), ent.Hits)
+
+	// overwrite existing items
+	assert.Eq(t, 2, c.Import(data, true))
+	assert.Eq(t, 1, c.Val("key1"))
+
+	// namespace view
+	ns := c.Namespace("ns")
+	assert.Eq(t, 2, ns.Import(data, false))
+	assert.Eq(t, 2, ns.Len())
+	assert.True(t, c.Has("ns:key2"))
+}
@@ -0,0 +1,153 @@
+// Package filecache caches the file contents or the parsed results by path, the cached
+// result is invalidated automatically when the file modify time or size changes.
+//
+// Usage:
+//
+//	// cache raw contents, max 64MB
+//	fc := filecache.NewBytes(filecache.WithMaxBytes(64 << 20))
+//	data, err := fc.Get("static/app.js")
+//
+//	// cache parsed results
+//	tc := filecache.New(func(path string, data []byte) (*template.Template, error) {
+//		return template.New(path).Parse(string(data))
+//	})
+//	tpl, err := tc.Get("views/home.tpl")
+package filecache
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+)
+
+// ParseFunc parse the file contents to the cached result
+type ParseFunc[T any] func(path string, data []byte) (T, error)
+
+// Option for the Cache
+type Option func(o *options)
+
+// options 配置
+type options struct {
+	maxBytes int64
+	capacity int
+}
+
+// WithMaxBytes set the max total bytes of the cached file contents, the least recently
+// used files are evicted when exceeded. The files larger than it are not cached. 0 for no limit.
+func WithMaxBytes(n int64) Option {
+	return func(o *options) { o.maxBytes = n }
+}
+
+// WithCapacity set the max number of cached files. default is 1000
+func WithCapacity(n int) Option {
+	return func(o *options) { o.capacity = n }
+}
+
+// Cache caches the parsed results of files
+type Cache[T any] struct {
+	c     *lcache.Cache
+	opt   options
+	parse ParseFunc[T]
+	// mu 保证写入时大小统计的一致
+	mu   sync.Mutex
+	size atomic.Int64
+}
+
+// entry 缓存的文件结果
+type entry[T any] struct {
+	val     T
+	modTime time.Time
+	size    int64
+}
+
+// New create a file cache with the parse func
+func New[T any](parse ParseFunc[T], opts ...Option) *Cache[T] {
+	fc := &Cache[T]{parse: parse, opt: options{capacity: 1000}}
+	for _, opt := range opts {
+		opt(&fc.opt)
+	}
+
+	fc.c = lcache.New(
+		lcache.WithCapacity(fc.opt.capacity),
+		lcache.WithOnRemovedFn(func(_ string, val any, _ lcache.RemoveReason) {
+			if e, ok := val.(*entry[T]); ok {
+				fc.size.Add(-e.size)
+			}
+		}),
+	)
+	return fc
+}
+
+// NewBytes create a file cache for the raw file contents
+func NewBytes(opts ...Option) *Cache[[]byte] {
+	return New(func(_ string, data []byte) ([]byte, error) { return data, nil }, opts...)
+}
+
+// Get the cached result of the file. If the file is not cached or changed, read and parse it.
+//
+// NOTE: the results are shared, should not modify them.
+func (fc *Cache[T]) Get(path string) (T, error) {
+	var zero T
+	st, err := os.Stat(path)
+	if err != nil {
+		fc.c.Delete(path)
+		return zero, err
+	}
+
+	if val, ok := fc.c.Get(path); ok {
+		if e, ok := val.(*entry[T]); ok && e.modTime.Equal(st.ModTime()) && e.size == st.Size() {
+			return e.val, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return zero, err
+	}
+
+	val, err := fc.parse(path, data)
+	if err != nil {
+		return zero, err
+	}
+
+	// 超过总大小限制的文件不缓存
+	size := int64(len(data))
+	if fc.opt.maxBytes > 0 && size > fc.opt.maxBytes {
+		fc.c.Delete(path)
+		return val, nil
+	}
+
+	fc.store(path, &entry[T]{val: val, modTime: st.ModTime(), size: size})
+	return val, nil
+}
+
+// store 写入缓存，并淘汰最久未使用的文件直到总大小不超过限制
+func (fc *Cache[T]) store(path string, e *entry[T]) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	// 先删除旧的数据，触发回调更新总大小
+	fc.c.Delete(path)
+	fc.c.Set(path, e, 0)
+	fc.size.Add(e.size)
+
+	for fc.opt.maxBytes > 0 && fc.size.Load() > fc.opt.maxBytes {
+		key, ok := fc.c.OldestKey()
+		if !ok || key == path {
+			break
+		}
+		fc.c.Delete(key)
+	}
+}
+
+// Invalidate remove the cached result of the file
+func (fc *Cache[T]) Invalidate(path string) { fc.c.Delete(path) }
+
+// Len get the number of cached files
+func (fc *Cache[T]) Len() int { return fc.c.Len() }
+
+// Size get the total bytes of the cached file contents
+func (fc *Cache[T]) Size() int64 { return fc.size.Load() }
@@ -0,0 +1,93 @@
+package filecache_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache/filecache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func writeFile(t *testing.T, path, content string, mtime time.Time) {
+	assert.NoErr(t, os.WriteFile(path, []byte(content), 0644))
+	assert.NoErr(t, os.Chtimes(path, mtime, mtime))
+}
+
+func TestCache_Get(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.txt")
+	now := time.Now()
+	writeFile(t, file, "hello", now)
+
+	var parsed int
+	fc := filecache.New(func(path string, data []byte) (string, error) {
+		parsed++
+		if len(data) == 0 {
+			return "", errors.New("empty file")
+		}
+		return strings.ToUpper(string(data)), nil
+	})
+
+	val, err := fc.Get(file)
+	assert.NoErr(t, err)
+	assert.Eq(t, "HELLO", val)
+	_, _ = fc.Get(file)
+	assert.Eq(t, 1, parsed)
+	assert.Eq(t, int64(5), fc.Size())
+
+	// mtime changed
+	writeFile(t, file, "world", now.Add(time.Second))
+	val, err = fc.Get(file)
+	assert.NoErr(t, err)
+	assert.Eq(t, "WORLD", val)
+	assert.Eq(t, 2, parsed)
+
+	// size changed, same mtime
+	writeFile(t, file, "hi", now.Add(time.Second))
+	val, _ = fc.Get(file)
+	assert.Eq(t, "HI", val)
+	assert.Eq(t, int64(2), fc.Size())
+
+	// parse error
+	writeFile(t, file, "", now)
+	_, err = fc.Get(file)
+	assert.ErrMsg(t, err, "empty file")
+
+	// removed file
+	assert.NoErr(t, os.Remove(file))
+	_, err = fc.Get(file)
+	assert.Err(t, err)
+	assert.Eq(t, 0, fc.Len())
+	assert.Eq(t, int64(0), fc.Size())
+}
+
+func TestWithMaxBytes(t *testing.T) {
+	dir := t.TempDir()
+	fc := filecache.NewBytes(filecache.WithMaxBytes(10))
+	for _, name := range []string{"a", "b", "c"} {
+		writeFile(t, filepath.Join(dir, name), "1234", time.Now())
+	}
+	writeFile(t, filepath.Join(dir, "big"), "12345678901", time.Now())
+
+	for _, name := range []string{"a", "b", "c"} {
+		data, err := fc.Get(filepath.Join(dir, name))
+		assert.NoErr(t, err)
+		assert.Eq(t, []byte("1234"), data)
+	}
+	// "a" is evicted
+	assert.Eq(t, 2, fc.Len())
+	assert.Eq(t, int64(8), fc.Size())
+
+	// too large to cache
+	data, err := fc.Get(filepath.Join(dir, "big"))
+	assert.NoErr(t, err)
+	assert.Len(t, data, 11)
+	assert.Eq(t, 2, fc.Len())
+
+	fc.Invalidate(filepath.Join(dir, "b"))
+	assert.Eq(t, int64(4), fc.Size())
+}
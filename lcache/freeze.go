@@ -0,0 +1,33 @@
+package lcache
+
+import "time"
+
+// Freeze make the cache read-only, it cannot be unfrozen.
+//
+// Usually used for a cache warmed once on startup(eg: by LoadFile) and then served concurrently.
+// After frozen:
+//
+//   - SetE, DeleteE, LoadFile, LoadAOF, Update return ErrFrozen, other write methods are no-op.
+//   - Get and GetState read the items without any locking, expired items are treated as misses.
+//   - loaded values by GetOrLoad and the read-through loader are returned but not cached.
+func (c *Cache) Freeze() {
+	c.mu.Lock()
+	c.frozen.Store(true)
+	c.mu.Unlock()
+}
+
+// Frozen check the cache is frozen. see Freeze
+func (c *Cache) Frozen() bool { return c.frozen.Load() }
+
+// getFrozen 冻结后只读，无需加锁获取数据
+func (c *Cache) getFrozen(key string) (any, ItemState) {
+	_, it := c.find(c.nsKey(key))
+	nowUm := time.Now().UnixMilli()
+	if it == nil || c.invalid(it, nowUm) {
+		c.misses.Add(1)
+		return nil, StateMissing
+	}
+
+	c.hit(it, nowUm)
+	return it.Val, StateValid
+}
@@ -0,0 +1,96 @@
+package lcache
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gookit/ext/lcache/serializer"
+)
+
+// GobSerializer builtin serializer: gob, registered as "gob".
+//
+// The cache using GobSerializer will auto register the value types on Set,
+// but the loading process still needs to register them first. see GobRegister
+type GobSerializer = serializer.Gob
+
+// GobRegister register the value types to gob, for encode/decode the cached values by GobSerializer.
+// Each type is registered once, the types already registered to gob under another name are skipped.
+//
+// NOTE: gob only allows register one of T and *T, the values are decoded as the first registered one.
+//
+// Usage:
+//
+//	lcache.GobRegister(User{}, &Order{})
+func GobRegister(values ...any) { serializer.GobRegister(values...) }
+
+// isGob 检查缓存是否使用 GobSerializer
+func (c *Cache) isGob() bool {
+	s, err := c.serializer()
+	if err != nil {
+		return false
+	}
+
+	_, ok := s.(GobSerializer)
+	return ok
+}
+
+// gobStreamHead gob 快照的头部，之后是 Count 条 gobStreamItem 记录.
+// 逐条编码数据项，加载时不需要一次读取和解码整个快照
+type gobStreamHead struct {
+	Gen   uint64
+	Count int
+}
+
+// gobStreamItem gob 快照中的一条数据项
+type gobStreamItem struct {
+	Key  string
+	Item *Item
+}
+
+// encodeGobStream 按 LRU 顺序逐条编码快照的数据项
+func encodeGobStream(w io.Writer, data *snapshot) error {
+	enc := gob.NewEncoder(w)
+	keys := data.orderedKeys()
+	if err := enc.Encode(&gobStreamHead{Gen: data.Gen, Count: len(keys)}); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := enc.Encode(&gobStreamItem{Key: key, Item: data.Items[key]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeGobStream 逐条解码 gob 快照，数据项的顺序即为 LRU 顺序
+func decodeGobStream(r io.Reader, onGen func(gen uint64), onItem func(key string, it *Item) error) error {
+	dec := gob.NewDecoder(r)
+	var head gobStreamHead
+	if err := dec.Decode(&head); err != nil {
+		return err
+	}
+	if head.Count < 0 {
+		return fmt.Errorf("%w: invalid item count %d", ErrBadSnapshot, head.Count)
+	}
+
+	onGen(head.Gen)
+	for range head.Count {
+		var rec gobStreamItem
+		if err := dec.Decode(&rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = fmt.Errorf("%w: truncated items", ErrBadSnapshot)
+			}
+			return err
+		}
+		if rec.Item == nil {
+			rec.Item = new(Item)
+		}
+		if err := onItem(rec.Key, rec.Item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package lcache_test
+
+import (
+	"testing"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+type gobOrder struct {
+	ID    int
+	Items []string
+}
+
+func TestGobSerializer(t *testing.T) {
+	c := lcache.New(lcache.WithSerializer("gob"))
+	// auto register on Set
+	c.Set("order", &gobOrder{ID: 1, Items: []string{"a", "b"}}, 0)
+	c.Set("int", 23, 0)
+	c.Set("map", map[string]any{"a": "b"}, 0)
+
+	filename := t.TempDir() + "/cache.gob"
+	assert.NoErr(t, c.SaveFile(filename))
+
+	c2 := lcache.New(lcache.WithSerializer("gob"))
+	assert.NoErr(t, c2.LoadFile(filename))
+	assert.Eq(t, 3, c2.Len())
+	assert.Eq(t, 23, c2.Val("int"))
+	assert.Eq(t, map[string]any{"a": "b"}, c2.Val("map"))
+
+	o, ok := lcache.TypedInCache[*gobOrder](c2, "order")
+	assert.True(t, ok)
+	assert.Eq(t, []string{"a", "b"}, o.Items)
+
+	// register repeatedly
+	lcache.GobRegister(gobOrder{}, &gobOrder{}, nil)
+}
@@ -0,0 +1,135 @@
+package lcache
+
+import "time"
+
+// GroupOptions for a cache group. see Cache.DefineGroup
+type GroupOptions struct {
+	// TTL default TTL for Group.Set. 0 means never expire
+	TTL time.Duration
+	// Policy eviction policy of the group items. allow: PolicyLRU, PolicyFIFO
+	Policy Policy
+	// OnEvicted callback on group item evicted, override the cache OnEvicted.
+	OnEvicted func(key string, value any)
+}
+
+// Group is a logical group of items in the cache.
+//
+// All groups share the top-level capacity budget of the cache, but each can
+// override the eviction policy, default TTL and callbacks. Keys of group items
+// are stored with prefix "name:".
+//
+// When the cache is full, a write to the group evicts the items of the group
+// first, by the Policy of the group. The group membership of the items is saved
+// by SaveFile and the AOF, except the lcbin serializer.
+type Group struct {
+	c    *Cache
+	name string
+	opt  GroupOptions
+}
+
+// DefineGroup define a logical group in the cache, or update options of the exists group.
+// The groups of the loaded items(see LoadFile, LoadAOF) are defined with the default options,
+// call DefineGroup to update them.
+//
+// Returns nil if the lock cannot be acquired in time. see WithLockTimeout
+//
+// Usage:
+//
+//	thumbs := c.DefineGroup("thumbnails", lcache.GroupOptions{TTL: time.Hour, Policy: lcache.PolicyFIFO})
+//	thumbs.Set("img1", data)
+func (c *Cache) DefineGroup(name string, opt GroupOptions) *Group {
+	if !c.lock() {
+		return nil
+	}
+	defer c.mu.Unlock()
+
+	g := c.loadGroup(name)
+	g.opt = opt
+	return g
+}
+
+// loadGroup 获取分组，不存在时使用默认选项定义 (不加锁)
+func (c *Cache) loadGroup(name string) *Group {
+	if g, ok := c.groups[name]; ok {
+		return g
+	}
+
+	if c.groups == nil {
+		c.groups = make(map[string]*Group)
+	}
+
+	g := &Group{c: c, name: name}
+	c.groups[name] = g
+	return g
+}
+
+// Group get the defined group by name, returns nil if not defined.
+func (c *Cache) Group(name string) *Group {
+	if !c.rlock() {
+		return nil
+	}
+	defer c.mu.RUnlock()
+	return c.groups[name]
+}
+
+// Name of the group
+func (g *Group) Name() string { return g.name }
+
+// key 获取分组数据在缓存中的 key
+func (g *Group) key(key string) string { return g.name + ":" + key }
+
+// prefix 获取分组数据实际存储的 key 前缀
+func (g *Group) prefix() string { return g.c.nsKey(g.key("")) }
+
+// groupName 获取数据项所属分组的名称，不属于分组时返回空字符串
+func (it *Item) groupName() string {
+	if it.grp == nil {
+		return ""
+	}
+	return it.grp.name
+}
+
+// Set value to the group with the default TTL of group.
+func (g *Group) Set(key string, value any) {
+	g.SetTTL(key, value, g.opt.TTL)
+}
+
+// SetTTL set value to the group with specified TTL.
+//
+// With Store configured, writes through to the store first. see WithStore
+func (g *Group) SetTTL(key string, value any, ttl time.Duration) {
+	c := g.c
+	nk, err := c.normKey(g.key(key))
+	if err != nil || c.frozen.Load() || c.checkSize(nk, value) != nil {
+		return
+	}
+	if c.storeSave(nk, value, ttl) != nil || !c.lock() {
+		return
+	}
+	if c.frozen.Load() {
+		c.mu.Unlock()
+		return
+	}
+
+	exp := ttlToExp(ttl)
+	key = c.nsKey(nk)
+	hk, it := c.newItem(key, value, exp)
+	it.grp = g
+	if !c.putItem(hk, it) {
+		c.mu.Unlock()
+		return
+	}
+	_ = c.appendRecord(&aofRecord{Op: aofOpSet, Key: key, Val: value, Exp: exp, Grp: g.name})
+	c.mu.Unlock()
+
+	c.onSet(key, value, ttl)
+}
+
+// Get value from the group
+func (g *Group) Get(key string) (any, bool) { return g.c.Get(g.key(key)) }
+
+// Has checks if an item exists in the group.
+func (g *Group) Has(key string) bool { return g.c.Has(g.key(key)) }
+
+// Delete value from the group
+func (g *Group) Delete(key string) bool { return g.c.Delete(g.key(key)) }
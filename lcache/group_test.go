@@ -0,0 +1,96 @@
+package lcache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_DefineGroup(t *testing.T) {
+	var evicted, grpEvicted []string
+	c := lcache.New(
+		lcache.WithCapacity(3),
+		lcache.WithOnEvictFn(func(key string, _ any) {
+			evicted = append(evicted, key)
+		}),
+	)
+
+	thumbs := c.DefineGroup("thumbs", lcache.GroupOptions{
+		TTL:    time.Hour,
+		Policy: lcache.PolicyFIFO,
+		OnEvicted: func(key string, _ any) {
+			grpEvicted = append(grpEvicted, key)
+		},
+	})
+	assert.Eq(t, "thumbs", thumbs.Name())
+	assert.Eq(t, thumbs, c.Group("thumbs"))
+	assert.Nil(t, c.Group("not-exists"))
+
+	thumbs.Set("img1", "data1")
+	c.Set("key1", "value1", 0)
+	thumbs.Set("img2", "data2")
+	assert.True(t, thumbs.Has("img1"))
+	assert.Eq(t, "data1", c.Val("thumbs:img1"))
+
+	// FIFO: reading img1 does not change the order, it is evicted first
+	val, ok := thumbs.Get("img1")
+	assert.True(t, ok)
+	assert.Eq(t, "data1", val)
+
+	c.Set("key2", "value2", 0)
+	assert.False(t, thumbs.Has("img1"))
+	assert.Eq(t, []string{"thumbs:img1"}, grpEvicted)
+	assert.Empty(t, evicted)
+
+	assert.True(t, thumbs.Delete("img2"))
+	assert.Eq(t, 2, c.Len())
+}
+
+func TestGroup_evictAndPersist(t *testing.T) {
+	s := newMemStore()
+	c := lcache.New(lcache.WithCapacity(3), lcache.WithStore(s))
+	imgs := c.DefineGroup("imgs", lcache.GroupOptions{Policy: lcache.PolicyFIFO})
+
+	// write through to the store
+	imgs.Set("img1", "data1")
+	assert.Eq(t, "data1", s.data["imgs:img1"])
+
+	// the group write evicts the group items first, by the FIFO policy
+	c.Set("key1", "value1", 0)
+	imgs.Set("img2", "data2")
+	c.Get("key1")
+	imgs.Get("img1")
+	imgs.Set("img3", "data3")
+	assert.False(t, imgs.Has("img1"))
+	assert.True(t, c.Has("key1"))
+	assert.True(t, imgs.Has("img2"))
+
+	// the membership is saved and loaded
+	filename := t.TempDir() + "/cache.json"
+	assert.NoErr(t, c.SaveFile(filename))
+
+	var evicted []string
+	c2 := lcache.New(lcache.WithCapacity(3))
+	assert.NoErr(t, c2.LoadFile(filename))
+	imgs2 := c2.Group("imgs")
+	assert.NotNil(t, imgs2)
+	c2.DefineGroup("imgs", lcache.GroupOptions{OnEvicted: func(key string, _ any) {
+		evicted = append(evicted, key)
+	}})
+	imgs2.Set("img4", "data4")
+	assert.Eq(t, []string{"imgs:img2"}, evicted)
+	assert.True(t, c2.Has("key1"))
+
+	// the membership is replayed from the AOF
+	aofFile := t.TempDir() + "/cache.aof"
+	c3 := lcache.New(lcache.WithAOF(aofFile))
+	c3.DefineGroup("imgs", lcache.GroupOptions{}).Set("img1", "data1")
+	assert.NoErr(t, c3.Close())
+
+	c4 := lcache.New()
+	assert.NoErr(t, c4.LoadAOF(aofFile))
+	assert.NotNil(t, c4.Group("imgs"))
+	assert.True(t, c4.Group("imgs").Has("img1"))
+}
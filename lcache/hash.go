@@ -0,0 +1,169 @@
+package lcache
+
+import (
+	"maps"
+	"time"
+)
+
+// HSet set the field of the hash stored at key, like Redis HSET. Creates the hash(never expire)
+// if the key does not exist or expired, use Touch to set its TTL.
+//
+// The hash is stored as map[string]any under one key and shares the TTL of the key. It is
+// copied on write, so the maps returned by Get or HGetAll are not changed by the later writes.
+// The hash operations are local only, not written to the Store.
+//
+// Returns ErrNotHash if the value of key is not a hash, ErrBusy, ErrFrozen or ErrCacheFull.
+// The whole hash is checked by WithMaxValueBytes after the fields are set, returns a *SizeError if exceeded.
+func (c *Cache) HSet(key, field string, value any) error {
+	return c.HMSet(key, map[string]any{field: value})
+}
+
+// HMSet set multiple fields of the hash stored at key atomically. see HSet
+func (c *Cache) HMSet(key string, fields map[string]any) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	key, err := c.normKey(key)
+	if err != nil {
+		return err
+	}
+	if !c.lock() {
+		return ErrBusy
+	}
+	defer c.unlock()
+	if c.frozen.Load() {
+		return ErrFrozen
+	}
+
+	nk := c.nsKey(key)
+	hk, it := c.find(nk)
+	if it == nil || c.invalid(it, time.Now().UnixMilli()) {
+		hash := maps.Clone(fields)
+		if err = c.checkSize(key, hash); err != nil {
+			return err
+		}
+		if c.set(nk, hash, 0) == nil {
+			return ErrCacheFull
+		}
+		return c.appendSet(nk, hash, 0)
+	}
+
+	old, ok := it.Val.(map[string]any)
+	if !ok {
+		return ErrNotHash
+	}
+
+	hash := make(map[string]any, len(old)+len(fields))
+	maps.Copy(hash, old)
+	maps.Copy(hash, fields)
+	if err = c.checkSize(key, hash); err != nil {
+		return err
+	}
+	it.Val = hash
+	c.updateIndexes(hk, it)
+	c.touch(hk, it)
+	return c.appendSet(nk, hash, it.Exp)
+}
+
+// HGet get the field value of the hash stored at key.
+// returns false if the hash or the field is missing.
+func (c *Cache) HGet(key, field string) (any, bool) {
+	key, err := c.normKey(key)
+	if err != nil || !c.lockRead() {
+		return nil, false
+	}
+	defer c.unlockRead()
+
+	hk, it := c.find(c.nsKey(key))
+	hash, ok := c.validHash(it)
+	if !ok {
+		return nil, false
+	}
+
+	c.touch(hk, it)
+	val, ok := hash[field]
+	return val, ok
+}
+
+// HGetAll get a copy of all fields of the hash stored at key, nil if the hash is missing.
+func (c *Cache) HGetAll(key string) map[string]any {
+	key, err := c.normKey(key)
+	if err != nil || !c.lockRead() {
+		return nil
+	}
+	defer c.unlockRead()
+
+	hk, it := c.find(c.nsKey(key))
+	hash, ok := c.validHash(it)
+	if !ok {
+		return nil
+	}
+
+	c.touch(hk, it)
+	return maps.Clone(hash)
+}
+
+// HDel delete the fields of the hash stored at key, returns the number of deleted fields.
+// The key is deleted when the hash becomes empty.
+func (c *Cache) HDel(key string, fields ...string) int {
+	key, err := c.normKey(key)
+	if err != nil || !c.lock() {
+		return 0
+	}
+	defer c.unlock()
+	if c.frozen.Load() {
+		return 0
+	}
+
+	nk := c.nsKey(key)
+	hk, it := c.find(nk)
+	old, ok := c.validHash(it)
+	if !ok {
+		return 0
+	}
+
+	hash := maps.Clone(old)
+	for _, field := range fields {
+		delete(hash, field)
+	}
+
+	n := len(old) - len(hash)
+	if n == 0 {
+		return 0
+	}
+	if len(hash) == 0 {
+		c.removeElement(hk, ReasonDeleted)
+		_ = c.appendAOF(aofOpDel, nk, nil, 0)
+		return n
+	}
+
+	it.Val = hash
+	c.updateIndexes(hk, it)
+	c.touch(hk, it)
+	_ = c.appendSet(nk, hash, it.Exp)
+	return n
+}
+
+// HLen get the number of fields of the hash stored at key, 0 if the hash is missing or not a hash.
+func (c *Cache) HLen(key string) int {
+	key, err := c.normKey(key)
+	if err != nil || !c.rlock() {
+		return 0
+	}
+	defer c.mu.RUnlock()
+
+	_, it := c.find(c.nsKey(key))
+	hash, _ := c.validHash(it)
+	return len(hash)
+}
+
+// validHash 获取有效数据项中的 hash (不加锁)
+func (c *Cache) validHash(it *Item) (map[string]any, bool) {
+	if it == nil || c.invalid(it, time.Now().UnixMilli()) {
+		return nil, false
+	}
+
+	hash, ok := it.Val.(map[string]any)
+	return hash, ok
+}
@@ -0,0 +1,51 @@
+package lcache_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_HashOps(t *testing.T) {
+	c := lcache.New()
+
+	assert.NoErr(t, c.HSet("user:1", "name", "inhere"))
+	assert.NoErr(t, c.HMSet("user:1", map[string]any{"age": 20, "city": "sz"}))
+	assert.Eq(t, 3, c.HLen("user:1"))
+
+	val, ok := c.HGet("user:1", "name")
+	assert.True(t, ok)
+	assert.Eq(t, "inhere", val)
+	_, ok = c.HGet("user:1", "not-exists")
+	assert.False(t, ok)
+	_, ok = c.HGet("not-exists", "name")
+	assert.False(t, ok)
+
+	// copy on write
+	all := c.HGetAll("user:1")
+	old := c.Val("user:1").(map[string]any)
+	assert.NoErr(t, c.HSet("user:1", "age", 21))
+	assert.Eq(t, 20, all["age"])
+	assert.Eq(t, 20, old["age"])
+	all["age"] = 30
+	val, _ = c.HGet("user:1", "age")
+	assert.Eq(t, 21, val)
+
+	assert.Eq(t, 2, c.HDel("user:1", "age", "city", "not-exists"))
+	assert.Eq(t, 0, c.HDel("user:1", "age"))
+	assert.Eq(t, map[string]any{"name": "inhere"}, c.HGetAll("user:1"))
+
+	// the key is deleted when empty
+	assert.Eq(t, 1, c.HDel("user:1", "name"))
+	assert.False(t, c.Has("user:1"))
+	assert.Nil(t, c.HGetAll("user:1"))
+
+	// not a hash
+	c.Set("str", "abc", 0)
+	err := c.HSet("str", "name", "inhere")
+	assert.True(t, errors.Is(err, lcache.ErrNotHash))
+	assert.Eq(t, 0, c.HLen("str"))
+	assert.Eq(t, 0, c.HDel("str", "name"))
+}
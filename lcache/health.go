@@ -0,0 +1,143 @@
+package lcache
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HealthReport the result of Cache.HealthCheck
+type HealthReport struct {
+	// Problems the problems found by the check, empty if healthy
+	Problems []string
+	// Len number of the items, LRULen number of the elements in the LRU list
+	Len, LRULen int
+	// JanitorRunAt the last run time of the janitor, zero if the janitor is disabled
+	JanitorRunAt time.Time
+	// LastSaveAt last time of SaveFile called(manual or auto-save)
+	LastSaveAt time.Time
+	// LastSaveErr error of the last SaveFile call
+	LastSaveErr error
+	// AOFErr the last error of the AOF
+	AOFErr error
+}
+
+// Healthy check the report has no problems
+func (r HealthReport) Healthy() bool {
+	return len(r.Problems) == 0
+}
+
+// Err get the error of the problems, nil if healthy. eg: for readiness probes
+func (r HealthReport) Err() error {
+	if r.Healthy() {
+		return nil
+	}
+	return errors.New("lcache: unhealthy: " + strings.Join(r.Problems, "; "))
+}
+
+// HealthCheck validate the internal invariants of the cache(the items, LRU index and list are
+// consistent), the liveness of the janitor and auto-save, and the last persistence errors.
+//
+// The janitor or auto-save is considered stalled if it has not run for 2 intervals.
+//
+// NOTE: it traverses the LRU list under the read lock, the time complexity is O(N)
+//
+// Usage:
+//
+//	http.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+//		if err := c.HealthCheck().Err(); err != nil {
+//			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+//		}
+//	})
+func (c *Cache) HealthCheck() HealthReport {
+	var r HealthReport
+	if !c.rlock() {
+		r.Problems = append(r.Problems, ErrBusy.Error())
+		return r
+	}
+	r.Problems = c.checkInvariants()
+	r.Len, r.LRULen = len(c.items), c.lruList.Len()
+	r.AOFErr = c.aofErr
+	c.mu.RUnlock()
+
+	now := time.Now()
+	if interval := c.opt.JanitorInterval; interval > 0 {
+		r.JanitorRunAt = time.UnixMilli(c.janRunAt.Load())
+		if lag := now.Sub(r.JanitorRunAt); lag > 2*interval {
+			r.Problems = append(r.Problems, fmt.Sprintf("janitor has not run for %s", lag.Round(time.Millisecond)))
+		}
+	}
+
+	c.saveMu.Lock()
+	r.LastSaveAt, r.LastSaveErr = c.lastSaveAt, c.lastSaveErr
+	lastRun := c.autoSaveStartAt
+	c.saveMu.Unlock()
+
+	if interval := c.opt.AutoSaveInterval; interval > 0 && c.opt.AutoSaveFile != "" {
+		if r.LastSaveAt.After(lastRun) {
+			lastRun = r.LastSaveAt
+		}
+		if lag := now.Sub(lastRun); lag > 2*interval {
+			r.Problems = append(r.Problems, fmt.Sprintf("auto-save has not run for %s", lag.Round(time.Millisecond)))
+		}
+	}
+
+	if r.LastSaveErr != nil {
+		r.Problems = append(r.Problems, "last save failed: "+r.LastSaveErr.Error())
+	}
+	if r.AOFErr != nil {
+		r.Problems = append(r.Problems, "AOF error: "+r.AOFErr.Error())
+	}
+	return r
+}
+
+// checkInvariants 检查数据项、LRU 索引和链表是否一致 (不加锁)
+func (c *Cache) checkInvariants() (problems []string) {
+	var prioSum int
+	for _, n := range c.prioLen {
+		prioSum += n
+	}
+	if prioSum != len(c.items) {
+		problems = append(problems, fmt.Sprintf("priority counters sum is %d, but has %d items", prioSum, len(c.items)))
+	}
+
+	if c.liveOn {
+		var live int
+		for _, it := range c.items {
+			if it.live {
+				live++
+			}
+		}
+		if live != c.liveN {
+			problems = append(problems, fmt.Sprintf("live counter is %d, but has %d live items", c.liveN, live))
+		}
+	}
+
+	// 关闭 LRU 时不维护链表
+	if c.opt.DisableLRU {
+		return problems
+	}
+
+	if len(c.lruMap) != len(c.items) {
+		problems = append(problems, fmt.Sprintf("LRU index has %d keys, but has %d items", len(c.lruMap), len(c.items)))
+	}
+	if n := c.lruList.Len(); n != len(c.lruMap) {
+		problems = append(problems, fmt.Sprintf("LRU list has %d elements, but index has %d keys", n, len(c.lruMap)))
+	}
+
+	// 链表中的节点都应在索引中，且对应的数据项存在
+	var orphaned int
+	for elem := c.lruList.Front(); elem != nil; elem = elem.Next() {
+		key, ok := elem.Value.(string)
+		if !ok || c.lruMap[key] != elem {
+			orphaned++
+		} else if _, ok = c.items[key]; !ok {
+			orphaned++
+		}
+	}
+	if orphaned > 0 {
+		problems = append(problems, fmt.Sprintf("LRU list has %d orphaned elements", orphaned))
+	}
+	return problems
+}
@@ -0,0 +1,80 @@
+package lcache
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+// idleScheduler never runs the jobs, simulate the stalled background jobs
+type idleScheduler struct{}
+
+func (idleScheduler) Every(time.Duration, func()) func() { return func() {} }
+
+func (idleScheduler) After(time.Duration, func()) func() { return func() {} }
+
+func TestCache_HealthCheck(t *testing.T) {
+	c := New(WithCapacity(50), WithJanitor(time.Hour))
+	defer c.Close()
+	for i := 0; i < 100; i++ {
+		c.Set("key"+strconv.Itoa(i), i, 0)
+	}
+	c.Delete("key99")
+	c.Namespace("ns").Set("a", 1, time.Minute)
+
+	r := c.HealthCheck()
+	assert.True(t, r.Healthy())
+	assert.NoErr(t, r.Err())
+	assert.Eq(t, 50, r.Len)
+	assert.Eq(t, 50, r.LRULen)
+	assert.False(t, r.JanitorRunAt.IsZero())
+
+	// broken invariants
+	c.mu.Lock()
+	c.lruList.PushBack("orphan")
+	delete(c.lruMap, "key98")
+	c.mu.Unlock()
+
+	r = c.HealthCheck()
+	assert.False(t, r.Healthy())
+	assert.Eq(t, []string{
+		"LRU index has 49 keys, but has 50 items",
+		"LRU list has 51 elements, but index has 49 keys",
+		"LRU list has 2 orphaned elements",
+	}, r.Problems)
+	assert.ErrMsgContains(t, r.Err(), "lcache: unhealthy: LRU index has 49 keys")
+
+	// busy
+	c2 := New(WithLockTimeout(10 * time.Millisecond))
+	c2.mu.Lock()
+	assert.Eq(t, []string{ErrBusy.Error()}, c2.HealthCheck().Problems)
+	c2.mu.Unlock()
+	assert.True(t, New(WithLRU(false)).HealthCheck().Healthy())
+	assert.True(t, c2.HealthCheck().Healthy())
+}
+
+func TestCache_HealthCheck_jobs(t *testing.T) {
+	// the parent of the snapshot file is a file
+	filename := t.TempDir() + "/file/cache.json"
+	assert.NoErr(t, os.WriteFile(filepath.Dir(filename), nil, 0644))
+	c := New(WithScheduler(idleScheduler{}), WithJanitor(10*time.Millisecond),
+		WithAutoSave(filename, 10*time.Millisecond))
+	assert.True(t, c.HealthCheck().Healthy())
+
+	time.Sleep(30 * time.Millisecond)
+	r := c.HealthCheck()
+	assert.Len(t, r.Problems, 2)
+	assert.StrContains(t, r.Problems[0], "janitor has not run for")
+	assert.StrContains(t, r.Problems[1], "auto-save has not run for")
+
+	// the save error
+	c.Set("key", 1, 0)
+	assert.Err(t, c.SaveFile(filename))
+	r = c.HealthCheck()
+	assert.Err(t, r.LastSaveErr)
+	assert.StrContains(t, r.Problems[len(r.Problems)-1], "last save failed: ")
+}
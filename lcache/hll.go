@@ -0,0 +1,148 @@
+package lcache
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// hllPrecision HyperLogLog 的精度，使用 2^hllPrecision 个寄存器(4KB)，标准误差约 1.6%
+const (
+	hllPrecision = 12
+	hllRegisters = 1 << hllPrecision
+)
+
+// hyperLogLog 基数估算的 HyperLogLog 草图. 每个寄存器记录 hash 值的最大前导零数量+1
+type hyperLogLog struct {
+	Regs []byte `json:"r"`
+}
+
+func init() {
+	// 从快照加载时还原为 HyperLogLog 类型
+	RegisterType[*hyperLogLog]("lcache.hll")
+}
+
+// add 添加元素，返回是否有寄存器被更新
+func (h *hyperLogLog) add(elem string) bool {
+	x := xxh64(elem)
+	idx := x >> (64 - hllPrecision)
+	// 低位补 1，避免前导零数量超出范围
+	rho := byte(bits.LeadingZeros64(x<<hllPrecision|1<<(hllPrecision-1)) + 1)
+	if rho > h.Regs[idx] {
+		h.Regs[idx] = rho
+		return true
+	}
+	return false
+}
+
+// count 估算基数. 估算值较小时使用线性计数修正
+func (h *hyperLogLog) count() uint64 {
+	var sum float64
+	var zeros int
+	for _, r := range h.Regs {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	m := float64(hllRegisters)
+	est := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if est <= 2.5*m && zeros > 0 {
+		est = m * math.Log(m/float64(zeros))
+	}
+	return uint64(est + 0.5)
+}
+
+// PFAdd add the elements to the HyperLogLog stored at key, like Redis PFADD. Returns true if
+// the estimated cardinality may be changed. eg: track the approximate unique visitors.
+//
+// Each HyperLogLog uses 4KB memory, the standard error of PFCount is about 1.6%. It is created
+// with ttl(<= 0 for never expire) if the key does not exist or expired, the TTL is not changed
+// by the later adds, so a new HyperLogLog is started after it expires. eg: daily unique visitors.
+//
+// The HyperLogLog operations are local only, not written to the Store. Returns ErrNotHLL if the
+// value of key is not a HyperLogLog, ErrBusy, ErrFrozen or ErrCacheFull.
+func (c *Cache) PFAdd(key string, ttl time.Duration, elements ...string) (bool, error) {
+	key, err := c.normKey(key)
+	if err != nil {
+		return false, err
+	}
+	if !c.lock() {
+		return false, ErrBusy
+	}
+	defer c.unlock()
+	if c.frozen.Load() {
+		return false, ErrFrozen
+	}
+
+	nk := c.nsKey(key)
+	hk, it := c.find(nk)
+	if it != nil && !c.invalid(it, time.Now().UnixMilli()) {
+		h, ok := it.Val.(*hyperLogLog)
+		if !ok {
+			return false, ErrNotHLL
+		}
+
+		var changed bool
+		for _, elem := range elements {
+			changed = h.add(elem) || changed
+		}
+		if changed {
+			c.touch(hk, it)
+			_ = c.appendSet(nk, h, it.Exp)
+		}
+		return changed, nil
+	}
+
+	h := &hyperLogLog{Regs: make([]byte, hllRegisters)}
+	for _, elem := range elements {
+		h.add(elem)
+	}
+
+	exp := ttlToExp(ttl)
+	if c.set(nk, h, exp) == nil {
+		return false, ErrCacheFull
+	}
+	return true, c.appendSet(nk, h, exp)
+}
+
+// PFCount get the approximate number of the distinct elements added to the HyperLogLogs
+// stored at keys, like Redis PFCOUNT. Multiple keys are counted as their union.
+// The missing keys and the values of other types are skipped.
+func (c *Cache) PFCount(keys ...string) uint64 {
+	if !c.rlock() {
+		return 0
+	}
+	defer c.mu.RUnlock()
+
+	var union *hyperLogLog
+	nowUm := time.Now().UnixMilli()
+	for _, key := range keys {
+		key, err := c.normKey(key)
+		if err != nil {
+			continue
+		}
+
+		_, it := c.find(c.nsKey(key))
+		if it == nil || c.invalid(it, nowUm) {
+			continue
+		}
+		h, ok := it.Val.(*hyperLogLog)
+		if !ok || len(h.Regs) != hllRegisters {
+			continue
+		}
+
+		if union == nil {
+			union = &hyperLogLog{Regs: make([]byte, hllRegisters)}
+		}
+		for i, r := range h.Regs {
+			union.Regs[i] = max(union.Regs[i], r)
+		}
+	}
+
+	if union == nil {
+		return 0
+	}
+	return union.count()
+}
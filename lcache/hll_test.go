@@ -0,0 +1,66 @@
+package lcache_test
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_PFAdd(t *testing.T) {
+	c := lcache.New()
+
+	changed, err := c.PFAdd("uv", 0, "u1", "u2", "u3")
+	assert.NoErr(t, err)
+	assert.True(t, changed)
+	changed, err = c.PFAdd("uv", 0, "u1")
+	assert.NoErr(t, err)
+	assert.False(t, changed)
+	assert.Eq(t, uint64(3), c.PFCount("uv"))
+	assert.Eq(t, uint64(0), c.PFCount("not-exists"))
+
+	// approximate count, standard error is about 1.6%
+	for i := 0; i < 10000; i++ {
+		_, _ = c.PFAdd("uv:big", 0, "user"+strconv.Itoa(i))
+		_, _ = c.PFAdd("uv:big2", 0, "user"+strconv.Itoa(i+5000))
+	}
+	cnt := c.PFCount("uv:big")
+	assert.Gt(t, cnt, uint64(9500))
+	assert.Lt(t, cnt, uint64(10500))
+
+	// union
+	cnt = c.PFCount("uv:big", "uv:big2", "not-exists")
+	assert.Gt(t, cnt, uint64(14250))
+	assert.Lt(t, cnt, uint64(15750))
+
+	// not a HyperLogLog
+	c.Set("str", "abc", 0)
+	_, err = c.PFAdd("str", 0, "u1")
+	assert.True(t, errors.Is(err, lcache.ErrNotHLL))
+	assert.Eq(t, uint64(3), c.PFCount("uv", "str"))
+}
+
+func TestCache_PFAdd_ttl(t *testing.T) {
+	c := lcache.New()
+	_, _ = c.PFAdd("uv:day", 30*time.Millisecond, "u1", "u2")
+	time.Sleep(20 * time.Millisecond)
+	_, _ = c.PFAdd("uv:day", 30*time.Millisecond, "u3")
+	assert.Eq(t, uint64(3), c.PFCount("uv:day"))
+
+	// snapshot round-trip
+	file := filepath.Join(t.TempDir(), "hll.json")
+	assert.NoErr(t, c.SaveFile(file))
+	nc := lcache.New()
+	assert.NoErr(t, nc.LoadFile(file))
+	assert.Eq(t, uint64(3), nc.PFCount("uv:day"))
+
+	// the TTL is not refreshed, rollover after expired
+	time.Sleep(20 * time.Millisecond)
+	assert.Eq(t, uint64(0), c.PFCount("uv:day"))
+	_, _ = c.PFAdd("uv:day", 0, "u4")
+	assert.Eq(t, uint64(1), c.PFCount("uv:day"))
+}
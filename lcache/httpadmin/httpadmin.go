@@ -0,0 +1,221 @@
+// Package httpadmin provides a HTTP handler for runtime management of a lcache.Cache.
+//
+// Endpoints:
+//
+//	GET    /keys/{key}     get the value and remaining TTL of the key
+//	PUT    /keys/{key}     set the key, body is the JSON value. query: ttl=10m
+//	DELETE /keys/{key}     delete the key
+//	GET    /keys           list keys. query: prefix=user:, limit=100
+//	GET    /stats          get the cache statistics
+//	GET    /health         check the cache health, responds 503 if unhealthy. see lcache.Cache.HealthCheck
+//	POST   /clear          clear all the cache items
+//	POST   /save           save the cache to the snapshot file. see WithSaveFile
+//
+// Usage:
+//
+//	mux := http.NewServeMux()
+//	mux.Handle("/admin/cache/", http.StripPrefix("/admin/cache", httpadmin.Handler(c, httpadmin.WithToken(token))))
+package httpadmin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+)
+
+// Option for the admin handler
+type Option func(h *handler)
+
+// WithToken require the auth token on every request, by header "Authorization: Bearer <token>".
+func WithToken(token string) Option {
+	return func(h *handler) { h.token = token }
+}
+
+// WithSaveFile set the snapshot file for the POST /save endpoint.
+// If not set, the endpoint responds 501 Not Implemented.
+func WithSaveFile(filename string) Option {
+	return func(h *handler) { h.saveFile = filename }
+}
+
+// handler 管理接口的实现
+type handler struct {
+	c        *lcache.Cache
+	mux      *http.ServeMux
+	token    string
+	saveFile string
+}
+
+// Handler create the admin HTTP handler for the cache. it can be mounted on any mux.
+func Handler(c *lcache.Cache, opts ...Option) http.Handler {
+	h := &handler{c: c, mux: http.NewServeMux()}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.mux.HandleFunc("GET /keys/{key...}", h.getKey)
+	h.mux.HandleFunc("PUT /keys/{key...}", h.setKey)
+	h.mux.HandleFunc("DELETE /keys/{key...}", h.deleteKey)
+	h.mux.HandleFunc("GET /keys", h.listKeys)
+	h.mux.HandleFunc("GET /stats", h.stats)
+	h.mux.HandleFunc("GET /health", h.health)
+	h.mux.HandleFunc("POST /clear", h.clear)
+	h.mux.HandleFunc("POST /save", h.save)
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "invalid auth token")
+			return
+		}
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *handler) getKey(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	val, ok := h.c.Get(key)
+	if !ok {
+		writeError(w, http.StatusNotFound, "key not found")
+		return
+	}
+
+	// TTL 单位为毫秒，0 表示永不过期
+	ttl, _ := h.c.TTL(key)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"key":    key,
+		"value":  val,
+		"ttl_ms": ttl.Milliseconds(),
+	})
+}
+
+func (h *handler) setKey(w http.ResponseWriter, r *http.Request) {
+	var ttl time.Duration
+	if s := r.URL.Query().Get("ttl"); s != "" {
+		var err error
+		if ttl, err = time.ParseDuration(s); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid ttl: "+err.Error())
+			return
+		}
+	}
+
+	var val any
+	if err := json.NewDecoder(r.Body).Decode(&val); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON value: "+err.Error())
+		return
+	}
+
+	if err := h.c.SetE(r.PathValue("key"), val, ttl); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) deleteKey(w http.ResponseWriter, r *http.Request) {
+	if !h.c.Delete(r.PathValue("key")) {
+		writeError(w, http.StatusNotFound, "key not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) listKeys(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := -1
+	if s := q.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = n
+	}
+
+	prefix := q.Get("prefix")
+	keys := make([]string, 0)
+	for _, key := range h.c.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	if limit >= 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	writeJSON(w, http.StatusOK, keys)
+}
+
+func (h *handler) stats(w http.ResponseWriter, _ *http.Request) {
+	st := h.c.Stats()
+	data := map[string]any{
+		"len":             st.Len,
+		"valid_len":       st.ValidLen,
+		"generation":      st.Generation,
+		"last_save_at":    nil,
+		"last_save_error": errString(st.LastSaveErr),
+		"aof_error":       errString(st.AOFErr),
+	}
+	if !st.LastSaveAt.IsZero() {
+		data["last_save_at"] = st.LastSaveAt
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+func (h *handler) health(w http.ResponseWriter, _ *http.Request) {
+	r := h.c.HealthCheck()
+	status, problems := http.StatusOK, []string{}
+	if !r.Healthy() {
+		status, problems = http.StatusServiceUnavailable, r.Problems
+	}
+	writeJSON(w, status, map[string]any{
+		"healthy":  r.Healthy(),
+		"problems": problems,
+	})
+}
+
+func (h *handler) clear(w http.ResponseWriter, _ *http.Request) {
+	h.c.Clear()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) save(w http.ResponseWriter, _ *http.Request) {
+	if h.saveFile == "" {
+		writeError(w, http.StatusNotImplemented, "save file is not configured")
+		return
+	}
+
+	if err := h.c.SaveFile(h.saveFile); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// errString 错误信息，nil 返回空字符串
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
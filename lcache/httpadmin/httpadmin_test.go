@@ -0,0 +1,83 @@
+package httpadmin_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/lcache/httpadmin"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func doReq(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer abc")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	c := lcache.New()
+	c.Set("user:1", "tom", 0)
+	c.Set("user:2", "jack", 0)
+	c.Set("post:1", "hi", 0)
+
+	saveFile := filepath.Join(t.TempDir(), "cache.json")
+	h := httpadmin.Handler(c, httpadmin.WithToken("abc"), httpadmin.WithSaveFile(saveFile))
+
+	// auth
+	req := httptest.NewRequest("GET", "/stats", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Eq(t, http.StatusUnauthorized, w.Code)
+
+	w = doReq(h, "GET", "/keys/user:1", "")
+	assert.Eq(t, http.StatusOK, w.Code)
+	assert.Eq(t, `{"key":"user:1","ttl_ms":0,"value":"tom"}`, strings.TrimSpace(w.Body.String()))
+	assert.Eq(t, http.StatusNotFound, doReq(h, "GET", "/keys/none", "").Code)
+
+	w = doReq(h, "PUT", "/keys/a/b?ttl=1m", `{"name":"inhere"}`)
+	assert.Eq(t, http.StatusNoContent, w.Code)
+	assert.Eq(t, map[string]any{"name": "inhere"}, c.Val("a/b"))
+	ttl, _ := c.TTL("a/b")
+	assert.True(t, ttl > 0)
+	assert.Eq(t, http.StatusBadRequest, doReq(h, "PUT", "/keys/k?ttl=abc", `1`).Code)
+	assert.Eq(t, http.StatusBadRequest, doReq(h, "PUT", "/keys/k", `{bad`).Code)
+
+	w = doReq(h, "GET", "/keys?prefix=user:", "")
+	assert.Eq(t, `["user:1","user:2"]`, strings.TrimSpace(w.Body.String()))
+	w = doReq(h, "GET", "/keys?limit=1", "")
+	assert.Eq(t, `["a/b"]`, strings.TrimSpace(w.Body.String()))
+
+	assert.Eq(t, http.StatusNoContent, doReq(h, "DELETE", "/keys/a/b", "").Code)
+	assert.Eq(t, http.StatusNotFound, doReq(h, "DELETE", "/keys/a/b", "").Code)
+
+	w = doReq(h, "GET", "/stats", "")
+	assert.StrContains(t, w.Body.String(), `"len":3`)
+	assert.StrContains(t, w.Body.String(), `"valid_len":3`)
+
+	w = doReq(h, "GET", "/health", "")
+	assert.Eq(t, http.StatusOK, w.Code)
+	assert.Eq(t, `{"healthy":true,"problems":[]}`, strings.TrimSpace(w.Body.String()))
+
+	assert.Eq(t, http.StatusNoContent, doReq(h, "POST", "/save", "").Code)
+	_, err := os.Stat(saveFile)
+	assert.NoErr(t, err)
+
+	assert.Eq(t, http.StatusNoContent, doReq(h, "POST", "/clear", "").Code)
+	assert.Eq(t, 0, c.Len())
+	assert.Eq(t, http.StatusMethodNotAllowed, doReq(h, "GET", "/clear", "").Code)
+}
+
+func TestHandler_noSaveFile(t *testing.T) {
+	h := httpadmin.Handler(lcache.New())
+	req := httptest.NewRequest("POST", "/save", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Eq(t, http.StatusNotImplemented, w.Code)
+}
@@ -0,0 +1,211 @@
+// Package httpcache provides a HTTP middleware to cache the GET responses in a lcache.Cache.
+//
+// Usage:
+//
+//	c := lcache.New(lcache.WithCapacity(5000))
+//	mw := httpcache.Middleware(c, httpcache.WithTTL(time.Minute), httpcache.WithVary("Accept-Language"))
+//	http.ListenAndServe(":8080", mw(mux))
+package httpcache
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+)
+
+// HeaderCache the response header for report cache status: HIT or MISS
+const HeaderCache = "X-Cache"
+
+// Option for the middleware
+type Option func(o *options)
+
+// options 中间件配置
+type options struct {
+	ttl    time.Duration
+	vary   []string
+	prefix string
+	// identity 获取带凭证请求的身份标识
+	identity func(r *http.Request) string
+}
+
+// WithTTL use a fixed TTL for all cached responses, ignore the Cache-Control header of response.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) { o.ttl = ttl }
+}
+
+// WithVary add request headers to the cache key, the responses are cached separately
+// for the different header values. eg: "Accept-Encoding", "Accept-Language"
+func WithVary(headers ...string) Option {
+	return func(o *options) {
+		for _, h := range headers {
+			o.vary = append(o.vary, http.CanonicalHeaderKey(h))
+		}
+	}
+}
+
+// WithIdentity cache the responses of the requests with credentials(Authorization or Cookie
+// header), separately for each identity. fn returns the identity of the request, eg: the user ID
+// of the session, return empty string to skip the cache for the request.
+//
+// Without it, the requests with credentials are not cached, the responses may contain private data.
+func WithIdentity(fn func(r *http.Request) string) Option {
+	return func(o *options) { o.identity = fn }
+}
+
+// WithKeyPrefix set the cache key prefix. default is "httpcache:"
+func WithKeyPrefix(prefix string) Option {
+	return func(o *options) { o.prefix = prefix }
+}
+
+// entry 缓存的响应数据
+type entry struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Middleware create a middleware that caches the GET responses in the cache.
+//
+// The responses are keyed by method + URL (and the vary headers). Only 200 OK responses without
+// Set-Cookie are cached. The responses with no-store, no-cache or private are never cached. The TTL
+// is the WithTTL option if set, otherwise the max-age(or s-maxage) of the response Cache-Control
+// header, the responses without max-age are not cached.
+//
+// The requests with Authorization or Cookie header are passed through, unless WithIdentity is set.
+func Middleware(c *lcache.Cache, opts ...Option) func(http.Handler) http.Handler {
+	o := &options{prefix: "httpcache:"}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var ident string
+			if r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != "" {
+				if o.identity != nil {
+					ident = o.identity(r)
+				}
+				if ident == "" {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			key := o.cacheKey(r, ident)
+			if val, ok := c.Get(key); ok {
+				if e, ok := val.(*entry); ok {
+					e.writeTo(w)
+					return
+				}
+			}
+
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			rec.Header().Set(HeaderCache, "MISS")
+			next.ServeHTTP(rec, r)
+
+			if ttl, ok := o.responseTTL(rec); ok {
+				c.Set(key, &entry{
+					Status: rec.status,
+					Header: rec.Header().Clone(),
+					Body:   rec.body.Bytes(),
+				}, ttl)
+			}
+		})
+	}
+}
+
+// cacheKey 生成缓存 key: prefix + method + URL + vary headers + identity
+func (o *options) cacheKey(r *http.Request, ident string) string {
+	var sb strings.Builder
+	sb.WriteString(o.prefix)
+	sb.WriteString(r.Method)
+	sb.WriteByte(' ')
+	sb.WriteString(r.URL.String())
+	for _, h := range o.vary {
+		sb.WriteByte('\n')
+		sb.WriteString(h)
+		sb.WriteByte('=')
+		sb.WriteString(strings.Join(r.Header.Values(h), ","))
+	}
+	if ident != "" {
+		sb.WriteString("\nidentity=")
+		sb.WriteString(ident)
+	}
+	return sb.String()
+}
+
+// responseTTL 检查响应是否可以缓存，并返回缓存的 TTL
+func (o *options) responseTTL(rec *recorder) (time.Duration, bool) {
+	if rec.status != http.StatusOK || rec.Header().Get("Set-Cookie") != "" {
+		return 0, false
+	}
+
+	var maxAge, sMaxAge = -1, -1
+	for _, directive := range strings.Split(rec.Header().Get("Cache-Control"), ",") {
+		name, val, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache", "private":
+			return 0, false
+		case "max-age":
+			maxAge, _ = strconv.Atoi(val)
+		case "s-maxage":
+			sMaxAge, _ = strconv.Atoi(val)
+		}
+	}
+	if o.ttl > 0 {
+		return o.ttl, true
+	}
+
+	// 共享缓存优先使用 s-maxage
+	if sMaxAge > 0 {
+		return time.Duration(sMaxAge) * time.Second, true
+	}
+	if maxAge > 0 {
+		return time.Duration(maxAge) * time.Second, true
+	}
+	return 0, false
+}
+
+func (e *entry) writeTo(w http.ResponseWriter) {
+	h := w.Header()
+	for name, vals := range e.Header {
+		h[name] = append([]string(nil), vals...)
+	}
+	h.Set(HeaderCache, "HIT")
+	w.WriteHeader(e.Status)
+	_, _ = w.Write(e.Body)
+}
+
+// recorder 记录响应状态和内容，同时写入到原始的 ResponseWriter
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the original ResponseWriter, for http.ResponseController
+func (r *recorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
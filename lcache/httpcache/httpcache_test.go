@@ -0,0 +1,133 @@
+package httpcache_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/lcache/httpcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	var calls int
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/private":
+			w.Header().Set("Cache-Control", "private, max-age=60")
+		case "/none":
+		case "/err":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = fmt.Fprintf(w, "call %d lang=%s", calls, r.Header.Get("Accept-Language"))
+	})
+
+	c := lcache.New()
+	h := httpcache.Middleware(c, httpcache.WithVary("accept-language"))(next)
+	get := func(path, lang string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept-Language", lang)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/page?a=1", "en")
+	assert.Eq(t, "MISS", w.Header().Get(httpcache.HeaderCache))
+	assert.Eq(t, "call 1 lang=en", w.Body.String())
+
+	w = get("/page?a=1", "en")
+	assert.Eq(t, "HIT", w.Header().Get(httpcache.HeaderCache))
+	assert.Eq(t, "call 1 lang=en", w.Body.String())
+	assert.Eq(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Eq(t, 1, calls)
+
+	// vary by header
+	w = get("/page?a=1", "zh")
+	assert.Eq(t, "call 2 lang=zh", w.Body.String())
+	// different url
+	w = get("/page?a=2", "en")
+	assert.Eq(t, "MISS", w.Header().Get(httpcache.HeaderCache))
+
+	// not cacheable
+	for _, path := range []string{"/private", "/none", "/err"} {
+		get(path, "")
+		w = get(path, "")
+		assert.Eq(t, "MISS", w.Header().Get(httpcache.HeaderCache), path)
+	}
+
+	// non GET
+	req := httptest.NewRequest("POST", "/page?a=1", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Eq(t, "", w.Header().Get(httpcache.HeaderCache))
+}
+
+func TestWithTTL(t *testing.T) {
+	c := lcache.New()
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	h := httpcache.Middleware(c, httpcache.WithTTL(time.Minute), httpcache.WithKeyPrefix("page:"))(next)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/a", nil))
+	keys := c.Keys()
+	assert.Len(t, keys, 1)
+	assert.StrContains(t, keys[0], "page:GET /a")
+
+	ttl, ok := c.TTL(keys[0])
+	assert.True(t, ok)
+	assert.True(t, ttl > 50*time.Second)
+}
+
+func TestWithIdentity(t *testing.T) {
+	var calls int
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path == "/private" {
+			w.Header().Set("Cache-Control", "private")
+		}
+		_, _ = fmt.Fprintf(w, "call %d user=%s", calls, r.Header.Get("Authorization"))
+	})
+	get := func(h http.Handler, path, auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	// the requests with credentials are not cached by default
+	c := lcache.New()
+	h := httpcache.Middleware(c, httpcache.WithTTL(time.Minute))(next)
+	get(h, "/page", "tom")
+	w := get(h, "/page", "tom")
+	assert.Eq(t, "", w.Header().Get(httpcache.HeaderCache))
+	assert.Eq(t, "call 2 user=tom", w.Body.String())
+	assert.Eq(t, 0, c.Len())
+
+	// private is honored with WithTTL
+	get(h, "/private", "")
+	assert.Eq(t, "MISS", get(h, "/private", "").Header().Get(httpcache.HeaderCache))
+
+	// cached per identity
+	h = httpcache.Middleware(c, httpcache.WithTTL(time.Minute), httpcache.WithIdentity(func(r *http.Request) string {
+		return r.Header.Get("Authorization")
+	}))(next)
+	assert.Eq(t, "call 5 user=tom", get(h, "/page", "tom").Body.String())
+	w = get(h, "/page", "tom")
+	assert.Eq(t, "HIT", w.Header().Get(httpcache.HeaderCache))
+	assert.Eq(t, "call 5 user=tom", w.Body.String())
+	assert.Eq(t, "call 6 user=jack", get(h, "/page", "jack").Body.String())
+	assert.Eq(t, "call 7 user=", get(h, "/page", "").Body.String())
+}
@@ -0,0 +1,83 @@
+package lcache
+
+import "time"
+
+// GetByIndex get the values by secondary index name and index value. see WithIndex
+//
+// NOTE: the order of returned values is not guaranteed, and it does not update the LRU order.
+func (c *Cache) GetByIndex(name, val string) []any {
+	if !c.rlock() {
+		return nil
+	}
+	defer c.mu.RUnlock()
+
+	keys := c.indexes[name][val]
+	if len(keys) == 0 {
+		return nil
+	}
+
+	nowUm := time.Now().UnixMilli()
+	vals := make([]any, 0, len(keys))
+	for key := range keys {
+		if it, ok := c.items[key]; ok && !c.invalid(it, nowUm) {
+			vals = append(vals, it.Val)
+		}
+	}
+	return vals
+}
+
+// updateIndexes 更新 key 的所有二级索引 (不加锁)
+func (c *Cache) updateIndexes(key string, it *Item) {
+	if len(c.opt.Indexes) == 0 {
+		return
+	}
+
+	c.removeIndexes(key)
+	if c.indexes == nil {
+		c.indexes = make(map[string]map[string]map[string]struct{})
+		c.idxVals = make(map[string]map[string]string)
+	}
+
+	vals := make(map[string]string, len(c.opt.Indexes))
+	for name, fn := range c.opt.Indexes {
+		iv := fn(it.Val)
+		if iv == "" {
+			continue
+		}
+
+		idx := c.indexes[name]
+		if idx == nil {
+			idx = make(map[string]map[string]struct{})
+			c.indexes[name] = idx
+		}
+		if idx[iv] == nil {
+			idx[iv] = make(map[string]struct{})
+		}
+
+		idx[iv][key] = struct{}{}
+		vals[name] = iv
+	}
+	c.idxVals[key] = vals
+}
+
+// removeIndexes 删除 key 的所有二级索引 (不加锁)
+func (c *Cache) removeIndexes(key string) {
+	vals, ok := c.idxVals[key]
+	if !ok {
+		return
+	}
+
+	for name, iv := range vals {
+		keys := c.indexes[name][iv]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(c.indexes[name], iv)
+		}
+	}
+	delete(c.idxVals, key)
+}
+
+// resetIndexes 清空所有二级索引 (不加锁)
+func (c *Cache) resetIndexes() {
+	c.indexes, c.idxVals = nil, nil
+}
@@ -0,0 +1,44 @@
+package lcache_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+type order struct {
+	ID     int
+	UserID int
+}
+
+func TestCache_GetByIndex(t *testing.T) {
+	c := lcache.New(lcache.WithIndex("byUser", func(val any) string {
+		if o, ok := val.(*order); ok {
+			return strconv.Itoa(o.UserID)
+		}
+		return ""
+	}))
+
+	c.Set("order:1", &order{ID: 1, UserID: 42}, 0)
+	c.Set("order:2", &order{ID: 2, UserID: 42}, 0)
+	c.Set("order:3", &order{ID: 3, UserID: 7}, 0)
+	c.Set("other", "not indexed", 0)
+
+	assert.Len(t, c.GetByIndex("byUser", "42"), 2)
+	assert.Len(t, c.GetByIndex("byUser", "7"), 1)
+	assert.Empty(t, c.GetByIndex("byUser", "100"))
+	assert.Empty(t, c.GetByIndex("not-exists", "42"))
+
+	// update value, index should be moved
+	c.Set("order:2", &order{ID: 2, UserID: 7}, 0)
+	assert.Len(t, c.GetByIndex("byUser", "42"), 1)
+	assert.Len(t, c.GetByIndex("byUser", "7"), 2)
+
+	c.Delete("order:1")
+	assert.Empty(t, c.GetByIndex("byUser", "42"))
+
+	c.Clear()
+	assert.Empty(t, c.GetByIndex("byUser", "7"))
+}
@@ -0,0 +1,78 @@
+package lcache
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+)
+
+// EntryInfo the metadata of a cache item. see Cache.Inspect
+type EntryInfo struct {
+	// CreatedAt the time of the item written(or loaded from snapshot)
+	CreatedAt time.Time
+	// LastAccess the time of the last hit, zero time if never hit
+	LastAccess time.Time
+	// Hits number of hits since the item was written
+	Hits uint64
+	// TTL remaining time to live, 0 for never expire
+	TTL time.Duration
+	// Size approximate size of the value in bytes. see ApproxSize
+	Size int
+}
+
+// Inspect get the metadata of a valid item, for debugging and admin UIs.
+// It does not update the LRU order and the hit statistics.
+func (c *Cache) Inspect(key string) (EntryInfo, bool) {
+	key, err := c.normKey(key)
+	if err != nil || !c.rlock() {
+		return EntryInfo{}, false
+	}
+	defer c.mu.RUnlock()
+
+	_, it := c.find(c.nsKey(key))
+	nowUm := time.Now().UnixMilli()
+	if it == nil || c.invalid(it, nowUm) {
+		return EntryInfo{}, false
+	}
+
+	info := EntryInfo{
+		CreatedAt: time.UnixMilli(it.created),
+		Hits:      atomic.LoadUint64(&it.hits),
+		Size:      ApproxSize(it.Val),
+	}
+	if access := atomic.LoadInt64(&it.access); access > 0 {
+		info.LastAccess = time.UnixMilli(access)
+	}
+	if it.Exp > 0 {
+		info.TTL = time.Duration(it.Exp-nowUm) * time.Millisecond
+	}
+	return info, true
+}
+
+// ApproxSize get the approximate size of the value in bytes.
+// It is the length for string and []byte, the size for fixed-size numbers,
+// otherwise the length of the JSON encoded value, 0 if it cannot be encoded.
+func ApproxSize(val any) int {
+	switch v := val.(type) {
+	case nil:
+		return 0
+	case string:
+		return len(v)
+	case []byte:
+		return len(v)
+	case bool, int8, uint8:
+		return 1
+	case int16, uint16:
+		return 2
+	case int32, uint32, float32:
+		return 4
+	case int, uint, int64, uint64, float64, uintptr:
+		return 8
+	}
+
+	bs, err := json.Marshal(val)
+	if err != nil {
+		return 0
+	}
+	return len(bs)
+}
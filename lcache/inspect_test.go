@@ -0,0 +1,50 @@
+package lcache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_Inspect(t *testing.T) {
+	c := lcache.New(lcache.WithCapacity(2))
+	start := time.Now().Truncate(time.Millisecond)
+	c.Set("key1", "hello", time.Hour)
+	c.Set("key2", map[string]int{"a": 1}, 0)
+
+	info, ok := c.Inspect("key1")
+	assert.True(t, ok)
+	assert.False(t, info.CreatedAt.Before(start))
+	assert.True(t, info.LastAccess.IsZero())
+	assert.Eq(t, uint64(0), info.Hits)
+	assert.True(t, info.TTL > 59*time.Minute)
+	assert.Eq(t, 5, info.Size)
+
+	c.Get("key1")
+	info, _ = c.Inspect("key1")
+	assert.Eq(t, uint64(1), info.Hits)
+	assert.False(t, info.LastAccess.Before(info.CreatedAt))
+
+	info, ok = c.Inspect("key2")
+	assert.True(t, ok)
+	assert.Eq(t, time.Duration(0), info.TTL)
+	assert.Eq(t, 7, info.Size)
+
+	// does not update the LRU order
+	c.Set("key3", 3, 0)
+	assert.False(t, c.Has("key2"))
+	_, ok = c.Inspect("key2")
+	assert.False(t, ok)
+	assert.Eq(t, uint64(1), c.Stats().Hits)
+}
+
+func TestApproxSize(t *testing.T) {
+	assert.Eq(t, 0, lcache.ApproxSize(nil))
+	assert.Eq(t, 3, lcache.ApproxSize([]byte("abc")))
+	assert.Eq(t, 8, lcache.ApproxSize(23))
+	assert.Eq(t, 1, lcache.ApproxSize(true))
+	assert.Eq(t, 7, lcache.ApproxSize([]int{1, 2, 3}))
+	assert.Eq(t, 0, lcache.ApproxSize(func() {}))
+}
@@ -0,0 +1,106 @@
+package lcache
+
+import (
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Range calls fn for each valid item in the cache, stop iteration if fn returns false.
+// The exp is zero time for items without expiration.
+//
+// It iterates a snapshot of the items taken under the read lock, so fn can
+// safely call the cache methods. For a namespace view, only iterates the items in it.
+func (c *Cache) Range(fn func(key string, val any, exp time.Time) bool) {
+	for _, e := range c.rangeItems() {
+		var exp time.Time
+		if e.exp > 0 {
+			exp = time.UnixMilli(e.exp)
+		}
+
+		if !fn(e.key, e.val, exp) {
+			return
+		}
+	}
+}
+
+// rangeEntry 迭代时的数据项快照
+type rangeEntry struct {
+	key  string
+	val  any
+	exp  int64
+	hits uint64
+}
+
+// rangeItems 复制所有有效的数据项
+func (c *Cache) rangeItems() []rangeEntry {
+	if !c.rlock() {
+		return nil
+	}
+	defer c.mu.RUnlock()
+
+	entries := make([]rangeEntry, 0, len(c.items))
+	nowUm := time.Now().UnixMilli()
+	for k, v := range c.items {
+		if c.invalid(v, nowUm) {
+			continue
+		}
+
+		if c.ns != "" {
+			if !strings.HasPrefix(k, c.ns) {
+				continue
+			}
+			k = k[len(c.ns):]
+		}
+		entries = append(entries, rangeEntry{key: k, val: v.Val, exp: v.Exp, hits: atomic.LoadUint64(&v.hits)})
+	}
+	return entries
+}
+
+// ExpiringWithin get the valid keys that will expire within d, eg: for a warming job
+// to proactively refresh the soon-to-expire items. Items without expiration are not included.
+//
+// NOTE: it will traverse all data, the time complexity is O(N)
+func (c *Cache) ExpiringWithin(d time.Duration) []string {
+	deadline := time.Now().Add(d).UnixMilli()
+	return c.keys(func(_ string, it *Item) bool {
+		return it.Exp > 0 && it.Exp <= deadline
+	})
+}
+
+// KeyHits the hit count of a key. see HotKeys
+type KeyHits struct {
+	Key  string
+	Hits uint64
+}
+
+// KeyTracker track the accessed keys. eg: *topk.Tracker for the heavy hitters in fixed memory,
+// includes the missing and evicted keys. see WithKeyTracker
+type KeyTracker interface {
+	Add(key string)
+}
+
+// HotKeys get the top n valid keys by hit count since they were written, in descending order.
+// Keys never hit are not included.
+//
+// NOTE: it will traverse all data, the time complexity is O(N*logN)
+func (c *Cache) HotKeys(n int) []KeyHits {
+	var list []KeyHits
+	for _, e := range c.rangeItems() {
+		if e.hits > 0 {
+			list = append(list, KeyHits{Key: e.key, Hits: e.hits})
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Hits != list[j].Hits {
+			return list[i].Hits > list[j].Hits
+		}
+		return list[i].Key < list[j].Key
+	})
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
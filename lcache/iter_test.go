@@ -0,0 +1,102 @@
+package lcache_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_Range(t *testing.T) {
+	c := lcache.New()
+	c.Set("key1", "val1", 0)
+	c.Set("key2", "val2", time.Hour)
+	c.Set("expired", "val", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	data := make(map[string]any)
+	c.Range(func(key string, val any, exp time.Time) bool {
+		data[key] = val
+		if key == "key1" {
+			assert.True(t, exp.IsZero())
+		} else {
+			assert.True(t, exp.After(time.Now()))
+		}
+
+		// can call cache methods in fn
+		c.Set("new-"+key, val, 0)
+		return true
+	})
+	assert.Eq(t, map[string]any{"key1": "val1", "key2": "val2"}, data)
+	assert.True(t, c.Has("new-key1"))
+
+	// stop iteration
+	var n int
+	c.Range(func(string, any, time.Time) bool {
+		n++
+		return false
+	})
+	assert.Eq(t, 1, n)
+}
+
+func TestCache_ExpiringWithin(t *testing.T) {
+	c := lcache.New()
+	c.Set("never", 1, 0)
+	c.Set("soon", 2, time.Second)
+	c.Set("later", 3, time.Hour)
+	c.Set("expired", 4, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	assert.Eq(t, []string{"soon"}, c.ExpiringWithin(time.Minute))
+	assert.Len(t, c.ExpiringWithin(2*time.Hour), 2)
+	assert.Empty(t, c.ExpiringWithin(0))
+}
+
+func TestCache_HotKeys(t *testing.T) {
+	c := lcache.New()
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Set("c", 3, 0)
+	for i := 0; i < 3; i++ {
+		c.Get("b")
+	}
+	c.Get("a")
+	c.Get("none")
+
+	assert.Eq(t, []lcache.KeyHits{{Key: "b", Hits: 3}, {Key: "a", Hits: 1}}, c.HotKeys(5))
+	assert.Len(t, c.HotKeys(1), 1)
+
+	st := c.Stats()
+	assert.Eq(t, uint64(4), st.Hits)
+	assert.Eq(t, uint64(1), st.Misses)
+	assert.Eq(t, 0.8, st.HitRatio())
+	assert.Eq(t, 0.0, lcache.Stats{}.HitRatio())
+}
+
+type testKeyTracker struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (t *testKeyTracker) Add(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.keys = append(t.keys, key)
+}
+
+func TestWithKeyTracker(t *testing.T) {
+	tr := &testKeyTracker{}
+	c := lcache.New(lcache.WithKeyTracker(tr))
+	c.Set("a", 1, 0)
+	c.Namespace("users").Set("1", "tom", 0)
+
+	c.Get("a")
+	c.Get("missing")
+	c.Namespace("users").Get("1")
+	assert.Eq(t, 1, c.Val("a"))
+	// write is not tracked
+	c.Set("b", 2, 0)
+	assert.Eq(t, []string{"a", "missing", "users:1", "a"}, tr.keys)
+}
@@ -0,0 +1,188 @@
+package lcache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Alert triggered by the janitor. see WithAlert
+type Alert struct {
+	Kind AlertKind
+	// Value the observed value: hit ratio, evictions per second or memory size in bytes
+	Value float64
+	// Threshold the configured threshold of the alert. see AlertRules
+	Threshold float64
+	// Time of the alert triggered
+	Time time.Time
+}
+
+// String get the alert message
+func (a Alert) String() string {
+	return fmt.Sprintf("lcache alert %s: value %.4g, threshold %.4g", a.Kind, a.Value, a.Threshold)
+}
+
+// AlertRules the thresholds of the alerts, the zero value of a threshold disables the alert.
+type AlertRules struct {
+	// MinHitRatio alert when the hit ratio since the last janitor run is below it.
+	MinHitRatio float64
+	// MinRequests the minimum Get calls since the last janitor run to evaluate the hit ratio.
+	MinRequests uint64
+	// MaxEvictRate alert when the evictions per second since the last janitor run is above it.
+	MaxEvictRate float64
+	// MemoryBudget alert when the approximate size of the keys and values is above it, in bytes.
+	// see ApproxSize. NOTE: it will traverse all data, the time complexity is O(N)
+	MemoryBudget int64
+}
+
+// DefaultAlertRules the default alert rules for WithAlert
+var DefaultAlertRules = AlertRules{MinHitRatio: 0.5, MinRequests: 100}
+
+// janitorSample 执行清理任务时的统计计数
+type janitorSample struct {
+	at     time.Time
+	hits   uint64
+	misses uint64
+	evicts uint64
+}
+
+// startJanitor 根据配置(重新)启动定时清理任务
+func (c *Cache) startJanitor() { c.startJanitorAfter(0) }
+
+// startJanitorAfter 延迟 delay 后再启动定时清理任务，用于错开多个缓存实例的执行时间
+func (c *Cache) startJanitorAfter(delay time.Duration) {
+	c.janTaskMu.Lock()
+	defer c.janTaskMu.Unlock()
+	c.stopJanitorLocked()
+
+	c.mu.RLock()
+	interval := c.opt.JanitorInterval
+	c.mu.RUnlock()
+	if interval <= 0 {
+		return
+	}
+
+	c.janMu.Lock()
+	c.janLast = c.sample()
+	c.janMu.Unlock()
+	c.janRunAt.Store(time.Now().Add(delay).UnixMilli())
+	if delay <= 0 {
+		c.janitorStop = c.scheduler().Every(interval, c.runJanitor)
+		return
+	}
+
+	var mu sync.Mutex
+	var stopped bool
+	var stopEvery func()
+	cancel := c.scheduler().After(delay, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if !stopped {
+			stopEvery = c.scheduler().Every(interval, c.runJanitor)
+		}
+	})
+
+	c.janitorStop = func() {
+		cancel()
+		mu.Lock()
+		defer mu.Unlock()
+		stopped = true
+		if stopEvery != nil {
+			stopEvery()
+		}
+	}
+}
+
+// stopJanitor 停止定时清理任务
+func (c *Cache) stopJanitor() {
+	c.janTaskMu.Lock()
+	defer c.janTaskMu.Unlock()
+	c.stopJanitorLocked()
+}
+
+// stopJanitorLocked 停止定时清理任务 (需持有 janTaskMu)
+func (c *Cache) stopJanitorLocked() {
+	if c.janitorStop != nil {
+		c.janitorStop()
+		c.janitorStop = nil
+	}
+}
+
+// runJanitor 删除已过期的数据并检查告警
+func (c *Cache) runJanitor() {
+	c.janRunAt.Store(time.Now().UnixMilli())
+	if c.lock() {
+		if !c.frozen.Load() {
+			c.pruneExpired("")
+		}
+		c.mu.Unlock()
+	}
+
+	if c.opt.AlertFn != nil {
+		c.checkAlerts()
+	}
+}
+
+// sample 获取当前的统计计数
+func (c *Cache) sample() janitorSample {
+	return janitorSample{
+		at:     time.Now(),
+		hits:   c.hits.Load(),
+		misses: c.misses.Load(),
+		evicts: c.evictions.Load(),
+	}
+}
+
+// checkAlerts 与上次执行时的统计比较，检查告警. 告警只在条件首次满足时触发，恢复后可再次触发
+func (c *Cache) checkAlerts() {
+	rules := c.opt.AlertRules
+	var size int64
+	if rules.MemoryBudget > 0 {
+		size = c.approxMemory()
+	}
+
+	c.janMu.Lock()
+	now, last := c.sample(), c.janLast
+	c.janLast = now
+
+	var alerts []Alert
+	check := func(kind AlertKind, triggered bool, value, threshold float64) {
+		if triggered && !c.janAlerts[kind] {
+			alerts = append(alerts, Alert{Kind: kind, Value: value, Threshold: threshold, Time: now.at})
+		}
+		c.janAlerts[kind] = triggered
+	}
+
+	if rules.MinHitRatio > 0 {
+		hits := now.hits - last.hits
+		if total := hits + now.misses - last.misses; total > 0 && total >= rules.MinRequests {
+			ratio := float64(hits) / float64(total)
+			check(AlertLowHitRatio, ratio < rules.MinHitRatio, ratio, rules.MinHitRatio)
+		}
+	}
+	if secs := now.at.Sub(last.at).Seconds(); rules.MaxEvictRate > 0 && secs > 0 {
+		rate := float64(now.evicts-last.evicts) / secs
+		check(AlertEvictionSpike, rate > rules.MaxEvictRate, rate, rules.MaxEvictRate)
+	}
+	if rules.MemoryBudget > 0 {
+		check(AlertOverBudget, size > rules.MemoryBudget, float64(size), float64(rules.MemoryBudget))
+	}
+	c.janMu.Unlock()
+
+	for _, a := range alerts {
+		c.opt.AlertFn(a)
+	}
+}
+
+// approxMemory 估算所有 key 和值占用的内存大小. see ApproxSize
+func (c *Cache) approxMemory() (size int64) {
+	if !c.rlock() {
+		return 0
+	}
+	defer c.mu.RUnlock()
+
+	for key, it := range c.items {
+		size += int64(len(key) + ApproxSize(it.Val))
+	}
+	return size
+}
@@ -0,0 +1,95 @@
+package lcache_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_WithJanitor(t *testing.T) {
+	c := lcache.New(lcache.WithJanitor(10 * time.Millisecond))
+	defer c.Close()
+
+	c.Set("key1", "v1", 0)
+	c.Set("key2", "v2", 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Eq(t, 1, c.Len())
+}
+
+// alertRecorder 记录告警，回调在后台任务中调用
+type alertRecorder struct {
+	mu     sync.Mutex
+	alerts []lcache.Alert
+}
+
+func (r *alertRecorder) add(a lcache.Alert) {
+	r.mu.Lock()
+	r.alerts = append(r.alerts, a)
+	r.mu.Unlock()
+}
+
+func (r *alertRecorder) kinds() []lcache.AlertKind {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kinds := make([]lcache.AlertKind, 0, len(r.alerts))
+	for _, a := range r.alerts {
+		kinds = append(kinds, a.Kind)
+	}
+	return kinds
+}
+
+func TestCache_WithAlert(t *testing.T) {
+	rec := &alertRecorder{}
+	c := lcache.New(
+		lcache.WithCapacity(2),
+		lcache.WithAlertRules(lcache.AlertRules{MinHitRatio: 0.5, MinRequests: 10, MaxEvictRate: 100}),
+		lcache.WithAlert(rec.add),
+		lcache.WithJanitor(30*time.Millisecond),
+	)
+	defer c.Close()
+
+	// low hit ratio, triggered once
+	for i := 0; i < 20; i++ {
+		c.Get("not-exists")
+	}
+	time.Sleep(40 * time.Millisecond)
+	assert.Eq(t, []lcache.AlertKind{lcache.AlertLowHitRatio}, rec.kinds())
+	for i := 0; i < 20; i++ {
+		c.Get("not-exists")
+	}
+	time.Sleep(30 * time.Millisecond)
+	assert.Len(t, rec.kinds(), 1)
+
+	// eviction spike
+	for i := 0; i < 100; i++ {
+		c.Set(strings.Repeat("k", i+1), i, 0)
+	}
+	time.Sleep(30 * time.Millisecond)
+	assert.Eq(t, []lcache.AlertKind{lcache.AlertLowHitRatio, lcache.AlertEvictionSpike}, rec.kinds())
+	assert.Eq(t, uint64(98), c.Stats().Evictions)
+}
+
+func TestCache_WithAlert_memoryBudget(t *testing.T) {
+	rec := &alertRecorder{}
+	c := lcache.New(
+		lcache.WithAlert(rec.add),
+		lcache.WithAlertRules(lcache.AlertRules{MemoryBudget: 100}),
+		lcache.WithJanitor(10*time.Millisecond),
+	)
+	defer c.Close()
+
+	c.Set("key", strings.Repeat("a", 200), 0)
+	time.Sleep(30 * time.Millisecond)
+	assert.Eq(t, []lcache.AlertKind{lcache.AlertOverBudget}, rec.kinds())
+
+	rec.mu.Lock()
+	a := rec.alerts[0]
+	rec.mu.Unlock()
+	assert.Eq(t, float64(203), a.Value)
+	assert.Eq(t, "lcache alert over-budget: value 203, threshold 100", a.String())
+}
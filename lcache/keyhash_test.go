@@ -0,0 +1,50 @@
+package lcache
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestXXH64(t *testing.T) {
+	assert.Eq(t, uint64(0xef46db3751d8e999), xxh64(""))
+	assert.Eq(t, uint64(0x44bc2cf5ad770999), xxh64("abc"))
+	// >= 32 bytes
+	long := strings.Repeat("abcdefgh", 8)
+	assert.Eq(t, xxh64(long), xxh64(long))
+	assert.NotEq(t, xxh64(long), xxh64(long+"x"))
+}
+
+func TestCache_WithKeyHashing(t *testing.T) {
+	c := New(WithKeyHashing(16), WithKeyHashCheck(true))
+	longKey := "SELECT * FROM users WHERE id = 1"
+
+	c.Set("short", "val0", 0)
+	c.Set(longKey, "val1", 0)
+	assert.Eq(t, "val0", c.Val("short"))
+	assert.Eq(t, "val1", c.Val(longKey))
+	assert.True(t, c.Has(longKey))
+
+	hk := c.hashKey(longKey)
+	assert.Len(t, hk, len(keyHashPrefix)+16)
+	assert.Contains(t, c.Keys(), hk)
+
+	// simulate fingerprint collision
+	c.items[hk].Key = "other key"
+	_, ok := c.Get(longKey)
+	assert.False(t, ok)
+
+	// the colliding key is not deleted
+	assert.False(t, c.Delete(longKey))
+	c.MDelete(longKey)
+	assert.NoErr(t, c.Update(func(tx *Tx) error {
+		tx.Delete(longKey)
+		return nil
+	}))
+	assert.Eq(t, 2, c.Len())
+
+	c.items[hk].Key = longKey
+	assert.True(t, c.Delete(longKey))
+	assert.Eq(t, 1, c.Len())
+}
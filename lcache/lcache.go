@@ -17,44 +17,143 @@
 package lcache
 
 import (
-	"encoding/json"
-	"io"
+	"context"
+	"crypto/aes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync/atomic"
 	"time"
 
+	"github.com/gookit/ext/lcache/serializer"
 	"github.com/gookit/goutil/comdef"
 	"github.com/gookit/goutil/strutil"
 )
 
-// std 默认的全局缓存实例
-var std = New()
+var (
+	// ErrBusy the cache lock cannot be acquired in time. see WithLockTimeout
+	ErrBusy = errors.New("lcache: cache is busy, lock timeout")
+	// ErrBadSnapshot the snapshot file header is invalid or unsupported
+	ErrBadSnapshot = errors.New("lcache: bad snapshot file")
+	// ErrBadChecksum the snapshot file checksum mismatch, the file may be corrupted
+	ErrBadChecksum = errors.New("lcache: snapshot checksum mismatch")
+	// ErrWrongSerializer the snapshot file is saved by another serializer
+	ErrWrongSerializer = errors.New("lcache: snapshot serializer mismatch")
+	// ErrNotFound the key is not found in the cache. see Cache.GetCtx
+	ErrNotFound = errors.New("lcache: key not found")
+	// ErrFrozen the cache is frozen, read-only. see Cache.Freeze
+	ErrFrozen = errors.New("lcache: cache is frozen")
+	// ErrExists the key already exists. see Cache.SetX and WithNX
+	ErrExists = errors.New("lcache: key already exists")
+	// ErrCacheFull the cache is full and the overflow policy is RejectNew. see WithOverflowPolicy
+	ErrCacheFull = errors.New("lcache: cache is full")
+	// ErrNotList the value of the key is not a list. see Cache.LPush
+	ErrNotList = errors.New("lcache: value is not a list")
+	// ErrNotHash the value of the key is not a hash. see Cache.HSet
+	ErrNotHash = errors.New("lcache: value is not a hash")
+	// ErrNotSet the value of the key is not a set. see Cache.SAdd
+	ErrNotSet = errors.New("lcache: value is not a set")
+	// ErrNotZSet the value of the key is not a sorted set. see Cache.ZAdd
+	ErrNotZSet = errors.New("lcache: value is not a sorted set")
+	// ErrNotNumber the value of the key is not a number(or not an integer for IncrBy). see Cache.IncrBy
+	ErrNotNumber = errors.New("lcache: value is not a number")
+	// ErrOverflow the result of increment overflows. see Cache.IncrBy, Cache.IncrByFloat
+	ErrOverflow = errors.New("lcache: increment would overflow")
+	// ErrNotHLL the value of the key is not a HyperLogLog. see Cache.PFAdd
+	ErrNotHLL = errors.New("lcache: value is not a HyperLogLog")
+	// ErrNotString the value of the key is not a string or []byte. see Cache.Append
+	ErrNotString = errors.New("lcache: value is not a string or bytes")
+	// ErrNoAOF the append-only log is not enabled. see WithAOF, Cache.CompactAOF
+	ErrNoAOF = errors.New("lcache: AOF is not enabled")
+	// ErrTooLarge the key or value exceeds the size limit. see SizeError, WithMaxKeyLen, WithMaxValueBytes
+	ErrTooLarge = errors.New("lcache: key or value is too large")
+	// ErrKeyTooLong the key exceeds the size limit, it matches ErrTooLarge too. see SizeError, WithMaxKeyLen
+	ErrKeyTooLong = fmt.Errorf("%w: key is too long", ErrTooLarge)
+	// ErrValueTooLarge the value exceeds the size limit, it matches ErrTooLarge too. see SizeError, WithMaxValueBytes
+	ErrValueTooLarge = fmt.Errorf("%w: value is too large", ErrTooLarge)
+	// ErrBadNamespace the namespaces of RenameNamespace are empty or overlapping
+	ErrBadNamespace = errors.New("lcache: empty or overlapping namespace")
+	// ErrCopy the CopyFn failed to copy the value. see WithCopyFn
+	ErrCopy = errors.New("lcache: copy value failed")
+)
+
+// std 默认的全局缓存实例，使用原子指针，可以在使用中替换. see SetStd
+var std atomic.Pointer[Cache]
+
+func init() { std.Store(New()) }
+
+// Reset the default cache instance with a new one.
+// The background tasks(eg: auto-save, janitor) of the old instance are stopped, without the final save.
+func Reset() {
+	old := std.Swap(New())
+	old.stopTasks()
+}
 
-// Reset the default cache instance
-func Reset() { std = New() }
+// Std get the default cache instance behind the package-level functions
+func Std() *Cache { return std.Load() }
+
+// SetStd replace the default cache instance with a customized one, it is safe to call
+// while the package-level functions are in use. Returns the previous instance.
+//
+// The background tasks(eg: auto-save, janitor) of the previous instance are stopped like Reset.
+// Close it if the pending writes should be flushed and the final auto-save is needed.
+//
+// Usage:
+//
+//	old := lcache.SetStd(lcache.New(lcache.WithCapacity(10000), lcache.WithAutoSave("cache.json", time.Minute)))
+//	_ = old.Close()
+func SetStd(c *Cache) *Cache {
+	if c == nil {
+		panic("the default cache instance cannot be nil")
+	}
+
+	old := std.Swap(c)
+	if old != c {
+		old.stopTasks()
+	}
+	return old
+}
 
 // Configure the default cache settings
-func Configure(optFns ...OptionFn) { std.Configure(optFns...) }
+func Configure(optFns ...OptionFn) { Std().Configure(optFns...) }
+
+// CloseStd close the default cache instance. see Cache.Close
+//
+// Usage:
+//
+//	defer lcache.CloseStd()
+func CloseStd() error { return Std().Close() }
+
+// LoadAOF replay the append-only log file to the default cache.
+func LoadAOF(filename string) error { return Std().LoadAOF(filename) }
+
+// CompactAOF rewrite the append-only log file of the default cache.
+func CompactAOF() error { return Std().CompactAOF() }
 
 // Val get value by key
-func Val(key string) any { return std.Val(key) }
+func Val(key string) any { return Std().Val(key) }
 
 // Any get any type value by key
-func Any(key string) (val any, ok bool) { return std.Get(key) }
+func Any(key string) (val any, ok bool) { return Std().Get(key) }
 
 // Set value by key with TTL
 func Set[T any](key string, val T, ttl time.Duration) {
-	std.Set(key, val, ttl)
+	Std().Set(key, val, ttl)
 }
 
 // Get typed value by key, return zero value if not found
 func Get[T any](key string) (T, bool) {
-	return TypedInCache[T](std, key)
+	return TypedInCache[T](Std(), key)
 }
 
+// BumpGeneration invalidates all items written before the call. see Cache.BumpGeneration
+func BumpGeneration() uint64 { return Std().BumpGeneration() }
+
 // MGet get multiple key-value pairs from the cache.
-func MGet(keys ...string) map[string]any { return std.MGet(keys...) }
+func MGet(keys ...string) map[string]any { return Std().MGet(keys...) }
 
 // MSet set multiple key-value pairs in the cache.
-func MSet(items map[string]any, ttl time.Duration) { std.MSet(items, ttl) }
+func MSet(items map[string]any, ttl time.Duration) { Std().MSet(items, ttl) }
 
 // CacheNotExist 表示缓存不存在的特殊值，避免缓存穿透
 var CacheNotExist = "0_cache_not_exist_0"
@@ -73,32 +172,60 @@ func MGetOrElse[K comdef.SimpleType, T any](
 	cacheTTL time.Duration,
 	queryFn func(keys []K) (map[K]T, error),
 ) ([]T, error) {
-	return MGetElseUse(std, keyPrefix, keys, cacheTTL, queryFn)
+	return MGetElseUse(Std(), keyPrefix, keys, cacheTTL, queryFn)
 }
 
+// Has checks if a valid item exists in the default cache
+func Has(key string) bool { return Std().Has(key) }
+
 // Keys get the keys of the default cache
-func Keys() []string { return std.Keys() }
+func Keys() []string { return Std().Keys() }
 
 // Len get the number of items in the cache
-func Len() int { return std.Len() }
+func Len() int { return Std().Len() }
 
 // Clear all items from the default cache
-func Clear() { std.Clear() }
+func Clear() { Std().Clear() }
 
 // Delete key
-func Delete(key string) { std.Delete(key) }
+func Delete(key string) { Std().Delete(key) }
 
 // MDelete delete multiple keys
-func MDelete(keys ...string) { std.MDelete(keys...) }
+func MDelete(keys ...string) { Std().MDelete(keys...) }
 
 // SaveFile Save the cache data to a file.
 func SaveFile(filename string) error {
-	return std.SaveFile(filename)
+	return Std().SaveFile(filename)
+}
+
+// LoadFile Recover cache data from file load. see Cache.LoadFile
+func LoadFile(filename string, mode ...LoadMode) error {
+	return Std().LoadFile(filename, mode...)
+}
+
+// LoadFS load the cache data from a snapshot file in fsys. see Cache.LoadFS
+func LoadFS(fsys fs.FS, name string, mode ...LoadMode) error {
+	return Std().LoadFS(fsys, name, mode...)
+}
+
+// SaveDir save the cache data to a directory in shards. see Cache.SaveDir
+func SaveDir(dir string, shards int) error {
+	return Std().SaveDir(dir, shards)
+}
+
+// LoadDir load the cache data from a directory saved by SaveDir. see Cache.LoadDir
+func LoadDir(dir string, mode ...LoadMode) error {
+	return Std().LoadDir(dir, mode...)
 }
 
-// LoadFile Recover cache data from file load
-func LoadFile(filename string) error {
-	return std.LoadFile(filename)
+// HealthCheck check the internal invariants and the background jobs. see Cache.HealthCheck
+func HealthCheck() HealthReport {
+	return Std().HealthCheck()
+}
+
+// WarmUp load the entries from source in the background. see Cache.WarmUp
+func WarmUp(ctx context.Context, source WarmSource) (<-chan Progress, error) {
+	return Std().WarmUp(ctx, source)
 }
 
 //
@@ -199,47 +326,39 @@ func MGetElseUse[K comdef.SimpleType, T any](
 // ----- builtin serializers -----
 //
 
-type Serializer interface {
-	comdef.Codec
-	DecodeFrom(r io.Reader, dest any) error
-	EncodeTo(w io.Writer, src any) error
-}
+// Serializer interface for encode/decode the snapshot data. see package serializer
+type Serializer = serializer.Serializer
 
-var serializers = map[string]Serializer{
-	"json": JSONSerializer{},
-}
+// JSONSerializer builtin serializer: json
+type JSONSerializer = serializer.JSON
 
-// SetSerializer set new serializer for the cache. if serializer is nil, delete it
-func SetSerializer(name string, serializer Serializer) {
-	if serializer != nil {
-		serializers[name] = serializer
-	} else {
-		delete(serializers, name)
-	}
+// serializers 已注册的序列化器
+var serializers = serializer.NewRegistry()
+
+func init() {
+	serializers.Set("lcbin", LCBinSerializer{})
 }
 
-// JSONSerializer builtin serializer: json, gob
-type JSONSerializer struct{}
+// SetSerializer set new serializer for the cache. if serializer is nil, delete it.
+// The name is recorded in the snapshot file header, max 255 bytes.
+func SetSerializer(name string, s Serializer) { serializers.Set(name, s) }
 
-// Decode implements Serializer
-func (j JSONSerializer) Decode(data []byte, dest any) error {
-	return json.Unmarshal(data, dest)
-}
+// GetSerializer get the registered serializer by name
+func GetSerializer(name string) (Serializer, bool) { return serializers.Get(name) }
 
-// Encode implements Serializer
-func (j JSONSerializer) Encode(data any) ([]byte, error) {
-	return json.Marshal(data)
+// LookupSerializer get the registered serializer by name, returns an error if not registered
+func LookupSerializer(name string) (Serializer, error) {
+	if s, ok := serializers.Get(name); ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("lcache: not registered serializer name %q", name)
 }
 
-// DecodeFrom implements Serializer
-func (j JSONSerializer) DecodeFrom(r io.Reader, dest any) error {
-	return json.NewDecoder(r).Decode(dest)
-}
+// HasSerializer check the serializer name is registered
+func HasSerializer(name string) bool { return serializers.Has(name) }
 
-// EncodeTo implements Serializer
-func (j JSONSerializer) EncodeTo(w io.Writer, src any) error {
-	return json.NewEncoder(w).Encode(src)
-}
+// Serializers get all registered serializer names, sorted by name.
+func Serializers() []string { return serializers.Names() }
 
 //
 // ----- options for cache -----
@@ -249,12 +368,160 @@ func (j JSONSerializer) EncodeTo(w io.Writer, src any) error {
 type Options struct {
 	// Capacity maximum number of cached entries default is 1000
 	Capacity int
+	// Adaptive adjust the capacity by the memory pressure, nil to disable. see WithAdaptiveCapacity
+	Adaptive *AdaptiveOptions
+	// Overflow policy for writing a new item when the cache is full. see WithOverflowPolicy
+	Overflow OverflowPolicy
+	// MaxCost maximum total cost of the items, the least recently used items are evicted
+	// when exceeded. <= 0 to disable. see WithMaxCost and WithCost
+	MaxCost int64
+	// AccurateLen keep a live counter of the valid items, Len is O(1) and accurate. see WithAccurateLen
+	AccurateLen bool
+	// DisableLRU skip the LRU list maintenance, the cache is unbounded and Capacity is ignored,
+	// items are only removed by TTL or explicitly. Get of a valid item only takes the read lock.
+	// see WithLRU
+	DisableLRU bool
+	// LRUMode the way Get maintains the LRU order, default is LRUExact. see WithLRUMode
+	LRUMode LRUMode
+	// Frozen freeze the cache after configured, it is read-only. see Cache.Freeze
+	Frozen bool
+	// MissFilterSize expected number of the missing keys in the miss filter, > 0 to enable. see WithMissFilter
+	MissFilterSize int
+	// MissFilterFPRate false positive rate of the miss filter
+	MissFilterFPRate float64
+	// MissFilterRotate interval for rotate the miss filter
+	MissFilterRotate time.Duration
+	// MissFilter custom filter of the missing keys, replaces the builtin one. see WithCustomMissFilter
+	MissFilter MissFilter
+	// MaxKeyLen maximum length of the keys to write, <= 0 for unlimited. see WithMaxKeyLen
+	MaxKeyLen int
+	// MaxValueBytes maximum approximate size of the values to write, <= 0 for unlimited. see WithMaxValueBytes
+	MaxValueBytes int
+	// MaxListLen maximum length of the lists, <= 0 for unlimited. see WithMaxListLen and Cache.LPush
+	MaxListLen int
+	// CopyOnRead return the deep copies of the values on Get. see WithCopyOnRead
+	CopyOnRead bool
+	// CopyFn custom copy func for CopyOnRead, default use the serializer. see WithCopyFn
+	CopyFn CopyFn
 	// Serializer name, use for save/load file.
 	//
 	// default is: "json". see JSONSerializer
 	Serializer string
+	// SerializerObj custom serializer instance, will be used instead of Serializer name.
+	// see WithSerializerObj
+	SerializerObj Serializer
 	// OnEvicted callback function on item evicted
 	OnEvicted func(key string, value any)
+	// OnRemoved callback function on item removed, with the reason of removal.
+	OnRemoved func(key string, value any, reason RemoveReason)
+	// OnExpired callback function on item removed by expired. see WithOnExpiredFn
+	OnExpired func(key string, value any, expiredAt time.Time)
+	// AsyncCallbacks call the OnEvicted, OnRemoved and OnExpired callbacks in a background goroutine,
+	// outside the cache lock. see WithAsyncCallbacks
+	AsyncCallbacks bool
+	// CallbackQueueSize the buffer size of the async callbacks queue. default is 1024
+	CallbackQueueSize int
+	// KeyFunc validate and normalize the keys passed to the cache methods. see WithKeyFunc
+	KeyFunc func(key string) (string, error)
+	// OnSet callback function after an item is written, include the data structure operations,
+	// Touch and Import. It is called without holding the lock, the key contains the namespace prefix.
+	OnSet func(key string, value any, ttl time.Duration)
+	// Generation initial cache generation. items loaded from file with an older
+	// generation are treated as misses. see Cache.BumpGeneration
+	Generation uint64
+	// SaveSync call fsync on the snapshot file before rename it on SaveFile
+	SaveSync bool
+	// PersistProvider the storage of the snapshots, nil to use the local files. see WithPersistProvider
+	PersistProvider PersistProvider
+	// SaveMinTTL items with remaining TTL less than it will be skipped on SaveFile.
+	// items without expiration are always saved.
+	SaveMinTTL time.Duration
+	// SaveFilter skip the items it returns false on save the snapshots. see WithSaveFilter
+	SaveFilter func(key string, it Item) bool
+	// AutoSaveFile snapshot file for auto-save. see WithAutoSave
+	AutoSaveFile string
+	// AutoSaveInterval interval for auto-save, <= 0 to disable
+	AutoSaveInterval time.Duration
+	// KeyHashThreshold keys longer than it will be replaced by a fixed-size
+	// xxhash fingerprint. <= 0 to disable. see WithKeyHashing
+	KeyHashThreshold int
+	// KeyHashCheck store the original key for hashed keys and verify it on read,
+	// a fingerprint collision will be treated as a miss.
+	KeyHashCheck bool
+	// LockTimeout max wait time for acquire the internal lock. <= 0 for wait forever.
+	//
+	// On timeout: read operations return a miss, SetE, SaveFile, LoadFile return ErrBusy.
+	LockTimeout time.Duration
+	// AOFFile append-only log file, every write operation will be appended to it.
+	// see WithAOF and Cache.LoadAOF
+	AOFFile string
+	// AOFRewriteRatio rewrite the AOF in the background when its size exceeds the ratio of
+	// the size after the last rewrite, <= 0 to disable. see WithAOFRewrite
+	AOFRewriteRatio float64
+	// AOFRewriteMinSize the min size of the AOF in bytes to trigger the automatic rewrite
+	AOFRewriteMinSize int64
+	// Scheduler for run the periodic jobs. default start a goroutine for each job.
+	Scheduler Scheduler
+	// SaveCompression compression for snapshot file on SaveFile. eg: CompressGzip
+	//
+	// LoadFile will auto-detect compressed file.
+	SaveCompression Compression
+	// SaveEncryptKey AES key for encrypt the snapshot file with AES-GCM.
+	// length must be 16, 24 or 32 bytes. see WithSaveEncryption
+	SaveEncryptKey []byte
+	// Indexes secondary index functions, key is index name. see WithIndex
+	Indexes map[string]IndexFn
+	// RefreshAhead when a Get hits an item past the fraction of its TTL, refresh it
+	// asynchronously by RefreshLoader. range (0, 1), 0 to disable. see WithRefreshAhead
+	RefreshAhead float64
+	// StaleGrace keep the expired items for the grace window, Get returns the stale value
+	// and reloads it in the background by RefreshLoader. see WithStaleWhileRevalidate
+	StaleGrace time.Duration
+	// Store backend store for write-through and read-through. see WithStore
+	Store Store
+	// StoreTTL TTL for the items loaded from Store, <= 0 for never expire
+	StoreTTL time.Duration
+	// JanitorInterval interval for the janitor to remove the expired items and evaluate
+	// the alerts, <= 0 to disable. see WithJanitor
+	JanitorInterval time.Duration
+	// AlertFn called by the janitor when an alert is triggered. see WithAlert
+	AlertFn func(a Alert)
+	// AlertRules the thresholds of the alerts. see WithAlertRules
+	AlertRules AlertRules
+	// WriteBehindInterval interval for flush the queued writes to Store, > 0 to enable
+	// write-behind mode. see WithWriteBehind
+	WriteBehindInterval time.Duration
+	// WriteBehindMaxQueue flush the queued writes in background when the queue size reaches it. <= 0 to disable
+	WriteBehindMaxQueue int
+	// OnStoreErr callback on write the item to Store failed in write-behind mode
+	OnStoreErr func(key string, err error)
+	// Loader read-through loader, Get will load the missing items by it. see WithLoader
+	Loader ReadLoaderFn
+	// RefreshLoader loader for reload items in the background, by refresh-ahead or stale-while-revalidate
+	RefreshLoader LoaderFn
+	// Executor run the background reload tasks of RefreshLoader, nil to start a goroutine
+	// for each task. see WithExecutor
+	Executor Executor
+	// KeyLocker the locker for LockKey, nil to use the builtin striped mutexes. see WithKeyLocker
+	KeyLocker KeyLocker
+	// FlightGroup deduplicate the concurrent loads, nil to use the builtin one. see WithFlightGroup
+	FlightGroup FlightGroup
+	// KeyTracker track the keys read by Get and its variants. see WithKeyTracker
+	KeyTracker KeyTracker
+}
+
+// IndexFn extract the index value from cached value, return empty string to skip indexing.
+type IndexFn func(val any) string
+
+// validate 检查选项是否有效. see NewE
+func (o *Options) validate() error {
+	if o.Capacity <= 0 && !o.DisableLRU {
+		return fmt.Errorf("lcache: invalid capacity %d, must be greater than 0", o.Capacity)
+	}
+	if o.SerializerObj == nil && !HasSerializer(o.Serializer) {
+		return fmt.Errorf("lcache: not registered serializer name %q", o.Serializer)
+	}
+	return nil
 }
 
 // OptionFn option config func
@@ -267,10 +534,68 @@ func WithCapacity(capacity int) OptionFn {
 	}
 }
 
+// WithOverflowPolicy set the policy for writing a new item when the cache is full(Capacity or MaxCost).
+//
+// With RejectNew, the expired items are removed first to make room, then the new item
+// is rejected if still full: SetE, SetX and Update return ErrCacheFull, the other writes
+// are skipped. Existing keys can be updated unless the new cost exceeds MaxCost. With
+// Store configured, the value is already written to the Store when SetE returns ErrCacheFull.
+//
+// The O(N) scan for the expired items is skipped until the earliest expiration since the
+// last scan, so the writes to a full cache without expired items are rejected quickly.
+func WithOverflowPolicy(p OverflowPolicy) OptionFn {
+	return func(o *Options) {
+		o.Overflow = p
+	}
+}
+
+// WithMaxCost set the maximum total cost of the items. see WithCost
+func WithMaxCost(maxCost int64) OptionFn {
+	return func(o *Options) {
+		o.MaxCost = maxCost
+	}
+}
+
+// WithLRU enable or disable the LRU eviction, default is enabled.
+//
+// Disable it for the TTL-only usage: saves the per-entry list memory and Get no longer
+// needs the write lock. OldestKey and NewestKey always return false when disabled.
+func WithLRU(enable bool) OptionFn {
+	return func(o *Options) {
+		o.DisableLRU = !enable
+	}
+}
+
+// WithLRUMode set the way Get maintains the LRU order.
+//
+// With LRUExact, each Get moves the item to the front of the LRU list, it takes the write
+// lock and the concurrent reads contend on it. With LRUClock, Get of a valid item only takes
+// the read lock and marks the item as referenced, a referenced item at the tail gets a second
+// chance instead of being evicted. The eviction order is an approximation of LRU.
+//
+// The default is LRUExact. Compare them for the workload by the BenchmarkCompare_* benchmarks
+// on the target machine before switching to LRUClock.
+func WithLRUMode(m LRUMode) OptionFn {
+	return func(o *Options) {
+		o.LRUMode = m
+	}
+}
+
+// WithFrozen freeze the cache after configured. eg: re-configure a warmed cache
+//
+//	c.Configure(lcache.WithFrozen())
+//
+// see Cache.Freeze
+func WithFrozen() OptionFn {
+	return func(o *Options) {
+		o.Frozen = true
+	}
+}
+
 // WithSerializer specify serializer name. eg: "json", "gob"
 func WithSerializer(serializer string) OptionFn {
 	// check serializer name
-	if _, ok := serializers[serializer]; !ok {
+	if !HasSerializer(serializer) {
 		panic("not registered serializer name: " + serializer)
 	}
 
@@ -279,9 +604,503 @@ func WithSerializer(serializer string) OptionFn {
 	}
 }
 
+// WithMissFilter maintain a Bloom filter of the keys that the loader(or Store) returned ErrNotFound,
+// the repeated misses of these keys return ErrNotFound(or a miss for Get) without calling the loader.
+// Protects the backend from cache penetration by the garbage keys.
+//
+//   - size: expected number of the missing keys in a rotation, the filter is rotated when reached.
+//   - fpRate: false positive rate, eg: 0.01. The existing keys may be reported as missing at this rate.
+//   - rotate: the filter is rotated on every interval, a key is remembered for one to two intervals.
+//     <= 0 to rotate only when full.
+//
+// Applies to Get, GetCtx and GetOrLoad. NOTE: a key created in the backend is still reported as
+// missing until rotated, call Set for it or ResetMissFilter.
+func WithMissFilter(size int, fpRate float64, rotate time.Duration) OptionFn {
+	if fpRate <= 0 || fpRate >= 1 {
+		panic("miss filter false positive rate must be in range (0, 1)")
+	}
+
+	return func(o *Options) {
+		o.MissFilterSize = size
+		o.MissFilterFPRate = fpRate
+		o.MissFilterRotate = rotate
+	}
+}
+
+// WithCustomMissFilter use a custom filter of the missing keys instead of the builtin
+// rotated Bloom filter, it works the same as WithMissFilter. eg: a bloom.Filter loaded
+// from file, so the misses are remembered across restarts.
+//
+//	f := bloom.New(100000, 0.01)
+//	c := lcache.New(lcache.WithCustomMissFilter(f.AsMissFilter()))
+func WithCustomMissFilter(f MissFilter) OptionFn {
+	return func(o *Options) {
+		o.MissFilter = f
+	}
+}
+
+// WithMaxListLen set the maximum length of the lists operated by LPush and RPush,
+// the exceeded elements are removed from the other end. see Cache.LPush
+func WithMaxListLen(maxLen int) OptionFn {
+	return func(o *Options) {
+		o.MaxListLen = maxLen
+	}
+}
+
+// WithCopyOnRead return the deep copies of the values on Get, so the callers mutating
+// the returned map/slice do not corrupt the cached values.
+//
+// The values are copied by DeepCopy, the types are kept. Use WithCopyFn for a custom copy
+// func. The immutable values(eg: string, number) are returned directly.
+//
+// Applies to Get, GetState, Peek, GetStale, MGet and the loaded values. If the CopyFn fails,
+// the reads return a miss and the loading methods(eg: GetOrLoad) return ErrCopy, the shared
+// value is never returned.
+func WithCopyOnRead(enable bool) OptionFn {
+	return func(o *Options) {
+		o.CopyOnRead = enable
+	}
+}
+
+// WithCopyFn enable copy-on-read with a custom deep copy func. see WithCopyOnRead
+func WithCopyFn(fn CopyFn) OptionFn {
+	return func(o *Options) {
+		o.CopyOnRead = true
+		o.CopyFn = fn
+	}
+}
+
+// WithSerializerObj use a private serializer instance, not need register it by SetSerializer.
+//
+// The snapshot file header records the registered name of the serializer instance, see SetSerializer.
+// If it is not registered, records the type name of it, eg: "*lcache.wrappedSerializer"
+func WithSerializerObj(s Serializer) OptionFn {
+	return func(o *Options) {
+		o.SerializerObj = s
+	}
+}
+
+// WithSaveSync set whether to fsync the snapshot file on SaveFile
+func WithSaveSync(sync bool) OptionFn {
+	return func(o *Options) {
+		o.SaveSync = sync
+	}
+}
+
+// WithPersistProvider set the storage of the snapshots for SaveFile, LoadFile and the auto-save.
+// If p implements OpAppender, the write operations are also appended to it. see PersistProvider
+//
+//	c := lcache.New(lcache.WithPersistProvider(s3Provider), lcache.WithAutoSave("cache/users.snap", time.Minute))
+func WithPersistProvider(p PersistProvider) OptionFn {
+	return func(o *Options) {
+		o.PersistProvider = p
+	}
+}
+
+// WithSavePolicy set the min remaining TTL of the items to be saved on SaveFile,
+// items about to expire anyway will be skipped to shrink the snapshot.
+func WithSavePolicy(minRemainingTTL time.Duration) OptionFn {
+	return func(o *Options) {
+		o.SaveMinTTL = minRemainingTTL
+	}
+}
+
+// WithSaveFilter set the predicate of the items to be saved on SaveFile, SaveDir, MarshalJSON
+// and the auto-save, the items it returns false are skipped. eg: exclude the sensitive or
+// cheap-to-recompute items from the snapshots. The key contains the namespace prefix.
+//
+//	c := lcache.New(lcache.WithSaveFilter(func(key string, it lcache.Item) bool {
+//		return !strings.HasPrefix(key, "session:")
+//	}))
+func WithSaveFilter(keep func(key string, it Item) bool) OptionFn {
+	return func(o *Options) {
+		o.SaveFilter = keep
+	}
+}
+
+// WithAutoSave start a background goroutine to save the cache to filename on every interval.
+//
+// Use the Stats() to get the last save time and error. interval <= 0 to stop auto-save.
+func WithAutoSave(filename string, interval time.Duration) OptionFn {
+	return func(o *Options) {
+		o.AutoSaveFile = filename
+		o.AutoSaveInterval = interval
+	}
+}
+
+// WithJanitor start a background task to remove the expired items on every interval,
+// and evaluate the alerts if configured. interval <= 0 to stop it. see WithAlert
+func WithJanitor(interval time.Duration) OptionFn {
+	return func(o *Options) {
+		o.JanitorInterval = interval
+	}
+}
+
+// WithAlert set the alert callback, it is called by the janitor when the hit ratio drops
+// below the threshold, the eviction rate spikes, or the memory budget is exceeded.
+//
+// An alert is triggered once when its condition becomes true, and can be triggered again
+// after recovered. Uses DefaultAlertRules if no rules set, and starts the janitor with
+// 1 minute interval if not started. see WithAlertRules, WithJanitor
+//
+// Usage:
+//
+//	c := lcache.New(lcache.WithAlert(func(a lcache.Alert) {
+//		log.Println(a)
+//	}))
+func WithAlert(fn func(a Alert)) OptionFn {
+	return func(o *Options) {
+		o.AlertFn = fn
+		if o.AlertRules == (AlertRules{}) {
+			o.AlertRules = DefaultAlertRules
+		}
+		if o.JanitorInterval <= 0 {
+			o.JanitorInterval = time.Minute
+		}
+	}
+}
+
+// WithAlertRules set the thresholds of the alerts. see WithAlert
+func WithAlertRules(rules AlertRules) OptionFn {
+	return func(o *Options) {
+		o.AlertRules = rules
+	}
+}
+
+// WithKeyHashing replace keys longer than threshold with a fixed-size fingerprint.
+//
+// Useful for caches keyed by URLs or SQL statements. NOTE: Keys() will return the fingerprints.
+func WithKeyHashing(threshold int) OptionFn {
+	return func(o *Options) {
+		o.KeyHashThreshold = threshold
+	}
+}
+
+// WithKeyHashCheck enable collision check for hashed keys. see WithKeyHashing
+func WithKeyHashCheck(check bool) OptionFn {
+	return func(o *Options) {
+		o.KeyHashCheck = check
+	}
+}
+
+// WithLockTimeout set the max wait time for acquire the internal lock,
+// make operations fail fast with ErrBusy instead of hang indefinitely.
+//
+// The methods without an error result report a miss or do nothing on timeout,
+// eg: Get returns false, Set and Delete drop the write. The timeouts are counted
+// in Stats.LockTimeouts, use the E variants(eg: SetE, DeleteE) to get ErrBusy.
+func WithLockTimeout(timeout time.Duration) OptionFn {
+	return func(o *Options) {
+		o.LockTimeout = timeout
+	}
+}
+
+// WithAOF enable the append-only log, every Set/Delete will be appended to filename.
+//
+// Use Cache.LoadAOF to replay it on startup.
+func WithAOF(filename string) OptionFn {
+	return func(o *Options) {
+		o.AOFFile = filename
+	}
+}
+
+// WithAOFRewrite enable the automatic rewrite of the AOF, it is rewritten by Cache.CompactAOF
+// in the background when the size exceeds ratio * the size after the last rewrite(or on open),
+// and is at least minSize bytes. eg: WithAOFRewrite(2, 64<<20)
+func WithAOFRewrite(ratio float64, minSize int64) OptionFn {
+	return func(o *Options) {
+		o.AOFRewriteRatio = ratio
+		o.AOFRewriteMinSize = minSize
+	}
+}
+
+// WithScheduler set the scheduler for periodic jobs, can be shared by multiple caches.
+//
+// Usage:
+//
+//	sched := lcache.NewSharedScheduler()
+//	c1 := lcache.New(lcache.WithScheduler(sched), lcache.WithAutoSave("c1.json", time.Minute))
+//	c2 := lcache.New(lcache.WithScheduler(sched), lcache.WithAutoSave("c2.json", time.Minute))
+func WithScheduler(s Scheduler) OptionFn {
+	return func(o *Options) {
+		o.Scheduler = s
+	}
+}
+
+// WithSaveCompression set compression for snapshot file. eg: CompressGzip
+func WithSaveCompression(comp Compression) OptionFn {
+	return func(o *Options) {
+		o.SaveCompression = comp
+	}
+}
+
+// WithSaveEncryption encrypt the snapshot file with AES-GCM on SaveFile, and decrypt it on LoadFile.
+//
+// The key length must be 16, 24 or 32 bytes to select AES-128, AES-192, or AES-256.
+func WithSaveEncryption(key []byte) OptionFn {
+	if _, err := aes.NewCipher(key); err != nil {
+		panic("invalid encryption key: " + err.Error())
+	}
+
+	return func(o *Options) {
+		o.SaveEncryptKey = key
+	}
+}
+
+// WithIndex add a secondary index on the cached values, maintained on Set/Delete.
+//
+// Usage:
+//
+//	c := lcache.New(lcache.WithIndex("byUser", func(val any) string {
+//		if o, ok := val.(*Order); ok {
+//			return strconv.Itoa(o.UserID)
+//		}
+//		return ""
+//	}))
+//
+//	orders := c.GetByIndex("byUser", "42")
+func WithIndex(name string, fn IndexFn) OptionFn {
+	return func(o *Options) {
+		if o.Indexes == nil {
+			o.Indexes = make(map[string]IndexFn)
+		}
+		o.Indexes[name] = fn
+	}
+}
+
+// WithOnRemovedFn set cache item removed callback function, with the reason of removal.
+func WithOnRemovedFn(fn func(key string, value any, reason RemoveReason)) OptionFn {
+	return func(o *Options) {
+		o.OnRemoved = fn
+	}
+}
+
+// WithOnExpiredFn set the callback function on item removed by expired, eg: archive the expired
+// payloads. It is called with the value and the expiration time when the expired item is removed
+// by the janitor, PruneExpired, a read, an overwrite or the capacity limit. The live items evicted
+// by the capacity limit are not reported.
+//
+// NOTE: expiredAt is zero time for the items invalidated by BumpGeneration
+//
+// Usage:
+//
+//	c := lcache.New(lcache.WithJanitor(time.Minute), lcache.WithOnExpiredFn(func(key string, val any, expiredAt time.Time) {
+//		archive.Put(key, val, expiredAt)
+//	}))
+func WithOnExpiredFn(fn func(key string, value any, expiredAt time.Time)) OptionFn {
+	return func(o *Options) {
+		o.OnExpired = fn
+	}
+}
+
+// WithAsyncCallbacks call the OnEvicted, OnRemoved and OnExpired callbacks(include the group
+// callbacks) in a background goroutine, in the order of removal.
+//
+// By default, the callbacks are called while holding the cache write lock, a slow
+// callback stalls all cache operations and calling the cache methods in it deadlocks.
+// With async callbacks, the removed items are queued in a buffer of queueSize(<= 0 for
+// the default 1024) and the callbacks can safely call the cache methods. Close waits
+// for the queued callbacks.
+//
+// The queue is never waited under the cache lock: when it is full, the callbacks of the
+// removed item are dropped and counted in Stats.CallbackDrops.
+func WithAsyncCallbacks(queueSize int) OptionFn {
+	return func(o *Options) {
+		o.AsyncCallbacks = true
+		o.CallbackQueueSize = queueSize
+	}
+}
+
+// WithKeyFunc set the func to validate and normalize every key passed to the cache methods,
+// eg: lowercase, trim, enforce max length. On error, SetE, DeleteE, GetCtx and GetOrLoad
+// return it, the reads treat the key as missing and the other writes are skipped.
+//
+// NOTE: fn must be idempotent, eg: fn(fn(key)) == fn(key), it may be applied more than once.
+//
+// Usage:
+//
+//	c := lcache.New(lcache.WithKeyFunc(func(key string) (string, error) {
+//		if len(key) > 128 {
+//			return "", errors.New("key too long")
+//		}
+//		return strings.ToLower(strings.TrimSpace(key)), nil
+//	}))
+func WithKeyFunc(fn func(key string) (string, error)) OptionFn {
+	return func(o *Options) {
+		o.KeyFunc = fn
+	}
+}
+
+// WithOnSetFn set the callback function after an item is written, eg: for replication,
+// audit logging or invalidation broadcasting.
+//
+// It is called after every successful write: Set, SetX, MSet, Group.Set, Update, the loaders,
+// Import, Touch and the data structure operations(eg: IncrBy, Append, HSet, LPush, SAdd, ZAdd,
+// PFAdd). The value is the whole new value, eg: the hash map of HSet. The ttl of the in-place
+// updates is the remaining TTL of the key, 0 for never expire. The deletes are not reported,
+// see WithOnRemovedFn.
+func WithOnSetFn(fn func(key string, value any, ttl time.Duration)) OptionFn {
+	return func(o *Options) {
+		o.OnSet = fn
+	}
+}
+
 // WithOnEvictFn set cache item evicted callback function
 func WithOnEvictFn(fn func(key string, value any)) OptionFn {
 	return func(o *Options) {
 		o.OnEvicted = fn
 	}
 }
+
+// WithStore set the backend store, make the cache as a caching layer in front of it.
+//
+// Set, MSet write through to the store, Delete, MDelete delete from the store, and Get,
+// GetCtx load the missing items from the store. see WithStoreTTL
+//
+// The store keys contain the namespace prefix. see Cache.Namespace
+func WithStore(s Store) OptionFn {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// WithStoreTTL set the TTL for the items loaded from Store. default is 0, never expire.
+func WithStoreTTL(ttl time.Duration) OptionFn {
+	return func(o *Options) {
+		o.StoreTTL = ttl
+	}
+}
+
+// WithWriteBehind enable the write-behind mode for the Store, should be used with WithStore.
+//
+// The writes and deletes are queued and flushed to the store in batches on every interval,
+// or when the queue size reaches maxQueue(<= 0 to disable). onErr is called for each failed item.
+//
+// Call Cache.Flush to flush the queue manually, Cache.Close also flushes it.
+func WithWriteBehind(interval time.Duration, maxQueue int, onErr func(key string, err error)) OptionFn {
+	return func(o *Options) {
+		o.WriteBehindInterval = interval
+		o.WriteBehindMaxQueue = maxQueue
+		o.OnStoreErr = onErr
+	}
+}
+
+// WithLoader set the read-through loader, Get and GetCtx will load the missing items by it,
+// and store them with the returned TTL. Concurrent misses for the same key trigger only one loader call.
+//
+// Usage:
+//
+//	c := lcache.New(lcache.WithLoader(func(ctx context.Context, key string) (any, time.Duration, error) {
+//		user, err := db.FindUser(ctx, key)
+//		return user, time.Minute, err
+//	}))
+//
+//	user, err := c.GetCtx(ctx, "42")
+func WithLoader(fn ReadLoaderFn) OptionFn {
+	return func(o *Options) {
+		o.Loader = fn
+	}
+}
+
+// WithRefreshAhead refresh the hot items before they expire.
+//
+// When a Get hits an item past the fraction of its TTL, the cached value is returned
+// immediately, and the item is reloaded by loader in the background with the same TTL.
+//
+// Usage:
+//
+//	// refresh the item when 80% of its TTL has passed
+//	c := lcache.New(lcache.WithRefreshAhead(0.8, loadUser))
+func WithRefreshAhead(fraction float64, loader LoaderFn) OptionFn {
+	if fraction <= 0 || fraction >= 1 {
+		panic("refresh-ahead fraction must be in range (0, 1)")
+	}
+	if loader == nil {
+		panic("refresh-ahead loader is required")
+	}
+
+	return func(o *Options) {
+		o.RefreshAhead = fraction
+		o.RefreshLoader = loader
+	}
+}
+
+// WithStaleWhileRevalidate keep the expired items for the grace window.
+//
+// Get on a stale item returns the stale value immediately(see Cache.GetState), and the
+// item is reloaded by loader in the background with its original TTL. This keeps latency
+// flat when the origin is slow.
+func WithStaleWhileRevalidate(grace time.Duration, loader LoaderFn) OptionFn {
+	if grace <= 0 {
+		panic("stale grace window must be greater than 0")
+	}
+	if loader == nil {
+		panic("stale-while-revalidate loader is required")
+	}
+
+	return func(o *Options) {
+		o.StaleGrace = grace
+		o.RefreshLoader = loader
+	}
+}
+
+// WithExecutor set the executor to run the background reload tasks of refresh-ahead
+// and stale-while-revalidate, for limit the concurrent reloads. The task is skipped
+// if the executor rejects it, and retried on the next Get.
+//
+// Usage:
+//
+//	pool := lpool.New(lpool.WithWorkers(4))
+//	c := lcache.New(lcache.WithRefreshAhead(0.8, loadUser), lcache.WithExecutor(pool))
+//
+// The task is submitted after the cache lock is released, a blocking Submit only delays
+// the Get call that triggered the reload. see Executor
+func WithExecutor(e Executor) OptionFn {
+	return func(o *Options) {
+		o.Executor = e
+	}
+}
+
+// WithKeyLocker set the locker for LockKey. eg: llock.Keyed for the exact per-key locks
+// with the TTL-based auto-release.
+//
+//	c := lcache.New(lcache.WithKeyLocker(llock.New(llock.WithTTL(10*time.Second))))
+func WithKeyLocker(l KeyLocker) OptionFn {
+	return func(o *Options) {
+		o.KeyLocker = l
+	}
+}
+
+// WithFlightGroup set the group to deduplicate the concurrent loads of GetOrLoad, the
+// read-through loader and the refresh. eg: share a *sflight.Group[any] with other caches
+// or the code outside the cache, the loads of the same key are executed once.
+//
+// The keys passed to the group are prefixed by the namespace. NOTE: a result cached by the
+// group(eg: sflight.WithTTL) is returned without loading, the refresh is skipped in this period.
+//
+//	g := sflight.New[any]()
+//	c := lcache.New(lcache.WithFlightGroup(g))
+func WithFlightGroup(g FlightGroup) OptionFn {
+	return func(o *Options) {
+		o.FlightGroup = g
+	}
+}
+
+// WithKeyTracker set the tracker of the keys read by Get and its variants, include
+// the hits and misses. The keys passed to the tracker are prefixed by the namespace.
+//
+//	tr := topk.New(100, topk.WithDecay(time.Minute, 0.5))
+//	c := lcache.New(lcache.WithKeyTracker(tr))
+//	hot := tr.Top(10)
+func WithKeyTracker(t KeyTracker) OptionFn {
+	return func(o *Options) {
+		o.KeyTracker = t
+	}
+}
+
+// WithGeneration set the initial cache generation. eg: use config version
+func WithGeneration(gen uint64) OptionFn {
+	return func(o *Options) {
+		o.Generation = gen
+	}
+}
@@ -1,6 +1,8 @@
 package lcache_test
 
 import (
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -112,13 +114,71 @@ func TestOptions(t *testing.T) {
 	lcache.Reset()
 }
 
+func TestSetStd(t *testing.T) {
+	defer lcache.Reset()
+	c := lcache.New(lcache.WithCapacity(10))
+	prev := lcache.Std()
+	assert.True(t, lcache.SetStd(c) == prev)
+	assert.True(t, lcache.Std() == c)
+
+	lcache.Set("key1", "val1", 0)
+	assert.Eq(t, "val1", c.Val("key1"))
+	assert.Panics(t, func() {
+		lcache.SetStd(nil)
+	})
+
+	// replace while in use
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			lcache.Set("key2", i, 0)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			lcache.SetStd(lcache.New())
+		}
+	}()
+	wg.Wait()
+}
+
+func TestSerializers(t *testing.T) {
+	assert.True(t, lcache.HasSerializer("json"))
+	assert.False(t, lcache.HasSerializer("json4"))
+	assert.Contains(t, lcache.Serializers(), "gob")
+
+	// concurrent register and lookup
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			lcache.SetSerializer("json4", lcache.JSONSerializer{})
+		}()
+		go func() {
+			defer wg.Done()
+			lcache.New(lcache.WithSerializer("json"))
+			lcache.Serializers()
+		}()
+	}
+	wg.Wait()
+
+	s, ok := lcache.GetSerializer("json4")
+	assert.True(t, ok)
+	assert.NotNil(t, s)
+	lcache.SetSerializer("json4", nil)
+}
+
 func TestSaveFileAndLoadFile(t *testing.T) {
 	lcache.Configure(lcache.WithCapacity(50))
 	lcache.Clear()
 	lcache.Set("key1", "value1", 10*time.Second)
 	lcache.Set("key2", "value2", 10*time.Second)
 
-	filename := "testdata/test_cache.json"
+	filename := filepath.Join(t.TempDir(), "test_cache.json")
 	err := lcache.SaveFile(filename)
 	assert.NoError(t, err)
 
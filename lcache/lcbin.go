@@ -0,0 +1,202 @@
+package lcache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// LCBinSerializer compact binary snapshot serializer, registered as "lcbin".
+//
+// Each item is stored as a record:
+//
+//	key len(uvarint) | key | exp(varint) | gen(uvarint) | original key len(uvarint) | original key |
+//	type name len(uvarint) | type name | value len(uvarint) | value
+//
+// The value is encoded by the Value serializer(default is JSON). The records can be
+// read one by one, so LoadFile loads lcbin snapshots in streaming. They are written from
+// the least to the most recently used, so the LRU order is restored on load, but the hit
+// counts of the items are not stored.
+//
+// NOTE: it only supports encode/decode the cache snapshot data.
+type LCBinSerializer struct {
+	// Value serializer for encode item value. default is JSONSerializer
+	Value Serializer
+}
+
+// errLCBinData the data is not a cache snapshot
+var errLCBinData = errors.New("lcache: lcbin serializer only supports cache snapshot")
+
+func (s LCBinSerializer) valueSerializer() Serializer {
+	if s.Value != nil {
+		return s.Value
+	}
+	return JSONSerializer{}
+}
+
+// Decode implements Serializer
+func (s LCBinSerializer) Decode(data []byte, dest any) error {
+	return s.DecodeFrom(bytes.NewReader(data), dest)
+}
+
+// Encode implements Serializer
+func (s LCBinSerializer) Encode(data any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.EncodeTo(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeFrom implements Serializer
+func (s LCBinSerializer) DecodeFrom(r io.Reader, dest any) error {
+	snap, ok := dest.(*snapshot)
+	if !ok {
+		return errLCBinData
+	}
+
+	*snap = snapshot{Items: make(map[string]*Item)}
+	return s.stream(r, func(gen uint64) { snap.Gen = gen }, snap.add)
+}
+
+// EncodeTo implements Serializer
+func (s LCBinSerializer) EncodeTo(w io.Writer, src any) error {
+	snap, ok := src.(*snapshot)
+	if !ok {
+		return errLCBinData
+	}
+
+	bw := bufio.NewWriter(w)
+	buf := binary.AppendUvarint(nil, snap.Gen)
+	if _, err := bw.Write(buf); err != nil {
+		return err
+	}
+
+	vs := s.valueSerializer()
+	for _, key := range snap.orderedKeys() {
+		it := snap.Items[key]
+		val, err := vs.Encode(it.Val)
+		if err != nil {
+			return err
+		}
+
+		buf = appendLCBinBytes(buf[:0], key)
+		buf = binary.AppendVarint(buf, it.Exp)
+		buf = binary.AppendUvarint(buf, it.Gen)
+		buf = appendLCBinBytes(buf, it.Key)
+		buf = appendLCBinBytes(buf, it.Typ)
+		buf = appendLCBinBytes(buf, string(val))
+		if _, err = bw.Write(buf); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// stream 逐条读取记录
+func (s LCBinSerializer) stream(r io.Reader, onGen func(gen uint64), onItem func(key string, it *Item) error) error {
+	br := bufio.NewReader(r)
+	gen, err := binary.ReadUvarint(br)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+	onGen(gen)
+
+	vs := s.valueSerializer()
+	for {
+		key, err := readLCBinBytes(br)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		it := new(Item)
+		if it.Exp, err = binary.ReadVarint(br); err != nil {
+			return noEOF(err)
+		}
+		if it.Gen, err = binary.ReadUvarint(br); err != nil {
+			return noEOF(err)
+		}
+
+		origKey, err := readLCBinBytes(br)
+		if err != nil {
+			return noEOF(err)
+		}
+		it.Key = string(origKey)
+
+		typ, err := readLCBinBytes(br)
+		if err != nil {
+			return noEOF(err)
+		}
+		it.Typ = string(typ)
+
+		val, err := readLCBinBytes(br)
+		if err != nil {
+			return noEOF(err)
+		}
+		if err = vs.Decode(val, &it.Val); err != nil {
+			return err
+		}
+		if err = onItem(string(key), it); err != nil {
+			return err
+		}
+	}
+}
+
+func appendLCBinBytes(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// lcbinChunk 超过它的字段按实际读取到的数据分配内存，避免损坏的长度导致分配大量内存
+const lcbinChunk = 64 << 10
+
+// readLCBinBytes 读取一个字段. 长度超出剩余数据时返回 ErrBadSnapshot
+func readLCBinBytes(br *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+
+	if n <= lcbinChunk {
+		bs := make([]byte, n)
+		if _, err = io.ReadFull(br, bs); err != nil {
+			return nil, lcbinCorrupt(err, n)
+		}
+		return bs, nil
+	}
+
+	if n > math.MaxInt32 {
+		return nil, fmt.Errorf("%w: invalid field length %d", ErrBadSnapshot, n)
+	}
+	var buf bytes.Buffer
+	if _, err = io.CopyN(&buf, br, int64(n)); err != nil {
+		return nil, lcbinCorrupt(err, n)
+	}
+	return buf.Bytes(), nil
+}
+
+// lcbinCorrupt 字段数据不完整时返回 ErrBadSnapshot
+func lcbinCorrupt(err error, n uint64) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: field length %d exceeds the remaining data", ErrBadSnapshot, n)
+	}
+	return err
+}
+
+// noEOF 记录中途遇到 EOF 表示数据不完整
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,46 @@
+package lcache_test
+
+import (
+	"encoding/binary"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestLCBinSerializer(t *testing.T) {
+	c := lcache.New(lcache.WithSerializer("lcbin"), lcache.WithCapacity(2000))
+	for i := 0; i < 1000; i++ {
+		c.Set("key"+strconv.Itoa(i), "value"+strconv.Itoa(i), time.Hour)
+	}
+	c.Set("map", map[string]any{"a": "b"}, 0)
+
+	filename := t.TempDir() + "/cache.lcbin"
+	assert.NoErr(t, c.SaveFile(filename))
+
+	c2 := lcache.New(lcache.WithSerializer("lcbin"), lcache.WithCapacity(2000))
+	assert.NoErr(t, c2.LoadFile(filename))
+	assert.Eq(t, 1001, c2.Len())
+	assert.Eq(t, "value999", c2.Val("key999"))
+	assert.Eq(t, map[string]any{"a": "b"}, c2.Val("map"))
+
+	// only support snapshot data
+	_, err := lcache.LCBinSerializer{}.Encode(map[string]any{"a": "b"})
+	assert.Err(t, err)
+}
+
+func TestLCBinSerializer_corrupt(t *testing.T) {
+	filename := t.TempDir() + "/cache.lcbin"
+	for _, n := range []uint64{100, 1 << 30, 1 << 40} {
+		// gen, key length and the truncated key
+		data := binary.AppendUvarint([]byte{0}, n)
+		data = append(data, "key1"...)
+		assert.NoErr(t, os.WriteFile(filename, data, 0644))
+
+		c := lcache.New(lcache.WithSerializer("lcbin"))
+		assert.ErrIs(t, c.LoadFile(filename), lcache.ErrBadSnapshot)
+	}
+}
@@ -0,0 +1,15 @@
+module github.com/gookit/ext/lcache/lcbson
+
+go 1.23
+
+require (
+	github.com/gookit/ext v0.0.0
+	go.mongodb.org/mongo-driver v1.17.10
+)
+
+require (
+	github.com/gookit/goutil v0.8.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+)
+
+replace github.com/gookit/ext => ../..
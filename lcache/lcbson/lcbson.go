@@ -0,0 +1,96 @@
+// Package lcbson provides a BSON serializer for lcache snapshot files,
+// so that cache dump files can be inspected and imported with MongoDB tooling.
+//
+// It is a separate module to avoid adding the mongo driver dependency to lcache.
+//
+// The struct fields use the `bson` tag, fallback to the `json` tag. The embedded documents
+// and arrays in the untyped values are decoded as map[string]any and []any like JSON,
+// so that the values of the types registered by lcache.RegisterType are restored on load.
+//
+// Usage:
+//
+//	lcache.SetSerializer("bson", lcbson.Serializer{})
+//	c := lcache.New(lcache.WithSerializer("bson"))
+package lcbson
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+)
+
+// Name of the serializer
+const Name = "bson"
+
+// registry 编解码使用的注册表. 结构体字段名回退使用 json tag，
+// 未知类型的文档和数组解码为 map[string]any 和 []any，与 JSON 一致
+var registry = newRegistry()
+
+func newRegistry() *bsoncodec.Registry {
+	reg := bson.NewRegistry()
+
+	sc, err := bsoncodec.NewStructCodec(bsoncodec.JSONFallbackStructTagParser)
+	if err != nil {
+		panic(err)
+	}
+	reg.RegisterKindEncoder(reflect.Struct, sc)
+	reg.RegisterKindDecoder(reflect.Struct, sc)
+
+	reg.RegisterTypeMapEntry(bson.TypeEmbeddedDocument, reflect.TypeOf(map[string]any{}))
+	reg.RegisterTypeMapEntry(bson.TypeArray, reflect.TypeOf([]any{}))
+	return reg
+}
+
+// Serializer BSON serializer, implements the lcache.Serializer
+type Serializer struct{}
+
+// Decode implements lcache.Serializer
+func (Serializer) Decode(data []byte, dest any) error {
+	dec, err := bson.NewDecoder(bsonrw.NewBSONDocumentReader(data))
+	if err != nil {
+		return err
+	}
+	if err = dec.SetRegistry(registry); err != nil {
+		return err
+	}
+	return dec.Decode(dest)
+}
+
+// Encode implements lcache.Serializer
+func (s Serializer) Encode(data any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.EncodeTo(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeFrom implements lcache.Serializer
+func (s Serializer) DecodeFrom(r io.Reader, dest any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return s.Decode(data, dest)
+}
+
+// EncodeTo implements lcache.Serializer
+func (Serializer) EncodeTo(w io.Writer, src any) error {
+	vw, err := bsonrw.NewBSONValueWriter(w)
+	if err != nil {
+		return err
+	}
+
+	enc, err := bson.NewEncoder(vw)
+	if err != nil {
+		return err
+	}
+	if err = enc.SetRegistry(registry); err != nil {
+		return err
+	}
+	return enc.Encode(src)
+}
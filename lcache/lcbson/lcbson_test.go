@@ -0,0 +1,94 @@
+package lcbson_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+
+	"github.com/gookit/ext/lcache/lcbson"
+)
+
+type item struct {
+	Val any   `bson:"v"`
+	Exp int64 `bson:"e"`
+}
+
+func TestSerializer(t *testing.T) {
+	s := lcbson.Serializer{}
+	src := map[string]item{"key1": {Val: "value1", Exp: 100}}
+
+	var buf bytes.Buffer
+	if err := s.EncodeTo(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	var dst map[string]item
+	if err := s.DecodeFrom(&buf, &dst); err != nil {
+		t.Fatal(err)
+	}
+	if dst["key1"].Val != "value1" || dst["key1"].Exp != 100 {
+		t.Fatalf("unexpected decode result: %+v", dst)
+	}
+
+	bs, err := s.Encode(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst = nil
+	if err = s.Decode(bs, &dst); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 1 {
+		t.Fatalf("unexpected decode result: %+v", dst)
+	}
+}
+
+type user struct {
+	ID   int      `json:"id"`
+	Name string   `json:"user_name"`
+	Tags []string `json:"tags"`
+}
+
+func TestSerializer_cache(t *testing.T) {
+	lcache.SetSerializer(lcbson.Name, lcbson.Serializer{})
+	lcache.RegisterType[user]("lcbson_user")
+
+	c := lcache.New(lcache.WithSerializer(lcbson.Name))
+	defer c.Close()
+	c.Set("user", user{ID: 1, Name: "inhere", Tags: []string{"a", "b"}}, 0)
+	c.Set("map", map[string]any{"k": "v"}, 0)
+	c.Set("list", []any{"a", "b"}, time.Minute)
+
+	file := filepath.Join(t.TempDir(), "cache.bson")
+	if err := c.SaveFile(file); err != nil {
+		t.Fatal(err)
+	}
+
+	c2 := lcache.New(lcache.WithSerializer(lcbson.Name))
+	defer c2.Close()
+	if err := c2.LoadFile(file); err != nil {
+		t.Fatal(err)
+	}
+
+	val, ok := c2.Get("user")
+	u, isUser := val.(user)
+	if !ok || !isUser || u.ID != 1 || u.Name != "inhere" || len(u.Tags) != 2 {
+		t.Fatalf("unexpected user value: %#v", val)
+	}
+
+	val, _ = c2.Get("map")
+	if m, ok := val.(map[string]any); !ok || m["k"] != "v" {
+		t.Fatalf("unexpected map value: %#v", val)
+	}
+
+	val, _ = c2.Get("list")
+	if l, ok := val.([]any); !ok || len(l) != 2 || l[1] != "b" {
+		t.Fatalf("unexpected list value: %#v", val)
+	}
+	if ttl, _ := c2.TTL("list"); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("unexpected list ttl: %v", ttl)
+	}
+}
@@ -0,0 +1,281 @@
+// Package lcdebug provides a debug HTTP endpoint /debug/lcache for the registered caches,
+// like /debug/pprof but for caches.
+//
+// It renders an HTML(or JSON with ?format=json) view of each cache: size, hit ratio,
+// hot keys and upcoming expirations, with buttons to delete keys or trigger a snapshot.
+//
+// Usage:
+//
+//	lcdebug.Register("users", usersCache, lcdebug.WithSaveFile("users.json"))
+//	lcdebug.RegisterDebug(mux)
+//	// open http://localhost:8080/debug/lcache/
+package lcdebug
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+)
+
+// Path the URL path prefix of the debug endpoint
+const Path = "/debug/lcache/"
+
+// topN 页面中显示的热点 key 和即将过期的 key 数量
+const topN = 20
+
+// Option for registered cache
+type Option func(e *entry)
+
+// WithSaveFile set the snapshot file of the cache, enable the snapshot button.
+func WithSaveFile(filename string) Option {
+	return func(e *entry) { e.saveFile = filename }
+}
+
+// entry 已注册的缓存
+type entry struct {
+	c        *lcache.Cache
+	saveFile string
+}
+
+// caches 已注册的缓存. name => entry
+var caches = struct {
+	sync.RWMutex
+	m map[string]*entry
+}{m: make(map[string]*entry)}
+
+// Register the cache with name to the debug endpoint, will replace the exists one.
+func Register(name string, c *lcache.Cache, opts ...Option) {
+	e := &entry{c: c}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	caches.Lock()
+	caches.m[name] = e
+	caches.Unlock()
+}
+
+// Unregister the cache by name
+func Unregister(name string) {
+	caches.Lock()
+	delete(caches.m, name)
+	caches.Unlock()
+}
+
+func getEntry(name string) *entry {
+	caches.RLock()
+	defer caches.RUnlock()
+	return caches.m[name]
+}
+
+// RegisterDebug register the debug handler on the mux, at Path. nil mux for http.DefaultServeMux
+func RegisterDebug(mux *http.ServeMux) {
+	if mux == nil {
+		mux = http.DefaultServeMux
+	}
+	mux.Handle(Path, Handler())
+}
+
+// Handler create the debug HTTP handler. it serves the requests under Path.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+Path+"{$}", serveIndex)
+	mux.HandleFunc("GET "+Path+"{name}", serveCache)
+	mux.HandleFunc("POST "+Path+"{name}/delete", serveDelete)
+	mux.HandleFunc("POST "+Path+"{name}/save", serveSave)
+	return mux
+}
+
+// cacheInfo 缓存的调试信息
+type cacheInfo struct {
+	Name        string           `json:"name"`
+	Len         int              `json:"len"`
+	ValidLen    int              `json:"valid_len"`
+	Hits        uint64           `json:"hits"`
+	Misses      uint64           `json:"misses"`
+	HitRatio    float64          `json:"hit_ratio"`
+	Generation  uint64           `json:"generation"`
+	LastSaveAt  *time.Time       `json:"last_save_at"`
+	LastSaveErr string           `json:"last_save_error,omitempty"`
+	CanSave     bool             `json:"can_save"`
+	HotKeys     []lcache.KeyHits `json:"hot_keys,omitempty"`
+	Expiring    []expiring       `json:"expiring,omitempty"`
+}
+
+// expiring 即将过期的 key
+type expiring struct {
+	Key string        `json:"key"`
+	TTL time.Duration `json:"ttl"`
+}
+
+func newInfo(name string, e *entry, detail bool) *cacheInfo {
+	st := e.c.Stats()
+	info := &cacheInfo{
+		Name:       name,
+		Len:        st.Len,
+		ValidLen:   st.ValidLen,
+		Hits:       st.Hits,
+		Misses:     st.Misses,
+		HitRatio:   st.HitRatio(),
+		Generation: st.Generation,
+		CanSave:    e.saveFile != "",
+	}
+	if !st.LastSaveAt.IsZero() {
+		info.LastSaveAt = &st.LastSaveAt
+	}
+	if st.LastSaveErr != nil {
+		info.LastSaveErr = st.LastSaveErr.Error()
+	}
+	if !detail {
+		return info
+	}
+
+	info.HotKeys = e.c.HotKeys(topN)
+	for _, key := range e.c.ExpiringWithin(time.Hour) {
+		if ttl, ok := e.c.TTL(key); ok {
+			info.Expiring = append(info.Expiring, expiring{Key: key, TTL: ttl})
+		}
+	}
+	sort.Slice(info.Expiring, func(i, j int) bool { return info.Expiring[i].TTL < info.Expiring[j].TTL })
+	if len(info.Expiring) > topN {
+		info.Expiring = info.Expiring[:topN]
+	}
+	return info
+}
+
+func serveIndex(w http.ResponseWriter, r *http.Request) {
+	caches.RLock()
+	list := make([]*cacheInfo, 0, len(caches.m))
+	for name, e := range caches.m {
+		list = append(list, newInfo(name, e, false))
+	}
+	caches.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	if isJSON(r) {
+		writeJSON(w, http.StatusOK, list)
+		return
+	}
+	render(w, indexTpl, list)
+}
+
+func serveCache(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	e := getEntry(name)
+	if e == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	info := newInfo(name, e, true)
+	if isJSON(r) {
+		writeJSON(w, http.StatusOK, info)
+		return
+	}
+	render(w, cacheTpl, info)
+}
+
+func serveDelete(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	e := getEntry(name)
+	if e == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	deleted := e.c.Delete(r.FormValue("key"))
+	if isJSON(r) {
+		writeJSON(w, http.StatusOK, map[string]bool{"deleted": deleted})
+		return
+	}
+	http.Redirect(w, r, Path+url.PathEscape(name), http.StatusSeeOther)
+}
+
+func serveSave(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	e := getEntry(name)
+	if e == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if e.saveFile == "" {
+		http.Error(w, "save file is not configured", http.StatusNotImplemented)
+		return
+	}
+
+	if err := e.c.SaveFile(e.saveFile); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if isJSON(r) {
+		writeJSON(w, http.StatusOK, map[string]bool{"saved": true})
+		return
+	}
+	http.Redirect(w, r, Path+url.PathEscape(name), http.StatusSeeOther)
+}
+
+func isJSON(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "json"
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func render(w http.ResponseWriter, tpl *template.Template, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tpl.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+var indexTpl = template.Must(template.New("index").Funcs(tplFuncs).Parse(`<!DOCTYPE html>
+<html><head><title>/debug/lcache/</title></head>
+<body>
+<h1>/debug/lcache/</h1>
+<table border="1" cellpadding="4">
+<tr><th>Name</th><th>Len</th><th>Hits</th><th>Misses</th><th>Hit Ratio</th><th>Last Save</th></tr>
+{{range .}}<tr>
+<td><a href="{{.Name}}">{{.Name}}</a></td><td>{{.Len}}</td><td>{{.Hits}}</td><td>{{.Misses}}</td>
+<td>{{printf "%.2f%%" (mul100 .HitRatio)}}</td><td>{{if .LastSaveAt}}{{.LastSaveAt.Format "2006-01-02 15:04:05"}}{{end}}</td>
+</tr>{{else}}<tr><td colspan="6">no registered caches</td></tr>{{end}}
+</table>
+<p><a href="?format=json">JSON</a></p>
+</body></html>
+`))
+
+var cacheTpl = template.Must(template.New("cache").Funcs(tplFuncs).Parse(`<!DOCTYPE html>
+<html><head><title>/debug/lcache/{{.Name}}</title></head>
+<body>
+<p><a href="./">/debug/lcache/</a></p>
+<h1>{{.Name}}</h1>
+<p>Len: {{.Len}}, Valid: {{.ValidLen}}, Hits: {{.Hits}}, Misses: {{.Misses}}, Hit Ratio: {{printf "%.2f%%" (mul100 .HitRatio)}}, Generation: {{.Generation}}</p>
+<p>Last Save: {{if .LastSaveAt}}{{.LastSaveAt.Format "2006-01-02 15:04:05"}}{{else}}-{{end}} {{.LastSaveErr}}
+{{if .CanSave}}<form method="post" action="{{.Name}}/save" style="display:inline"><button>Snapshot</button></form>{{end}}</p>
+<h2>Hot Keys</h2>
+<table border="1" cellpadding="4">
+<tr><th>Key</th><th>Hits</th><th></th></tr>
+{{$name := .Name}}{{range .HotKeys}}<tr><td>{{.Key}}</td><td>{{.Hits}}</td>
+<td><form method="post" action="{{$name}}/delete"><input type="hidden" name="key" value="{{.Key}}"><button>Delete</button></form></td></tr>
+{{end}}</table>
+<h2>Upcoming Expirations</h2>
+<table border="1" cellpadding="4">
+<tr><th>Key</th><th>TTL</th><th></th></tr>
+{{range .Expiring}}<tr><td>{{.Key}}</td><td>{{.TTL}}</td>
+<td><form method="post" action="{{$name}}/delete"><input type="hidden" name="key" value="{{.Key}}"><button>Delete</button></form></td></tr>
+{{end}}</table>
+<p><a href="?format=json">JSON</a></p>
+</body></html>
+`))
+
+var tplFuncs = template.FuncMap{
+	"mul100": func(f float64) float64 { return f * 100 },
+}
@@ -0,0 +1,76 @@
+package lcdebug_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/lcache/lcdebug"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestHandler(t *testing.T) {
+	c := lcache.New()
+	c.Set("user:1", "tom", 0)
+	c.Set("user:2", "jack", time.Minute)
+	c.Get("user:1")
+	c.Get("none")
+
+	lcdebug.Register("users", c, lcdebug.WithSaveFile(filepath.Join(t.TempDir(), "users.json")))
+	lcdebug.Register("other", lcache.New())
+	defer lcdebug.Unregister("users")
+	defer lcdebug.Unregister("other")
+
+	mux := http.NewServeMux()
+	lcdebug.RegisterDebug(mux)
+	do := func(method, target string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	// index
+	w := do("GET", "/debug/lcache/", nil)
+	assert.Eq(t, http.StatusOK, w.Code)
+	assert.StrContains(t, w.Body.String(), `<a href="users">users</a>`)
+
+	var list []map[string]any
+	w = do("GET", "/debug/lcache/?format=json", nil)
+	assert.NoErr(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list, 2)
+	assert.Eq(t, "other", list[0]["name"])
+	assert.Eq(t, 0.5, list[1]["hit_ratio"])
+
+	// detail
+	w = do("GET", "/debug/lcache/users", nil)
+	assert.Eq(t, http.StatusOK, w.Code)
+	assert.StrContains(t, w.Body.String(), "Hit Ratio: 50.00%")
+	assert.StrContains(t, w.Body.String(), "<td>user:2</td>")
+
+	var info map[string]any
+	w = do("GET", "/debug/lcache/users?format=json", nil)
+	assert.NoErr(t, json.Unmarshal(w.Body.Bytes(), &info))
+	assert.Eq(t, true, info["can_save"])
+	assert.Len(t, info["hot_keys"], 1)
+	assert.Len(t, info["expiring"], 1)
+	assert.Eq(t, http.StatusNotFound, do("GET", "/debug/lcache/none", nil).Code)
+
+	// actions
+	w = do("POST", "/debug/lcache/users/delete", url.Values{"key": {"user:1"}})
+	assert.Eq(t, http.StatusSeeOther, w.Code)
+	assert.Eq(t, "/debug/lcache/users", w.Header().Get("Location"))
+	assert.False(t, c.Has("user:1"))
+
+	w = do("POST", "/debug/lcache/users/save", nil)
+	assert.Eq(t, http.StatusSeeOther, w.Code)
+	assert.False(t, c.Stats().LastSaveAt.IsZero())
+	assert.Eq(t, http.StatusNotImplemented, do("POST", "/debug/lcache/other/save", nil).Code)
+}
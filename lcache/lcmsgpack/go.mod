@@ -0,0 +1,18 @@
+module github.com/gookit/ext/lcache/lcmsgpack
+
+go 1.23
+
+require (
+	github.com/gookit/ext v0.0.0
+	github.com/gookit/goutil v0.8.0
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+)
+
+require (
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/term v0.29.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+)
+
+replace github.com/gookit/ext => ../..
@@ -0,0 +1,58 @@
+// Package lcmsgpack provides a MessagePack serializer for lcache snapshot files,
+// built on github.com/vmihailenco/msgpack/v5.
+//
+// It is a separate module to avoid adding the msgpack dependency to lcache.
+//
+// The struct fields use the `msgpack` tag, fallback to the `json` tag. Like JSON, the untyped
+// values are decoded to the generic types(eg: map[string]any, []any, int64, float64), so that
+// the values of the types registered by lcache.RegisterType are restored on load.
+//
+// Usage:
+//
+//	lcache.SetSerializer(lcmsgpack.Name, lcmsgpack.Serializer{})
+//	c := lcache.New(lcache.WithSerializer(lcmsgpack.Name))
+package lcmsgpack
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Name of the serializer
+const Name = "msgpack"
+
+// Serializer MessagePack serializer, implements the lcache.Serializer
+type Serializer struct{}
+
+// Decode implements lcache.Serializer
+func (s Serializer) Decode(data []byte, dest any) error {
+	return s.DecodeFrom(bytes.NewReader(data), dest)
+}
+
+// Encode implements lcache.Serializer
+func (s Serializer) Encode(data any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.EncodeTo(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeFrom implements lcache.Serializer
+func (Serializer) DecodeFrom(r io.Reader, dest any) error {
+	dec := msgpack.NewDecoder(r)
+	dec.SetCustomStructTag("json")
+	// 与 JSON 一致: 整数解码为 int64(超出时为 uint64)，map 解码为 map[string]any
+	dec.UseLooseInterfaceDecoding(true)
+	return dec.Decode(dest)
+}
+
+// EncodeTo implements lcache.Serializer
+func (Serializer) EncodeTo(w io.Writer, src any) error {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	return enc.Encode(src)
+}
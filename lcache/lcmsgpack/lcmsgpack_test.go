@@ -0,0 +1,56 @@
+package lcmsgpack_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/lcache/lcmsgpack"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+type mpUser struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestSerializer(t *testing.T) {
+	var s lcmsgpack.Serializer
+	bs, err := s.Encode(map[string]any{"name": "inhere", "age": 20, "tags": []string{"a"}})
+	assert.NoErr(t, err)
+
+	var dst any
+	assert.NoErr(t, s.Decode(bs, &dst))
+	assert.Eq(t, map[string]any{"name": "inhere", "age": int64(20), "tags": []any{"a"}}, dst)
+
+	var u mpUser
+	assert.NoErr(t, s.Decode(bs, &u))
+	assert.Eq(t, mpUser{Name: "inhere", Age: 20}, u)
+}
+
+func TestSerializer_snapshot(t *testing.T) {
+	lcache.SetSerializer(lcmsgpack.Name, lcmsgpack.Serializer{})
+	defer lcache.SetSerializer(lcmsgpack.Name, nil)
+	lcache.RegisterType[mpUser]("mp-user")
+
+	c := lcache.New(lcache.WithSerializer(lcmsgpack.Name), lcache.WithLRUMode(lcache.LRUExact))
+	c.Set("key1", "val1", 0)
+	c.Set("key2", 2, time.Hour)
+	c.Set("user", mpUser{Name: "inhere", Age: 20}, 0)
+	c.Get("key1")
+
+	filename := t.TempDir() + "/cache.msgpack"
+	assert.NoErr(t, c.SaveFile(filename))
+
+	c2 := lcache.New(lcache.WithSerializer(lcmsgpack.Name))
+	assert.NoErr(t, c2.LoadFile(filename))
+	assert.Eq(t, 3, c2.Len())
+	// the LRU order is restored
+	key, _ := c2.NewestKey()
+	assert.Eq(t, "key1", key)
+
+	assert.Eq(t, "val1", c2.Val("key1"))
+	assert.Eq(t, int64(2), c2.Val("key2"))
+	assert.Eq(t, mpUser{Name: "inhere", Age: 20}, c2.Val("user"))
+
+}
@@ -0,0 +1,17 @@
+module github.com/gookit/ext/lcache/lcredis
+
+go 1.23
+
+require (
+	github.com/gookit/ext v0.0.0
+	github.com/redis/go-redis/v9 v9.7.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/gookit/goutil v0.8.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+)
+
+replace github.com/gookit/ext => ../..
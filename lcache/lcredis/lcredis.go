@@ -0,0 +1,53 @@
+// Package lcredis provides a Redis adapter of lcache.RemoteCache, for use
+// redis as the remote L2 cache of lcache.Tiered.
+//
+// It is a separate module to avoid adding the redis client dependency to lcache.
+//
+// Usage:
+//
+//	rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+//	tc := lcache.NewTiered(lcache.New(), lcredis.New(rdb))
+package lcredis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/redis/go-redis/v9"
+)
+
+// Remote redis adapter, implements the lcache.RemoteCache
+type Remote struct {
+	rdb redis.Cmdable
+	// Prefix for all keys. eg: "app:"
+	Prefix string
+}
+
+// New create a redis remote cache. rdb can be a client, cluster client or ring.
+func New(rdb redis.Cmdable) *Remote {
+	return &Remote{rdb: rdb}
+}
+
+// Get implements lcache.RemoteCache
+func (r *Remote) Get(ctx context.Context, key string) ([]byte, error) {
+	bs, err := r.rdb.Get(ctx, r.Prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, lcache.ErrNotFound
+	}
+	return bs, err
+}
+
+// Set implements lcache.RemoteCache
+func (r *Remote) Set(ctx context.Context, key string, val []byte, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+	return r.rdb.Set(ctx, r.Prefix+key, val, ttl).Err()
+}
+
+// Del implements lcache.RemoteCache
+func (r *Remote) Del(ctx context.Context, key string) error {
+	return r.rdb.Del(ctx, r.Prefix+key).Err()
+}
@@ -0,0 +1,43 @@
+package lcredis_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/lcache/lcredis"
+	"github.com/redis/go-redis/v9"
+)
+
+var _ lcache.RemoteCache = (*lcredis.Remote)(nil)
+
+// set env REDIS_ADDR to run the test. eg: REDIS_ADDR=localhost:6379
+func TestRemote(t *testing.T) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_ADDR is not set")
+	}
+
+	ctx := context.Background()
+	r := lcredis.New(redis.NewClient(&redis.Options{Addr: addr}))
+	r.Prefix = "lcredis_test:"
+
+	if err := r.Set(ctx, "key1", []byte("val1"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	bs, err := r.Get(ctx, "key1")
+	if err != nil || string(bs) != "val1" {
+		t.Fatalf("want val1, got %q, err: %v", bs, err)
+	}
+
+	if err = r.Del(ctx, "key1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.Get(ctx, "key1"); !errors.Is(err, lcache.ErrNotFound) {
+		t.Fatalf("want ErrNotFound, got %v", err)
+	}
+}
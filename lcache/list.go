@@ -0,0 +1,180 @@
+package lcache
+
+import "time"
+
+// LPush insert the values at the head of the list stored at key, like Redis LPUSH:
+// LPush("k", "a", "b") results in ["b", "a"]. Creates the list(never expire) if the key
+// does not exist or expired, use Touch to set its TTL. Returns the length of the list.
+//
+// The list is stored as []any under one key and shares the TTL of the key. With WithMaxListLen,
+// the elements beyond the limit are removed from the tail. The list operations are local only,
+// not written to the Store.
+//
+// Returns ErrNotList if the value of key is not a list, ErrBusy, ErrFrozen, ErrCacheFull,
+// or a *SizeError if the new list exceeds WithMaxValueBytes.
+func (c *Cache) LPush(key string, values ...any) (int, error) {
+	return c.pushList(key, true, values)
+}
+
+// RPush append the values at the tail of the list stored at key, like Redis RPUSH.
+// With WithMaxListLen, the elements beyond the limit are removed from the head. see LPush
+func (c *Cache) RPush(key string, values ...any) (int, error) {
+	return c.pushList(key, false, values)
+}
+
+// LPop remove and return the first element of the list stored at key.
+// The key is deleted when the list becomes empty. returns false if the list is missing or empty.
+func (c *Cache) LPop(key string) (any, bool) { return c.popList(key, true) }
+
+// RPop remove and return the last element of the list stored at key. see LPop
+func (c *Cache) RPop(key string) (any, bool) { return c.popList(key, false) }
+
+// LRange get a copy of the elements of the list stored at key between start and stop(inclusive),
+// like Redis LRANGE. Negative index counts from the tail, eg: LRange(key, 0, -1) get all elements.
+// returns nil if the list is missing.
+func (c *Cache) LRange(key string, start, stop int) []any {
+	key, err := c.normKey(key)
+	if err != nil || !c.lockRead() {
+		return nil
+	}
+	defer c.unlockRead()
+
+	hk, it := c.find(c.nsKey(key))
+	list, ok := c.validList(it)
+	if !ok {
+		return nil
+	}
+	c.touch(hk, it)
+
+	n := len(list)
+	if start < 0 {
+		start = max(n+start, 0)
+	}
+	if stop < 0 {
+		stop = n + stop
+	}
+	stop = min(stop, n-1)
+	if start > stop {
+		return []any{}
+	}
+	return append([]any(nil), list[start:stop+1]...)
+}
+
+// LLen get the length of the list stored at key, 0 if the list is missing or not a list.
+func (c *Cache) LLen(key string) int {
+	key, err := c.normKey(key)
+	if err != nil || !c.rlock() {
+		return 0
+	}
+	defer c.mu.RUnlock()
+
+	_, it := c.find(c.nsKey(key))
+	list, _ := c.validList(it)
+	return len(list)
+}
+
+// validList 获取有效数据项中的列表 (不加锁)
+func (c *Cache) validList(it *Item) ([]any, bool) {
+	if it == nil || c.invalid(it, time.Now().UnixMilli()) {
+		return nil, false
+	}
+
+	list, ok := it.Val.([]any)
+	return list, ok
+}
+
+// pushList 向列表头部(left 为 true)或尾部插入数据，总是创建新的切片，不影响已读取的旧列表
+func (c *Cache) pushList(key string, left bool, values []any) (int, error) {
+	key, err := c.normKey(key)
+	if err != nil {
+		return 0, err
+	}
+	if !c.lock() {
+		return 0, ErrBusy
+	}
+	defer c.unlock()
+	if c.frozen.Load() {
+		return 0, ErrFrozen
+	}
+
+	nk := c.nsKey(key)
+	hk, it := c.find(nk)
+	var old []any
+	if it != nil && !c.invalid(it, time.Now().UnixMilli()) {
+		var ok bool
+		if old, ok = it.Val.([]any); !ok {
+			return 0, ErrNotList
+		}
+	} else {
+		it = nil
+	}
+
+	list := make([]any, 0, len(old)+len(values))
+	if left {
+		for i := len(values) - 1; i >= 0; i-- {
+			list = append(list, values[i])
+		}
+		list = append(list, old...)
+		if maxLen := c.opt.MaxListLen; maxLen > 0 && len(list) > maxLen {
+			list = list[:maxLen]
+		}
+	} else {
+		list = append(append(list, old...), values...)
+		if maxLen := c.opt.MaxListLen; maxLen > 0 && len(list) > maxLen {
+			list = list[len(list)-maxLen:]
+		}
+	}
+
+	if err = c.checkSize(key, list); err != nil {
+		return 0, err
+	}
+	if it == nil {
+		if c.set(nk, list, 0) == nil {
+			return 0, ErrCacheFull
+		}
+		return len(list), c.appendSet(nk, list, 0)
+	}
+
+	it.Val = list
+	c.updateIndexes(hk, it)
+	c.touch(hk, it)
+	return len(list), c.appendSet(nk, list, it.Exp)
+}
+
+// popList 删除并返回列表头部(left 为 true)或尾部的数据. 列表为空时删除 key
+func (c *Cache) popList(key string, left bool) (any, bool) {
+	key, err := c.normKey(key)
+	if err != nil || !c.lock() {
+		return nil, false
+	}
+	defer c.unlock()
+	if c.frozen.Load() {
+		return nil, false
+	}
+
+	nk := c.nsKey(key)
+	hk, it := c.find(nk)
+	list, ok := c.validList(it)
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+
+	var val any
+	if left {
+		val, list = list[0], list[1:]
+	} else {
+		val, list = list[len(list)-1], list[:len(list)-1]
+	}
+
+	if len(list) == 0 {
+		c.removeElement(hk, ReasonDeleted)
+		_ = c.appendAOF(aofOpDel, nk, nil, 0)
+		return val, true
+	}
+
+	it.Val = list
+	c.updateIndexes(hk, it)
+	c.touch(hk, it)
+	_ = c.appendSet(nk, list, it.Exp)
+	return val, true
+}
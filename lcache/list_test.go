@@ -0,0 +1,77 @@
+package lcache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_ListOps(t *testing.T) {
+	c := lcache.New()
+
+	n, err := c.RPush("list", "a", "b")
+	assert.NoErr(t, err)
+	assert.Eq(t, 2, n)
+	n, err = c.LPush("list", "x", "y")
+	assert.NoErr(t, err)
+	assert.Eq(t, 4, n)
+	assert.Eq(t, []any{"y", "x", "a", "b"}, c.LRange("list", 0, -1))
+	assert.Eq(t, []any{"x", "a"}, c.LRange("list", 1, 2))
+	assert.Eq(t, []any{"a", "b"}, c.LRange("list", -2, 10))
+	assert.Eq(t, []any{}, c.LRange("list", 3, 1))
+	assert.Nil(t, c.LRange("not-exists", 0, -1))
+	assert.Eq(t, 4, c.LLen("list"))
+
+	// the read list is not changed by the later ops
+	old := c.Val("list").([]any)
+	val, ok := c.RPop("list")
+	assert.True(t, ok)
+	assert.Eq(t, "b", val)
+	_, _ = c.RPush("list", "c")
+	assert.Eq(t, []any{"y", "x", "a", "b"}, old)
+
+	val, ok = c.LPop("list")
+	assert.True(t, ok)
+	assert.Eq(t, "y", val)
+	assert.Eq(t, []any{"x", "a", "c"}, c.LRange("list", 0, -1))
+
+	// the key is deleted when empty
+	c.LPop("list")
+	c.LPop("list")
+	c.LPop("list")
+	assert.False(t, c.Has("list"))
+	_, ok = c.LPop("list")
+	assert.False(t, ok)
+
+	// not a list
+	c.Set("str", "abc", 0)
+	_, err = c.LPush("str", "a")
+	assert.True(t, errors.Is(err, lcache.ErrNotList))
+	_, ok = c.RPop("str")
+	assert.False(t, ok)
+	assert.Eq(t, 0, c.LLen("str"))
+}
+
+func TestCache_ListOps_ttlAndMaxLen(t *testing.T) {
+	c := lcache.New(lcache.WithMaxListLen(3))
+	_, _ = c.RPush("list", 1, 2, 3, 4)
+	assert.Eq(t, []any{2, 3, 4}, c.LRange("list", 0, -1))
+	_, _ = c.LPush("list", 0)
+	assert.Eq(t, []any{0, 2, 3}, c.LRange("list", 0, -1))
+
+	// shared TTL
+	assert.True(t, c.Touch("list", 20*time.Millisecond))
+	_, _ = c.RPush("list", 5)
+	ttl, ok := c.TTL("list")
+	assert.True(t, ok)
+	assert.Gt(t, ttl, time.Duration(0))
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Eq(t, 0, c.LLen("list"))
+	n, err := c.RPush("list", 1)
+	assert.NoErr(t, err)
+	assert.Eq(t, 1, n)
+}
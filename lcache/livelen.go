@@ -0,0 +1,105 @@
+package lcache
+
+import (
+	"container/heap"
+	"time"
+)
+
+// expEntry 过期时间堆的元素. exp 与数据项当前的 Exp 不一致时，说明 TTL 已被更新，该元素已失效
+type expEntry struct {
+	exp int64
+	it  *Item
+}
+
+// expHeap 按过期时间排列的最小堆. see WithAccurateLen
+type expHeap []expEntry
+
+func (h expHeap) Len() int           { return len(h) }
+func (h expHeap) Less(i, j int) bool { return h[i].exp < h[j].exp }
+func (h expHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *expHeap) Push(x any)        { *h = append(*h, x.(expEntry)) }
+
+func (h *expHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+// WithAccurateLen keep a live counter of the valid(not expired) items, so Len is both O(1)
+// and accurate, without the expired items not yet removed by the janitor or a read.
+//
+// The expiration times are kept in a min-heap, Len takes the write lock to pop the expired
+// ones and decrements the counter, each item is popped once. It costs a heap push for each
+// write of an item with TTL. Default is disabled, Len returns the size of the map, include
+// the expired items not yet removed.
+//
+// NOTE: Len of a namespace view still traverses the items of the namespace.
+func WithAccurateLen(enable bool) OptionFn {
+	return func(o *Options) {
+		o.AccurateLen = enable
+	}
+}
+
+// liveLen 获取有效数据的数量，先移除堆中已过期的数据 (需持有写锁)
+func (c *Cache) liveLen(nowUm int64) int {
+	for len(c.expHeap) > 0 && c.expHeap[0].exp < nowUm {
+		e := heap.Pop(&c.expHeap).(expEntry)
+		if e.it.live && e.it.Exp == e.exp {
+			e.it.live = false
+			c.liveN--
+		}
+	}
+	return c.liveN
+}
+
+// trackLive 数据项写入 items 后计入有效数量 (不加锁). 已失效的数据项不计入
+func (c *Cache) trackLive(it *Item) {
+	// 数据项可能复制自其他缓存，重新设置标记
+	it.live = c.liveOn && !c.invalid(it, time.Now().UnixMilli())
+	if it.live {
+		c.liveN++
+	}
+	c.pushExp(it)
+}
+
+// untrackLive 删除或替换数据项时从有效数量中移除 (不加锁)
+func (c *Cache) untrackLive(it *Item) {
+	if it.live {
+		it.live = false
+		c.liveN--
+	}
+}
+
+// pushExp 数据项写入或更新 TTL 后记录其过期时间 (不加锁)
+func (c *Cache) pushExp(it *Item) {
+	c.notePrune(it.Exp)
+	if !it.live || it.Exp == 0 {
+		return
+	}
+
+	// 覆盖写入和更新 TTL 留下的失效元素过多时重建，重建时已包含 it
+	if len(c.expHeap) > 2*len(c.items)+64 {
+		c.rebuildLive()
+		return
+	}
+	heap.Push(&c.expHeap, expEntry{exp: it.Exp, it: it})
+}
+
+// rebuildLive 重新统计有效数量和过期时间堆 (不加锁). 关闭时清空
+func (c *Cache) rebuildLive() {
+	c.liveN, c.expHeap = 0, nil
+	nowUm := time.Now().UnixMilli()
+	for _, it := range c.items {
+		it.live = c.liveOn && !c.invalid(it, nowUm)
+		if !it.live {
+			continue
+		}
+
+		c.liveN++
+		if it.Exp > 0 {
+			c.expHeap = append(c.expHeap, expEntry{exp: it.Exp, it: it})
+		}
+	}
+	heap.Init(&c.expHeap)
+}
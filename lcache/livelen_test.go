@@ -0,0 +1,62 @@
+package lcache_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestWithAccurateLen(t *testing.T) {
+	c := lcache.New(lcache.WithAccurateLen(true), lcache.WithCapacity(10))
+	c.Set("key1", 1, 0)
+	c.Set("key2", 2, 20*time.Millisecond)
+	c.Set("key3", 3, 20*time.Millisecond)
+	c.Set("key3", 3, 0) // replaced
+	c.Set("key4", 4, 20*time.Millisecond)
+	assert.True(t, c.Touch("key4", time.Minute))
+	assert.Eq(t, 4, c.Len())
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Eq(t, 3, c.Len())
+	assert.Eq(t, 3, c.ValidLen())
+	assert.Eq(t, 3, c.Stats().ValidLen)
+	assert.True(t, c.HealthCheck().Healthy())
+
+	// delete, evict and clear
+	c.Delete("key1")
+	assert.Eq(t, 2, c.Len())
+	for i := 0; i < 20; i++ {
+		c.Set("k"+strconv.Itoa(i), i, time.Minute)
+	}
+	assert.Eq(t, 10, c.Len())
+	assert.True(t, c.HealthCheck().Healthy())
+
+	c.BumpGeneration()
+	assert.Eq(t, 0, c.Len())
+	c.Set("key5", 5, 0)
+	assert.Eq(t, 1, c.Len())
+	c.Clear()
+	assert.Eq(t, 0, c.Len())
+
+	// the stale entries of the overwritten keys are compacted
+	for i := 0; i < 1000; i++ {
+		c.Set("key6", i, time.Minute)
+	}
+	assert.Eq(t, 1, c.Len())
+	assert.True(t, c.HealthCheck().Healthy())
+
+	// enable and disable on the fly
+	c2 := lcache.New()
+	c2.Set("key1", 1, 10*time.Millisecond)
+	c2.Set("key2", 2, 0)
+	time.Sleep(20 * time.Millisecond)
+	assert.Eq(t, 2, c2.Len())
+	c2.Configure(lcache.WithAccurateLen(true))
+	assert.Eq(t, 1, c2.Len())
+	c2.Configure(lcache.WithAccurateLen(false))
+	assert.Eq(t, 2, c2.Len())
+	assert.True(t, c2.HealthCheck().Healthy())
+}
@@ -0,0 +1,322 @@
+package lcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// flight 正在进行中的加载调用. 同一个 key 的并发加载共享结果
+type flight struct {
+	done chan struct{}
+	val  any
+	err  error
+}
+
+// GetOrLoad get value by key, on miss call loader to load the value and store it with ttl.
+//
+// Concurrent misses for the same key trigger only one loader call, the other callers
+// wait and share its result(cache stampede protection). The loader error is not cached.
+//
+// Usage:
+//
+//	val, err := c.GetOrLoad("user:1", time.Minute, func(key string) (any, error) {
+//		return db.FindUser(1)
+//	})
+func (c *Cache) GetOrLoad(key string, ttl time.Duration, loader func(key string) (any, error)) (any, error) {
+	key, err := c.normKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if val, ok := c.Get(key); ok {
+		return val, nil
+	}
+
+	val, err := c.doLoad(context.Background(), key, true, func() (any, time.Duration, error) {
+		val, err := loader(key)
+		return val, ttl, err
+	})
+	return c.loadedCopy(val, err)
+}
+
+// GetOrLoadCtx like GetOrLoad, but the loader is called with ctx, and the waiting
+// callers return ctx.Err() if their ctx is done before the loading finished.
+//
+// NOTE: the loader is called with the ctx of the first caller, the other callers share its result.
+func (c *Cache) GetOrLoadCtx(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context, key string) (any, error)) (any, error) {
+	key, err := c.normKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if val, ok := c.Get(key); ok {
+		return val, nil
+	}
+
+	val, err := c.doLoad(ctx, key, true, func() (any, time.Duration, error) {
+		val, err := loader(ctx, key)
+		return val, ttl, err
+	})
+	return c.loadedCopy(val, err)
+}
+
+// MGetOrLoad get the values of multiple keys, the missing keys are loaded by loader
+// in one batch call and stored with ttl. Returns the merged values.
+//
+// The keys not returned by loader are not included in the result.
+// On loader error, returns the cached values and the error.
+//
+// Usage:
+//
+//	users, err := c.MGetOrLoad(ids, time.Minute, func(missing []string) (map[string]any, error) {
+//		return db.FindUsersMap(missing)
+//	})
+func (c *Cache) MGetOrLoad(keys []string, ttl time.Duration, loader func(missing []string) (map[string]any, error)) (map[string]any, error) {
+	result := make(map[string]any, len(keys))
+	var missing []string
+	for _, key := range keys {
+		key, err := c.normKey(key)
+		if err != nil {
+			continue
+		}
+
+		if val, st := c.GetState(key); st != StateMissing {
+			result[key] = val
+		} else {
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	loaded, err := loader(missing)
+	if err != nil {
+		return result, err
+	}
+
+	c.msetLocal(c.sizeFilter(loaded), ttl)
+	for key, val := range loaded {
+		if result[key], err = c.readCopy(val); err != nil {
+			delete(result, key)
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// GetCtx get value by key, on miss load it by the read-through loader. see WithLoader
+//
+// Returns ErrNotFound if the key is missing and no loader configured,
+// or the ctx error if ctx is done before the loading finished.
+func (c *Cache) GetCtx(ctx context.Context, key string) (any, error) {
+	key, err := c.normKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	val, st := c.GetState(key)
+	if st != StateMissing {
+		return val, nil
+	}
+
+	if c.opt.Loader == nil && c.opt.Store == nil {
+		return nil, ErrNotFound
+	}
+	return c.loadThrough(ctx, key)
+}
+
+// loadThrough 使用 read-through loader 或 Store 加载数据. 优先使用 loader
+func (c *Cache) loadThrough(ctx context.Context, key string) (any, error) {
+	val, err := c.doLoad(ctx, key, true, func() (any, time.Duration, error) {
+		if c.opt.Loader != nil {
+			return c.opt.Loader(ctx, key)
+		}
+
+		val, err := c.storeLoad(ctx, key)
+		return val, c.opt.StoreTTL, err
+	})
+	return c.loadedCopy(val, err)
+}
+
+// doLoad 调用 fn 加载 key 的数据并写入缓存，同一个 key 的并发调用只执行一次 fn.
+// check 为 true 时先检查缓存中是否已存在
+func (c *Cache) doLoad(ctx context.Context, key string, check bool, fn func() (any, time.Duration, error)) (val any, err error) {
+	fk := c.nsKey(key)
+	mf := c.loadMissFilter()
+	if check && mf != nil && mf.Test(fk) {
+		return nil, ErrNotFound
+	}
+
+	if g := c.opt.FlightGroup; g != nil {
+		var leader bool
+		val, err, _ = g.DoCtx(ctx, fk, func(context.Context) (any, error) {
+			leader = true
+			return c.loadKey(key, fk, check, mf, fn)
+		})
+		if !leader {
+			c.loadCoalesced()
+		}
+		return val, err
+	}
+
+	c.flightMu.Lock()
+	if c.flights == nil {
+		c.flights = make(map[string]*flight)
+	}
+	if f, ok := c.flights[fk]; ok {
+		c.flightMu.Unlock()
+		c.loadCoalesced()
+		select {
+		case <-f.done:
+			return f.val, f.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	f := &flight{done: make(chan struct{})}
+	c.flights[fk] = f
+	c.flightMu.Unlock()
+
+	defer func() {
+		// loader panic 时也要唤醒等待的调用方
+		if r := recover(); r != nil {
+			err = fmt.Errorf("lcache: loader panic: %v", r)
+			f.err = err
+		}
+
+		c.flightMu.Lock()
+		delete(c.flights, fk)
+		c.flightMu.Unlock()
+		close(f.done)
+	}()
+
+	val, err = c.loadKey(key, fk, check, mf, fn)
+	f.val, f.err = val, err
+	return val, err
+}
+
+// loadKey 调用 fn 加载数据并写入缓存，加载失败且为 ErrNotFound 时记录到 mf. 需在 flight 中调用
+func (c *Cache) loadKey(key, fk string, check bool, mf MissFilter, fn func() (any, time.Duration, error)) (any, error) {
+	// 可能在获取 flight 前，其他调用已经加载完成
+	if check {
+		if v, st := c.GetState(key); st != StateMissing {
+			return v, nil
+		}
+	}
+
+	val, ttl, err := c.callLoader(fk, fn)
+	if mf != nil && errors.Is(err, ErrNotFound) {
+		mf.Add(fk)
+	}
+	// 冻结后只返回加载的数据，不写入缓存
+	if err == nil && !c.frozen.Load() {
+		if err = c.checkSize(key, val); err == nil {
+			err = c.setLocal(key, val, ttl, nil)
+		}
+	}
+	return val, err
+}
+
+// callLoader 调用 fn 并记录加载统计. see LoadStats
+func (c *Cache) callLoader(fk string, fn func() (any, time.Duration, error)) (val any, ttl time.Duration, err error) {
+	start := time.Now()
+	c.loadStart(fk, start)
+	// panic 时同样记录为失败
+	failed := true
+	defer func() { c.loadEnd(fk, start, failed) }()
+
+	val, ttl, err = fn()
+	failed = err != nil
+	return val, ttl, err
+}
+
+// FlightGroup deduplicate the concurrent loads of the same key. eg: *sflight.Group[any].
+// see WithFlightGroup
+type FlightGroup interface {
+	// DoCtx execute fn for the key, the concurrent calls of the same key only execute it once.
+	DoCtx(ctx context.Context, key string, fn func(ctx context.Context) (any, error)) (val any, err error, shared bool)
+	// InFlight check the key has an in-flight call
+	InFlight(key string) bool
+}
+
+// inFlight 检查 key 是否正在加载中
+func (c *Cache) inFlight(fk string) bool {
+	if g := c.opt.FlightGroup; g != nil {
+		return g.InFlight(fk)
+	}
+
+	c.flightMu.Lock()
+	defer c.flightMu.Unlock()
+	_, ok := c.flights[fk]
+	return ok
+}
+
+// LoaderFn load the value for the key. see WithRefreshAhead
+type LoaderFn func(key string) (any, error)
+
+// ReadLoaderFn read-through loader, returns the value and its TTL. see WithLoader
+type ReadLoaderFn func(ctx context.Context, key string) (any, time.Duration, error)
+
+// Executor run the background tasks. eg: *lpool.Pool. see WithExecutor
+type Executor interface {
+	// Submit a task to run in the background, returns error if it cannot be accepted.
+	// It is called after the cache lock is released, so it can block or run the task
+	// synchronously, but the latter blocks the Get until the reload is done.
+	Submit(task func()) error
+}
+
+// checkRefreshAhead 数据已超过 TTL 的指定比例时，返回后台刷新的任务 (不加锁)
+func (c *Cache) checkRefreshAhead(key string, it *Item, nowUm int64) func() {
+	if it.ttl <= 0 || c.opt.RefreshAhead <= 0 {
+		return nil
+	}
+	if nowUm >= it.Exp-int64(float64(it.ttl)*(1-c.opt.RefreshAhead)) {
+		return c.refreshTask(key, it)
+	}
+	return nil
+}
+
+// isStale 检查已过期的数据是否在宽限期内 (不加锁)
+func (c *Cache) isStale(it *Item, nowUm int64) bool {
+	return c.opt.StaleGrace > 0 && it.Gen >= c.gen && it.Exp > 0 &&
+		nowUm <= it.Exp+c.opt.StaleGrace.Milliseconds()
+}
+
+// refreshTask 创建使用 RefreshLoader 重新加载数据的任务，TTL 不变. 无需刷新时返回 nil (不加锁)
+func (c *Cache) refreshTask(key string, it *Item) func() {
+	if it.ttl <= 0 || c.opt.RefreshLoader == nil {
+		return nil
+	}
+
+	// 已有刷新在进行中
+	if c.inFlight(c.nsKey(key)) {
+		return nil
+	}
+
+	ttl := time.Duration(it.ttl) * time.Millisecond
+	return func() {
+		_, _ = c.doLoad(context.Background(), key, false, func() (any, time.Duration, error) {
+			val, err := c.opt.RefreshLoader(key)
+			return val, ttl, err
+		})
+	}
+}
+
+// runRefresh 在后台执行刷新任务. 必须在释放缓存锁之后调用，执行器的 Submit 可能阻塞
+func (c *Cache) runRefresh(task func()) {
+	if task == nil {
+		return
+	}
+
+	// 执行器拒绝时跳过本次刷新，下次 Get 时重试
+	if c.opt.Executor != nil {
+		_ = c.opt.Executor.Submit(task)
+	} else {
+		go task()
+	}
+}
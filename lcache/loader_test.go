@@ -0,0 +1,418 @@
+package lcache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_GetOrLoad(t *testing.T) {
+	c := lcache.New()
+
+	var calls int32
+	loader := func(key string) (any, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		return "val-" + key, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 1000; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			val, err := c.GetOrLoad("key1", time.Minute, loader)
+			assert.NoErr(t, err)
+			assert.Eq(t, "val-key1", val)
+		}()
+	}
+	wg.Wait()
+	assert.Eq(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Eq(t, "val-key1", c.Val("key1"))
+
+	// error is not cached
+	errLoad := errors.New("load error")
+	_, err := c.GetOrLoad("key2", 0, func(string) (any, error) {
+		return nil, errLoad
+	})
+	assert.ErrIs(t, err, errLoad)
+	assert.False(t, c.Has("key2"))
+
+	// loader panic
+	_, err = c.GetOrLoad("key3", 0, func(string) (any, error) {
+		panic("oops")
+	})
+	assert.Err(t, err)
+	assert.Contains(t, err.Error(), "oops")
+}
+
+func TestCache_GetOrLoadCtx(t *testing.T) {
+	c := lcache.New()
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "v1")
+	val, err := c.GetOrLoadCtx(ctx, "key1", time.Minute, func(ctx context.Context, key string) (any, error) {
+		return ctx.Value(ctxKey{}), nil
+	})
+	assert.NoErr(t, err)
+	assert.Eq(t, "v1", val)
+	assert.Eq(t, "v1", c.Val("key1"))
+
+	// the waiting caller returns on its ctx done
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_, _ = c.GetOrLoadCtx(context.Background(), "key2", 0, func(context.Context, string) (any, error) {
+			close(started)
+			<-release
+			return 2, nil
+		})
+	}()
+	<-started
+
+	ctx2, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = c.GetOrLoadCtx(ctx2, "key2", 0, func(context.Context, string) (any, error) {
+		return 3, nil
+	})
+	assert.ErrIs(t, err, context.DeadlineExceeded)
+	close(release)
+}
+
+func TestWithRefreshAhead(t *testing.T) {
+	var calls int32
+	exec := &testExecutor{}
+	c := lcache.New(lcache.WithExecutor(exec), lcache.WithRefreshAhead(0.5, func(key string) (any, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return key + "-v" + string(rune('0'+n)), nil
+	}))
+
+	c.Set("key1", "key1-v0", 300*time.Millisecond)
+	assert.Eq(t, "key1-v0", c.Val("key1"))
+	assert.Eq(t, int32(0), exec.tasks.Load())
+
+	// past 50% of TTL, return cached value and refresh in background
+	time.Sleep(160 * time.Millisecond)
+	assert.Eq(t, "key1-v0", c.Val("key1"))
+	exec.wg.Wait()
+	assert.Eq(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Eq(t, "key1-v1", c.Val("key1"))
+
+	// not expired after the original TTL
+	time.Sleep(150 * time.Millisecond)
+	assert.True(t, c.Has("key1"))
+
+	assert.Panics(t, func() {
+		lcache.WithRefreshAhead(1.5, func(string) (any, error) { return nil, nil })
+	})
+}
+
+// testExecutor run the tasks in background, or reject them
+type testExecutor struct {
+	wg     sync.WaitGroup
+	reject atomic.Bool
+	tasks  atomic.Int32
+}
+
+func (e *testExecutor) Submit(task func()) error {
+	if e.reject.Load() {
+		return errors.New("rejected")
+	}
+	e.tasks.Add(1)
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		task()
+	}()
+	return nil
+}
+
+// testFlightGroup record the keys, without deduplication
+type testFlightGroup struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (g *testFlightGroup) DoCtx(ctx context.Context, key string, fn func(ctx context.Context) (any, error)) (any, error, bool) {
+	g.mu.Lock()
+	g.keys = append(g.keys, key)
+	g.mu.Unlock()
+	val, err := fn(ctx)
+	return val, err, false
+}
+
+func (g *testFlightGroup) InFlight(string) bool { return false }
+
+func TestWithFlightGroup(t *testing.T) {
+	g := &testFlightGroup{}
+	c := lcache.New(lcache.WithFlightGroup(g))
+	users := c.Namespace("users")
+
+	val, err := users.GetOrLoad("1", 0, func(key string) (any, error) {
+		return "user-" + key, nil
+	})
+	assert.NoErr(t, err)
+	assert.Eq(t, "user-1", val)
+	assert.Eq(t, "user-1", users.Val("1"))
+
+	// cached, not loaded again
+	val, err = users.GetOrLoad("1", 0, func(key string) (any, error) {
+		return "other", nil
+	})
+	assert.NoErr(t, err)
+	assert.Eq(t, "user-1", val)
+
+	_, err = c.GetOrLoad("2", 0, func(key string) (any, error) {
+		return nil, errors.New("load error")
+	})
+	assert.ErrMsg(t, err, "load error")
+	assert.False(t, c.Has("2"))
+	assert.Eq(t, []string{"users:1", "2"}, g.keys)
+}
+
+func TestWithExecutor(t *testing.T) {
+	exec := &testExecutor{}
+	exec.reject.Store(true)
+	c := lcache.New(lcache.WithRefreshAhead(0.5, func(key string) (any, error) {
+		return key + "-new", nil
+	}), lcache.WithExecutor(exec))
+
+	c.Set("key1", "old", 40*time.Millisecond)
+	time.Sleep(25 * time.Millisecond)
+
+	// rejected, skip the refresh
+	assert.Eq(t, "old", c.Val("key1"))
+	assert.Eq(t, "old", c.Val("key1"))
+	assert.Eq(t, int32(0), exec.tasks.Load())
+
+	// retried on next Get
+	exec.reject.Store(false)
+	assert.Eq(t, "old", c.Val("key1"))
+	exec.wg.Wait()
+	assert.Eq(t, int32(1), exec.tasks.Load())
+	assert.Eq(t, "key1-new", c.Val("key1"))
+
+	// submitted after the lock released, run the task in the caller
+	c = lcache.New(lcache.WithRefreshAhead(0.5, func(key string) (any, error) {
+		return key + "-new", nil
+	}), lcache.WithExecutor(callerExecutor{}))
+	c.Set("key1", "old", 40*time.Millisecond)
+	time.Sleep(25 * time.Millisecond)
+	assert.Eq(t, "old", c.Val("key1"))
+	assert.Eq(t, "key1-new", c.Val("key1"))
+}
+
+// callerExecutor run the tasks in the caller goroutine
+type callerExecutor struct{}
+
+func (callerExecutor) Submit(task func()) error {
+	task()
+	return nil
+}
+
+func TestWithStaleWhileRevalidate(t *testing.T) {
+	var calls int32
+	exec := &testExecutor{}
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	c := lcache.New(lcache.WithExecutor(exec), lcache.WithStaleWhileRevalidate(time.Minute, func(key string) (any, error) {
+		atomic.AddInt32(&calls, 1)
+		started <- struct{}{}
+		<-release
+		return key + "-new", nil
+	}))
+
+	c.Set("key1", "key1-old", 10*time.Millisecond)
+	c.Set("key2", "key2-old", 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	// return the stale value, and reload in the background
+	val, st := c.GetState("key1")
+	assert.Eq(t, "key1-old", val)
+	assert.Eq(t, lcache.StateStale, st)
+	assert.Eq(t, lcache.StateStale, c.State("key2"))
+	<-started
+	assert.Eq(t, "key1-old", c.Val("key1"))
+
+	close(release)
+	exec.wg.Wait()
+	assert.Eq(t, int32(1), atomic.LoadInt32(&calls))
+	val, st = c.GetState("key1")
+	assert.Eq(t, "key1-new", val)
+	assert.Eq(t, lcache.StateValid, st)
+
+	// items without TTL are not affected
+	c.Set("key3", "val3", 0)
+	assert.Eq(t, "val3", c.Val("key3"))
+}
+
+func TestWithLoader(t *testing.T) {
+	_, err := lcache.New().GetCtx(context.Background(), "key1")
+	assert.ErrIs(t, err, lcache.ErrNotFound)
+
+	errLoad := errors.New("load error")
+	var calls int32
+	c := lcache.New(lcache.WithLoader(func(ctx context.Context, key string) (any, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		if key == "bad" {
+			return nil, 0, errLoad
+		}
+		if key == "slow" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		return "val-" + key, time.Minute, nil
+	}))
+
+	// read-through on Get
+	val, ok := c.Get("key1")
+	assert.True(t, ok)
+	assert.Eq(t, "val-key1", val)
+	assert.Eq(t, "val-key1", c.Val("key1"))
+	assert.Eq(t, int32(1), atomic.LoadInt32(&calls))
+
+	val, err = c.GetCtx(context.Background(), "key2")
+	assert.NoErr(t, err)
+	assert.Eq(t, "val-key2", val)
+
+	_, ok = c.Get("bad")
+	assert.False(t, ok)
+	_, err = c.GetCtx(context.Background(), "bad")
+	assert.ErrIs(t, err, errLoad)
+
+	// waiting caller returns on ctx done
+	go c.Get("slow")
+	time.Sleep(5 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = c.GetCtx(ctx, "slow")
+	assert.ErrIs(t, err, context.DeadlineExceeded)
+}
+
+func TestCache_MGetOrLoad(t *testing.T) {
+	c := lcache.New()
+	c.Set("key1", "val1", 0)
+
+	var loadKeys []string
+	data, err := c.MGetOrLoad([]string{"key1", "key2", "key3"}, time.Minute, func(missing []string) (map[string]any, error) {
+		loadKeys = missing
+		return map[string]any{"key2": "val2"}, nil
+	})
+	assert.NoErr(t, err)
+	assert.Eq(t, []string{"key2", "key3"}, loadKeys)
+	assert.Eq(t, map[string]any{"key1": "val1", "key2": "val2"}, data)
+	assert.Eq(t, "val2", c.Val("key2"))
+
+	// all hit, loader not called
+	_, err = c.MGetOrLoad([]string{"key1", "key2"}, 0, func([]string) (map[string]any, error) {
+		panic("should not be called")
+	})
+	assert.NoErr(t, err)
+
+	errLoad := errors.New("load error")
+	data, err = c.MGetOrLoad([]string{"key1", "key4"}, 0, func([]string) (map[string]any, error) {
+		return nil, errLoad
+	})
+	assert.ErrIs(t, err, errLoad)
+	assert.Eq(t, map[string]any{"key1": "val1"}, data)
+}
+
+func TestWithMissFilter(t *testing.T) {
+	var calls int32
+	c := lcache.New(
+		lcache.WithMissFilter(100, 0.01, 50*time.Millisecond),
+		lcache.WithLoader(func(ctx context.Context, key string) (any, time.Duration, error) {
+			atomic.AddInt32(&calls, 1)
+			if key == "garbage" {
+				return nil, 0, lcache.ErrNotFound
+			}
+			return "val-" + key, 0, nil
+		}),
+	)
+
+	for i := 0; i < 3; i++ {
+		_, ok := c.Get("garbage")
+		assert.False(t, ok)
+	}
+	_, err := c.GetCtx(context.Background(), "garbage")
+	assert.ErrIs(t, err, lcache.ErrNotFound)
+	_, err = c.GetOrLoad("garbage", 0, func(key string) (any, error) {
+		return "loaded", nil
+	})
+	assert.ErrIs(t, err, lcache.ErrNotFound)
+	assert.Eq(t, int32(1), atomic.LoadInt32(&calls))
+
+	// the existing keys are not affected
+	assert.Eq(t, "val-key1", c.Val("key1"))
+	assert.Eq(t, int32(2), atomic.LoadInt32(&calls))
+
+	// reset
+	c.ResetMissFilter()
+	c.Get("garbage")
+	assert.Eq(t, int32(3), atomic.LoadInt32(&calls))
+
+	// forgotten after two rotations
+	time.Sleep(110 * time.Millisecond)
+	c.Get("garbage")
+	assert.Eq(t, int32(4), atomic.LoadInt32(&calls))
+
+	assert.Panics(t, func() {
+		lcache.WithMissFilter(100, 0, time.Minute)
+	})
+}
+
+// mapMissFilter exact filter for testing
+type mapMissFilter struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+func (f *mapMissFilter) Add(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys[key] = true
+}
+
+func (f *mapMissFilter) Test(key string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.keys[key]
+}
+
+func (f *mapMissFilter) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	clear(f.keys)
+}
+
+func TestWithCustomMissFilter(t *testing.T) {
+	var calls int32
+	mf := &mapMissFilter{keys: map[string]bool{}}
+	c := lcache.New(
+		// ignored by the custom filter
+		lcache.WithMissFilter(100, 0.01, time.Minute),
+		lcache.WithCustomMissFilter(mf),
+		lcache.WithLoader(func(ctx context.Context, key string) (any, time.Duration, error) {
+			atomic.AddInt32(&calls, 1)
+			return nil, 0, lcache.ErrNotFound
+		}),
+	)
+
+	app := c.Namespace("app")
+	for i := 0; i < 3; i++ {
+		_, ok := app.Get("garbage")
+		assert.False(t, ok)
+	}
+	assert.Eq(t, int32(1), atomic.LoadInt32(&calls))
+	// records the namespaced key
+	assert.True(t, mf.Test("app:garbage"))
+
+	c.ResetMissFilter()
+	assert.False(t, mf.Test("app:garbage"))
+	app.Get("garbage")
+	assert.Eq(t, int32(2), atomic.LoadInt32(&calls))
+}
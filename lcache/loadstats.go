@@ -0,0 +1,119 @@
+package lcache
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// loadSamples 记录最近加载耗时的样本数量，用于计算 P50/P99
+const loadSamples = 1024
+
+// LoadStats represents a snapshot of the loader statistics of GetOrLoad, GetOrLoadCtx,
+// the read-through loader(or Store) and the background refresh. see Cache.LoadStats
+type LoadStats struct {
+	// Loads number of the loader calls
+	Loads uint64
+	// Coalesced number of the calls that shared the result of an in-flight load
+	Coalesced uint64
+	// Errors number of the loader calls that returned an error or panicked, include ErrNotFound
+	Errors uint64
+	// P50, P99 the loader latency percentiles of the recent 1024 calls
+	P50, P99 time.Duration
+	// InFlight number of the keys currently being loaded
+	InFlight int
+	// LongestInFlight how long the oldest in-flight load has been running. eg: alert on stuck loaders
+	LongestInFlight time.Duration
+}
+
+// loadStats 加载调用的统计，使用独立的锁
+type loadStats struct {
+	mu        sync.Mutex
+	loads     uint64
+	coalesced uint64
+	errors    uint64
+	// 最近加载耗时的环形数组，loads 为写入位置
+	samples [loadSamples]time.Duration
+	// 正在加载的 key(包含命名空间前缀) -> 开始时间
+	loading map[string]time.Time
+}
+
+// LoadStats get the loader statistics snapshot of the cache.
+//
+// Usage:
+//
+//	st := c.LoadStats()
+//	if st.LongestInFlight > 30*time.Second {
+//		log.Println("stuck loaders:", c.InFlight())
+//	}
+func (c *Cache) LoadStats() LoadStats {
+	ls := &c.loadSt
+	ls.mu.Lock()
+	st := LoadStats{
+		Loads:     ls.loads,
+		Coalesced: ls.coalesced,
+		Errors:    ls.errors,
+		InFlight:  len(ls.loading),
+	}
+	n := int(min(ls.loads, loadSamples))
+	samples := make([]time.Duration, n)
+	copy(samples, ls.samples[:n])
+
+	now := time.Now()
+	for _, start := range ls.loading {
+		st.LongestInFlight = max(st.LongestInFlight, now.Sub(start))
+	}
+	ls.mu.Unlock()
+
+	if n > 0 {
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		st.P50 = samples[(n*50+99)/100-1]
+		st.P99 = samples[(n*99+99)/100-1]
+	}
+	return st
+}
+
+// InFlight get the keys currently being loaded, sorted by key. It is a debug accessor
+// for spotting the stuck loaders. The keys contain the namespace prefix.
+func (c *Cache) InFlight() []string {
+	c.loadSt.mu.Lock()
+	keys := make([]string, 0, len(c.loadSt.loading))
+	for key := range c.loadSt.loading {
+		keys = append(keys, key)
+	}
+	c.loadSt.mu.Unlock()
+
+	sort.Strings(keys)
+	return keys
+}
+
+// loadStart 记录 key 开始加载
+func (c *Cache) loadStart(fk string, start time.Time) {
+	ls := &c.loadSt
+	ls.mu.Lock()
+	if ls.loading == nil {
+		ls.loading = make(map[string]time.Time)
+	}
+	ls.loading[fk] = start
+	ls.mu.Unlock()
+}
+
+// loadEnd 记录 key 加载完成的耗时和结果
+func (c *Cache) loadEnd(fk string, start time.Time, failed bool) {
+	ls := &c.loadSt
+	ls.mu.Lock()
+	delete(ls.loading, fk)
+	ls.samples[ls.loads%loadSamples] = time.Since(start)
+	ls.loads++
+	if failed {
+		ls.errors++
+	}
+	ls.mu.Unlock()
+}
+
+// loadCoalesced 记录一次共享其他调用加载结果的调用
+func (c *Cache) loadCoalesced() {
+	c.loadSt.mu.Lock()
+	c.loadSt.coalesced++
+	c.loadSt.mu.Unlock()
+}
@@ -0,0 +1,99 @@
+package lcache_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/sflight"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_LoadStats(t *testing.T) {
+	c := lcache.New()
+	assert.Eq(t, lcache.LoadStats{}, c.LoadStats())
+	assert.Empty(t, c.InFlight())
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _ = c.GetOrLoad("key1", 0, func(string) (any, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+	}()
+	<-started
+
+	// the waiting callers are coalesced
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = c.Namespace("ns").GetOrLoad("key2", 0, func(string) (any, error) {
+				time.Sleep(10 * time.Millisecond)
+				return 2, nil
+			})
+			_, _ = c.GetOrLoad("key1", 0, func(string) (any, error) { return 0, nil })
+		}()
+	}
+	time.Sleep(5 * time.Millisecond)
+	assert.Eq(t, []string{"key1", "ns:key2"}, c.InFlight())
+	st := c.LoadStats()
+	assert.Eq(t, 2, st.InFlight)
+	assert.Gt(t, st.LongestInFlight, time.Duration(0))
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Empty(t, c.InFlight())
+
+	// errors
+	errLoad := errors.New("load error")
+	_, _ = c.GetOrLoad("key3", 0, func(string) (any, error) { return nil, errLoad })
+	_, _ = c.GetOrLoad("key4", 0, func(string) (any, error) { panic("oops") })
+
+	st = c.LoadStats()
+	assert.Eq(t, uint64(4), st.Loads)
+	assert.Eq(t, uint64(5), st.Coalesced)
+	assert.Eq(t, uint64(2), st.Errors)
+	assert.Eq(t, 0, st.InFlight)
+	assert.Eq(t, time.Duration(0), st.LongestInFlight)
+	assert.Gte(t, st.P99, 20*time.Millisecond)
+	assert.Gte(t, st.P99, st.P50)
+}
+
+func TestCache_LoadStats_flightGroup(t *testing.T) {
+	c := lcache.New(lcache.WithFlightGroup(sflight.New[any]()))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = c.GetOrLoad("key1", 0, func(string) (any, error) {
+				close(started)
+				<-release
+				return 1, nil
+			})
+		}()
+		if i == 0 {
+			<-started
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	assert.Eq(t, []string{"key1"}, c.InFlight())
+	close(release)
+	wg.Wait()
+
+	st := c.LoadStats()
+	assert.Eq(t, uint64(1), st.Loads)
+	assert.Eq(t, uint64(2), st.Coalesced)
+}
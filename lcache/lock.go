@@ -0,0 +1,118 @@
+package lcache
+
+import "time"
+
+// lock 获取写锁. 配置了 LockTimeout 时，超时未获取到返回 false
+func (c *Cache) lock() bool {
+	if c.mu.TryLock() {
+		return true
+	}
+	defer c.lockWaited(time.Now())
+
+	timeout := time.Duration(c.lockTimeout.Load())
+	if timeout <= 0 {
+		c.mu.Lock()
+		return true
+	}
+	return c.timedOut(tryLockUntil(c.mu.TryLock, timeout))
+}
+
+// unlock 释放写锁，然后调用持有锁期间记录的 OnSet 回调. see appendSet
+func (c *Cache) unlock() {
+	events := c.setEvents
+	c.setEvents = nil
+	c.mu.Unlock()
+
+	for _, e := range events {
+		c.onSet(e.key, e.val, e.ttl)
+	}
+}
+
+// rlock 获取读锁. 配置了 LockTimeout 时，超时未获取到返回 false
+func (c *Cache) rlock() bool {
+	if c.mu.TryRLock() {
+		return true
+	}
+	defer c.lockWaited(time.Now())
+
+	timeout := time.Duration(c.lockTimeout.Load())
+	if timeout <= 0 {
+		c.mu.RLock()
+		return true
+	}
+	return c.timedOut(tryLockUntil(c.mu.TryRLock, timeout))
+}
+
+// timedOut 记录获取锁超时的次数，返回是否获取到锁. see Stats.LockTimeouts
+func (c *Cache) timedOut(locked bool) bool {
+	if !locked {
+		c.lockTimeouts.Add(1)
+	}
+	return locked
+}
+
+// lockWaited 记录获取锁需要等待的次数和时间. see Stats.LockWaits
+func (c *Cache) lockWaited(start time.Time) {
+	c.lockWaits.Add(1)
+	c.lockWaitNs.Add(int64(time.Since(start)))
+}
+
+// tryLockUntil 循环尝试获取锁，直到成功或超时. 每次失败后等待时间指数增长(最大1ms)
+func tryLockUntil(tryFn func() bool, timeout time.Duration) bool {
+	if tryFn() {
+		return true
+	}
+
+	deadline := time.Now().Add(timeout)
+	wait := 10 * time.Microsecond
+	for {
+		time.Sleep(wait)
+		if tryFn() {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		if wait < time.Millisecond {
+			wait *= 2
+		}
+	}
+}
+
+// KeyLocker lock by key. eg: *llock.Keyed, *llock.Striped. see WithKeyLocker
+type KeyLocker interface {
+	// Lock the key, returns the unlock func.
+	Lock(key string) (unlock func())
+}
+
+// keyLockStripes 按 key 加锁使用的锁分片数量
+const keyLockStripes = 256
+
+// LockKey lock the key for serialize the read-modify-write sequences on it,
+// returns the unlock func. It does not block other operations on the cache.
+//
+// The builtin locks are striped by key hash, so different keys may share a lock.
+// Set a custom locker by WithKeyLocker.
+//
+// NOTE: do not lock multiple keys at the same time, it may deadlock.
+//
+// Usage:
+//
+//	unlock := c.LockKey("counter")
+//	defer unlock()
+//
+//	n, _ := lcache.TypedInCache[int](c, "counter")
+//	c.Set("counter", n+1, 0)
+func (c *Cache) LockKey(key string) (unlock func()) {
+	if nk, err := c.normKey(key); err == nil {
+		key = nk
+	}
+
+	if c.opt.KeyLocker != nil {
+		return c.opt.KeyLocker.Lock(c.nsKey(key))
+	}
+
+	mu := &c.keyLocks[xxh64(c.nsKey(key))%keyLockStripes]
+	mu.Lock()
+	return mu.Unlock
+}
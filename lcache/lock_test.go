@@ -0,0 +1,88 @@
+package lcache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_WithLockTimeout(t *testing.T) {
+	c := New(WithLockTimeout(20 * time.Millisecond))
+	c.Set("key", "val", 0)
+	assert.Eq(t, "val", c.Val("key"))
+
+	// simulate a slow operation holding the lock
+	c.mu.Lock()
+	start := time.Now()
+	assert.ErrIs(t, c.SetE("key", "val2", 0), ErrBusy)
+	assert.True(t, time.Since(start) < time.Second)
+
+	_, ok := c.Get("key")
+	assert.False(t, ok)
+	assert.ErrIs(t, c.LoadFile("not-exists.json"), ErrBusy)
+
+	// the writes without error result are dropped and counted
+	c.Set("key", "val3", 0)
+	assert.False(t, c.Delete("key"))
+	c.MSet(map[string]any{"key1": 1}, 0)
+	c.MDelete("key")
+	c.Clear()
+	c.mu.Unlock()
+
+	assert.Eq(t, uint64(8), c.Stats().LockTimeouts)
+	assert.Eq(t, "val", c.Val("key"))
+	assert.False(t, c.Has("key1"))
+
+	assert.NoErr(t, c.SetE("key", "val2", 0))
+	assert.Eq(t, "val2", c.Val("key"))
+}
+
+func TestCache_LockKey(t *testing.T) {
+	c := New()
+	c.Set("counter", 0, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := c.LockKey("counter")
+			defer unlock()
+
+			n, _ := TypedInCache[int](c, "counter")
+			c.Set("counter", n+1, 0)
+		}()
+	}
+	wg.Wait()
+	assert.Eq(t, 100, c.Val("counter"))
+
+	// not block other keys operations
+	unlock := c.LockKey("counter")
+	c.Set("other", 1, 0)
+	assert.Eq(t, 1, c.Val("other"))
+	unlock()
+}
+
+// testLocker records the locked keys
+type testLocker struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (l *testLocker) Lock(key string) func() {
+	l.mu.Lock()
+	l.keys = append(l.keys, key)
+	return l.mu.Unlock
+}
+
+func TestWithKeyLocker(t *testing.T) {
+	l := &testLocker{}
+	c := New(WithKeyLocker(l))
+
+	unlock := c.LockKey("key1")
+	unlock()
+	c.Namespace("ns").LockKey("key2")()
+	assert.Eq(t, []string{"key1", "ns:key2"}, l.keys)
+}
@@ -0,0 +1,109 @@
+package lcache
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// Manager a registry of named caches, for enumerate, monitor and flush them in one place.
+//
+// Usage:
+//
+//	lcache.Default().Register("users", lcache.New(lcache.WithCapacity(5000)))
+//	users, ok := lcache.Default().Get("users")
+//
+//	// on shutdown
+//	err := lcache.Default().CloseAll()
+type Manager struct {
+	mu     sync.RWMutex
+	caches map[string]*Cache
+}
+
+// defManager 默认的管理器实例
+var defManager = NewManager()
+
+// Default get the default Manager instance
+func Default() *Manager { return defManager }
+
+// NewManager create a new cache manager
+func NewManager() *Manager {
+	return &Manager{caches: make(map[string]*Cache)}
+}
+
+// Register the cache with name, will replace the exists one.
+func (m *Manager) Register(name string, c *Cache) {
+	m.mu.Lock()
+	m.caches[name] = c
+	m.mu.Unlock()
+}
+
+// Unregister the cache by name, returns false if not exists. It does not close the cache.
+func (m *Manager) Unregister(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.caches[name]
+	delete(m.caches, name)
+	return ok
+}
+
+// Get the cache by name
+func (m *Manager) Get(name string) (*Cache, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.caches[name]
+	return c, ok
+}
+
+// Names get the sorted names of all registered caches
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	names := make([]string, 0, len(m.caches))
+	for name := range m.caches {
+		names = append(names, name)
+	}
+	m.mu.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// Stats get the statistics of all registered caches. name => Stats
+func (m *Manager) Stats() map[string]Stats {
+	all := make(map[string]Stats)
+	for name, c := range m.snapshot() {
+		all[name] = c.Stats()
+	}
+	return all
+}
+
+// ClearAll clear the data of all registered caches
+func (m *Manager) ClearAll() {
+	for _, c := range m.snapshot() {
+		c.Clear()
+	}
+}
+
+// CloseAll close all registered caches, returns the joined errors. see Cache.Close
+func (m *Manager) CloseAll() error {
+	var errs []error
+	for _, c := range m.snapshot() {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// snapshot 复制已注册的缓存，避免持有锁时调用缓存的方法
+func (m *Manager) snapshot() map[string]*Cache {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	caches := make(map[string]*Cache, len(m.caches))
+	for name, c := range m.caches {
+		caches[name] = c
+	}
+	return caches
+}
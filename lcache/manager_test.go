@@ -0,0 +1,40 @@
+package lcache_test
+
+import (
+	"testing"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestManager(t *testing.T) {
+	m := lcache.NewManager()
+	c1, c2 := lcache.New(), lcache.New()
+	m.Register("users", c1)
+	m.Register("posts", c2)
+
+	c, ok := m.Get("users")
+	assert.True(t, ok)
+	assert.True(t, c == c1)
+	_, ok = m.Get("none")
+	assert.False(t, ok)
+	assert.Eq(t, []string{"posts", "users"}, m.Names())
+
+	c1.Set("key1", "val1", 0)
+	c1.Get("key1")
+	st := m.Stats()
+	assert.Len(t, st, 2)
+	assert.Eq(t, 1, st["users"].Len)
+	assert.Eq(t, uint64(1), st["users"].Hits)
+
+	m.ClearAll()
+	assert.Eq(t, 0, c1.Len())
+	assert.NoErr(t, m.CloseAll())
+
+	assert.True(t, m.Unregister("posts"))
+	assert.False(t, m.Unregister("posts"))
+	assert.Eq(t, []string{"users"}, m.Names())
+
+	assert.NotNil(t, lcache.Default())
+	assert.True(t, lcache.Default() == lcache.Default())
+}
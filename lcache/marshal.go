@@ -0,0 +1,40 @@
+package lcache
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// MarshalJSON implements json.Marshaler, output the same structure as SaveFile
+// with the JSON serializer. So the cache can be embedded in a larger struct.
+func (c *Cache) MarshalJSON() ([]byte, error) {
+	if !c.rlock() {
+		return nil, ErrBusy
+	}
+	defer c.mu.RUnlock()
+
+	return json.Marshal(c.snapshotData())
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replace the current data like LoadFile.
+// A zero value Cache will be initialized with the default options.
+func (c *Cache) UnmarshalJSON(data []byte) error {
+	if c.core == nil {
+		*c = *New()
+	}
+
+	if !c.lock() {
+		return ErrBusy
+	}
+	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return ErrFrozen
+	}
+
+	st := c.newLoadStage(LoadReplace)
+	if err := decodeJSONStream(bytes.NewReader(data), false, st.setGen, st.put); err != nil {
+		return err
+	}
+	st.commit()
+	return nil
+}
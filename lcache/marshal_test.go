@@ -0,0 +1,47 @@
+package lcache_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_MarshalJSON(t *testing.T) {
+	type state struct {
+		Name  string        `json:"name"`
+		Cache *lcache.Cache `json:"cache"`
+	}
+
+	c := lcache.New()
+	c.Set("key1", "val1", 0)
+	c.Set("key2", 2, time.Hour)
+	c.Set("expired", 3, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	bs, err := json.Marshal(state{Name: "test", Cache: c})
+	assert.NoErr(t, err)
+	assert.StrContains(t, string(bs), `"items":{`)
+	assert.NotContains(t, string(bs), "expired")
+
+	var st state
+	assert.NoErr(t, json.Unmarshal(bs, &st))
+	assert.Eq(t, "test", st.Name)
+	assert.Eq(t, 2, st.Cache.Len())
+	assert.Eq(t, "val1", st.Cache.Val("key1"))
+	assert.Eq(t, float64(2), st.Cache.Val("key2"))
+	ttl, ok := st.Cache.TTL("key2")
+	assert.True(t, ok)
+	assert.True(t, ttl > time.Minute)
+
+	// replace the current data
+	c2 := lcache.New()
+	c2.Set("old", 1, 0)
+	assert.NoErr(t, json.Unmarshal(bs, c2))
+	assert.False(t, c2.Has("old"))
+
+	c2.Freeze()
+	assert.ErrIs(t, json.Unmarshal(bs, c2), lcache.ErrFrozen)
+}
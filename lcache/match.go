@@ -0,0 +1,80 @@
+package lcache
+
+import "regexp"
+
+// KeysMatch get all valid keys matched the glob pattern, like the Redis KEYS command.
+//
+// Pattern syntax:
+//
+//   - '*' matches any sequence of characters, include empty
+//   - '?' matches any single character
+//   - '\' escapes the next character
+//
+// Usage:
+//
+//	keys := c.KeysMatch("user:*:profile")
+//
+// NOTE: it will traverse all data, the time complexity is O(N)
+func (c *Cache) KeysMatch(pattern string) []string {
+	return c.keys(func(key string, _ *Item) bool {
+		return globMatch(pattern, key)
+	})
+}
+
+// KeysRegexp get all valid keys matched the regexp.
+//
+// NOTE: it will traverse all data, the time complexity is O(N)
+func (c *Cache) KeysRegexp(re *regexp.Regexp) []string {
+	return c.keys(func(key string, _ *Item) bool {
+		return re.MatchString(key)
+	})
+}
+
+// GlobMatch reports whether s matches the glob pattern. see KeysMatch for the pattern syntax
+func GlobMatch(pattern, s string) bool { return globMatch(pattern, s) }
+
+// globMatch 检查 s 是否匹配 glob 模式. 支持 '*', '?' 和 '\' 转义，按字节匹配
+func globMatch(pattern, s string) bool {
+	// 回溯位置: 最近一个 '*' 之后的模式位置，以及其匹配到的 s 位置
+	starP, starS := -1, 0
+	p, i := 0, 0
+	for i < len(s) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '*':
+				starP, starS = p+1, i
+				p++
+				continue
+			case '?':
+				p++
+				i++
+				continue
+			case '\\':
+				if p+1 < len(pattern) && pattern[p+1] == s[i] {
+					p += 2
+					i++
+					continue
+				}
+			default:
+				if pattern[p] == s[i] {
+					p++
+					i++
+					continue
+				}
+			}
+		}
+
+		// 不匹配时回溯，让上一个 '*' 多匹配一个字符
+		if starP < 0 {
+			return false
+		}
+		starS++
+		p, i = starP, starS
+	}
+
+	// 剩余的模式只能是 '*'
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
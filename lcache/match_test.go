@@ -0,0 +1,47 @@
+package lcache
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*", "", true},
+		{"*", "abc", true},
+		{"user:*", "user:1", true},
+		{"user:*", "users:1", false},
+		{"user:*:profile", "user:1/2:profile", true},
+		{"user:?", "user:12", false},
+		{"user:??", "user:12", true},
+		{"*a*b", "xaybzb", true},
+		{"a*b*c", "abcbc", true},
+		{"a*b*c", "abcbd", false},
+		{`a\*`, "a*", true},
+		{`a\*`, "ab", false},
+		{"", "", true},
+		{"", "a", false},
+	}
+
+	for _, tt := range tests {
+		assert.Eq(t, tt.want, globMatch(tt.pattern, tt.s), tt.pattern+" -> "+tt.s)
+	}
+}
+
+func TestCache_KeysMatch(t *testing.T) {
+	c := New()
+	c.MSet(map[string]any{"user:1": 1, "user:2": 2, "order:1": 3}, 0)
+
+	assert.Len(t, c.KeysMatch("user:*"), 2)
+	assert.Eq(t, []string{"order:1"}, c.KeysMatch("*rder:?"))
+	assert.Empty(t, c.KeysMatch("none*"))
+	assert.Len(t, c.KeysRegexp(regexp.MustCompile(`^\w+:1$`)), 2)
+
+	// namespace view
+	assert.Eq(t, []string{"1"}, c.Namespace("order").KeysMatch("*"))
+}
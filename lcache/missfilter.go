@@ -0,0 +1,123 @@
+package lcache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gookit/ext/internal/bloombits"
+)
+
+// MissFilter a custom filter of the missing keys. eg: bloom.MissFilter. see WithCustomMissFilter
+type MissFilter interface {
+	// Add record a missing key
+	Add(key string)
+	// Test check the key may be missing
+	Test(key string) bool
+	// Reset clear the recorded keys, called by Cache.ResetMissFilter
+	Reset()
+}
+
+// missFilter 记录不存在的 key 的布隆过滤器. 使用两个过滤器轮换，限制误判率并让旧记录过期
+type missFilter struct {
+	mu sync.Mutex
+	// 每个过滤器的位数和 hash 函数数量
+	bits, hashes uint64
+	capacity     int
+	fpRate       float64
+	rotate       time.Duration
+	// 当前和上一个过滤器，检查时两个都检查
+	cur, prev bloombits.Bits
+	count     int
+	rotatedAt time.Time
+}
+
+// newMissFilter 按容量和误判率计算过滤器的位数和 hash 函数数量
+func newMissFilter(capacity int, fpRate float64, rotate time.Duration) *missFilter {
+	bits, hashes := bloombits.Estimate(capacity, fpRate)
+	return &missFilter{
+		bits:      bits,
+		hashes:    hashes,
+		capacity:  capacity,
+		fpRate:    fpRate,
+		rotate:    rotate,
+		cur:       bloombits.Make(bits),
+		rotatedAt: time.Now(),
+	}
+}
+
+// maybeRotate 超过轮换时间或当前过滤器已满时轮换 (已加锁)
+func (f *missFilter) maybeRotate() {
+	elapsed := time.Since(f.rotatedAt)
+	if (f.rotate > 0 && elapsed >= f.rotate) || f.count >= f.capacity {
+		f.prev, f.cur = f.cur, bloombits.Make(f.bits)
+		// 超过两个轮换时间未使用，上一个过滤器也已过期
+		if f.rotate > 0 && elapsed >= 2*f.rotate {
+			f.prev = nil
+		}
+		f.count = 0
+		f.rotatedAt = time.Now()
+	}
+}
+
+// Add 记录不存在的 key
+func (f *missFilter) Add(key string) {
+	h1, h2 := bloombits.Hash(key)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.maybeRotate()
+	f.cur.Set(f.bits, f.hashes, h1, h2)
+	f.count++
+}
+
+// Test 检查 key 是否可能不存在
+func (f *missFilter) Test(key string) bool {
+	h1, h2 := bloombits.Hash(key)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.maybeRotate()
+	return f.cur.Test(f.bits, f.hashes, h1, h2) || (f.prev != nil && f.prev.Test(f.bits, f.hashes, h1, h2))
+}
+
+// Reset 清空两个过滤器
+func (f *missFilter) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cur, f.prev = bloombits.Make(f.bits), nil
+	f.count = 0
+	f.rotatedAt = time.Now()
+}
+
+// ResetMissFilter clear the miss filter, eg: after the missing keys are created in the backend.
+// see WithMissFilter, WithCustomMissFilter
+func (c *Cache) ResetMissFilter() {
+	if mf := c.opt.MissFilter; mf != nil {
+		mf.Reset()
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.initMissFilter()
+}
+
+// loadMissFilter 获取使用的过滤器，优先使用自定义的过滤器. 未启用时返回 nil
+func (c *Cache) loadMissFilter() MissFilter {
+	if c.opt.MissFilter != nil {
+		return c.opt.MissFilter
+	}
+	if mf := c.missFilter.Load(); mf != nil {
+		return mf
+	}
+	return nil
+}
+
+// initMissFilter 根据配置创建过滤器 (已加锁)
+func (c *Cache) initMissFilter() {
+	if c.opt.MissFilterSize <= 0 || c.opt.MissFilter != nil {
+		c.missFilter.Store(nil)
+		return
+	}
+	c.missFilter.Store(newMissFilter(c.opt.MissFilterSize, c.opt.MissFilterFPRate, c.opt.MissFilterRotate))
+}
@@ -0,0 +1,125 @@
+package lcache
+
+import "strings"
+
+// Namespace returns a view of the cache, it transparently prefixes keys with "prefix:".
+//
+// The view shares the data, options and lock with the cache. Keys, Len and Clear of
+// the view are scoped to the namespace, other methods(eg: SaveFile) act on the whole cache.
+// The scoped methods scan all keys of the cache, and Configure on a view panics.
+//
+// Usage:
+//
+//	users := c.Namespace("users")
+//	users.Set("1", user, 0) // stored as "users:1"
+//	users.Keys()            // ["1"]
+//
+// NOTE: hashed long keys(see WithKeyHashing) do not contain the prefix, will not be listed by Keys.
+func (c *Cache) Namespace(prefix string) *Cache {
+	return &Cache{core: c.core, ns: c.ns + prefix + ":"}
+}
+
+// nsKey 添加命名空间前缀
+func (c *Cache) nsKey(key string) string {
+	if c.ns == "" {
+		return key
+	}
+	return c.ns + key
+}
+
+// normKey 使用 KeyFunc 规范化 key，未配置时返回原 key. see WithKeyFunc
+func (c *Cache) normKey(key string) (string, error) {
+	if c.opt.KeyFunc == nil {
+		return key, nil
+	}
+	return c.opt.KeyFunc(key)
+}
+
+// nsKeys 获取命名空间中所有实际存储的 key (不加锁)
+func (c *Cache) nsKeys() []string {
+	var keys []string
+	for key := range c.items {
+		if strings.HasPrefix(key, c.ns) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// RenameNamespace atomically re-prefixes all keys with prefix oldNs to newNs,
+// returns the number of renamed keys.
+//
+// Existing keys in newNs are removed first, so a freshly warmed namespace can
+// replace the live one instantly(blue/green dataset swap). The LRU order is kept.
+// Nothing is changed if there is no key in oldNs.
+//
+// Returns ErrBadNamespace if oldNs is empty, or one of the prefixes contains the other(eg: "users"
+// and "users_v2"), the keys of both namespaces cannot be distinguished.
+//
+// NOTE: hashed long keys(see WithKeyHashing) do not contain the prefix, will not be renamed.
+func (c *Cache) RenameNamespace(oldNs, newNs string) (int, error) {
+	if oldNs == "" {
+		return 0, ErrBadNamespace
+	}
+	oldNs, newNs = c.nsKey(oldNs), c.nsKey(newNs)
+	if strings.HasPrefix(oldNs, newNs) || strings.HasPrefix(newNs, oldNs) {
+		return 0, ErrBadNamespace
+	}
+
+	if !c.lock() {
+		return 0, ErrBusy
+	}
+	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return 0, ErrFrozen
+	}
+
+	n := c.renameNamespace(oldNs, newNs)
+	if n > 0 {
+		_ = c.appendAOF(aofOpRename, oldNs, newNs, 0)
+	}
+	return n, nil
+}
+
+// renameNamespace 内部重命名方法 (不加锁)
+func (c *Cache) renameNamespace(oldNs, newNs string) int {
+	var oldKeys []string
+	moving := make(map[string]bool)
+	for key := range c.items {
+		if strings.HasPrefix(key, oldNs) {
+			oldKeys = append(oldKeys, key)
+			moving[key] = true
+		}
+	}
+	if len(oldKeys) == 0 {
+		return 0
+	}
+
+	// 删除目标命名空间中的旧数据
+	for key := range c.items {
+		if strings.HasPrefix(key, newNs) && !moving[key] {
+			c.removeElement(key, ReasonDeleted)
+		}
+	}
+
+	for _, key := range oldKeys {
+		newKey := newNs + key[len(oldNs):]
+		it := c.items[key]
+		c.items[newKey] = it
+		delete(c.items, key)
+		c.removeIndexes(key)
+		c.updateIndexes(newKey, it)
+		c.renameSlot(key, newKey)
+		if _, ok := c.pinned[key]; ok {
+			c.pinned[newKey] = struct{}{}
+			delete(c.pinned, key)
+		}
+
+		if elem, ok := c.lruMap[key]; ok {
+			elem.Value = newKey
+			c.lruMap[newKey] = elem
+			delete(c.lruMap, key)
+		}
+	}
+	return len(oldKeys)
+}
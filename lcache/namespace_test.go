@@ -0,0 +1,79 @@
+package lcache_test
+
+import (
+	"testing"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_RenameNamespace(t *testing.T) {
+	c := lcache.New()
+	c.Set("users:1", "old1", 0)
+	c.Set("users:2", "old2", 0)
+	c.Set("users_v2:1", "new1", 0)
+	c.Set("users_v2:3", "new3", 0)
+	c.Set("other", "val", 0)
+
+	n, err := c.RenameNamespace("users_v2:", "users:")
+	assert.NoErr(t, err)
+	assert.Eq(t, 2, n)
+	assert.Eq(t, 3, c.Len())
+	assert.Eq(t, "new1", c.Val("users:1"))
+	assert.Eq(t, "new3", c.Val("users:3"))
+	assert.False(t, c.Has("users:2"))
+	assert.False(t, c.Has("users_v2:1"))
+	assert.Eq(t, "val", c.Val("other"))
+
+	// the target is kept if the source is empty
+	n, err = c.RenameNamespace("not-exists:", "users:")
+	assert.NoErr(t, err)
+	assert.Eq(t, 0, n)
+	assert.Eq(t, 3, c.Len())
+	assert.Eq(t, "new1", c.Val("users:1"))
+
+	for _, pair := range [][2]string{{"", "users:"}, {"users", "users_v2"}, {"users:", "users:"}, {"users:", ""}} {
+		_, err = c.RenameNamespace(pair[0], pair[1])
+		assert.ErrIs(t, err, lcache.ErrBadNamespace)
+	}
+	assert.Eq(t, 3, c.Len())
+}
+
+func TestCache_Namespace(t *testing.T) {
+	c := lcache.New()
+	c.Set("1", "root", 0)
+
+	users := c.Namespace("users")
+	users.Set("1", "user1", 0)
+	users.MSet(map[string]any{"2": "user2", "3": "user3"}, 0)
+
+	assert.Eq(t, "root", c.Val("1"))
+	assert.Eq(t, "user1", users.Val("1"))
+	assert.Eq(t, "user1", c.Val("users:1"))
+	assert.Eq(t, map[string]any{"2": "user2", "4": nil}, users.MGet("2", "4"))
+	assert.True(t, users.Has("3"))
+	assert.Eq(t, lcache.StateValid, users.State("3"))
+
+	assert.Eq(t, 4, c.Len())
+	assert.Eq(t, 3, users.Len())
+	assert.Len(t, users.Keys(), 3)
+	assert.Contains(t, users.Keys(), "2")
+
+	// nested namespace
+	admins := users.Namespace("admin")
+	admins.Set("1", "admin1", 0)
+	assert.Eq(t, "admin1", c.Val("users:admin:1"))
+	assert.Eq(t, 4, users.Len())
+
+	assert.True(t, users.Delete("3"))
+	users.Clear()
+	assert.Eq(t, 0, users.Len())
+	assert.Eq(t, 1, c.Len())
+	assert.Eq(t, "root", c.Val("1"))
+
+	// the options are shared with the parent cache
+	assert.Panics(t, func() {
+		users.Configure(lcache.WithCapacity(10))
+	})
+	assert.Eq(t, 1000, c.Stats().Capacity)
+}
@@ -0,0 +1,739 @@
+package lcache
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gookit/goutil/x/stdio"
+)
+
+// snapshot 持久化到文件的数据结构
+type snapshot struct {
+	// Gen 保存时的缓存代数
+	Gen   uint64           `json:"gen"`
+	Items map[string]*Item `json:"items"`
+	// Order 数据项的 key，从最久未使用到最近使用. 保存时按此顺序写入数据项，加载时即可还原 LRU 顺序
+	Order []string `json:"order,omitempty"`
+}
+
+// orderedKeys 按 Order 的顺序返回所有数据项的 key. 不在 Order 中的 key 按字典序排在前面，视为最久未使用
+func (s *snapshot) orderedKeys() []string {
+	inOrder := make(map[string]bool, len(s.Order))
+	for _, key := range s.Order {
+		if _, ok := s.Items[key]; ok {
+			inOrder[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(s.Items))
+	for key := range s.Items {
+		if !inOrder[key] {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range s.Order {
+		if inOrder[key] {
+			keys = append(keys, key)
+			// 忽略重复的 key
+			delete(inOrder, key)
+		}
+	}
+	return keys
+}
+
+// add 按读取的顺序添加一条数据项
+func (s *snapshot) add(key string, it *Item) error {
+	if s.Items == nil {
+		s.Items = make(map[string]*Item)
+	}
+	s.Items[key] = it
+	s.Order = append(s.Order, key)
+	return nil
+}
+
+// MarshalJSON 按 LRU 顺序输出 "items" 中的数据项，不再单独输出 "order".
+// 流式加载时按读取的顺序写入，即可还原 LRU 顺序
+func (s *snapshot) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`{"gen":`)
+	buf.WriteString(strconv.FormatUint(s.Gen, 10))
+	buf.WriteString(`,"items":{`)
+	for i, key := range s.orderedKeys() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		kb, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		ib, err := json.Marshal(s.Items[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+		buf.Write(ib)
+	}
+	buf.WriteString("}}")
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON 数据项的顺序即为 LRU 顺序. see MarshalJSON
+func (s *snapshot) UnmarshalJSON(data []byte) error {
+	*s = snapshot{Items: make(map[string]*Item)}
+	return decodeJSONStream(bytes.NewReader(data), false, func(gen uint64) { s.Gen = gen }, s.add)
+}
+
+// serializer 获取序列化器. 优先使用 SerializerObj
+func (c *Cache) serializer() (Serializer, error) {
+	if c.opt.SerializerObj != nil {
+		return c.opt.SerializerObj, nil
+	}
+	return LookupSerializer(c.opt.Serializer)
+}
+
+// serializerName 获取记录到快照文件头的序列化器名称.
+// 使用 SerializerObj 时为其注册的名称，未注册时为其类型名
+func (c *Cache) serializerName() string {
+	if c.opt.SerializerObj == nil {
+		return c.opt.Serializer
+	}
+	if name, ok := serializers.NameOf(c.opt.SerializerObj); ok {
+		return name
+	}
+	return fmt.Sprintf("%T", c.opt.SerializerObj)
+}
+
+// SaveFile Save the cache data to a file.
+//
+// The data is written to "<filename>.tmp" first and then renamed to filename,
+// so the previous snapshot survives a failed or interrupted write.
+//
+// The LRU order and the hit counts of the items are also saved, so LoadFile restores
+// the eviction order and HotKeys of the cache.
+func (c *Cache) SaveFile(filename string) error {
+	err := c.saveFile(filename)
+
+	c.saveMu.Lock()
+	c.lastSaveAt, c.lastSaveErr = time.Now(), err
+	c.saveMu.Unlock()
+	return err
+}
+
+func (c *Cache) saveFile(filename string) error {
+	if !c.rlock() {
+		return ErrBusy
+	}
+	defer c.mu.RUnlock()
+
+	data := c.snapshotData()
+	if len(data.Items) == 0 {
+		return nil
+	}
+
+	serializer, err := c.serializer()
+	if err != nil {
+		return err
+	}
+
+	return c.persistProvider().Snapshot(filename, func(w io.Writer) error {
+		return c.writeSnapshot(w, serializer, data)
+	})
+}
+
+// snapshotData 准备序列化数据，剔除已过期的、剩余TTL不足 SaveMinTTL 的 和 SaveFilter 返回 false 的 (不加锁).
+// 开启 LRU 时，按从最久未使用到最近使用的顺序记录 key 到 Order
+func (c *Cache) snapshotData() *snapshot {
+	data := &snapshot{Gen: c.gen, Items: make(map[string]*Item)}
+	nowUm := time.Now().UnixMilli()
+	minTTL := c.opt.SaveMinTTL.Milliseconds()
+	for k, v := range c.items {
+		if c.invalid(v, nowUm) {
+			continue
+		}
+		if minTTL > 0 && v.Exp > 0 && v.Exp-nowUm < minTTL {
+			continue
+		}
+
+		// 复制一份并记录已注册类型的名称和命中次数，避免修改缓存中的数据.
+		// NOTE: 关闭 LRU 时 hits 会在读锁下更新，不能直接复制 *v
+		it := &Item{Val: v.Val, Exp: v.Exp, Gen: v.Gen, Key: v.Key, Typ: typeName(v.Val),
+			Hits: atomic.LoadUint64(&v.hits), Grp: v.groupName()}
+		if c.opt.SaveFilter != nil && !c.opt.SaveFilter(cmp.Or(v.Key, k), *it) {
+			continue
+		}
+		data.Items[k] = it
+	}
+
+	if !c.opt.DisableLRU {
+		data.Order = make([]string, 0, len(data.Items))
+		for elem := c.lruList.Back(); elem != nil; elem = elem.Prev() {
+			if key := elem.Value.(string); data.Items[key] != nil {
+				data.Order = append(data.Order, key)
+			}
+		}
+	}
+	return data
+}
+
+// LoadMode the mode for LoadFile handle the existing data
+type LoadMode uint8
+
+const (
+	// LoadReplace clear the current data before load. it is default mode.
+	LoadReplace LoadMode = iota
+	// LoadMerge merge loaded items into current data, keep the existing item on conflict.
+	LoadMerge
+	// LoadMergeOverwrite merge loaded items into current data, overwrite the existing item on conflict.
+	LoadMergeOverwrite
+)
+
+// LoadFile Recover cache data from file load.
+//
+// Default will clear the current data before load, can use LoadMerge, LoadMergeOverwrite
+// to merge loaded items into current data. The loaded items are more recently used
+// than the existing ones, in the LRU order recorded in the file.
+//
+// The JSON, gob and lcbin snapshots are decoded item by item, without reading the whole file
+// into memory first. The items are staged while decoding and applied after the whole file is
+// decoded and verified, so the cache is unchanged if the file is corrupted or fails to decode.
+// LoadReplace swaps the staged data in, the merge modes move the staged items into the cache.
+//
+// LoadReplace takes the generation of the file if it is newer. The merge modes keep the
+// generation of the cache, the merged items belong to it. see BumpGeneration
+//
+// Usage:
+//
+//	err := c.LoadFile("cache.json", lcache.LoadMerge)
+func (c *Cache) LoadFile(filename string, mode ...LoadMode) error {
+	return c.load(mode, func(read func(r io.Reader) error) error {
+		return c.persistProvider().Restore(filename, read)
+	})
+}
+
+// LoadFS load the cache data from a snapshot file in fsys, eg: the warm-start data embedded
+// into the binary by go:embed, or read from a zip file. The mode is same as LoadFile.
+//
+// Usage:
+//
+//	//go:embed data/warm.snap
+//	var warmFS embed.FS
+//
+//	err := c.LoadFS(warmFS, "data/warm.snap")
+func (c *Cache) LoadFS(fsys fs.FS, name string, mode ...LoadMode) error {
+	return c.load(mode, func(read func(r io.Reader) error) error {
+		file, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer stdio.SafeClose(file)
+		return read(file)
+	})
+}
+
+// load 加锁后通过 open 读取快照并加载
+func (c *Cache) load(mode []LoadMode, open func(read func(r io.Reader) error) error) error {
+	if !c.lock() {
+		return ErrBusy
+	}
+	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return ErrFrozen
+	}
+
+	serializer, err := c.serializer()
+	if err != nil {
+		return err
+	}
+
+	loadMode := LoadReplace
+	if len(mode) > 0 {
+		loadMode = mode[0]
+	}
+
+	return open(func(r io.Reader) error {
+		return c.loadSnapshot(r, serializer, loadMode)
+	})
+}
+
+// loadSnapshot 流式解码快照，数据项逐条写入暂存，全部解码并校验成功后再提交到缓存 (不加锁).
+// 快照损坏或解码出错时缓存保持不变
+func (c *Cache) loadSnapshot(src io.Reader, serializer Serializer, loadMode LoadMode) error {
+	st := c.newLoadStage(loadMode)
+	if err := c.decodeSnapshot(src, serializer, st.setGen, st.put); err != nil {
+		return err
+	}
+	st.commit()
+	return nil
+}
+
+// decodeSnapshot 校验并解码快照，不修改缓存. onGen 在数据项之前调用，
+// 数据项按从最久未使用到最近使用的顺序传给 onItem，onItem 返回错误时停止解码.
+// payload 的 crc32 在解码的同时计算，解码完成后校验
+func (c *Cache) decodeSnapshot(src io.Reader, serializer Serializer, onGen func(gen uint64),
+	onItem func(key string, it *Item) error) error {
+	r, version, verify, err := c.checkSnapshot(src)
+	if err != nil {
+		return err
+	}
+	return verify(c.decodePayload(r, version, serializer, onGen, onItem))
+}
+
+// decodePayload 解码快照的 payload. JSON、gob 和 lcbin 快照使用流式解码，逐条读取数据项，
+// 其他序列化器需要先解码整个快照
+func (c *Cache) decodePayload(src io.Reader, version byte, serializer Serializer, onGen func(gen uint64),
+	onItem func(key string, it *Item) error) error {
+	r, closeFn, err := c.payloadReader(src, version, serializer)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	switch s := serializer.(type) {
+	case JSONSerializer:
+		return decodeJSONStream(r, version == 0, onGen, onItem)
+	case LCBinSerializer:
+		return s.stream(r, onGen, onItem)
+	case GobSerializer:
+		// 版本 2 开始 gob 快照逐条编码数据项
+		if version >= 2 {
+			return decodeGobStream(r, onGen, onItem)
+		}
+	}
+
+	var data snapshot
+	if err = serializer.DecodeFrom(r, &data); err != nil {
+		return err
+	}
+
+	onGen(data.Gen)
+	for _, key := range data.orderedKeys() {
+		if err = onItem(key, data.Items[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadStage 加载快照时暂存解码的数据项. 数据项逐条写入暂存的缓存实例，不会先解码出完整的快照，
+// 全部解码并校验成功后再提交到缓存，出错时直接丢弃
+type loadStage struct {
+	c     *Cache
+	tmp   *Cache
+	mode  LoadMode
+	nowUm int64
+}
+
+// newLoadStage 创建加载暂存 (不加锁). 暂存使用缓存的选项，但不调用缓存的删除回调:
+// 超出容量在暂存时就被淘汰的数据项，没有写入过缓存
+func (c *Cache) newLoadStage(mode LoadMode) *loadStage {
+	tmp := newCache()
+	tmp.opt = c.opt
+	tmp.opt.OnEvicted, tmp.opt.OnRemoved, tmp.opt.OnExpired = nil, nil, nil
+	tmp.gen, tmp.liveOn = c.gen, c.liveOn
+	return &loadStage{c: c, tmp: tmp, mode: mode, nowUm: time.Now().UnixMilli()}
+}
+
+// setGen 使用快照的代数. 合并模式下保留当前的代数，避免已存在的数据失效
+func (s *loadStage) setGen(gen uint64) {
+	if s.mode == LoadReplace && gen > s.tmp.gen {
+		s.tmp.gen = gen
+	}
+}
+
+// put 还原值的类型并暂存一条数据项. 跳过已过期或属于旧代数的，避免加载即过期
+func (s *loadStage) put(key string, it *Item) error {
+	if it.isExpired1(s.nowUm) {
+		return nil
+	}
+	if err := restoreType(it); err != nil {
+		return fmt.Errorf("lcache: restore value type for key %q: %w", key, err)
+	}
+
+	// 合并的数据使用当前的代数，之后 BumpGeneration 时一起失效
+	if s.mode != LoadReplace {
+		it.Gen = min(it.Gen, s.tmp.gen)
+	}
+	if s.tmp.invalid(it, s.nowUm) {
+		return nil
+	}
+
+	it.hits, it.Hits = it.Hits, 0
+	if it.Grp != "" {
+		it.grp, it.Grp = s.c.loadGroup(it.Grp), ""
+	}
+	s.tmp.putItem(key, it)
+	return nil
+}
+
+// commit 提交暂存的数据项 (不加锁). LoadReplace 直接交换暂存的数据，
+// 合并模式按 LRU 顺序移入缓存，加载的数据项比已存在的更近使用
+func (s *loadStage) commit() {
+	c := s.c
+	if s.mode == LoadReplace {
+		c.gen = s.tmp.gen
+		c.swapData(s.tmp.core)
+		return
+	}
+
+	s.tmp.eachOrdered(func(key string, it *Item) {
+		// 合并模式下保留已存在的有效数据
+		if s.mode == LoadMerge {
+			if old, ok := c.items[key]; ok && !c.invalid(old, s.nowUm) {
+				return
+			}
+		}
+		c.putItem(key, it)
+	})
+}
+
+// decodeJSONStream 流式解码 JSON 快照，逐条读取数据项，避免先将整个 payload 读取到内存.
+// legacy 为 true 时兼容最初的无文件头格式: 顶层即为 key 到数据项的映射.
+// 数据项的顺序即为 LRU 顺序，旧版本快照中单独记录的 "order" 会被忽略
+func decodeJSONStream(r io.Reader, legacy bool, onGen func(gen uint64), onItem func(key string, it *Item) error) error {
+	dec := json.NewDecoder(r)
+	if err := expectJSONDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok {
+		case "gen":
+			var gen uint64
+			if err = dec.Decode(&gen); err == nil {
+				onGen(gen)
+			}
+		case "items":
+			err = decodeJSONItems(dec, onItem)
+		default:
+			if legacy {
+				it := new(Item)
+				if err = dec.Decode(it); err == nil {
+					err = onItem(tok.(string), it)
+				}
+				break
+			}
+
+			// 跳过未知字段
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return expectJSONDelim(dec, '}')
+}
+
+// decodeJSONItems 逐条解码 "items" 中的数据项
+func decodeJSONItems(dec *json.Decoder, onItem func(key string, it *Item) error) error {
+	tok, err := dec.Token()
+	if err != nil || tok == nil { // "items": null
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return fmt.Errorf("lcache: invalid snapshot items, unexpected token %v", tok)
+	}
+
+	for dec.More() {
+		if tok, err = dec.Token(); err != nil {
+			return err
+		}
+
+		it := new(Item)
+		if err = dec.Decode(it); err != nil {
+			return err
+		}
+		if err = onItem(tok.(string), it); err != nil {
+			return err
+		}
+	}
+	return expectJSONDelim(dec, '}')
+}
+
+func expectJSONDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("lcache: invalid snapshot, expect %q but got %v", delim, tok)
+	}
+	return nil
+}
+
+//
+// ----- snapshot file header -----
+//
+
+// snapshotMagic 快照文件头的魔数
+const snapshotMagic = "LCSF"
+
+// snapshotVersion 当前快照文件格式版本. 版本 2: gob 快照逐条编码数据项. see encodeGobStream
+// 版本 3: 加密的快照分块加密，加载时流式解密. see cryptWriter
+const snapshotVersion byte = 3
+
+// maxSerializerName 文件头中序列化器名称的最大长度
+const maxSerializerName = 255
+
+// snapshotHeader 快照文件头. 格式:
+//
+//	magic(4) | version(1) | serializer name len(1) | serializer name | entry count(4) | crc32(4)
+//
+// 之后是序列化(可能已压缩、加密)的数据(payload). crc32 为 payload 的校验和
+type snapshotHeader struct {
+	Version    byte
+	Serializer string
+	Count      uint32
+	CRC        uint32
+}
+
+// writeSnapshot 写入文件头和数据. 写入文件时完成后回填 payload 的 crc32，否则先在内存中序列化 payload
+func (c *Cache) writeSnapshot(w io.Writer, serializer Serializer, data *snapshot) error {
+	name := c.serializerName()
+	if len(name) > maxSerializerName {
+		return fmt.Errorf("lcache: the serializer name %q is too long, max %d bytes", name, maxSerializerName)
+	}
+
+	hdr := make([]byte, 0, 14+len(name))
+	hdr = append(hdr, snapshotMagic...)
+	hdr = append(hdr, snapshotVersion, byte(len(name)))
+	hdr = append(hdr, name...)
+	hdr = binary.BigEndian.AppendUint32(hdr, uint32(len(data.Items)))
+
+	if file, ok := w.(*os.File); ok {
+		if start, err := file.Seek(0, io.SeekCurrent); err == nil {
+			return c.writeSnapshotFile(file, start, hdr, serializer, data)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := c.encodeTo(&buf, serializer, data); err != nil {
+		return err
+	}
+	hdr = binary.BigEndian.AppendUint32(hdr, crc32.ChecksumIEEE(buf.Bytes()))
+	if _, err := w.Write(hdr); err != nil {
+		return err
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// writeSnapshotFile 流式写入 payload 到文件，完成后在 start+len(hdr) 处回填 crc32
+func (c *Cache) writeSnapshotFile(file *os.File, start int64, hdr []byte, serializer Serializer, data *snapshot) error {
+	hdr = binary.BigEndian.AppendUint32(hdr, 0) // crc32 占位
+	if _, err := file.Write(hdr); err != nil {
+		return err
+	}
+
+	hash := crc32.NewIEEE()
+	if err := c.encodeTo(io.MultiWriter(file, hash), serializer, data); err != nil {
+		return err
+	}
+
+	_, err := file.WriteAt(binary.BigEndian.AppendUint32(nil, hash.Sum32()), start+int64(len(hdr)-4))
+	return err
+}
+
+// readSnapshotHeader 读取快照文件头. 不是以魔数开头的旧格式文件返回 nil，不消耗数据
+func readSnapshotHeader(br *bufio.Reader) (*snapshotHeader, error) {
+	head, err := br.Peek(6)
+	if err != nil {
+		// 旧格式的小文件
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if string(head[:4]) != snapshotMagic {
+		return nil, nil
+	}
+
+	hdr := &snapshotHeader{Version: head[4]}
+	if hdr.Version > snapshotVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrBadSnapshot, hdr.Version)
+	}
+
+	nameLen := int(head[5])
+	buf := make([]byte, 6+nameLen+8)
+	if _, err := io.ReadFull(br, buf); err != nil {
+		return nil, fmt.Errorf("%w: truncated header", ErrBadSnapshot)
+	}
+
+	buf = buf[6:]
+	hdr.Serializer = string(buf[:nameLen])
+	hdr.Count = binary.BigEndian.Uint32(buf[nameLen:])
+	hdr.CRC = binary.BigEndian.Uint32(buf[nameLen+4:])
+	return hdr, nil
+}
+
+// checkSnapshot 读取并检查快照文件头，返回 payload 的 reader、格式版本和校验函数.
+// 读取 payload 时同时计算 crc32，解码完成后调用 verify 读取剩余的数据并校验，
+// 校验失败时返回 ErrBadChecksum，否则返回解码的错误. 旧格式(无文件头)的文件版本为 0，不校验
+func (c *Cache) checkSnapshot(r io.Reader) (payload io.Reader, version byte, verify func(err error) error, err error) {
+	br := bufio.NewReader(r)
+	hdr, err := readSnapshotHeader(br)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if hdr == nil {
+		return br, 0, func(err error) error { return err }, nil
+	}
+
+	if name := c.serializerName(); hdr.Serializer != name {
+		return nil, 0, nil, fmt.Errorf("%w: file use %q, but cache use %q", ErrWrongSerializer, hdr.Serializer, name)
+	}
+
+	hash := crc32.NewIEEE()
+	payload = io.TeeReader(br, hash)
+	verify = func(err error) error {
+		if _, cerr := io.Copy(io.Discard, payload); cerr != nil {
+			return cmp.Or(err, cerr)
+		}
+		if hash.Sum32() != hdr.CRC {
+			return ErrBadChecksum
+		}
+		return err
+	}
+	return payload, hdr.Version, verify, nil
+}
+
+// gzipMagic gzip 文件头的魔数，用于加载时自动检测
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// encodeTo 序列化数据并写入 w，按配置进行压缩、加密
+func (c *Cache) encodeTo(w io.Writer, serializer Serializer, data any) (err error) {
+	out := w
+	var cw *cryptWriter
+	if len(c.opt.SaveEncryptKey) > 0 {
+		if cw, err = newCryptWriter(w, c.opt.SaveEncryptKey); err != nil {
+			return err
+		}
+		out = cw
+	}
+
+	if c.opt.SaveCompression == CompressGzip {
+		gw := gzip.NewWriter(out)
+		if err = encodePayload(gw, serializer, data); err == nil {
+			err = gw.Close()
+		}
+	} else {
+		err = encodePayload(out, serializer, data)
+	}
+
+	if err != nil || cw == nil {
+		return err
+	}
+	return cw.Close()
+}
+
+// encodePayload 序列化快照数据. gob 快照逐条编码数据项，加载时可以流式解码
+func encodePayload(w io.Writer, serializer Serializer, data any) error {
+	if snap, ok := data.(*snapshot); ok {
+		if _, ok = serializer.(GobSerializer); ok {
+			return encodeGobStream(w, snap)
+		}
+	}
+	return serializer.EncodeTo(w, data)
+}
+
+// payloadReader 获取快照 payload 的明文读取器. 按配置解密，自动检测是否为 gzip 压缩数据.
+// 版本 3 之前的加密快照整体加密，需要先读取到内存再解密
+//
+// NOTE: 包装了中间件的序列化器自行处理压缩，不进行 gzip 检测
+func (c *Cache) payloadReader(r io.Reader, version byte, serializer Serializer) (io.Reader, func(), error) {
+	noop := func() {}
+	if len(c.opt.SaveEncryptKey) > 0 && version >= 3 {
+		cr, err := newCryptReader(r, c.opt.SaveEncryptKey)
+		if err != nil {
+			return nil, noop, err
+		}
+		r = cr
+	} else if len(c.opt.SaveEncryptKey) > 0 {
+		raw, err := io.ReadAll(r)
+		if err != nil {
+			return nil, noop, err
+		}
+
+		plain, err := aesGCMOpen(c.opt.SaveEncryptKey, raw)
+		if err != nil {
+			return nil, noop, err
+		}
+		r = bytes.NewReader(plain)
+	}
+
+	if _, ok := serializer.(*wrappedSerializer); ok {
+		return r, noop, nil
+	}
+
+	br := bufio.NewReader(r)
+	if head, err := br.Peek(len(gzipMagic)); err == nil && string(head) == string(gzipMagic) {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, noop, err
+		}
+		return gr, func() { stdio.SafeClose(gr) }, nil
+	}
+	return br, noop, nil
+}
+
+// aesGCMSeal 使用 AES-GCM 加密数据. 输出格式: nonce + ciphertext
+func aesGCMSeal(key, plain []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(plain)+gcm.Overhead())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+// aesGCMOpen 解密 aesGCMSeal 输出的数据
+func aesGCMOpen(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("lcache: invalid encrypted data")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,389 @@
+package lcache_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_WithSaveCompression(t *testing.T) {
+	c := lcache.New(lcache.WithSaveCompression(lcache.CompressGzip))
+	c.Set("key1", "value1", 0)
+
+	filename := t.TempDir() + "/cache.json.gz"
+	assert.NoErr(t, c.SaveFile(filename))
+
+	bs, err := os.ReadFile(filename)
+	assert.NoErr(t, err)
+	// after header: magic(4) + version(1) + name len(1) + "json" + count(4) + crc32(4)
+	assert.Eq(t, []byte{0x1f, 0x8b}, bs[18:20])
+
+	// auto-detect on load
+	c2 := lcache.New()
+	assert.NoErr(t, c2.LoadFile(filename))
+	assert.Eq(t, "value1", c2.Val("key1"))
+}
+
+func TestCache_WithSaveEncryption(t *testing.T) {
+	assert.Panics(t, func() {
+		lcache.WithSaveEncryption([]byte("short"))
+	})
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	c := lcache.New(
+		lcache.WithSaveEncryption(key),
+		lcache.WithSaveCompression(lcache.CompressGzip),
+	)
+	c.Set("token", "secret-token-value", 0)
+
+	filename := t.TempDir() + "/cache.enc"
+	assert.NoErr(t, c.SaveFile(filename))
+
+	bs, err := os.ReadFile(filename)
+	assert.NoErr(t, err)
+	assert.NotContains(t, string(bs), "secret-token-value")
+
+	c2 := lcache.New(lcache.WithSaveEncryption(key))
+	assert.NoErr(t, c2.LoadFile(filename))
+	assert.Eq(t, "secret-token-value", c2.Val("token"))
+
+	// wrong key
+	c3 := lcache.New(lcache.WithSaveEncryption([]byte("0123456789abcdef")))
+	assert.Err(t, c3.LoadFile(filename))
+}
+
+func TestCache_WithSaveEncryption_chunks(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	c := lcache.New(lcache.WithSaveEncryption(key), lcache.WithSerializer("lcbin"))
+	val := strings.Repeat("v", 1024)
+	for i := 0; i < 200; i++ {
+		c.Set("key"+strconv.Itoa(i), val, 0)
+	}
+
+	filename := t.TempDir() + "/cache.enc"
+	assert.NoErr(t, c.SaveFile(filename))
+	bs, err := os.ReadFile(filename)
+	assert.NoErr(t, err)
+	assert.Gt(t, len(bs), 3*64<<10)
+
+	// decrypted in streaming, the reader does not support seek
+	c2 := lcache.New(lcache.WithSaveEncryption(key), lcache.WithSerializer("lcbin"))
+	assert.NoErr(t, c2.LoadFS(streamFS{"cache.enc": bs}, "cache.enc"))
+	assert.Eq(t, 200, c2.Len())
+	assert.Eq(t, val, c2.Val("key199"))
+
+	// corrupted middle chunk, the cache is unchanged
+	c2.Set("keep", "val", 0)
+	bs[len(bs)/2] ^= 0xff
+	err = c2.LoadFS(streamFS{"cache.enc": bs}, "cache.enc")
+	assert.ErrIs(t, err, lcache.ErrBadChecksum)
+	assert.Eq(t, 201, c2.Len())
+	assert.Eq(t, "val", c2.Val("keep"))
+}
+
+// streamFS the files do not support seek
+type streamFS map[string][]byte
+
+func (s streamFS) Open(name string) (fs.File, error) {
+	data, ok := s[name]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return streamFile{Reader: bytes.NewReader(data)}, nil
+}
+
+type streamFile struct {
+	io.Reader
+}
+
+func (f streamFile) Stat() (fs.FileInfo, error) { return nil, fs.ErrInvalid }
+func (f streamFile) Close() error               { return nil }
+
+func TestCache_LoadFile_header(t *testing.T) {
+	c := lcache.New()
+	c.Set("key1", "value1", 0)
+
+	filename := t.TempDir() + "/cache.json"
+	assert.NoErr(t, c.SaveFile(filename))
+
+	bs, err := os.ReadFile(filename)
+	assert.NoErr(t, err)
+	assert.Eq(t, "LCSF", string(bs[:4]))
+
+	// serializer mismatch
+	lcache.SetSerializer("json3", lcache.JSONSerializer{})
+	defer lcache.SetSerializer("json3", nil)
+	err = lcache.New(lcache.WithSerializer("json3")).LoadFile(filename)
+	assert.ErrIs(t, err, lcache.ErrWrongSerializer)
+
+	// corrupted payload
+	bs[len(bs)-3] ^= 0xff
+	assert.NoErr(t, os.WriteFile(filename, bs, 0644))
+	assert.ErrIs(t, lcache.New().LoadFile(filename), lcache.ErrBadChecksum)
+
+	// unsupported version
+	bs[4] = 99
+	assert.NoErr(t, os.WriteFile(filename, bs, 0644))
+	assert.ErrIs(t, lcache.New().LoadFile(filename), lcache.ErrBadSnapshot)
+
+	// legacy file without header
+	legacy := `{"gen":0,"items":{"key2":{"v":"value2","e":0}}}`
+	assert.NoErr(t, os.WriteFile(filename, []byte(legacy), 0644))
+	c2 := lcache.New()
+	assert.NoErr(t, c2.LoadFile(filename))
+	assert.Eq(t, "value2", c2.Val("key2"))
+
+	// the baseline format: a map of key to item
+	legacy = `{"key3":{"v":"value3","e":0},"key4":{"v":4,"e":1}}`
+	assert.NoErr(t, os.WriteFile(filename, []byte(legacy), 0644))
+	assert.NoErr(t, c2.LoadFile(filename))
+	assert.Eq(t, []string{"key3"}, c2.Keys())
+	assert.Eq(t, "value3", c2.Val("key3"))
+}
+
+func TestCache_LoadFile_merge(t *testing.T) {
+	c := lcache.New()
+	c.Set("key1", "file1", 0)
+	c.Set("key2", "file2", 0)
+
+	filename := t.TempDir() + "/cache.json"
+	assert.NoErr(t, c.SaveFile(filename))
+
+	t.Run("keep existing", func(t *testing.T) {
+		c2 := lcache.New()
+		c2.Set("key1", "mem1", 0)
+		c2.Set("key3", "mem3", 0)
+
+		assert.NoErr(t, c2.LoadFile(filename, lcache.LoadMerge))
+		assert.Eq(t, 3, c2.Len())
+		assert.Eq(t, "mem1", c2.Val("key1"))
+		assert.Eq(t, "file2", c2.Val("key2"))
+		assert.Eq(t, "mem3", c2.Val("key3"))
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		c2 := lcache.New()
+		c2.Set("key1", "mem1", 0)
+		c2.Set("key3", "mem3", 0)
+
+		assert.NoErr(t, c2.LoadFile(filename, lcache.LoadMergeOverwrite))
+		assert.Eq(t, 3, c2.Len())
+		assert.Eq(t, "file1", c2.Val("key1"))
+		assert.Eq(t, "mem3", c2.Val("key3"))
+	})
+
+	// a merge keeps the existing generation
+	t.Run("keep generation", func(t *testing.T) {
+		c1 := lcache.New()
+		c1.BumpGeneration()
+		c1.BumpGeneration()
+		c1.Set("key1", "file1", 0)
+		genFile := t.TempDir() + "/cache.json"
+		assert.NoErr(t, c1.SaveFile(genFile))
+
+		c2 := lcache.New()
+		c2.Set("key3", "mem3", 0)
+		assert.NoErr(t, c2.LoadFile(genFile, lcache.LoadMerge))
+		assert.Eq(t, uint64(0), c2.Generation())
+		assert.Eq(t, "mem3", c2.Val("key3"))
+		assert.Eq(t, "file1", c2.Val("key1"))
+
+		// the merged items are invalidated together
+		c2.BumpGeneration()
+		assert.False(t, c2.Has("key1"))
+		assert.False(t, c2.Has("key3"))
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		c2 := lcache.New()
+		c2.Set("key3", "mem3", 0)
+
+		assert.NoErr(t, c2.LoadFile(filename))
+		assert.Eq(t, 2, c2.Len())
+		assert.False(t, c2.Has("key3"))
+	})
+}
+
+// wrapSerializer not a JSONSerializer, will not use streaming load
+type wrapSerializer struct {
+	lcache.JSONSerializer
+}
+
+func TestCache_LoadFile_stream(t *testing.T) {
+	c := lcache.New(lcache.WithCapacity(2000))
+	for i := 0; i < 1000; i++ {
+		c.Set("key"+strconv.Itoa(i), i, 0)
+	}
+
+	filename := t.TempDir() + "/cache.json"
+	assert.NoErr(t, c.SaveFile(filename))
+
+	c2 := lcache.New(lcache.WithCapacity(2000))
+	assert.NoErr(t, c2.LoadFile(filename))
+	assert.Eq(t, 1000, c2.Len())
+	assert.Eq(t, float64(999), c2.Val("key999"))
+
+	// full decode path
+	lcache.SetSerializer("wrap", wrapSerializer{})
+	defer lcache.SetSerializer("wrap", nil)
+
+	c3 := lcache.New(lcache.WithCapacity(2000), lcache.WithSerializer("wrap"))
+	c3.MSet(map[string]any{"key1": "val1", "key2": "val2"}, 0)
+	assert.NoErr(t, c3.SaveFile(filename))
+
+	c4 := lcache.New(lcache.WithSerializer("wrap"))
+	assert.NoErr(t, c4.LoadFile(filename))
+	assert.Eq(t, 2, c4.Len())
+	assert.Eq(t, "val2", c4.Val("key2"))
+
+	// gob records
+	c5 := lcache.New(lcache.WithCapacity(2000), lcache.WithSerializer("gob"))
+	for i := 0; i < 1000; i++ {
+		c5.Set("key"+strconv.Itoa(i), i, 0)
+	}
+	assert.NoErr(t, c5.SaveFile(filename))
+	c6 := lcache.New(lcache.WithCapacity(2000), lcache.WithSerializer("gob"))
+	assert.NoErr(t, c6.LoadFile(filename))
+	assert.Eq(t, 1000, c6.Len())
+	assert.Eq(t, 999, c6.Val("key999"))
+}
+
+func TestCache_LoadFile_decodeError(t *testing.T) {
+	c := lcache.New()
+	c.Set("keep", "val", 0)
+
+	// the cache is unchanged on a mid-stream decode error
+	err := c.UnmarshalJSON([]byte(`{"gen":0,"items":{"key1":{"v":1,"e":0},"key2":{"v":`))
+	assert.Err(t, err)
+	assert.Eq(t, []string{"keep"}, c.Keys())
+
+	// and on a type restore error
+	lcache.RegisterType[typedUser]("user")
+	err = c.UnmarshalJSON([]byte(`{"gen":0,"items":{"key1":{"v":1,"e":0},"key2":{"v":"bad","t":"user"}}}`))
+	assert.Err(t, err)
+	assert.Eq(t, []string{"keep"}, c.Keys())
+}
+
+func TestCache_LoadFile_lruOrder(t *testing.T) {
+	tests := map[string]lcache.OptionFn{
+		"json":  lcache.WithSerializer("json"),
+		"lcbin": lcache.WithSerializer("lcbin"),
+		"gob":   lcache.WithSerializer("gob"),
+		"wrap":  lcache.WithSerializerObj(wrapSerializer{}),
+	}
+
+	for name, opt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := lcache.New(lcache.WithCapacity(3), lcache.WithLRUMode(lcache.LRUExact), opt)
+			c.Set("key1", "val1", 0)
+			c.Set("key2", "val2", 0)
+			c.Set("key3", "val3", 0)
+			c.Get("key1")
+			c.Get("key1")
+
+			filename := t.TempDir() + "/cache.snap"
+			assert.NoErr(t, c.SaveFile(filename))
+
+			c2 := lcache.New(lcache.WithCapacity(3), opt)
+			assert.NoErr(t, c2.LoadFile(filename))
+			key, _ := c2.OldestKey()
+			assert.Eq(t, "key2", key)
+			key, _ = c2.NewestKey()
+			assert.Eq(t, "key1", key)
+			if name != "lcbin" {
+				assert.Eq(t, []lcache.KeyHits{{Key: "key1", Hits: 2}}, c2.HotKeys(3))
+			}
+
+			// the least recently used is evicted first
+			c2.Set("key4", "val4", 0)
+			assert.False(t, c2.Has("key2"))
+			assert.True(t, c2.Has("key3"))
+		})
+	}
+}
+
+func TestCache_LoadFS(t *testing.T) {
+	c := lcache.New(lcache.WithSaveCompression(lcache.CompressGzip))
+	c.Set("key1", "val1", 0)
+	filename := t.TempDir() + "/cache.json"
+	assert.NoErr(t, c.SaveFile(filename))
+	data, err := os.ReadFile(filename)
+	assert.NoErr(t, err)
+
+	fsys := fstest.MapFS{"data/warm.snap": {Data: data}}
+	c2 := lcache.New()
+	c2.Set("other", "val", 0)
+	assert.NoErr(t, c2.LoadFS(fsys, "data/warm.snap", lcache.LoadMerge))
+	assert.Eq(t, "val1", c2.Val("key1"))
+	assert.Eq(t, "val", c2.Val("other"))
+	assert.ErrIs(t, c2.LoadFS(fsys, "none.snap"), fs.ErrNotExist)
+
+	// zip file, the reader does not support seek
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("warm.snap")
+	assert.NoErr(t, err)
+	_, err = w.Write(data)
+	assert.NoErr(t, err)
+	assert.NoErr(t, zw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoErr(t, err)
+	c3 := lcache.New()
+	assert.NoErr(t, c3.LoadFS(zr, "warm.snap"))
+	assert.Eq(t, "val1", c3.Val("key1"))
+
+	// bad checksum
+	data[len(data)-1] ^= 0xff
+	assert.ErrIs(t, c3.LoadFS(fsys, "data/warm.snap"), lcache.ErrBadChecksum)
+}
+
+func TestWithSerializerObj(t *testing.T) {
+	s := lcache.WrapSerializer(lcache.JSONSerializer{}, lcache.CRCMiddleware())
+	c := lcache.New(lcache.WithSerializerObj(s))
+	c.Set("key1", "val1", 0)
+
+	filename := t.TempDir() + "/cache.dat"
+	assert.NoErr(t, c.SaveFile(filename))
+
+	c2 := lcache.New(lcache.WithSerializerObj(s))
+	assert.NoErr(t, c2.LoadFile(filename))
+	assert.Eq(t, "val1", c2.Val("key1"))
+
+	// name based serializer
+	c3 := lcache.New()
+	assert.ErrIs(t, c3.LoadFile(filename), lcache.ErrWrongSerializer)
+
+	// the registered instances are recorded by name
+	s1 := lcache.WrapSerializer(lcache.JSONSerializer{}, lcache.CRCMiddleware())
+	s2 := lcache.WrapSerializer(lcache.JSONSerializer{}, lcache.GzipMiddleware())
+	lcache.SetSerializer("json-crc", s1)
+	lcache.SetSerializer("json-gz", s2)
+	defer lcache.SetSerializer("json-crc", nil)
+	defer lcache.SetSerializer("json-gz", nil)
+
+	c = lcache.New(lcache.WithSerializerObj(s1))
+	c.Set("key1", "val1", 0)
+	assert.NoErr(t, c.SaveFile(filename))
+	assert.ErrIs(t, lcache.New(lcache.WithSerializerObj(s2)).LoadFile(filename), lcache.ErrWrongSerializer)
+	c4 := lcache.New(lcache.WithSerializer("json-crc"))
+	assert.NoErr(t, c4.LoadFile(filename))
+	assert.Eq(t, "val1", c4.Val("key1"))
+
+	// the name is too long
+	long := strings.Repeat("s", 256)
+	lcache.SetSerializer(long, lcache.JSONSerializer{})
+	defer lcache.SetSerializer(long, nil)
+	c = lcache.New(lcache.WithSerializer(long))
+	c.Set("key1", "val1", 0)
+	assert.Err(t, c.SaveFile(filename))
+}
@@ -0,0 +1,59 @@
+package lcache
+
+import "time"
+
+// Pin mark the existing item of the key as pinned, it will never be evicted by the Capacity
+// or MaxCost limit. The TTL still applies, and Delete/Clear still remove the item.
+//
+// The pin is kept when the value is replaced, and dropped when the item is removed(eg: deleted,
+// expired or cleared), so the pins never outnumber the items. It is not saved to the snapshot or AOF.
+// returns false if the key does not exist, is invalid or the cache is frozen.
+//
+// NOTE: the capacity eviction scans past the pinned items, and if all items are pinned,
+// the cache can grow beyond the Capacity. Keep the pinned items few.
+//
+// Usage:
+//
+//	c.Set("flags", flags, 0)
+//	c.Pin("flags")
+func (c *Cache) Pin(key string) bool {
+	key, err := c.normKey(key)
+	if err != nil || !c.lock() {
+		return false
+	}
+	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return false
+	}
+
+	hk, it := c.find(c.nsKey(key))
+	if it == nil || c.invalid(it, time.Now().UnixMilli()) {
+		return false
+	}
+
+	if c.pinned == nil {
+		c.pinned = make(map[string]struct{})
+	}
+	c.pinned[hk] = struct{}{}
+	return true
+}
+
+// Unpin remove the pin of the key, it can be evicted as usual again.
+// returns false if the key is not pinned.
+func (c *Cache) Unpin(key string) bool {
+	key, err := c.normKey(key)
+	if err != nil || !c.lock() {
+		return false
+	}
+	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return false
+	}
+
+	hk := c.hashKey(c.nsKey(key))
+	if _, ok := c.pinned[hk]; !ok {
+		return false
+	}
+	delete(c.pinned, hk)
+	return true
+}
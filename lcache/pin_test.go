@@ -0,0 +1,87 @@
+package lcache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_Pin(t *testing.T) {
+	c := lcache.New(lcache.WithCapacity(2))
+	c.Set("flags", 1, 0)
+	assert.True(t, c.Pin("flags"))
+	assert.Eq(t, 1, c.Stats().Pinned)
+
+	// capacity eviction skips the pinned key
+	c.Set("key1", 2, 0)
+	c.Set("key2", 3, 0)
+	c.Set("key3", 4, 0)
+	assert.True(t, c.Has("flags"))
+	assert.False(t, c.Has("key1"))
+	assert.Eq(t, 2, c.Len())
+
+	assert.Eq(t, 1, c.EvictN(5))
+	assert.Eq(t, []string{"flags"}, c.Keys())
+
+	// pin is kept when the value is replaced
+	c.Set("flags", 5, 0)
+	c.Set("key4", 6, 0)
+	c.Set("key5", 7, 0)
+	val, _ := c.Get("flags")
+	assert.Eq(t, 5, val)
+
+	// unpin
+	assert.True(t, c.Unpin("flags"))
+	assert.False(t, c.Unpin("flags"))
+	assert.Eq(t, 0, c.Stats().Pinned)
+	c.Set("key6", 8, 0)
+	c.Set("key7", 9, 0)
+	assert.False(t, c.Has("flags"))
+}
+
+func TestCache_Pin_ttlAndCost(t *testing.T) {
+	c := lcache.New(lcache.WithMaxCost(10))
+	ns := c.Namespace("ns")
+	assert.False(t, ns.Pin("big"))
+	assert.NoErr(t, ns.SetX("big", 1, lcache.WithCost(8)))
+	assert.True(t, ns.Pin("big"))
+	assert.NoErr(t, c.SetX("big", 2, lcache.WithCost(4)))
+	assert.True(t, ns.Has("big"))
+	assert.False(t, c.Has("big"))
+	assert.False(t, c.Pin("big"))
+
+	// all other items pinned, cache may exceed the max cost. the pin is kept on replace
+	assert.NoErr(t, c.SetX("big", 2, lcache.WithCost(2)))
+	assert.True(t, c.Pin("big"))
+	assert.NoErr(t, c.SetX("big", 3, lcache.WithCost(4)))
+	assert.Eq(t, int64(12), c.Stats().Cost)
+
+	// TTL still applies, the pin is dropped with the expired item
+	tc := lcache.New()
+	tc.Set("temp", 1, 20*time.Millisecond)
+	assert.True(t, tc.Pin("temp"))
+	assert.Eq(t, 1, tc.Stats().Pinned)
+	time.Sleep(30 * time.Millisecond)
+	assert.False(t, tc.Has("temp"))
+	assert.False(t, tc.Pin("temp"))
+	tc.PruneExpired()
+	assert.Eq(t, 0, tc.Stats().Pinned)
+
+	// the pin is moved by RenameNamespace, and dropped by Delete and Clear
+	n, err := c.RenameNamespace("ns", "moved")
+	assert.NoErr(t, err)
+	assert.Eq(t, 1, n)
+	assert.Eq(t, 2, c.Stats().Pinned)
+	c.Delete("big")
+	assert.Eq(t, 1, c.Stats().Pinned)
+	c.Namespace("moved").Clear()
+	assert.Eq(t, 0, c.Stats().Pinned)
+	c.Set("big", 1, 0)
+	assert.True(t, c.Pin("big"))
+
+	c.Freeze()
+	assert.False(t, c.Pin("key"))
+	assert.False(t, c.Unpin("big"))
+}
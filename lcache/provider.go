@@ -0,0 +1,125 @@
+package lcache
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gookit/goutil/fsutil"
+	"github.com/gookit/goutil/x/stdio"
+)
+
+// PersistProvider the storage of the snapshots for SaveFile, LoadFile and the auto-save.
+// The default provider saves the snapshots to the local files. Implement it to save the
+// snapshots elsewhere, eg: S3, bolt or sqlite, then set it by WithPersistProvider.
+//
+// The name is the filename passed to SaveFile and LoadFile, eg: use it as the object key.
+// The snapshot data is in the SaveFile format, include the header, compression and encryption.
+type PersistProvider interface {
+	// Snapshot save a snapshot of the name, the data is written to w by write.
+	// The previous snapshot of the name should be kept if write returns an error.
+	Snapshot(name string, write func(w io.Writer) error) error
+	// Restore read the snapshot of the name by read.
+	// Returns an error matched fs.ErrNotExist if the snapshot does not exist.
+	Restore(name string, read func(r io.Reader) error) error
+}
+
+// OpAppender the optional interface of PersistProvider, receives the write operations like
+// the AOF records, for persist the changes incrementally between the snapshots.
+//
+// NOTE: AppendOp is called under the cache lock, it should be fast. eg: buffer the operations
+// and write them in batches. The error is recorded as Stats.AOFErr.
+type OpAppender interface {
+	AppendOp(op PersistOp) error
+}
+
+// OpKind the kind of the write operation
+type OpKind string
+
+// The kinds of the write operations
+const (
+	OpSet   OpKind = "set"
+	OpDel   OpKind = "del"
+	OpClear OpKind = "clear"
+	// OpRename rename a namespace: Key is the old namespace, Val is the new namespace
+	OpRename OpKind = "rename"
+	// OpGen bump the generation: Val is the new generation(uint64), the items written before are invalid
+	OpGen OpKind = "gen"
+)
+
+// PersistOp a write operation. see OpAppender
+type PersistOp struct {
+	Kind OpKind
+	// Key of the item, contains the namespace prefix
+	Key string
+	// Val of the item for OpSet
+	Val any
+	// Exp the expiration time of the item in unix milliseconds, 0 for never expire
+	Exp int64
+}
+
+// persistProvider 获取配置的持久化提供者，默认为本地文件
+func (c *Cache) persistProvider() PersistProvider {
+	if c.opt.PersistProvider != nil {
+		return c.opt.PersistProvider
+	}
+	return FileProvider{Sync: c.opt.SaveSync}
+}
+
+// FileProvider the default PersistProvider, saves the snapshots to the local files.
+//
+// Snapshot writes the data to "<name>.tmp" and renames it to name after the write succeeded,
+// the previous file is kept if the write fails. Set Sync to fsync the temp file before the
+// rename, then the new file is complete after a system crash.
+//
+// It is also used by the other packages to save their data files. eg: lqueue, bloom
+type FileProvider struct {
+	// Sync call fsync on the temp file before rename it
+	Sync bool
+}
+
+// Snapshot implements PersistProvider
+func (p FileProvider) Snapshot(name string, write func(w io.Writer) error) error {
+	tmpFile := name + ".tmp"
+	file, err := fsutil.OpenTruncFile(tmpFile, 0644)
+	if err != nil {
+		return err
+	}
+
+	err = write(file)
+	if err == nil && p.Sync {
+		err = file.Sync()
+	}
+	if err1 := file.Close(); err == nil {
+		err = err1
+	}
+
+	if err != nil {
+		_ = os.Remove(tmpFile)
+		return err
+	}
+	return os.Rename(tmpFile, name)
+}
+
+// Restore implements PersistProvider
+func (p FileProvider) Restore(name string, read func(r io.Reader) error) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer stdio.SafeClose(file)
+	return read(file)
+}
+
+// appendOp 追加写操作到 OpAppender. 未配置时直接返回
+func (c *Cache) appendOp(op, key string, val any, exp int64) error {
+	ap, ok := c.opt.PersistProvider.(OpAppender)
+	if !ok {
+		return nil
+	}
+
+	if err := ap.AppendOp(PersistOp{Kind: OpKind(op), Key: key, Val: val, Exp: exp}); err != nil {
+		return fmt.Errorf("lcache: append op to persist provider: %w", err)
+	}
+	return nil
+}
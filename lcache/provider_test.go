@@ -0,0 +1,117 @@
+package lcache_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+// memProvider 保存快照到内存，并记录写操作
+type memProvider struct {
+	mu    sync.Mutex
+	snaps map[string][]byte
+	ops   []lcache.PersistOp
+}
+
+func newMemProvider() *memProvider {
+	return &memProvider{snaps: make(map[string][]byte)}
+}
+
+func (p *memProvider) Snapshot(name string, write func(w io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.snaps[name] = buf.Bytes()
+	return nil
+}
+
+func (p *memProvider) Restore(name string, read func(r io.Reader) error) error {
+	p.mu.Lock()
+	data, ok := p.snaps[name]
+	p.mu.Unlock()
+	if !ok {
+		return fs.ErrNotExist
+	}
+	// 不支持 Seek 的 reader
+	return read(io.MultiReader(bytes.NewReader(data)))
+}
+
+func (p *memProvider) AppendOp(op lcache.PersistOp) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if op.Key == "bad" {
+		return errors.New("append failed")
+	}
+	p.ops = append(p.ops, op)
+	return nil
+}
+
+func TestWithPersistProvider(t *testing.T) {
+	p := newMemProvider()
+	key := bytes.Repeat([]byte("k"), 32)
+	c := lcache.New(lcache.WithPersistProvider(p), lcache.WithSaveCompression(lcache.CompressGzip),
+		lcache.WithSaveEncryption(key))
+	c.Set("key1", "val1", 0)
+	c.Set("key2", "val2", time.Hour)
+	assert.NoErr(t, c.SaveFile("snap/users"))
+	assert.Gt(t, len(p.snaps["snap/users"]), 0)
+
+	c2 := lcache.New(lcache.WithPersistProvider(p), lcache.WithSaveEncryption(key))
+	assert.NoErr(t, c2.LoadFile("snap/users"))
+	assert.Eq(t, "val1", c2.Val("key1"))
+	assert.Eq(t, "val2", c2.Val("key2"))
+	assert.ErrIs(t, c2.LoadFile("none"), fs.ErrNotExist)
+
+	// bad checksum
+	data := p.snaps["snap/users"]
+	data[len(data)-1] ^= 0xff
+	assert.ErrIs(t, c2.LoadFile("snap/users"), lcache.ErrBadChecksum)
+}
+
+func TestWithPersistProvider_appendOp(t *testing.T) {
+	p := newMemProvider()
+	c := lcache.New(lcache.WithPersistProvider(p))
+	c.Set("key1", "val1", 0)
+	c.Namespace("users").Set("1", "tom", 0)
+	c.Delete("key1")
+	c.Clear()
+	assert.Eq(t, []lcache.PersistOp{
+		{Kind: lcache.OpSet, Key: "key1", Val: "val1"},
+		{Kind: lcache.OpSet, Key: "users:1", Val: "tom"},
+		{Kind: lcache.OpDel, Key: "key1"},
+		{Kind: lcache.OpClear},
+	}, p.ops)
+
+	assert.ErrMsg(t, c.SetE("bad", 1, 0), "lcache: append op to persist provider: append failed")
+	assert.Err(t, c.Stats().AOFErr)
+}
+
+func TestWithPersistProvider_fileFormat(t *testing.T) {
+	// the snapshots written by a provider are in the SaveFile format
+	p := newMemProvider()
+	c := lcache.New(lcache.WithPersistProvider(p))
+	c.Set("key1", "val1", 0)
+	assert.NoErr(t, c.SaveFile("snap"))
+
+	filename := t.TempDir() + "/cache.json"
+	assert.NoErr(t, os.WriteFile(filename, p.snaps["snap"], 0644))
+	snap, err := lcache.ReadSnapshot(filename)
+	assert.NoErr(t, err)
+	assert.Eq(t, "val1", snap.Items["key1"].Val)
+
+	c2 := lcache.New()
+	assert.NoErr(t, c2.LoadFile(filename))
+	assert.Eq(t, "val1", c2.Val("key1"))
+}
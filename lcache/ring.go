@@ -0,0 +1,232 @@
+package lcache
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ringReplicas 容量最小的节点的虚拟节点数量. 其他节点按容量比例增加，最多 ringReplicas*ringMaxWeight 个
+const (
+	ringReplicas  = 64
+	ringMaxWeight = 64
+)
+
+// Ring routes the keys by consistent hashing across multiple Cache instances,
+// for partition a huge keyspace. eg: per-core caches.
+//
+// The nodes with larger capacity get more virtual nodes, so more keys.
+type Ring struct {
+	nodes map[string]*Cache
+	// 虚拟节点的 hash 值(已排序)和对应的节点
+	hashes []uint64
+	owners map[uint64]*Cache
+}
+
+// NewRing create a consistent-hash router with named nodes.
+//
+// Usage:
+//
+//	r := lcache.NewRing(map[string]*lcache.Cache{
+//		"n1": lcache.New(lcache.WithCapacity(10000)),
+//		"n2": lcache.New(lcache.WithCapacity(20000)),
+//	})
+//	r.Set("key", "value", time.Minute)
+func NewRing(nodes map[string]*Cache) *Ring {
+	if len(nodes) == 0 {
+		panic("lcache: ring nodes is empty")
+	}
+
+	minCap := 0
+	for _, c := range nodes {
+		if capacity := max(c.opt.Capacity, 1); minCap == 0 || capacity < minCap {
+			minCap = capacity
+		}
+	}
+
+	r := &Ring{nodes: nodes, owners: make(map[uint64]*Cache)}
+	for name, c := range nodes {
+		weight := min(max(c.opt.Capacity, 1)/minCap, ringMaxWeight)
+		for i := 0; i < ringReplicas*weight; i++ {
+			h := xxh64(name + "#" + strconv.Itoa(i))
+			r.hashes = append(r.hashes, h)
+			r.owners[h] = c
+		}
+	}
+
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+	return r
+}
+
+// NewShards create a Ring of n caches with the same options, the Capacity and MaxCost are
+// split evenly across the shards. n <= 0 to use runtime.GOMAXPROCS(0). The shards are named
+// "s0", "s1", ...
+//
+// Returns an error if the options are invalid(see NewE), or the Capacity is less than n.
+//
+// Each shard has its own lock, so the writes to the different shards do not contend, at the
+// cost of routing each key. Measure it against a single Cache by the BenchmarkCompare_* benchmarks
+// with the target GOMAXPROCS. The methods across all keys(eg: Scan, Update, SaveFile) are only
+// available on each shard by Nodes, the options with a file(eg: WithAutoSave, WithAOF) must not
+// be shared by the shards.
+//
+// Usage:
+//
+//	r, err := lcache.NewShards(0, lcache.WithCapacity(100000))
+//	r.Set("key", "value", time.Minute)
+func NewShards(n int, optFns ...OptionFn) (*Ring, error) {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+
+	nodes := make(map[string]*Cache, n)
+	for i := 0; i < n; i++ {
+		c := newCache()
+		for _, optFn := range optFns {
+			optFn(&c.opt)
+		}
+		if err := c.opt.validate(); err != nil {
+			return nil, err
+		}
+		if !c.opt.DisableLRU && c.opt.Capacity < n {
+			return nil, fmt.Errorf("lcache: capacity %d is less than the shard count %d", c.opt.Capacity, n)
+		}
+
+		c.opt.Capacity = (c.opt.Capacity + n - 1) / n
+		if c.opt.MaxCost > 0 {
+			c.opt.MaxCost = max((c.opt.MaxCost+int64(n)-1)/int64(n), 1)
+		}
+		nodes["s"+strconv.Itoa(i)] = c.Configure()
+	}
+	return NewRing(nodes), nil
+}
+
+// Node get the cache instance for the key
+func (r *Ring) Node(key string) *Cache {
+	h := xxh64(key)
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.owners[r.hashes[i]]
+}
+
+// Nodes get all named cache instances
+func (r *Ring) Nodes() map[string]*Cache { return r.nodes }
+
+// Set value to the node of the key. see Cache.Set
+func (r *Ring) Set(key string, value any, ttl time.Duration) { r.Node(key).Set(key, value, ttl) }
+
+// SetE value to the node of the key. see Cache.SetE
+func (r *Ring) SetE(key string, value any, ttl time.Duration) error {
+	return r.Node(key).SetE(key, value, ttl)
+}
+
+// Get value from the node of the key. see Cache.Get
+func (r *Ring) Get(key string) (any, bool) { return r.Node(key).Get(key) }
+
+// Val get value from the node of the key. see Cache.Val
+func (r *Ring) Val(key string) any { return r.Node(key).Val(key) }
+
+// Has check the key exists in its node. see Cache.Has
+func (r *Ring) Has(key string) bool { return r.Node(key).Has(key) }
+
+// Delete the key from its node. see Cache.Delete
+func (r *Ring) Delete(key string) bool { return r.Node(key).Delete(key) }
+
+// MGet get multiple keys from their nodes. see Cache.MGet
+func (r *Ring) MGet(keys ...string) map[string]any {
+	result := make(map[string]any, len(keys))
+	for node, nodeKeys := range r.groupKeys(keys) {
+		for k, v := range node.MGet(nodeKeys...) {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// MSet set multiple items to their nodes. see Cache.MSet
+func (r *Ring) MSet(items map[string]any, ttl time.Duration) {
+	groups := make(map[*Cache]map[string]any)
+	for k, v := range items {
+		node := r.Node(k)
+		if groups[node] == nil {
+			groups[node] = make(map[string]any)
+		}
+		groups[node][k] = v
+	}
+
+	for node, nodeItems := range groups {
+		node.MSet(nodeItems, ttl)
+	}
+}
+
+// MDelete delete multiple keys from their nodes. see Cache.MDelete
+func (r *Ring) MDelete(keys ...string) {
+	for node, nodeKeys := range r.groupKeys(keys) {
+		node.MDelete(nodeKeys...)
+	}
+}
+
+// Keys get all valid keys of all nodes
+func (r *Ring) Keys() []string {
+	var keys []string
+	for _, c := range r.nodes {
+		keys = append(keys, c.Keys()...)
+	}
+	return keys
+}
+
+// Len get the total number of items of all nodes
+func (r *Ring) Len() (n int) {
+	for _, c := range r.nodes {
+		n += c.Len()
+	}
+	return
+}
+
+// Clear all nodes
+func (r *Ring) Clear() {
+	for _, c := range r.nodes {
+		c.Clear()
+	}
+}
+
+// Stats get the statistics of each node, eg: to verify the keys and load are evenly
+// distributed by Len, HitRatio and AvgLockWait. see Cache.Stats
+func (r *Ring) Stats() map[string]Stats {
+	stats := make(map[string]Stats, len(r.nodes))
+	for name, c := range r.nodes {
+		stats[name] = c.Stats()
+	}
+	return stats
+}
+
+// StartJanitors start the janitor of each node with the same interval, the start times
+// are staggered evenly in the interval, so the nodes are not cleaned at the same time.
+// interval <= 0 to stop them. see WithJanitor
+func (r *Ring) StartJanitors(interval time.Duration) {
+	names := make([]string, 0, len(r.nodes))
+	for name := range r.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		c := r.nodes[name]
+		c.configure([]OptionFn{WithJanitor(interval)})
+		c.startJanitorAfter(interval * time.Duration(i) / time.Duration(len(names)))
+	}
+}
+
+// groupKeys 按节点分组 key
+func (r *Ring) groupKeys(keys []string) map[*Cache][]string {
+	groups := make(map[*Cache][]string)
+	for _, key := range keys {
+		node := r.Node(key)
+		groups[node] = append(groups[node], key)
+	}
+	return groups
+}
@@ -0,0 +1,117 @@
+package lcache_test
+
+import (
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestRing(t *testing.T) {
+	n1 := lcache.New(lcache.WithCapacity(10000))
+	n2 := lcache.New(lcache.WithCapacity(20000))
+	r := lcache.NewRing(map[string]*lcache.Cache{"n1": n1, "n2": n2})
+
+	for i := 0; i < 3000; i++ {
+		r.Set("key"+strconv.Itoa(i), i, time.Minute)
+	}
+	assert.Eq(t, 3000, r.Len())
+	assert.Len(t, r.Keys(), 3000)
+	assert.Eq(t, 100, r.Val("key100"))
+	// larger capacity node gets more keys
+	assert.Gt(t, n2.Len(), n1.Len())
+	assert.Gt(t, n1.Len(), 500)
+
+	// routes to the same node
+	node := r.Node("key1")
+	assert.True(t, node.Has("key1"))
+	assert.True(t, node == r.Node("key1"))
+
+	r.MSet(map[string]any{"a": 1, "b": 2}, 0)
+	assert.Eq(t, map[string]any{"a": 1, "b": 2, "c": nil}, r.MGet("a", "b", "c"))
+	r.MDelete("a", "b")
+	assert.False(t, r.Has("a"))
+	assert.True(t, r.Delete("key1"))
+
+	r.Clear()
+	assert.Eq(t, 0, r.Len())
+
+	assert.Panics(t, func() {
+		lcache.NewRing(nil)
+	})
+}
+
+func TestRing_Stats(t *testing.T) {
+	r := lcache.NewRing(map[string]*lcache.Cache{"n1": lcache.New(), "n2": lcache.New()})
+	for i := 0; i < 100; i++ {
+		r.Set("key"+strconv.Itoa(i), i, 0)
+		r.Get("key" + strconv.Itoa(i))
+	}
+
+	stats := r.Stats()
+	assert.Len(t, stats, 2)
+	assert.Eq(t, 100, stats["n1"].Len+stats["n2"].Len)
+	assert.Eq(t, uint64(100), stats["n1"].Hits+stats["n2"].Hits)
+	assert.Eq(t, float64(1), stats["n1"].HitRatio())
+}
+
+func TestRing_StartJanitors(t *testing.T) {
+	n1, n2 := lcache.New(), lcache.New()
+	defer n1.Close()
+	defer n2.Close()
+	r := lcache.NewRing(map[string]*lcache.Cache{"n1": n1, "n2": n2})
+
+	for i := 0; i < 100; i++ {
+		r.Set("key"+strconv.Itoa(i), i, 10*time.Millisecond)
+	}
+	r.StartJanitors(20 * time.Millisecond)
+	time.Sleep(60 * time.Millisecond)
+	assert.Eq(t, 0, n1.Len())
+	assert.Eq(t, 0, n2.Len())
+
+	r.StartJanitors(0)
+	r.Set("key1", 1, 10*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	assert.Eq(t, 1, r.Len())
+
+	// restart concurrently with Close
+	var wg sync.WaitGroup
+	for i := 1; i <= 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.StartJanitors(time.Duration(i) * time.Millisecond)
+		}()
+	}
+	assert.NoErr(t, n1.Close())
+	wg.Wait()
+	r.StartJanitors(0)
+}
+
+func TestNewShards(t *testing.T) {
+	r, err := lcache.NewShards(4, lcache.WithCapacity(1000), lcache.WithMaxCost(100))
+	assert.NoErr(t, err)
+	assert.Len(t, r.Nodes(), 4)
+	for i := 0; i < 2000; i++ {
+		r.Set("key"+strconv.Itoa(i), i, 0)
+	}
+	assert.Eq(t, 1000, r.Len())
+	for name, st := range r.Stats() {
+		assert.Eq(t, 250, st.Len, name)
+	}
+
+	// default to GOMAXPROCS
+	r, err = lcache.NewShards(0)
+	assert.NoErr(t, err)
+	assert.Len(t, r.Nodes(), runtime.GOMAXPROCS(0))
+
+	// invalid options
+	_, err = lcache.NewShards(4, lcache.WithCapacity(0))
+	assert.ErrMsg(t, err, "lcache: invalid capacity 0, must be greater than 0")
+	_, err = lcache.NewShards(4, lcache.WithCapacity(3))
+	assert.ErrMsg(t, err, "lcache: capacity 3 is less than the shard count 4")
+}
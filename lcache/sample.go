@@ -0,0 +1,65 @@
+package lcache
+
+import (
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// RandomKey get a random valid key from the cache. returns false if the cache is empty.
+//
+// NOTE: it does not update the LRU order of the key.
+func (c *Cache) RandomKey() (string, bool) {
+	for key := range c.Sample(1) {
+		return key, true
+	}
+	return "", false
+}
+
+// Sample get up to n random valid items from the cache, for monitoring and auditing.
+// For a namespace view, the returned keys are without prefix.
+//
+// NOTE: it does not update the LRU order of the items.
+func (c *Cache) Sample(n int) map[string]any {
+	if n <= 0 || !c.rlock() {
+		return nil
+	}
+	defer c.mu.RUnlock()
+
+	result := make(map[string]any, n)
+	nowUm := time.Now().UnixMilli()
+	add := func(key string) {
+		it := c.items[key]
+		if it == nil || c.invalid(it, nowUm) {
+			return
+		}
+
+		if c.ns != "" {
+			if !strings.HasPrefix(key, c.ns) {
+				return
+			}
+			key = key[len(c.ns):]
+		}
+		result[key] = it.Val
+	}
+
+	// 随机选取槽位，跳过空洞
+	for tries := n * 4; tries > 0 && len(result) < n && len(c.slots) > 0; tries-- {
+		pos := rand.IntN(len(c.slots))
+		if key := c.slots[pos]; c.slotIdx[key] == pos {
+			add(key)
+		}
+	}
+
+	// 随机选取的数量不足时(空洞或过期数据较多)，从随机位置开始顺序补充
+	if len(result) < n && len(c.slots) > 0 {
+		start := rand.IntN(len(c.slots))
+		for i := 0; i < len(c.slots) && len(result) < n; i++ {
+			pos := (start + i) % len(c.slots)
+			if key := c.slots[pos]; c.slotIdx[key] == pos {
+				add(key)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,37 @@
+package lcache_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_Sample(t *testing.T) {
+	c := lcache.New()
+	_, ok := c.RandomKey()
+	assert.False(t, ok)
+	assert.Empty(t, c.Sample(3))
+
+	for i := 0; i < 100; i++ {
+		c.Set("key"+strconv.Itoa(i), i, 0)
+	}
+
+	key, ok := c.RandomKey()
+	assert.True(t, ok)
+	assert.True(t, c.Has(key))
+
+	data := c.Sample(10)
+	assert.Len(t, data, 10)
+	for k, v := range data {
+		assert.Eq(t, c.Val(k), v)
+	}
+
+	// n larger than the cache size
+	assert.Len(t, c.Sample(200), 100)
+
+	// namespace view
+	c.Namespace("ns").Set("a", 1, 0)
+	assert.Eq(t, map[string]any{"a": 1}, c.Namespace("ns").Sample(5))
+}
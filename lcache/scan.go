@@ -0,0 +1,166 @@
+package lcache
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// scanPosBits cursor 中槽位位置占用的位数，高位记录槽位压缩次数
+const scanPosBits = 48
+
+// minSlotHoles 空洞数量超过它，并且超过槽位数量的一半时压缩槽位
+const minSlotHoles = 1024
+
+// maxSlotRemaps 保留的压缩映射数量. 两次 Scan 调用之间压缩次数超过它时，游标从头开始
+const maxSlotRemaps = 4
+
+// slotRemap 一次压缩(或清空)的槽位映射: epoch 为压缩前的次数，kept 为保留的 key 在压缩前的位置(递增)
+type slotRemap struct {
+	epoch uint64
+	kept  []int
+}
+
+// Scan incrementally iterates the valid keys in bounded chunks, like the Redis SCAN command.
+//
+// Start with cursor 0, and call again with the returned next cursor, until next is 0.
+// count is the max number of keys returned per call, default is 10.
+//
+// Keys present during the full iteration are returned exactly once. The cursor stays valid
+// when the internal slots are compacted, unless more than 4 compactions happen between two
+// calls, then the iteration restarts and a key may be returned multiple times.
+//
+// The new keys are appended to the end of the iteration. The full iteration terminates if
+// fewer keys are added between two calls than the count, eg: a smaller count for the
+// write-heavy caches may never reach the end.
+//
+// Usage:
+//
+//	var cursor uint64
+//	for {
+//		var keys []string
+//		keys, cursor = c.Scan(cursor, 100)
+//		// handle keys ...
+//		if cursor == 0 {
+//			break
+//		}
+//	}
+func (c *Cache) Scan(cursor uint64, count int) (keys []string, next uint64) {
+	if count <= 0 {
+		count = 10
+	}
+
+	if !c.rlock() {
+		return nil, cursor
+	}
+	defer c.mu.RUnlock()
+
+	pos := c.cursorPos(cursor)
+
+	// 限制单次访问的槽位数量，避免命名空间视图长时间持有锁
+	nowUm := time.Now().UnixMilli()
+	maxVisit := pos + count*10
+	for ; pos < len(c.slots) && len(keys) < count && pos < maxVisit; pos++ {
+		key := c.slots[pos]
+		if idx, ok := c.slotIdx[key]; !ok || idx != pos {
+			continue // 空洞
+		}
+		if it := c.items[key]; it == nil || c.invalid(it, nowUm) {
+			continue
+		}
+
+		if c.ns != "" {
+			if !strings.HasPrefix(key, c.ns) {
+				continue
+			}
+			key = key[len(c.ns):]
+		}
+		keys = append(keys, key)
+	}
+
+	if pos >= len(c.slots) {
+		return keys, 0
+	}
+	return keys, c.slotEpoch<<scanPosBits | uint64(pos)
+}
+
+// cursorPos 获取游标在当前槽位中的位置. 槽位已压缩时，通过压缩映射转换位置 (不加锁)
+func (c *Cache) cursorPos(cursor uint64) int {
+	const epochMask = 1<<(64-scanPosBits) - 1
+	pos := int(cursor & (1<<scanPosBits - 1))
+	epoch := cursor >> scanPosBits
+	for epoch != c.slotEpoch&epochMask {
+		i := slices.IndexFunc(c.slotRemaps, func(r slotRemap) bool { return r.epoch&epochMask == epoch })
+		if i < 0 {
+			return 0 // 映射已丢弃，从头开始迭代
+		}
+
+		// 压缩保持顺序，新位置为 pos 之前保留的 key 数量
+		pos, _ = slices.BinarySearch(c.slotRemaps[i].kept, pos)
+		epoch = (epoch + 1) & epochMask
+	}
+	return pos
+}
+
+// addSlotRemap 记录一次压缩的槽位映射，只保留最近的几次 (不加锁)
+func (c *Cache) addSlotRemap(kept []int) {
+	if len(c.slotRemaps) >= maxSlotRemaps {
+		c.slotRemaps = slices.Delete(c.slotRemaps, 0, 1)
+	}
+	c.slotRemaps = append(c.slotRemaps, slotRemap{epoch: c.slotEpoch, kept: kept})
+	c.slotEpoch++
+}
+
+// addSlot 为新的 key 分配槽位 (不加锁)
+func (c *Cache) addSlot(key string) {
+	c.slotIdx[key] = len(c.slots)
+	c.slots = append(c.slots, key)
+}
+
+// delSlot 删除 key 的槽位，留下空洞 (不加锁)
+func (c *Cache) delSlot(key string) {
+	if _, ok := c.slotIdx[key]; !ok {
+		return
+	}
+
+	delete(c.slotIdx, key)
+	c.slotHoles++
+	if c.slotHoles > minSlotHoles && c.slotHoles > len(c.slots)/2 {
+		c.compactSlots()
+	}
+}
+
+// renameSlot 重命名 key，保持槽位位置不变 (不加锁)
+func (c *Cache) renameSlot(key, newKey string) {
+	if idx, ok := c.slotIdx[key]; ok {
+		delete(c.slotIdx, key)
+		c.slots[idx] = newKey
+		c.slotIdx[newKey] = idx
+	}
+}
+
+// compactSlots 压缩槽位，移除空洞并保持原有顺序 (不加锁)
+func (c *Cache) compactSlots() {
+	slots := make([]string, 0, len(c.slotIdx))
+	kept := make([]int, 0, len(c.slotIdx))
+	for i, key := range c.slots {
+		if idx, ok := c.slotIdx[key]; ok && idx == i {
+			c.slotIdx[key] = len(slots)
+			slots = append(slots, key)
+			kept = append(kept, i)
+		}
+	}
+
+	c.slots = slots
+	c.slotHoles = 0
+	c.addSlotRemap(kept)
+}
+
+// resetSlots 清空槽位 (不加锁)
+func (c *Cache) resetSlots() {
+	c.slots = nil
+	c.slotIdx = make(map[string]int)
+	c.slotHoles = 0
+	// 所有的 key 已删除，旧的游标都转换到开头
+	c.addSlotRemap(nil)
+}
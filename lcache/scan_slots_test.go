@@ -0,0 +1,39 @@
+package lcache
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_compactSlots_deletedFirst(t *testing.T) {
+	c := New(WithCapacity(5000))
+	for i := 0; i < 3000; i++ {
+		c.Set("key"+strconv.Itoa(i), i, 0)
+	}
+
+	// the deleted key at slot 0 is not kept by the compaction
+	c.Delete("key0")
+	for i := 1; i < 2000; i++ {
+		c.Delete("key" + strconv.Itoa(i))
+	}
+	assert.Eq(t, 1000, c.Len())
+	assert.Len(t, c.slotIdx, 1000)
+	_, ok := c.slotIdx["key0"]
+	assert.False(t, ok)
+
+	var keys []string
+	var cursor uint64
+	for {
+		var part []string
+		part, cursor = c.Scan(cursor, 100)
+		keys = append(keys, part...)
+		if cursor == 0 {
+			break
+		}
+	}
+	assert.Len(t, keys, 1000)
+	assert.NotContains(t, keys, "key0")
+	assert.Eq(t, "key2000", keys[0])
+}
@@ -0,0 +1,86 @@
+package lcache_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func scanAll(c *lcache.Cache, count int, fn func()) map[string]int {
+	seen := make(map[string]int)
+	var cursor uint64
+	for {
+		var keys []string
+		keys, cursor = c.Scan(cursor, count)
+		for _, key := range keys {
+			seen[key]++
+		}
+		if fn != nil {
+			fn()
+		}
+		if cursor == 0 {
+			return seen
+		}
+	}
+}
+
+func TestCache_Scan(t *testing.T) {
+	c := lcache.New(lcache.WithCapacity(5000))
+	for i := 0; i < 100; i++ {
+		c.Set("key"+strconv.Itoa(i), i, 0)
+	}
+
+	seen := scanAll(c, 7, nil)
+	assert.Len(t, seen, 100)
+	assert.Eq(t, 1, seen["key99"])
+
+	// deleted keys are skipped
+	c.MDelete("key1", "key2")
+	seen = scanAll(c, 0, nil)
+	assert.Len(t, seen, 98)
+
+	// namespace view
+	ns := c.Namespace("ns")
+	ns.Set("a", 1, 0)
+	ns.Set("b", 2, 0)
+	assert.Eq(t, map[string]int{"a": 1, "b": 1}, scanAll(ns, 10, nil))
+
+	// keys present during the iteration are returned once, even with compaction
+	c.Clear()
+	for i := 0; i < 3000; i++ {
+		c.Set("key"+strconv.Itoa(i), i, 0)
+	}
+
+	n := 0
+	seen = scanAll(c, 100, func() {
+		// delete the keys with index >= 1000, will trigger compaction
+		for j := 0; j < 300 && n < 2000; j++ {
+			c.Delete("key" + strconv.Itoa(1000+n))
+			n++
+		}
+	})
+	for i := 0; i < 1000; i++ {
+		assert.Eq(t, 1, seen["key"+strconv.Itoa(i)])
+	}
+
+	// steady churn: add and delete the keys between the calls, terminates
+	c.Clear()
+	for i := 0; i < 3000; i++ {
+		c.Set("key"+strconv.Itoa(i), i, 0)
+	}
+	n, calls := 0, 0
+	seen = scanAll(c, 100, func() {
+		calls++
+		for j := 0; j < 50; j++ {
+			c.Delete("key" + strconv.Itoa(n))
+			c.Set("new"+strconv.Itoa(n), n, 0)
+			n++
+		}
+	})
+	assert.Lt(t, calls, 200)
+	for i := n; i < 3000; i++ {
+		assert.Eq(t, 1, seen["key"+strconv.Itoa(i)])
+	}
+}
@@ -0,0 +1,190 @@
+package lcache
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// Scheduler runs the periodic maintenance jobs(eg: auto-save) of the caches.
+//
+// Applications embedding many caches can share one scheduler to reduce the idle wakeups. see WithScheduler
+type Scheduler interface {
+	// Every run fn on every interval, returns a func to stop it.
+	Every(interval time.Duration, fn func()) (stop func())
+	// After run fn once after the duration, returns a func to cancel it.
+	After(d time.Duration, fn func()) (stop func())
+}
+
+// goScheduler the default scheduler, start a goroutine for each job.
+type goScheduler struct{}
+
+// Every implements Scheduler
+func (goScheduler) Every(interval time.Duration, fn func()) func() {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				fn()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(stop) }) }
+}
+
+// After implements Scheduler
+func (goScheduler) After(d time.Duration, fn func()) func() {
+	t := time.AfterFunc(d, fn)
+	return func() { t.Stop() }
+}
+
+// SharedScheduler runs all jobs on one goroutine with a single timer.
+//
+// NOTE: the jobs are run in sequence, so the job func should return quickly.
+type SharedScheduler struct {
+	mu   sync.Mutex
+	jobs jobHeap
+	// wake 有新的任务加入时唤醒调度 goroutine
+	wake chan struct{}
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewSharedScheduler create a shared scheduler and start it.
+func NewSharedScheduler() *SharedScheduler {
+	s := &SharedScheduler{
+		wake: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+	}
+
+	go s.run()
+	return s
+}
+
+// Every implements Scheduler
+func (s *SharedScheduler) Every(interval time.Duration, fn func()) func() {
+	return s.add(&schedJob{at: time.Now().Add(interval), every: interval, fn: fn})
+}
+
+// After implements Scheduler
+func (s *SharedScheduler) After(d time.Duration, fn func()) func() {
+	return s.add(&schedJob{at: time.Now().Add(d), fn: fn})
+}
+
+// Stop the scheduler, all pending jobs will be dropped.
+func (s *SharedScheduler) Stop() {
+	s.once.Do(func() { close(s.stop) })
+}
+
+func (s *SharedScheduler) add(job *schedJob) func() {
+	s.mu.Lock()
+	heap.Push(&s.jobs, job)
+	s.mu.Unlock()
+	s.notify()
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if job.index >= 0 {
+			heap.Remove(&s.jobs, job.index)
+		}
+	}
+}
+
+func (s *SharedScheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *SharedScheduler) run() {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		wait := time.Hour
+		if len(s.jobs) > 0 {
+			wait = time.Until(s.jobs[0].at)
+		}
+		s.mu.Unlock()
+
+		timer.Reset(wait)
+		select {
+		case <-s.stop:
+			return
+		case <-s.wake:
+			continue
+		case <-timer.C:
+		}
+
+		for _, job := range s.popDue(time.Now()) {
+			job.fn()
+		}
+	}
+}
+
+// popDue 取出所有到期的任务，周期任务会重新加入队列
+func (s *SharedScheduler) popDue(now time.Time) []*schedJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*schedJob
+	for len(s.jobs) > 0 && !s.jobs[0].at.After(now) {
+		job := s.jobs[0]
+		due = append(due, job)
+
+		if job.every > 0 {
+			job.at = now.Add(job.every)
+			heap.Fix(&s.jobs, 0)
+		} else {
+			heap.Pop(&s.jobs)
+		}
+	}
+	return due
+}
+
+// schedJob 调度任务
+type schedJob struct {
+	at    time.Time
+	every time.Duration
+	fn    func()
+	// index 在堆中的位置，-1 表示已不在堆中
+	index int
+}
+
+// jobHeap 按执行时间排序的最小堆
+type jobHeap []*schedJob
+
+func (h jobHeap) Len() int           { return len(h) }
+func (h jobHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+func (h jobHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *jobHeap) Push(x any) {
+	job := x.(*schedJob)
+	job.index = len(*h)
+	*h = append(*h, job)
+}
+
+func (h *jobHeap) Pop() any {
+	old := *h
+	n := len(old)
+	job := old[n-1]
+	old[n-1] = nil
+	job.index = -1
+	*h = old[:n-1]
+	return job
+}
@@ -0,0 +1,50 @@
+package lcache_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestSharedScheduler(t *testing.T) {
+	s := lcache.NewSharedScheduler()
+	defer s.Stop()
+
+	var every, after, canceled atomic.Int32
+	stop := s.Every(20*time.Millisecond, func() { every.Add(1) })
+	s.After(30*time.Millisecond, func() { after.Add(1) })
+	cancel := s.After(30*time.Millisecond, func() { canceled.Add(1) })
+	cancel()
+
+	time.Sleep(110 * time.Millisecond)
+	stop()
+	n := every.Load()
+	assert.Gt(t, n, int32(2))
+	assert.Eq(t, int32(1), after.Load())
+	assert.Eq(t, int32(0), canceled.Load())
+
+	// stopped job is not run anymore
+	time.Sleep(50 * time.Millisecond)
+	assert.Eq(t, n, every.Load())
+}
+
+func TestCache_WithScheduler(t *testing.T) {
+	s := lcache.NewSharedScheduler()
+	defer s.Stop()
+
+	dir := t.TempDir()
+	c1 := lcache.New(lcache.WithScheduler(s), lcache.WithAutoSave(dir+"/c1.json", 30*time.Millisecond))
+	c2 := lcache.New(lcache.WithScheduler(s), lcache.WithAutoSave(dir+"/c2.json", 30*time.Millisecond))
+	c1.Set("key1", "value1", 0)
+	c2.Set("key2", "value2", 0)
+
+	time.Sleep(80 * time.Millisecond)
+	assert.True(t, fsutil.FileExists(dir+"/c1.json"))
+	assert.True(t, fsutil.FileExists(dir+"/c2.json"))
+	assert.NoErr(t, c1.Close())
+	assert.NoErr(t, c2.Close())
+}
@@ -0,0 +1,86 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// Gob builtin serializer: gob
+//
+// The cached values are stored as interface, so their concrete types must be registered
+// by gob.Register before decode. see GobRegister
+type Gob struct{}
+
+// Decode implements Serializer
+func (g Gob) Decode(data []byte, dest any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(dest)
+}
+
+// Encode implements Serializer
+func (g Gob) Encode(data any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeFrom implements Serializer
+func (g Gob) DecodeFrom(r io.Reader, dest any) error {
+	return gob.NewDecoder(r).Decode(dest)
+}
+
+// EncodeTo implements Serializer
+func (g Gob) EncodeTo(w io.Writer, src any) error {
+	return gob.NewEncoder(w).Encode(src)
+}
+
+// gobTypes 已注册到 gob 的类型，避免重复调用 gob.Register
+var gobTypes sync.Map
+
+func init() {
+	GobRegister(map[string]any{}, []any{})
+}
+
+// GobRegister register the value types to gob, for encode/decode the cached values by Gob serializer.
+//
+// Each type is registered once, it is cheap to call on every write. The types already
+// registered by gob.Register or gob.RegisterName under another name are skipped.
+//
+// NOTE: gob only allows register one of T and *T, the values are decoded as the first registered one.
+//
+// Usage:
+//
+//	serializer.GobRegister(User{}, &Order{})
+func GobRegister(values ...any) {
+	for _, val := range values {
+		if val == nil {
+			continue
+		}
+
+		// 已处理过的类型直接跳过，避免每次写入都去除指针和注册
+		rawTyp := reflect.TypeOf(val)
+		if _, ok := gobTypes.Load(rawTyp); ok {
+			continue
+		}
+
+		// gob 按去除指针后的基础类型注册，T 和 *T 只能注册一次
+		typ := rawTyp
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		if _, loaded := gobTypes.LoadOrStore(typ, struct{}{}); !loaded {
+			gobRegister(val)
+		}
+		gobTypes.Store(rawTyp, struct{}{})
+	}
+}
+
+// gobRegister 注册类型到 gob. 类型已使用其他名称注册时 gob.Register 会 panic，忽略即可
+func gobRegister(val any) {
+	defer func() { _ = recover() }()
+	gob.Register(val)
+}
@@ -0,0 +1,29 @@
+package serializer
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSON builtin serializer: json
+type JSON struct{}
+
+// Decode implements Serializer
+func (j JSON) Decode(data []byte, dest any) error {
+	return json.Unmarshal(data, dest)
+}
+
+// Encode implements Serializer
+func (j JSON) Encode(data any) ([]byte, error) {
+	return json.Marshal(data)
+}
+
+// DecodeFrom implements Serializer
+func (j JSON) DecodeFrom(r io.Reader, dest any) error {
+	return json.NewDecoder(r).Decode(dest)
+}
+
+// EncodeTo implements Serializer
+func (j JSON) EncodeTo(w io.Writer, src any) error {
+	return json.NewEncoder(w).Encode(src)
+}
@@ -0,0 +1,91 @@
+// Package serializer provides the Serializer interface, builtin serializers and
+// a goroutine-safe Registry for manage serializers by name.
+package serializer
+
+import (
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/gookit/goutil/comdef"
+)
+
+// Serializer interface for encode/decode data, support streaming.
+type Serializer interface {
+	comdef.Codec
+	DecodeFrom(r io.Reader, dest any) error
+	EncodeTo(w io.Writer, src any) error
+}
+
+// Registry a goroutine-safe serializer registry, manage serializers by name.
+type Registry struct {
+	mu sync.RWMutex
+	m  map[string]Serializer
+}
+
+// NewRegistry create a new Registry, with the builtin serializers: json, gob
+func NewRegistry() *Registry {
+	return &Registry{m: map[string]Serializer{
+		"json": JSON{},
+		"gob":  Gob{},
+	}}
+}
+
+// Set add or replace a serializer by name. if s is nil, delete it
+func (r *Registry) Set(name string, s Serializer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s != nil {
+		r.m[name] = s
+	} else {
+		delete(r.m, name)
+	}
+}
+
+// Get the serializer by name
+func (r *Registry) Get(name string) (Serializer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.m[name]
+	return s, ok
+}
+
+// Has check the serializer name is registered
+func (r *Registry) Has(name string) bool {
+	_, ok := r.Get(name)
+	return ok
+}
+
+// NameOf get the registered name of the serializer instance, the smallest name
+// if it is registered by multiple names.
+func (r *Registry) NameOf(s Serializer) (string, bool) {
+	if s == nil || !reflect.TypeOf(s).Comparable() {
+		return "", false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found string
+	for name, rs := range r.m {
+		if rs == s && (found == "" || name < found) {
+			found = name
+		}
+	}
+	return found, found != ""
+}
+
+// Names get all registered serializer names, sorted by name.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.m))
+	for name := range r.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
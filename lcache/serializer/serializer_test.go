@@ -0,0 +1,61 @@
+package serializer_test
+
+import (
+	"encoding/gob"
+	"testing"
+
+	"github.com/gookit/ext/lcache/serializer"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	r := serializer.NewRegistry()
+	assert.Eq(t, []string{"gob", "json"}, r.Names())
+	assert.True(t, r.Has("json"))
+
+	r.Set("json2", serializer.JSON{})
+	s, ok := r.Get("json2")
+	assert.True(t, ok)
+
+	bs, err := s.Encode(map[string]any{"a": 1})
+	assert.NoErr(t, err)
+	assert.Eq(t, `{"a":1}`, string(bs))
+
+	name, ok := r.NameOf(serializer.Gob{})
+	assert.True(t, ok)
+	assert.Eq(t, "gob", name)
+	name, _ = r.NameOf(serializer.JSON{})
+	assert.Eq(t, "json", name)
+
+	r.Set("json2", nil)
+	assert.False(t, r.Has("json2"))
+	_, ok = r.NameOf(nil)
+	assert.False(t, ok)
+}
+
+type gobUser struct {
+	Name string
+}
+
+func TestGob(t *testing.T) {
+	serializer.GobRegister(gobUser{}, &gobUser{})
+
+	var g serializer.Gob
+	bs, err := g.Encode(map[string]any{"u": gobUser{Name: "inhere"}})
+	assert.NoErr(t, err)
+
+	var m map[string]any
+	assert.NoErr(t, g.Decode(bs, &m))
+	assert.Eq(t, gobUser{Name: "inhere"}, m["u"])
+
+	// registered under another name, not panic
+	type gobOrder struct{ ID int }
+	gob.RegisterName("my-order", gobOrder{})
+	assert.NotPanics(t, func() {
+		serializer.GobRegister(gobOrder{}, &gobOrder{})
+	})
+	bs, err = g.Encode(map[string]any{"o": gobOrder{ID: 1}})
+	assert.NoErr(t, err)
+	assert.NoErr(t, g.Decode(bs, &m))
+	assert.Eq(t, gobOrder{ID: 1}, m["o"])
+}
@@ -0,0 +1,154 @@
+package lcache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// SerializerMiddleware transform the encoded bytes of a Serializer. see WrapSerializer
+type SerializerMiddleware interface {
+	// Wrap transform the data after encoded by the base serializer
+	Wrap(data []byte) ([]byte, error)
+	// Unwrap restore the data before decoded by the base serializer
+	Unwrap(data []byte) ([]byte, error)
+}
+
+// MiddlewareFuncs quick create a SerializerMiddleware by functions
+type MiddlewareFuncs struct {
+	WrapFn   func(data []byte) ([]byte, error)
+	UnwrapFn func(data []byte) ([]byte, error)
+}
+
+// Wrap implements SerializerMiddleware
+func (m MiddlewareFuncs) Wrap(data []byte) ([]byte, error) { return m.WrapFn(data) }
+
+// Unwrap implements SerializerMiddleware
+func (m MiddlewareFuncs) Unwrap(data []byte) ([]byte, error) { return m.UnwrapFn(data) }
+
+// WrapSerializer layer middlewares over the base serializer.
+//
+// On encode, the middlewares are applied in order. On decode, in reverse order.
+//
+// Usage:
+//
+//	s := lcache.WrapSerializer(lcache.JSONSerializer{}, lcache.GzipMiddleware(), lcache.AESMiddleware(key))
+//	lcache.SetSerializer("json-gz-aes", s)
+func WrapSerializer(base Serializer, mw ...SerializerMiddleware) Serializer {
+	return &wrappedSerializer{base: base, mws: mw}
+}
+
+// wrappedSerializer 包装了中间件的序列化器
+type wrappedSerializer struct {
+	base Serializer
+	mws  []SerializerMiddleware
+}
+
+// Encode implements Serializer
+func (s *wrappedSerializer) Encode(data any) ([]byte, error) {
+	bs, err := s.base.Encode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, mw := range s.mws {
+		if bs, err = mw.Wrap(bs); err != nil {
+			return nil, err
+		}
+	}
+	return bs, nil
+}
+
+// Decode implements Serializer
+func (s *wrappedSerializer) Decode(data []byte, dest any) (err error) {
+	for i := len(s.mws) - 1; i >= 0; i-- {
+		if data, err = s.mws[i].Unwrap(data); err != nil {
+			return err
+		}
+	}
+	return s.base.Decode(data, dest)
+}
+
+// DecodeFrom implements Serializer
+func (s *wrappedSerializer) DecodeFrom(r io.Reader, dest any) error {
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return s.Decode(bs, dest)
+}
+
+// EncodeTo implements Serializer
+func (s *wrappedSerializer) EncodeTo(w io.Writer, src any) error {
+	bs, err := s.Encode(src)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(bs)
+	return err
+}
+
+// GzipMiddleware compress the encoded data with gzip
+func GzipMiddleware() SerializerMiddleware {
+	return MiddlewareFuncs{
+		WrapFn: func(data []byte) ([]byte, error) {
+			var buf bytes.Buffer
+			gw := gzip.NewWriter(&buf)
+			if _, err := gw.Write(data); err != nil {
+				return nil, err
+			}
+			if err := gw.Close(); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		},
+		UnwrapFn: func(data []byte) ([]byte, error) {
+			gr, err := gzip.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			defer gr.Close()
+			return io.ReadAll(gr)
+		},
+	}
+}
+
+// AESMiddleware encrypt the encoded data with AES-GCM.
+//
+// The key length must be 16, 24 or 32 bytes, will panic on invalid key.
+func AESMiddleware(key []byte) SerializerMiddleware {
+	if _, err := aes.NewCipher(key); err != nil {
+		panic("invalid encryption key: " + err.Error())
+	}
+
+	return MiddlewareFuncs{
+		WrapFn:   func(data []byte) ([]byte, error) { return aesGCMSeal(key, data) },
+		UnwrapFn: func(data []byte) ([]byte, error) { return aesGCMOpen(key, data) },
+	}
+}
+
+// CRCMiddleware append a CRC32 checksum to the encoded data, and verify it on decode.
+//
+// Returns ErrBadChecksum on decode if the data is corrupted.
+func CRCMiddleware() SerializerMiddleware {
+	return MiddlewareFuncs{
+		WrapFn: func(data []byte) ([]byte, error) {
+			return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(data)), nil
+		},
+		UnwrapFn: func(data []byte) ([]byte, error) {
+			if len(data) < 4 {
+				return nil, fmt.Errorf("%w: data too short", ErrBadChecksum)
+			}
+
+			n := len(data) - 4
+			if crc32.ChecksumIEEE(data[:n]) != binary.BigEndian.Uint32(data[n:]) {
+				return nil, ErrBadChecksum
+			}
+			return data[:n], nil
+		},
+	}
+}
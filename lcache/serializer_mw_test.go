@@ -0,0 +1,52 @@
+package lcache_test
+
+import (
+	"testing"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestWrapSerializer(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	s := lcache.WrapSerializer(lcache.JSONSerializer{},
+		lcache.GzipMiddleware(),
+		lcache.AESMiddleware(key),
+		lcache.CRCMiddleware(),
+	)
+
+	bs, err := s.Encode(map[string]any{"a": "b"})
+	assert.NoErr(t, err)
+	assert.NotContains(t, string(bs), `"a"`)
+
+	var m map[string]any
+	assert.NoErr(t, s.Decode(bs, &m))
+	assert.Eq(t, "b", m["a"])
+
+	// corrupted data
+	bs[0] ^= 0xff
+	assert.ErrIs(t, s.Decode(bs, &m), lcache.ErrBadChecksum)
+
+	assert.Panics(t, func() {
+		lcache.AESMiddleware([]byte("short"))
+	})
+}
+
+func TestWrapSerializer_saveLoad(t *testing.T) {
+	lcache.SetSerializer("json-gz-crc", lcache.WrapSerializer(lcache.JSONSerializer{},
+		lcache.GzipMiddleware(),
+		lcache.CRCMiddleware(),
+	))
+	defer lcache.SetSerializer("json-gz-crc", nil)
+
+	c := lcache.New(lcache.WithSerializer("json-gz-crc"))
+	c.MSet(map[string]any{"key1": "val1", "key2": "val2"}, 0)
+
+	filename := t.TempDir() + "/cache.dat"
+	assert.NoErr(t, c.SaveFile(filename))
+
+	c2 := lcache.New(lcache.WithSerializer("json-gz-crc"))
+	assert.NoErr(t, c2.LoadFile(filename))
+	assert.Eq(t, 2, c2.Len())
+	assert.Eq(t, "val2", c2.Val("key2"))
+}
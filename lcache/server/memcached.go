@@ -0,0 +1,257 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+)
+
+// mcMaxRelTime exptime 大于它时为 unix 时间戳(30天)
+const mcMaxRelTime = 60 * 60 * 24 * 30
+
+// mcMaxItem 单个值的最大长度，同 memcached 默认的 item 大小限制
+const mcMaxItem = 1 << 20
+
+// errMcTooLarge 值超出限制时关闭连接，未读取的数据无法跳过
+var errMcTooLarge = errors.New("object too large")
+
+// Memcached serves a cache over the memcached text protocol.
+//
+// Supported commands: get, gets, set, add, replace, delete, incr, decr, touch, version, quit.
+//
+// The values set by clients are stored as []byte, at most 1MB, the flags are not stored and
+// always returns 0. Other values in the cache are returned as: string, integers as decimal, others as JSON.
+//
+// Usage:
+//
+//	srv := server.NewMemcached(c)
+//	go srv.ListenAndServe("tcp", "127.0.0.1:11211")
+//	defer srv.Close()
+type Memcached struct {
+	base
+	c *lcache.Cache
+}
+
+// NewMemcached create a memcached protocol server for the cache
+func NewMemcached(c *lcache.Cache) *Memcached {
+	s := &Memcached{c: c}
+	s.handle = s.serveConn
+	return s
+}
+
+func (s *Memcached) serveConn(r *bufio.Reader, w *bufio.Writer) error {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			writeLine(w, "ERROR")
+			continue
+		}
+
+		cmd := strings.ToLower(args[0])
+		if cmd == "quit" {
+			return nil
+		}
+
+		if err = s.exec(cmd, args[1:], r, w); err != nil {
+			return err
+		}
+		if err = w.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+// exec 执行命令. 返回错误时关闭连接
+func (s *Memcached) exec(cmd string, args []string, r *bufio.Reader, w *bufio.Writer) error {
+	switch cmd {
+	case "get", "gets":
+		for _, key := range args {
+			val, ok := s.c.Get(key)
+			if !ok {
+				continue
+			}
+
+			bs := toBytes(val)
+			if cmd == "gets" {
+				writeLine(w, fmt.Sprintf("VALUE %s 0 %d 0", key, len(bs)))
+			} else {
+				writeLine(w, fmt.Sprintf("VALUE %s 0 %d", key, len(bs)))
+			}
+			_, _ = w.Write(bs)
+			writeLine(w, "")
+		}
+		writeLine(w, "END")
+	case "set", "add", "replace":
+		return s.store(cmd, args, r, w)
+	case "delete":
+		if len(args) < 1 {
+			writeLine(w, "ERROR")
+			break
+		}
+		reply(w, args[1:], s.c.Delete(args[0]), "DELETED", "NOT_FOUND")
+	case "incr", "decr":
+		s.incr(cmd == "incr", args, w)
+	case "touch":
+		if len(args) < 2 {
+			writeLine(w, "ERROR")
+			break
+		}
+
+		ttl, err := parseExptime(args[1])
+		if err != nil {
+			writeLine(w, "CLIENT_ERROR bad command line format")
+			break
+		}
+		reply(w, args[2:], s.c.Touch(args[0], ttl), "TOUCHED", "NOT_FOUND")
+	case "version":
+		writeLine(w, "VERSION lcache")
+	default:
+		writeLine(w, "ERROR")
+	}
+	return nil
+}
+
+// store 处理 set/add/replace 命令: <cmd> <key> <flags> <exptime> <bytes> [noreply]
+func (s *Memcached) store(cmd string, args []string, r *bufio.Reader, w *bufio.Writer) error {
+	if len(args) < 4 {
+		writeLine(w, "ERROR")
+		return nil
+	}
+
+	n, err := strconv.Atoi(args[3])
+	if err != nil || n < 0 {
+		writeLine(w, "CLIENT_ERROR bad command line format")
+		return nil
+	}
+	if n > mcMaxItem {
+		writeLine(w, "SERVER_ERROR object too large for cache")
+		return errMcTooLarge
+	}
+
+	data := make([]byte, n+2)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return err
+	}
+	if string(data[n:]) != "\r\n" {
+		writeLine(w, "CLIENT_ERROR bad data chunk")
+		return nil
+	}
+
+	ttl, err := parseExptime(args[2])
+	if err != nil {
+		writeLine(w, "CLIENT_ERROR bad command line format")
+		return nil
+	}
+
+	key := args[0]
+	unlock := s.c.LockKey(key)
+	defer unlock()
+
+	exists := s.c.Has(key)
+	if (cmd == "add" && exists) || (cmd == "replace" && !exists) {
+		reply(w, args[4:], false, "STORED", "NOT_STORED")
+		return nil
+	}
+
+	// 负数的 exptime 表示立即过期
+	if ttl < 0 {
+		s.c.Delete(key)
+	} else {
+		s.c.Set(key, data[:n], ttl)
+	}
+	reply(w, args[4:], true, "STORED", "NOT_STORED")
+	return nil
+}
+
+// incr 处理 incr/decr 命令: <cmd> <key> <value> [noreply]
+func (s *Memcached) incr(incr bool, args []string, w *bufio.Writer) {
+	if len(args) < 2 {
+		writeLine(w, "ERROR")
+		return
+	}
+
+	delta, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		writeLine(w, "CLIENT_ERROR invalid numeric delta argument")
+		return
+	}
+
+	key := args[0]
+	unlock := s.c.LockKey(key)
+	defer unlock()
+
+	val, ok := s.c.Get(key)
+	if !ok {
+		reply(w, args[2:], false, "", "NOT_FOUND")
+		return
+	}
+
+	num, err := strconv.ParseUint(string(toBytes(val)), 10, 64)
+	if err != nil {
+		writeLine(w, "CLIENT_ERROR cannot increment or decrement non-numeric value")
+		return
+	}
+
+	if incr {
+		num += delta
+	} else if delta > num {
+		num = 0 // decr 最小为 0
+	} else {
+		num -= delta
+	}
+
+	// 保持原有的 TTL
+	ttl, _ := s.c.TTL(key)
+	newVal := strconv.FormatUint(num, 10)
+	s.c.Set(key, []byte(newVal), ttl)
+	reply(w, args[2:], true, newVal, "")
+}
+
+// parseExptime 解析 exptime: 0 永不过期，负数立即过期，大于 30 天为 unix 时间戳
+func parseExptime(s string) (time.Duration, error) {
+	exp, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case exp < 0:
+		return -1, nil
+	case exp > mcMaxRelTime:
+		if ttl := time.Until(time.Unix(exp, 0)); ttl > 0 {
+			return ttl, nil
+		}
+		return -1, nil
+	default:
+		return time.Duration(exp) * time.Second, nil
+	}
+}
+
+// reply 输出命令结果，带有 noreply 参数时不输出
+func reply(w *bufio.Writer, rest []string, ok bool, okMsg, failMsg string) {
+	if len(rest) > 0 && rest[len(rest)-1] == "noreply" {
+		return
+	}
+
+	if ok {
+		writeLine(w, okMsg)
+	} else {
+		writeLine(w, failMsg)
+	}
+}
+
+func writeLine(w *bufio.Writer, line string) {
+	_, _ = w.WriteString(line)
+	_, _ = w.WriteString("\r\n")
+}
@@ -0,0 +1,99 @@
+package server_test
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/lcache/server"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+// mcClient send the command and read the response lines until the end line
+type mcClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (c *mcClient) do(t *testing.T, cmd string, lines int) string {
+	_, err := c.conn.Write([]byte(cmd))
+	assert.NoErr(t, err)
+
+	var sb strings.Builder
+	for i := 0; i < lines; i++ {
+		line, err := c.r.ReadString('\n')
+		assert.NoErr(t, err)
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+func TestMemcached(t *testing.T) {
+	c := lcache.New()
+	c.Set("str", "hello", 0)
+	c.Set("map", map[string]any{"a": 1}, 0)
+
+	srv := server.NewMemcached(c)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoErr(t, err)
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	assert.NoErr(t, err)
+	defer conn.Close()
+	mc := &mcClient{conn: conn, r: bufio.NewReader(conn)}
+
+	assert.Eq(t, "VALUE str 0 5\r\nhello\r\nVALUE map 0 7\r\n{\"a\":1}\r\nEND\r\n", mc.do(t, "get str map none\r\n", 5))
+	assert.Eq(t, "STORED\r\n", mc.do(t, "set key1 0 60 3\r\nabc\r\n", 1))
+	assert.Eq(t, []byte("abc"), c.Val("key1"))
+	assert.Eq(t, "VALUE key1 0 3 0\r\nabc\r\nEND\r\n", mc.do(t, "gets key1\r\n", 3))
+
+	assert.Eq(t, "NOT_STORED\r\n", mc.do(t, "add key1 0 0 1\r\nx\r\n", 1))
+	assert.Eq(t, "NOT_STORED\r\n", mc.do(t, "replace key2 0 0 1\r\nx\r\n", 1))
+	assert.Eq(t, "STORED\r\n", mc.do(t, "add num 0 0 2\r\n10\r\n", 1))
+
+	assert.Eq(t, "15\r\n", mc.do(t, "incr num 5\r\n", 1))
+	assert.Eq(t, "0\r\n", mc.do(t, "decr num 20\r\n", 1))
+	assert.Eq(t, "NOT_FOUND\r\n", mc.do(t, "incr none 1\r\n", 1))
+	assert.Contains(t, mc.do(t, "incr key1 1\r\n", 1), "CLIENT_ERROR")
+
+	assert.Eq(t, "TOUCHED\r\n", mc.do(t, "touch key1 3600\r\n", 1))
+	ttl, _ := c.TTL("key1")
+	assert.True(t, ttl > time.Minute)
+
+	assert.Eq(t, "DELETED\r\n", mc.do(t, "delete key1\r\n", 1))
+	assert.Eq(t, "NOT_FOUND\r\n", mc.do(t, "delete key1\r\n", 1))
+	// noreply
+	assert.Eq(t, "END\r\n", mc.do(t, "delete num noreply\r\nget num\r\n", 1))
+
+	assert.Eq(t, "VERSION lcache\r\n", mc.do(t, "version\r\n", 1))
+	assert.Eq(t, "ERROR\r\n", mc.do(t, "unknown\r\n", 1))
+	assert.Eq(t, "CLIENT_ERROR bad data chunk\r\n", mc.do(t, "set k 0 0 1\r\nabc\r\n", 1))
+}
+
+func TestMemcached_badInput(t *testing.T) {
+	c := lcache.New()
+	srv := server.NewMemcached(c)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoErr(t, err)
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	assert.NoErr(t, err)
+	defer conn.Close()
+	mc := &mcClient{conn: conn, r: bufio.NewReader(conn)}
+
+	assert.Eq(t, "CLIENT_ERROR bad command line format\r\n", mc.do(t, "set k 0 0 -1\r\n", 1))
+	assert.Eq(t, "CLIENT_ERROR bad command line format\r\n", mc.do(t, "set k 0 0 abc\r\n", 1))
+	assert.Eq(t, "SERVER_ERROR object too large for cache\r\n", mc.do(t, "set k 0 0 99999999999\r\n", 1))
+
+	// the connection is closed
+	_, err = mc.r.ReadString('\n')
+	assert.Err(t, err)
+	assert.False(t, c.Has("k"))
+}
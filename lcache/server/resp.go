@@ -0,0 +1,329 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+)
+
+// respMaxBulk 单个参数的最大长度
+const respMaxBulk = 1 << 20
+
+// errRESPProtocol 客户端发送的数据不符合 RESP 协议
+var errRESPProtocol = errors.New("protocol error")
+
+// respWriteCmds 常见的写命令，返回只读错误
+var respWriteCmds = map[string]bool{
+	"set": true, "setex": true, "mset": true, "del": true, "unlink": true, "expire": true,
+	"incr": true, "decr": true, "flushdb": true, "flushall": true, "rename": true,
+}
+
+// RESP serves a cache over the Redis protocol(RESP), read-only.
+//
+// It lets operators inspect the live cache with redis-cli. Supported commands:
+// GET, MGET, EXISTS, TTL, PTTL, KEYS, SCAN, DBSIZE, INFO, PING, COMMAND, QUIT.
+//
+// GET and MGET do not update the LRU order and do not trigger the loaders. see lcache.Cache.Peek
+//
+// Values are returned as bulk strings: []byte and string as is, integers as decimal, others as JSON.
+//
+// Usage:
+//
+//	srv := server.NewRESP(c)
+//	go srv.ListenAndServe("tcp", "127.0.0.1:6380")
+//	defer srv.Close()
+//
+//	// redis-cli -p 6380 scan 0 match "user:*"
+type RESP struct {
+	base
+	c *lcache.Cache
+}
+
+// NewRESP create a read-only RESP protocol server for the cache
+func NewRESP(c *lcache.Cache) *RESP {
+	s := &RESP{c: c}
+	s.handle = s.serveConn
+	return s
+}
+
+func (s *RESP) serveConn(r *bufio.Reader, w *bufio.Writer) error {
+	for {
+		args, err := readRESPCommand(r)
+		if err != nil {
+			if errors.Is(err, errRESPProtocol) {
+				writeRESPError(w, "ERR Protocol error")
+			}
+			return err
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		cmd := strings.ToLower(args[0])
+		if cmd == "quit" {
+			_, _ = w.WriteString("+OK\r\n")
+			return nil
+		}
+
+		s.exec(cmd, args[1:], w)
+		if err = w.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *RESP) exec(cmd string, args []string, w *bufio.Writer) {
+	switch cmd {
+	case "ping":
+		if len(args) > 0 {
+			writeRESPBulk(w, []byte(args[0]))
+		} else {
+			_, _ = w.WriteString("+PONG\r\n")
+		}
+	case "get":
+		if !checkArgs(w, cmd, args, 1) {
+			return
+		}
+		if val, ok := s.c.Peek(args[0]); ok {
+			writeRESPBulk(w, toBytes(val))
+		} else {
+			writeRESPBulk(w, nil)
+		}
+	case "mget":
+		if len(args) == 0 {
+			writeArgsError(w, cmd)
+			return
+		}
+
+		writeRESPLen(w, '*', len(args))
+		for _, key := range args {
+			if val, ok := s.c.Peek(key); ok {
+				writeRESPBulk(w, toBytes(val))
+			} else {
+				writeRESPBulk(w, nil)
+			}
+		}
+	case "exists":
+		var n int
+		for _, key := range args {
+			if s.c.Has(key) {
+				n++
+			}
+		}
+		writeRESPLen(w, ':', n)
+	case "ttl", "pttl":
+		if !checkArgs(w, cmd, args, 1) {
+			return
+		}
+
+		ttl, ok := s.c.TTL(args[0])
+		switch {
+		case !ok:
+			writeRESPLen(w, ':', -2)
+		case ttl == 0:
+			writeRESPLen(w, ':', -1)
+		case cmd == "ttl":
+			writeRESPLen(w, ':', int((ttl+500*time.Millisecond)/time.Second))
+		default:
+			writeRESPLen(w, ':', int(ttl.Milliseconds()))
+		}
+	case "keys":
+		if !checkArgs(w, cmd, args, 1) {
+			return
+		}
+		writeRESPStrings(w, s.c.KeysMatch(args[0]))
+	case "scan":
+		s.scan(args, w)
+	case "dbsize":
+		writeRESPLen(w, ':', s.c.Len())
+	case "info":
+		writeRESPBulk(w, []byte(s.info()))
+	case "command":
+		// redis-cli 启动时会发送 COMMAND DOCS
+		writeRESPLen(w, '*', 0)
+	default:
+		if respWriteCmds[cmd] {
+			writeRESPError(w, "READONLY You can't write against a read only server.")
+		} else {
+			writeRESPError(w, fmt.Sprintf("ERR unknown command '%s'", cmd))
+		}
+	}
+}
+
+// scan 处理命令: SCAN cursor [MATCH pattern] [COUNT count]
+func (s *RESP) scan(args []string, w *bufio.Writer) {
+	if len(args) == 0 {
+		writeArgsError(w, "scan")
+		return
+	}
+
+	cursor, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		writeRESPError(w, "ERR invalid cursor")
+		return
+	}
+
+	var pattern string
+	count := 10
+	for i := 1; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			writeRESPError(w, "ERR syntax error")
+			return
+		}
+
+		switch strings.ToLower(args[i]) {
+		case "match":
+			pattern = args[i+1]
+		case "count":
+			if count, err = strconv.Atoi(args[i+1]); err != nil || count < 1 {
+				writeRESPError(w, "ERR value is not an integer or out of range")
+				return
+			}
+		default:
+			writeRESPError(w, "ERR syntax error")
+			return
+		}
+	}
+
+	keys, next := s.c.Scan(cursor, count)
+	// 与 Redis 一致，MATCH 在获取到一批 key 之后过滤
+	if pattern != "" {
+		matched := keys[:0]
+		for _, key := range keys {
+			if lcache.GlobMatch(pattern, key) {
+				matched = append(matched, key)
+			}
+		}
+		keys = matched
+	}
+
+	writeRESPLen(w, '*', 2)
+	writeRESPBulk(w, []byte(strconv.FormatUint(next, 10)))
+	writeRESPStrings(w, keys)
+}
+
+// info 生成 INFO 命令的输出
+func (s *RESP) info() string {
+	st := s.c.Stats()
+
+	var sb strings.Builder
+	sb.WriteString("# Server\r\nserver:lcache\r\n\r\n")
+	sb.WriteString("# Keyspace\r\n")
+	fmt.Fprintf(&sb, "keys:%d\r\nvalid_keys:%d\r\ngeneration:%d\r\n\r\n", st.Len, st.ValidLen, st.Generation)
+
+	sb.WriteString("# Persistence\r\n")
+	var lastSave int64
+	if !st.LastSaveAt.IsZero() {
+		lastSave = st.LastSaveAt.Unix()
+	}
+	fmt.Fprintf(&sb, "last_save_time:%d\r\n", lastSave)
+	fmt.Fprintf(&sb, "last_save_error:%s\r\n", errString(st.LastSaveErr))
+	fmt.Fprintf(&sb, "aof_error:%s\r\n", errString(st.AOFErr))
+	return sb.String()
+}
+
+// readRESPCommand 读取一个命令. 支持 RESP 数组和 inline 命令
+func readRESPCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readRESPLine(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) == 0 || line[0] != '*' {
+		return strings.Fields(line), nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 1 || n > 1024*1024 {
+		return nil, errRESPProtocol
+	}
+
+	args := make([]string, 0, min(n, 64))
+	for i := 0; i < n; i++ {
+		if line, err = readRESPLine(r); err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, errRESPProtocol
+		}
+
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 || size > respMaxBulk {
+			return nil, errRESPProtocol
+		}
+
+		buf := make([]byte, size+2)
+		if _, err = io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		if string(buf[size:]) != "\r\n" {
+			return nil, errRESPProtocol
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+// readRESPLine 读取一行，去除结尾的 \r\n
+func readRESPLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func checkArgs(w *bufio.Writer, cmd string, args []string, n int) bool {
+	if len(args) != n {
+		writeArgsError(w, cmd)
+		return false
+	}
+	return true
+}
+
+func writeArgsError(w *bufio.Writer, cmd string) {
+	writeRESPError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", cmd))
+}
+
+func writeRESPError(w *bufio.Writer, msg string) {
+	_, _ = w.WriteString("-" + msg + "\r\n")
+}
+
+// writeRESPLen 输出整数或数组长度. prefix: ':' 整数，'*' 数组，'$' 字符串
+func writeRESPLen(w *bufio.Writer, prefix byte, n int) {
+	_ = w.WriteByte(prefix)
+	_, _ = w.WriteString(strconv.Itoa(n))
+	_, _ = w.WriteString("\r\n")
+}
+
+// writeRESPBulk 输出 bulk string, nil 输出为 null
+func writeRESPBulk(w *bufio.Writer, bs []byte) {
+	if bs == nil {
+		_, _ = w.WriteString("$-1\r\n")
+		return
+	}
+
+	writeRESPLen(w, '$', len(bs))
+	_, _ = w.Write(bs)
+	_, _ = w.WriteString("\r\n")
+}
+
+func writeRESPStrings(w *bufio.Writer, ss []string) {
+	writeRESPLen(w, '*', len(ss))
+	for _, s := range ss {
+		writeRESPBulk(w, []byte(s))
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,97 @@
+package server_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/lcache/server"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestRESP(t *testing.T) {
+	c := lcache.New()
+	c.Set("user:1", "tom", 0)
+	c.Set("user:2", map[string]any{"name": "jack"}, time.Minute)
+	c.Set("num", 23, 0)
+
+	srv := server.NewRESP(c)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoErr(t, err)
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	assert.NoErr(t, err)
+	defer conn.Close()
+	rc := &mcClient{conn: conn, r: bufio.NewReader(conn)}
+
+	assert.Eq(t, "+PONG\r\n", rc.do(t, "*1\r\n$4\r\nPING\r\n", 1))
+	assert.Eq(t, "$3\r\ntom\r\n", rc.do(t, "*2\r\n$3\r\nGET\r\n$6\r\nuser:1\r\n", 2))
+	assert.Eq(t, "$-1\r\n", rc.do(t, "*2\r\n$3\r\nget\r\n$4\r\nnone\r\n", 1))
+	// inline command
+	assert.Eq(t, "*3\r\n$2\r\n23\r\n$-1\r\n$15\r\n{\"name\":\"jack\"}\r\n", rc.do(t, "mget num none user:2\r\n", 6))
+	assert.Eq(t, ":2\r\n", rc.do(t, "exists num user:1 none\r\n", 1))
+
+	assert.Eq(t, ":-1\r\n", rc.do(t, "ttl num\r\n", 1))
+	assert.Eq(t, ":-2\r\n", rc.do(t, "ttl none\r\n", 1))
+	assert.Eq(t, ":60\r\n", rc.do(t, "ttl user:2\r\n", 1))
+	assert.Eq(t, ":3\r\n", rc.do(t, "dbsize\r\n", 1))
+
+	keys := rc.do(t, "keys user:*\r\n", 5)
+	assert.StrContains(t, keys, "*2\r\n")
+	assert.StrContains(t, keys, "user:1")
+	assert.StrContains(t, keys, "user:2")
+
+	assert.Eq(t, "*2\r\n$1\r\n0\r\n*1\r\n$3\r\nnum\r\n", rc.do(t, "scan 0 match n* count 10\r\n", 6))
+	assert.Eq(t, "-ERR syntax error\r\n", rc.do(t, "scan 0 match\r\n", 1))
+
+	head := rc.do(t, "info\r\n", 1)
+	n, err := strconv.Atoi(strings.TrimSpace(head[1:]))
+	assert.NoErr(t, err)
+	info := make([]byte, n+2)
+	_, err = io.ReadFull(rc.r, info)
+	assert.NoErr(t, err)
+	assert.StrContains(t, string(info), "keys:3\r\n")
+
+	assert.StrContains(t, rc.do(t, "set key val\r\n", 1), "-READONLY")
+	assert.Eq(t, "-ERR unknown command 'foo'\r\n", rc.do(t, "foo\r\n", 1))
+	assert.Eq(t, "-ERR wrong number of arguments for 'get' command\r\n", rc.do(t, "get\r\n", 1))
+	assert.Eq(t, "+OK\r\n", rc.do(t, "quit\r\n", 1))
+}
+
+func TestRESP_badInput(t *testing.T) {
+	var loads int
+	c := lcache.New(lcache.WithLoader(func(context.Context, string) (any, time.Duration, error) {
+		loads++
+		return "loaded", 0, nil
+	}))
+
+	srv := server.NewRESP(c)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoErr(t, err)
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	for _, cmd := range []string{"*-1\r\n", "*0\r\n", "*99999999\r\n", "*1\r\n$-5\r\n"} {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		assert.NoErr(t, err)
+		rc := &mcClient{conn: conn, r: bufio.NewReader(conn)}
+		assert.Eq(t, "-ERR Protocol error\r\n", rc.do(t, cmd, 1), cmd)
+		conn.Close()
+	}
+
+	// GET does not trigger the loader
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	assert.NoErr(t, err)
+	defer conn.Close()
+	rc := &mcClient{conn: conn, r: bufio.NewReader(conn)}
+	assert.Eq(t, "$-1\r\n", rc.do(t, "get key1\r\n", 1))
+	assert.Eq(t, 0, loads)
+}
@@ -0,0 +1,145 @@
+// Package server exposes a running lcache.Cache over network protocols,
+// for debugging and sharing the in-process cache with sidecar tools.
+//
+// Supported protocols:
+//
+//   - memcached text protocol: get/gets/set/add/replace/delete/incr/decr/touch. see NewMemcached
+//   - RESP(Redis protocol), read-only: GET/MGET/EXISTS/TTL/PTTL/KEYS/SCAN/DBSIZE/INFO. see NewRESP
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// handler 处理一个客户端连接
+type handler func(r *bufio.Reader, w *bufio.Writer) error
+
+// base 通用的服务端实现: 监听、连接管理和关闭
+type base struct {
+	mu     sync.Mutex
+	ln     net.Listener
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+	handle handler
+}
+
+// ErrServerClosed returned by Serve after the server is closed
+var ErrServerClosed = errors.New("lcache/server: server closed")
+
+// ListenAndServe listen on the network address and serve. network can be "tcp" or "unix".
+func (s *base) ListenAndServe(network, addr string) error {
+	ln, err := net.Listen(network, addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+// Serve accept connections on the listener, blocks until the server is closed.
+func (s *base) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.ln = ln
+	s.conns = make(map[net.Conn]struct{})
+	s.mu.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		if !s.track(conn) {
+			_ = conn.Close()
+			return ErrServerClosed
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.untrack(conn)
+			// 处理出错时仅关闭当前连接，不影响整个进程
+			defer func() { _ = recover() }()
+			w := bufio.NewWriter(conn)
+			_ = s.handle(bufio.NewReader(conn), w)
+			_ = w.Flush()
+		}()
+	}
+}
+
+// Addr get the listen address, nil if not serving
+func (s *base) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ln == nil {
+		return nil
+	}
+	return s.ln.Addr()
+}
+
+// Close the listener and all connections, wait the handlers exit.
+func (s *base) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	var err error
+	if s.ln != nil {
+		err = s.ln.Close()
+	}
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+func (s *base) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *base) untrack(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+	_ = conn.Close()
+}
+
+// toBytes 将缓存中的值转换为字节数据
+func toBytes(val any) []byte {
+	switch v := val.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return []byte(fmt.Sprint(v))
+	}
+
+	bs, err := json.Marshal(val)
+	if err != nil {
+		return []byte(fmt.Sprint(val))
+	}
+	return bs
+}
@@ -0,0 +1,177 @@
+package lcache
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+// memberSet 集合类型的值，写入时复制. 使用 bool 值以支持 gob 编码
+type memberSet map[string]bool
+
+func init() {
+	// 从快照加载时还原为集合类型
+	RegisterType[memberSet]("lcache.set")
+}
+
+// SAdd add the members to the set stored at key, like Redis SADD. Returns the number of
+// the added members, not including the existing ones.
+//
+// Creates the set if the key does not exist or expired. ttl > 0 sets the TTL of the key on
+// every call, so the set expires after ttl since the last add; ttl <= 0 keeps the current TTL,
+// and the new set never expires.
+//
+// The set is copied on write under the cache lock, the set operations are local only,
+// not written to the Store. Returns ErrNotSet if the value of key is not a set,
+// ErrBusy, ErrFrozen, ErrCacheFull or a *SizeError. see WithMaxValueBytes
+func (c *Cache) SAdd(key string, ttl time.Duration, members ...string) (int, error) {
+	key, err := c.normKey(key)
+	if err != nil {
+		return 0, err
+	}
+	if !c.lock() {
+		return 0, ErrBusy
+	}
+	defer c.unlock()
+	if c.frozen.Load() {
+		return 0, ErrFrozen
+	}
+
+	nk := c.nsKey(key)
+	hk, it := c.find(nk)
+	old, ok := c.validSet(it)
+	if !ok && it != nil && !c.invalid(it, time.Now().UnixMilli()) {
+		return 0, ErrNotSet
+	}
+
+	set := make(memberSet, len(old)+len(members))
+	maps.Copy(set, old)
+	for _, m := range members {
+		set[m] = true
+	}
+
+	n := len(set) - len(old)
+	if n > 0 {
+		if err = c.checkSize(key, set); err != nil {
+			return 0, err
+		}
+	}
+	if !ok {
+		if n == 0 {
+			return 0, nil
+		}
+
+		exp := ttlToExp(ttl)
+		if c.set(nk, set, exp) == nil {
+			return 0, ErrCacheFull
+		}
+		return n, c.appendSet(nk, set, exp)
+	}
+
+	if ttl > 0 {
+		it.Exp = ttlToExp(ttl)
+		c.pushExp(it)
+	} else if n == 0 {
+		return 0, nil
+	}
+
+	it.Val = set
+	c.updateIndexes(hk, it)
+	c.touch(hk, it)
+	return n, c.appendSet(nk, set, it.Exp)
+}
+
+// SRem remove the members from the set stored at key, returns the number of the removed members.
+// The key is deleted when the set becomes empty.
+func (c *Cache) SRem(key string, members ...string) int {
+	key, err := c.normKey(key)
+	if err != nil || !c.lock() {
+		return 0
+	}
+	defer c.unlock()
+	if c.frozen.Load() {
+		return 0
+	}
+
+	nk := c.nsKey(key)
+	hk, it := c.find(nk)
+	old, ok := c.validSet(it)
+	if !ok {
+		return 0
+	}
+
+	set := maps.Clone(old)
+	for _, m := range members {
+		delete(set, m)
+	}
+
+	n := len(old) - len(set)
+	if n == 0 {
+		return 0
+	}
+	if len(set) == 0 {
+		c.removeElement(hk, ReasonDeleted)
+		_ = c.appendAOF(aofOpDel, nk, nil, 0)
+		return n
+	}
+
+	it.Val = set
+	c.updateIndexes(hk, it)
+	c.touch(hk, it)
+	_ = c.appendSet(nk, set, it.Exp)
+	return n
+}
+
+// SIsMember check the member is in the set stored at key.
+func (c *Cache) SIsMember(key, member string) bool {
+	key, err := c.normKey(key)
+	if err != nil || !c.rlock() {
+		return false
+	}
+	defer c.mu.RUnlock()
+
+	_, it := c.find(c.nsKey(key))
+	set, _ := c.validSet(it)
+	return set[member]
+}
+
+// SMembers get the sorted members of the set stored at key, nil if the set is missing.
+func (c *Cache) SMembers(key string) []string {
+	key, err := c.normKey(key)
+	if err != nil || !c.lockRead() {
+		return nil
+	}
+	defer c.unlockRead()
+
+	hk, it := c.find(c.nsKey(key))
+	set, ok := c.validSet(it)
+	if !ok {
+		return nil
+	}
+
+	c.touch(hk, it)
+	return slices.Sorted(maps.Keys(set))
+}
+
+// SCard get the number of members of the set stored at key, 0 if the set is missing or not a set.
+func (c *Cache) SCard(key string) int {
+	key, err := c.normKey(key)
+	if err != nil || !c.rlock() {
+		return 0
+	}
+	defer c.mu.RUnlock()
+
+	_, it := c.find(c.nsKey(key))
+	set, _ := c.validSet(it)
+	return len(set)
+}
+
+// validSet 获取有效数据项中的集合 (不加锁)
+func (c *Cache) validSet(it *Item) (memberSet, bool) {
+	if it == nil || c.invalid(it, time.Now().UnixMilli()) {
+		return nil, false
+	}
+
+	set, ok := it.Val.(memberSet)
+	return set, ok
+}
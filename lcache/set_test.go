@@ -0,0 +1,76 @@
+package lcache_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_SetOps(t *testing.T) {
+	c := lcache.New()
+
+	n, err := c.SAdd("seen", 0, "id1", "id2", "id1")
+	assert.NoErr(t, err)
+	assert.Eq(t, 2, n)
+	n, err = c.SAdd("seen", 0, "id2", "id3")
+	assert.NoErr(t, err)
+	assert.Eq(t, 1, n)
+
+	assert.True(t, c.SIsMember("seen", "id1"))
+	assert.False(t, c.SIsMember("seen", "id4"))
+	assert.False(t, c.SIsMember("not-exists", "id1"))
+	assert.Eq(t, 3, c.SCard("seen"))
+	assert.Eq(t, []string{"id1", "id2", "id3"}, c.SMembers("seen"))
+	assert.Nil(t, c.SMembers("not-exists"))
+
+	assert.Eq(t, 2, c.SRem("seen", "id1", "id3", "id4"))
+	assert.Eq(t, []string{"id2"}, c.SMembers("seen"))
+	assert.Eq(t, 1, c.SRem("seen", "id2"))
+	assert.False(t, c.Has("seen"))
+
+	n, err = c.SAdd("empty", 0)
+	assert.NoErr(t, err)
+	assert.Eq(t, 0, n)
+	assert.False(t, c.Has("empty"))
+
+	// not a set
+	c.Set("str", "abc", 0)
+	_, err = c.SAdd("str", 0, "a")
+	assert.True(t, errors.Is(err, lcache.ErrNotSet))
+	assert.Eq(t, 0, c.SRem("str", "a"))
+}
+
+func TestCache_SetOps_ttl(t *testing.T) {
+	c := lcache.New()
+	_, _ = c.SAdd("flags", 30*time.Millisecond, "a")
+	time.Sleep(20 * time.Millisecond)
+
+	// refresh the TTL
+	_, _ = c.SAdd("flags", 30*time.Millisecond, "b")
+	time.Sleep(20 * time.Millisecond)
+	assert.Eq(t, 2, c.SCard("flags"))
+
+	// keep the TTL
+	_, _ = c.SAdd("flags", 0, "c")
+	time.Sleep(20 * time.Millisecond)
+	assert.Eq(t, 0, c.SCard("flags"))
+}
+
+func TestCache_SetOps_snapshot(t *testing.T) {
+	c := lcache.New()
+	_, _ = c.SAdd("seen", 0, "id1", "id2")
+
+	file := filepath.Join(t.TempDir(), "set.json")
+	assert.NoErr(t, c.SaveFile(file))
+
+	nc := lcache.New()
+	assert.NoErr(t, nc.LoadFile(file))
+	assert.True(t, nc.SIsMember("seen", "id1"))
+	n, err := nc.SAdd("seen", 0, "id3")
+	assert.NoErr(t, err)
+	assert.Eq(t, 1, n)
+}
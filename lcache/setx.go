@@ -0,0 +1,176 @@
+package lcache
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SetOption option func for Cache.SetX
+type SetOption func(*setOptions)
+
+// setOptions SetX 的写入选项
+type setOptions struct {
+	ttl      time.Duration
+	expireAt time.Time
+	tags     []string
+	cost     int64
+	prio     Priority
+	nx       bool
+}
+
+// WithTTL set the TTL of the item, <= 0 for never expire. see Cache.SetX
+func WithTTL(ttl time.Duration) SetOption {
+	return func(o *setOptions) {
+		o.ttl = ttl
+	}
+}
+
+// WithAbsoluteExpiry set the absolute expiration time of the item, it overrides WithTTL. see Cache.SetX
+func WithAbsoluteExpiry(t time.Time) SetOption {
+	return func(o *setOptions) {
+		o.expireAt = t
+	}
+}
+
+// WithTags set the tags of the item, the items can be deleted by tag. see Cache.DeleteByTag
+func WithTags(tags ...string) SetOption {
+	return func(o *setOptions) {
+		o.tags = tags
+	}
+}
+
+// WithCost set the cost of the item, eg: the size in bytes.
+// The total cost is limited by WithMaxCost. see Cache.SetX
+func WithCost(cost int64) SetOption {
+	return func(o *setOptions) {
+		o.cost = cost
+	}
+}
+
+// WithPriority set the eviction priority of the item. When the cache is full, the items
+// with lower priority are evicted first regardless of recency. see Cache.SetX
+//
+// Panics if the priority is unknown.
+func WithPriority(p Priority) SetOption {
+	if int(p) >= len(evictOrder) {
+		panic(fmt.Sprintf("lcache: unknown priority %d", p))
+	}
+	return func(o *setOptions) {
+		o.prio = p
+	}
+}
+
+// WithNX only set the item if the key does not exist, otherwise SetX returns ErrExists.
+func WithNX() SetOption {
+	return func(o *setOptions) {
+		o.nx = true
+	}
+}
+
+// SetX set the item with options: WithTTL, WithAbsoluteExpiry, WithTags, WithCost, WithPriority, WithNX.
+//
+// Usage:
+//
+//	err := c.SetX("user:1", user, lcache.WithTTL(time.Hour), lcache.WithTags("users"), lcache.WithNX())
+//
+// Returns ErrExists if WithNX is set and the key exists, other errors are same as SetE.
+// With Store configured, writes through to the store first. For WithNX, writes
+// to the store after the item is set.
+//
+// NOTE: the tags, cost and priority are not persisted by SaveFile and AOF.
+func (c *Cache) SetX(key string, value any, opts ...SetOption) error {
+	if c.frozen.Load() {
+		return ErrFrozen
+	}
+	key, err := c.normKey(key)
+	if err != nil {
+		return err
+	}
+	if err = c.checkSize(key, value); err != nil {
+		return err
+	}
+
+	so := &setOptions{}
+	for _, fn := range opts {
+		fn(so)
+	}
+
+	ttl, exp := so.ttl, ttlToExp(so.ttl)
+	if !so.expireAt.IsZero() {
+		ttl, exp = time.Until(so.expireAt), so.expireAt.UnixMilli()
+	}
+	// 已过期的数据不写入 Store
+	saveStore := exp == 0 || ttl > 0
+
+	if !so.nx && saveStore {
+		if err = c.storeSave(key, value, ttl); err != nil {
+			return err
+		}
+	}
+
+	if !c.lock() {
+		return ErrBusy
+	}
+	if c.frozen.Load() {
+		c.mu.Unlock()
+		return ErrFrozen
+	}
+
+	nk := c.nsKey(key)
+	if so.nx {
+		if _, it := c.find(nk); it != nil {
+			if nowUm := time.Now().UnixMilli(); !c.invalid(it, nowUm) || c.isStale(it, nowUm) {
+				c.mu.Unlock()
+				return ErrExists
+			}
+		}
+	}
+
+	hk, it := c.newItem(nk, value, exp)
+	it.tags, it.cost, it.prio = so.tags, so.cost, so.prio
+	if !c.putItem(hk, it) {
+		c.mu.Unlock()
+		return ErrCacheFull
+	}
+	err = c.appendAOF(aofOpSet, nk, value, exp)
+	c.mu.Unlock()
+
+	if so.nx && saveStore {
+		if err1 := c.storeSave(key, value, ttl); err1 != nil {
+			return err1
+		}
+	}
+
+	c.onSet(nk, value, ttl)
+	return err
+}
+
+// DeleteByTag removes the items with any of the tags, returns the number of removed items.
+// For a namespace view, only removes the items in the namespace. see WithTags
+//
+// NOTE: it will traverse all data, the time complexity is O(N)
+func (c *Cache) DeleteByTag(tags ...string) int {
+	if len(tags) == 0 || !c.lock() {
+		return 0
+	}
+	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return 0
+	}
+
+	var n int
+	for key, it := range c.items {
+		if c.ns != "" && !strings.HasPrefix(key, c.ns) {
+			continue
+		}
+
+		if slices.ContainsFunc(it.tags, func(tag string) bool { return slices.Contains(tags, tag) }) {
+			c.removeElement(key, ReasonDeleted)
+			_ = c.appendAOF(aofOpDel, key, nil, 0)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,111 @@
+package lcache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_SetX(t *testing.T) {
+	c := lcache.New()
+	assert.NoErr(t, c.SetX("key1", 1, lcache.WithTTL(time.Hour)))
+	ttl, ok := c.TTL("key1")
+	assert.True(t, ok)
+	assert.True(t, ttl > 59*time.Minute)
+
+	// absolute expiry
+	exp := time.Now().Add(time.Minute)
+	assert.NoErr(t, c.SetX("key2", 2, lcache.WithTTL(time.Hour), lcache.WithAbsoluteExpiry(exp)))
+	ttl, _ = c.TTL("key2")
+	assert.True(t, ttl <= time.Minute)
+	assert.NoErr(t, c.SetX("key3", 3, lcache.WithAbsoluteExpiry(time.Now().Add(-time.Second))))
+	assert.False(t, c.Has("key3"))
+
+	// NX
+	assert.ErrIs(t, c.SetX("key1", 10, lcache.WithNX()), lcache.ErrExists)
+	assert.Eq(t, 1, c.Val("key1"))
+	assert.NoErr(t, c.SetX("key3", 30, lcache.WithNX()))
+	assert.Eq(t, 30, c.Val("key3"))
+
+	c.Freeze()
+	assert.ErrIs(t, c.SetX("key4", 4), lcache.ErrFrozen)
+}
+
+func TestCache_DeleteByTag(t *testing.T) {
+	c := lcache.New()
+	assert.NoErr(t, c.SetX("user:1", 1, lcache.WithTags("users", "vip")))
+	assert.NoErr(t, c.SetX("user:2", 2, lcache.WithTags("users")))
+	assert.NoErr(t, c.SetX("order:1", 3, lcache.WithTags("orders")))
+	ns := c.Namespace("ns")
+	assert.NoErr(t, ns.SetX("user:3", 3, lcache.WithTags("users")))
+	c.Set("other", 4, 0)
+
+	assert.Eq(t, 0, c.DeleteByTag())
+	assert.Eq(t, 1, ns.DeleteByTag("users"))
+	assert.Eq(t, 2, c.DeleteByTag("users", "none"))
+	assert.Eq(t, 2, c.Len())
+	assert.True(t, c.Has("order:1"))
+
+	// tags are replaced by the new value
+	c.Set("order:1", 5, 0)
+	assert.Eq(t, 0, c.DeleteByTag("orders"))
+}
+
+func TestCache_WithMaxCost(t *testing.T) {
+	c := lcache.New(lcache.WithMaxCost(10))
+	assert.NoErr(t, c.SetX("key1", 1, lcache.WithCost(4)))
+	assert.NoErr(t, c.SetX("key2", 2, lcache.WithCost(4)))
+	c.Set("key3", 3, 0) // no cost
+	assert.Eq(t, int64(8), c.Stats().Cost)
+
+	// evict the least recently used key1
+	c.Get("key2")
+	assert.NoErr(t, c.SetX("key4", 4, lcache.WithCost(4)))
+	assert.False(t, c.Has("key1"))
+	assert.Eq(t, int64(8), c.Stats().Cost)
+
+	// replace and delete
+	assert.NoErr(t, c.SetX("key2", 2, lcache.WithCost(1)))
+	assert.Eq(t, int64(5), c.Stats().Cost)
+	c.Delete("key4")
+	assert.Eq(t, int64(1), c.Stats().Cost)
+
+	// keep the item with cost larger than max cost
+	assert.NoErr(t, c.SetX("big", 0, lcache.WithCost(20)))
+	assert.Eq(t, []string{"big"}, c.Keys())
+	c.Clear()
+	assert.Eq(t, int64(0), c.Stats().Cost)
+}
+
+func TestCache_WithPriority(t *testing.T) {
+	var evicted []string
+	c := lcache.New(lcache.WithCapacity(3), lcache.WithOnEvictFn(func(key string, _ any) {
+		evicted = append(evicted, key)
+	}))
+	assert.NoErr(t, c.SetX("high", 1, lcache.WithPriority(lcache.PriorityHigh)))
+	c.Set("normal", 2, 0)
+	assert.NoErr(t, c.SetX("low", 3, lcache.WithPriority(lcache.PriorityLow)))
+
+	// low priority is evicted first, even it is the most recently used
+	c.Get("low")
+	c.Set("key1", 4, 0)
+	c.Set("key2", 5, 0)
+	assert.Eq(t, []string{"low", "normal"}, evicted)
+	assert.True(t, c.Has("high"))
+
+	// high priority is evicted only without lower priority items
+	assert.Eq(t, 2, c.EvictN(2))
+	assert.Eq(t, []string{"high"}, c.Keys())
+
+	// priority is reset when replaced without it
+	c.Set("high", 1, 0)
+	c.Set("key3", 6, 0)
+	assert.Eq(t, 1, c.EvictN(1))
+	assert.Eq(t, []string{"key3"}, c.Keys())
+
+	assert.Panics(t, func() {
+		lcache.WithPriority(9)
+	})
+}
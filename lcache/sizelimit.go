@@ -0,0 +1,102 @@
+package lcache
+
+import "fmt"
+
+// SizeError the error of a write rejected by the size limits, matches ErrTooLarge by errors.Is,
+// and ErrKeyTooLong or ErrValueTooLarge by the exceeded limit.
+// see WithMaxKeyLen, WithMaxValueBytes
+type SizeError struct {
+	// Key of the rejected write, contains the namespace prefix
+	Key string
+	// Value is true if the value exceeds the limit, false for the key
+	Value bool
+	// Size the length of the key, or the approximate size of the value in bytes
+	Size int
+	// Limit the configured limit
+	Limit int
+}
+
+// Error implements error
+func (e *SizeError) Error() string {
+	if e.Value {
+		return fmt.Sprintf("lcache: value size %d of key %q exceeds the limit %d", e.Size, e.Key, e.Limit)
+	}
+	return fmt.Sprintf("lcache: key length %d exceeds the limit %d", e.Size, e.Limit)
+}
+
+// Is reports whether the target is ErrTooLarge, or ErrKeyTooLong / ErrValueTooLarge by the exceeded limit
+func (e *SizeError) Is(target error) bool {
+	switch target {
+	case ErrTooLarge:
+		return true
+	case ErrValueTooLarge:
+		return e.Value
+	case ErrKeyTooLong:
+		return !e.Value
+	}
+	return false
+}
+
+// WithMaxKeyLen reject the writes of the keys longer than n bytes, the namespace prefix is
+// included. <= 0 for unlimited. see WithMaxValueBytes
+func WithMaxKeyLen(n int) OptionFn {
+	return func(o *Options) {
+		o.MaxKeyLen = n
+	}
+}
+
+// WithMaxValueBytes reject the writes of the values larger than n bytes, eg: a huge blob
+// cached by accident. <= 0 for unlimited. The size is calculated by ApproxSize, the values
+// except string, []byte and numbers are encoded to JSON, it costs for the large structs.
+//
+// The limits apply to Set, SetE, SetX, SetWithOnEvict, MSet, Group.Set, Tx.Set, the loaded
+// values and the new values built by the data structure operations(Append, Incr, LPush, HSet,
+// SAdd, ZAdd). The rejected writes are not written to the Store. SetE, SetX, Tx.Set and the
+// data structure operations return a *SizeError(Append returns 0, Tx.Set aborts the Update),
+// GetOrLoad and GetCtx return the loaded value with it, the other writes are skipped.
+//
+// Usage:
+//
+//	c := lcache.New(lcache.WithMaxKeyLen(256), lcache.WithMaxValueBytes(1<<20))
+//
+//	if err := c.SetE(key, blob, time.Minute); errors.Is(err, lcache.ErrTooLarge) {
+//		log.Println(err)
+//	}
+func WithMaxValueBytes(n int) OptionFn {
+	return func(o *Options) {
+		o.MaxValueBytes = n
+	}
+}
+
+// checkSize 检查 key 和值的大小是否超出限制. key 为不含命名空间前缀的 key
+func (c *Cache) checkSize(key string, value any) error {
+	if c.opt.MaxKeyLen <= 0 && c.opt.MaxValueBytes <= 0 {
+		return nil
+	}
+
+	key = c.nsKey(key)
+	if n := len(key); c.opt.MaxKeyLen > 0 && n > c.opt.MaxKeyLen {
+		return &SizeError{Key: key, Size: n, Limit: c.opt.MaxKeyLen}
+	}
+	if c.opt.MaxValueBytes > 0 {
+		if n := ApproxSize(value); n > c.opt.MaxValueBytes {
+			return &SizeError{Key: key, Value: true, Size: n, Limit: c.opt.MaxValueBytes}
+		}
+	}
+	return nil
+}
+
+// sizeFilter 过滤掉超出大小限制的数据，未配置限制时返回原 items
+func (c *Cache) sizeFilter(items map[string]any) map[string]any {
+	if c.opt.MaxKeyLen <= 0 && c.opt.MaxValueBytes <= 0 {
+		return items
+	}
+
+	kept := make(map[string]any, len(items))
+	for key, val := range items {
+		if c.checkSize(key, val) == nil {
+			kept[key] = val
+		}
+	}
+	return kept
+}
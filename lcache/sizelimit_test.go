@@ -0,0 +1,85 @@
+package lcache_test
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestWithMaxValueBytes(t *testing.T) {
+	c := lcache.New(lcache.WithMaxKeyLen(10), lcache.WithMaxValueBytes(8))
+	assert.NoErr(t, c.SetE("key1", "12345678", 0))
+	assert.NoErr(t, c.SetE("key2", map[string]int{"a": 1}, 0))
+
+	// value too large
+	err := c.SetE("key3", strings.Repeat("x", 9), 0)
+	assert.ErrIs(t, err, lcache.ErrTooLarge)
+	assert.ErrIs(t, err, lcache.ErrValueTooLarge)
+	assert.False(t, errors.Is(err, lcache.ErrKeyTooLong))
+	var se *lcache.SizeError
+	assert.True(t, errors.As(err, &se))
+	assert.Eq(t, &lcache.SizeError{Key: "key3", Value: true, Size: 9, Limit: 8}, se)
+	assert.Eq(t, `lcache: value size 9 of key "key3" exceeds the limit 8`, err.Error())
+	assert.False(t, c.Has("key3"))
+
+	// key too long, include the namespace prefix
+	err = c.SetE("key4-too-long", 1, 0)
+	assert.ErrIs(t, err, lcache.ErrTooLarge)
+	assert.ErrIs(t, err, lcache.ErrKeyTooLong)
+	assert.False(t, errors.Is(err, lcache.ErrValueTooLarge))
+	assert.Eq(t, "lcache: key length 13 exceeds the limit 10", err.Error())
+	assert.ErrIs(t, c.Namespace("ns").SetE("key4-123", 1, 0), lcache.ErrTooLarge)
+	assert.ErrIs(t, c.SetX("key5", []byte("123456789"), lcache.WithTTL(time.Minute)), lcache.ErrTooLarge)
+
+	// the other writes are skipped
+	c.Set("key6", "123456789", 0)
+	c.MSet(map[string]any{"key7": 7, "key8": "123456789"}, 0)
+	c.DefineGroup("g", lcache.GroupOptions{}).Set("key9", "123456789")
+
+	// the failed write aborts the transaction
+	err = c.Update(func(tx *lcache.Tx) error {
+		assert.NoErr(t, tx.Set("key10", 10, 0))
+		assert.ErrIs(t, tx.Set("key10-too-long", 1, 0), lcache.ErrKeyTooLong)
+		// the later writes return the same error
+		assert.ErrIs(t, tx.Delete("key1"), lcache.ErrKeyTooLong)
+		return nil
+	})
+	assert.ErrIs(t, err, lcache.ErrKeyTooLong)
+	err = c.Update(func(tx *lcache.Tx) error {
+		assert.NoErr(t, tx.Delete("key1"))
+		assert.ErrIs(t, tx.Set("key10", "123456789", 0), lcache.ErrValueTooLarge)
+		return nil
+	})
+	assert.ErrIs(t, err, lcache.ErrValueTooLarge)
+	keys := c.Keys()
+	sort.Strings(keys)
+	assert.Eq(t, []string{"key1", "key2", "key7"}, keys)
+
+	// the loaded value is returned with the error
+	val, err := c.GetOrLoad("key11", 0, func(string) (any, error) {
+		return "123456789", nil
+	})
+	assert.ErrIs(t, err, lcache.ErrTooLarge)
+	assert.Eq(t, "123456789", val)
+	assert.False(t, c.Has("key11"))
+
+	// the data structure operations check the new value
+	assert.Eq(t, 4, c.Append("s1", "1234"))
+	assert.Eq(t, 0, c.Append("s1", "56789"))
+	assert.Eq(t, "1234", c.Val("s1"))
+	_, err = c.LPush("l1", "123", "456")
+	assert.ErrIs(t, err, lcache.ErrTooLarge)
+	assert.False(t, c.Has("l1"))
+	assert.NoErr(t, c.HSet("h1", "a", 1))
+	assert.ErrIs(t, c.HSet("h1", "b", 2), lcache.ErrTooLarge)
+	assert.Eq(t, map[string]any{"a": 1}, c.HGetAll("h1"))
+	_, err = c.SAdd("set1", 0, "123456789")
+	assert.ErrIs(t, err, lcache.ErrTooLarge)
+	_, err = c.ZAdd("z1", 0, lcache.ZMember{Member: "a", Score: 1})
+	assert.ErrIs(t, err, lcache.ErrTooLarge)
+}
@@ -0,0 +1,166 @@
+package lcache
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// cryptChunk 加密快照每个分块的明文大小
+const cryptChunk = 64 << 10
+
+// errCryptData 加密的快照数据不完整或已损坏
+var errCryptData = errors.New("lcache: invalid encrypted data")
+
+// cryptWriter 分块加密快照 payload，加载时可以流式解密. 格式(快照版本 3 开始):
+//
+//	nonce(12) | sealed chunk | ... | sealed final chunk
+//
+// 每个分块使用 AES-GCM 单独加密，nonce 为基础 nonce 异或分块序号，
+// 附加数据标记是否为最后一个分块，截断或调换分块都会校验失败
+type cryptWriter struct {
+	w     io.Writer
+	gcm   cipher.AEAD
+	nonce []byte
+	seq   uint64
+	buf   []byte
+	out   []byte
+}
+
+func newCryptWriter(w io.Writer, key []byte) (*cryptWriter, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(nonce); err != nil {
+		return nil, err
+	}
+	return &cryptWriter{w: w, gcm: gcm, nonce: nonce, buf: make([]byte, 0, cryptChunk)}, nil
+}
+
+// Write 写满一个分块后，还有更多数据时才加密写入，保证最后一个分块在 Close 时写入
+func (cw *cryptWriter) Write(p []byte) (int, error) {
+	var n int
+	for len(p) > 0 {
+		if len(cw.buf) == cryptChunk {
+			if err := cw.flush(false); err != nil {
+				return n, err
+			}
+		}
+
+		m := copy(cw.buf[len(cw.buf):cryptChunk], p)
+		cw.buf = cw.buf[:len(cw.buf)+m]
+		p, n = p[m:], n+m
+	}
+	return n, nil
+}
+
+// Close 加密写入最后一个分块. 不会关闭底层的 writer
+func (cw *cryptWriter) Close() error {
+	return cw.flush(true)
+}
+
+func (cw *cryptWriter) flush(final bool) error {
+	cw.out = cw.gcm.Seal(cw.out[:0], chunkNonce(cw.nonce, cw.seq), cw.buf, chunkAD(final))
+	cw.seq++
+	cw.buf = cw.buf[:0]
+	_, err := cw.w.Write(cw.out)
+	return err
+}
+
+// cryptReader 流式解密 cryptWriter 写入的数据
+type cryptReader struct {
+	r     *bufio.Reader
+	gcm   cipher.AEAD
+	nonce []byte
+	seq   uint64
+	// sealed 读取的密文分块; plain 当前分块未读取的明文
+	sealed []byte
+	plain  []byte
+	done   bool
+}
+
+func newCryptReader(r io.Reader, key []byte) (*cryptReader, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(r, nonce); err != nil {
+		return nil, errCryptData
+	}
+	return &cryptReader{
+		r:      bufio.NewReader(r),
+		gcm:    gcm,
+		nonce:  nonce,
+		sealed: make([]byte, cryptChunk+gcm.Overhead()),
+	}, nil
+}
+
+func (cr *cryptReader) Read(p []byte) (int, error) {
+	for len(cr.plain) == 0 {
+		if cr.done {
+			return 0, io.EOF
+		}
+		if err := cr.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, cr.plain)
+	cr.plain = cr.plain[n:]
+	return n, nil
+}
+
+// next 读取并解密下一个分块. 读满一个分块且之后没有数据，或者不足一个分块时为最后一个分块
+func (cr *cryptReader) next() error {
+	n, err := io.ReadFull(cr.r, cr.sealed)
+	switch {
+	case err == nil:
+		if _, err = cr.r.Peek(1); errors.Is(err, io.EOF) {
+			cr.done = true
+		} else if err != nil {
+			return err
+		}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		cr.done = true
+	case errors.Is(err, io.EOF):
+		// 缺少最后一个分块
+		return errCryptData
+	default:
+		return err
+	}
+
+	plain, err := cr.gcm.Open(cr.sealed[:0], chunkNonce(cr.nonce, cr.seq), cr.sealed[:n], chunkAD(cr.done))
+	if err != nil {
+		return err
+	}
+	cr.seq++
+	cr.plain = plain
+	return nil
+}
+
+// chunkNonce 基础 nonce 的后 8 字节异或分块序号
+func chunkNonce(base []byte, seq uint64) []byte {
+	nonce := make([]byte, len(base))
+	copy(nonce, base)
+	tail := nonce[len(nonce)-8:]
+	binary.BigEndian.PutUint64(tail, binary.BigEndian.Uint64(tail)^seq)
+	return nonce
+}
+
+func chunkAD(final bool) []byte {
+	if final {
+		return []byte{1}
+	}
+	return []byte{0}
+}
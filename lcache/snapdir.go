@@ -0,0 +1,208 @@
+package lcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// snapManifest 分片快照目录的清单文件名
+const snapManifest = "manifest.json"
+
+// dirManifest 分片快照的清单，在所有分片写入后最后写入
+type dirManifest struct {
+	Gen        uint64    `json:"gen"`
+	Serializer string    `json:"serializer"`
+	Count      int       `json:"count"`
+	Shards     []string  `json:"shards"`
+	SavedAt    time.Time `json:"saved_at"`
+}
+
+// SaveDir save the cache data to a directory, split into shards files, they are encoded and
+// written in parallel. It is faster than SaveFile for the huge caches. shards <= 0 for
+// runtime.GOMAXPROCS(0).
+//
+// The files are "shard-0000.snap", ... and the manifest "manifest.json", which is written
+// last, so the previous snapshot in the dir is still loadable if the save fails. The options
+// of SaveFile are also applied to each shard, eg: compression, encryption and PersistProvider.
+//
+// Usage:
+//
+//	err := c.SaveDir("data/cache", 8)
+//	err = c.LoadDir("data/cache")
+func (c *Cache) SaveDir(dir string, shards int) error {
+	err := c.saveDir(dir, shards)
+
+	c.saveMu.Lock()
+	c.lastSaveAt, c.lastSaveErr = time.Now(), err
+	c.saveMu.Unlock()
+	return err
+}
+
+func (c *Cache) saveDir(dir string, shards int) error {
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0)
+	}
+
+	serializer, err := c.serializer()
+	if err != nil {
+		return err
+	}
+
+	if !c.rlock() {
+		return ErrBusy
+	}
+	defer c.mu.RUnlock()
+
+	// 按 LRU 顺序轮流分配到各分片，使各分片的大小接近. 加载时可按相同规则还原整体的顺序
+	data := c.snapshotData()
+	parts := make([]*snapshot, shards)
+	for i := range parts {
+		parts[i] = &snapshot{Gen: c.gen, Items: make(map[string]*Item, len(data.Items)/shards+1)}
+	}
+	for i, k := range data.orderedKeys() {
+		part := parts[i%shards]
+		part.Items[k] = data.Items[k]
+		if !c.opt.DisableLRU {
+			part.Order = append(part.Order, k)
+		}
+	}
+	count := len(data.Items)
+	// 释放完整的 map，降低峰值内存
+	data = nil
+
+	p := c.persistProvider()
+	m := &dirManifest{Gen: c.gen, Serializer: c.serializerName(), Count: count, Shards: make([]string, shards)}
+	errs := make([]error, shards)
+
+	var wg sync.WaitGroup
+	for i := range parts {
+		m.Shards[i] = fmt.Sprintf("shard-%04d.snap", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = p.Snapshot(filepath.Join(dir, m.Shards[i]), func(w io.Writer) error {
+				return c.writeSnapshot(w, serializer, parts[i])
+			})
+		}()
+	}
+	wg.Wait()
+	if err = errors.Join(errs...); err != nil {
+		return err
+	}
+
+	m.SavedAt = time.Now()
+	return p.Snapshot(filepath.Join(dir, snapManifest), func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(m)
+	})
+}
+
+// LoadDir load the cache data from a directory saved by SaveDir, the shards are read and
+// decoded in parallel. The mode is same as LoadFile.
+//
+// The items are staged while decoding like LoadFile, the cache is unchanged if any shard
+// is missing or corrupted.
+func (c *Cache) LoadDir(dir string, mode ...LoadMode) error {
+	serializer, err := c.serializer()
+	if err != nil {
+		return err
+	}
+
+	p := c.persistProvider()
+	var m dirManifest
+	err = p.Restore(filepath.Join(dir, snapManifest), func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(&m)
+	})
+	if err != nil {
+		return err
+	}
+	if name := c.serializerName(); m.Serializer != name {
+		return fmt.Errorf("%w: dir use %q, but cache use %q", ErrWrongSerializer, m.Serializer, name)
+	}
+
+	if !c.lock() {
+		return ErrBusy
+	}
+	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return ErrFrozen
+	}
+
+	loadMode := LoadReplace
+	if len(mode) > 0 {
+		loadMode = mode[0]
+	}
+
+	st := c.newLoadStage(loadMode)
+	st.setGen(m.Gen)
+
+	// 并行解码各分片，解码的数据项通过各自的通道传出. 已持有缓存锁
+	chs := make([]chan shardItem, len(m.Shards))
+	errs := make([]error, len(m.Shards))
+	abort := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i, name := range m.Shards {
+		chs[i] = make(chan shardItem, 64)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(chs[i])
+
+			errs[i] = p.Restore(filepath.Join(dir, name), func(r io.Reader) error {
+				return c.decodeSnapshot(r, serializer, func(uint64) {}, func(key string, it *Item) error {
+					select {
+					case chs[i] <- shardItem{key: key, it: it}:
+						return nil
+					case <-abort:
+						return errLoadAborted
+					}
+				})
+			})
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("lcache: load shard %s: %w", name, errs[i])
+			}
+		}()
+	}
+
+	// 分片是按 LRU 顺序轮流分配的，轮流从各分片取出数据项写入暂存即为整体的顺序
+	for open := len(chs); open > 0 && err == nil; {
+		open = 0
+		for _, ch := range chs {
+			rec, ok := <-ch
+			if !ok {
+				continue
+			}
+
+			open++
+			if err = st.put(rec.key, rec.it); err != nil {
+				break
+			}
+		}
+	}
+	close(abort)
+	wg.Wait()
+
+	if err == nil {
+		err = errors.Join(errs...)
+	}
+	if err != nil {
+		return err
+	}
+	st.commit()
+	return nil
+}
+
+// shardItem 分片中解码的一条数据项
+type shardItem struct {
+	key string
+	it  *Item
+}
+
+// errLoadAborted 其他分片出错，停止解码
+var errLoadAborted = errors.New("lcache: load aborted")
@@ -0,0 +1,77 @@
+package lcache_test
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_SaveDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snap")
+	c := lcache.New(lcache.WithSerializer("lcbin"), lcache.WithSaveCompression(lcache.CompressGzip),
+		lcache.WithCapacity(2000), lcache.WithLRUMode(lcache.LRUExact))
+	for i := 0; i < 1000; i++ {
+		c.Set(fmt.Sprintf("key%d", i), fmt.Sprint("val", i), 0)
+	}
+	c.Set("expired", "val", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	c.Get("key5")
+
+	assert.NoErr(t, c.SaveDir(dir, 4))
+	files, err := filepath.Glob(filepath.Join(dir, "shard-*.snap"))
+	assert.NoErr(t, err)
+	assert.Len(t, files, 4)
+	assert.NoErr(t, c.Stats().LastSaveErr)
+
+	// a shard is also a snapshot file
+	snap, err := lcache.ReadSnapshot(files[0])
+	assert.NoErr(t, err)
+	assert.Eq(t, 250, len(snap.Items))
+
+	c2 := lcache.New(lcache.WithSerializer("lcbin"), lcache.WithCapacity(2000))
+	c2.Set("other", 1, 0)
+	assert.NoErr(t, c2.LoadDir(dir))
+	assert.Eq(t, 1000, c2.Len())
+	// the LRU order is restored across the shards
+	key, _ := c2.OldestKey()
+	assert.Eq(t, "key0", key)
+	key, _ = c2.NewestKey()
+	assert.Eq(t, "key5", key)
+	assert.Eq(t, "val999", c2.Val("key999"))
+	assert.False(t, c2.Has("other"))
+
+	c3 := lcache.New(lcache.WithSerializer("lcbin"), lcache.WithCapacity(2000))
+	c3.Set("other", 1, 0)
+	assert.NoErr(t, c3.LoadDir(dir, lcache.LoadMerge))
+	assert.Eq(t, 1001, c3.Len())
+
+	// wrong serializer
+	assert.ErrIs(t, lcache.New().LoadDir(dir), lcache.ErrWrongSerializer)
+	assert.ErrIs(t, c2.LoadDir(filepath.Join(dir, "none")), fs.ErrNotExist)
+}
+
+func TestCache_LoadDir_corrupted(t *testing.T) {
+	dir := t.TempDir()
+	c := lcache.New()
+	c.Set("key1", "val1", 0)
+	c.Set("key2", "val2", 0)
+	// default shards
+	assert.NoErr(t, c.SaveDir(dir, 0))
+
+	c2 := lcache.New()
+	assert.NoErr(t, c2.LoadDir(dir))
+	assert.Eq(t, "val2", c2.Val("key2"))
+
+	file := filepath.Join(dir, "shard-0000.snap")
+	data, err := os.ReadFile(file)
+	assert.NoErr(t, err)
+	data[len(data)-2] ^= 0xff
+	assert.NoErr(t, os.WriteFile(file, data, 0644))
+	assert.ErrIs(t, c2.LoadDir(dir), lcache.ErrBadChecksum)
+}
@@ -0,0 +1,96 @@
+package lcache
+
+import (
+	"bufio"
+	"io"
+	"os"
+
+	"github.com/gookit/goutil/x/stdio"
+)
+
+// Snapshot the data of a snapshot file, for inspect or convert it without a cache. see ReadSnapshot
+type Snapshot struct {
+	// Serializer name used to decode the file
+	Serializer string
+	// Gen the cache generation on saved
+	Gen uint64
+	// Items all items in the file, include the expired ones
+	Items map[string]*Item
+	// Order the keys from the least to the most recently used, for restore the LRU order on load.
+	// The keys not in it are treated as the least recently used.
+	Order []string
+}
+
+// snapCache 创建仅用于读写快照文件的缓存实例，不启动后台任务
+func snapCache(optFns []OptionFn) *Cache {
+	c := &Cache{core: &core{opt: Options{Serializer: "json"}}}
+	for _, optFn := range optFns {
+		optFn(&c.opt)
+	}
+	return c
+}
+
+// ReadSnapshot read a snapshot file written by SaveFile, without loading it into a cache.
+//
+// The optFns are the options used on save, eg: WithSaveEncryption. The serializer recorded
+// in the file header is used for decode if it is registered, so it is only required for
+// the old files without header.
+func ReadSnapshot(filename string, optFns ...OptionFn) (*Snapshot, error) {
+	c := snapCache(optFns)
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer stdio.SafeClose(file)
+
+	// 优先使用文件头中记录的序列化器
+	hdr, err := readSnapshotHeader(bufio.NewReader(file))
+	if err != nil {
+		return nil, err
+	}
+	if hdr != nil && c.opt.SerializerObj == nil && HasSerializer(hdr.Serializer) {
+		c.opt.Serializer = hdr.Serializer
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	serializer, err := c.serializer()
+	if err != nil {
+		return nil, err
+	}
+
+	data := &snapshot{Items: make(map[string]*Item)}
+	if err = c.decodeSnapshot(file, serializer, func(gen uint64) { data.Gen = gen }, data.add); err != nil {
+		return nil, err
+	}
+	return &Snapshot{Serializer: c.serializerName(), Gen: data.Gen, Items: data.Items, Order: data.Order}, nil
+}
+
+// WriteSnapshot write the snapshot to a file in the SaveFile format, the file can be loaded by LoadFile.
+//
+// The optFns set the serializer, compression and encryption for the file. eg: convert a snapshot:
+//
+//	snap, err := lcache.ReadSnapshot("cache.json")
+//	err = lcache.WriteSnapshot("cache.gob", snap, lcache.WithSerializer("gob"))
+func WriteSnapshot(filename string, snap *Snapshot, optFns ...OptionFn) error {
+	c := snapCache(optFns)
+	serializer, err := c.serializer()
+	if err != nil {
+		return err
+	}
+
+	// gob 需要注册值的类型
+	if _, ok := serializer.(GobSerializer); ok {
+		for _, it := range snap.Items {
+			if it.Val != nil {
+				GobRegister(it.Val)
+			}
+		}
+	}
+
+	return FileProvider{}.Snapshot(filename, func(w io.Writer) error {
+		return c.writeSnapshot(w, serializer, &snapshot{Gen: snap.Gen, Items: snap.Items, Order: snap.Order})
+	})
+}
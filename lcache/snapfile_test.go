@@ -0,0 +1,41 @@
+package lcache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestReadSnapshot(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	c := lcache.New(lcache.WithSerializer("gob"), lcache.WithSaveEncryption(key), lcache.WithGeneration(3))
+	c.Set("key1", "val1", 0)
+	c.Set("key2", 23, time.Hour)
+
+	filename := t.TempDir() + "/cache.gob"
+	assert.NoErr(t, c.SaveFile(filename))
+
+	_, err := lcache.ReadSnapshot(filename)
+	assert.Err(t, err)
+
+	// serializer is detected from the header
+	snap, err := lcache.ReadSnapshot(filename, lcache.WithSaveEncryption(key))
+	assert.NoErr(t, err)
+	assert.Eq(t, "gob", snap.Serializer)
+	assert.Eq(t, uint64(3), snap.Gen)
+	assert.Len(t, snap.Items, 2)
+	assert.Eq(t, "val1", snap.Items["key1"].Val)
+	assert.True(t, snap.Items["key2"].Exp > 0)
+
+	// convert to json + gzip
+	out := t.TempDir() + "/cache.json"
+	assert.NoErr(t, lcache.WriteSnapshot(out, snap, lcache.WithSaveCompression(lcache.CompressGzip)))
+
+	c2 := lcache.New()
+	assert.NoErr(t, c2.LoadFile(out))
+	assert.Eq(t, "val1", c2.Val("key1"))
+	assert.Eq(t, float64(23), c2.Val("key2"))
+	assert.Eq(t, uint64(3), c2.Generation())
+}
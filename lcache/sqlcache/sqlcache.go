@@ -0,0 +1,291 @@
+// Package sqlcache caches the database/sql query results in a lcache.Cache.
+//
+// The cache key is the hash of the query and args. Concurrent queries for the same
+// key only run once. The results can be invalidated by table tags:
+//
+//	ctx = sqlcache.WithTags(ctx, "users")
+//	rows, err := sqlcache.Query(ctx, db, c, time.Minute, "SELECT * FROM users WHERE age > ?", 18)
+//
+//	// after update the users table
+//	sqlcache.Invalidate(c, "users")
+package sqlcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+)
+
+// KeyPrefix the prefix of the cache keys
+const KeyPrefix = "sqlcache:"
+
+// tagPrefix 表标签版本号的 key 前缀
+const tagPrefix = KeyPrefix + "tag:"
+
+// Querier the interface to run a query. eg: *sql.DB, *sql.Tx, *sql.Conn
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// tagsKey context key of the tags
+type tagsKey struct{}
+
+// WithTags set the table tags for the cached query results, the results can be
+// invalidated by Invalidate with any of the tags.
+func WithTags(ctx context.Context, tables ...string) context.Context {
+	return context.WithValue(ctx, tagsKey{}, tables)
+}
+
+// Invalidate all the cached query results with any of the table tags.
+//
+// It updates the version of the tags, the old results become unreachable
+// and are removed on expired or evicted.
+func Invalidate(c *lcache.Cache, tables ...string) {
+	ver := time.Now().UnixNano()
+	for _, table := range tables {
+		c.Set(tagPrefix+table, ver, 0)
+	}
+}
+
+// Query run the query and cache the result rows with ttl. On cache hit, returns the cached rows.
+//
+// Each row is a map of column name to value. The []byte values are converted to string.
+//
+// NOTE: the returned rows are shared with the cache, should not modify them.
+func Query(ctx context.Context, db Querier, c *lcache.Cache, ttl time.Duration, query string, args ...any) ([]map[string]any, error) {
+	key := cacheKey(ctx, c, "map", query, args)
+	val, err := c.GetOrLoad(key, ttl, func(string) (any, error) {
+		return queryMaps(ctx, db, query, args)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if rows, ok := val.([]map[string]any); ok {
+		return rows, nil
+	}
+
+	// 可能是从快照文件加载的数据，类型已改变. 重新查询
+	rows, err := queryMaps(ctx, db, query, args)
+	if err == nil {
+		c.Set(key, rows, ttl)
+	}
+	return rows, err
+}
+
+// QueryTo run the query and scan the rows into []T, cache the result with ttl.
+//
+// T can be a struct, the columns are mapped to the fields by the "db" tag or
+// the field name(case-insensitive), the unknown columns are ignored.
+// Otherwise, the query must return a single column and it is scanned into T.
+//
+// Usage:
+//
+//	users, err := sqlcache.QueryTo[User](ctx, db, c, time.Minute, "SELECT id, name FROM users")
+func QueryTo[T any](ctx context.Context, db Querier, c *lcache.Cache, ttl time.Duration, query string, args ...any) ([]T, error) {
+	key := cacheKey(ctx, c, reflect.TypeFor[T]().String(), query, args)
+	val, err := c.GetOrLoad(key, ttl, func(string) (any, error) {
+		return queryTo[T](ctx, db, query, args)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if list, ok := val.([]T); ok {
+		return list, nil
+	}
+
+	list, err := queryTo[T](ctx, db, query, args)
+	if err == nil {
+		c.Set(key, list, ttl)
+	}
+	return list, err
+}
+
+// cacheKey 生成缓存 key: 查询语句、参数、结果类型和表标签版本号的 hash
+func cacheKey(ctx context.Context, c *lcache.Cache, kind, query string, args []any) string {
+	h := sha256.New()
+	h.Write([]byte(kind))
+	h.Write([]byte{0})
+	h.Write([]byte(query))
+	for _, arg := range args {
+		arg = keyArg(arg)
+		_, _ = fmt.Fprintf(h, "\x00%T:%v", arg, arg)
+	}
+
+	tags, _ := ctx.Value(tagsKey{}).([]string)
+	for _, tag := range tags {
+		_, _ = fmt.Fprintf(h, "\x01%s:%d", tag, tagVersion(c, tag))
+	}
+	return KeyPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// keyArg 获取参数生成 key 使用的值: 解引用指针，driver.Valuer 使用 Value() 的结果.
+// 与驱动实际使用的值一致，避免按指针地址生成 key
+func keyArg(arg any) any {
+	if na, ok := arg.(sql.NamedArg); ok {
+		return sql.Named(na.Name, keyArg(na.Value))
+	}
+
+	rv := reflect.ValueOf(arg)
+	if rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil
+	}
+	if vr, ok := arg.(driver.Valuer); ok {
+		if val, err := vr.Value(); err == nil {
+			return val
+		}
+		return arg
+	}
+	if rv.Kind() == reflect.Pointer {
+		return keyArg(rv.Elem().Interface())
+	}
+	return arg
+}
+
+// tagVersion 获取表标签的版本号，不存在时初始化.
+// 使用时间作为版本号，避免版本号被淘汰后重新初始化为旧的值
+func tagVersion(c *lcache.Cache, tag string) int64 {
+	key := tagPrefix + tag
+	unlock := c.LockKey(key)
+	defer unlock()
+
+	if ver, ok := c.Get(key); ok {
+		if v, ok := ver.(int64); ok {
+			return v
+		}
+	}
+
+	ver := time.Now().UnixNano()
+	c.Set(key, ver, 0)
+	return ver
+}
+
+func queryMaps(ctx context.Context, db Querier, query string, args []any) ([]map[string]any, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]map[string]any, 0)
+	vals := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+
+	for rows.Next() {
+		if err = rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]any, len(cols))
+		for i, col := range cols {
+			if bs, ok := vals[i].([]byte); ok {
+				row[col] = string(bs)
+			} else {
+				row[col] = vals[i]
+			}
+		}
+		list = append(list, row)
+	}
+	return list, rows.Err()
+}
+
+func queryTo[T any](ctx context.Context, db Querier, query string, args []any) ([]T, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	typ := reflect.TypeFor[T]()
+	isStruct := typ.Kind() == reflect.Struct && !typ.Implements(scannerType) && !reflect.PointerTo(typ).Implements(scannerType)
+	if !isStruct && len(cols) != 1 {
+		return nil, fmt.Errorf("sqlcache: scan %d columns into non-struct type %s", len(cols), typ)
+	}
+
+	var fields []int
+	if isStruct {
+		fields = mapFields(typ, cols)
+	}
+
+	list := make([]T, 0)
+	for rows.Next() {
+		var dest T
+		if isStruct {
+			err = rows.Scan(fieldPtrs(reflect.ValueOf(&dest).Elem(), fields)...)
+		} else {
+			err = rows.Scan(&dest)
+		}
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, dest)
+	}
+	return list, rows.Err()
+}
+
+// scannerType sql.Scanner 接口类型
+var scannerType = reflect.TypeFor[sql.Scanner]()
+
+// mapFields 获取每个列对应的结构体字段索引，-1 表示没有对应的字段
+func mapFields(typ reflect.Type, cols []string) []int {
+	fields := make([]int, len(cols))
+	for i, col := range cols {
+		fields[i] = -1
+		for j := 0; j < typ.NumField(); j++ {
+			f := typ.Field(j)
+			if !f.IsExported() {
+				continue
+			}
+
+			name := f.Tag.Get("db")
+			if name == "-" {
+				continue
+			}
+			if name == col || (name == "" && strings.EqualFold(f.Name, col)) {
+				fields[i] = j
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// discard 忽略没有对应字段的列
+type discard struct{}
+
+// Scan implements sql.Scanner
+func (discard) Scan(any) error { return nil }
+
+func fieldPtrs(v reflect.Value, fields []int) []any {
+	ptrs := make([]any, len(fields))
+	for i, idx := range fields {
+		if idx < 0 {
+			ptrs[i] = discard{}
+		} else {
+			ptrs[i] = v.Field(idx).Addr().Interface()
+		}
+	}
+	return ptrs
+}
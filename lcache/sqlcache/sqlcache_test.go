@@ -0,0 +1,163 @@
+package sqlcache_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/lcache/sqlcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+// fakeDriver returns the users rows for any query, and counts the queries
+type fakeDriver struct{ queries atomic.Int32 }
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d: d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{d: c.d}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+type fakeStmt struct{ d *fakeDriver }
+
+func (s *fakeStmt) Close() error                               { return nil }
+func (s *fakeStmt) NumInput() int                              { return -1 }
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.queries.Add(1)
+	return &fakeRows{data: [][]driver.Value{
+		{int64(1), []byte("tom"), int64(20)},
+		{int64(2), []byte("jack"), int64(30)},
+	}}, nil
+}
+
+type fakeRows struct {
+	data [][]driver.Value
+	pos  int
+}
+
+func (r *fakeRows) Columns() []string { return []string{"id", "name", "age"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.data) {
+		return io.EOF
+	}
+	copy(dest, r.data[r.pos])
+	r.pos++
+	return nil
+}
+
+var drv = &fakeDriver{}
+
+func init() { sql.Register("sqlcache_fake", drv) }
+
+type user struct {
+	ID       int64
+	UserName string `db:"name"`
+}
+
+func TestQuery(t *testing.T) {
+	db, err := sql.Open("sqlcache_fake", "")
+	assert.NoErr(t, err)
+	defer db.Close()
+
+	c := lcache.New()
+	ctx := sqlcache.WithTags(context.Background(), "users")
+	before := drv.queries.Load()
+
+	rows, err := sqlcache.Query(ctx, db, c, time.Minute, "SELECT * FROM users WHERE age > ?", 18)
+	assert.NoErr(t, err)
+	assert.Eq(t, []map[string]any{
+		{"id": int64(1), "name": "tom", "age": int64(20)},
+		{"id": int64(2), "name": "jack", "age": int64(30)},
+	}, rows)
+
+	// hit cache
+	_, err = sqlcache.Query(ctx, db, c, time.Minute, "SELECT * FROM users WHERE age > ?", 18)
+	assert.NoErr(t, err)
+	assert.Eq(t, int32(1), drv.queries.Load()-before)
+
+	// different args
+	_, err = sqlcache.Query(ctx, db, c, time.Minute, "SELECT * FROM users WHERE age > ?", 20)
+	assert.NoErr(t, err)
+	assert.Eq(t, int32(2), drv.queries.Load()-before)
+
+	// invalidate by tag
+	sqlcache.Invalidate(c, "users")
+	_, err = sqlcache.Query(ctx, db, c, time.Minute, "SELECT * FROM users WHERE age > ?", 18)
+	assert.NoErr(t, err)
+	assert.Eq(t, int32(3), drv.queries.Load()-before)
+
+	// other tag does not affect
+	sqlcache.Invalidate(c, "posts")
+	_, err = sqlcache.Query(ctx, db, c, time.Minute, "SELECT * FROM users WHERE age > ?", 18)
+	assert.NoErr(t, err)
+	assert.Eq(t, int32(3), drv.queries.Load()-before)
+}
+
+func TestQuery_pointerArgs(t *testing.T) {
+	db, err := sql.Open("sqlcache_fake", "")
+	assert.NoErr(t, err)
+	defer db.Close()
+
+	c := lcache.New()
+	ctx := context.Background()
+	before := drv.queries.Load()
+	query := "SELECT * FROM users WHERE age > ? AND name = ?"
+
+	// the pointers with same value hit the cache
+	age1, age2 := 18, 18
+	_, err = sqlcache.Query(ctx, db, c, time.Minute, query, &age1, sql.NullString{String: "tom", Valid: true})
+	assert.NoErr(t, err)
+	_, err = sqlcache.Query(ctx, db, c, time.Minute, query, &age2, sql.NullString{String: "tom", Valid: true})
+	assert.NoErr(t, err)
+	assert.Eq(t, int32(1), drv.queries.Load()-before)
+
+	// the value behind the pointer changed
+	age1 = 20
+	_, err = sqlcache.Query(ctx, db, c, time.Minute, query, &age1, sql.NullString{String: "tom", Valid: true})
+	assert.NoErr(t, err)
+	assert.Eq(t, int32(2), drv.queries.Load()-before)
+
+	// the Valuer values
+	_, err = sqlcache.Query(ctx, db, c, time.Minute, query, &age1, sql.NullString{String: "jack", Valid: true})
+	assert.NoErr(t, err)
+	_, err = sqlcache.Query(ctx, db, c, time.Minute, query, &age1, &sql.NullString{String: "jack", Valid: true})
+	assert.NoErr(t, err)
+	assert.Eq(t, int32(3), drv.queries.Load()-before)
+}
+
+func TestQueryTo(t *testing.T) {
+	db, err := sql.Open("sqlcache_fake", "")
+	assert.NoErr(t, err)
+	defer db.Close()
+
+	c := lcache.New()
+	ctx := context.Background()
+	before := drv.queries.Load()
+
+	users, err := sqlcache.QueryTo[user](ctx, db, c, time.Minute, "SELECT id, name, age FROM users")
+	assert.NoErr(t, err)
+	assert.Eq(t, []user{{ID: 1, UserName: "tom"}, {ID: 2, UserName: "jack"}}, users)
+
+	// same query with other result type use different key
+	rows, err := sqlcache.Query(ctx, db, c, time.Minute, "SELECT id, name, age FROM users")
+	assert.NoErr(t, err)
+	assert.Len(t, rows, 2)
+
+	users, err = sqlcache.QueryTo[user](ctx, db, c, time.Minute, "SELECT id, name, age FROM users")
+	assert.NoErr(t, err)
+	assert.Len(t, users, 2)
+	assert.Eq(t, int32(2), drv.queries.Load()-before)
+
+	// non-struct type requires single column
+	_, err = sqlcache.QueryTo[int64](ctx, db, c, time.Minute, "SELECT id, name, age FROM users")
+	assert.ErrSubMsg(t, err, "scan 3 columns into non-struct type int64")
+}
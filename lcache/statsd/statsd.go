@@ -0,0 +1,221 @@
+// Package statsd periodically exports the statistics of the registered caches over UDP
+// in StatsD format, with DogStatsD tags. eg: for DataDog agent.
+//
+// Each cache is tagged with "cache:<name>", the metrics:
+//
+//   - gauges: size, valid_size, cost, hit_ratio, lock_wait_avg_ms
+//   - counters(delta since the last flush): hits, misses, evictions, lock_waits
+//
+// Usage:
+//
+//	lcache.Default().Register("users", usersCache)
+//	exp, err := statsd.New("127.0.0.1:8125", nil, statsd.WithPrefix("myapp.cache."), statsd.WithTags("env:prod"))
+//	if err != nil {
+//		return err
+//	}
+//	defer exp.Close()
+//	exp.Start()
+package statsd
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+)
+
+const (
+	// DefaultPrefix the default prefix of the metric names
+	DefaultPrefix = "lcache."
+	// DefaultInterval the default flush interval
+	DefaultInterval = 10 * time.Second
+	// maxPacketSize UDP 包的最大长度，避免超出常见的 MTU 被分片
+	maxPacketSize = 1432
+)
+
+// Option for the exporter
+type Option func(e *Exporter)
+
+// WithPrefix set the prefix of the metric names, default is DefaultPrefix
+func WithPrefix(prefix string) Option {
+	return func(e *Exporter) { e.prefix = prefix }
+}
+
+// WithTags add the DogStatsD tags to all metrics. eg: "env:prod", "service:api"
+func WithTags(tags ...string) Option {
+	return func(e *Exporter) { e.tags = append(e.tags, tags...) }
+}
+
+// WithInterval set the flush interval of Start, default is DefaultInterval
+func WithInterval(interval time.Duration) Option {
+	return func(e *Exporter) { e.interval = interval }
+}
+
+// Exporter send the cache statistics to a StatsD server.
+type Exporter struct {
+	conn     net.Conn
+	m        *lcache.Manager
+	prefix   string
+	tags     []string
+	interval time.Duration
+
+	// mu 串行执行 Flush. last 为上次发送时各缓存的统计，用于计算计数器的增量
+	mu   sync.Mutex
+	last map[string]lcache.Stats
+
+	started  atomic.Bool
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New create an exporter send the statistics of the caches registered in m to addr.
+// m is nil for lcache.Default()
+func New(addr string, m *lcache.Manager, opts ...Option) (*Exporter, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if m == nil {
+		m = lcache.Default()
+	}
+
+	e := &Exporter{
+		conn:     conn,
+		m:        m,
+		prefix:   DefaultPrefix,
+		interval: DefaultInterval,
+		last:     make(map[string]lcache.Stats),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e, nil
+}
+
+// Start a background goroutine to flush the statistics on every interval.
+func (e *Exporter) Start() {
+	if !e.started.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer close(e.done)
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-e.stop:
+				return
+			case <-ticker.C:
+				_ = e.Flush()
+			}
+		}
+	}()
+}
+
+// Close stop the background goroutine if started, flush the statistics and close the connection.
+func (e *Exporter) Close() error {
+	e.stopOnce.Do(func() {
+		close(e.stop)
+		if e.started.Load() {
+			<-e.done
+		}
+	})
+
+	err := e.Flush()
+	if err1 := e.conn.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
+
+// Flush send the current statistics of all caches, returns the first write error.
+func (e *Exporter) Flush() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var firstErr error
+	var buf bytes.Buffer
+	send := func() {
+		if buf.Len() == 0 {
+			return
+		}
+		if _, err := e.conn.Write(buf.Bytes()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		buf.Reset()
+	}
+
+	stats := e.m.Stats()
+	for name, st := range stats {
+		last := e.last[name]
+		e.last[name] = st
+
+		tags := e.tagString(name)
+		for _, line := range e.lines(st, last) {
+			line += tags
+			if buf.Len() > 0 && buf.Len()+1+len(line) > maxPacketSize {
+				send()
+			}
+			if buf.Len() > 0 {
+				buf.WriteByte('\n')
+			}
+			buf.WriteString(line)
+		}
+	}
+	send()
+
+	// 删除已注销的缓存
+	for name := range e.last {
+		if _, ok := stats[name]; !ok {
+			delete(e.last, name)
+		}
+	}
+	return firstErr
+}
+
+// lines 格式化缓存的指标，不包含 tags
+func (e *Exporter) lines(st, last lcache.Stats) []string {
+	return []string{
+		e.metric("size", strconv.Itoa(st.Len), "g"),
+		e.metric("valid_size", strconv.Itoa(st.ValidLen), "g"),
+		e.metric("cost", strconv.FormatInt(st.Cost, 10), "g"),
+		e.metric("hit_ratio", formatFloat(st.HitRatio()), "g"),
+		e.metric("lock_wait_avg_ms", formatFloat(float64(st.AvgLockWait())/float64(time.Millisecond)), "g"),
+		e.metric("hits", delta(st.Hits, last.Hits), "c"),
+		e.metric("misses", delta(st.Misses, last.Misses), "c"),
+		e.metric("evictions", delta(st.Evictions, last.Evictions), "c"),
+		e.metric("lock_waits", delta(st.LockWaits, last.LockWaits), "c"),
+	}
+}
+
+func (e *Exporter) metric(name, value, typ string) string {
+	return e.prefix + name + ":" + value + "|" + typ
+}
+
+// tagString 格式化 DogStatsD tags. eg: "|#cache:users,env:prod"
+func (e *Exporter) tagString(name string) string {
+	return "|#" + strings.Join(append([]string{"cache:" + name}, e.tags...), ",")
+}
+
+// delta 计数器的增量. 缓存重建后计数小于上次的值时，使用当前值
+func delta(cur, last uint64) string {
+	if cur < last {
+		return strconv.FormatUint(cur, 10)
+	}
+	return strconv.FormatUint(cur-last, 10)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
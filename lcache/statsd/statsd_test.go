@@ -0,0 +1,74 @@
+package statsd_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/lcache/statsd"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+// listen 启动 UDP 服务，返回地址和读取一个数据包的函数
+func listen(t *testing.T) (string, func() string) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoErr(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn.LocalAddr().String(), func() string {
+		buf := make([]byte, 2048)
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		assert.NoErr(t, err)
+		return string(buf[:n])
+	}
+}
+
+func TestExporter_Flush(t *testing.T) {
+	addr, read := listen(t)
+	m := lcache.NewManager()
+	c := lcache.New()
+	m.Register("users", c)
+	c.Set("user:1", "tom", 0)
+	c.Get("user:1")
+	c.Get("none")
+
+	exp, err := statsd.New(addr, m, statsd.WithPrefix("app."), statsd.WithTags("env:prod"))
+	assert.NoErr(t, err)
+
+	assert.NoErr(t, exp.Flush())
+	lines := strings.Split(read(), "\n")
+	assert.Contains(t, lines, "app.size:1|g|#cache:users,env:prod")
+	assert.Contains(t, lines, "app.hit_ratio:0.5|g|#cache:users,env:prod")
+	assert.Contains(t, lines, "app.hits:1|c|#cache:users,env:prod")
+	assert.Contains(t, lines, "app.misses:1|c|#cache:users,env:prod")
+
+	// counters are the delta since the last flush
+	c.Get("user:1")
+	c.Get("user:1")
+	assert.NoErr(t, exp.Close())
+	lines = strings.Split(read(), "\n")
+	assert.Contains(t, lines, "app.hits:2|c|#cache:users,env:prod")
+	assert.Contains(t, lines, "app.misses:0|c|#cache:users,env:prod")
+}
+
+func TestExporter_Start(t *testing.T) {
+	addr, read := listen(t)
+	m := lcache.NewManager()
+	for _, name := range []string{"c1", "c2", "c3", "c4", "c5", "c6", "c7", "c8"} {
+		m.Register(name, lcache.New())
+	}
+
+	exp, err := statsd.New(addr, m, statsd.WithInterval(10*time.Millisecond))
+	assert.NoErr(t, err)
+	exp.Start()
+	defer exp.Close()
+
+	// split into multiple packets
+	pkt := read()
+	assert.True(t, strings.HasPrefix(pkt, "lcache."))
+	assert.Lt(t, len(pkt), 1433)
+	assert.Lt(t, strings.Count(pkt, "\n")+1, 8*9)
+}
@@ -0,0 +1,96 @@
+package lcache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Store backend store for write-through and read-through. eg: database, KV store. see WithStore
+type Store interface {
+	// Load value by key, returns ErrNotFound if the key does not exist
+	Load(ctx context.Context, key string) (any, error)
+	// Save value by key, ttl <= 0 means never expire
+	Save(ctx context.Context, key string, val any, ttl time.Duration) error
+	// Delete value by key
+	Delete(ctx context.Context, key string) error
+}
+
+// DeleteE like Delete, but returns the store error. see WithStore
+func (c *Cache) DeleteE(key string) error {
+	if c.frozen.Load() {
+		return ErrFrozen
+	}
+	key, err := c.normKey(key)
+	if err != nil {
+		return err
+	}
+
+	err = c.storeDelete(key)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	if !c.lock() {
+		return ErrBusy
+	}
+	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return ErrFrozen
+	}
+
+	key = c.nsKey(key)
+	c.removeKey(key, ReasonDeleted)
+	return c.appendAOF(aofOpDel, key, nil, 0)
+}
+
+// storeSave 写入 Store. 未配置 Store 时不处理
+func (c *Cache) storeSave(key string, val any, ttl time.Duration) error {
+	if c.opt.Store == nil {
+		return nil
+	}
+	if c.isWriteBehind() {
+		c.enqueueWrite(c.nsKey(key), newWBEntry(val, ttl))
+		return nil
+	}
+	return c.opt.Store.Save(context.Background(), c.nsKey(key), val, ttl)
+}
+
+// storeLoad 从 Store 加载数据. write-behind 模式下优先使用队列中尚未写入的数据
+func (c *Cache) storeLoad(ctx context.Context, key string) (any, error) {
+	key = c.nsKey(key)
+	if e, ok := c.queuedWrite(key); ok {
+		if e.del || e.expired(time.Now().UnixMilli()) {
+			return nil, ErrNotFound
+		}
+		return e.val, nil
+	}
+	return c.opt.Store.Load(ctx, key)
+}
+
+// storeSaveAll 批量写入 Store，返回写入成功的数据
+func (c *Cache) storeSaveAll(items map[string]any, ttl time.Duration) map[string]any {
+	if c.opt.Store == nil {
+		return items
+	}
+
+	saved := make(map[string]any, len(items))
+	for key, val := range items {
+		if c.storeSave(key, val, ttl) == nil {
+			saved[key] = val
+		}
+	}
+	return saved
+}
+
+// storeDelete 从 Store 删除. 未配置 Store 时不处理
+func (c *Cache) storeDelete(key string) error {
+	if c.opt.Store == nil {
+		return nil
+	}
+	if c.isWriteBehind() {
+		c.enqueueWrite(c.nsKey(key), &wbEntry{del: true})
+		return nil
+	}
+	return c.opt.Store.Delete(context.Background(), c.nsKey(key))
+}
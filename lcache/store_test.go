@@ -0,0 +1,172 @@
+package lcache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+// memStore a Store for testing
+type memStore struct {
+	mu    sync.Mutex
+	data  map[string]any
+	loads int
+	fail  bool
+	// failKey Save fails for the key
+	failKey string
+}
+
+func newMemStore() *memStore {
+	return &memStore{data: make(map[string]any)}
+}
+
+func (m *memStore) Load(_ context.Context, key string) (any, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.loads++
+	if val, ok := m.data[key]; ok {
+		return val, nil
+	}
+	return nil, lcache.ErrNotFound
+}
+
+func (m *memStore) Save(_ context.Context, key string, val any, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.fail || key == m.failKey {
+		return errors.New("store save failed")
+	}
+	m.data[key] = val
+	return nil
+}
+
+func (m *memStore) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+func TestWithStore(t *testing.T) {
+	s := newMemStore()
+	s.data["db-key"] = "db-val"
+	c := lcache.New(lcache.WithStore(s), lcache.WithStoreTTL(time.Minute))
+
+	// read through
+	assert.Eq(t, "db-val", c.Val("db-key"))
+	assert.Eq(t, "db-val", c.Val("db-key"))
+	assert.Eq(t, 1, s.loads)
+
+	_, ok := c.Get("not-exists")
+	assert.False(t, ok)
+	_, err := c.GetCtx(context.Background(), "not-exists")
+	assert.ErrIs(t, err, lcache.ErrNotFound)
+
+	// write through
+	c.Set("key1", "val1", 0)
+	c.MSet(map[string]any{"key2": "val2"}, 0)
+	assert.Eq(t, "val1", s.data["key1"])
+	assert.Eq(t, "val2", s.data["key2"])
+
+	c.Delete("key1")
+	c.MDelete("key2")
+	assert.Empty(t, s.data["key1"])
+	assert.Empty(t, s.data["key2"])
+	assert.NoErr(t, c.DeleteE("db-key"))
+	assert.Len(t, s.data, 0)
+
+	// store error, not write to local
+	s.fail = true
+	assert.Err(t, c.SetE("key3", "val3", 0))
+	assert.False(t, c.Has("key3"))
+
+	// namespace keys with prefix
+	s.fail = false
+	c.Namespace("ns").Set("a", 1, 0)
+	assert.Eq(t, 1, s.data["ns:a"])
+}
+
+func TestWithWriteBehind(t *testing.T) {
+	s := newMemStore()
+	var errKeys []string
+	c := lcache.New(
+		lcache.WithStore(s),
+		lcache.WithWriteBehind(time.Hour, 3, func(key string, err error) {
+			errKeys = append(errKeys, key)
+		}),
+	)
+
+	c.Set("key1", "val1", 0)
+	c.Set("key2", "val2", 0)
+	assert.Len(t, s.data, 0)
+
+	// read the queued write
+	c.Delete("key2")
+	_, err := c.GetCtx(context.Background(), "key2")
+	assert.ErrIs(t, err, lcache.ErrNotFound)
+
+	assert.NoErr(t, c.Flush())
+	assert.Eq(t, map[string]any{"key1": "val1"}, s.data)
+
+	// flush in background on the queue is full
+	c.MSet(map[string]any{"key3": 3, "key4": 4, "key5": 5}, 0)
+	time.Sleep(20 * time.Millisecond)
+	s.mu.Lock()
+	assert.Len(t, s.data, 4)
+	s.mu.Unlock()
+
+	// error callback
+	s.fail = true
+	c.Set("key6", 6, 0)
+	assert.Err(t, c.Close())
+	assert.Eq(t, []string{"key6"}, errKeys)
+}
+
+// slowStore a Store blocks the Save until release, and records the saved ttl
+type slowStore struct {
+	*memStore
+	saving  chan struct{}
+	release chan struct{}
+	ttl     time.Duration
+}
+
+func (s *slowStore) Save(ctx context.Context, key string, val any, ttl time.Duration) error {
+	s.saving <- struct{}{}
+	<-s.release
+	s.ttl = ttl
+	return s.memStore.Save(ctx, key, val, ttl)
+}
+
+func TestWithWriteBehind_inFlight(t *testing.T) {
+	s := &slowStore{
+		memStore: newMemStore(),
+		saving:   make(chan struct{}),
+		release:  make(chan struct{}),
+	}
+	s.data["key1"] = "old"
+	c := lcache.New(lcache.WithStore(s), lcache.WithWriteBehind(time.Hour, 0, nil))
+
+	c.Set("key1", "new", time.Minute)
+	time.Sleep(20 * time.Millisecond)
+
+	done := make(chan error)
+	go func() { done <- c.Flush() }()
+	<-s.saving
+
+	// the entry being written is still visible to the read through
+	c.Clear()
+	val, err := c.GetCtx(context.Background(), "key1")
+	assert.NoErr(t, err)
+	assert.Eq(t, "new", val)
+
+	close(s.release)
+	assert.NoErr(t, <-done)
+	assert.Eq(t, "new", s.data["key1"])
+	// saved with the remaining ttl
+	assert.True(t, s.ttl > 0 && s.ttl < time.Minute)
+}
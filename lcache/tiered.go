@@ -0,0 +1,162 @@
+package lcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// RemoteCache the remote L2 cache for Tiered. eg: redis, memcached
+type RemoteCache interface {
+	// Get value by key, returns ErrNotFound if the key does not exist
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set value with ttl, ttl <= 0 means never expire
+	Set(ctx context.Context, key string, val []byte, ttl time.Duration) error
+	// Del delete the key
+	Del(ctx context.Context, key string) error
+}
+
+// Tiered a two-tier cache, reads the local cache first, falls back to the remote
+// cache, and back-fills the local cache.
+//
+// The remote values are saved with their type name, so Get restores the same type
+// as Set: the builtin scalar types and the types registered by RegisterType.
+// The other values are decoded as the Serializer does, eg: map[string]any from JSON.
+type Tiered struct {
+	local  *Cache
+	remote RemoteCache
+
+	// Serializer for encode/decode the values of remote cache.
+	// default is the serializer of the local cache, or JSONSerializer if not registered.
+	Serializer Serializer
+	// BackfillTTL TTL for back-fill the local cache from remote. default is 1 minute
+	BackfillTTL time.Duration
+}
+
+// NewTiered create a two-tier cache with local L1 and remote L2.
+//
+// Usage:
+//
+//	tc := lcache.NewTiered(lcache.New(), lcredis.New(rdb))
+//	err := tc.Set(ctx, "key", "value", time.Hour)
+//	val, err := tc.Get(ctx, "key")
+func NewTiered(local *Cache, remote RemoteCache) *Tiered {
+	s, err := local.serializer()
+	if err != nil {
+		s = JSONSerializer{}
+	}
+
+	return &Tiered{
+		local:       local,
+		remote:      remote,
+		Serializer:  s,
+		BackfillTTL: time.Minute,
+	}
+}
+
+// tieredValue 远程缓存中保存的值. Typ 为值的类型名称，用于解码后还原具体类型
+type tieredValue struct {
+	Val any    `json:"v"`
+	Typ string `json:"t,omitempty"`
+}
+
+// basicTypes 内置的基础类型，无需注册即可还原. eg: JSON 解码的 float64 还原为 int
+var basicTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, typ := range []reflect.Type{
+		reflect.TypeFor[bool](), reflect.TypeFor[string](),
+		reflect.TypeFor[int](), reflect.TypeFor[int8](), reflect.TypeFor[int16](),
+		reflect.TypeFor[int32](), reflect.TypeFor[int64](),
+		reflect.TypeFor[uint](), reflect.TypeFor[uint8](), reflect.TypeFor[uint16](),
+		reflect.TypeFor[uint32](), reflect.TypeFor[uint64](),
+		reflect.TypeFor[float32](), reflect.TypeFor[float64](),
+	} {
+		basicTypes[typ.String()] = typ
+	}
+}
+
+// encode 编码值及其类型名称. 优先使用 RegisterType 注册的名称
+func (t *Tiered) encode(val any) ([]byte, error) {
+	tv := tieredValue{Val: val, Typ: typeName(val)}
+	if tv.Typ == "" && val != nil {
+		if typ := reflect.TypeOf(val); basicTypes[typ.String()] == typ {
+			tv.Typ = typ.String()
+		}
+	}
+
+	if _, ok := t.Serializer.(GobSerializer); ok {
+		GobRegister(val)
+	}
+	return t.Serializer.Encode(tv)
+}
+
+// decode 解码远程缓存的值，并按记录的类型名称还原具体类型
+func (t *Tiered) decode(bs []byte) (any, error) {
+	var tv tieredValue
+	if err := t.Serializer.Decode(bs, &tv); err != nil {
+		return nil, err
+	}
+
+	typ, ok := basicTypes[tv.Typ]
+	if !ok {
+		return RestoreType(tv.Typ, tv.Val)
+	}
+
+	rv := reflect.ValueOf(tv.Val)
+	if !rv.IsValid() || !rv.CanConvert(typ) {
+		return nil, fmt.Errorf("lcache: cannot restore the remote value %T as %s", tv.Val, typ)
+	}
+	return rv.Convert(typ).Interface(), nil
+}
+
+// Local get the local cache
+func (t *Tiered) Local() *Cache { return t.local }
+
+// Get value by key, returns ErrNotFound if the key does not exist in both tiers.
+func (t *Tiered) Get(ctx context.Context, key string) (any, error) {
+	if val, ok := t.local.Get(key); ok {
+		return val, nil
+	}
+
+	bs, err := t.remote.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := t.decode(bs)
+	if err != nil {
+		return nil, err
+	}
+
+	t.local.Set(key, val, t.BackfillTTL)
+	return val, nil
+}
+
+// Set value to both tiers. the local TTL is limited by BackfillTTL
+func (t *Tiered) Set(ctx context.Context, key string, val any, ttl time.Duration) error {
+	bs, err := t.encode(val)
+	if err != nil {
+		return err
+	}
+	if err = t.remote.Set(ctx, key, bs, ttl); err != nil {
+		return err
+	}
+
+	localTTL := t.BackfillTTL
+	if ttl > 0 && (localTTL <= 0 || ttl < localTTL) {
+		localTTL = ttl
+	}
+	return t.local.SetE(key, val, localTTL)
+}
+
+// Delete the key from both tiers
+func (t *Tiered) Delete(ctx context.Context, key string) error {
+	t.local.Delete(key)
+	if err := t.remote.Del(ctx, key); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package lcache_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+// memRemote a RemoteCache for testing
+type memRemote struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	gets int
+}
+
+func (m *memRemote) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gets++
+	if bs, ok := m.data[key]; ok {
+		return bs, nil
+	}
+	return nil, lcache.ErrNotFound
+}
+
+func (m *memRemote) Set(_ context.Context, key string, val []byte, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = val
+	return nil
+}
+
+func (m *memRemote) Del(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+func TestTiered(t *testing.T) {
+	ctx := context.Background()
+	remote := &memRemote{data: map[string][]byte{"remote": []byte(`{"v":"rval","t":"string"}`)}}
+	tc := lcache.NewTiered(lcache.New(), remote)
+
+	// fallback to remote and back-fill local
+	val, err := tc.Get(ctx, "remote")
+	assert.NoErr(t, err)
+	assert.Eq(t, "rval", val)
+	assert.Eq(t, "rval", tc.Local().Val("remote"))
+
+	val, err = tc.Get(ctx, "remote")
+	assert.NoErr(t, err)
+	assert.Eq(t, "rval", val)
+	assert.Eq(t, 1, remote.gets)
+
+	_, err = tc.Get(ctx, "not-exists")
+	assert.ErrIs(t, err, lcache.ErrNotFound)
+
+	// set to both tiers
+	assert.NoErr(t, tc.Set(ctx, "key1", "val1", time.Hour))
+	assert.Eq(t, `{"v":"val1","t":"string"}`, string(remote.data["key1"]))
+	assert.Eq(t, "val1", tc.Local().Val("key1"))
+
+	assert.NoErr(t, tc.Delete(ctx, "key1"))
+	assert.False(t, tc.Local().Has("key1"))
+	_, err = tc.Get(ctx, "key1")
+	assert.ErrIs(t, err, lcache.ErrNotFound)
+}
+
+func TestTiered_restoreType(t *testing.T) {
+	lcache.RegisterType[typedUser]("user")
+
+	ctx := context.Background()
+	for _, name := range []string{"json", "gob"} {
+		remote := &memRemote{data: map[string][]byte{}}
+		tc := lcache.NewTiered(lcache.New(lcache.WithSerializer(name)), remote)
+
+		assert.NoErr(t, tc.Set(ctx, "int", 23, time.Hour))
+		assert.NoErr(t, tc.Set(ctx, "user", typedUser{ID: 1, Name: "inhere"}, time.Hour))
+		assert.NoErr(t, tc.Set(ctx, "list", []string{"a", "b"}, time.Hour))
+		tc.Local().Clear()
+
+		// back-filled with the same type
+		val, err := tc.Get(ctx, "int")
+		assert.NoErr(t, err)
+		assert.Eq(t, 23, val, name)
+		val, err = tc.Get(ctx, "user")
+		assert.NoErr(t, err)
+		assert.Eq(t, typedUser{ID: 1, Name: "inhere"}, val, name)
+		assert.Eq(t, typedUser{ID: 1, Name: "inhere"}, tc.Local().Val("user"), name)
+
+		// not registered, decoded by the serializer
+		val, err = tc.Get(ctx, "list")
+		assert.NoErr(t, err)
+		assert.NotEmpty(t, val)
+	}
+}
@@ -0,0 +1,92 @@
+// Package tokencache caches the credentials(eg: OAuth access tokens) and refreshes them
+// transparently when near expiry.
+//
+// Usage:
+//
+//	tc := tokencache.New(func(ctx context.Context, name string) (tokencache.Token, error) {
+//		tok, err := oauthConf.Token(ctx)
+//		if err != nil {
+//			return tokencache.Token{}, err
+//		}
+//		return tokencache.Token{Value: tok.AccessToken, ExpiresAt: tok.Expiry}, nil
+//	})
+//
+//	tok, err := tc.Get(ctx, "github")
+package tokencache
+
+import (
+	"context"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+)
+
+// Token a cached credential
+type Token struct {
+	// Value of the token. eg: access token
+	Value string
+	// ExpiresAt the expiry time, zero for never expire
+	ExpiresAt time.Time
+}
+
+// RefreshFunc fetch a new token by name
+type RefreshFunc func(ctx context.Context, name string) (Token, error)
+
+// Option for the Cache
+type Option func(tc *Cache)
+
+// WithEarly refresh the token before it expires by d. default is 1 minute
+func WithEarly(d time.Duration) Option {
+	return func(tc *Cache) { tc.early = d }
+}
+
+// Cache the token cache
+type Cache struct {
+	c       *lcache.Cache
+	refresh RefreshFunc
+	early   time.Duration
+}
+
+// New create a token cache with the refresh func
+func New(refresh RefreshFunc, opts ...Option) *Cache {
+	if refresh == nil {
+		panic("tokencache: refresh func is required")
+	}
+
+	tc := &Cache{refresh: refresh, early: time.Minute}
+	for _, opt := range opts {
+		opt(tc)
+	}
+
+	tc.c = lcache.New(lcache.WithLoader(tc.fetch))
+	return tc
+}
+
+// Get the token by name. If it is missing or near expiry, calls the refresh func to fetch
+// a new one. Concurrent calls for the same name only call the refresh func once.
+func (tc *Cache) Get(ctx context.Context, name string) (Token, error) {
+	val, err := tc.c.GetCtx(ctx, name)
+	if err != nil {
+		return Token{}, err
+	}
+	return val.(Token), nil
+}
+
+// Invalidate remove the cached token, the next Get will refresh it. eg: on the token is revoked
+func (tc *Cache) Invalidate(name string) { tc.c.Delete(name) }
+
+// fetch 获取新的 token. 缓存的 TTL 为距离过期的时间减去 early，
+// 所以即将过期的 token 会被视为缓存未命中，下次获取时自动刷新
+func (tc *Cache) fetch(ctx context.Context, name string) (any, time.Duration, error) {
+	tok, err := tc.refresh(ctx, name)
+	if err != nil {
+		return nil, 0, err
+	}
+	if tok.ExpiresAt.IsZero() {
+		return tok, 0, nil
+	}
+
+	// 已临近过期的 token 只缓存很短的时间，TTL 为 0 会永不过期
+	ttl := time.Until(tok.ExpiresAt) - tc.early
+	return tok, max(ttl, time.Millisecond), nil
+}
@@ -0,0 +1,73 @@
+package tokencache_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache/tokencache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_Get(t *testing.T) {
+	var calls atomic.Int32
+	tc := tokencache.New(func(ctx context.Context, name string) (tokencache.Token, error) {
+		n := calls.Add(1)
+		time.Sleep(5 * time.Millisecond)
+		return tokencache.Token{
+			Value:     name + "-" + strconv.Itoa(int(n)),
+			ExpiresAt: time.Now().Add(80 * time.Millisecond),
+		}, nil
+	}, tokencache.WithEarly(50*time.Millisecond))
+
+	// concurrent calls only refresh once
+	var wg sync.WaitGroup
+	vals := make([]string, 10)
+	for i := range vals {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tok, _ := tc.Get(context.Background(), "api")
+			vals[i] = tok.Value
+		}(i)
+	}
+	wg.Wait()
+	for _, val := range vals {
+		assert.Eq(t, "api-1", val)
+	}
+	assert.Eq(t, int32(1), calls.Load())
+
+	// near expiry: refresh
+	time.Sleep(40 * time.Millisecond)
+	tok, err := tc.Get(context.Background(), "api")
+	assert.NoErr(t, err)
+	assert.Eq(t, "api-2", tok.Value)
+
+	tc.Invalidate("api")
+	tok, err = tc.Get(context.Background(), "api")
+	assert.NoErr(t, err)
+	assert.Eq(t, "api-3", tok.Value)
+}
+
+func TestCache_error(t *testing.T) {
+	tc := tokencache.New(func(ctx context.Context, name string) (tokencache.Token, error) {
+		if name == "bad" {
+			return tokencache.Token{}, errors.New("refresh failed")
+		}
+		return tokencache.Token{Value: "never"}, nil
+	})
+
+	_, err := tc.Get(context.Background(), "bad")
+	assert.ErrMsg(t, err, "refresh failed")
+
+	tok, err := tc.Get(context.Background(), "ok")
+	assert.NoErr(t, err)
+	assert.Eq(t, "never", tok.Value)
+	assert.True(t, tok.ExpiresAt.IsZero())
+
+	assert.Panics(t, func() { tokencache.New(nil) })
+}
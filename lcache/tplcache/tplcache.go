@@ -0,0 +1,187 @@
+// Package tplcache caches the parsed text/template and html/template objects, so the
+// web apps do not re-parse templates per request.
+//
+// The templates parsed from source are keyed by name + source hash. The templates parsed
+// from files are re-parsed when any of the files(include the layouts and partials) changes.
+//
+// Usage:
+//
+//	tc := tplcache.NewHTML(tplcache.WithFuncs(funcs))
+//	// views/home.tpl with the layout and partial files
+//	tpl, err := tc.ParseFiles("views/home.tpl", "views/layout.tpl", "views/header.tpl")
+//	err = tpl.Execute(w, data)
+package tplcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	htmltemplate "html/template"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/lcache/filecache"
+)
+
+// errNoFiles 没有指定模板文件
+var errNoFiles = errors.New("tplcache: no files named in ParseFiles")
+
+// Option for the Cache
+type Option func(o *options)
+
+// options 配置
+type options struct {
+	funcs    map[string]any
+	capacity int
+}
+
+// WithFuncs set the template functions
+func WithFuncs(funcs map[string]any) Option {
+	return func(o *options) { o.funcs = funcs }
+}
+
+// WithCapacity set the max number of cached templates. default is 1000
+func WithCapacity(n int) Option {
+	return func(o *options) { o.capacity = n }
+}
+
+// source 模板名称和内容
+type source struct {
+	name string
+	text string
+}
+
+// srcFile 缓存的模板文件内容和 hash
+type srcFile struct {
+	text string
+	hash string
+}
+
+// entry 缓存的模板. hash 为所有文件 hash 的组合
+type entry[T any] struct {
+	tpl  T
+	hash string
+}
+
+// Cache caches the parsed templates
+type Cache[T any] struct {
+	c     *lcache.Cache
+	fc    *filecache.Cache[srcFile]
+	parse func(srcs []source) (T, error)
+}
+
+// NewHTML create a cache for html/template
+func NewHTML(opts ...Option) *Cache[*htmltemplate.Template] {
+	o := newOptions(opts)
+	return newCache(o, func(srcs []source) (*htmltemplate.Template, error) {
+		t := htmltemplate.New(srcs[0].name).Funcs(o.funcs)
+		for i, src := range srcs {
+			tmpl := t
+			if i > 0 {
+				tmpl = t.New(src.name)
+			}
+			if _, err := tmpl.Parse(src.text); err != nil {
+				return nil, err
+			}
+		}
+		return t, nil
+	})
+}
+
+// NewText create a cache for text/template
+func NewText(opts ...Option) *Cache[*texttemplate.Template] {
+	o := newOptions(opts)
+	return newCache(o, func(srcs []source) (*texttemplate.Template, error) {
+		t := texttemplate.New(srcs[0].name).Funcs(o.funcs)
+		for i, src := range srcs {
+			tmpl := t
+			if i > 0 {
+				tmpl = t.New(src.name)
+			}
+			if _, err := tmpl.Parse(src.text); err != nil {
+				return nil, err
+			}
+		}
+		return t, nil
+	})
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{capacity: 1000}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func newCache[T any](o *options, parse func(srcs []source) (T, error)) *Cache[T] {
+	return &Cache[T]{
+		c:     lcache.New(lcache.WithCapacity(o.capacity)),
+		parse: parse,
+		fc: filecache.New(func(_ string, data []byte) (srcFile, error) {
+			return srcFile{text: string(data), hash: hashOf(string(data))}, nil
+		}, filecache.WithCapacity(o.capacity)),
+	}
+}
+
+// Parse the template source with name, the result is cached by name + source hash.
+func (tc *Cache[T]) Parse(name, src string) (T, error) {
+	key := "src:" + name + ":" + hashOf(src)
+	val, err := tc.c.GetOrLoad(key, 0, func(string) (any, error) {
+		return tc.parse([]source{{name: name, text: src}})
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return val.(T), nil
+}
+
+// ParseFiles parse the template files like template.ParseFiles: the first file is the main
+// template, the others are the dependencies(layouts, partials). Each template is named by
+// the base name of the file.
+//
+// The result is re-parsed when any of the files changes. see filecache
+func (tc *Cache[T]) ParseFiles(files ...string) (T, error) {
+	var zero T
+	if len(files) == 0 {
+		return zero, errNoFiles
+	}
+
+	// 通过文件缓存读取，文件的修改时间或大小变化时重新读取
+	srcs := make([]source, len(files))
+	hashes := make([]string, len(files))
+	for i, file := range files {
+		f, err := tc.fc.Get(file)
+		if err != nil {
+			return zero, err
+		}
+		srcs[i] = source{name: filepath.Base(file), text: f.text}
+		hashes[i] = f.hash
+	}
+
+	key := "files:" + strings.Join(files, "\x00")
+	hash := strings.Join(hashes, ",")
+	if val, ok := tc.c.Get(key); ok {
+		if e := val.(*entry[T]); e.hash == hash {
+			return e.tpl, nil
+		}
+	}
+
+	tpl, err := tc.parse(srcs)
+	if err != nil {
+		return zero, err
+	}
+	tc.c.Set(key, &entry[T]{tpl: tpl, hash: hash}, 0)
+	return tpl, nil
+}
+
+// Clear all the cached templates
+func (tc *Cache[T]) Clear() { tc.c.Clear() }
+
+func hashOf(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,71 @@
+package tplcache_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache/tplcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_Parse(t *testing.T) {
+	tc := tplcache.NewText(tplcache.WithFuncs(map[string]any{"upper": strings.ToUpper}))
+
+	t1, err := tc.Parse("hello", `hello {{upper .}}`)
+	assert.NoErr(t, err)
+	t2, err := tc.Parse("hello", `hello {{upper .}}`)
+	assert.NoErr(t, err)
+	assert.True(t, t1 == t2)
+
+	// source changed
+	t3, err := tc.Parse("hello", `hi {{upper .}}`)
+	assert.NoErr(t, err)
+	assert.False(t, t1 == t3)
+
+	buf := new(bytes.Buffer)
+	assert.NoErr(t, t3.Execute(buf, "tom"))
+	assert.Eq(t, "hi TOM", buf.String())
+
+	_, err = tc.Parse("bad", `{{.Name`)
+	assert.Err(t, err)
+}
+
+func TestCache_ParseFiles(t *testing.T) {
+	dir := t.TempDir()
+	home := filepath.Join(dir, "home.tpl")
+	layout := filepath.Join(dir, "layout.tpl")
+	assert.NoErr(t, os.WriteFile(home, []byte(`{{template "layout.tpl" .}}`), 0644))
+	assert.NoErr(t, os.WriteFile(layout, []byte(`<b>{{.}}</b>`), 0644))
+
+	tc := tplcache.NewHTML()
+	t1, err := tc.ParseFiles(home, layout)
+	assert.NoErr(t, err)
+	t2, err := tc.ParseFiles(home, layout)
+	assert.NoErr(t, err)
+	assert.True(t, t1 == t2)
+
+	buf := new(bytes.Buffer)
+	assert.NoErr(t, t2.Execute(buf, "<x>"))
+	assert.Eq(t, "<b>&lt;x&gt;</b>", buf.String())
+
+	// dependency file changed
+	assert.NoErr(t, os.WriteFile(layout, []byte(`<i>{{.}}</i>`), 0644))
+	mtime := time.Now().Add(time.Second)
+	assert.NoErr(t, os.Chtimes(layout, mtime, mtime))
+
+	t3, err := tc.ParseFiles(home, layout)
+	assert.NoErr(t, err)
+	assert.False(t, t1 == t3)
+	buf.Reset()
+	assert.NoErr(t, t3.Execute(buf, "a"))
+	assert.Eq(t, "<i>a</i>", buf.String())
+
+	_, err = tc.ParseFiles()
+	assert.Err(t, err)
+	_, err = tc.ParseFiles(filepath.Join(dir, "none.tpl"))
+	assert.Err(t, err)
+}
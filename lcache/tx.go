@@ -0,0 +1,252 @@
+package lcache
+
+import (
+	"errors"
+	"time"
+)
+
+// Tx a transaction on the cache, the writes are buffered and applied
+// atomically on commit. see Cache.Update
+type Tx struct {
+	c *Cache
+	// 缓冲的写操作: key -> op. 按写入顺序记录 key
+	ops  map[string]*txOp
+	keys []string
+	// err 第一个失败的写操作的错误，提交时中止事务
+	err error
+	// 事务是否已提交
+	done bool
+}
+
+// txOp 事务中的写操作. del 为 true 表示删除
+type txOp struct {
+	del bool
+	val any
+	exp int64
+}
+
+// Update run fn in a transaction, holding the cache write lock.
+//
+// The writes made by Tx.Set and Tx.Delete are applied atomically after fn returns nil,
+// or discarded if fn returns an error or panics. Returns the error of fn, ErrBusy
+// if the lock cannot be acquired in time, or ErrFrozen if the cache is frozen.
+// If a write in fn failed(eg: the key or value exceeds the size limit), all writes are
+// discarded and returns the error of the write, eg: a *SizeError matches ErrKeyTooLong
+// or ErrValueTooLarge.
+// With the RejectNew overflow policy, returns ErrCacheFull and discards all writes
+// if the new keys do not fit in the cache.
+//
+// With Store configured, the writes are written through to the store before applied.
+// If any of them fails, the store writes already made by the transaction are rolled back
+// to the values in the cache, returns the store errors and discards all writes. see WithStore
+//
+// Usage:
+//
+//	err := c.Update(func(tx *lcache.Tx) error {
+//		from, _ := tx.Get("account:1")
+//		to, _ := tx.Get("account:2")
+//		if from.(int) < 100 {
+//			return errors.New("insufficient balance")
+//		}
+//		tx.Set("account:1", from.(int)-100, 0)
+//		tx.Set("account:2", to.(int)+100, 0)
+//		return nil
+//	})
+//
+// NOTE: do not call the cache methods in fn, it will deadlock.
+func (c *Cache) Update(fn func(tx *Tx) error) error {
+	if !c.lock() {
+		return ErrBusy
+	}
+
+	tx := &Tx{c: c, ops: make(map[string]*txOp)}
+	// 释放锁后再调用 OnSet 回调
+	defer tx.notify()
+	defer c.mu.Unlock()
+	if c.frozen.Load() {
+		return ErrFrozen
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if tx.err != nil {
+		return tx.err
+	}
+	if !tx.fits() {
+		return ErrCacheFull
+	}
+	if err := tx.writeStore(); err != nil {
+		return err
+	}
+	tx.done = true
+	return tx.commit()
+}
+
+// Get value by key, can see the uncommitted writes in the transaction.
+func (tx *Tx) Get(key string) (any, bool) {
+	key, err := tx.c.normKey(key)
+	if err != nil {
+		return nil, false
+	}
+
+	key = tx.c.nsKey(key)
+	if op, ok := tx.ops[key]; ok {
+		if op.del || (op.exp > 0 && time.Now().UnixMilli() > op.exp) {
+			return nil, false
+		}
+		return op.val, true
+	}
+
+	_, it := tx.c.find(key)
+	if it == nil || tx.c.invalid(it, time.Now().UnixMilli()) {
+		return nil, false
+	}
+	return it.Val, true
+}
+
+// Set value to the key in the transaction. see Cache.Set
+//
+// Returns the error of the KeyFunc, or a *SizeError if the key or value exceeds the
+// size limit. The failed write aborts the transaction, Update discards all writes and
+// returns the error. see WithMaxKeyLen, WithMaxValueBytes
+func (tx *Tx) Set(key string, value any, ttl time.Duration) error {
+	return tx.put(key, &txOp{val: value, exp: ttlToExp(ttl)})
+}
+
+// Delete the key in the transaction. Returns the error of the KeyFunc, it aborts the
+// transaction like Set.
+func (tx *Tx) Delete(key string) error {
+	return tx.put(key, &txOp{del: true})
+}
+
+// put 缓冲写操作. 失败时记录到 tx.err，之后的写操作都返回该错误
+func (tx *Tx) put(key string, op *txOp) error {
+	if tx.err != nil {
+		return tx.err
+	}
+
+	key, err := tx.c.normKey(key)
+	if err == nil && !op.del {
+		err = tx.c.checkSize(key, op.val)
+	}
+	if err != nil {
+		tx.err = err
+		return err
+	}
+
+	key = tx.c.nsKey(key)
+	if _, ok := tx.ops[key]; !ok {
+		tx.keys = append(tx.keys, key)
+	}
+	tx.ops[key] = op
+	return nil
+}
+
+// commit 按写入顺序应用事务中的写操作，返回所有追加 AOF 的错误 (已加锁)
+func (tx *Tx) commit() error {
+	var errs []error
+	for _, key := range tx.keys {
+		op := tx.ops[key]
+		if op.del {
+			tx.c.removeKey(key, ReasonDeleted)
+			errs = append(errs, tx.c.appendAOF(aofOpDel, key, nil, 0))
+		} else {
+			tx.c.set(key, op.val, op.exp)
+			errs = append(errs, tx.c.appendAOF(aofOpSet, key, op.val, op.exp))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeStore 按写入顺序将事务中的写操作写入 Store (已加锁). 写入失败时停止，
+// 并将已写入的 key 回滚为缓存中的值，返回写入和回滚的错误.
+// 事务中的 key 已添加命名空间前缀，使用根视图写入
+func (tx *Tx) writeStore() error {
+	if tx.c.opt.Store == nil {
+		return nil
+	}
+
+	root := &Cache{core: tx.c.core}
+	for i, key := range tx.keys {
+		op := tx.ops[key]
+		if err := root.writeStoreOp(key, op.del, op.val, op.exp); err != nil {
+			return errors.Join(err, tx.rollbackStore(root, tx.keys[:i]))
+		}
+	}
+	return nil
+}
+
+// rollbackStore 按相反的顺序将已写入 Store 的 key 恢复为缓存中的值，缓存中不存在的从 Store 删除 (已加锁)
+func (tx *Tx) rollbackStore(root *Cache, keys []string) error {
+	nowUm := time.Now().UnixMilli()
+	var errs []error
+	for i := len(keys) - 1; i >= 0; i-- {
+		key := keys[i]
+		if _, it := root.find(key); it != nil && !root.invalid(it, nowUm) {
+			errs = append(errs, root.writeStoreOp(key, false, it.Val, it.Exp))
+		} else {
+			errs = append(errs, root.writeStoreOp(key, true, nil, 0))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeStoreOp 写入或删除 Store 中的 key，exp 为过期时间 millitime. 删除不存在的 key 不视为错误
+func (c *Cache) writeStoreOp(key string, del bool, val any, exp int64) error {
+	if del {
+		if err := c.storeDelete(key); !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		return nil
+	}
+
+	var ttl time.Duration
+	if exp > 0 {
+		ttl = time.Until(time.UnixMilli(exp))
+	}
+	return c.storeSave(key, val, ttl)
+}
+
+// fits 检查溢出策略为 RejectNew 时缓存是否有空间写入事务中的新 key (已加锁)
+func (tx *Tx) fits() bool {
+	c := tx.c
+	if c.opt.Overflow != RejectNew || c.opt.DisableLRU {
+		return true
+	}
+
+	count := func() int {
+		n := len(c.items)
+		for _, key := range tx.keys {
+			_, exists := c.items[c.hashKey(key)]
+			if op := tx.ops[key]; op.del && exists {
+				n--
+			} else if !op.del && !exists {
+				n++
+			}
+		}
+		return n
+	}
+
+	if count() <= c.opt.Capacity {
+		return true
+	}
+	return c.pruneForRoom() && count() <= c.opt.Capacity
+}
+
+// notify 对已提交的写入调用 OnSet 回调 (不加锁)
+func (tx *Tx) notify() {
+	if !tx.done || tx.c.opt.OnSet == nil {
+		return
+	}
+
+	for _, key := range tx.keys {
+		if op := tx.ops[key]; !op.del {
+			var ttl time.Duration
+			if op.exp > 0 {
+				ttl = time.Until(time.UnixMilli(op.exp))
+			}
+			tx.c.opt.OnSet(key, op.val, ttl)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package lcache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_Update(t *testing.T) {
+	c := lcache.New()
+	c.Set("account:1", 100, 0)
+	c.Set("account:2", 0, 0)
+	c.Set("tmp", 1, 0)
+
+	transfer := func(tx *lcache.Tx) error {
+		from, _ := tx.Get("account:1")
+		to, _ := tx.Get("account:2")
+		if from.(int) < 100 {
+			return errors.New("insufficient balance")
+		}
+
+		tx.Set("account:1", from.(int)-100, 0)
+		tx.Set("account:2", to.(int)+100, time.Minute)
+		tx.Delete("tmp")
+
+		// read uncommitted writes in tx
+		val, ok := tx.Get("account:1")
+		assert.True(t, ok)
+		assert.Eq(t, 0, val)
+		_, ok = tx.Get("tmp")
+		assert.False(t, ok)
+		return nil
+	}
+
+	assert.NoErr(t, c.Update(transfer))
+	assert.Eq(t, 0, c.Val("account:1"))
+	assert.Eq(t, 100, c.Val("account:2"))
+	assert.False(t, c.Has("tmp"))
+
+	// rollback on error
+	err := c.Update(func(tx *lcache.Tx) error {
+		tx.Set("account:2", 0, 0)
+		return transfer(tx)
+	})
+	assert.Err(t, err)
+	assert.Eq(t, 100, c.Val("account:2"))
+
+	// rollback on panic
+	assert.Panics(t, func() {
+		_ = c.Update(func(tx *lcache.Tx) error {
+			tx.Delete("account:2")
+			panic("oops")
+		})
+	})
+	assert.Eq(t, 100, c.Val("account:2"))
+}
+
+func TestCache_Update_errors(t *testing.T) {
+	// all the append errors are returned
+	p := newMemProvider()
+	c := lcache.New(lcache.WithPersistProvider(p))
+	err := c.Update(func(tx *lcache.Tx) error {
+		tx.Set("bad", 1, 0)
+		tx.Set("key1", 1, 0)
+		return nil
+	})
+	assert.Err(t, err)
+	assert.True(t, c.Has("key1"))
+
+	// write through to the store
+	s := newMemStore()
+	c = lcache.New(lcache.WithStore(s))
+	c.Set("key2", 2, 0)
+	assert.NoErr(t, c.Namespace("ns").Update(func(tx *lcache.Tx) error {
+		tx.Set("key1", 1, time.Hour)
+		return nil
+	}))
+	assert.Eq(t, 1, s.data["ns:key1"])
+	assert.NoErr(t, c.Update(func(tx *lcache.Tx) error {
+		tx.Delete("key2")
+		return nil
+	}))
+	assert.False(t, c.Has("key2"))
+	assert.Nil(t, s.data["key2"])
+
+	// discard all writes on the store error
+	s.fail = true
+	err = c.Update(func(tx *lcache.Tx) error {
+		tx.Set("key3", 3, 0)
+		return nil
+	})
+	assert.Err(t, err)
+	assert.False(t, c.Has("key3"))
+
+	// the store fails partway through, the applied store writes are rolled back
+	s.fail = false
+	c.Set("key4", 4, 0)
+	s.failKey = "key6"
+	err = c.Update(func(tx *lcache.Tx) error {
+		tx.Set("key4", 40, 0)
+		tx.Set("key5", 5, 0)
+		tx.Set("key6", 6, 0)
+		tx.Set("key7", 7, 0)
+		return nil
+	})
+	assert.Err(t, err)
+	assert.Eq(t, 4, c.Val("key4"))
+	assert.False(t, c.Has("key5"))
+	assert.Eq(t, 4, s.data["key4"])
+	assert.Nil(t, s.data["key5"])
+	assert.Nil(t, s.data["key7"])
+}
@@ -0,0 +1,93 @@
+package lcache
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+// typeRegistry 已注册的值类型. see RegisterType
+var typeRegistry = struct {
+	sync.RWMutex
+	names     map[reflect.Type]string
+	restorers map[string]func(val any) (any, error)
+}{
+	names:     make(map[reflect.Type]string),
+	restorers: make(map[string]func(val any) (any, error)),
+}
+
+// RegisterType register the value type T with a unique name.
+//
+// SaveFile records the type name of the registered values in snapshot, then LoadFile
+// can reconstruct the concrete type instead of map[string]any, float64 from JSON.
+//
+// Usage:
+//
+//	lcache.RegisterType[User]("user")
+//	lcache.RegisterType[*User]("user_ptr")
+func RegisterType[T any](name string) {
+	typ := reflect.TypeFor[T]()
+
+	typeRegistry.Lock()
+	defer typeRegistry.Unlock()
+	typeRegistry.names[typ] = name
+	typeRegistry.restorers[name] = func(val any) (any, error) {
+		// 序列化器已还原为具体类型. eg: gob
+		if tv, ok := val.(T); ok {
+			return tv, nil
+		}
+
+		bs, err := json.Marshal(val)
+		if err != nil {
+			return nil, err
+		}
+
+		var tv T
+		err = json.Unmarshal(bs, &tv)
+		return tv, err
+	}
+}
+
+// TypeName get the registered name of the value type, returns empty string if not registered.
+// It is for the Store implementations to record the value type. see RestoreType
+func TypeName(val any) string { return typeName(val) }
+
+// RestoreType reconstruct the concrete type of the decoded value by the registered type name,
+// returns the value as is if the name is empty or not registered. see RegisterType
+func RestoreType(name string, val any) (any, error) {
+	it := &Item{Val: val, Typ: name}
+	err := restoreType(it)
+	return it.Val, err
+}
+
+// typeName 获取值类型的注册名称，未注册返回空字符串
+func typeName(val any) string {
+	if val == nil {
+		return ""
+	}
+
+	typeRegistry.RLock()
+	defer typeRegistry.RUnlock()
+	return typeRegistry.names[reflect.TypeOf(val)]
+}
+
+// restoreType 按记录的类型名称还原值的具体类型. 类型未注册时保持原值
+func restoreType(it *Item) error {
+	if it.Typ == "" {
+		return nil
+	}
+
+	typeRegistry.RLock()
+	fn, ok := typeRegistry.restorers[it.Typ]
+	typeRegistry.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	val, err := fn(it.Val)
+	if err != nil {
+		return err
+	}
+	it.Val = val
+	return nil
+}
@@ -0,0 +1,61 @@
+package lcache_test
+
+import (
+	"testing"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+type typedUser struct {
+	ID   int
+	Name string
+}
+
+func TestRegisterType(t *testing.T) {
+	lcache.RegisterType[typedUser]("user")
+	lcache.RegisterType[*typedUser]("user_ptr")
+	lcache.RegisterType[int]("int")
+
+	for _, name := range []string{"json", "lcbin"} {
+		t.Run(name, func(t *testing.T) {
+			c := lcache.New(lcache.WithSerializer(name))
+			c.Set("user", typedUser{ID: 1, Name: "inhere"}, 0)
+			c.Set("user_ptr", &typedUser{ID: 2, Name: "tom"}, 0)
+			c.Set("int", 23, 0)
+			c.Set("str", "abc", 0)
+
+			filename := t.TempDir() + "/cache.dat"
+			assert.NoErr(t, c.SaveFile(filename))
+
+			c2 := lcache.New(lcache.WithSerializer(name))
+			assert.NoErr(t, c2.LoadFile(filename))
+
+			u, ok := lcache.TypedInCache[typedUser](c2, "user")
+			assert.True(t, ok)
+			assert.Eq(t, "inhere", u.Name)
+
+			up, ok := lcache.TypedInCache[*typedUser](c2, "user_ptr")
+			assert.True(t, ok)
+			assert.Eq(t, 2, up.ID)
+
+			assert.Eq(t, 23, c2.Val("int"))
+			assert.Eq(t, "abc", c2.Val("str"))
+		})
+	}
+}
+
+func TestRestoreType(t *testing.T) {
+	lcache.RegisterType[typedUser]("user")
+	assert.Eq(t, "user", lcache.TypeName(typedUser{}))
+	assert.Eq(t, "", lcache.TypeName("abc"))
+	assert.Eq(t, "", lcache.TypeName(nil))
+
+	val, err := lcache.RestoreType("user", map[string]any{"ID": 1.0, "Name": "inhere"})
+	assert.NoErr(t, err)
+	assert.Eq(t, typedUser{ID: 1, Name: "inhere"}, val)
+
+	val, err = lcache.RestoreType("", "abc")
+	assert.NoErr(t, err)
+	assert.Eq(t, "abc", val)
+}
@@ -0,0 +1,233 @@
+package lcache
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WarmSource the source of the entries for WarmUp. see WarmFromSnapshot, WarmFromStore, WarmFromKeys
+type WarmSource interface {
+	// Total get the number of the entries, -1 if unknown
+	Total() int
+	// Range read the entries and call fn for each one, err is the error of loading the entry.
+	// It should stop when ctx is done. The returned error aborts the warm-up.
+	Range(ctx context.Context, fn func(key string, ent Entry, err error)) error
+}
+
+// Progress the progress of WarmUp
+type Progress struct {
+	// Total number of the entries in the source, -1 if unknown
+	Total int
+	// Loaded number of the entries written into the cache
+	Loaded int
+	// Skipped number of the entries skipped, eg: expired, not found or already in the cache
+	Skipped int
+	// Failed number of the entries failed to load
+	Failed int
+	// Done is true on the last progress, then the channel is closed
+	Done bool
+	// Err the summary of the errors on done, nil if no error
+	Err error
+}
+
+const (
+	// warmReportEvery 每处理多少条数据报告一次进度
+	warmReportEvery = 100
+	// warmMaxErrs 错误汇总中最多保留的错误数
+	warmMaxErrs = 10
+)
+
+// WarmUp load the entries from source into the cache in a background goroutine, the cache
+// serves the requests while warming. The existing valid items are kept, so the values written
+// during the warm-up are not overwritten. For a namespace view, the keys are loaded into it.
+//
+// The progress is reported on the returned channel, the last one has Done=true and the error
+// summary, then the channel is closed. A slow receiver does not block the warm-up, the
+// intermediate progress may be dropped. Cancel the ctx to stop the warm-up.
+//
+// Usage:
+//
+//	ch, err := c.WarmUp(ctx, lcache.WarmFromKeys(userIDs, loadUser))
+//	for p := range ch {
+//		log.Printf("warm up: %d/%d", p.Loaded, p.Total)
+//	}
+func (c *Cache) WarmUp(ctx context.Context, source WarmSource) (<-chan Progress, error) {
+	if c.frozen.Load() {
+		return nil, ErrFrozen
+	}
+
+	ch := make(chan Progress, 1)
+	go c.warmUp(ctx, source, ch)
+	return ch, nil
+}
+
+func (c *Cache) warmUp(ctx context.Context, source WarmSource, ch chan Progress) {
+	defer close(ch)
+
+	p := Progress{Total: source.Total()}
+	var errs []error
+	fail := func(key string, err error) {
+		p.Failed++
+		if len(errs) < warmMaxErrs {
+			errs = append(errs, fmt.Errorf("key %q: %w", key, err))
+		}
+	}
+
+	err := source.Range(ctx, func(key string, ent Entry, err error) {
+		if err == nil {
+			var ok bool
+			if ok, err = c.warmEntry(key, ent); ok {
+				p.Loaded++
+			} else if err == nil {
+				p.Skipped++
+			}
+		} else if errors.Is(err, ErrNotFound) {
+			p.Skipped++
+			err = nil
+		}
+		if err != nil {
+			fail(key, err)
+		}
+
+		if (p.Loaded+p.Skipped+p.Failed)%warmReportEvery == 0 {
+			sendProgress(ch, p)
+		}
+	})
+
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		err = fmt.Errorf("lcache: warm up: %w", err)
+	}
+	if p.Failed > 0 {
+		if p.Failed > warmMaxErrs {
+			errs = append(errs, fmt.Errorf("and %d more errors", p.Failed-warmMaxErrs))
+		}
+		err = errors.Join(err, fmt.Errorf("lcache: warm up %d entries failed: %w", p.Failed, errors.Join(errs...)))
+	}
+
+	p.Done, p.Err = true, err
+	sendProgress(ch, p)
+}
+
+// warmEntry 写入一条数据，已存在有效数据时跳过
+func (c *Cache) warmEntry(key string, ent Entry) (bool, error) {
+	if !c.lock() {
+		return false, ErrBusy
+	}
+	defer c.unlock()
+	if c.frozen.Load() {
+		return false, ErrFrozen
+	}
+	return c.importEntry(key, ent, false, time.Now().UnixMilli())
+}
+
+// sendProgress 发送进度，不阻塞. 丢弃接收方未读取的旧进度
+func sendProgress(ch chan Progress, p Progress) {
+	select {
+	case <-ch:
+	default:
+	}
+	// 只有一个发送方，清空后一定可以写入
+	ch <- p
+}
+
+//
+// ----- built-in warm sources -----
+//
+
+// WarmFromSnapshot warm up from a snapshot read by ReadSnapshot, the items are loaded in the
+// LRU order recorded in the snapshot.
+func WarmFromSnapshot(snap *Snapshot) WarmSource {
+	return snapshotSource{snap: &snapshot{Gen: snap.Gen, Items: snap.Items, Order: snap.Order}}
+}
+
+type snapshotSource struct {
+	snap *snapshot
+}
+
+func (s snapshotSource) Total() int { return len(s.snap.Items) }
+
+func (s snapshotSource) Range(ctx context.Context, fn func(key string, ent Entry, err error)) error {
+	for _, key := range s.snap.orderedKeys() {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// 复制一份，避免修改快照中的数据
+		it := *s.snap.Items[key]
+		if err := restoreType(&it); err != nil {
+			fn(key, Entry{}, fmt.Errorf("restore value type: %w", err))
+			continue
+		}
+
+		ent := Entry{Value: it.Val, Hits: it.Hits}
+		if it.Exp > 0 {
+			ent.ExpiresAt = time.UnixMilli(it.Exp)
+		}
+		fn(cmp.Or(it.Key, key), ent, nil)
+	}
+	return nil
+}
+
+// RangeStore a Store can iterate all the valid items. eg: *boltstore.Store. see WarmFromStore
+type RangeStore interface {
+	// Len get the number of the items
+	Len() int
+	// Range iterate the items, stop if fn returns false. exp is zero time for never expire
+	Range(fn func(key string, val any, exp time.Time) bool) error
+}
+
+// WarmFromStore warm up from all the items in the store, eg: warm up from the Store of the cache.
+func WarmFromStore(s RangeStore) WarmSource {
+	return storeSource{s: s}
+}
+
+type storeSource struct {
+	s RangeStore
+}
+
+func (s storeSource) Total() int { return s.s.Len() }
+
+func (s storeSource) Range(ctx context.Context, fn func(key string, ent Entry, err error)) error {
+	return s.s.Range(func(key string, val any, exp time.Time) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		fn(key, Entry{Value: val, ExpiresAt: exp}, nil)
+		return true
+	})
+}
+
+// WarmFromKeys warm up the keys by the loader, eg: load the hot keys from the database.
+// The keys are loaded one by one, ErrNotFound returned by the loader is counted as skipped.
+func WarmFromKeys(keys []string, loader ReadLoaderFn) WarmSource {
+	return keysSource{keys: keys, loader: loader}
+}
+
+type keysSource struct {
+	keys   []string
+	loader ReadLoaderFn
+}
+
+func (s keysSource) Total() int { return len(s.keys) }
+
+func (s keysSource) Range(ctx context.Context, fn func(key string, ent Entry, err error)) error {
+	for _, key := range s.keys {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		val, ttl, err := s.loader(ctx, key)
+		ent := Entry{Value: val}
+		if ttl > 0 {
+			ent.ExpiresAt = time.Now().Add(ttl)
+		}
+		fn(key, ent, err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package lcache_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+// lastProgress 读取所有进度，返回最后一个
+func lastProgress(t *testing.T, ch <-chan lcache.Progress) lcache.Progress {
+	var last lcache.Progress
+	for p := range ch {
+		assert.False(t, last.Done)
+		last = p
+	}
+	assert.True(t, last.Done)
+	return last
+}
+
+func TestCache_WarmUp_keys(t *testing.T) {
+	keys := make([]string, 0, 250)
+	for i := 0; i < 250; i++ {
+		keys = append(keys, "key"+strconv.Itoa(i))
+	}
+	keys = append(keys, "none", "bad")
+
+	c := lcache.New()
+	c.Set("key1", "fresh", 0)
+	ch, err := c.WarmUp(context.Background(), lcache.WarmFromKeys(keys, func(ctx context.Context, key string) (any, time.Duration, error) {
+		switch key {
+		case "none":
+			return nil, 0, lcache.ErrNotFound
+		case "bad":
+			return nil, 0, errors.New("db error")
+		}
+		return "val-" + key, time.Hour, nil
+	}))
+	assert.NoErr(t, err)
+
+	p := lastProgress(t, ch)
+	assert.Eq(t, 252, p.Total)
+	assert.Eq(t, 249, p.Loaded)
+	assert.Eq(t, 2, p.Skipped)
+	assert.Eq(t, 1, p.Failed)
+	assert.ErrMsg(t, p.Err, "lcache: warm up 1 entries failed: key \"bad\": db error")
+
+	// the existing item is kept
+	assert.Eq(t, "fresh", c.Val("key1"))
+	assert.Eq(t, "val-key2", c.Val("key2"))
+	ttl, ok := c.TTL("key2")
+	assert.True(t, ok)
+	assert.Gt(t, ttl, 50*time.Minute)
+}
+
+func TestCache_WarmUp_snapshot(t *testing.T) {
+	c := lcache.New(lcache.WithLRUMode(lcache.LRUExact))
+	c.Set("key1", "val1", 0)
+	c.Set("key2", "val2", time.Hour)
+	c.Namespace("users").Set("1", "tom", 0)
+	c.Get("key1")
+
+	filename := t.TempDir() + "/cache.json"
+	assert.NoErr(t, c.SaveFile(filename))
+	snap, err := lcache.ReadSnapshot(filename)
+	assert.NoErr(t, err)
+
+	c2 := lcache.New()
+	ch, err := c2.WarmUp(context.Background(), lcache.WarmFromSnapshot(snap))
+	assert.NoErr(t, err)
+	p := lastProgress(t, ch)
+	assert.Eq(t, 3, p.Loaded)
+	assert.NoErr(t, p.Err)
+
+	key, _ := c2.NewestKey()
+	assert.Eq(t, "key1", key)
+	assert.Eq(t, []lcache.KeyHits{{Key: "key1", Hits: 1}}, c2.HotKeys(3))
+	assert.Eq(t, "tom", c2.Namespace("users").Val("1"))
+
+	// frozen cache
+	c2.Freeze()
+	_, err = c2.WarmUp(context.Background(), lcache.WarmFromSnapshot(snap))
+	assert.ErrIs(t, err, lcache.ErrFrozen)
+}
+
+// mapStore a RangeStore for tests
+type mapStore map[string]any
+
+func (s mapStore) Len() int { return len(s) }
+
+func (s mapStore) Range(fn func(key string, val any, exp time.Time) bool) error {
+	for k, v := range s {
+		if !fn(k, v, time.Time{}) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func TestCache_WarmUp_cancel(t *testing.T) {
+	s := mapStore{}
+	for i := 0; i < 10; i++ {
+		s["key"+strconv.Itoa(i)] = i
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ch, err := lcache.New().WarmUp(ctx, lcache.WarmFromStore(s))
+	assert.NoErr(t, err)
+
+	p := lastProgress(t, ch)
+	assert.Eq(t, 10, p.Total)
+	assert.Eq(t, 0, p.Loaded)
+	assert.ErrIs(t, p.Err, context.Canceled)
+
+	c := lcache.New()
+	ch, err = c.WarmUp(context.Background(), lcache.WarmFromStore(s))
+	assert.NoErr(t, err)
+	assert.Eq(t, 10, lastProgress(t, ch).Loaded)
+	assert.Eq(t, 3, c.Val("key3"))
+}
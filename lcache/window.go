@@ -0,0 +1,94 @@
+package lcache
+
+import "time"
+
+// windowBuckets 滑动窗口计数器的分桶数量，计数精度为 window/windowBuckets
+const windowBuckets = 10
+
+// windowCounter 分桶的滑动窗口计数器. Counts 为环形数组，Last 为最新分桶的绝对序号(时间/分桶宽度)
+type windowCounter struct {
+	Width  int64   `json:"w"`
+	Last   int64   `json:"l"`
+	Counts []int64 `json:"c"`
+}
+
+func init() {
+	// 从快照加载时还原为计数器类型
+	RegisterType[*windowCounter]("lcache.window")
+}
+
+// add 在 nowUm 所在的分桶中记录一次事件，返回窗口内的事件总数
+func (w *windowCounter) add(nowUm int64) (total int64) {
+	idx := nowUm / w.Width
+	if idx > w.Last {
+		// 清空已滑出窗口的分桶
+		for i := w.Last + 1; i <= idx && i <= w.Last+windowBuckets; i++ {
+			w.Counts[i%windowBuckets] = 0
+		}
+		w.Last = idx
+	}
+
+	// 时钟回拨时计入最新的分桶
+	w.Counts[w.Last%windowBuckets]++
+	for _, n := range w.Counts {
+		total += n
+	}
+	return total
+}
+
+// CountEvent record an event of key and returns the number of events in the last window,
+// including this one. It is a sliding window counter, eg: for per-user rate limiting.
+//
+// The window is split into 10 buckets, so the count is approximate at the granularity of
+// window/10. The counter expires after a quiet window. Changing the window of a key resets
+// its counter. Returns 0 without recording if window <= 0, the key holds a value of another
+// type, the cache is frozen, or the lock cannot be acquired in time.
+//
+// Usage:
+//
+//	if c.CountEvent("login:"+userID, time.Minute) > 5 {
+//		return errTooManyAttempts
+//	}
+func (c *Cache) CountEvent(key string, window time.Duration) int64 {
+	width := window.Milliseconds() / windowBuckets
+	if width <= 0 {
+		return 0
+	}
+
+	key, err := c.normKey(key)
+	if err != nil || !c.lock() {
+		return 0
+	}
+	defer c.unlock()
+	if c.frozen.Load() {
+		return 0
+	}
+
+	nk := c.nsKey(key)
+	hk, it := c.find(nk)
+	nowUm := time.Now().UnixMilli()
+	exp := nowUm + window.Milliseconds()
+	if it != nil && !c.invalid(it, nowUm) {
+		wc, ok := it.Val.(*windowCounter)
+		if !ok {
+			return 0
+		}
+
+		if wc.Width == width {
+			total := wc.add(nowUm)
+			it.Exp = exp
+			c.pushExp(it)
+			c.touch(hk, it)
+			_ = c.appendSet(nk, wc, exp)
+			return total
+		}
+	}
+
+	wc := &windowCounter{Width: width, Last: nowUm / width, Counts: make([]int64, windowBuckets)}
+	total := wc.add(nowUm)
+	if c.set(nk, wc, exp) == nil {
+		return 0
+	}
+	_ = c.appendSet(nk, wc, exp)
+	return total
+}
@@ -0,0 +1,64 @@
+package lcache_test
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_CountEvent(t *testing.T) {
+	c := lcache.New()
+	window := 100 * time.Millisecond
+
+	for i := 1; i <= 5; i++ {
+		assert.Eq(t, int64(i), c.CountEvent("login:1", window))
+	}
+	assert.Eq(t, int64(1), c.CountEvent("login:2", window))
+
+	// the old events slide out of the window
+	time.Sleep(60 * time.Millisecond)
+	assert.Eq(t, int64(6), c.CountEvent("login:1", window))
+	time.Sleep(70 * time.Millisecond)
+	assert.Eq(t, int64(2), c.CountEvent("login:1", window))
+
+	// expires after a quiet window
+	time.Sleep(120 * time.Millisecond)
+	assert.False(t, c.Has("login:1"))
+	assert.Eq(t, int64(1), c.CountEvent("login:1", window))
+
+	// change the window resets the counter
+	assert.Eq(t, int64(1), c.CountEvent("login:1", time.Second))
+
+	// invalid
+	assert.Eq(t, int64(0), c.CountEvent("login:1", time.Millisecond))
+	c.Set("str", "abc", 0)
+	assert.Eq(t, int64(0), c.CountEvent("str", window))
+	assert.Eq(t, "abc", c.Val("str"))
+}
+
+func TestCache_CountEvent_concurrent(t *testing.T) {
+	c := lcache.New()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.CountEvent("api", time.Minute)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Eq(t, int64(1001), c.CountEvent("api", time.Minute))
+
+	// snapshot round-trip
+	file := filepath.Join(t.TempDir(), "window.json")
+	assert.NoErr(t, c.SaveFile(file))
+	nc := lcache.New()
+	assert.NoErr(t, nc.LoadFile(file))
+	assert.Eq(t, int64(1002), nc.CountEvent("api", time.Minute))
+}
@@ -0,0 +1,163 @@
+// Package workload generates configurable synthetic cache access streams,
+// for reproducible benchmarks of the cache policies and backends.
+//
+// Usage:
+//
+//	gen := workload.New(workload.Config{Keys: 10000, Skew: 1.1, ReadRatio: 0.9})
+//	for i := 0; i < b.N; i++ {
+//		op := gen.Next()
+//		switch op.Kind {
+//		case workload.OpGet:
+//			c.Get(op.Key)
+//		case workload.OpSet:
+//			c.Set(op.Key, op.Value, op.TTL)
+//		}
+//	}
+package workload
+
+import (
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+// OpKind type of the cache operation
+type OpKind uint8
+
+// Operation kinds
+const (
+	OpGet OpKind = iota
+	OpSet
+)
+
+// String get the name of OpKind
+func (k OpKind) String() string {
+	if k == OpSet {
+		return "set"
+	}
+	return "get"
+}
+
+// Op is a generated cache operation
+type Op struct {
+	Kind OpKind
+	Key  string
+	// Value for OpSet, nil for OpGet
+	Value []byte
+	// TTL for OpSet
+	TTL time.Duration
+}
+
+// Config for the workload generator
+type Config struct {
+	// Seed for the random source, same seed generates the same stream.
+	Seed int64
+	// Keys number of distinct keys(key cardinality). default is 1000
+	Keys int
+	// KeyPrefix prefix for generated keys. default is "key:"
+	KeyPrefix string
+	// Skew zipfian skew parameter, must be > 1 to enable zipfian distribution,
+	// larger value means more skewed. otherwise keys are uniformly distributed.
+	Skew float64
+	// ReadRatio ratio of OpGet in the stream, range [0, 1]. default is 0 (all OpSet)
+	ReadRatio float64
+	// MinValueSize, MaxValueSize value size range in bytes for OpSet. default is 64
+	MinValueSize, MaxValueSize int
+	// MinTTL, MaxTTL TTL range for OpSet, TTL is uniformly distributed in it.
+	// default is 0 - never expire.
+	MinTTL, MaxTTL time.Duration
+}
+
+// Generator generates synthetic access streams.
+//
+// NOTE: it is not goroutine-safe, create one generator for each goroutine with different Seed.
+type Generator struct {
+	cfg  Config
+	rnd  *rand.Rand
+	zipf *rand.Zipf
+	keys []string
+	// buf 共享的值数据，不同长度的值使用其不同长度的切片
+	buf []byte
+}
+
+// New create a workload generator
+func New(cfg Config) *Generator {
+	if cfg.Keys <= 0 {
+		cfg.Keys = 1000
+	}
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = "key:"
+	}
+	if cfg.MaxValueSize <= 0 {
+		cfg.MaxValueSize = 64
+	}
+	if cfg.MinValueSize <= 0 || cfg.MinValueSize > cfg.MaxValueSize {
+		cfg.MinValueSize = cfg.MaxValueSize
+	}
+	if cfg.MaxTTL < cfg.MinTTL {
+		cfg.MaxTTL = cfg.MinTTL
+	}
+
+	g := &Generator{
+		cfg:  cfg,
+		rnd:  rand.New(rand.NewSource(cfg.Seed)),
+		keys: make([]string, cfg.Keys),
+		buf:  make([]byte, cfg.MaxValueSize),
+	}
+
+	for i := range g.keys {
+		g.keys[i] = cfg.KeyPrefix + strconv.Itoa(i)
+	}
+	g.rnd.Read(g.buf)
+
+	if cfg.Skew > 1 {
+		g.zipf = rand.NewZipf(g.rnd, cfg.Skew, 1, uint64(cfg.Keys-1))
+	}
+	return g
+}
+
+// Keys get all keys of the workload
+func (g *Generator) Keys() []string { return g.keys }
+
+// Key generate next key by the distribution
+func (g *Generator) Key() string {
+	if g.zipf != nil {
+		return g.keys[g.zipf.Uint64()]
+	}
+	return g.keys[g.rnd.Intn(len(g.keys))]
+}
+
+// Next generate next operation
+func (g *Generator) Next() Op {
+	key := g.Key()
+	if g.cfg.ReadRatio > 0 && g.rnd.Float64() < g.cfg.ReadRatio {
+		return Op{Kind: OpGet, Key: key}
+	}
+
+	return Op{Kind: OpSet, Key: key, Value: g.value(), TTL: g.ttl()}
+}
+
+// Ops generate n operations. useful for pre-generate the stream before benchmark timer starts.
+func (g *Generator) Ops(n int) []Op {
+	ops := make([]Op, n)
+	for i := range ops {
+		ops[i] = g.Next()
+	}
+	return ops
+}
+
+func (g *Generator) value() []byte {
+	size := g.cfg.MinValueSize
+	if n := g.cfg.MaxValueSize - g.cfg.MinValueSize; n > 0 {
+		size += g.rnd.Intn(n + 1)
+	}
+	return g.buf[:size]
+}
+
+func (g *Generator) ttl() time.Duration {
+	ttl := g.cfg.MinTTL
+	if n := int64(g.cfg.MaxTTL - g.cfg.MinTTL); n > 0 {
+		ttl += time.Duration(g.rnd.Int63n(n + 1))
+	}
+	return ttl
+}
@@ -0,0 +1,45 @@
+package workload_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache/workload"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestGenerator(t *testing.T) {
+	cfg := workload.Config{
+		Seed:         1,
+		Keys:         100,
+		Skew:         1.2,
+		ReadRatio:    0.8,
+		MinValueSize: 10,
+		MaxValueSize: 20,
+		MinTTL:       time.Second,
+		MaxTTL:       time.Minute,
+	}
+	ops := workload.New(cfg).Ops(1000)
+
+	// same seed generates the same stream
+	assert.Eq(t, ops, workload.New(cfg).Ops(1000))
+
+	var gets int
+	counts := make(map[string]int)
+	for _, op := range ops {
+		counts[op.Key]++
+		if op.Kind == workload.OpGet {
+			gets++
+			assert.Nil(t, op.Value)
+			continue
+		}
+
+		assert.Eq(t, "set", op.Kind.String())
+		assert.True(t, len(op.Value) >= 10 && len(op.Value) <= 20)
+		assert.True(t, op.TTL >= time.Second && op.TTL <= time.Minute)
+	}
+
+	assert.True(t, gets > 700 && gets < 900)
+	// zipfian: the first key is the hottest
+	assert.True(t, counts["key:0"] > counts["key:50"])
+}
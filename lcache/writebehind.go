@@ -0,0 +1,139 @@
+package lcache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// wbEntry write-behind 队列中待写入 Store 的数据. del 为 true 表示删除
+type wbEntry struct {
+	del bool
+	val any
+	// 绝对过期时间(毫秒)，0 表示永不过期. 刷新时再换算为剩余的 ttl
+	exp int64
+}
+
+// newWBEntry 创建待写入的数据，将 ttl 转换为绝对过期时间
+func newWBEntry(val any, ttl time.Duration) *wbEntry {
+	e := &wbEntry{val: val}
+	if ttl > 0 {
+		e.exp = time.Now().Add(ttl).UnixMilli()
+	}
+	return e
+}
+
+// expired 数据在写入 Store 之前是否已经过期
+func (e *wbEntry) expired(nowUm int64) bool {
+	return !e.del && e.exp > 0 && e.exp <= nowUm
+}
+
+// Flush write the queued dirty items to the Store in write-behind mode. see WithWriteBehind
+//
+// The items being written stay visible to the read-through loads until their writes
+// complete, and each item is saved with its remaining TTL. An item that expired
+// while queued is deleted from the Store instead.
+//
+// Returns the joined errors of the failed items, each error is also passed to the
+// OnStoreErr callback. The failed items are not re-queued.
+func (c *Cache) Flush() error {
+	// 串行执行刷新，避免同一个 key 的新旧数据乱序写入
+	c.wbFlushMu.Lock()
+	defer c.wbFlushMu.Unlock()
+
+	// 队列移入 wbFlight，写入完成前 storeLoad 仍可读到这些数据
+	c.wbMu.Lock()
+	queue := c.wbQueue
+	c.wbQueue = nil
+	c.wbFlight = queue
+	c.wbMu.Unlock()
+
+	if len(queue) == 0 {
+		return nil
+	}
+
+	var errs []error
+	ctx := context.Background()
+	for key, e := range queue {
+		var err error
+		if nowUm := time.Now().UnixMilli(); e.del || e.expired(nowUm) {
+			if err = c.opt.Store.Delete(ctx, key); errors.Is(err, ErrNotFound) {
+				err = nil
+			}
+		} else {
+			var ttl time.Duration
+			if e.exp > 0 {
+				ttl = time.Duration(e.exp-nowUm) * time.Millisecond
+			}
+			err = c.opt.Store.Save(ctx, key, e.val, ttl)
+		}
+
+		c.wbMu.Lock()
+		delete(c.wbFlight, key)
+		c.wbMu.Unlock()
+
+		if err != nil {
+			errs = append(errs, err)
+			if c.opt.OnStoreErr != nil {
+				c.opt.OnStoreErr(key, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isWriteBehind 是否为 write-behind 模式
+func (c *Cache) isWriteBehind() bool {
+	return c.opt.Store != nil && c.opt.WriteBehindInterval > 0
+}
+
+// enqueueWrite 加入 write-behind 队列，队列长度达到阈值时在后台刷新. key 为实际存储的 key
+func (c *Cache) enqueueWrite(key string, e *wbEntry) {
+	c.wbMu.Lock()
+	if c.wbQueue == nil {
+		c.wbQueue = make(map[string]*wbEntry)
+	}
+	c.wbQueue[key] = e
+	full := c.opt.WriteBehindMaxQueue > 0 && len(c.wbQueue) >= c.opt.WriteBehindMaxQueue
+	c.wbMu.Unlock()
+
+	if full && c.wbFlushing.CompareAndSwap(false, true) {
+		go func() {
+			defer c.wbFlushing.Store(false)
+			_ = c.Flush()
+		}()
+	}
+}
+
+// queuedWrite 获取 write-behind 队列中和正在刷新中尚未写入 Store 的数据.
+// 队列中的数据比刷新中的新，优先返回. key 为实际存储的 key
+func (c *Cache) queuedWrite(key string) (*wbEntry, bool) {
+	c.wbMu.Lock()
+	defer c.wbMu.Unlock()
+	if e, ok := c.wbQueue[key]; ok {
+		return e, true
+	}
+	e, ok := c.wbFlight[key]
+	return e, ok
+}
+
+// startWriteBehind 根据配置(重新)启动定时刷新任务
+func (c *Cache) startWriteBehind() {
+	c.stopWriteBehind()
+	if !c.isWriteBehind() {
+		return
+	}
+
+	c.wbStop = c.scheduler().Every(c.opt.WriteBehindInterval, func() {
+		// 错误通过 OnStoreErr 回调处理
+		_ = c.Flush()
+	})
+}
+
+// stopWriteBehind 停止定时刷新任务
+func (c *Cache) stopWriteBehind() {
+	if c.wbStop != nil {
+		c.wbStop()
+		c.wbStop = nil
+	}
+}
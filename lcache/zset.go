@@ -0,0 +1,201 @@
+package lcache
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// ZMember a member of the sorted set with its score. see Cache.ZAdd
+type ZMember struct {
+	Member string  `json:"m"`
+	Score  float64 `json:"s"`
+}
+
+// sortedSet 有序集合的值，按分数升序排列，分数相同时按成员升序. 写入时复制
+type sortedSet []ZMember
+
+func init() {
+	// 从快照加载时还原为有序集合类型
+	RegisterType[sortedSet]("lcache.zset")
+}
+
+// ZAdd add the members to the sorted set stored at key, or update the scores of the existing
+// members, like Redis ZADD. Returns the number of the added members, not including the updated.
+//
+// maxLen > 0 bounds the size of the set, the members with the lowest scores are removed
+// when exceeded. eg: keep the top 100 of a leaderboard. Creates the set(never expire) if
+// the key does not exist or expired, use Touch to set its TTL.
+//
+// The set is stored as a sorted slice, copied on write under the cache lock. The sorted set
+// operations are local only, not written to the Store. Returns ErrNotZSet if the value of
+// key is not a sorted set, ErrBusy, ErrFrozen or ErrCacheFull, and ErrTooLarge(*SizeError)
+// if the set exceeds WithMaxValueBytes after the add.
+func (c *Cache) ZAdd(key string, maxLen int, members ...ZMember) (int, error) {
+	key, err := c.normKey(key)
+	if err != nil {
+		return 0, err
+	}
+	if !c.lock() {
+		return 0, ErrBusy
+	}
+	defer c.unlock()
+	if c.frozen.Load() {
+		return 0, ErrFrozen
+	}
+
+	nk := c.nsKey(key)
+	hk, it := c.find(nk)
+	old, ok := c.validZSet(it)
+	if !ok && it != nil && !c.invalid(it, time.Now().UnixMilli()) {
+		return 0, ErrNotZSet
+	}
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	scores := make(map[string]float64, len(old)+len(members))
+	for _, m := range old {
+		scores[m.Member] = m.Score
+	}
+
+	var n int
+	for _, m := range members {
+		if _, exists := scores[m.Member]; !exists {
+			n++
+		}
+		scores[m.Member] = m.Score
+	}
+
+	zs := make(sortedSet, 0, len(scores))
+	for member, score := range scores {
+		zs = append(zs, ZMember{Member: member, Score: score})
+	}
+	slices.SortFunc(zs, func(a, b ZMember) int {
+		return cmp.Or(cmp.Compare(a.Score, b.Score), cmp.Compare(a.Member, b.Member))
+	})
+	if maxLen > 0 && len(zs) > maxLen {
+		zs = zs[len(zs)-maxLen:]
+	}
+
+	if err = c.checkSize(key, zs); err != nil {
+		return 0, err
+	}
+	if !ok {
+		if c.set(nk, zs, 0) == nil {
+			return 0, ErrCacheFull
+		}
+		return n, c.appendSet(nk, zs, 0)
+	}
+
+	it.Val = zs
+	c.updateIndexes(hk, it)
+	c.touch(hk, it)
+	return n, c.appendSet(nk, zs, it.Exp)
+}
+
+// ZTopN get the n members with the highest scores in descending order, n <= 0 for all.
+// returns nil if the sorted set is missing.
+func (c *Cache) ZTopN(key string, n int) []ZMember {
+	key, err := c.normKey(key)
+	if err != nil || !c.lockRead() {
+		return nil
+	}
+	defer c.unlockRead()
+
+	hk, it := c.find(c.nsKey(key))
+	zs, ok := c.validZSet(it)
+	if !ok {
+		return nil
+	}
+	c.touch(hk, it)
+
+	if n <= 0 || n > len(zs) {
+		n = len(zs)
+	}
+	top := make([]ZMember, n)
+	for i := range top {
+		top[i] = zs[len(zs)-1-i]
+	}
+	return top
+}
+
+// ZScore get the score of the member in the sorted set stored at key.
+func (c *Cache) ZScore(key, member string) (float64, bool) {
+	key, err := c.normKey(key)
+	if err != nil || !c.rlock() {
+		return 0, false
+	}
+	defer c.mu.RUnlock()
+
+	_, it := c.find(c.nsKey(key))
+	zs, _ := c.validZSet(it)
+	for _, m := range zs {
+		if m.Member == member {
+			return m.Score, true
+		}
+	}
+	return 0, false
+}
+
+// ZRem remove the members from the sorted set stored at key, returns the number of the
+// removed members. The key is deleted when the set becomes empty.
+func (c *Cache) ZRem(key string, members ...string) int {
+	key, err := c.normKey(key)
+	if err != nil || !c.lock() {
+		return 0
+	}
+	defer c.unlock()
+	if c.frozen.Load() {
+		return 0
+	}
+
+	nk := c.nsKey(key)
+	hk, it := c.find(nk)
+	old, ok := c.validZSet(it)
+	if !ok {
+		return 0
+	}
+
+	zs := slices.DeleteFunc(slices.Clone(old), func(m ZMember) bool {
+		return slices.Contains(members, m.Member)
+	})
+	n := len(old) - len(zs)
+	if n == 0 {
+		return 0
+	}
+	if len(zs) == 0 {
+		c.removeElement(hk, ReasonDeleted)
+		_ = c.appendAOF(aofOpDel, nk, nil, 0)
+		return n
+	}
+
+	it.Val = zs
+	c.updateIndexes(hk, it)
+	c.touch(hk, it)
+	_ = c.appendSet(nk, zs, it.Exp)
+	return n
+}
+
+// ZCard get the number of members of the sorted set stored at key, 0 if it is missing.
+func (c *Cache) ZCard(key string) int {
+	key, err := c.normKey(key)
+	if err != nil || !c.rlock() {
+		return 0
+	}
+	defer c.mu.RUnlock()
+
+	_, it := c.find(c.nsKey(key))
+	zs, _ := c.validZSet(it)
+	return len(zs)
+}
+
+// validZSet 获取有效数据项中的有序集合 (不加锁)
+func (c *Cache) validZSet(it *Item) (sortedSet, bool) {
+	if it == nil || c.invalid(it, time.Now().UnixMilli()) {
+		return nil, false
+	}
+
+	zs, ok := it.Val.(sortedSet)
+	return zs, ok
+}
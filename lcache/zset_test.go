@@ -0,0 +1,72 @@
+package lcache_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache_ZSetOps(t *testing.T) {
+	c := lcache.New()
+
+	n, err := c.ZAdd("board", 0,
+		lcache.ZMember{Member: "tom", Score: 10},
+		lcache.ZMember{Member: "jack", Score: 30},
+		lcache.ZMember{Member: "lucy", Score: 20},
+	)
+	assert.NoErr(t, err)
+	assert.Eq(t, 3, n)
+
+	// update score
+	n, err = c.ZAdd("board", 0, lcache.ZMember{Member: "tom", Score: 40}, lcache.ZMember{Member: "bob", Score: 5})
+	assert.NoErr(t, err)
+	assert.Eq(t, 1, n)
+	assert.Eq(t, 4, c.ZCard("board"))
+
+	assert.Eq(t, []lcache.ZMember{{"tom", 40}, {"jack", 30}}, c.ZTopN("board", 2))
+	assert.Len(t, c.ZTopN("board", 0), 4)
+	assert.Len(t, c.ZTopN("board", 10), 4)
+	assert.Nil(t, c.ZTopN("not-exists", 2))
+
+	score, ok := c.ZScore("board", "lucy")
+	assert.True(t, ok)
+	assert.Eq(t, float64(20), score)
+	_, ok = c.ZScore("board", "none")
+	assert.False(t, ok)
+
+	assert.Eq(t, 2, c.ZRem("board", "bob", "lucy", "none"))
+	assert.Eq(t, []lcache.ZMember{{"tom", 40}, {"jack", 30}}, c.ZTopN("board", 0))
+	assert.Eq(t, 2, c.ZRem("board", "tom", "jack"))
+	assert.False(t, c.Has("board"))
+
+	// not a sorted set
+	c.Set("str", "abc", 0)
+	_, err = c.ZAdd("str", 0, lcache.ZMember{Member: "a"})
+	assert.True(t, errors.Is(err, lcache.ErrNotZSet))
+	assert.Eq(t, 0, c.ZCard("str"))
+}
+
+func TestCache_ZSetOps_maxLen(t *testing.T) {
+	c := lcache.New()
+	for i, name := range []string{"a", "b", "c", "d", "e"} {
+		_, err := c.ZAdd("top3", 3, lcache.ZMember{Member: name, Score: float64(i)})
+		assert.NoErr(t, err)
+	}
+	assert.Eq(t, []lcache.ZMember{{"e", 4}, {"d", 3}, {"c", 2}}, c.ZTopN("top3", 0))
+
+	// lower than the lowest, not added
+	_, _ = c.ZAdd("top3", 3, lcache.ZMember{Member: "f", Score: 1})
+	assert.Eq(t, 3, c.ZCard("top3"))
+	_, ok := c.ZScore("top3", "f")
+	assert.False(t, ok)
+
+	// snapshot round-trip
+	file := filepath.Join(t.TempDir(), "zset.json")
+	assert.NoErr(t, c.SaveFile(file))
+	nc := lcache.New()
+	assert.NoErr(t, nc.LoadFile(file))
+	assert.Eq(t, []lcache.ZMember{{"e", 4}}, nc.ZTopN("top3", 1))
+}
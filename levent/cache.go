@@ -0,0 +1,51 @@
+package levent
+
+import (
+	"time"
+
+	"github.com/gookit/ext/lcache"
+)
+
+const (
+	// TopicCacheSet the topic suffix of the cache item written event
+	TopicCacheSet = "set"
+	// TopicCacheRemoved the topic suffix of the cache item removed event
+	TopicCacheRemoved = "removed"
+)
+
+// CacheEvent the event data of the lcache hooks. see CacheHooks
+type CacheEvent struct {
+	// Key the key of the item, contains the namespace prefix
+	Key   string
+	Value any
+	// TTL of the written item, only for the set event
+	TTL time.Duration
+	// Reason of the removal, only for the removed event
+	Reason lcache.RemoveReason
+}
+
+// CacheHooks get the lcache options to publish the cache events to the bus, with the
+// topics prefix+TopicCacheSet and prefix+TopicCacheRemoved. The events are published
+// synchronously.
+//
+// NOTE: it replaces the OnSet and OnRemoved callbacks of the cache. The removed event is
+// published while holding the cache lock, use lcache.WithAsyncCallbacks if the listeners
+// call the cache methods.
+//
+// Usage:
+//
+//	c := lcache.New(levent.CacheHooks(levent.Std(), "users.")...)
+//	levent.Subscribe(levent.Std(), "users.removed", func(topic string, e *levent.CacheEvent) {
+//		log.Println("removed", e.Key, e.Reason)
+//	})
+func CacheHooks(b *Bus, prefix string) []lcache.OptionFn {
+	setTopic, removedTopic := prefix+TopicCacheSet, prefix+TopicCacheRemoved
+	return []lcache.OptionFn{
+		lcache.WithOnSetFn(func(key string, value any, ttl time.Duration) {
+			b.Publish(setTopic, &CacheEvent{Key: key, Value: value, TTL: ttl})
+		}),
+		lcache.WithOnRemovedFn(func(key string, value any, reason lcache.RemoveReason) {
+			b.Publish(removedTopic, &CacheEvent{Key: key, Value: value, Reason: reason})
+		}),
+	}
+}
@@ -0,0 +1,246 @@
+// Package levent provides a lightweight in-process event bus, with typed subscription,
+// sync/async dispatch, wildcard topics and once-listeners.
+//
+// Usage:
+//
+//	bus := levent.New()
+//	cancel := levent.Subscribe(bus, "user.*", func(topic string, u *User) {
+//		fmt.Println(topic, u.Name)
+//	})
+//	defer cancel()
+//
+//	bus.Publish("user.created", user)
+//	bus.PublishAsync("user.deleted", user)
+//
+// The topic pattern supports the glob syntax of lcache.KeysMatch: '*' matches any
+// sequence of characters, '?' matches any single character.
+package levent
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gookit/ext/lcache"
+)
+
+// std 默认的全局事件总线
+var std = New()
+
+// Std get the default event bus, shared in the application
+func Std() *Bus { return std }
+
+// Publish an event to the default bus synchronously. see Bus.Publish
+func Publish(topic string, data any) int { return std.Publish(topic, data) }
+
+// PublishAsync an event to the default bus asynchronously. see Bus.PublishAsync
+func PublishAsync(topic string, data any) { std.PublishAsync(topic, data) }
+
+// Options for the event bus
+type Options struct {
+	// Executor run the async dispatch tasks, nil to start a goroutine for each event.
+	// eg: *lpool.Pool
+	Executor lcache.Executor
+	// PanicHandler handle the listener panic on async dispatch. If nil, the panic is not recovered.
+	PanicHandler func(topic string, val any)
+}
+
+// OptionFn option func for New
+type OptionFn func(o *Options)
+
+// WithExecutor set the executor to run the async dispatch tasks
+func WithExecutor(e lcache.Executor) OptionFn {
+	return func(o *Options) { o.Executor = e }
+}
+
+// WithPanicHandler set the handler of the listener panic on async dispatch
+func WithPanicHandler(fn func(topic string, val any)) OptionFn {
+	return func(o *Options) { o.PanicHandler = fn }
+}
+
+// listener 事件监听器
+type listener struct {
+	id      uint64
+	pattern string
+	// call 数据类型匹配时调用处理函数并返回 true
+	call func(topic string, data any) bool
+	once bool
+	// fired once 监听器是否已触发
+	fired atomic.Bool
+}
+
+// Bus an in-process event bus, it is goroutine-safe.
+type Bus struct {
+	opt Options
+	mu  sync.RWMutex
+	// 按 topic 精确匹配的监听器 和 使用通配符的监听器，均按订阅顺序排列
+	exact map[string][]*listener
+	wild  []*listener
+	seq   uint64
+	// 进行中的异步分发. see Wait
+	wg sync.WaitGroup
+}
+
+// New create an event bus
+func New(optFns ...OptionFn) *Bus {
+	b := &Bus{exact: make(map[string][]*listener)}
+	for _, fn := range optFns {
+		fn(&b.opt)
+	}
+	return b
+}
+
+// Subscribe the events of the topic pattern, the fn is called only when the event data
+// is of type T(or nil for an interface T). Use T as any to receive all events.
+// Returns the func to unsubscribe.
+func Subscribe[T any](b *Bus, pattern string, fn func(topic string, data T)) (cancel func()) {
+	return b.subscribe(pattern, false, typed(fn))
+}
+
+// Once subscribe the events of the topic pattern, the fn is called at most once for the
+// first event with data of type T, then unsubscribed automatically.
+func Once[T any](b *Bus, pattern string, fn func(topic string, data T)) (cancel func()) {
+	return b.subscribe(pattern, true, typed(fn))
+}
+
+// typed 包装处理函数，只处理类型为 T 的数据. T 为接口类型时也处理 nil 数据
+func typed[T any](fn func(topic string, data T)) func(topic string, data any) bool {
+	isIface := reflect.TypeFor[T]().Kind() == reflect.Interface
+	return func(topic string, data any) bool {
+		val, ok := data.(T)
+		if ok || data == nil && isIface {
+			fn(topic, val)
+			return true
+		}
+		return false
+	}
+}
+
+func (b *Bus) subscribe(pattern string, once bool, call func(topic string, data any) bool) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	l := &listener{id: b.seq, pattern: pattern, call: call, once: once}
+	if isWildcard(pattern) {
+		b.wild = append(b.wild, l)
+	} else {
+		b.exact[pattern] = append(b.exact[pattern], l)
+	}
+	return func() { b.remove(l) }
+}
+
+// remove 删除监听器
+func (b *Bus) remove(l *listener) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	del := func(ls []*listener) []*listener {
+		for i, v := range ls {
+			if v == l {
+				// 复制后删除，不影响正在分发的监听器列表
+				return append(ls[:i:i], ls[i+1:]...)
+			}
+		}
+		return ls
+	}
+
+	if isWildcard(l.pattern) {
+		b.wild = del(b.wild)
+	} else if ls := del(b.exact[l.pattern]); len(ls) > 0 {
+		b.exact[l.pattern] = ls
+	} else {
+		delete(b.exact, l.pattern)
+	}
+}
+
+// Publish an event synchronously, the matched listeners are called in the order of
+// subscription in the current goroutine. Returns the number of the called listeners.
+func (b *Bus) Publish(topic string, data any) int {
+	var n int
+	for _, l := range b.listeners(topic) {
+		if l.once {
+			// 类型匹配后才算触发，先标记避免并发发布时重复调用
+			if !l.fired.CompareAndSwap(false, true) {
+				continue
+			}
+			if !l.call(topic, data) {
+				l.fired.Store(false)
+				continue
+			}
+			b.remove(l)
+			n++
+		} else if l.call(topic, data) {
+			n++
+		}
+	}
+	return n
+}
+
+// PublishAsync publish an event in the background by the executor, or a new goroutine.
+// The listeners of one event are called in the order of subscription, the order between
+// events is not guaranteed. The event is dropped if the executor rejects it.
+func (b *Bus) PublishAsync(topic string, data any) {
+	b.wg.Add(1)
+	task := func() {
+		defer b.wg.Done()
+		if b.opt.PanicHandler != nil {
+			defer func() {
+				if val := recover(); val != nil {
+					b.opt.PanicHandler(topic, val)
+				}
+			}()
+		}
+		b.Publish(topic, data)
+	}
+
+	if b.opt.Executor == nil {
+		go task()
+	} else if err := b.opt.Executor.Submit(task); err != nil {
+		b.wg.Done()
+	}
+}
+
+// Wait for the async dispatched events done
+func (b *Bus) Wait() { b.wg.Wait() }
+
+// HasListeners check if there are listeners for the topic
+func (b *Bus) HasListeners(topic string) bool { return len(b.listeners(topic)) > 0 }
+
+// listeners 获取匹配 topic 的监听器，按订阅顺序排列
+func (b *Bus) listeners(topic string) []*listener {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	exact := b.exact[topic]
+	var matched []*listener
+	for _, l := range b.wild {
+		if lcache.GlobMatch(l.pattern, topic) {
+			matched = append(matched, l)
+		}
+	}
+	if len(matched) == 0 {
+		return exact
+	}
+
+	// 合并两个按订阅序号排列的列表
+	ls := make([]*listener, 0, len(exact)+len(matched))
+	i, j := 0, 0
+	for i < len(exact) && j < len(matched) {
+		if exact[i].id < matched[j].id {
+			ls = append(ls, exact[i])
+			i++
+		} else {
+			ls = append(ls, matched[j])
+			j++
+		}
+	}
+	ls = append(ls, exact[i:]...)
+	return append(ls, matched[j:]...)
+}
+
+// isWildcard 检查 topic 模式是否包含通配符
+func isWildcard(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?\`)
+}
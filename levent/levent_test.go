@@ -0,0 +1,138 @@
+package levent_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/levent"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+type user struct{ Name string }
+
+func TestBus_Subscribe(t *testing.T) {
+	b := levent.New()
+	var got []string
+	levent.Subscribe(b, "user.created", func(topic string, u *user) {
+		got = append(got, "exact:"+u.Name)
+	})
+	cancel := levent.Subscribe(b, "user.*", func(topic string, data any) {
+		got = append(got, "wild:"+topic)
+	})
+	levent.Subscribe(b, "user.created", func(topic string, n int) {
+		got = append(got, "int")
+	})
+
+	// called in the order of subscription, only for the matched data type
+	assert.Eq(t, 2, b.Publish("user.created", &user{Name: "inhere"}))
+	assert.Eq(t, []string{"exact:inhere", "wild:user.created"}, got)
+
+	got = nil
+	assert.Eq(t, 2, b.Publish("user.created", 1))
+	assert.Eq(t, []string{"wild:user.created", "int"}, got)
+
+	// unsubscribe
+	cancel()
+	got = nil
+	assert.Eq(t, 0, b.Publish("user.deleted", &user{}))
+	assert.True(t, b.HasListeners("user.created"))
+	assert.False(t, b.HasListeners("user.deleted"))
+	assert.Empty(t, got)
+}
+
+func TestBus_Once(t *testing.T) {
+	b := levent.New()
+	var calls atomic.Int32
+	levent.Once(b, "ready", func(topic string, s string) {
+		calls.Add(1)
+	})
+
+	// type not matched, not fired
+	assert.Eq(t, 0, b.Publish("ready", 1))
+	assert.True(t, b.HasListeners("ready"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.Publish("ready", "ok")
+		}()
+	}
+	wg.Wait()
+	assert.Eq(t, int32(1), calls.Load())
+	assert.False(t, b.HasListeners("ready"))
+}
+
+// rejectExecutor reject all tasks
+type rejectExecutor struct{}
+
+func (rejectExecutor) Submit(func()) error { return errors.New("rejected") }
+
+func TestBus_PublishAsync(t *testing.T) {
+	var recovered atomic.Value
+	b := levent.New(levent.WithPanicHandler(func(topic string, val any) {
+		recovered.Store(topic)
+	}))
+
+	var sum atomic.Int64
+	levent.Subscribe(b, "num", func(topic string, n int) {
+		sum.Add(int64(n))
+	})
+	levent.Subscribe(b, "bad", func(topic string, data any) {
+		panic("oops")
+	})
+
+	for i := 1; i <= 10; i++ {
+		b.PublishAsync("num", i)
+	}
+	b.PublishAsync("bad", nil)
+	b.Wait()
+	assert.Eq(t, int64(55), sum.Load())
+	assert.Eq(t, "bad", recovered.Load())
+
+	// rejected by the executor, dropped
+	b = levent.New(levent.WithExecutor(rejectExecutor{}))
+	levent.Subscribe(b, "num", func(topic string, n int) {
+		sum.Add(int64(n))
+	})
+	b.PublishAsync("num", 1)
+	b.Wait()
+	assert.Eq(t, int64(55), sum.Load())
+}
+
+func TestStd(t *testing.T) {
+	var got string
+	cancel := levent.Subscribe(levent.Std(), "app.start", func(topic string, s string) {
+		got = s
+	})
+	defer cancel()
+
+	assert.Eq(t, 1, levent.Publish("app.start", "v1"))
+	assert.Eq(t, "v1", got)
+	levent.PublishAsync("app.start", "v2")
+	levent.Std().Wait()
+	assert.Eq(t, "v2", got)
+}
+
+func TestCacheHooks(t *testing.T) {
+	b := levent.New()
+	var events []string
+	levent.Subscribe(b, "users.*", func(topic string, e *levent.CacheEvent) {
+		events = append(events, topic+":"+e.Key)
+		if topic == "users.removed" {
+			assert.Eq(t, lcache.ReasonDeleted, e.Reason)
+		} else {
+			assert.Eq(t, time.Minute, e.TTL)
+		}
+	})
+
+	c := lcache.New(levent.CacheHooks(b, "users.")...)
+	c.Set("u1", "inhere", time.Minute)
+	c.Delete("u1")
+	assert.Eq(t, []string{"users.set:u1", "users.removed:u1"}, events)
+}
@@ -0,0 +1,243 @@
+package llock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Options for the Keyed
+type Options struct {
+	// TTL auto-release the lock held longer than it, eg: the holder crashed or forgot
+	// to unlock. 0 to disable.
+	TTL time.Duration
+	// OnExpired callback on a lock is auto-released by the TTL
+	OnExpired func(key string)
+}
+
+// OptionFn option func for New
+type OptionFn func(o *Options)
+
+// WithTTL set the TTL to auto-release the locks
+func WithTTL(ttl time.Duration) OptionFn {
+	return func(o *Options) { o.TTL = ttl }
+}
+
+// WithOnExpired set the callback on a lock is auto-released by the TTL
+func WithOnExpired(fn func(key string)) OptionFn {
+	return func(o *Options) { o.OnExpired = fn }
+}
+
+// keyLock 单个 key 的读写锁状态
+type keyLock struct {
+	writer bool
+	// holders 持有锁的 token, 值为 TTL 自动释放的定时器
+	holders map[uint64]*time.Timer
+	// waitWriters 等待写锁的数量. 有等待的写锁时不再获取读锁，避免写锁饥饿
+	waitWriters int
+	waiters     int
+	// wake 状态变更时关闭，唤醒所有等待者
+	wake chan struct{}
+}
+
+// Keyed the exact per-key RWMutexes. The lock of a key is created on demand and
+// removed when it is free. Waiting writers block the new readers.
+//
+// The unlock funcs are idempotent, calling it after the lock is auto-released by
+// the TTL is a no-op.
+type Keyed struct {
+	opt   Options
+	mu    sync.Mutex
+	locks map[string]*keyLock
+	seq   uint64
+}
+
+// New create a Keyed
+func New(optFns ...OptionFn) *Keyed {
+	k := &Keyed{locks: make(map[string]*keyLock)}
+	for _, fn := range optFns {
+		fn(&k.opt)
+	}
+	return k
+}
+
+// Lock the key for writing, returns the unlock func.
+func (k *Keyed) Lock(key string) (unlock func()) {
+	unlock, _ = k.acquire(context.Background(), key, true, true)
+	return unlock
+}
+
+// LockCtx lock the key for writing, returns ctx.Err() if ctx is done before locked.
+func (k *Keyed) LockCtx(ctx context.Context, key string) (unlock func(), err error) {
+	return k.acquire(ctx, key, true, true)
+}
+
+// TryLock try to lock the key for writing without blocking.
+func (k *Keyed) TryLock(key string) (unlock func(), ok bool) {
+	unlock, err := k.acquire(context.Background(), key, true, false)
+	return unlock, err == nil
+}
+
+// RLock the key for reading, returns the unlock func.
+func (k *Keyed) RLock(key string) (unlock func()) {
+	unlock, _ = k.acquire(context.Background(), key, false, true)
+	return unlock
+}
+
+// RLockCtx lock the key for reading, returns ctx.Err() if ctx is done before locked.
+func (k *Keyed) RLockCtx(ctx context.Context, key string) (unlock func(), err error) {
+	return k.acquire(ctx, key, false, true)
+}
+
+// TryRLock try to lock the key for reading without blocking.
+func (k *Keyed) TryRLock(key string) (unlock func(), ok bool) {
+	unlock, err := k.acquire(context.Background(), key, false, false)
+	return unlock, err == nil
+}
+
+// IsLocked check the key is locked for writing or reading
+func (k *Keyed) IsLocked(key string) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	l, ok := k.locks[key]
+	return ok && len(l.holders) > 0
+}
+
+// Len get the number of the locked or waiting keys
+func (k *Keyed) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.locks)
+}
+
+// errWouldBlock TryLock 获取锁失败
+var errWouldBlock = errors.New("llock: would block")
+
+// acquire 获取锁. wait 为 false 时不等待
+func (k *Keyed) acquire(ctx context.Context, key string, write, wait bool) (func(), error) {
+	k.mu.Lock()
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyLock{holders: make(map[uint64]*time.Timer), wake: make(chan struct{})}
+		k.locks[key] = l
+	}
+
+	waiting := false
+	for {
+		free := !l.writer && (len(l.holders) == 0 || !write)
+		// 读锁: 有等待的写锁时，新的读锁需要等待
+		if free && (write || l.waitWriters == 0 || waiting) {
+			break
+		}
+		if !wait {
+			k.release(key, l)
+			k.mu.Unlock()
+			return nil, errWouldBlock
+		}
+
+		if !waiting {
+			waiting = true
+			l.waiters++
+			if write {
+				l.waitWriters++
+			}
+		}
+		ch := l.wake
+		k.mu.Unlock()
+
+		select {
+		case <-ch:
+			k.mu.Lock()
+		case <-ctx.Done():
+			k.mu.Lock()
+			k.stopWait(key, l, write)
+			k.mu.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+
+	if waiting {
+		l.waiters--
+		if write {
+			l.waitWriters--
+		}
+	}
+
+	k.seq++
+	id := k.seq
+	l.writer = write
+	var timer *time.Timer
+	if k.opt.TTL > 0 {
+		timer = time.AfterFunc(k.opt.TTL, func() { k.expire(key, l, id) })
+	}
+	l.holders[id] = timer
+	k.mu.Unlock()
+
+	var once sync.Once
+	return func() { once.Do(func() { k.unlock(key, l, id) }) }, nil
+}
+
+// stopWait 取消等待. 需持有锁
+func (k *Keyed) stopWait(key string, l *keyLock, write bool) {
+	l.waiters--
+	if write {
+		l.waitWriters--
+		// 等待的读锁可能因为该写锁在等待
+		k.broadcast(l)
+	}
+	k.release(key, l)
+}
+
+func (k *Keyed) unlock(key string, l *keyLock, id uint64) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	timer, ok := l.holders[id]
+	if !ok {
+		return
+	}
+	if timer != nil {
+		timer.Stop()
+	}
+	k.drop(key, l, id)
+}
+
+// expire TTL 到期自动释放锁
+func (k *Keyed) expire(key string, l *keyLock, id uint64) {
+	k.mu.Lock()
+	_, ok := l.holders[id]
+	if ok {
+		k.drop(key, l, id)
+	}
+	k.mu.Unlock()
+
+	if ok && k.opt.OnExpired != nil {
+		k.opt.OnExpired(key)
+	}
+}
+
+// drop 移除持有者并唤醒等待者. 需持有锁
+func (k *Keyed) drop(key string, l *keyLock, id uint64) {
+	delete(l.holders, id)
+	if len(l.holders) == 0 {
+		l.writer = false
+	}
+	k.broadcast(l)
+	k.release(key, l)
+}
+
+// broadcast 唤醒所有等待者
+func (k *Keyed) broadcast(l *keyLock) {
+	if l.waiters > 0 {
+		close(l.wake)
+		l.wake = make(chan struct{})
+	}
+}
+
+// release 锁空闲且没有等待者时删除. 需持有锁
+func (k *Keyed) release(key string, l *keyLock) {
+	if len(l.holders) == 0 && l.waiters == 0 && k.locks[key] == l {
+		delete(k.locks, key)
+	}
+}
@@ -0,0 +1,125 @@
+package llock_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/llock"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestKeyed(t *testing.T) {
+	k := llock.New()
+
+	var wg sync.WaitGroup
+	counter := 0
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := k.Lock("counter")
+			defer unlock()
+			counter++
+		}()
+	}
+	wg.Wait()
+	assert.Eq(t, 100, counter)
+	assert.Eq(t, 0, k.Len())
+
+	// different keys not block each other
+	unlock1 := k.Lock("a")
+	unlock2, ok := k.TryLock("b")
+	assert.True(t, ok)
+	_, ok = k.TryLock("a")
+	assert.False(t, ok)
+	_, ok = k.TryRLock("a")
+	assert.False(t, ok)
+	assert.True(t, k.IsLocked("a"))
+	assert.Eq(t, 2, k.Len())
+
+	// unlock is idempotent
+	unlock1()
+	unlock1()
+	unlock2()
+	assert.False(t, k.IsLocked("a"))
+	assert.Eq(t, 0, k.Len())
+}
+
+func TestKeyed_RLock(t *testing.T) {
+	k := llock.New()
+
+	r1 := k.RLock("key")
+	r2, ok := k.TryRLock("key")
+	assert.True(t, ok)
+	_, ok = k.TryLock("key")
+	assert.False(t, ok)
+
+	// a waiting writer blocks the new readers
+	locked := make(chan struct{})
+	go func() {
+		unlock := k.Lock("key")
+		close(locked)
+		unlock()
+	}()
+	for {
+		r, ok := k.TryRLock("key")
+		if !ok {
+			break
+		}
+		r()
+		time.Sleep(time.Millisecond)
+	}
+
+	r1()
+	r2()
+	<-locked
+
+	r3, err := k.RLockCtx(context.Background(), "key")
+	assert.NoErr(t, err)
+	r3()
+	assert.Eq(t, 0, k.Len())
+}
+
+func TestKeyed_ctx(t *testing.T) {
+	k := llock.New()
+	unlock := k.Lock("key")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := k.LockCtx(ctx, "key")
+	assert.ErrIs(t, err, context.DeadlineExceeded)
+	_, err = k.RLockCtx(ctx, "key")
+	assert.ErrIs(t, err, context.DeadlineExceeded)
+
+	unlock()
+	assert.Eq(t, 0, k.Len())
+}
+
+func TestKeyed_TTL(t *testing.T) {
+	var expired atomic.Value
+	k := llock.New(llock.WithTTL(20*time.Millisecond), llock.WithOnExpired(func(key string) {
+		expired.Store(key)
+	}))
+
+	// the holder forgot to unlock
+	unlock := k.Lock("job")
+	start := time.Now()
+	unlock2 := k.Lock("job")
+	assert.Gt(t, time.Since(start), 15*time.Millisecond)
+	assert.Eq(t, "job", expired.Load())
+
+	// unlock after auto-released is a no-op
+	unlock()
+	assert.True(t, k.IsLocked("job"))
+	unlock2()
+	assert.False(t, k.IsLocked("job"))
+
+	// as the lcache key locker
+	c := lcache.New(lcache.WithKeyLocker(k))
+	c.LockKey("key")()
+	assert.Eq(t, 0, k.Len())
+}
@@ -0,0 +1,82 @@
+// Package llock provides the keyed mutex utilities.
+//
+//   - Striped: fixed number of RWMutexes selected by key hash, no allocation per key,
+//     but different keys may share a lock.
+//   - Keyed: exact per-key RWMutexes created on demand and removed when free, supports
+//     context cancellation and the TTL-based auto-release.
+//
+// Both implement lcache.KeyLocker, can be used for the lcache.Cache.LockKey.
+//
+// Usage:
+//
+//	kl := llock.New(llock.WithTTL(10 * time.Second))
+//	unlock := kl.Lock("order:1001")
+//	defer unlock()
+//
+//	if unlock, ok := kl.TryLock("job:sync"); ok {
+//		defer unlock()
+//		// ...
+//	}
+package llock
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// DefaultStripes default number of the locks of Striped
+const DefaultStripes = 256
+
+// Striped the striped keyed RWMutexes. The lock of a key is selected by the key hash,
+// so different keys may share a lock.
+//
+// NOTE: do not lock multiple keys at the same time, it may deadlock.
+type Striped struct {
+	seed  maphash.Seed
+	locks []sync.RWMutex
+}
+
+// NewStriped create a Striped with the number of locks, DefaultStripes if n <= 0.
+func NewStriped(n int) *Striped {
+	if n <= 0 {
+		n = DefaultStripes
+	}
+	return &Striped{seed: maphash.MakeSeed(), locks: make([]sync.RWMutex, n)}
+}
+
+// get 按 key 哈希选择锁
+func (s *Striped) get(key string) *sync.RWMutex {
+	return &s.locks[maphash.String(s.seed, key)%uint64(len(s.locks))]
+}
+
+// Lock the key for writing, returns the unlock func.
+func (s *Striped) Lock(key string) (unlock func()) {
+	mu := s.get(key)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// TryLock try to lock the key for writing without blocking.
+func (s *Striped) TryLock(key string) (unlock func(), ok bool) {
+	mu := s.get(key)
+	if mu.TryLock() {
+		return mu.Unlock, true
+	}
+	return nil, false
+}
+
+// RLock the key for reading, returns the unlock func.
+func (s *Striped) RLock(key string) (unlock func()) {
+	mu := s.get(key)
+	mu.RLock()
+	return mu.RUnlock
+}
+
+// TryRLock try to lock the key for reading without blocking.
+func (s *Striped) TryRLock(key string) (unlock func(), ok bool) {
+	mu := s.get(key)
+	if mu.TryRLock() {
+		return mu.RUnlock, true
+	}
+	return nil, false
+}
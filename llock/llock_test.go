@@ -0,0 +1,66 @@
+package llock_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/llock"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestStriped(t *testing.T) {
+	s := llock.NewStriped(0)
+
+	var wg sync.WaitGroup
+	counter := 0
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := s.Lock("counter")
+			defer unlock()
+			counter++
+		}()
+	}
+	wg.Wait()
+	assert.Eq(t, 100, counter)
+
+	unlock := s.Lock("key")
+	_, ok := s.TryLock("key")
+	assert.False(t, ok)
+	_, ok = s.TryRLock("key")
+	assert.False(t, ok)
+	unlock()
+
+	runlock := s.RLock("key")
+	runlock2, ok := s.TryRLock("key")
+	assert.True(t, ok)
+	_, ok = s.TryLock("key")
+	assert.False(t, ok)
+	runlock()
+	runlock2()
+
+	unlock, ok = s.TryLock("key")
+	assert.True(t, ok)
+	unlock()
+}
+
+func TestStriped_lcache(t *testing.T) {
+	c := lcache.New(lcache.WithKeyLocker(llock.NewStriped(16)))
+	c.Set("counter", 0, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := c.LockKey("counter")
+			defer unlock()
+			n, _ := lcache.TypedInCache[int](c, "counter")
+			c.Set("counter", n+1, 0)
+		}()
+	}
+	wg.Wait()
+	assert.Eq(t, 50, c.Val("counter"))
+}
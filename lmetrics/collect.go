@@ -0,0 +1,86 @@
+package lmetrics
+
+import (
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/lpool"
+	"github.com/gookit/ext/lqueue"
+)
+
+// RegisterCache register a collector of the cache statistics with the label cache=name,
+// replaces the one registered with the same name. The metrics:
+//
+//   - gauges: lcache_items, lcache_valid_items, lcache_capacity, lcache_cost, lcache_pinned,
+//     lcache_loads_in_flight, lcache_load_p50_seconds, lcache_load_p99_seconds
+//   - counters: lcache_hits_total, lcache_misses_total, lcache_evictions_total,
+//     lcache_lock_waits_total, lcache_lock_wait_seconds_total, lcache_loads_total,
+//     lcache_load_coalesced_total, lcache_load_errors_total
+//
+// NOTE: the valid items is counted by traversing all data on export. see Cache.Stats
+func (r *Registry) RegisterCache(name string, c *lcache.Cache) {
+	r.Register("lcache:"+name, func() []Point {
+		st, ls := c.Stats(), c.LoadStats()
+		labels := []string{"cache", name}
+		return []Point{
+			{Name: "lcache_items", Kind: KindGauge, Labels: labels, Value: float64(st.Len)},
+			{Name: "lcache_valid_items", Kind: KindGauge, Labels: labels, Value: float64(st.ValidLen)},
+			{Name: "lcache_capacity", Kind: KindGauge, Labels: labels, Value: float64(st.Capacity)},
+			{Name: "lcache_cost", Kind: KindGauge, Labels: labels, Value: float64(st.Cost)},
+			{Name: "lcache_pinned", Kind: KindGauge, Labels: labels, Value: float64(st.Pinned)},
+			{Name: "lcache_hits_total", Kind: KindCounter, Labels: labels, Value: float64(st.Hits)},
+			{Name: "lcache_misses_total", Kind: KindCounter, Labels: labels, Value: float64(st.Misses)},
+			{Name: "lcache_evictions_total", Kind: KindCounter, Labels: labels, Value: float64(st.Evictions)},
+			{Name: "lcache_lock_waits_total", Kind: KindCounter, Labels: labels, Value: float64(st.LockWaits)},
+			{Name: "lcache_lock_wait_seconds_total", Kind: KindCounter, Labels: labels, Value: st.LockWaitTime.Seconds()},
+			{Name: "lcache_loads_in_flight", Kind: KindGauge, Labels: labels, Value: float64(ls.InFlight)},
+			{Name: "lcache_load_p50_seconds", Kind: KindGauge, Labels: labels, Value: ls.P50.Seconds()},
+			{Name: "lcache_load_p99_seconds", Kind: KindGauge, Labels: labels, Value: ls.P99.Seconds()},
+			{Name: "lcache_loads_total", Kind: KindCounter, Labels: labels, Value: float64(ls.Loads)},
+			{Name: "lcache_load_coalesced_total", Kind: KindCounter, Labels: labels, Value: float64(ls.Coalesced)},
+			{Name: "lcache_load_errors_total", Kind: KindCounter, Labels: labels, Value: float64(ls.Errors)},
+		}
+	})
+}
+
+// RegisterManager register the caches in the manager by RegisterCache, nil for
+// lcache.Default(). The caches registered to m later are not included.
+func (r *Registry) RegisterManager(m *lcache.Manager) {
+	if m == nil {
+		m = lcache.Default()
+	}
+	for _, name := range m.Names() {
+		if c, ok := m.Get(name); ok {
+			r.RegisterCache(name, c)
+		}
+	}
+}
+
+// RegisterQueue register a collector of the queue with the label queue=name, replaces
+// the one registered with the same name. The metrics:
+//
+//   - gauges: lqueue_length, lqueue_capacity(0 for unlimited)
+func (r *Registry) RegisterQueue(name string, q *lqueue.Queue) {
+	r.Register("lqueue:"+name, func() []Point {
+		labels := []string{"queue", name}
+		return []Point{
+			{Name: "lqueue_length", Kind: KindGauge, Labels: labels, Value: float64(q.Len())},
+			{Name: "lqueue_capacity", Kind: KindGauge, Labels: labels, Value: float64(max(q.Options().Capacity, 0))},
+		}
+	})
+}
+
+// RegisterPool register a collector of the goroutine pool with the label pool=name,
+// replaces the one registered with the same name. The metrics:
+//
+//   - gauges: lpool_running, lpool_waiting, lpool_workers
+//   - counters: lpool_panics_total
+func (r *Registry) RegisterPool(name string, p *lpool.Pool) {
+	r.Register("lpool:"+name, func() []Point {
+		labels := []string{"pool", name}
+		return []Point{
+			{Name: "lpool_running", Kind: KindGauge, Labels: labels, Value: float64(p.Running())},
+			{Name: "lpool_waiting", Kind: KindGauge, Labels: labels, Value: float64(p.Waiting())},
+			{Name: "lpool_workers", Kind: KindGauge, Labels: labels, Value: float64(p.Options().Workers)},
+			{Name: "lpool_panics_total", Kind: KindCounter, Labels: labels, Value: float64(p.Panics())},
+		}
+	})
+}
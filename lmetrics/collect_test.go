@@ -0,0 +1,79 @@
+package lmetrics_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/lmetrics"
+	"github.com/gookit/ext/lpool"
+	"github.com/gookit/ext/lqueue"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+// pointValue find the value of the point by name
+func pointValue(points []lmetrics.Point, name string, labels ...string) (float64, bool) {
+	for _, p := range points {
+		if p.Name == name && strings.Join(p.Labels, ",") == strings.Join(labels, ",") {
+			return p.Value, true
+		}
+	}
+	return 0, false
+}
+
+func TestRegistry_RegisterCache(t *testing.T) {
+	r := lmetrics.NewRegistry()
+	c := lcache.New()
+	c.Set("key1", "val1", 0)
+	c.Get("key1")
+	c.Get("none")
+	r.RegisterCache("users", c)
+
+	points := r.Snapshot()
+	val, ok := pointValue(points, "lcache_items", "cache", "users")
+	assert.True(t, ok)
+	assert.Eq(t, 1.0, val)
+	val, _ = pointValue(points, "lcache_hits_total", "cache", "users")
+	assert.Eq(t, 1.0, val)
+	val, _ = pointValue(points, "lcache_misses_total", "cache", "users")
+	assert.Eq(t, 1.0, val)
+
+	// manager
+	m := lcache.NewManager()
+	m.Register("orders", lcache.New())
+	r.RegisterManager(m)
+	_, ok = pointValue(r.Snapshot(), "lcache_items", "cache", "orders")
+	assert.True(t, ok)
+
+	// loader statistics
+	_, _ = c.GetOrLoad("key2", 0, func(string) (any, error) { return 2, nil })
+	val, _ = pointValue(r.Snapshot(), "lcache_loads_total", "cache", "users")
+	assert.Eq(t, 1.0, val)
+}
+
+func TestRegistry_RegisterQueue(t *testing.T) {
+	r := lmetrics.NewRegistry()
+	q := lqueue.New(lqueue.WithCapacity(10))
+	assert.NoErr(t, q.Push("job1"))
+	r.RegisterQueue("jobs", q)
+
+	points := r.Snapshot()
+	val, _ := pointValue(points, "lqueue_length", "queue", "jobs")
+	assert.Eq(t, 1.0, val)
+	val, _ = pointValue(points, "lqueue_capacity", "queue", "jobs")
+	assert.Eq(t, 10.0, val)
+}
+
+func TestRegistry_RegisterPool(t *testing.T) {
+	r := lmetrics.NewRegistry()
+	p := lpool.New(lpool.WithWorkers(2))
+	defer p.Shutdown(context.Background())
+	r.RegisterPool("workers", p)
+
+	points := r.Snapshot()
+	val, _ := pointValue(points, "lpool_workers", "pool", "workers")
+	assert.Eq(t, 2.0, val)
+	_, ok := pointValue(points, "lpool_panics_total", "pool", "workers")
+	assert.True(t, ok)
+}
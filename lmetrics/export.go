@@ -0,0 +1,273 @@
+package lmetrics
+
+import (
+	"bytes"
+	"expvar"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Exporter push the points to a backend. eg: *StatsD
+type Exporter interface {
+	Export(points []Point) error
+}
+
+// StartExport start a goroutine to export the snapshot to e on every interval, returns
+// a func to stop it. The export errors are ignored, the stop func exports once more.
+func (r *Registry) StartExport(e Exporter, interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				_ = e.Export(r.Snapshot())
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-exited
+			_ = e.Export(r.Snapshot())
+		})
+	}
+}
+
+//
+// ----- Prometheus text format -----
+//
+
+// WritePrometheus write the snapshot in the Prometheus text exposition format.
+//
+// NOTE: the metric and label names are not validated, use the valid Prometheus names. eg: "http_requests_total"
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	var sb strings.Builder
+	var lastName string
+	for _, p := range r.Snapshot() {
+		if p.Name != lastName {
+			lastName = p.Name
+			sb.WriteString("# TYPE " + p.Name + " " + p.Kind.String() + "\n")
+		}
+
+		if p.Kind != KindHistogram || p.Hist == nil {
+			sb.WriteString(p.Name)
+			writeLabels(&sb, p.Labels, "", "")
+			sb.WriteString(" " + formatFloat(p.Value) + "\n")
+			continue
+		}
+
+		hv := p.Hist
+		for i, bound := range hv.Bounds {
+			sb.WriteString(p.Name + "_bucket")
+			writeLabels(&sb, p.Labels, "le", formatFloat(bound))
+			sb.WriteString(" " + strconv.FormatUint(hv.Counts[i], 10) + "\n")
+		}
+		sb.WriteString(p.Name + "_bucket")
+		writeLabels(&sb, p.Labels, "le", "+Inf")
+		sb.WriteString(" " + strconv.FormatUint(hv.Count, 10) + "\n")
+
+		sb.WriteString(p.Name + "_sum")
+		writeLabels(&sb, p.Labels, "", "")
+		sb.WriteString(" " + formatFloat(hv.Sum) + "\n")
+		sb.WriteString(p.Name + "_count")
+		writeLabels(&sb, p.Labels, "", "")
+		sb.WriteString(" " + strconv.FormatUint(hv.Count, 10) + "\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Handler get the http handler serves the metrics in the Prometheus text format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.WritePrometheus(w)
+	})
+}
+
+//
+// ----- expvar -----
+//
+
+// PublishExpvar publish the metrics to expvar with the name, the value is a map of
+// `name{labels}` => value, a histogram is a map with "count", "sum" and "buckets".
+//
+// NOTE: expvar panics if the name is already published.
+func (r *Registry) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return r.expvarMap() }))
+}
+
+func (r *Registry) expvarMap() map[string]any {
+	points := r.Snapshot()
+	vars := make(map[string]any, len(points))
+	for _, p := range points {
+		key := metricKey(p.Name, p.Labels)
+		if p.Kind != KindHistogram || p.Hist == nil {
+			vars[key] = p.Value
+			continue
+		}
+
+		buckets := make(map[string]uint64, len(p.Hist.Bounds)+1)
+		for i, bound := range p.Hist.Bounds {
+			buckets[formatFloat(bound)] = p.Hist.Counts[i]
+		}
+		buckets["+Inf"] = p.Hist.Count
+		vars[key] = map[string]any{"count": p.Hist.Count, "sum": p.Hist.Sum, "buckets": buckets}
+	}
+	return vars
+}
+
+//
+// ----- StatsD -----
+//
+
+// maxPacketSize UDP 包的最大长度，避免超出常见的 MTU 被分片
+const maxPacketSize = 1432
+
+// StatsDOption option for the StatsD exporter
+type StatsDOption func(s *StatsD)
+
+// WithStatsDPrefix set the prefix of the metric names. eg: "myapp."
+func WithStatsDPrefix(prefix string) StatsDOption {
+	return func(s *StatsD) { s.prefix = prefix }
+}
+
+// WithStatsDTags add the DogStatsD tags to all metrics. eg: "env:prod"
+func WithStatsDTags(tags ...string) StatsDOption {
+	return func(s *StatsD) { s.tags = append(s.tags, tags...) }
+}
+
+// StatsD export the points over UDP in StatsD format, the labels are sent as the
+// DogStatsD tags "name:value".
+//
+// The counters are sent as the delta since the last export, the gauges as is. A histogram
+// is sent as the counters "<name>_count" and "<name>_sum".
+type StatsD struct {
+	conn   net.Conn
+	prefix string
+	tags   []string
+
+	// mu 串行执行 Export. last 为上次发送的计数器的值，用于计算增量
+	mu   sync.Mutex
+	last map[string]float64
+}
+
+// NewStatsD create a StatsD exporter send to addr. eg: "127.0.0.1:8125"
+func NewStatsD(addr string, opts ...StatsDOption) (*StatsD, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &StatsD{conn: conn, last: make(map[string]float64)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Export send the points, returns the first write error.
+func (s *StatsD) Export(points []Point) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firstErr error
+	var buf bytes.Buffer
+	send := func() {
+		if buf.Len() == 0 {
+			return
+		}
+		if _, err := s.conn.Write(buf.Bytes()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		buf.Reset()
+	}
+
+	seen := make(map[string]bool, len(points))
+	for _, p := range points {
+		tags := s.tagString(p.Labels)
+		for _, line := range s.lines(p, seen) {
+			line += tags
+			if buf.Len() > 0 && buf.Len()+1+len(line) > maxPacketSize {
+				send()
+			}
+			if buf.Len() > 0 {
+				buf.WriteByte('\n')
+			}
+			buf.WriteString(line)
+		}
+	}
+	send()
+
+	// 删除已移除的计数器
+	for key := range s.last {
+		if !seen[key] {
+			delete(s.last, key)
+		}
+	}
+	return firstErr
+}
+
+// Close the connection
+func (s *StatsD) Close() error { return s.conn.Close() }
+
+// lines 格式化指标，不包含 tags
+func (s *StatsD) lines(p Point, seen map[string]bool) []string {
+	switch {
+	case p.Kind == KindGauge:
+		return []string{s.prefix + p.Name + ":" + formatFloat(p.Value) + "|g"}
+	case p.Kind == KindCounter:
+		return []string{s.counter(p.Name, p.Labels, p.Value, seen)}
+	case p.Hist != nil:
+		return []string{
+			s.counter(p.Name+"_count", p.Labels, float64(p.Hist.Count), seen),
+			s.counter(p.Name+"_sum", p.Labels, p.Hist.Sum, seen),
+		}
+	}
+	return nil
+}
+
+// counter 格式化计数器的增量. 计数器重建后小于上次的值时，使用当前值
+func (s *StatsD) counter(name string, labels []string, val float64, seen map[string]bool) string {
+	key := metricKey(name, labels)
+	seen[key] = true
+	last, ok := s.last[key]
+	s.last[key] = val
+
+	delta := val
+	if ok && val >= last {
+		delta = val - last
+	}
+	return s.prefix + name + ":" + formatFloat(delta) + "|c"
+}
+
+// tagString 格式化 DogStatsD tags. eg: "|#method:GET,env:prod"
+func (s *StatsD) tagString(labels []string) string {
+	if len(labels) == 0 && len(s.tags) == 0 {
+		return ""
+	}
+
+	tags := make([]string, 0, len(labels)/2+len(s.tags))
+	for i := 0; i < len(labels); i += 2 {
+		tags = append(tags, labels[i]+":"+labels[i+1])
+	}
+	return "|#" + strings.Join(append(tags, s.tags...), ",")
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,120 @@
+package lmetrics_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lmetrics"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func newTestRegistry() *lmetrics.Registry {
+	r := lmetrics.NewRegistry()
+	r.Counter("requests_total", "method", "GET").Add(3)
+	r.Counter("requests_total", "method", "POST").Inc()
+	r.Gauge("temperature").Set(21.5)
+	h := r.Histogram("latency_seconds", []float64{0.1, 1}, "path", `/a"b`)
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(3)
+	return r
+}
+
+func TestRegistry_WritePrometheus(t *testing.T) {
+	r := newTestRegistry()
+
+	var sb strings.Builder
+	assert.NoErr(t, r.WritePrometheus(&sb))
+	assert.Eq(t, `# TYPE latency_seconds histogram
+latency_seconds_bucket{path="/a\"b",le="0.1"} 1
+latency_seconds_bucket{path="/a\"b",le="1"} 2
+latency_seconds_bucket{path="/a\"b",le="+Inf"} 3
+latency_seconds_sum{path="/a\"b"} 3.55
+latency_seconds_count{path="/a\"b"} 3
+# TYPE requests_total counter
+requests_total{method="GET"} 3
+requests_total{method="POST"} 1
+# TYPE temperature gauge
+temperature 21.5
+`, sb.String())
+
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Eq(t, 200, w.Code)
+	assert.StrContains(t, w.Header().Get("Content-Type"), "text/plain")
+	assert.StrContains(t, w.Body.String(), `requests_total{method="GET"} 3`)
+}
+
+func TestRegistry_PublishExpvar(t *testing.T) {
+	r := newTestRegistry()
+	r.PublishExpvar("test_lmetrics")
+
+	var vars map[string]any
+	assert.NoErr(t, json.Unmarshal([]byte(expvar.Get("test_lmetrics").String()), &vars))
+	assert.Eq(t, 3.0, vars[`requests_total{method="GET"}`])
+	assert.Eq(t, 21.5, vars["temperature"])
+
+	hist := vars[`latency_seconds{path="/a\"b"}`].(map[string]any)
+	assert.Eq(t, 3.0, hist["count"])
+	assert.Eq(t, map[string]any{"0.1": 1.0, "1": 2.0, "+Inf": 3.0}, hist["buckets"])
+}
+
+// listen 启动 UDP 服务，返回地址和读取一个数据包的函数
+func listen(t *testing.T) (string, func() []string) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoErr(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn.LocalAddr().String(), func() []string {
+		buf := make([]byte, 2048)
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		assert.NoErr(t, err)
+		return strings.Split(string(buf[:n]), "\n")
+	}
+}
+
+func TestStatsD_Export(t *testing.T) {
+	addr, read := listen(t)
+	r := newTestRegistry()
+
+	s, err := lmetrics.NewStatsD(addr, lmetrics.WithStatsDPrefix("app."), lmetrics.WithStatsDTags("env:prod"))
+	assert.NoErr(t, err)
+	defer s.Close()
+
+	assert.NoErr(t, s.Export(r.Snapshot()))
+	lines := read()
+	assert.Contains(t, lines, "app.requests_total:3|c|#method:GET,env:prod")
+	assert.Contains(t, lines, "app.temperature:21.5|g|#env:prod")
+	assert.Contains(t, lines, `app.latency_seconds_count:3|c|#path:/a"b,env:prod`)
+
+	// counters are the delta since the last export
+	r.Counter("requests_total", "method", "GET").Add(2)
+	assert.NoErr(t, s.Export(r.Snapshot()))
+	lines = read()
+	assert.Contains(t, lines, "app.requests_total:2|c|#method:GET,env:prod")
+	assert.Contains(t, lines, "app.requests_total:0|c|#method:POST,env:prod")
+}
+
+func TestRegistry_StartExport(t *testing.T) {
+	addr, read := listen(t)
+	r := lmetrics.NewRegistry()
+	r.Gauge("temperature").Set(20)
+
+	s, err := lmetrics.NewStatsD(addr)
+	assert.NoErr(t, err)
+	defer s.Close()
+
+	stop := r.StartExport(s, 20*time.Millisecond)
+	assert.Eq(t, []string{"temperature:20|g"}, read())
+
+	stop()
+	stop()
+	// exported once more on stop
+	assert.Eq(t, []string{"temperature:20|g"}, read())
+}
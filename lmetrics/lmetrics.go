@@ -0,0 +1,372 @@
+// Package lmetrics provides a shared lightweight metrics registry: counters, gauges and
+// histograms, with the exporters for expvar, Prometheus text format and StatsD.
+//
+// The ext packages publish into a registry by the collectors, so the apps get one
+// consistent metrics surface. eg: RegisterCache, RegisterQueue and RegisterPool.
+//
+// Usage:
+//
+//	r := lmetrics.Default()
+//	reqs := r.Counter("http_requests_total", "method", "GET")
+//	reqs.Inc()
+//	latency := r.Histogram("http_request_seconds", nil)
+//	latency.ObserveDuration(time.Since(start))
+//
+//	r.RegisterCache("users", usersCache)
+//	http.Handle("/metrics", r.Handler())
+package lmetrics
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Kind of the metric
+type Kind uint8
+
+const (
+	// KindCounter a monotonically increasing value
+	KindCounter Kind = iota
+	// KindGauge a value can go up and down
+	KindGauge
+	// KindHistogram the distribution of the observed values in buckets
+	KindHistogram
+)
+
+// String get the kind name
+func (k Kind) String() string {
+	switch k {
+	case KindCounter:
+		return "counter"
+	case KindGauge:
+		return "gauge"
+	case KindHistogram:
+		return "histogram"
+	}
+	return "unknown"
+}
+
+// DefBuckets the default histogram buckets, for the request latencies in seconds.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Point a snapshot value of a metric, passed to the exporters.
+type Point struct {
+	Name string
+	Kind Kind
+	// Labels the label name-value pairs. eg: ["method", "GET", "code", "200"]
+	Labels []string
+	// Value of the counter or gauge
+	Value float64
+	// Hist value of the histogram, only for KindHistogram
+	Hist *HistValue
+}
+
+// HistValue the snapshot value of a histogram
+type HistValue struct {
+	Count uint64
+	Sum   float64
+	// Bounds the upper bounds of the buckets, Counts[i] is the cumulative number of
+	// the observations <= Bounds[i]. The +Inf bucket is Count.
+	Bounds []float64
+	Counts []uint64
+}
+
+// Collector collect the points on export. eg: convert the stats of a cache to points
+type Collector func() []Point
+
+// Counter a monotonically increasing counter, it is goroutine-safe.
+type Counter struct {
+	val atomic.Uint64
+}
+
+// Inc increase the counter by 1
+func (c *Counter) Inc() { c.val.Add(1) }
+
+// Add n to the counter
+func (c *Counter) Add(n uint64) { c.val.Add(n) }
+
+// Value get the counter value
+func (c *Counter) Value() uint64 { return c.val.Load() }
+
+// Gauge a value can go up and down, it is goroutine-safe.
+type Gauge struct {
+	bits atomic.Uint64
+}
+
+// Set the gauge value
+func (g *Gauge) Set(v float64) { g.bits.Store(math.Float64bits(v)) }
+
+// Add delta to the gauge value, delta can be negative.
+func (g *Gauge) Add(delta float64) { addFloat(&g.bits, delta) }
+
+// Value get the gauge value
+func (g *Gauge) Value() float64 { return math.Float64frombits(g.bits.Load()) }
+
+// Histogram count the observed values in buckets, it is goroutine-safe.
+type Histogram struct {
+	bounds []float64
+	// counts 每个桶的数量(非累计)，最后一个为 +Inf 桶
+	counts []atomic.Uint64
+	count  atomic.Uint64
+	sum    atomic.Uint64
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	bounds := slices.Clone(buckets)
+	slices.Sort(bounds)
+	bounds = slices.Compact(bounds)
+	return &Histogram{bounds: bounds, counts: make([]atomic.Uint64, len(bounds)+1)}
+}
+
+// Observe a value
+func (h *Histogram) Observe(v float64) {
+	i, _ := slices.BinarySearch(h.bounds, v)
+	h.counts[i].Add(1)
+	h.count.Add(1)
+	addFloat(&h.sum, v)
+}
+
+// ObserveDuration observe a duration in seconds
+func (h *Histogram) ObserveDuration(d time.Duration) { h.Observe(d.Seconds()) }
+
+// Value get the snapshot value of the histogram
+func (h *Histogram) Value() *HistValue {
+	hv := &HistValue{Bounds: h.bounds, Counts: make([]uint64, len(h.bounds))}
+	var cum uint64
+	for i := range h.bounds {
+		cum += h.counts[i].Load()
+		hv.Counts[i] = cum
+	}
+	// 与各桶的数量保持一致，不使用可能已变更的 count
+	hv.Count = cum + h.counts[len(h.bounds)].Load()
+	hv.Sum = math.Float64frombits(h.sum.Load())
+	return hv
+}
+
+// addFloat 原子地累加 float64
+func addFloat(bits *atomic.Uint64, delta float64) {
+	for {
+		old := bits.Load()
+		if bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+// metric 注册的指标. 按类型使用其中一个字段
+type metric struct {
+	name   string
+	kind   Kind
+	labels []string
+
+	counter *Counter
+	gauge   *Gauge
+	hist    *Histogram
+	fn      func() float64
+}
+
+func (m *metric) point() Point {
+	p := Point{Name: m.name, Kind: m.kind, Labels: m.labels}
+	switch {
+	case m.fn != nil:
+		p.Value = m.fn()
+	case m.counter != nil:
+		p.Value = float64(m.counter.Value())
+	case m.gauge != nil:
+		p.Value = m.gauge.Value()
+	case m.hist != nil:
+		p.Hist = m.hist.Value()
+	}
+	return p
+}
+
+// Registry the metrics registry, it is goroutine-safe.
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]*metric
+	// collectors 按名称注册的收集器
+	collectors map[string]Collector
+}
+
+// NewRegistry create a registry
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]*metric), collectors: make(map[string]Collector)}
+}
+
+var std = NewRegistry()
+
+// Default get the default registry
+func Default() *Registry { return std }
+
+// Counter get or create the counter of the name and labels.
+// Panics if the labels are not pairs, or the metric exists with another kind.
+func (r *Registry) Counter(name string, labels ...string) *Counter {
+	return r.getOrAdd(name, KindCounter, labels, func(m *metric) {
+		m.counter = &Counter{}
+	}).counter
+}
+
+// Gauge get or create the gauge of the name and labels.
+// Panics if the labels are not pairs, or the metric exists with another kind.
+func (r *Registry) Gauge(name string, labels ...string) *Gauge {
+	return r.getOrAdd(name, KindGauge, labels, func(m *metric) {
+		m.gauge = &Gauge{}
+	}).gauge
+}
+
+// Histogram get or create the histogram of the name and labels, buckets is the upper
+// bounds of the buckets, nil for DefBuckets. The buckets are ignored if it exists.
+// Panics if the labels are not pairs, or the metric exists with another kind.
+func (r *Registry) Histogram(name string, buckets []float64, labels ...string) *Histogram {
+	return r.getOrAdd(name, KindHistogram, labels, func(m *metric) {
+		m.hist = newHistogram(buckets)
+	}).hist
+}
+
+// CounterFunc register a counter that the value is got by fn on export, it replaces the
+// existing one of the name and labels.
+func (r *Registry) CounterFunc(name string, fn func() float64, labels ...string) {
+	r.setFunc(name, KindCounter, fn, labels)
+}
+
+// GaugeFunc register a gauge that the value is got by fn on export, it replaces the
+// existing one of the name and labels.
+func (r *Registry) GaugeFunc(name string, fn func() float64, labels ...string) {
+	r.setFunc(name, KindGauge, fn, labels)
+}
+
+// Register a collector with the name, it replaces the existing one of the name.
+func (r *Registry) Register(name string, c Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors[name] = c
+}
+
+// Unregister the collector of the name
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.collectors, name)
+}
+
+// Remove the metric of the name and labels, returns false if not exists.
+func (r *Registry) Remove(name string, labels ...string) bool {
+	key := metricKey(name, labels)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.metrics[key]
+	delete(r.metrics, key)
+	return ok
+}
+
+// Snapshot get the points of all metrics and collectors, sorted by the name and labels.
+func (r *Registry) Snapshot() []Point {
+	r.mu.RLock()
+	metrics := make([]*metric, 0, len(r.metrics))
+	for _, m := range r.metrics {
+		metrics = append(metrics, m)
+	}
+	collectors := make([]Collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mu.RUnlock()
+
+	// 解锁后计算，函数和收集器可以调用 registry 的方法
+	points := make([]Point, 0, len(metrics))
+	for _, m := range metrics {
+		points = append(points, m.point())
+	}
+	for _, c := range collectors {
+		points = append(points, c()...)
+	}
+
+	slices.SortStableFunc(points, func(a, b Point) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return slices.Compare(a.Labels, b.Labels)
+	})
+	return points
+}
+
+func (r *Registry) getOrAdd(name string, kind Kind, labels []string, init func(m *metric)) *metric {
+	key := metricKey(name, labels)
+	r.mu.RLock()
+	m, ok := r.metrics[key]
+	r.mu.RUnlock()
+
+	if !ok {
+		r.mu.Lock()
+		if m, ok = r.metrics[key]; !ok {
+			m = &metric{name: name, kind: kind, labels: slices.Clone(labels)}
+			init(m)
+			r.metrics[key] = m
+		}
+		r.mu.Unlock()
+	}
+
+	if m.kind != kind || m.fn != nil {
+		panic("lmetrics: metric " + key + " exists with another kind or as a func")
+	}
+	return m
+}
+
+func (r *Registry) setFunc(name string, kind Kind, fn func() float64, labels []string) {
+	key := metricKey(name, labels)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics[key] = &metric{name: name, kind: kind, labels: slices.Clone(labels), fn: fn}
+}
+
+// metricKey 指标的唯一 key. eg: `name{method="GET"}`
+func metricKey(name string, labels []string) string {
+	if len(labels)%2 != 0 {
+		panic("lmetrics: labels must be name-value pairs")
+	}
+	if len(labels) == 0 {
+		return name
+	}
+
+	var sb strings.Builder
+	sb.WriteString(name)
+	writeLabels(&sb, labels, "", "")
+	return sb.String()
+}
+
+// writeLabels 以 Prometheus 格式写入 labels, extraName 不为空时追加. eg: `{method="GET",le="0.1"}`
+func writeLabels(sb *strings.Builder, labels []string, extraName, extraVal string) {
+	if len(labels) == 0 && extraName == "" {
+		return
+	}
+
+	sb.WriteByte('{')
+	for i := 0; i < len(labels); i += 2 {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(labels[i])
+		sb.WriteString(`="`)
+		sb.WriteString(labelEscaper.Replace(labels[i+1]))
+		sb.WriteByte('"')
+	}
+	if extraName != "" {
+		if len(labels) > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(extraName)
+		sb.WriteString(`="`)
+		sb.WriteString(extraVal)
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
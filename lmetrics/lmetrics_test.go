@@ -0,0 +1,102 @@
+package lmetrics_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lmetrics"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestRegistry_Counter(t *testing.T) {
+	r := lmetrics.NewRegistry()
+	c := r.Counter("requests_total", "method", "GET")
+	c.Inc()
+	c.Add(2)
+	assert.Eq(t, uint64(3), c.Value())
+
+	// get the same counter
+	assert.Eq(t, c, r.Counter("requests_total", "method", "GET"))
+	assert.NotEq(t, c, r.Counter("requests_total", "method", "POST"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.Counter("requests_total", "method", "GET").Inc()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Eq(t, uint64(1003), c.Value())
+
+	assert.Panics(t, func() { r.Gauge("requests_total", "method", "GET") })
+	assert.Panics(t, func() { r.Counter("requests_total", "method") })
+}
+
+func TestRegistry_Gauge(t *testing.T) {
+	r := lmetrics.NewRegistry()
+	g := r.Gauge("temperature")
+	g.Set(1.5)
+	g.Add(2)
+	g.Add(-0.5)
+	assert.Eq(t, 3.0, g.Value())
+
+	r.GaugeFunc("goroutines", func() float64 { return 8 })
+	r.CounterFunc("uptime_seconds_total", func() float64 { return 60 })
+	assert.Panics(t, func() { r.Gauge("goroutines") })
+
+	points := r.Snapshot()
+	assert.Len(t, points, 3)
+	assert.Eq(t, "goroutines", points[0].Name)
+	assert.Eq(t, lmetrics.KindGauge, points[0].Kind)
+	assert.Eq(t, 8.0, points[0].Value)
+	assert.Eq(t, "temperature", points[1].Name)
+	assert.Eq(t, "uptime_seconds_total", points[2].Name)
+	assert.Eq(t, "counter", points[2].Kind.String())
+
+	assert.True(t, r.Remove("goroutines"))
+	assert.False(t, r.Remove("goroutines"))
+	assert.Len(t, r.Snapshot(), 2)
+}
+
+func TestRegistry_Histogram(t *testing.T) {
+	r := lmetrics.NewRegistry()
+	h := r.Histogram("latency_seconds", []float64{1, 0.1, 0.5, 0.5})
+	h.Observe(0.05)
+	h.Observe(0.1)
+	h.Observe(0.3)
+	h.ObserveDuration(2 * time.Second)
+
+	hv := h.Value()
+	assert.Eq(t, []float64{0.1, 0.5, 1}, hv.Bounds)
+	assert.Eq(t, []uint64{2, 3, 3}, hv.Counts)
+	assert.Eq(t, uint64(4), hv.Count)
+	assert.Eq(t, 2.45, hv.Sum)
+
+	// default buckets
+	h2 := r.Histogram("size", nil, "kind", "img")
+	assert.Len(t, h2.Value().Bounds, len(lmetrics.DefBuckets))
+	assert.Eq(t, h, r.Histogram("latency_seconds", nil))
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := lmetrics.NewRegistry()
+	r.Counter("b_total").Inc()
+	r.Register("test", func() []lmetrics.Point {
+		return []lmetrics.Point{{Name: "a_items", Kind: lmetrics.KindGauge, Value: 5}}
+	})
+
+	points := r.Snapshot()
+	assert.Len(t, points, 2)
+	assert.Eq(t, "a_items", points[0].Name)
+	assert.Eq(t, 5.0, points[0].Value)
+	assert.Eq(t, 1.0, points[1].Value)
+
+	r.Unregister("test")
+	assert.Len(t, r.Snapshot(), 1)
+	assert.NotNil(t, lmetrics.Default())
+}
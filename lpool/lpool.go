@@ -0,0 +1,244 @@
+// Package lpool provides a lightweight bounded goroutine pool, with panic recovery,
+// queue backpressure policies and graceful shutdown.
+//
+// Usage:
+//
+//	p := lpool.New(lpool.WithWorkers(8), lpool.WithQueueSize(100))
+//	defer p.Shutdown(context.Background())
+//
+//	err := p.Submit(func() { sendMail(to) })
+//	// submit and wait for the task done
+//	err = p.SubmitWait(func() { resizeImage(file) })
+//
+// The pool can be used as the executor of lcache background tasks:
+//
+//	c := lcache.New(lcache.WithRefreshAhead(0.8, loadUser), lcache.WithExecutor(p))
+//
+// With OverflowCallerRuns, a reload that cannot be queued runs in the Get call that triggered it.
+package lpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrQueueFull the task queue is full and the overflow policy is OverflowReject
+	ErrQueueFull = errors.New("lpool: task queue is full")
+	// ErrClosed the pool is shut down
+	ErrClosed = errors.New("lpool: pool is closed")
+)
+
+// Overflow the policy of submitting a task when the task queue is full. see WithOverflow
+type Overflow uint8
+
+const (
+	// OverflowBlock block the submitter until the queue has room. it is default policy.
+	OverflowBlock Overflow = iota
+	// OverflowReject reject the task, Submit returns ErrQueueFull.
+	OverflowReject
+	// OverflowCallerRuns run the task in the submitter goroutine.
+	OverflowCallerRuns
+)
+
+// PanicError the error of a recovered task panic, returned by SubmitWait.
+type PanicError struct {
+	// Value the recovered value
+	Value any
+}
+
+// Error implements error
+func (e *PanicError) Error() string { return fmt.Sprintf("lpool: task panic: %v", e.Value) }
+
+// Options for the pool
+type Options struct {
+	// Workers number of the worker goroutines. default is runtime.NumCPU()
+	Workers int
+	// QueueSize size of the task queue. default is 1000
+	QueueSize int
+	// Overflow policy when the task queue is full. default is OverflowBlock
+	Overflow Overflow
+	// PanicHandler handle the recovered task panic, the worker keeps running.
+	PanicHandler func(val any)
+}
+
+// OptionFn option func for New
+type OptionFn func(o *Options)
+
+// WithWorkers set the number of the worker goroutines
+func WithWorkers(n int) OptionFn {
+	return func(o *Options) { o.Workers = n }
+}
+
+// WithQueueSize set the size of the task queue, 0 for no queue: the task is submitted
+// only when a worker is idle.
+func WithQueueSize(size int) OptionFn {
+	return func(o *Options) { o.QueueSize = size }
+}
+
+// WithOverflow set the policy when the task queue is full. see Overflow
+func WithOverflow(policy Overflow) OptionFn {
+	return func(o *Options) { o.Overflow = policy }
+}
+
+// WithPanicHandler set the handler of the recovered task panic
+func WithPanicHandler(fn func(val any)) OptionFn {
+	return func(o *Options) { o.PanicHandler = fn }
+}
+
+// Pool a bounded goroutine pool. The workers are started on created, and exit on Shutdown.
+type Pool struct {
+	opt   Options
+	tasks chan func()
+	// mu 保护 closed 和关闭 tasks 通道，提交任务时持有读锁
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+	// 正在执行的任务数和已恢复的 panic 数
+	running atomic.Int64
+	panics  atomic.Uint64
+}
+
+// New create a pool and start the workers
+func New(optFns ...OptionFn) *Pool {
+	opt := Options{
+		Workers:   runtime.NumCPU(),
+		QueueSize: 1000,
+	}
+	for _, fn := range optFns {
+		fn(&opt)
+	}
+	opt.Workers = max(opt.Workers, 1)
+	opt.QueueSize = max(opt.QueueSize, 0)
+
+	p := &Pool{
+		opt:   opt,
+		tasks: make(chan func(), opt.QueueSize),
+		done:  make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(opt.Workers)
+	for range opt.Workers {
+		go func() {
+			defer wg.Done()
+			for task := range p.tasks {
+				p.run(task)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(p.done)
+	}()
+	return p
+}
+
+// Options get the options of the pool
+func (p *Pool) Options() Options { return p.opt }
+
+// Submit a task to the pool. When the queue is full, it blocks, returns ErrQueueFull
+// or runs the task in the caller goroutine by the overflow policy.
+// Returns ErrClosed if the pool is shut down.
+func (p *Pool) Submit(task func()) error {
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return ErrClosed
+	}
+
+	if p.opt.Overflow == OverflowBlock {
+		p.tasks <- task
+		p.mu.RUnlock()
+		return nil
+	}
+
+	select {
+	case p.tasks <- task:
+		p.mu.RUnlock()
+		return nil
+	default:
+		p.mu.RUnlock()
+	}
+
+	if p.opt.Overflow == OverflowCallerRuns {
+		// 释放锁后执行，任务中可以再次提交任务
+		p.run(task)
+		return nil
+	}
+	return ErrQueueFull
+}
+
+// SubmitWait submit a task and wait for it done. Returns *PanicError if the task panics,
+// other errors are same as Submit.
+func (p *Pool) SubmitWait(task func()) error {
+	var perr error
+	done := make(chan struct{})
+	err := p.Submit(func() {
+		defer close(done)
+		defer func() {
+			if val := recover(); val != nil {
+				perr = &PanicError{Value: val}
+				p.recovered(val)
+			}
+		}()
+		task()
+	})
+	if err != nil {
+		return err
+	}
+
+	<-done
+	return perr
+}
+
+// Running get the number of the running tasks
+func (p *Pool) Running() int { return int(p.running.Load()) }
+
+// Waiting get the number of the tasks waiting in the queue
+func (p *Pool) Waiting() int { return len(p.tasks) }
+
+// Panics get the number of the recovered task panics
+func (p *Pool) Panics() uint64 { return p.panics.Load() }
+
+// Shutdown stop accepting new tasks and wait for the queued and running tasks done,
+// or returns ctx.Err() if ctx is done first. It is safe to call multiple times.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.tasks)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run 执行任务并恢复 panic
+func (p *Pool) run(task func()) {
+	p.running.Add(1)
+	defer func() {
+		p.running.Add(-1)
+		if val := recover(); val != nil {
+			p.recovered(val)
+		}
+	}()
+	task()
+}
+
+// recovered 记录并处理任务的 panic
+func (p *Pool) recovered(val any) {
+	p.panics.Add(1)
+	if p.opt.PanicHandler != nil {
+		p.opt.PanicHandler(val)
+	}
+}
@@ -0,0 +1,108 @@
+package lpool_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lpool"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestPool_Submit(t *testing.T) {
+	p := lpool.New(lpool.WithWorkers(2))
+	assert.Eq(t, 1000, p.Options().QueueSize)
+
+	var n atomic.Int32
+	for i := 0; i < 100; i++ {
+		assert.NoErr(t, p.Submit(func() { n.Add(1) }))
+	}
+
+	// wait for queued tasks done
+	assert.NoErr(t, p.Shutdown(context.Background()))
+	assert.Eq(t, int32(100), n.Load())
+	assert.ErrIs(t, p.Submit(func() {}), lpool.ErrClosed)
+	assert.NoErr(t, p.Shutdown(context.Background()))
+}
+
+func TestPool_SubmitWait(t *testing.T) {
+	var handled atomic.Value
+	p := lpool.New(lpool.WithWorkers(1), lpool.WithPanicHandler(func(val any) {
+		handled.Store(val)
+	}))
+	defer p.Shutdown(context.Background())
+
+	var done bool
+	assert.NoErr(t, p.SubmitWait(func() { done = true }))
+	assert.True(t, done)
+
+	// panic is recovered, the worker keeps running
+	err := p.SubmitWait(func() { panic("oops") })
+	var perr *lpool.PanicError
+	assert.True(t, errors.As(err, &perr))
+	assert.Eq(t, "oops", perr.Value)
+	assert.Eq(t, "lpool: task panic: oops", err.Error())
+	assert.Eq(t, "oops", handled.Load())
+	assert.Eq(t, uint64(1), p.Panics())
+	assert.NoErr(t, p.SubmitWait(func() {}))
+}
+
+func TestPool_overflow(t *testing.T) {
+	block := make(chan struct{})
+	started := make(chan struct{})
+	busy := func() {
+		started <- struct{}{}
+		<-block
+	}
+
+	// reject
+	p := lpool.New(lpool.WithWorkers(1), lpool.WithQueueSize(1), lpool.WithOverflow(lpool.OverflowReject))
+	assert.NoErr(t, p.Submit(busy))
+	<-started
+	assert.NoErr(t, p.Submit(func() {}))
+	assert.Eq(t, 1, p.Running())
+	assert.Eq(t, 1, p.Waiting())
+	assert.ErrIs(t, p.Submit(func() {}), lpool.ErrQueueFull)
+
+	// shutdown timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	assert.ErrIs(t, p.Shutdown(ctx), context.DeadlineExceeded)
+	block <- struct{}{}
+	assert.NoErr(t, p.Shutdown(context.Background()))
+
+	// caller runs
+	p = lpool.New(lpool.WithWorkers(1), lpool.WithQueueSize(1), lpool.WithOverflow(lpool.OverflowCallerRuns))
+	defer p.Shutdown(context.Background())
+	assert.NoErr(t, p.Submit(busy))
+	<-started
+	assert.NoErr(t, p.Submit(func() {}))
+
+	var inCaller bool
+	assert.NoErr(t, p.Submit(func() { inCaller = true }))
+	assert.True(t, inCaller)
+	block <- struct{}{}
+}
+
+func TestPool_blockSubmit(t *testing.T) {
+	p := lpool.New(lpool.WithWorkers(1), lpool.WithQueueSize(0))
+	block := make(chan struct{})
+	assert.NoErr(t, p.Submit(func() { <-block }))
+
+	submitted := make(chan struct{})
+	go func() {
+		_ = p.Submit(func() {})
+		close(submitted)
+	}()
+
+	select {
+	case <-submitted:
+		t.Fatal("submit should be blocked")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(block)
+	<-submitted
+	assert.NoErr(t, p.Shutdown(context.Background()))
+}
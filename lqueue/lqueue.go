@@ -0,0 +1,320 @@
+// Package lqueue provides a goroutine-safe, bounded in-memory queue with message
+// priority, TTL and file persistence.
+//
+// Messages with higher priority are popped first, messages with the same priority
+// are popped in FIFO order. The default priority is 0, so the queue works as a FIFO queue.
+//
+// Usage:
+//
+//	q := lqueue.New(lqueue.WithCapacity(1000))
+//	err := q.Push("job1")
+//	err = q.PushX("urgent", lqueue.WithPriority(10), lqueue.WithTTL(time.Minute))
+//
+//	// blocking pop, wait until a message is pushed or ctx is done
+//	val, err := q.Pop(ctx)
+//	// non-blocking pop
+//	val, ok := q.TryPop()
+package lqueue
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrFull the queue is full. see WithCapacity
+	ErrFull = errors.New("lqueue: queue is full")
+	// ErrClosed the queue is closed. see Queue.Close
+	ErrClosed = errors.New("lqueue: queue is closed")
+)
+
+// Options for the queue
+type Options struct {
+	// Capacity maximum number of messages, <= 0 for unlimited. default is 1000
+	Capacity int
+	// TTL default TTL of the messages, <= 0 for never expire.
+	TTL time.Duration
+	// Serializer name of the registered lcache serializer for SaveFile and LoadFile. default is "json"
+	Serializer string
+	// SaveSync call fsync on the file before rename it on SaveFile
+	SaveSync bool
+}
+
+// OptionFn option func for New
+type OptionFn func(o *Options)
+
+// WithCapacity set the maximum number of messages, <= 0 for unlimited.
+func WithCapacity(capacity int) OptionFn {
+	return func(o *Options) { o.Capacity = capacity }
+}
+
+// WithDefaultTTL set the default TTL of the messages, it can be overridden by WithTTL on PushX.
+func WithDefaultTTL(ttl time.Duration) OptionFn {
+	return func(o *Options) { o.TTL = ttl }
+}
+
+// WithSerializer set the serializer for SaveFile and LoadFile, it must be registered
+// in lcache. see lcache.SetSerializer
+func WithSerializer(name string) OptionFn {
+	return func(o *Options) { o.Serializer = name }
+}
+
+// WithSaveSync set whether to fsync the file on SaveFile, then the saved file
+// is complete after a system crash.
+func WithSaveSync(sync bool) OptionFn {
+	return func(o *Options) { o.SaveSync = sync }
+}
+
+// PushOption option func for Queue.PushX
+type PushOption func(m *message)
+
+// WithPriority set the priority of the message, higher is popped first. default is 0
+func WithPriority(pri int) PushOption {
+	return func(m *message) { m.Pri = pri }
+}
+
+// WithTTL set the TTL of the message, <= 0 for never expire. The expired messages are
+// dropped and never popped.
+func WithTTL(ttl time.Duration) PushOption {
+	return func(m *message) { m.Exp = ttlToExp(ttl) }
+}
+
+// message 队列中的消息. 字段需要导出，用于持久化
+type message struct {
+	Val any `json:"v"`
+	// Pri 优先级，越大越先出队
+	Pri int `json:"p,omitempty"`
+	// Exp 过期时间 millitime, 0 为永不过期
+	Exp int64 `json:"e,omitempty"`
+	// Seq 入队序号，相同优先级按序号先进先出
+	Seq uint64 `json:"s"`
+}
+
+// expired 检查消息是否已过期
+func (m *message) expired(nowUm int64) bool {
+	return m.Exp > 0 && nowUm > m.Exp
+}
+
+// msgHeap 按优先级和入队序号排列的消息堆. implements heap.Interface
+type msgHeap []*message
+
+func (h msgHeap) Len() int { return len(h) }
+
+func (h msgHeap) Less(i, j int) bool {
+	if h[i].Pri != h[j].Pri {
+		return h[i].Pri > h[j].Pri
+	}
+	return h[i].Seq < h[j].Seq
+}
+
+func (h msgHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *msgHeap) Push(x any) { *h = append(*h, x.(*message)) }
+
+func (h *msgHeap) Pop() any {
+	old := *h
+	n := len(old)
+	m := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return m
+}
+
+// Queue a goroutine-safe bounded queue with priority and TTL support.
+type Queue struct {
+	opt Options
+	mu  sync.Mutex
+	// 消息堆
+	msgs msgHeap
+	// 下一个入队序号
+	seq uint64
+	// 等待消息的通知通道，有等待者时才创建，入队时关闭以唤醒所有等待者
+	ready  chan struct{}
+	closed bool
+}
+
+// New create a queue with options
+func New(optFns ...OptionFn) *Queue {
+	q := &Queue{
+		opt: Options{
+			Capacity:   1000,
+			Serializer: "json",
+		},
+	}
+	for _, fn := range optFns {
+		fn(&q.opt)
+	}
+	return q
+}
+
+// Options get the options of the queue
+func (q *Queue) Options() Options { return q.opt }
+
+// Push add a message to the queue with the default TTL and priority 0.
+// Returns ErrFull if the queue is full, ErrClosed if the queue is closed.
+func (q *Queue) Push(val any) error { return q.PushX(val) }
+
+// PushX add a message to the queue with options: WithPriority, WithTTL.
+// Returns ErrFull if the queue is full, ErrClosed if the queue is closed.
+func (q *Queue) PushX(val any, opts ...PushOption) error {
+	m := &message{Val: val, Exp: ttlToExp(q.opt.TTL)}
+	for _, fn := range opts {
+		fn(m)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	if q.full() {
+		return ErrFull
+	}
+
+	q.push(m)
+	q.notify()
+	return nil
+}
+
+// TryPop get and remove the first message without blocking, returns false if the queue is empty.
+func (q *Queue) TryPop() (any, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pop()
+}
+
+// Pop get and remove the first message, blocks until a message is available or ctx is done.
+// Returns ctx.Err() if ctx is done, ErrClosed if the queue is closed and empty.
+func (q *Queue) Pop(ctx context.Context) (any, error) {
+	for {
+		q.mu.Lock()
+		if val, ok := q.pop(); ok {
+			q.mu.Unlock()
+			return val, nil
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return nil, ErrClosed
+		}
+
+		if q.ready == nil {
+			q.ready = make(chan struct{})
+		}
+		ready := q.ready
+		q.mu.Unlock()
+
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// PopTimeout get and remove the first message, blocks until a message is available or timeout.
+// Returns context.DeadlineExceeded on timeout.
+func (q *Queue) PopTimeout(timeout time.Duration) (any, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return q.Pop(ctx)
+}
+
+// Peek get the first message without removing it, returns false if the queue is empty.
+func (q *Queue) Peek() (any, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.dropExpired(time.Now().UnixMilli())
+	if len(q.msgs) == 0 {
+		return nil, false
+	}
+	return q.msgs[0].Val, true
+}
+
+// Len get the number of messages in the queue.
+//
+// NOTE: the expired messages are counted until they are dropped on pop or when the queue is full.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.msgs)
+}
+
+// Clear remove all messages
+func (q *Queue) Clear() {
+	q.mu.Lock()
+	q.msgs = nil
+	q.mu.Unlock()
+}
+
+// Close the queue, the blocked Pop calls return ErrClosed. The remaining messages
+// can still be popped, but Push returns ErrClosed.
+func (q *Queue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		q.notify()
+	}
+}
+
+// push 写入消息并分配序号 (不加锁)
+func (q *Queue) push(m *message) {
+	q.seq++
+	m.Seq = q.seq
+	heap.Push(&q.msgs, m)
+}
+
+// pop 取出第一个未过期的消息 (不加锁)
+func (q *Queue) pop() (any, bool) {
+	q.dropExpired(time.Now().UnixMilli())
+	if len(q.msgs) == 0 {
+		return nil, false
+	}
+	return heap.Pop(&q.msgs).(*message).Val, true
+}
+
+// dropExpired 删除堆顶的已过期消息 (不加锁)
+func (q *Queue) dropExpired(nowUm int64) {
+	for len(q.msgs) > 0 && q.msgs[0].expired(nowUm) {
+		heap.Pop(&q.msgs)
+	}
+}
+
+// full 检查队列是否已满，已满时先删除所有已过期的消息再检查 (不加锁)
+func (q *Queue) full() bool {
+	if q.opt.Capacity <= 0 || len(q.msgs) < q.opt.Capacity {
+		return false
+	}
+
+	nowUm := time.Now().UnixMilli()
+	msgs := q.msgs[:0]
+	for _, m := range q.msgs {
+		if !m.expired(nowUm) {
+			msgs = append(msgs, m)
+		}
+	}
+	clear(q.msgs[len(msgs):])
+	q.msgs = msgs
+	heap.Init(&q.msgs)
+	return len(q.msgs) >= q.opt.Capacity
+}
+
+// notify 唤醒所有等待消息的 Pop 调用 (不加锁)
+func (q *Queue) notify() {
+	if q.ready != nil {
+		close(q.ready)
+		q.ready = nil
+	}
+}
+
+// ttlToExp 将 TTL 转换为过期时间 millitime. ttl <= 0 时为 0(永不过期)
+func ttlToExp(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return time.Now().Add(ttl).UnixMilli()
+}
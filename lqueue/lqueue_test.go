@@ -0,0 +1,138 @@
+package lqueue_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lqueue"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestQueue_fifoAndPriority(t *testing.T) {
+	q := lqueue.New()
+	assert.NoErr(t, q.Push("a"))
+	assert.NoErr(t, q.Push("b"))
+	assert.NoErr(t, q.PushX("urgent", lqueue.WithPriority(10)))
+	assert.NoErr(t, q.PushX("low", lqueue.WithPriority(-1)))
+	assert.NoErr(t, q.Push("c"))
+	assert.Eq(t, 5, q.Len())
+
+	val, ok := q.Peek()
+	assert.True(t, ok)
+	assert.Eq(t, "urgent", val)
+
+	var got []any
+	for {
+		val, ok := q.TryPop()
+		if !ok {
+			break
+		}
+		got = append(got, val)
+	}
+	assert.Eq(t, []any{"urgent", "a", "b", "c", "low"}, got)
+	assert.Eq(t, 0, q.Len())
+}
+
+func TestQueue_capacityAndTTL(t *testing.T) {
+	q := lqueue.New(lqueue.WithCapacity(2))
+	assert.NoErr(t, q.PushX("tmp", lqueue.WithTTL(time.Millisecond)))
+	assert.NoErr(t, q.Push("a"))
+	assert.ErrIs(t, q.Push("b"), lqueue.ErrFull)
+
+	// expired message is dropped to make room
+	time.Sleep(5 * time.Millisecond)
+	assert.NoErr(t, q.Push("b"))
+	assert.Eq(t, 2, q.Len())
+
+	val, ok := q.TryPop()
+	assert.True(t, ok)
+	assert.Eq(t, "a", val)
+
+	// default TTL
+	q = lqueue.New(lqueue.WithDefaultTTL(time.Millisecond))
+	assert.NoErr(t, q.Push("a"))
+	assert.NoErr(t, q.PushX("b", lqueue.WithTTL(0)))
+	time.Sleep(5 * time.Millisecond)
+	val, ok = q.TryPop()
+	assert.True(t, ok)
+	assert.Eq(t, "b", val)
+	_, ok = q.TryPop()
+	assert.False(t, ok)
+}
+
+func TestQueue_Pop(t *testing.T) {
+	q := lqueue.New()
+
+	var wg sync.WaitGroup
+	results := make(chan any, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if val, err := q.Pop(context.Background()); err == nil {
+				results <- val
+			}
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	assert.NoErr(t, q.Push(1))
+	assert.NoErr(t, q.Push(2))
+	wg.Wait()
+	assert.Eq(t, 3, (<-results).(int)+(<-results).(int))
+
+	// timeout
+	_, err := q.PopTimeout(5 * time.Millisecond)
+	assert.ErrIs(t, err, context.DeadlineExceeded)
+
+	// close wakes up the blocked pop
+	assert.NoErr(t, q.Push("left"))
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.Close()
+	}()
+	val, err := q.Pop(context.Background())
+	assert.NoErr(t, err)
+	assert.Eq(t, "left", val)
+	_, err = q.Pop(context.Background())
+	assert.ErrIs(t, err, lqueue.ErrClosed)
+	assert.ErrIs(t, q.Push("x"), lqueue.ErrClosed)
+}
+
+func TestQueue_SaveFile(t *testing.T) {
+	filename := t.TempDir() + "/queue.json"
+	q := lqueue.New(lqueue.WithSaveSync(true))
+	assert.NoErr(t, q.Push("a"))
+	assert.NoErr(t, q.PushX("b", lqueue.WithPriority(5)))
+	assert.NoErr(t, q.PushX("tmp", lqueue.WithTTL(time.Millisecond)))
+	assert.NoErr(t, q.Push(map[string]any{"id": "c"}))
+	time.Sleep(5 * time.Millisecond)
+	assert.NoErr(t, q.SaveFile(filename))
+	assert.False(t, fsutil.FileExists(filename+".tmp"))
+
+	q2 := lqueue.New(lqueue.WithCapacity(2))
+	assert.NoErr(t, q2.Push("first"))
+	assert.ErrIs(t, q2.LoadFile(filename), lqueue.ErrFull)
+	assert.Eq(t, 2, q2.Len())
+	val, _ := q2.TryPop()
+	assert.Eq(t, "first", val)
+	val, _ = q2.TryPop()
+	assert.Eq(t, "a", val)
+
+	q3 := lqueue.New()
+	assert.NoErr(t, q3.LoadFile(filename))
+	assert.Eq(t, 3, q3.Len())
+	for _, want := range []any{"b", "a", map[string]any{"id": "c"}} {
+		val, _ = q3.TryPop()
+		assert.Eq(t, want, val)
+	}
+
+	// errors
+	assert.Err(t, q3.LoadFile(filename+".not-exist"))
+	q4 := lqueue.New(lqueue.WithSerializer("not-exist"))
+	assert.Err(t, q4.SaveFile(filename))
+	assert.Err(t, q4.LoadFile(filename))
+}
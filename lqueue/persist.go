@@ -0,0 +1,93 @@
+package lqueue
+
+import (
+	"cmp"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/goutil/x/stdio"
+)
+
+// snapshot 持久化到文件的数据结构
+type snapshot struct {
+	Msgs []*message `json:"msgs"`
+}
+
+// SaveFile save the messages to a file, the expired messages are skipped.
+//
+// The file is replaced by lcache.FileProvider, a failed write keeps the previous file.
+// Use WithSaveSync to fsync the file before the rename.
+//
+// NOTE: the values are decoded as generic types by the JSON serializer(eg: map[string]any),
+// use the gob serializer and register the value types for keeping the concrete types.
+func (q *Queue) SaveFile(filename string) error {
+	s, err := lcache.LookupSerializer(q.opt.Serializer)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	nowUm := time.Now().UnixMilli()
+	data := &snapshot{Msgs: make([]*message, 0, len(q.msgs))}
+	for _, m := range q.msgs {
+		if !m.expired(nowUm) {
+			data.Msgs = append(data.Msgs, m)
+		}
+	}
+
+	return lcache.FileProvider{Sync: q.opt.SaveSync}.Snapshot(filename, func(w io.Writer) error {
+		return s.EncodeTo(w, data)
+	})
+}
+
+// LoadFile load the messages from a file saved by SaveFile, and push them after the
+// existing messages with the saved order, priority and expiration.
+//
+// Returns ErrFull if the queue is full, the messages before it are loaded.
+func (q *Queue) LoadFile(filename string) error {
+	s, err := lcache.LookupSerializer(q.opt.Serializer)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer stdio.SafeClose(file)
+
+	var data snapshot
+	if err = s.DecodeFrom(file, &data); err != nil {
+		return err
+	}
+
+	// 按保存时的入队顺序写入
+	data.Msgs = slices.DeleteFunc(data.Msgs, func(m *message) bool { return m == nil })
+	slices.SortFunc(data.Msgs, func(a, b *message) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	defer q.notify()
+
+	nowUm := time.Now().UnixMilli()
+	for _, m := range data.Msgs {
+		if m.expired(nowUm) {
+			continue
+		}
+		if q.full() {
+			return ErrFull
+		}
+		q.push(m)
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+// Package lring provides a generic concurrent fixed-size ring buffer.
+//
+// Two modes when the ring is full:
+//
+//   - ModeOverwrite: the oldest item is overwritten, Put never blocks. eg: tracking the recent N items.
+//   - ModeBlock: Put blocks until there is room. eg: delivering the events to a consumer.
+//
+// Usage:
+//
+//	// the recent 100 errors
+//	recent := lring.New[error](100)
+//	recent.Put(err)
+//	errs := recent.Snapshot()
+//
+//	// event delivery with backpressure, consumed in batches
+//	events := lring.New[Event](1024, lring.WithMode(lring.ModeBlock))
+//	go func() {
+//		for {
+//			batch, err := events.DrainWait(ctx, 64)
+//			if err != nil {
+//				return
+//			}
+//			handle(batch)
+//		}
+//	}()
+//	err := events.Put(evt)
+package lring
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClosed the ring is closed. see Ring.Close
+var ErrClosed = errors.New("lring: ring is closed")
+
+// Mode the behavior of Put when the ring is full
+type Mode uint8
+
+const (
+	// ModeOverwrite overwrite the oldest item. it is default mode.
+	ModeOverwrite Mode = iota
+	// ModeBlock block until there is room
+	ModeBlock
+)
+
+// Options for the ring
+type Options struct {
+	// Mode the behavior of Put when the ring is full. default is ModeOverwrite
+	Mode Mode
+}
+
+// OptionFn option func for New
+type OptionFn func(o *Options)
+
+// WithMode set the behavior of Put when the ring is full
+func WithMode(mode Mode) OptionFn {
+	return func(o *Options) { o.Mode = mode }
+}
+
+// Ring a generic fixed-size ring buffer, it is goroutine-safe.
+type Ring[T any] struct {
+	opt Options
+	mu  sync.Mutex
+	buf []T
+	// head 最早的数据的位置; size 数据数量
+	head, size int
+	// overwritten 覆盖的数据总数
+	overwritten uint64
+	closed      bool
+	// wake 状态变更时关闭，唤醒所有等待者; waiters 等待者数量
+	wake    chan struct{}
+	waiters int
+}
+
+// New create a ring with the capacity, panics if capacity <= 0.
+func New[T any](capacity int, optFns ...OptionFn) *Ring[T] {
+	if capacity <= 0 {
+		panic("lring: capacity must be greater than 0")
+	}
+
+	r := &Ring[T]{buf: make([]T, capacity), wake: make(chan struct{})}
+	for _, fn := range optFns {
+		fn(&r.opt)
+	}
+	return r
+}
+
+// Put an item to the ring. In ModeBlock, it blocks until there is room.
+// Returns ErrClosed if the ring is closed.
+func (r *Ring[T]) Put(val T) error {
+	return r.PutCtx(context.Background(), val)
+}
+
+// PutCtx like Put, returns ctx.Err() if ctx is done before there is room.
+func (r *Ring[T]) PutCtx(ctx context.Context, val T) error {
+	r.mu.Lock()
+	for {
+		if r.closed {
+			r.mu.Unlock()
+			return ErrClosed
+		}
+		if r.size < len(r.buf) || r.opt.Mode == ModeOverwrite {
+			break
+		}
+
+		if err := r.wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	r.put(val)
+	r.mu.Unlock()
+	return nil
+}
+
+// TryPut put an item without blocking, returns false if the ring is full in ModeBlock
+// or closed.
+func (r *Ring[T]) TryPut(val T) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed || (r.size == len(r.buf) && r.opt.Mode == ModeBlock) {
+		return false
+	}
+	r.put(val)
+	return true
+}
+
+// put 写入数据，已满时覆盖最早的数据. 需持有锁
+func (r *Ring[T]) put(val T) {
+	if r.size == len(r.buf) {
+		r.buf[r.head] = val
+		r.head = (r.head + 1) % len(r.buf)
+		r.overwritten++
+	} else {
+		r.buf[(r.head+r.size)%len(r.buf)] = val
+		r.size++
+	}
+	r.broadcast()
+}
+
+// TryGet get and remove the oldest item without blocking, returns false if empty.
+func (r *Ring[T]) TryGet() (val T, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.size == 0 {
+		return val, false
+	}
+	return r.take(1)[0], true
+}
+
+// Get and remove the oldest item, blocks until an item is available.
+// Returns ErrClosed if the ring is closed and empty, or ctx.Err() if ctx is done.
+func (r *Ring[T]) Get(ctx context.Context) (val T, err error) {
+	items, err := r.DrainWait(ctx, 1)
+	if err != nil {
+		return val, err
+	}
+	return items[0], nil
+}
+
+// Drain get and remove at most n oldest items without blocking, n <= 0 for all.
+// Returns nil if empty.
+func (r *Ring[T]) Drain(n int) []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.size == 0 {
+		return nil
+	}
+	return r.take(n)
+}
+
+// DrainWait like Drain, but blocks until at least one item is available.
+// Returns ErrClosed if the ring is closed and empty, or ctx.Err() if ctx is done.
+func (r *Ring[T]) DrainWait(ctx context.Context, n int) ([]T, error) {
+	r.mu.Lock()
+	for r.size == 0 {
+		if r.closed {
+			r.mu.Unlock()
+			return nil, ErrClosed
+		}
+
+		if err := r.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	items := r.take(n)
+	r.mu.Unlock()
+	return items, nil
+}
+
+// take 取出最早的 n 个数据. 需持有锁且不为空
+func (r *Ring[T]) take(n int) []T {
+	if n <= 0 || n > r.size {
+		n = r.size
+	}
+
+	var zero T
+	items := make([]T, n)
+	for i := range items {
+		items[i] = r.buf[r.head]
+		// 释放引用
+		r.buf[r.head] = zero
+		r.head = (r.head + 1) % len(r.buf)
+	}
+	r.size -= n
+	r.broadcast()
+	return items
+}
+
+// Peek get the oldest item without removing it
+func (r *Ring[T]) Peek() (val T, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.size == 0 {
+		return val, false
+	}
+	return r.buf[r.head], true
+}
+
+// Snapshot get a copy of all items from the oldest to the newest, without removing them.
+func (r *Ring[T]) Snapshot() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	items := make([]T, r.size)
+	for i := range items {
+		items[i] = r.buf[(r.head+i)%len(r.buf)]
+	}
+	return items
+}
+
+// Len get the number of items
+func (r *Ring[T]) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.size
+}
+
+// Cap get the capacity
+func (r *Ring[T]) Cap() int { return len(r.buf) }
+
+// Overwritten get the total number of the overwritten items in ModeOverwrite
+func (r *Ring[T]) Overwritten() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.overwritten
+}
+
+// Clear remove all items
+func (r *Ring[T]) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.buf)
+	r.head, r.size = 0, 0
+	r.broadcast()
+}
+
+// Close the ring, the blocked and later Put return ErrClosed. The remaining items can
+// still be got, then Get and DrainWait return ErrClosed.
+func (r *Ring[T]) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.closed {
+		r.closed = true
+		r.broadcast()
+	}
+}
+
+// wait 等待状态变更. 需持有锁，返回 nil 时仍持有锁，返回错误时已解锁
+func (r *Ring[T]) wait(ctx context.Context) error {
+	ch := r.wake
+	r.waiters++
+	r.mu.Unlock()
+
+	var err error
+	select {
+	case <-ch:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	r.mu.Lock()
+	r.waiters--
+	if err != nil {
+		r.mu.Unlock()
+	}
+	return err
+}
+
+// broadcast 唤醒所有等待者. 需持有锁
+func (r *Ring[T]) broadcast() {
+	if r.waiters > 0 {
+		close(r.wake)
+		r.wake = make(chan struct{})
+	}
+}
@@ -0,0 +1,129 @@
+package lring_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lring"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestRing_overwrite(t *testing.T) {
+	r := lring.New[int](3)
+	assert.Eq(t, 3, r.Cap())
+	_, ok := r.Peek()
+	assert.False(t, ok)
+	assert.Nil(t, r.Drain(0))
+
+	for i := 1; i <= 5; i++ {
+		assert.NoErr(t, r.Put(i))
+	}
+	assert.Eq(t, 3, r.Len())
+	assert.Eq(t, uint64(2), r.Overwritten())
+	assert.Eq(t, []int{3, 4, 5}, r.Snapshot())
+
+	val, ok := r.Peek()
+	assert.True(t, ok)
+	assert.Eq(t, 3, val)
+	assert.True(t, r.TryPut(6))
+	assert.Eq(t, []int{4, 5, 6}, r.Snapshot())
+
+	val, ok = r.TryGet()
+	assert.True(t, ok)
+	assert.Eq(t, 4, val)
+	assert.Eq(t, []int{5, 6}, r.Drain(0))
+	_, ok = r.TryGet()
+	assert.False(t, ok)
+
+	// wrap around
+	for i := 7; i <= 10; i++ {
+		r.Put(i)
+	}
+	assert.Eq(t, []int{8}, r.Drain(1))
+	assert.Eq(t, []int{9, 10}, r.Drain(5))
+
+	r.Put(1)
+	r.Clear()
+	assert.Eq(t, 0, r.Len())
+	assert.Eq(t, []int{}, r.Snapshot())
+
+	assert.Panics(t, func() { lring.New[int](0) })
+}
+
+func TestRing_block(t *testing.T) {
+	r := lring.New[int](2, lring.WithMode(lring.ModeBlock))
+	assert.NoErr(t, r.Put(1))
+	assert.True(t, r.TryPut(2))
+	assert.False(t, r.TryPut(3))
+
+	// timeout on full
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrIs(t, r.PutCtx(ctx, 3), context.DeadlineExceeded)
+
+	// unblocked by get
+	done := make(chan error, 1)
+	go func() { done <- r.Put(3) }()
+	time.Sleep(10 * time.Millisecond)
+	val, err := r.Get(context.Background())
+	assert.NoErr(t, err)
+	assert.Eq(t, 1, val)
+	assert.NoErr(t, <-done)
+	assert.Eq(t, []int{2, 3}, r.Snapshot())
+	assert.Eq(t, uint64(0), r.Overwritten())
+
+	// close unblocks the put
+	go func() { done <- r.Put(4) }()
+	time.Sleep(10 * time.Millisecond)
+	r.Close()
+	assert.ErrIs(t, <-done, lring.ErrClosed)
+	assert.ErrIs(t, r.Put(5), lring.ErrClosed)
+	assert.False(t, r.TryPut(5))
+
+	// the remaining items can be got
+	items, err := r.DrainWait(context.Background(), 0)
+	assert.NoErr(t, err)
+	assert.Eq(t, []int{2, 3}, items)
+	_, err = r.Get(context.Background())
+	assert.ErrIs(t, err, lring.ErrClosed)
+}
+
+func TestRing_DrainWait(t *testing.T) {
+	r := lring.New[int](100, lring.WithMode(lring.ModeBlock))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := r.DrainWait(ctx, 10)
+	assert.ErrIs(t, err, context.DeadlineExceeded)
+
+	// producers and a batch consumer
+	var wg sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 250; i++ {
+				_ = r.Put(i)
+			}
+		}()
+	}
+
+	got := make(chan int, 1)
+	go func() {
+		var n int
+		for {
+			batch, err := r.DrainWait(context.Background(), 16)
+			if err != nil {
+				got <- n
+				return
+			}
+			n += len(batch)
+		}
+	}()
+
+	wg.Wait()
+	r.Close()
+	assert.Eq(t, 1000, <-got)
+}
@@ -0,0 +1,255 @@
+// Package lru provides a minimal generic LRU container, without TTL and serialization.
+//
+// Usage:
+//
+//	c := lru.New[string, int](1000, lru.WithOnEvict(func(key string, val int) {
+//		fmt.Println("evicted:", key)
+//	}))
+//	c.Set("a", 1)
+//	val, ok := c.Get("a")
+//
+//	// iterate from the most to the least recently used
+//	c.Range(func(key string, val int) bool {
+//		return true
+//	})
+package lru
+
+import "sync"
+
+// entry 链表节点
+type entry[K comparable, V any] struct {
+	key        K
+	val        V
+	prev, next *entry[K, V]
+}
+
+// OptionFn option func for New
+type OptionFn[K comparable, V any] func(c *Cache[K, V])
+
+// WithOnEvict set the callback on an entry evicted by the capacity limit, not called
+// for Delete and Clear. It is called after the cache is unlocked, can call the cache methods.
+func WithOnEvict[K comparable, V any](fn func(key K, val V)) OptionFn[K, V] {
+	return func(c *Cache[K, V]) { c.onEvict = fn }
+}
+
+// Cache a generic LRU cache with fixed capacity. It is goroutine-safe.
+type Cache[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[K]*entry[K, V]
+	// root 哨兵节点. root.next 为最近使用, root.prev 为最久未使用
+	root    entry[K, V]
+	onEvict func(key K, val V)
+}
+
+// New create a LRU cache with the capacity, panics if capacity <= 0.
+func New[K comparable, V any](capacity int, opts ...OptionFn[K, V]) *Cache[K, V] {
+	if capacity <= 0 {
+		panic("lru: capacity must be greater than 0")
+	}
+
+	c := &Cache[K, V]{capacity: capacity, items: make(map[K]*entry[K, V], capacity)}
+	c.root.next, c.root.prev = &c.root, &c.root
+	for _, fn := range opts {
+		fn(c)
+	}
+	return c
+}
+
+// Set the value of the key and mark it as most recently used.
+// Returns true if the least recently used entry is evicted.
+func (c *Cache[K, V]) Set(key K, val V) (evicted bool) {
+	c.mu.Lock()
+	if e, ok := c.items[key]; ok {
+		e.val = val
+		c.moveToFront(e)
+		c.mu.Unlock()
+		return false
+	}
+
+	e := &entry[K, V]{key: key, val: val}
+	c.items[key] = e
+	c.pushFront(e)
+	removed := c.evict(c.capacity)
+	c.mu.Unlock()
+
+	c.notify(removed)
+	return len(removed) > 0
+}
+
+// Get the value of the key and mark it as most recently used.
+func (c *Cache[K, V]) Get(key K) (val V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return val, false
+	}
+	c.moveToFront(e)
+	return e.val, true
+}
+
+// Peek get the value of the key without updating the recently used order.
+func (c *Cache[K, V]) Peek(key K) (val V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		return e.val, true
+	}
+	return val, false
+}
+
+// Contains check the key exists, without updating the recently used order.
+func (c *Cache[K, V]) Contains(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.items[key]
+	return ok
+}
+
+// Delete the key, returns false if not exists.
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if ok {
+		c.remove(e)
+	}
+	return ok
+}
+
+// Oldest get the least recently used entry, without updating the order.
+func (c *Cache[K, V]) Oldest() (key K, val V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e := c.root.prev; e != &c.root {
+		return e.key, e.val, true
+	}
+	return key, val, false
+}
+
+// RemoveOldest remove and return the least recently used entry.
+func (c *Cache[K, V]) RemoveOldest() (key K, val V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e := c.root.prev; e != &c.root {
+		c.remove(e)
+		return e.key, e.val, true
+	}
+	return key, val, false
+}
+
+// Keys get all keys, from the most to the least recently used.
+func (c *Cache[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]K, 0, len(c.items))
+	for e := c.root.next; e != &c.root; e = e.next {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+// Range calls fn for each entry from the most to the least recently used, stop
+// iteration if fn returns false. The order is not updated.
+//
+// It iterates a snapshot of the entries, so fn can safely call the cache methods.
+func (c *Cache[K, V]) Range(fn func(key K, val V) bool) {
+	c.mu.Lock()
+	entries := make([]entry[K, V], 0, len(c.items))
+	for e := c.root.next; e != &c.root; e = e.next {
+		entries = append(entries, entry[K, V]{key: e.key, val: e.val})
+	}
+	c.mu.Unlock()
+
+	for _, e := range entries {
+		if !fn(e.key, e.val) {
+			return
+		}
+	}
+}
+
+// Len get the number of entries
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// Cap get the capacity
+func (c *Cache[K, V]) Cap() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.capacity
+}
+
+// Resize change the capacity, the least recently used entries are evicted if the cache
+// exceeds the new capacity. Returns the number of the evicted entries, panics if capacity <= 0.
+func (c *Cache[K, V]) Resize(capacity int) int {
+	if capacity <= 0 {
+		panic("lru: capacity must be greater than 0")
+	}
+
+	c.mu.Lock()
+	c.capacity = capacity
+	removed := c.evict(capacity)
+	c.mu.Unlock()
+
+	c.notify(removed)
+	return len(removed)
+}
+
+// Clear remove all entries, the eviction callback is not called.
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.items)
+	c.root.next, c.root.prev = &c.root, &c.root
+}
+
+// evict 淘汰最久未使用的数据，直到数量不超过 n. 需持有锁
+func (c *Cache[K, V]) evict(n int) (removed []*entry[K, V]) {
+	for len(c.items) > n {
+		e := c.root.prev
+		c.remove(e)
+		removed = append(removed, e)
+	}
+	return removed
+}
+
+// notify 解锁后执行淘汰回调
+func (c *Cache[K, V]) notify(removed []*entry[K, V]) {
+	if c.onEvict == nil {
+		return
+	}
+	for _, e := range removed {
+		c.onEvict(e.key, e.val)
+	}
+}
+
+func (c *Cache[K, V]) pushFront(e *entry[K, V]) {
+	e.prev, e.next = &c.root, c.root.next
+	c.root.next.prev = e
+	c.root.next = e
+}
+
+func (c *Cache[K, V]) moveToFront(e *entry[K, V]) {
+	if c.root.next == e {
+		return
+	}
+	e.prev.next, e.next.prev = e.next, e.prev
+	c.pushFront(e)
+}
+
+func (c *Cache[K, V]) remove(e *entry[K, V]) {
+	e.prev.next, e.next.prev = e.next, e.prev
+	e.prev, e.next = nil, nil
+	delete(c.items, e.key)
+}
@@ -0,0 +1,110 @@
+package lru_test
+
+import (
+	"testing"
+
+	"github.com/gookit/ext/lru"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestCache(t *testing.T) {
+	var evicted []string
+	c := lru.New[string, int](3, lru.WithOnEvict(func(key string, val int) {
+		evicted = append(evicted, key)
+	}))
+
+	assert.False(t, c.Set("a", 1))
+	assert.False(t, c.Set("b", 2))
+	assert.False(t, c.Set("c", 3))
+	assert.False(t, c.Set("a", 10)) // update
+	assert.Eq(t, []string{"a", "c", "b"}, c.Keys())
+
+	// Get updates the order, Peek and Contains not
+	val, ok := c.Get("b")
+	assert.True(t, ok)
+	assert.Eq(t, 2, val)
+	val, ok = c.Peek("c")
+	assert.True(t, ok)
+	assert.Eq(t, 3, val)
+	assert.True(t, c.Contains("c"))
+	assert.Eq(t, []string{"b", "a", "c"}, c.Keys())
+
+	key, val, ok := c.Oldest()
+	assert.True(t, ok)
+	assert.Eq(t, "c", key)
+	assert.Eq(t, 3, val)
+
+	// evict the least recently used
+	assert.True(t, c.Set("d", 4))
+	assert.Eq(t, []string{"c"}, evicted)
+	_, ok = c.Get("c")
+	assert.False(t, ok)
+	_, ok = c.Peek("c")
+	assert.False(t, ok)
+	assert.Eq(t, 3, c.Len())
+	assert.Eq(t, 3, c.Cap())
+
+	// delete and remove oldest are not evictions
+	assert.True(t, c.Delete("b"))
+	assert.False(t, c.Delete("b"))
+	key, val, ok = c.RemoveOldest()
+	assert.True(t, ok)
+	assert.Eq(t, "a", key)
+	assert.Eq(t, 10, val)
+	assert.Eq(t, []string{"d"}, c.Keys())
+	assert.Eq(t, []string{"c"}, evicted)
+
+	c.Clear()
+	assert.Eq(t, 0, c.Len())
+	_, _, ok = c.Oldest()
+	assert.False(t, ok)
+	_, _, ok = c.RemoveOldest()
+	assert.False(t, ok)
+
+	assert.Panics(t, func() {
+		lru.New[string, int](0)
+	})
+}
+
+func TestCache_Resize(t *testing.T) {
+	var evicted []int
+	c := lru.New[int, int](5, lru.WithOnEvict(func(key, val int) {
+		evicted = append(evicted, key)
+	}))
+	for i := 1; i <= 5; i++ {
+		c.Set(i, i*10)
+	}
+
+	assert.Eq(t, 0, c.Resize(10))
+	assert.Eq(t, 3, c.Resize(2))
+	assert.Eq(t, []int{1, 2, 3}, evicted)
+	assert.Eq(t, []int{5, 4}, c.Keys())
+	assert.Eq(t, 2, c.Cap())
+
+	assert.Panics(t, func() {
+		c.Resize(-1)
+	})
+}
+
+func TestCache_Range(t *testing.T) {
+	c := lru.New[string, int](10)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+
+	// no evict callback
+	c2 := lru.New[int, int](1)
+	assert.False(t, c2.Set(1, 1))
+	assert.True(t, c2.Set(2, 2))
+	assert.Eq(t, 0, c2.Resize(1))
+
+	var keys []string
+	c.Range(func(key string, val int) bool {
+		keys = append(keys, key)
+		// safe to call the cache methods
+		c.Delete(key)
+		return key != "b"
+	})
+	assert.Eq(t, []string{"c", "b"}, keys)
+	assert.Eq(t, []string{"a"}, c.Keys())
+}
@@ -0,0 +1,208 @@
+package lsched
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule describes the run times of a job.
+type Schedule interface {
+	// Next get the next run time after t, zero time for no more runs.
+	Next(t time.Time) time.Time
+}
+
+// Interval get a schedule to run on every interval, the interval must be greater than 0.
+func Interval(d time.Duration) Schedule { return intervalSchedule(d) }
+
+// intervalSchedule 固定间隔的调度
+type intervalSchedule time.Duration
+
+// Next implements Schedule
+func (d intervalSchedule) Next(t time.Time) time.Time { return t.Add(time.Duration(d)) }
+
+// cron 字段的取值范围
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [5]cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// cronDescriptors 预定义的 cron 表达式
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSchedule 解析后的 cron 表达式. 每个字段使用位图记录允许的值
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// 日期和星期都有限制时，满足其一即可(与标准 cron 一致)
+	domStar, dowStar bool
+}
+
+// ParseCron parse the standard 5 fields cron expression: "minute hour day-of-month month day-of-week".
+// The times are evaluated in the location of the time passed to Next.
+//
+// Field syntax: '*', a value '5', a range '1-5', a step '*/15' or '1-30/5', and a list '1,3,5'.
+// The day of week is 0-6 (Sunday=0, 7 is also accepted as Sunday).
+//
+// Also supports the descriptors: @yearly, @monthly, @weekly, @daily, @hourly and "@every <duration>".
+//
+// Usage:
+//
+//	sched, err := lsched.ParseCron("*/5 9-18 * * 1-5") // every 5 minutes in working hours
+func ParseCron(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(expr[len("@every "):]))
+		if err != nil {
+			return nil, fmt.Errorf("lsched: invalid cron expression %q: %w", expr, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("lsched: invalid cron expression %q: duration must be greater than 0", expr)
+		}
+		return Interval(d), nil
+	}
+	if s, ok := cronDescriptors[expr]; ok {
+		expr = s
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("lsched: invalid cron expression %q: expect 5 fields", expr)
+	}
+
+	var bits [5]uint64
+	for i, f := range fields {
+		b, err := parseCronField(f, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("lsched: invalid cron expression %q: %w", expr, err)
+		}
+		bits[i] = b
+	}
+
+	// 星期 7 等同于 0
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+	return &cronSchedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: fields[2] == "*" || fields[2] == "?",
+		dowStar: fields[4] == "*" || fields[4] == "?",
+	}, nil
+}
+
+// MustCron parse the cron expression, panics on error. see ParseCron
+func MustCron(expr string) Schedule {
+	s, err := ParseCron(expr)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// parseCronField 解析单个字段为位图
+func parseCronField(s string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, step := f.min, f.max, 1
+		rng := part
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field: %q", f.name, part)
+			}
+			step, rng = n, part[:i]
+		}
+
+		switch {
+		case rng == "*" || rng == "?":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(a)
+			hi, err2 = strconv.Atoi(b)
+			if err := errors.Join(err1, err2); err != nil {
+				return 0, fmt.Errorf("invalid range in %s field: %q", f.name, part)
+			}
+		default:
+			n, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value in %s field: %q", f.name, part)
+			}
+			lo, hi = n, n
+			// "5/10" 表示从 5 开始每 10 个
+			if step > 1 {
+				hi = f.max
+			}
+		}
+
+		maxVal := f.max
+		if f.name == "day of week" {
+			maxVal = 7
+		}
+		if lo < f.min || hi > maxVal || lo > hi {
+			return 0, fmt.Errorf("%s field out of range [%d, %d]: %q", f.name, f.min, f.max, part)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// has 检查值是否在位图中
+func has(bits uint64, v int) bool { return bits&(1<<v) != 0 }
+
+// Next implements Schedule
+func (c *cronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// 最多向后查找 5 年，避免不可能满足的表达式(如 2 月 30 日)死循环
+	yearLimit := t.Year() + 5
+	for t.Year() <= yearLimit {
+		y, m, d := t.Date()
+		switch {
+		case !has(c.month, int(m)):
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatch(t):
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+		case !has(c.hour, t.Hour()):
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, loc)
+		case !has(c.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatch 检查日期是否匹配. 日期和星期都有限制时满足其一即可
+func (c *cronSchedule) dayMatch(t time.Time) bool {
+	domOk, dowOk := has(c.dom, t.Day()), has(c.dow, int(t.Weekday()))
+	if c.domStar || c.dowStar {
+		return domOk && dowOk
+	}
+	return domOk || dowOk
+}
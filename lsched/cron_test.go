@@ -0,0 +1,56 @@
+package lsched_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lsched"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestParseCron(t *testing.T) {
+	// 2024-01-15 10:20:30 Monday
+	base := time.Date(2024, 1, 15, 10, 20, 30, 0, time.UTC)
+	tests := []struct {
+		expr, want string
+	}{
+		{"* * * * *", "2024-01-15 10:21"},
+		{"*/15 * * * *", "2024-01-15 10:30"},
+		{"0 3 * * *", "2024-01-16 03:00"},
+		{"30 9-18 * * 1-5", "2024-01-15 10:30"},
+		{"0 0 1 * *", "2024-02-01 00:00"},
+		{"0 12 * * 0", "2024-01-21 12:00"},
+		{"0 12 * * 7", "2024-01-21 12:00"},
+		{"5,10 0 29 2 *", "2024-02-29 00:05"},
+		{"0 0 13 * 5", "2024-01-19 00:00"}, // day of month or Friday
+		{"5/20 10 * * *", "2024-01-15 10:25"},
+		{"@hourly", "2024-01-15 11:00"},
+		{"@daily", "2024-01-16 00:00"},
+		{"@weekly", "2024-01-21 00:00"},
+		{"@yearly", "2025-01-01 00:00"},
+	}
+
+	for _, tt := range tests {
+		s, err := lsched.ParseCron(tt.expr)
+		assert.NoErr(t, err, tt.expr)
+		assert.Eq(t, tt.want, s.Next(base).Format("2006-01-02 15:04"), tt.expr)
+	}
+
+	// @every
+	s := lsched.MustCron("@every 90s")
+	assert.Eq(t, base.Add(90*time.Second), s.Next(base))
+
+	// never matched
+	s = lsched.MustCron("0 0 30 2 *")
+	assert.True(t, s.Next(base).IsZero())
+
+	// invalid
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *",
+		"a * * * *", "5-1 * * * *", "1-x * * * *", "@every", "@every -1s", "@every 1x"} {
+		_, err := lsched.ParseCron(expr)
+		assert.Err(t, err, expr)
+	}
+	assert.Panics(t, func() {
+		lsched.MustCron("bad")
+	})
+}
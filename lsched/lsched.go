@@ -0,0 +1,188 @@
+// Package lsched provides a scheduler for the recurring jobs, with fixed interval,
+// cron expressions, random jitter and context cancellation.
+//
+// Usage:
+//
+//	s := lsched.New()
+//	defer s.Stop()
+//
+//	s.Add(lsched.Interval(time.Minute), func(ctx context.Context) { syncData(ctx) }, lsched.WithJitter(5*time.Second))
+//	_, err := s.Cron("0 3 * * *", func(ctx context.Context) { cleanup(ctx) })
+//
+// The scheduler implements lcache.Scheduler, can be used to run the cache maintenance jobs:
+//
+//	c := lcache.New(lcache.WithScheduler(s), lcache.WithJanitor(time.Minute))
+package lsched
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Options for the scheduler
+type Options struct {
+	// Context the parent context of the jobs, the scheduler is stopped when it is done.
+	Context context.Context
+	// Jitter the default max random delay added to each run, 0 to disable. see WithJitter
+	Jitter time.Duration
+	// PanicHandler handle the job panic, the job keeps scheduled. If nil, the panic is not recovered.
+	PanicHandler func(val any)
+}
+
+// OptionFn option func for New
+type OptionFn func(o *Options)
+
+// WithContext set the parent context of the jobs, the scheduler is stopped when it is done.
+func WithContext(ctx context.Context) OptionFn {
+	return func(o *Options) { o.Context = ctx }
+}
+
+// WithDefaultJitter set the default max random delay added to each run of the jobs.
+func WithDefaultJitter(d time.Duration) OptionFn {
+	return func(o *Options) { o.Jitter = d }
+}
+
+// WithPanicHandler set the handler of the job panic
+func WithPanicHandler(fn func(val any)) OptionFn {
+	return func(o *Options) { o.PanicHandler = fn }
+}
+
+// JobOption option func for Scheduler.Add
+type JobOption func(j *job)
+
+// WithJitter add a random delay in [0, d) to each run of the job, to avoid many jobs run
+// at the same time(eg: the janitors of many caches).
+func WithJitter(d time.Duration) JobOption {
+	return func(j *job) { j.jitter = d }
+}
+
+// job 调度任务
+type job struct {
+	sched  Schedule
+	fn     func(ctx context.Context)
+	jitter time.Duration
+}
+
+// Scheduler runs the jobs by their schedules, each job runs in its own goroutine.
+// The next run time is calculated after the current run finished, so the runs of
+// a job never overlap.
+type Scheduler struct {
+	opt    Options
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New create a scheduler
+func New(optFns ...OptionFn) *Scheduler {
+	opt := Options{Context: context.Background()}
+	for _, fn := range optFns {
+		fn(&opt)
+	}
+
+	s := &Scheduler{opt: opt}
+	s.ctx, s.cancel = context.WithCancel(opt.Context)
+	return s
+}
+
+// Add a job with the schedule, returns the func to stop it. The ctx passed to fn is
+// canceled when the job is stopped or the scheduler is stopped.
+func (s *Scheduler) Add(sched Schedule, fn func(ctx context.Context), opts ...JobOption) (stop func()) {
+	j := &job{sched: sched, fn: fn, jitter: s.opt.Jitter}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx, j)
+	}()
+	return cancel
+}
+
+// Cron add a job with the cron expression, returns the func to stop it. see ParseCron
+func (s *Scheduler) Cron(expr string, fn func(ctx context.Context), opts ...JobOption) (stop func(), err error) {
+	sched, err := ParseCron(expr)
+	if err != nil {
+		return nil, err
+	}
+	return s.Add(sched, fn, opts...), nil
+}
+
+// Every run fn on every interval, returns a func to stop it. implements lcache.Scheduler
+func (s *Scheduler) Every(interval time.Duration, fn func()) (stop func()) {
+	return s.Add(Interval(interval), func(context.Context) { fn() })
+}
+
+// After run fn once after the duration, returns a func to cancel it. implements lcache.Scheduler
+func (s *Scheduler) After(d time.Duration, fn func()) (stop func()) {
+	return s.Add(&onceSchedule{d: d}, func(context.Context) { fn() })
+}
+
+// Stop all jobs and wait for the running ones done. The ctx of the running jobs are canceled.
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// run 按调度时间循环执行任务，直到 ctx 取消或没有下次执行时间
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		next := j.sched.Next(time.Now())
+		if next.IsZero() {
+			return
+		}
+
+		wait := time.Until(next)
+		if j.jitter > 0 {
+			wait += rand.N(j.jitter)
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		// 同时到期和停止时，不再执行
+		if ctx.Err() != nil {
+			return
+		}
+		s.call(ctx, j)
+	}
+}
+
+// call 执行任务，配置了 PanicHandler 时恢复 panic
+func (s *Scheduler) call(ctx context.Context, j *job) {
+	if s.opt.PanicHandler != nil {
+		defer func() {
+			if val := recover(); val != nil {
+				s.opt.PanicHandler(val)
+			}
+		}()
+	}
+	j.fn(ctx)
+}
+
+// onceSchedule 只执行一次的调度
+type onceSchedule struct {
+	d    time.Duration
+	done bool
+}
+
+// Next implements Schedule
+func (o *onceSchedule) Next(t time.Time) time.Time {
+	if o.done {
+		return time.Time{}
+	}
+	o.done = true
+	return t.Add(o.d)
+}
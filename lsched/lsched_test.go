@@ -0,0 +1,102 @@
+package lsched_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/lsched"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestScheduler_Add(t *testing.T) {
+	s := lsched.New()
+	defer s.Stop()
+
+	var n, jittered atomic.Int32
+	stop := s.Add(lsched.Interval(5*time.Millisecond), func(ctx context.Context) {
+		n.Add(1)
+	})
+	s.Add(lsched.Interval(5*time.Millisecond), func(ctx context.Context) {
+		jittered.Add(1)
+	}, lsched.WithJitter(5*time.Millisecond))
+
+	time.Sleep(30 * time.Millisecond)
+	stop()
+	time.Sleep(2 * time.Millisecond)
+	got := n.Load()
+	assert.Gt(t, got, int32(2))
+	assert.Gt(t, jittered.Load(), int32(1))
+
+	time.Sleep(15 * time.Millisecond)
+	assert.Eq(t, got, n.Load())
+}
+
+func TestScheduler_Stop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var recovered atomic.Value
+	s := lsched.New(lsched.WithContext(ctx), lsched.WithDefaultJitter(time.Millisecond),
+		lsched.WithPanicHandler(func(val any) { recovered.Store(val) }))
+
+	// running job ctx is canceled on stop
+	started := make(chan struct{})
+	var canceled atomic.Bool
+	s.Add(lsched.Interval(time.Millisecond), func(ctx context.Context) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		canceled.Store(true)
+	})
+
+	// panic is recovered, the job keeps scheduled
+	var panics atomic.Int32
+	s.Add(lsched.Interval(2*time.Millisecond), func(ctx context.Context) {
+		panics.Add(1)
+		panic("oops")
+	})
+
+	<-started
+	time.Sleep(15 * time.Millisecond)
+	cancel() // parent ctx done
+	s.Stop()
+	assert.True(t, canceled.Load())
+	assert.Eq(t, "oops", recovered.Load())
+	assert.Gt(t, panics.Load(), int32(1))
+}
+
+func TestScheduler_Cron(t *testing.T) {
+	s := lsched.New()
+	defer s.Stop()
+
+	stop, err := s.Cron("@every 5ms", func(ctx context.Context) {})
+	assert.NoErr(t, err)
+	stop()
+
+	_, err = s.Cron("bad", func(ctx context.Context) {})
+	assert.Err(t, err)
+}
+
+func TestScheduler_lcache(t *testing.T) {
+	s := lsched.New()
+	defer s.Stop()
+
+	// After
+	var fired atomic.Bool
+	s.After(5*time.Millisecond, func() { fired.Store(true) })
+	cancelFn := s.After(5*time.Millisecond, func() { t.Error("should be canceled") })
+	cancelFn()
+
+	// as the lcache scheduler, run the janitor
+	c := lcache.New(lcache.WithScheduler(s), lcache.WithJanitor(5*time.Millisecond))
+	defer c.Close()
+	c.Set("key", 1, time.Millisecond)
+	assert.Eq(t, 1, c.Len())
+
+	time.Sleep(30 * time.Millisecond)
+	assert.True(t, fired.Load())
+	assert.Eq(t, 0, c.Len())
+}
@@ -0,0 +1,405 @@
+// Package lsession provides the cookie-based HTTP session management, the session data
+// is stored in a lcache.Cache with sliding TTL.
+//
+// Usage:
+//
+//	store := lsession.New(lcache.New(lcache.WithCapacity(100000)), lsession.WithIdleTimeout(30*time.Minute))
+//	http.ListenAndServe(":8080", store.Middleware(mux))
+//
+//	// in handler
+//	sess := lsession.FromContext(r.Context())
+//	sess.Set("uid", 1001)
+//	sess.Rotate() // renew the session ID after login
+//	sess.AddFlash("msg", "login success")
+package lsession
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+)
+
+// ErrNoSession no session in the request context. see FromContext
+var ErrNoSession = errors.New("lsession: no session in context")
+
+// idLen 会话 ID 的随机字节数
+const idLen = 32
+
+// Options for the session store
+type Options struct {
+	// CookieName the name of the session cookie. default is "sid"
+	CookieName string
+	// CookiePath the path of the session cookie. default is "/"
+	CookiePath   string
+	CookieDomain string
+	// CookieSecure only send the cookie over HTTPS
+	CookieSecure bool
+	// CookieSameSite default is http.SameSiteLaxMode
+	CookieSameSite http.SameSite
+	// IdleTimeout the session expires after no request for the duration, refreshed on every
+	// request(sliding TTL). default is 30 minutes
+	IdleTimeout time.Duration
+	// AbsoluteTimeout the session expires after the duration since created, regardless
+	// of activity. 0 to disable. default is 24 hours
+	AbsoluteTimeout time.Duration
+	// KeyPrefix the key prefix of the sessions in the cache. default is "sess:"
+	KeyPrefix string
+}
+
+// OptionFn option func for New
+type OptionFn func(o *Options)
+
+// WithCookieName set the name of the session cookie
+func WithCookieName(name string) OptionFn {
+	return func(o *Options) { o.CookieName = name }
+}
+
+// WithCookie set the path, domain and secure flag of the session cookie
+func WithCookie(path, domain string, secure bool) OptionFn {
+	return func(o *Options) {
+		o.CookiePath, o.CookieDomain, o.CookieSecure = path, domain, secure
+	}
+}
+
+// WithIdleTimeout set the idle timeout of the sessions
+func WithIdleTimeout(d time.Duration) OptionFn {
+	return func(o *Options) { o.IdleTimeout = d }
+}
+
+// WithAbsoluteTimeout set the absolute timeout of the sessions, 0 to disable
+func WithAbsoluteTimeout(d time.Duration) OptionFn {
+	return func(o *Options) { o.AbsoluteTimeout = d }
+}
+
+// WithKeyPrefix set the key prefix of the sessions in the cache
+func WithKeyPrefix(prefix string) OptionFn {
+	return func(o *Options) { o.KeyPrefix = prefix }
+}
+
+// record 缓存中保存的会话数据
+type record struct {
+	Data    map[string]any   `json:"data"`
+	Flashes map[string][]any `json:"flashes,omitempty"`
+	// Created 创建时间 millitime
+	Created int64 `json:"created"`
+}
+
+// Store the session store backed by lcache.
+type Store struct {
+	opt   Options
+	cache *lcache.Cache
+}
+
+// New create a session store with the cache
+func New(c *lcache.Cache, opts ...OptionFn) *Store {
+	s := &Store{
+		cache: c,
+		opt: Options{
+			CookieName:      "sid",
+			CookiePath:      "/",
+			CookieSameSite:  http.SameSiteLaxMode,
+			IdleTimeout:     30 * time.Minute,
+			AbsoluteTimeout: 24 * time.Hour,
+			KeyPrefix:       "sess:",
+		},
+	}
+	for _, fn := range opts {
+		fn(&s.opt)
+	}
+	return s
+}
+
+// Options get the options of the store
+func (s *Store) Options() Options { return s.opt }
+
+// Load the session of the request by the cookie. Returns a new session if the cookie is
+// missing or the session is expired.
+func (s *Store) Load(r *http.Request) *Session {
+	if ck, err := r.Cookie(s.opt.CookieName); err == nil && validID(ck.Value) {
+		if sess := s.find(ck.Value); sess != nil {
+			return sess
+		}
+	}
+	return s.newSession()
+}
+
+// find 按 ID 查找未过期的会话
+func (s *Store) find(id string) *Session {
+	val, ok := s.cache.Get(s.opt.KeyPrefix + id)
+	if !ok {
+		return nil
+	}
+	rec, ok := val.(*record)
+	if !ok {
+		return nil
+	}
+
+	if s.opt.AbsoluteTimeout > 0 && time.Now().UnixMilli()-rec.Created > s.opt.AbsoluteTimeout.Milliseconds() {
+		s.cache.Delete(s.opt.KeyPrefix + id)
+		return nil
+	}
+
+	// 复制数据，保存前不影响缓存中的会话
+	return &Session{id: id, data: maps.Clone(rec.Data), flashes: cloneFlashes(rec.Flashes), created: rec.Created}
+}
+
+func (s *Store) newSession() *Session {
+	return &Session{
+		id:      newID(),
+		data:    make(map[string]any),
+		flashes: make(map[string][]any),
+		created: time.Now().UnixMilli(),
+		isNew:   true,
+	}
+}
+
+// Save the session to the cache and write the cookie if needed. The TTL of an unchanged
+// session is refreshed. The destroyed session is deleted and the cookie is expired.
+//
+// A new session is saved and its cookie is sent only after the data is set or Rotate is
+// called, so the requests without cookie(eg: crawlers) do not fill the cache and evict
+// the sessions of the logged-in users.
+//
+// NOTE: it must be called before the response header is written. The Middleware calls it automatically.
+func (s *Store) Save(w http.ResponseWriter, sess *Session) error {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if sess.oldID != "" {
+		s.cache.Delete(s.opt.KeyPrefix + sess.oldID)
+		sess.oldID = ""
+	}
+
+	if sess.destroyed {
+		s.cache.Delete(s.opt.KeyPrefix + sess.id)
+		s.setCookie(w, "", -1)
+		return nil
+	}
+	// 新会话没有数据时不保存
+	if sess.isNew && !sess.changed {
+		return nil
+	}
+
+	key := s.opt.KeyPrefix + sess.id
+	if sess.changed || !s.cache.Touch(key, s.opt.IdleTimeout) {
+		rec := &record{Data: maps.Clone(sess.data), Flashes: cloneFlashes(sess.flashes), Created: sess.created}
+		if err := s.cache.SetE(key, rec, s.opt.IdleTimeout); err != nil {
+			return err
+		}
+		sess.changed = false
+	}
+
+	if sess.isNew || sess.sendCookie {
+		s.setCookie(w, sess.id, 0)
+		sess.isNew, sess.sendCookie = false, false
+	}
+	return nil
+}
+
+// Delete the session by ID, eg: for logout all devices.
+func (s *Store) Delete(id string) { s.cache.Delete(s.opt.KeyPrefix + id) }
+
+func (s *Store) setCookie(w http.ResponseWriter, id string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.opt.CookieName,
+		Value:    id,
+		Path:     s.opt.CookiePath,
+		Domain:   s.opt.CookieDomain,
+		MaxAge:   maxAge,
+		Secure:   s.opt.CookieSecure,
+		HttpOnly: true,
+		SameSite: s.opt.CookieSameSite,
+	})
+}
+
+// ctxKey 会话在请求 context 中的 key
+type ctxKey struct{}
+
+// Middleware load the session into the request context and save it before the response
+// header is written. Use FromContext to get the session in handlers.
+func (s *Store) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess := s.Load(r)
+		sw := &saveWriter{ResponseWriter: w, save: func() { _ = s.Save(w, sess) }}
+		next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), ctxKey{}, sess)))
+		sw.saveOnce()
+	})
+}
+
+// FromContext get the session loaded by the Middleware, nil if not exists.
+func FromContext(ctx context.Context) *Session {
+	sess, _ := ctx.Value(ctxKey{}).(*Session)
+	return sess
+}
+
+// MustFromContext get the session loaded by the Middleware, panics with ErrNoSession if not exists.
+func MustFromContext(ctx context.Context) *Session {
+	if sess := FromContext(ctx); sess != nil {
+		return sess
+	}
+	panic(ErrNoSession)
+}
+
+// saveWriter 在第一次写入响应头之前保存会话
+type saveWriter struct {
+	http.ResponseWriter
+	save  func()
+	saved bool
+}
+
+func (w *saveWriter) saveOnce() {
+	if !w.saved {
+		w.saved = true
+		w.save()
+	}
+}
+
+// WriteHeader implements http.ResponseWriter
+func (w *saveWriter) WriteHeader(code int) {
+	w.saveOnce()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write implements http.ResponseWriter
+func (w *saveWriter) Write(b []byte) (int, error) {
+	w.saveOnce()
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap for http.ResponseController
+func (w *saveWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// Session the data of a client session, it is goroutine-safe.
+type Session struct {
+	mu      sync.Mutex
+	id      string
+	data    map[string]any
+	flashes map[string][]any
+	created int64
+	// oldID Rotate 之前的 ID，保存时删除
+	oldID string
+	// isNew 新建的会话; changed 数据有修改; sendCookie 需要写入 cookie
+	isNew, changed, sendCookie bool
+	destroyed                  bool
+}
+
+// ID get the session ID
+func (s *Session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.id
+}
+
+// IsNew check if the session is created in the current request
+func (s *Session) IsNew() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.isNew
+}
+
+// CreatedAt get the time of the session created
+func (s *Session) CreatedAt() time.Time { return time.UnixMilli(s.created) }
+
+// Get value by key
+func (s *Session) Get(key string) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	val, ok := s.data[key]
+	return val, ok
+}
+
+// Set value by key
+func (s *Session) Set(key string, val any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = val
+	s.changed = true
+}
+
+// Delete value by key
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data[key]; ok {
+		delete(s.data, key)
+		s.changed = true
+	}
+}
+
+// Values get a copy of all values
+func (s *Session) Values() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.data)
+}
+
+// AddFlash add a flash value by key, it is removed after read by Flashes.
+func (s *Session) AddFlash(key string, val any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flashes[key] = append(s.flashes[key], val)
+	s.changed = true
+}
+
+// Flashes get and remove the flash values of the key.
+func (s *Session) Flashes(key string) []any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vals, ok := s.flashes[key]
+	if ok {
+		delete(s.flashes, key)
+		s.changed = true
+	}
+	return vals
+}
+
+// Rotate renew the session ID and keep the data, the old ID is invalid after saved.
+// Should be called after the privilege level changes(eg: login) to prevent session fixation.
+func (s *Session) Rotate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.isNew && s.oldID == "" {
+		s.oldID = s.id
+	}
+	s.id = newID()
+	s.changed, s.sendCookie = true, true
+}
+
+// Destroy the session, the data is deleted and the cookie is expired after saved.
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.destroyed = true
+}
+
+// cloneFlashes 深拷贝 flash 数据，避免 append 共享底层数组
+func cloneFlashes(src map[string][]any) map[string][]any {
+	dst := make(map[string][]any, len(src))
+	for k, v := range src {
+		dst[k] = append([]any(nil), v...)
+	}
+	return dst
+}
+
+// newID 生成随机的会话 ID
+func newID() string {
+	b := make([]byte, idLen)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// validID 检查 cookie 中的会话 ID 格式
+func validID(id string) bool {
+	if len(id) != base64.RawURLEncoding.EncodedLen(idLen) {
+		return false
+	}
+	_, err := base64.RawURLEncoding.DecodeString(id)
+	return err == nil
+}
@@ -0,0 +1,186 @@
+package lsession_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/lsession"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+// serve 发送请求，返回响应的 body 和 cookie
+func serve(h http.Handler, cookie *http.Cookie) (string, *http.Cookie) {
+	r := httptest.NewRequest("GET", "/", nil)
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	body, _ := io.ReadAll(w.Result().Body)
+	var ck *http.Cookie
+	if cks := w.Result().Cookies(); len(cks) > 0 {
+		ck = cks[0]
+	}
+	return string(body), ck
+}
+
+func TestStore_Middleware(t *testing.T) {
+	c := lcache.New()
+	store := lsession.New(c, lsession.WithCookieName("test_sid"))
+	assert.Eq(t, "test_sid", store.Options().CookieName)
+
+	var action string
+	h := store.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess := lsession.MustFromContext(r.Context())
+		switch action {
+		case "set":
+			sess.Set("theme", "dark")
+		case "login":
+			sess.Set("uid", 1001)
+			sess.Rotate()
+			sess.AddFlash("msg", "welcome")
+		case "logout":
+			sess.Destroy()
+		}
+
+		uid, _ := sess.Get("uid")
+		fmt.Fprintf(w, "%v %v %v", sess.IsNew(), uid, sess.Flashes("msg"))
+	}))
+
+	// new session, not saved until the data is set
+	body, ck := serve(h, nil)
+	assert.Eq(t, "true <nil> []", body)
+	assert.Nil(t, ck)
+	assert.Eq(t, 0, c.Len())
+
+	action = "set"
+	body, ck = serve(h, nil)
+	assert.Eq(t, "true <nil> []", body)
+	assert.NotNil(t, ck)
+	assert.Eq(t, 1, c.Len())
+	assert.True(t, ck.HttpOnly)
+	assert.Eq(t, "/", ck.Path)
+
+	// no cookie for the existing session
+	action = ""
+	body, ck2 := serve(h, ck)
+	assert.Eq(t, "false <nil> []", body)
+	assert.Nil(t, ck2)
+
+	// login: rotate the ID, the old one is invalid
+	action = "login"
+	body, newCk := serve(h, ck)
+	assert.Eq(t, "false 1001 [welcome]", body)
+	assert.NotNil(t, newCk)
+	assert.NotEq(t, ck.Value, newCk.Value)
+
+	// flash is removed after read
+	action = ""
+	body, _ = serve(h, newCk)
+	assert.Eq(t, "false 1001 []", body)
+	body, _ = serve(h, ck)
+	assert.Eq(t, "true <nil> []", body)
+
+	// logout
+	action = "logout"
+	_, ck = serve(h, newCk)
+	assert.Eq(t, -1, ck.MaxAge)
+	action = ""
+	body, _ = serve(h, newCk)
+	assert.Eq(t, "true <nil> []", body)
+
+	// invalid cookie value
+	body, _ = serve(h, &http.Cookie{Name: "test_sid", Value: "../bad"})
+	assert.Eq(t, "true <nil> []", body)
+
+	assert.Nil(t, lsession.FromContext(context.Background()))
+	assert.Panics(t, func() {
+		lsession.MustFromContext(context.Background())
+	})
+}
+
+func TestStore_timeout(t *testing.T) {
+	c := lcache.New()
+	store := lsession.New(c, lsession.WithIdleTimeout(60*time.Millisecond),
+		lsession.WithAbsoluteTimeout(120*time.Millisecond), lsession.WithKeyPrefix("s:"))
+
+	h := store.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess := lsession.FromContext(r.Context())
+		if sess.IsNew() {
+			sess.Set("n", 1)
+		}
+		n, _ := sess.Get("n")
+		fmt.Fprint(w, n)
+	}))
+
+	_, ck := serve(h, nil)
+	sid := ck.Value
+	_, ok := c.Get("s:" + sid)
+	assert.True(t, ok)
+
+	// sliding idle TTL: keep alive by requests
+	for i := 0; i < 3; i++ {
+		time.Sleep(30 * time.Millisecond)
+		_, ck2 := serve(h, ck)
+		assert.Nil(t, ck2)
+	}
+
+	// absolute timeout
+	time.Sleep(40 * time.Millisecond)
+	_, ck2 := serve(h, ck)
+	assert.NotNil(t, ck2)
+	assert.NotEq(t, sid, ck2.Value)
+
+	// idle timeout
+	time.Sleep(80 * time.Millisecond)
+	_, ck3 := serve(h, ck2)
+	assert.NotNil(t, ck3)
+	assert.NotEq(t, ck2.Value, ck3.Value)
+
+	// delete by ID
+	store.Delete(ck3.Value)
+	_, ck4 := serve(h, ck3)
+	assert.NotNil(t, ck4)
+}
+
+func TestSession_values(t *testing.T) {
+	store := lsession.New(lcache.New())
+	sess := store.Load(httptest.NewRequest("GET", "/", nil))
+	assert.True(t, sess.IsNew())
+
+	// the empty new session is not saved
+	w := httptest.NewRecorder()
+	assert.NoErr(t, store.Save(w, sess))
+	assert.True(t, sess.IsNew())
+	assert.Len(t, w.Result().Cookies(), 0)
+
+	assert.NotEmpty(t, sess.ID())
+	assert.False(t, sess.CreatedAt().IsZero())
+
+	sess.Set("a", 1)
+	sess.Set("b", 2)
+	sess.Delete("b")
+	assert.Eq(t, map[string]any{"a": 1}, sess.Values())
+
+	sess.AddFlash("msg", "a")
+	sess.AddFlash("msg", "b")
+	assert.Eq(t, []any{"a", "b"}, sess.Flashes("msg"))
+	assert.Nil(t, sess.Flashes("msg"))
+
+	// rotate a new session, no old data to delete
+	id := sess.ID()
+	sess.Rotate()
+	assert.NotEq(t, id, sess.ID())
+
+	w = httptest.NewRecorder()
+	assert.NoErr(t, store.Save(w, sess))
+	assert.False(t, sess.IsNew())
+	assert.Len(t, w.Result().Cookies(), 1)
+}
@@ -0,0 +1,618 @@
+// Package lstore provides a small bitcask-style persistent key-value store.
+//
+// The records are appended to the data files in a directory, an in-memory index maps
+// each key to the position of its latest record, so a read costs at most one disk seek.
+// The index is rebuilt by scanning the data files on Open, and the space of the
+// overwritten and deleted records is reclaimed by Compact.
+//
+// Usage:
+//
+//	db, err := lstore.Open("/data/mydb", lstore.WithMaxFileSize(16<<20))
+//	defer db.Close()
+//
+//	err = db.Put("key", []byte("value"))
+//	val, err := db.Get("key")
+//
+// As the durable Store backend of lcache write-through mode:
+//
+//	c := lcache.New(lcache.WithStore(db.AsStore()))
+package lstore
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound the key does not exist or is expired
+	ErrNotFound = errors.New("lstore: key not found")
+	// ErrClosed the store is closed
+	ErrClosed = errors.New("lstore: store is closed")
+	// ErrEmptyKey the key is empty
+	ErrEmptyKey = errors.New("lstore: key is empty")
+	// ErrCorrupted the data file is corrupted
+	ErrCorrupted = errors.New("lstore: data file is corrupted")
+)
+
+// dataExt 数据文件扩展名
+const dataExt = ".data"
+
+// Options for the store
+type Options struct {
+	// MaxFileSize the max size of a data file, a new file is created when the active file
+	// exceeds it. default is 64MB
+	MaxFileSize int64
+	// SyncWrites fsync the active file after each write. default is false, the data is
+	// flushed by the OS or on Sync and Close.
+	SyncWrites bool
+	// Serializer name of the registered lcache serializer for AsStore. default is "json"
+	Serializer string
+}
+
+// OptionFn option func for Open
+type OptionFn func(o *Options)
+
+// WithMaxFileSize set the max size of a data file
+func WithMaxFileSize(size int64) OptionFn {
+	return func(o *Options) { o.MaxFileSize = size }
+}
+
+// WithSyncWrites fsync the active file after each write
+func WithSyncWrites(sync bool) OptionFn {
+	return func(o *Options) { o.SyncWrites = sync }
+}
+
+// WithSerializer set the serializer for AsStore, it must be registered in lcache.
+// see lcache.SetSerializer
+func WithSerializer(name string) OptionFn {
+	return func(o *Options) { o.Serializer = name }
+}
+
+// Stats of the store
+type Stats struct {
+	// Keys the number of the keys, includes the expired ones not cleaned yet
+	Keys int
+	// Files the number of the data files
+	Files int
+	// Size the total size of the data files
+	Size int64
+	// Garbage the size of the overwritten, deleted and expired records, can be reclaimed by Compact
+	Garbage int64
+}
+
+// entry 索引项: 记录所在的文件和位置
+type entry struct {
+	fid    int
+	offset int64
+	size   int64
+	// expire 过期时间 millitime, 0 表示永不过期
+	expire int64
+}
+
+func (e *entry) expired(nowUm int64) bool { return e.expire > 0 && e.expire <= nowUm }
+
+// DB the append-log persistent key-value store. It is goroutine-safe.
+type DB struct {
+	opt Options
+	dir string
+
+	mu     sync.RWMutex
+	index  map[string]*entry
+	files  map[int]*os.File
+	active *os.File
+	// activeID 当前写入的文件 ID, activeSize 当前写入文件的大小
+	activeID   int
+	activeSize int64
+	size       int64
+	garbage    int64
+	closed     bool
+}
+
+// Open the store in the directory, creates it if not exists. The index is rebuilt by
+// scanning the data files, a corrupted tail of the last file(eg: crashed in writing)
+// is truncated.
+func Open(dir string, optFns ...OptionFn) (*DB, error) {
+	opt := Options{MaxFileSize: 64 << 20, Serializer: "json"}
+	for _, fn := range optFns {
+		fn(&opt)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	db := &DB{
+		opt:   opt,
+		dir:   dir,
+		index: make(map[string]*entry),
+		files: make(map[int]*os.File),
+	}
+	if err := db.load(); err != nil {
+		db.closeFiles()
+		return nil, err
+	}
+	return db, nil
+}
+
+// load 按文件 ID 顺序扫描数据文件，重建索引
+func (db *DB) load() error {
+	ids, err := db.fileIDs()
+	if err != nil {
+		return err
+	}
+
+	for i, fid := range ids {
+		f, err := os.OpenFile(db.fileName(fid), os.O_RDWR, 0644)
+		if err != nil {
+			return err
+		}
+		db.files[fid] = f
+
+		end, err := db.scan(fid, f)
+		if err != nil {
+			if i < len(ids)-1 || !errors.Is(err, ErrCorrupted) {
+				return fmt.Errorf("%w: %s", err, f.Name())
+			}
+			// 最后一个文件尾部损坏，截断
+			if err = f.Truncate(end); err != nil {
+				return err
+			}
+		}
+		db.size += end
+		db.activeID, db.activeSize = fid, end
+	}
+
+	if len(ids) == 0 {
+		return db.rotate()
+	}
+	db.active = db.files[db.activeID]
+	_, err = db.active.Seek(db.activeSize, io.SeekStart)
+	return err
+}
+
+// scan 扫描一个数据文件，更新索引. 返回最后一条完整记录的结束位置
+func (db *DB) scan(fid int, f *os.File) (int64, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	r := &offsetReader{r: bufio.NewReader(f)}
+	for {
+		offset := r.n
+		rec, err := readRecord(r, fi.Size()-offset)
+		if err == io.EOF {
+			return offset, nil
+		}
+		if err != nil {
+			return offset, err
+		}
+
+		size := r.n - offset
+		if old, ok := db.index[rec.key]; ok {
+			db.garbage += old.size
+		}
+		if rec.deleted {
+			delete(db.index, rec.key)
+			db.garbage += size
+			continue
+		}
+		db.index[rec.key] = &entry{fid: fid, offset: offset, size: size, expire: rec.expire}
+	}
+}
+
+// fileIDs 获取所有数据文件 ID，已排序
+func (db *DB) fileIDs() ([]int, error) {
+	names, err := filepath.Glob(filepath.Join(db.dir, "*"+dataExt))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(names))
+	for _, name := range names {
+		id, err := strconv.Atoi(strings.TrimSuffix(filepath.Base(name), dataExt))
+		if err == nil {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+func (db *DB) fileName(fid int) string {
+	return filepath.Join(db.dir, fmt.Sprintf("%09d%s", fid, dataExt))
+}
+
+// rotate 创建新的数据文件作为当前写入文件
+func (db *DB) rotate() error {
+	if db.active != nil && db.opt.SyncWrites {
+		if err := db.active.Sync(); err != nil {
+			return err
+		}
+	}
+
+	fid := db.activeID + 1
+	f, err := os.OpenFile(db.fileName(fid), os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	db.files[fid] = f
+	db.active, db.activeID, db.activeSize = f, fid, 0
+	return nil
+}
+
+// Put set the value of the key, never expire
+func (db *DB) Put(key string, val []byte) error { return db.PutTTL(key, val, 0) }
+
+// PutTTL set the value of the key with ttl, ttl <= 0 means never expire.
+func (db *DB) PutTTL(key string, val []byte, ttl time.Duration) error {
+	if key == "" {
+		return ErrEmptyKey
+	}
+
+	var expire int64
+	if ttl > 0 {
+		expire = time.Now().Add(ttl).UnixMilli()
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.write(&record{key: key, val: val, expire: expire})
+}
+
+// Delete the key, no error if the key does not exist.
+func (db *DB) Delete(key string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.closed {
+		return ErrClosed
+	}
+	if _, ok := db.index[key]; !ok {
+		return nil
+	}
+	return db.write(&record{key: key, deleted: true})
+}
+
+// write 追加记录到当前写入文件，并更新索引
+func (db *DB) write(rec *record) error {
+	if db.closed {
+		return ErrClosed
+	}
+
+	buf := rec.encode()
+	if db.activeSize > 0 && db.activeSize+int64(len(buf)) > db.opt.MaxFileSize {
+		if err := db.rotate(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := db.active.Write(buf); err != nil {
+		return err
+	}
+	if db.opt.SyncWrites {
+		if err := db.active.Sync(); err != nil {
+			return err
+		}
+	}
+
+	size := int64(len(buf))
+	if old, ok := db.index[rec.key]; ok {
+		db.garbage += old.size
+	}
+	if rec.deleted {
+		delete(db.index, rec.key)
+		db.garbage += size
+	} else {
+		db.index[rec.key] = &entry{fid: db.activeID, offset: db.activeSize, size: size, expire: rec.expire}
+	}
+
+	db.activeSize += size
+	db.size += size
+	return nil
+}
+
+// Get the value of the key, returns ErrNotFound if the key does not exist or is expired.
+func (db *DB) Get(key string) ([]byte, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, ErrClosed
+	}
+	e, ok := db.index[key]
+	if !ok || e.expired(time.Now().UnixMilli()) {
+		return nil, ErrNotFound
+	}
+
+	rec, err := db.read(e)
+	if err != nil {
+		return nil, err
+	}
+	return rec.val, nil
+}
+
+// read 按索引项读取记录
+func (db *DB) read(e *entry) (*record, error) {
+	buf := make([]byte, e.size)
+	if _, err := db.files[e.fid].ReadAt(buf, e.offset); err != nil {
+		return nil, err
+	}
+	return decodeRecord(buf)
+}
+
+// Has check the key exists and not expired
+func (db *DB) Has(key string) bool {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	e, ok := db.index[key]
+	return ok && !e.expired(time.Now().UnixMilli())
+}
+
+// Keys get all not expired keys, sorted.
+func (db *DB) Keys() []string {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	nowUm := time.Now().UnixMilli()
+	keys := make([]string, 0, len(db.index))
+	for key, e := range db.index {
+		if !e.expired(nowUm) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Len get the number of the keys, includes the expired ones not cleaned by Compact.
+func (db *DB) Len() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return len(db.index)
+}
+
+// Stats get the stats of the store
+func (db *DB) Stats() Stats {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	// 已过期的记录也是可回收的空间
+	garbage, nowUm := db.garbage, time.Now().UnixMilli()
+	for _, e := range db.index {
+		if e.expired(nowUm) {
+			garbage += e.size
+		}
+	}
+	return Stats{Keys: len(db.index), Files: len(db.files), Size: db.size, Garbage: garbage}
+}
+
+// Compact rewrite the live records to the new data files and remove the old files,
+// the overwritten, deleted and expired records are dropped.
+//
+// The old files are removed after all records are rewritten and the new files are synced to
+// the disk, in ascending ID order. The new files have the greater IDs, so the records are not
+// lost or resurrected if crashed during compaction: the remaining old files are always the
+// latest ones, with the tombstones of the deleted keys.
+//
+// NOTE: the writes are blocked during compaction.
+func (db *DB) Compact() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return ErrClosed
+	}
+
+	// 保存旧的状态，失败时恢复
+	old := struct {
+		files                     map[int]*os.File
+		index                     map[string]*entry
+		active                    *os.File
+		activeID                  int
+		activeSize, size, garbage int64
+	}{db.files, db.index, db.active, db.activeID, db.activeSize, db.size, db.garbage}
+	db.files = make(map[int]*os.File)
+	db.index = make(map[string]*entry, len(old.index))
+	db.active, db.size, db.garbage = nil, 0, 0
+
+	err := db.rotate()
+	if err == nil {
+		err = db.rewrite(old.files, old.index)
+	}
+	if err != nil {
+		// 删除新写入的文件
+		for fid, f := range db.files {
+			_ = f.Close()
+			_ = os.Remove(db.fileName(fid))
+		}
+		db.files, db.index, db.active = old.files, old.index, old.active
+		db.activeID, db.activeSize, db.size, db.garbage = old.activeID, old.activeSize, old.size, old.garbage
+		return err
+	}
+
+	for _, f := range old.files {
+		_ = f.Close()
+	}
+	// 按 ID 升序删除旧文件，中途崩溃或出错时剩余的是较新的旧文件，删除记录不会丢失.
+	// 剩余的旧文件 ID 较小，重新打开时先于新文件加载
+	for _, fid := range slices.Sorted(maps.Keys(old.files)) {
+		if err = os.Remove(db.fileName(fid)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewrite 按文件顺序写入存活的记录，保持原来的写入顺序
+func (db *DB) rewrite(files map[int]*os.File, index map[string]*entry) error {
+	nowUm := time.Now().UnixMilli()
+	keys := make([]string, 0, len(index))
+	for key, e := range index {
+		if !e.expired(nowUm) {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		ea, eb := index[a], index[b]
+		if ea.fid != eb.fid {
+			return ea.fid - eb.fid
+		}
+		return int(ea.offset - eb.offset)
+	})
+
+	for _, key := range keys {
+		e := index[key]
+		buf := make([]byte, e.size)
+		if _, err := files[e.fid].ReadAt(buf, e.offset); err != nil {
+			return err
+		}
+		rec, err := decodeRecord(buf)
+		if err != nil {
+			return err
+		}
+		if err = db.write(rec); err != nil {
+			return err
+		}
+	}
+
+	// 删除旧文件前，新文件必须已写入磁盘
+	for _, f := range db.files {
+		if err := f.Sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sync flush the active data file to the disk
+func (db *DB) Sync() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return ErrClosed
+	}
+	return db.active.Sync()
+}
+
+// Close the store, the data is synced to the disk.
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return nil
+	}
+
+	db.closed = true
+	err := db.active.Sync()
+	if err1 := db.closeFiles(); err == nil {
+		err = err1
+	}
+	return err
+}
+
+func (db *DB) closeFiles() (err error) {
+	for _, f := range db.files {
+		if err1 := f.Close(); err == nil {
+			err = err1
+		}
+	}
+	return err
+}
+
+//
+// ---------------------- record encoding ----------------------
+//
+
+// headerSize 记录头: crc32(4) | expire(8) | flag(1) | keyLen(4) | valLen(4)
+const headerSize = 21
+
+const flagDeleted byte = 1
+
+// record 数据文件中的一条记录
+type record struct {
+	key     string
+	val     []byte
+	expire  int64
+	deleted bool
+}
+
+// encode 编码记录. crc 校验 crc 之后的所有数据
+func (r *record) encode() []byte {
+	buf := make([]byte, headerSize+len(r.key)+len(r.val))
+	binary.LittleEndian.PutUint64(buf[4:], uint64(r.expire))
+	if r.deleted {
+		buf[12] = flagDeleted
+	}
+	binary.LittleEndian.PutUint32(buf[13:], uint32(len(r.key)))
+	binary.LittleEndian.PutUint32(buf[17:], uint32(len(r.val)))
+	copy(buf[headerSize:], r.key)
+	copy(buf[headerSize+len(r.key):], r.val)
+	binary.LittleEndian.PutUint32(buf, crc32.ChecksumIEEE(buf[4:]))
+	return buf
+}
+
+// readRecord 读取一条记录, remain 为文件剩余的字节数.
+// 文件结束返回 io.EOF，不完整或校验失败返回 ErrCorrupted
+func readRecord(r io.Reader, remain int64) (*record, error) {
+	head := make([]byte, headerSize)
+	if n, err := io.ReadFull(r, head); err != nil {
+		if err == io.EOF && n == 0 {
+			return nil, io.EOF
+		}
+		return nil, ErrCorrupted
+	}
+
+	kl, vl := binary.LittleEndian.Uint32(head[13:]), binary.LittleEndian.Uint32(head[17:])
+	if int64(headerSize)+int64(kl)+int64(vl) > remain {
+		return nil, ErrCorrupted
+	}
+	buf := make([]byte, headerSize+int(kl)+int(vl))
+	copy(buf, head)
+	if _, err := io.ReadFull(r, buf[headerSize:]); err != nil {
+		return nil, ErrCorrupted
+	}
+	return decodeRecord(buf)
+}
+
+// decodeRecord 解码一条完整的记录
+func decodeRecord(buf []byte) (*record, error) {
+	if len(buf) < headerSize || crc32.ChecksumIEEE(buf[4:]) != binary.LittleEndian.Uint32(buf) {
+		return nil, ErrCorrupted
+	}
+
+	kl := int(binary.LittleEndian.Uint32(buf[13:]))
+	if headerSize+kl > len(buf) {
+		return nil, ErrCorrupted
+	}
+	return &record{
+		key:     string(buf[headerSize : headerSize+kl]),
+		val:     buf[headerSize+kl:],
+		expire:  int64(binary.LittleEndian.Uint64(buf[4:])),
+		deleted: buf[12] == flagDeleted,
+	}, nil
+}
+
+// offsetReader 记录已读取的字节数
+type offsetReader struct {
+	r io.Reader
+	n int64
+}
+
+func (o *offsetReader) Read(p []byte) (int, error) {
+	n, err := o.r.Read(p)
+	o.n += int64(n)
+	return n, err
+}
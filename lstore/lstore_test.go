@@ -0,0 +1,185 @@
+package lstore_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lstore"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestDB_basic(t *testing.T) {
+	dir := t.TempDir()
+	db, err := lstore.Open(dir)
+	assert.NoErr(t, err)
+
+	assert.NoErr(t, db.Put("k1", []byte("v1")))
+	assert.NoErr(t, db.Put("k2", []byte("v2")))
+	assert.NoErr(t, db.Put("k1", []byte("v1-new")))
+	assert.ErrIs(t, db.Put("", nil), lstore.ErrEmptyKey)
+
+	val, err := db.Get("k1")
+	assert.NoErr(t, err)
+	assert.Eq(t, "v1-new", string(val))
+	assert.True(t, db.Has("k2"))
+	assert.Eq(t, []string{"k1", "k2"}, db.Keys())
+
+	assert.NoErr(t, db.Delete("k2"))
+	assert.NoErr(t, db.Delete("not-exist"))
+	_, err = db.Get("k2")
+	assert.ErrIs(t, err, lstore.ErrNotFound)
+	assert.False(t, db.Has("k2"))
+
+	// ttl
+	assert.NoErr(t, db.PutTTL("tmp", []byte("1"), 20*time.Millisecond))
+	assert.True(t, db.Has("tmp"))
+	time.Sleep(30 * time.Millisecond)
+	assert.False(t, db.Has("tmp"))
+	_, err = db.Get("tmp")
+	assert.ErrIs(t, err, lstore.ErrNotFound)
+	assert.Eq(t, []string{"k1"}, db.Keys())
+	assert.Eq(t, 2, db.Len())
+
+	st := db.Stats()
+	assert.Eq(t, 2, st.Keys)
+	assert.Eq(t, 1, st.Files)
+	assert.Gt(t, st.Garbage, int64(0))
+	assert.Lt(t, st.Garbage, st.Size)
+
+	// reopen
+	assert.NoErr(t, db.Sync())
+	assert.NoErr(t, db.Close())
+	assert.NoErr(t, db.Close())
+	_, err = db.Get("k1")
+	assert.ErrIs(t, err, lstore.ErrClosed)
+	assert.ErrIs(t, db.Put("k1", nil), lstore.ErrClosed)
+
+	db, err = lstore.Open(dir)
+	assert.NoErr(t, err)
+	defer db.Close()
+
+	val, err = db.Get("k1")
+	assert.NoErr(t, err)
+	assert.Eq(t, "v1-new", string(val))
+	assert.False(t, db.Has("k2"))
+	assert.Eq(t, st, db.Stats())
+}
+
+func TestDB_Compact(t *testing.T) {
+	dir := t.TempDir()
+	db, err := lstore.Open(dir, lstore.WithMaxFileSize(200), lstore.WithSyncWrites(true))
+	assert.NoErr(t, err)
+
+	for i := 0; i < 50; i++ {
+		assert.NoErr(t, db.Put(fmt.Sprintf("key%d", i%10), []byte(fmt.Sprintf("val%d", i))))
+	}
+	assert.NoErr(t, db.Delete("key9"))
+	assert.NoErr(t, db.PutTTL("tmp", []byte("1"), time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+
+	before := db.Stats()
+	assert.Gt(t, before.Files, 1)
+	assert.Eq(t, 10, before.Keys)
+
+	assert.NoErr(t, db.Compact())
+	after := db.Stats()
+	assert.Eq(t, 9, after.Keys)
+	assert.Eq(t, int64(0), after.Garbage)
+	assert.Lt(t, after.Files, before.Files)
+	assert.Lt(t, after.Size, before.Size)
+
+	for i := 0; i < 9; i++ {
+		val, err := db.Get(fmt.Sprintf("key%d", i))
+		assert.NoErr(t, err)
+		assert.Eq(t, fmt.Sprintf("val%d", 40+i), string(val))
+	}
+
+	// write after compact and reopen
+	assert.NoErr(t, db.Put("key0", []byte("new")))
+	assert.NoErr(t, db.Close())
+	assert.ErrIs(t, db.Compact(), lstore.ErrClosed)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.data"))
+	assert.Len(t, files, after.Files)
+
+	db, err = lstore.Open(dir)
+	assert.NoErr(t, err)
+	defer db.Close()
+	assert.Eq(t, 9, db.Len())
+	val, _ := db.Get("key0")
+	assert.Eq(t, "new", string(val))
+}
+
+func TestDB_Compact_crashed(t *testing.T) {
+	dir := t.TempDir()
+	db, err := lstore.Open(dir, lstore.WithMaxFileSize(200))
+	assert.NoErr(t, err)
+
+	for i := 0; i < 50; i++ {
+		assert.NoErr(t, db.Put(fmt.Sprintf("key%d", i%10), []byte(fmt.Sprintf("val%d", i))))
+	}
+	assert.NoErr(t, db.Delete("key9"))
+	assert.NoErr(t, db.Sync())
+
+	// crashed after removed the older files, the latest old files are kept
+	oldFiles, _ := filepath.Glob(filepath.Join(dir, "*.data"))
+	assert.Gt(t, len(oldFiles), 2)
+	kept := make(map[string][]byte)
+	for _, name := range oldFiles[len(oldFiles)-2:] {
+		kept[name], err = os.ReadFile(name)
+		assert.NoErr(t, err)
+	}
+
+	assert.NoErr(t, db.Compact())
+	assert.NoErr(t, db.Close())
+	for name, data := range kept {
+		assert.NoErr(t, os.WriteFile(name, data, 0644))
+	}
+
+	db, err = lstore.Open(dir)
+	assert.NoErr(t, err)
+	defer db.Close()
+	assert.False(t, db.Has("key9"))
+	for i := 0; i < 9; i++ {
+		val, err := db.Get(fmt.Sprintf("key%d", i))
+		assert.NoErr(t, err)
+		assert.Eq(t, fmt.Sprintf("val%d", 40+i), string(val))
+	}
+}
+
+func TestOpen_corrupted(t *testing.T) {
+	dir := t.TempDir()
+	db, err := lstore.Open(dir)
+	assert.NoErr(t, err)
+	assert.NoErr(t, db.Put("k1", []byte("v1")))
+	assert.NoErr(t, db.Put("k2", []byte("v2")))
+	assert.NoErr(t, db.Close())
+
+	// crashed in writing: a partial record at the tail is truncated
+	file := filepath.Join(dir, "000000001.data")
+	fi, err := os.Stat(file)
+	assert.NoErr(t, err)
+	assert.NoErr(t, os.Truncate(file, fi.Size()-3))
+
+	db, err = lstore.Open(dir)
+	assert.NoErr(t, err)
+	assert.Eq(t, []string{"k1"}, db.Keys())
+	assert.NoErr(t, db.Put("k3", []byte("v3")))
+	assert.NoErr(t, db.Close())
+
+	db, err = lstore.Open(dir)
+	assert.NoErr(t, err)
+	assert.Eq(t, []string{"k1", "k3"}, db.Keys())
+	assert.NoErr(t, db.Close())
+
+	// corrupted data in the middle of a old file
+	data, _ := os.ReadFile(file)
+	data[25] ^= 0xff
+	assert.NoErr(t, os.WriteFile(file, data, 0644))
+	assert.NoErr(t, os.WriteFile(filepath.Join(dir, "000000002.data"), nil, 0644))
+	_, err = lstore.Open(dir)
+	assert.ErrIs(t, err, lstore.ErrCorrupted)
+}
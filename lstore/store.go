@@ -0,0 +1,81 @@
+package lstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+)
+
+// storeValue 包装保存的值. gob 只能将 interface 类型的字段解码到 any
+type storeValue struct {
+	V any `json:"v"`
+}
+
+// cacheStore 适配 lcache.Store，值使用 lcache 的序列化器编码
+type cacheStore struct {
+	db *DB
+	s  lcache.Serializer
+	// err 获取序列化器失败的错误
+	err error
+}
+
+// AsStore get a lcache.Store backed by the db, for the write-through and read-through
+// mode of lcache. The values are encoded by the serializer of Options.Serializer.
+//
+//	c := lcache.New(lcache.WithStore(db.AsStore()))
+//
+// NOTE: the values are decoded as generic types by the JSON serializer(eg: map[string]any),
+// use the gob serializer and register the value types for keeping the concrete types.
+func (db *DB) AsStore() lcache.Store {
+	cs := &cacheStore{db: db}
+	if s, ok := lcache.GetSerializer(db.opt.Serializer); ok {
+		cs.s = s
+	} else {
+		cs.err = errors.New("lstore: not registered serializer: " + db.opt.Serializer)
+	}
+	return cs
+}
+
+// Load implements lcache.Store
+func (cs *cacheStore) Load(_ context.Context, key string) (any, error) {
+	if cs.err != nil {
+		return nil, cs.err
+	}
+
+	data, err := cs.db.Get(key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, lcache.ErrNotFound
+		}
+		return nil, err
+	}
+
+	var sv storeValue
+	if err = cs.s.Decode(data, &sv); err != nil {
+		return nil, err
+	}
+	return sv.V, nil
+}
+
+// Save implements lcache.Store
+func (cs *cacheStore) Save(_ context.Context, key string, val any, ttl time.Duration) error {
+	if cs.err != nil {
+		return cs.err
+	}
+
+	data, err := cs.s.Encode(&storeValue{V: val})
+	if err != nil {
+		return err
+	}
+	return cs.db.PutTTL(key, data, ttl)
+}
+
+// Delete implements lcache.Store
+func (cs *cacheStore) Delete(_ context.Context, key string) error {
+	if cs.err != nil {
+		return cs.err
+	}
+	return cs.db.Delete(key)
+}
@@ -0,0 +1,45 @@
+package lstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/lstore"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestDB_AsStore(t *testing.T) {
+	dir := t.TempDir()
+	db, err := lstore.Open(dir)
+	assert.NoErr(t, err)
+
+	// write through
+	c := lcache.New(lcache.WithStore(db.AsStore()))
+	assert.NoErr(t, c.SetE("name", "inhere", 0))
+	assert.NoErr(t, c.SetE("age", 20, 0))
+	assert.NoErr(t, c.SetE("tmp", 1, 0))
+	assert.NoErr(t, c.DeleteE("tmp"))
+	assert.Eq(t, []string{"age", "name"}, db.Keys())
+	assert.NoErr(t, db.Close())
+
+	// read through after reopen
+	db, err = lstore.Open(dir)
+	assert.NoErr(t, err)
+	defer db.Close()
+
+	c = lcache.New(lcache.WithStore(db.AsStore()))
+	assert.Eq(t, "inhere", c.Val("name"))
+	assert.Eq(t, float64(20), c.Val("age"))
+	assert.Nil(t, c.Val("tmp"))
+
+	// not registered serializer
+	db2, err := lstore.Open(t.TempDir(), lstore.WithSerializer("not-exist"))
+	assert.NoErr(t, err)
+	defer db2.Close()
+	s := db2.AsStore()
+	assert.Err(t, s.Save(context.Background(), "key", 1, 0))
+	_, err = s.Load(context.Background(), "key")
+	assert.Err(t, err)
+	assert.Err(t, s.Delete(context.Background(), "key"))
+}
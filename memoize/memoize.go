@@ -0,0 +1,170 @@
+// Package memoize provides the function memoization built on lcache.
+//
+// The results of the function are cached by the argument, concurrent calls with the
+// same argument on miss only call the function once(singleflight).
+//
+// Usage:
+//
+//	fib := memoize.Func(slowFib, time.Minute)
+//	fib(40)
+//
+//	// with error, the errors are not cached by default
+//	getUser := memoize.FuncE(db.FindUser, time.Minute, memoize.WithErrorTTL(5*time.Second))
+//	user, err := getUser(1001)
+//
+//	// context-aware
+//	fetch := memoize.FuncCtx(func(ctx context.Context, url string) ([]byte, error) {
+//		return httpGet(ctx, url)
+//	}, time.Minute, memoize.WithCache(sharedCache), memoize.WithKeyPrefix("fetch:"))
+package memoize
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+)
+
+// Options for the memoized functions
+type Options struct {
+	// Cache the cache to store the results. default is a new lcache.Cache for each function.
+	Cache *lcache.Cache
+	// KeyPrefix the key prefix of the results in the cache. default is "" for a new cache,
+	// or "memo:<n>:" for the injected cache, to avoid conflicts between functions.
+	KeyPrefix string
+	// KeyFunc convert the argument to the cache key. default is fmt.Sprint(arg)
+	//
+	// NOTE: the default is not suitable for the pointer or the struct with pointer fields.
+	KeyFunc func(arg any) string
+	// ErrorTTL cache the errors of the function for the duration. default is 0, not cache errors.
+	ErrorTTL time.Duration
+}
+
+// OptionFn option func for the memoize functions
+type OptionFn func(o *Options)
+
+// WithCache set the cache to store the results
+func WithCache(c *lcache.Cache) OptionFn {
+	return func(o *Options) { o.Cache = c }
+}
+
+// WithKeyPrefix set the key prefix of the results in the cache
+func WithKeyPrefix(prefix string) OptionFn {
+	return func(o *Options) { o.KeyPrefix = prefix }
+}
+
+// WithKeyFunc set the func to convert the argument to the cache key
+func WithKeyFunc(fn func(arg any) string) OptionFn {
+	return func(o *Options) { o.KeyFunc = fn }
+}
+
+// WithErrorTTL cache the errors of the function for the duration, to avoid calling a
+// failing backend repeatedly.
+func WithErrorTTL(ttl time.Duration) OptionFn {
+	return func(o *Options) { o.ErrorTTL = ttl }
+}
+
+// Func memoize the pure function, the results are cached with ttl(<= 0 for never expire).
+// A panic of fn is propagated to the callers.
+func Func[K comparable, V any](fn func(K) V, ttl time.Duration, optFns ...OptionFn) func(K) V {
+	m := newMemo(optFns)
+	return func(arg K) V {
+		val, err := m.load(context.Background(), arg, ttl, func(context.Context) (any, error) {
+			return fn(arg), nil
+		})
+		if err != nil {
+			panic(err)
+		}
+		v, _ := val.(V)
+		return v
+	}
+}
+
+// FuncE memoize the function returns error, the results are cached with ttl(<= 0 for never expire).
+// The errors are not cached, unless set WithErrorTTL.
+func FuncE[K comparable, V any](fn func(K) (V, error), ttl time.Duration, optFns ...OptionFn) func(K) (V, error) {
+	m := newMemo(optFns)
+	return func(arg K) (V, error) {
+		return value[V](m.load(context.Background(), arg, ttl, func(context.Context) (any, error) {
+			return fn(arg)
+		}))
+	}
+}
+
+// FuncCtx memoize the context-aware function, like FuncE.
+//
+// The function is called with the ctx of the first caller on miss, the other callers
+// with the same argument wait for its result, and return ctx.Err() if their ctx is done.
+func FuncCtx[K comparable, V any](fn func(context.Context, K) (V, error), ttl time.Duration, optFns ...OptionFn) func(context.Context, K) (V, error) {
+	m := newMemo(optFns)
+	return func(ctx context.Context, arg K) (V, error) {
+		return value[V](m.load(ctx, arg, ttl, func(ctx context.Context) (any, error) {
+			return fn(ctx, arg)
+		}))
+	}
+}
+
+// memoSeq 注入缓存时生成默认 key 前缀
+var memoSeq atomic.Int64
+
+// memo 缓存函数结果
+type memo struct {
+	opt Options
+	c   *lcache.Cache
+}
+
+func newMemo(optFns []OptionFn) *memo {
+	opt := Options{KeyFunc: defaultKey}
+	for _, fn := range optFns {
+		fn(&opt)
+	}
+
+	m := &memo{opt: opt, c: opt.Cache}
+	if m.c == nil {
+		m.c = lcache.New()
+	} else if opt.KeyPrefix == "" {
+		m.opt.KeyPrefix = fmt.Sprintf("memo:%d:", memoSeq.Add(1))
+	}
+	return m
+}
+
+// cachedErr 缓存的函数错误
+type cachedErr struct{ err error }
+
+// load 从缓存获取结果，未命中时调用 fn. 同一个参数的并发调用只执行一次 fn
+func (m *memo) load(ctx context.Context, arg any, ttl time.Duration, fn func(ctx context.Context) (any, error)) (any, error) {
+	key := m.opt.KeyPrefix + m.opt.KeyFunc(arg)
+
+	// loaded 标记当前调用执行了 fn. fn 在当前 goroutine 中同步执行
+	var loaded bool
+	val, err := m.c.GetOrLoadCtx(ctx, key, ttl, func(ctx context.Context, _ string) (any, error) {
+		loaded = true
+		return fn(ctx)
+	})
+	if err != nil {
+		if loaded && m.opt.ErrorTTL > 0 {
+			m.c.Set(key, &cachedErr{err: err}, m.opt.ErrorTTL)
+		}
+		return nil, err
+	}
+
+	if ce, ok := val.(*cachedErr); ok {
+		return nil, ce.err
+	}
+	return val, nil
+}
+
+// value 转换缓存的结果为函数返回值类型
+func value[V any](val any, err error) (V, error) {
+	v, _ := val.(V)
+	return v, err
+}
+
+func defaultKey(arg any) string {
+	if s, ok := arg.(string); ok {
+		return s
+	}
+	return fmt.Sprint(arg)
+}
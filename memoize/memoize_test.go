@@ -0,0 +1,129 @@
+package memoize_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/memoize"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestFunc(t *testing.T) {
+	var calls atomic.Int32
+	square := memoize.Func(func(n int) int {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return n * n
+	}, time.Minute)
+
+	// concurrent calls on miss only call once
+	var wg sync.WaitGroup
+	results := make([]int, 100)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = square(3)
+		}()
+	}
+	wg.Wait()
+	for _, v := range results {
+		assert.Eq(t, 9, v)
+	}
+	assert.Eq(t, int32(1), calls.Load())
+
+	assert.Eq(t, 16, square(4))
+	assert.Eq(t, 16, square(4))
+	assert.Eq(t, int32(2), calls.Load())
+
+	// panic is propagated
+	fn := memoize.Func(func(s string) error { panic("oops") }, 0)
+	assert.Panics(t, func() {
+		fn("a")
+	})
+}
+
+func TestFuncE(t *testing.T) {
+	errLoad := errors.New("load error")
+	var calls atomic.Int32
+	load := func(id int) (string, error) {
+		calls.Add(1)
+		if id < 0 {
+			return "", errLoad
+		}
+		return "user" + strconv.Itoa(id), nil
+	}
+
+	// errors are not cached by default
+	get := memoize.FuncE(load, time.Minute)
+	val, err := get(1)
+	assert.NoErr(t, err)
+	assert.Eq(t, "user1", val)
+	_, _ = get(1)
+	_, err = get(-1)
+	assert.ErrIs(t, err, errLoad)
+	_, _ = get(-1)
+	assert.Eq(t, int32(3), calls.Load())
+
+	// cache errors
+	calls.Store(0)
+	get = memoize.FuncE(load, time.Minute, memoize.WithErrorTTL(20*time.Millisecond))
+	for i := 0; i < 3; i++ {
+		_, err = get(-1)
+		assert.ErrIs(t, err, errLoad)
+	}
+	assert.Eq(t, int32(1), calls.Load())
+	time.Sleep(25 * time.Millisecond)
+	_, err = get(-1)
+	assert.ErrIs(t, err, errLoad)
+	assert.Eq(t, int32(2), calls.Load())
+}
+
+func TestFuncCtx(t *testing.T) {
+	c := lcache.New()
+	fetch := memoize.FuncCtx(func(ctx context.Context, url string) (string, error) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(20 * time.Millisecond):
+			return "body of " + url, nil
+		}
+	}, time.Minute, memoize.WithCache(c), memoize.WithKeyPrefix("fetch:"))
+
+	val, err := fetch(context.Background(), "/a")
+	assert.NoErr(t, err)
+	assert.Eq(t, "body of /a", val)
+	assert.True(t, c.Has("fetch:/a"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	_, err = fetch(ctx, "/b")
+	assert.ErrIs(t, err, context.DeadlineExceeded)
+	assert.False(t, c.Has("fetch:/b"))
+}
+
+func TestWithCache(t *testing.T) {
+	c := lcache.New()
+
+	// the functions sharing a cache have different key prefixes
+	double := memoize.Func(func(n int) int { return n * 2 }, 0, memoize.WithCache(c))
+	triple := memoize.Func(func(n int) int { return n * 3 }, 0, memoize.WithCache(c))
+	assert.Eq(t, 4, double(2))
+	assert.Eq(t, 6, triple(2))
+	assert.Eq(t, 2, c.Len())
+
+	type point struct{ X, Y int }
+	dist := memoize.Func(func(p point) int { return p.X + p.Y }, 0, memoize.WithCache(c),
+		memoize.WithKeyPrefix("dist:"), memoize.WithKeyFunc(func(arg any) string {
+			p := arg.(point)
+			return strconv.Itoa(p.X) + "," + strconv.Itoa(p.Y)
+		}))
+	assert.Eq(t, 3, dist(point{1, 2}))
+	assert.True(t, c.Has("dist:1,2"))
+}
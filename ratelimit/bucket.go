@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Bucket a token-bucket limiter. The bucket is refilled with rate tokens per second,
+// and holds at most burst tokens. It is full on created.
+type Bucket struct {
+	mu    sync.Mutex
+	rate  float64
+	burst float64
+	// 当前令牌数和上次补充的时间
+	tokens float64
+	last   time.Time
+}
+
+// NewBucket create a token-bucket limiter, rate is the tokens per second, burst is the bucket size.
+//
+// Usage:
+//
+//	// 10 requests per second, allow burst of 20
+//	b := ratelimit.NewBucket(10, 20)
+//	if !b.Allow() {
+//		return errTooManyRequests
+//	}
+func NewBucket(rate float64, burst int) *Bucket {
+	return &Bucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Allow report whether one event may happen now, it takes one token if allowed.
+func (b *Bucket) Allow() bool { return b.AllowN(1) }
+
+// AllowN report whether n events may happen now, it takes n tokens if allowed.
+func (b *Bucket) AllowN(n int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	if b.tokens < float64(n) {
+		return false
+	}
+	b.tokens -= float64(n)
+	return true
+}
+
+// Tokens get the number of available tokens now
+func (b *Bucket) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	return b.tokens
+}
+
+// Wait blocks until one token is available and takes it, or returns ctx.Err() if ctx is done.
+func (b *Bucket) Wait(ctx context.Context) error {
+	for {
+		delay := b.reserve()
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// reserve 有可用令牌时取出并返回 0，否则返回需要等待的时间. rate <= 0 时不会补充，等待最长时间
+func (b *Bucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	if b.rate <= 0 || b.burst < 1 {
+		return time.Duration(1<<63 - 1)
+	}
+
+	delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	return max(delay, time.Millisecond)
+}
+
+// refill 按经过的时间补充令牌 (不加锁)
+func (b *Bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+}
@@ -0,0 +1,108 @@
+// Package ratelimit provides the token-bucket and sliding-window rate limiters,
+// and a keyed limiter which stores the per-key limiters in an lcache instance.
+//
+// Usage:
+//
+//	// limit each client to 10 requests per second, burst 20
+//	lim := ratelimit.NewKeyed(func() ratelimit.Limiter {
+//		return ratelimit.NewBucket(10, 20)
+//	})
+//	defer lim.Close()
+//
+//	if !lim.Allow(clientIP) {
+//		http.Error(w, "too many requests", http.StatusTooManyRequests)
+//		return
+//	}
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/gookit/ext/lcache"
+)
+
+// DefaultIdleTTL the default time to keep the limiter of an idle key
+const DefaultIdleTTL = 10 * time.Minute
+
+// Limiter the rate limiter interface. implemented by Bucket and Window
+type Limiter interface {
+	// Allow report whether one event may happen now
+	Allow() bool
+	// AllowN report whether n events may happen now
+	AllowN(n int) bool
+}
+
+// KeyedOption option func for NewKeyed
+type KeyedOption func(k *Keyed)
+
+// WithIdleTTL set the time to keep the limiter of an idle key, default is DefaultIdleTTL.
+//
+// NOTE: it should be longer than the time for refilling the limiter, otherwise a removed
+// limiter is recreated as full.
+func WithIdleTTL(ttl time.Duration) KeyedOption {
+	return func(k *Keyed) { k.idleTTL = ttl }
+}
+
+// WithCacheOptions set the options of the lcache instance to store the limiters.
+// eg: lcache.WithCapacity to limit the number of keys, default is 10000.
+//
+// NOTE: do not use lcache.WithCopyOnRead, the limiters must be shared.
+func WithCacheOptions(optFns ...lcache.OptionFn) KeyedOption {
+	return func(k *Keyed) { k.cacheOpts = append(k.cacheOpts, optFns...) }
+}
+
+// Keyed a limiter per key, eg: per user or client IP. The limiters are stored in an
+// lcache instance, the idle ones are removed after the idle TTL by the janitor.
+//
+// NOTE: when the number of keys exceeds the cache capacity, the least recently used
+// limiters are evicted, and recreated as full on next use.
+type Keyed struct {
+	newFn     func() Limiter
+	idleTTL   time.Duration
+	cacheOpts []lcache.OptionFn
+	cache     *lcache.Cache
+}
+
+// NewKeyed create a keyed limiter, newFn is called to create the limiter for a new key.
+func NewKeyed(newFn func() Limiter, opts ...KeyedOption) *Keyed {
+	k := &Keyed{newFn: newFn, idleTTL: DefaultIdleTTL}
+	for _, fn := range opts {
+		fn(k)
+	}
+
+	optFns := []lcache.OptionFn{lcache.WithCapacity(10000), lcache.WithJanitor(k.idleTTL)}
+	k.cache = lcache.New(append(optFns, k.cacheOpts...)...)
+	return k
+}
+
+// Allow report whether one event of the key may happen now.
+func (k *Keyed) Allow(key string) bool { return k.Limiter(key).AllowN(1) }
+
+// AllowN report whether n events of the key may happen now.
+func (k *Keyed) AllowN(key string, n int) bool { return k.Limiter(key).AllowN(n) }
+
+// Limiter get the limiter of the key, create it if not exists. The idle TTL of the key is refreshed.
+func (k *Keyed) Limiter(key string) Limiter {
+	val, err := k.cache.GetOrLoad(key, k.idleTTL, func(string) (any, error) {
+		return k.newFn(), nil
+	})
+	if err != nil {
+		// 缓存不可用时(如 key 无效)使用临时的限流器
+		return k.newFn()
+	}
+
+	k.cache.Touch(key, k.idleTTL)
+	return val.(Limiter)
+}
+
+// Reset remove the limiter of the key, it is recreated as full on next use.
+func (k *Keyed) Reset(key string) { k.cache.Delete(key) }
+
+// Len get the number of the keys with limiter
+func (k *Keyed) Len() int { return k.cache.Len() }
+
+// Cache get the lcache instance to store the limiters
+func (k *Keyed) Cache() *lcache.Cache { return k.cache }
+
+// Close stop the janitor of the cache
+func (k *Keyed) Close() error { return k.cache.Close() }
@@ -0,0 +1,95 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/ratelimit"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestBucket(t *testing.T) {
+	b := ratelimit.NewBucket(100, 3)
+	assert.True(t, b.Allow())
+	assert.True(t, b.AllowN(2))
+	assert.False(t, b.Allow())
+	assert.False(t, b.AllowN(4))
+
+	// refill 1 token per 10ms
+	time.Sleep(25 * time.Millisecond)
+	assert.True(t, b.AllowN(2))
+	assert.Lt(t, b.Tokens(), float64(1))
+
+	// wait
+	start := time.Now()
+	assert.NoErr(t, b.Wait(context.Background()))
+	assert.Gt(t, int64(time.Since(start)), int64(time.Millisecond))
+
+	// no refill, wait until ctx done
+	b = ratelimit.NewBucket(0, 1)
+	assert.NoErr(t, b.Wait(context.Background()))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	assert.ErrIs(t, b.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestWindow(t *testing.T) {
+	w := ratelimit.NewWindow(3, 50*time.Millisecond)
+	assert.True(t, w.AllowN(2))
+	assert.True(t, w.Allow())
+	assert.False(t, w.Allow())
+	assert.Eq(t, 3, w.Count())
+
+	// the previous window is weighted, not all released at once
+	time.Sleep(60 * time.Millisecond)
+	assert.Lt(t, w.Count(), 3)
+	assert.Gt(t, w.Count(), 0)
+
+	// after two windows, all released
+	time.Sleep(60 * time.Millisecond)
+	assert.Eq(t, 0, w.Count())
+	assert.True(t, w.AllowN(3))
+}
+
+func TestKeyed(t *testing.T) {
+	lim := ratelimit.NewKeyed(func() ratelimit.Limiter {
+		return ratelimit.NewBucket(0, 2)
+	}, ratelimit.WithIdleTTL(20*time.Millisecond), ratelimit.WithCacheOptions(lcache.WithCapacity(10)))
+	defer lim.Close()
+
+	assert.True(t, lim.Allow("a"))
+	assert.True(t, lim.Allow("a"))
+	assert.False(t, lim.Allow("a"))
+	assert.True(t, lim.AllowN("b", 2))
+	assert.Eq(t, 2, lim.Len())
+
+	// reset the key
+	lim.Reset("b")
+	assert.True(t, lim.Allow("b"))
+
+	// the idle key is removed by the janitor
+	time.Sleep(60 * time.Millisecond)
+	assert.Eq(t, 0, lim.Len())
+	assert.True(t, lim.Allow("a"))
+	assert.Eq(t, 1, lim.Cache().Len())
+}
+
+func TestKeyed_invalidKey(t *testing.T) {
+	lim := ratelimit.NewKeyed(func() ratelimit.Limiter {
+		return ratelimit.NewWindow(1, time.Minute)
+	}, ratelimit.WithCacheOptions(lcache.WithKeyFunc(func(key string) (string, error) {
+		if key == "" {
+			return "", errors.New("empty key")
+		}
+		return key, nil
+	})))
+	defer lim.Close()
+
+	// invalid key uses a temporary limiter
+	assert.True(t, lim.Allow(""))
+	assert.True(t, lim.Allow(""))
+	assert.Eq(t, 0, lim.Len())
+}
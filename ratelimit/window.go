@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Window a sliding-window limiter, allows at most limit events in any window of time.
+//
+// It uses the sliding window counter algorithm: the count of the previous fixed window
+// is weighted by its overlap with the sliding window, so the memory is constant.
+type Window struct {
+	mu    sync.Mutex
+	limit int
+	size  time.Duration
+	// 当前固定窗口的开始时间，当前和上一个窗口的计数
+	start     time.Time
+	cur, prev int
+}
+
+// NewWindow create a sliding-window limiter, allows at most limit events in any window of time.
+//
+// Usage:
+//
+//	// 100 requests per minute
+//	w := ratelimit.NewWindow(100, time.Minute)
+func NewWindow(limit int, window time.Duration) *Window {
+	return &Window{
+		limit: limit,
+		size:  window,
+		start: time.Now(),
+	}
+}
+
+// Allow report whether one event may happen now, it is counted if allowed.
+func (w *Window) Allow() bool { return w.AllowN(1) }
+
+// AllowN report whether n events may happen now, they are counted if allowed.
+func (w *Window) AllowN(n int) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	if w.count(now)+float64(n) > float64(w.limit) {
+		return false
+	}
+	w.cur += n
+	return true
+}
+
+// Count get the estimated number of events in the sliding window now.
+func (w *Window) Count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return int(w.count(time.Now()))
+}
+
+// count 滑动到当前时间，返回滑动窗口内的估算计数 (不加锁)
+func (w *Window) count(now time.Time) float64 {
+	if w.size <= 0 {
+		return float64(w.cur)
+	}
+
+	if elapsed := now.Sub(w.start); elapsed >= w.size {
+		// 超过两个窗口时，上一个窗口的计数也已经失效
+		if elapsed >= 2*w.size {
+			w.prev = 0
+		} else {
+			w.prev = w.cur
+		}
+		w.cur = 0
+		w.start = w.start.Add(elapsed / w.size * w.size)
+	}
+
+	weight := 1 - float64(now.Sub(w.start))/float64(w.size)
+	return float64(w.prev)*weight + float64(w.cur)
+}
@@ -0,0 +1,207 @@
+// Package retry provides the helpers to retry a func with backoff strategies.
+//
+// Usage:
+//
+//	err := retry.Do(ctx, func(ctx context.Context) error {
+//		return callRemote(ctx)
+//	}, retry.WithMaxAttempts(5), retry.WithExponentialBackoff(100*time.Millisecond, 5*time.Second),
+//		retry.WithJitter(0.2), retry.RetryIf(isTemporary))
+//
+//	// with a return value
+//	val, err := retry.DoValue(ctx, func(ctx context.Context) (*User, error) {
+//		return loadUser(ctx, id)
+//	})
+//
+// Return a Permanent error to stop retrying:
+//
+//	if resp.StatusCode == 404 {
+//		return retry.Permanent(ErrNotFound)
+//	}
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff get the delay before the next attempt, attempt is the number of the attempts made, starts from 1.
+type Backoff func(attempt int) time.Duration
+
+// ConstantBackoff a backoff with the fixed delay
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(int) time.Duration { return d }
+}
+
+// ExponentialBackoff a backoff with the delay doubled on each attempt: base, base*2, base*4 ...
+// The delay is limited to maxDelay if it is greater than 0.
+func ExponentialBackoff(base, maxDelay time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt; i++ {
+			// 超过最大值或将要溢出时停止
+			if d > math.MaxInt64/2 || (maxDelay > 0 && d >= maxDelay) {
+				break
+			}
+			d *= 2
+		}
+		if maxDelay > 0 {
+			return min(d, maxDelay)
+		}
+		return d
+	}
+}
+
+// Options for the retry
+type Options struct {
+	// MaxAttempts the max number of attempts, includes the first one. <= 0 for unlimited. default is 3
+	MaxAttempts int
+	// Backoff the delay strategy between attempts. default is ConstantBackoff(100ms)
+	Backoff Backoff
+	// Jitter randomize each delay in [d*(1-Jitter), d*(1+Jitter)], range [0, 1]. default is 0
+	Jitter float64
+	// RetryIf check the error is retryable. default is all errors, except Permanent errors.
+	RetryIf func(err error) bool
+	// OnRetry callback before each retry, with the number of the attempts made and the last error.
+	OnRetry func(attempt int, err error)
+}
+
+// OptionFn option func for Do
+type OptionFn func(o *Options)
+
+// WithMaxAttempts set the max number of attempts, includes the first one. <= 0 for unlimited.
+func WithMaxAttempts(n int) OptionFn {
+	return func(o *Options) { o.MaxAttempts = n }
+}
+
+// WithBackoff set the backoff strategy
+func WithBackoff(b Backoff) OptionFn {
+	return func(o *Options) { o.Backoff = b }
+}
+
+// WithConstantBackoff set the fixed delay between attempts. see ConstantBackoff
+func WithConstantBackoff(d time.Duration) OptionFn {
+	return WithBackoff(ConstantBackoff(d))
+}
+
+// WithExponentialBackoff set the exponential delay between attempts. see ExponentialBackoff
+func WithExponentialBackoff(base, maxDelay time.Duration) OptionFn {
+	return WithBackoff(ExponentialBackoff(base, maxDelay))
+}
+
+// WithJitter randomize each delay by the factor, in range [0, 1]. eg: 0.2 for ±20%
+func WithJitter(factor float64) OptionFn {
+	return func(o *Options) { o.Jitter = min(max(factor, 0), 1) }
+}
+
+// RetryIf set the func to check the error is retryable
+func RetryIf(fn func(err error) bool) OptionFn {
+	return func(o *Options) { o.RetryIf = fn }
+}
+
+// WithOnRetry set the callback before each retry
+func WithOnRetry(fn func(attempt int, err error)) OptionFn {
+	return func(o *Options) { o.OnRetry = fn }
+}
+
+// permanentError 不再重试的错误
+type permanentError struct{ err error }
+
+func (p *permanentError) Error() string { return p.err.Error() }
+func (p *permanentError) Unwrap() error { return p.err }
+
+// Permanent wrap the error to stop retrying, Do returns the original error.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent check the error is wrapped by Permanent
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+// Do call fn until it succeeds, the error is not retryable or the max attempts reached.
+// Returns the last error of fn.
+//
+// If ctx is done while waiting, returns an error wrapping both the ctx error and the last error.
+func Do(ctx context.Context, fn func(ctx context.Context) error, optFns ...OptionFn) error {
+	opt := Options{MaxAttempts: 3, Backoff: ConstantBackoff(100 * time.Millisecond)}
+	for _, f := range optFns {
+		f(&opt)
+	}
+
+	var timer *time.Timer
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		var pe *permanentError
+		if errors.As(err, &pe) {
+			return pe.err
+		}
+		if opt.RetryIf != nil && !opt.RetryIf(err) {
+			return err
+		}
+		if opt.MaxAttempts > 0 && attempt >= opt.MaxAttempts {
+			return err
+		}
+
+		if opt.OnRetry != nil {
+			opt.OnRetry(attempt, err)
+		}
+
+		delay := opt.delay(attempt)
+		if delay <= 0 {
+			if ctx.Err() != nil {
+				return fmt.Errorf("%w: %w", ctx.Err(), err)
+			}
+			continue
+		}
+
+		if timer == nil {
+			timer = time.NewTimer(delay)
+			defer timer.Stop()
+		} else {
+			timer.Reset(delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// DoValue call fn like Do, and returns the value of the successful call.
+func DoValue[T any](ctx context.Context, fn func(ctx context.Context) (T, error), optFns ...OptionFn) (T, error) {
+	var val T
+	err := Do(ctx, func(ctx context.Context) (err error) {
+		val, err = fn(ctx)
+		return err
+	}, optFns...)
+	return val, err
+}
+
+// delay 计算下次重试前的等待时间
+func (o *Options) delay(attempt int) time.Duration {
+	if o.Backoff == nil {
+		return 0
+	}
+
+	d := o.Backoff(attempt)
+	if o.Jitter > 0 && d > 0 {
+		// d * [1-Jitter, 1+Jitter)
+		d = time.Duration(float64(d) * (1 - o.Jitter + 2*o.Jitter*rand.Float64()))
+	}
+	return d
+}
@@ -0,0 +1,110 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/retry"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+var errTemp = errors.New("temporary error")
+
+func TestDo(t *testing.T) {
+	ctx := context.Background()
+
+	// succeed after retries
+	var n int
+	var retries []int
+	err := retry.Do(ctx, func(ctx context.Context) error {
+		if n++; n < 3 {
+			return errTemp
+		}
+		return nil
+	}, retry.WithConstantBackoff(time.Millisecond), retry.WithOnRetry(func(attempt int, err error) {
+		retries = append(retries, attempt)
+	}))
+	assert.NoErr(t, err)
+	assert.Eq(t, 3, n)
+	assert.Eq(t, []int{1, 2}, retries)
+
+	// max attempts reached, returns the last error
+	n = 0
+	err = retry.Do(ctx, func(ctx context.Context) error {
+		n++
+		return errTemp
+	}, retry.WithMaxAttempts(5), retry.WithBackoff(nil))
+	assert.ErrIs(t, err, errTemp)
+	assert.Eq(t, 5, n)
+
+	// not retryable error
+	n = 0
+	errFatal := errors.New("fatal")
+	err = retry.Do(ctx, func(ctx context.Context) error {
+		n++
+		return errFatal
+	}, retry.RetryIf(func(err error) bool { return errors.Is(err, errTemp) }))
+	assert.Eq(t, errFatal, err)
+	assert.Eq(t, 1, n)
+
+	// permanent error
+	n = 0
+	err = retry.Do(ctx, func(ctx context.Context) error {
+		n++
+		return retry.Permanent(errFatal)
+	})
+	assert.Eq(t, errFatal, err)
+	assert.Eq(t, 1, n)
+	assert.True(t, retry.IsPermanent(retry.Permanent(errFatal)))
+	assert.False(t, retry.IsPermanent(errFatal))
+	assert.Nil(t, retry.Permanent(nil))
+}
+
+func TestDo_ctx(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var n int
+	start := time.Now()
+	err := retry.Do(ctx, func(ctx context.Context) error {
+		n++
+		return errTemp
+	}, retry.WithMaxAttempts(0), retry.WithConstantBackoff(time.Second))
+	assert.Lt(t, time.Since(start), 500*time.Millisecond)
+	assert.ErrIs(t, err, context.DeadlineExceeded)
+	assert.ErrIs(t, err, errTemp)
+	assert.Eq(t, 1, n)
+
+	// no delay, stop on ctx done
+	err = retry.Do(ctx, func(ctx context.Context) error { return errTemp },
+		retry.WithMaxAttempts(0), retry.WithConstantBackoff(0))
+	assert.ErrIs(t, err, context.DeadlineExceeded)
+}
+
+func TestDoValue(t *testing.T) {
+	var n int
+	val, err := retry.DoValue(context.Background(), func(ctx context.Context) (string, error) {
+		if n++; n < 2 {
+			return "", errTemp
+		}
+		return "ok", nil
+	}, retry.WithExponentialBackoff(time.Millisecond, 0), retry.WithJitter(0.5))
+	assert.NoErr(t, err)
+	assert.Eq(t, "ok", val)
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := retry.ExponentialBackoff(100*time.Millisecond, time.Second)
+	assert.Eq(t, 100*time.Millisecond, b(1))
+	assert.Eq(t, 200*time.Millisecond, b(2))
+	assert.Eq(t, 800*time.Millisecond, b(4))
+	assert.Eq(t, time.Second, b(5))
+	assert.Eq(t, time.Second, b(100))
+
+	// no max delay, stop before overflow
+	b = retry.ExponentialBackoff(time.Second, 0)
+	assert.Eq(t, 8*time.Second, b(4))
+	assert.Gt(t, b(100), b(30))
+}
@@ -0,0 +1,208 @@
+// Package sflight provides a generic singleflight Group with optional result caching.
+//
+// Concurrent calls of the same key only execute fn once and share the result. With
+// WithTTL, a successful result is also cached for the TTL, the calls in this period
+// return it without executing fn. Errors are never cached.
+//
+// Usage:
+//
+//	g := sflight.New[*User](sflight.WithTTL(time.Second))
+//	user, err, shared := g.Do("user:1001", func() (*User, error) {
+//		return db.FindUser(1001)
+//	})
+//
+//	// share the loads with lcache
+//	c := lcache.New(lcache.WithFlightGroup(sflight.New[any]()))
+package sflight
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// minSweep 触发清理过期结果的最小数量
+const minSweep = 64
+
+// Options for the Group
+type Options struct {
+	// TTL cache the successful results for it, 0 to disable.
+	TTL time.Duration
+}
+
+// OptionFn option func for New
+type OptionFn func(o *Options)
+
+// WithTTL cache the successful results for ttl
+func WithTTL(ttl time.Duration) OptionFn {
+	return func(o *Options) { o.TTL = ttl }
+}
+
+// Flight info of an in-flight call
+type Flight struct {
+	Key string
+	// Start time of the call
+	Start time.Time
+	// Dups number of the calls waiting for the result
+	Dups int
+}
+
+// call 进行中的调用
+type call[T any] struct {
+	done  chan struct{}
+	start time.Time
+	dups  int
+	val   T
+	err   error
+}
+
+// result 缓存的结果. exp 过期时间 unix nano
+type result[T any] struct {
+	val T
+	exp int64
+}
+
+// Group the singleflight group, it is goroutine-safe.
+// The zero value is a group without result caching, ready to use.
+type Group[T any] struct {
+	opt     Options
+	mu      sync.Mutex
+	calls   map[string]*call[T]
+	results map[string]result[T]
+	// sweepAt 缓存结果数量达到该值时清理过期结果
+	sweepAt int
+}
+
+// New create a Group
+func New[T any](optFns ...OptionFn) *Group[T] {
+	g := &Group[T]{}
+	for _, fn := range optFns {
+		fn(&g.opt)
+	}
+	return g
+}
+
+// Do execute fn for the key, the concurrent calls of the same key only execute it once.
+// shared is true if the result is shared with other calls or from the cache.
+//
+// A panic of fn is returned as an error.
+func (g *Group[T]) Do(key string, fn func() (T, error)) (val T, err error, shared bool) {
+	return g.DoCtx(context.Background(), key, func(context.Context) (T, error) {
+		return fn()
+	})
+}
+
+// DoCtx like Do, but the waiting calls return ctx.Err() if ctx is done before the result.
+// fn is called with the ctx of the first call.
+func (g *Group[T]) DoCtx(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (val T, err error, shared bool) {
+	now := time.Now()
+	g.mu.Lock()
+	if r, ok := g.results[key]; ok {
+		if r.exp > now.UnixNano() {
+			g.mu.Unlock()
+			return r.val, nil, true
+		}
+		delete(g.results, key)
+	}
+
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.val, c.err, true
+		case <-ctx.Done():
+			return val, ctx.Err(), false
+		}
+	}
+
+	if g.calls == nil {
+		g.calls = make(map[string]*call[T])
+	}
+	c := &call[T]{done: make(chan struct{}), start: now}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	g.exec(ctx, key, c, fn)
+	return c.val, c.err, c.dups > 0
+}
+
+// exec 执行调用并保存结果
+func (g *Group[T]) exec(ctx context.Context, key string, c *call[T], fn func(ctx context.Context) (T, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.err = fmt.Errorf("sflight: call panic: %v", r)
+		}
+
+		g.mu.Lock()
+		// Forget 后不再保存结果
+		if g.calls[key] == c {
+			delete(g.calls, key)
+			if c.err == nil && g.opt.TTL > 0 {
+				g.store(key, c.val)
+			}
+		}
+		g.mu.Unlock()
+		close(c.done)
+	}()
+
+	c.val, c.err = fn(ctx)
+}
+
+// store 缓存结果. 需持有锁
+func (g *Group[T]) store(key string, val T) {
+	now := time.Now().UnixNano()
+	if g.results == nil {
+		g.results = make(map[string]result[T])
+	}
+	g.results[key] = result[T]{val: val, exp: now + int64(g.opt.TTL)}
+
+	// 数量翻倍时清理一次，均摊 O(1)
+	if len(g.results) >= max(g.sweepAt, minSweep) {
+		for k, r := range g.results {
+			if r.exp <= now {
+				delete(g.results, k)
+			}
+		}
+		g.sweepAt = 2 * len(g.results)
+	}
+}
+
+// Forget the cached result and the in-flight call of the key, the next call executes
+// fn again. The in-flight call is not canceled, but its result is not cached.
+func (g *Group[T]) Forget(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.calls, key)
+	delete(g.results, key)
+}
+
+// InFlight check the key has an in-flight call
+func (g *Group[T]) InFlight(key string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.calls[key]
+	return ok
+}
+
+// Flights get the info of the in-flight calls, sorted by the start time.
+func (g *Group[T]) Flights() []Flight {
+	g.mu.Lock()
+	fs := make([]Flight, 0, len(g.calls))
+	for key, c := range g.calls {
+		fs = append(fs, Flight{Key: key, Start: c.start, Dups: c.dups})
+	}
+	g.mu.Unlock()
+
+	slices.SortFunc(fs, func(a, b Flight) int { return a.Start.Compare(b.Start) })
+	return fs
+}
+
+// Cached get the number of the cached results, includes the expired ones not removed yet.
+func (g *Group[T]) Cached() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.results)
+}
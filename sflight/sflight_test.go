@@ -0,0 +1,215 @@
+package sflight_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/sflight"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+type result struct {
+	val    int
+	err    error
+	shared bool
+}
+
+func TestGroup_Do(t *testing.T) {
+	var g sflight.Group[int]
+	var calls int32
+	release := make(chan struct{})
+
+	fn := func() (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return 42, nil
+	}
+
+	results := make(chan result, 10)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			val, err, shared := g.Do("key", fn)
+			results <- result{val, err, shared}
+		}()
+	}
+
+	// wait all calls joined the flight
+	for {
+		fs := g.Flights()
+		if len(fs) == 1 && fs[0].Dups == 9 {
+			assert.Eq(t, "key", fs[0].Key)
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	assert.True(t, g.InFlight("key"))
+
+	close(release)
+	wg.Wait()
+	close(results)
+	for r := range results {
+		assert.Eq(t, 42, r.val)
+		assert.NoErr(t, r.err)
+		assert.True(t, r.shared)
+	}
+	assert.Eq(t, int32(1), atomic.LoadInt32(&calls))
+	assert.False(t, g.InFlight("key"))
+	assert.Len(t, g.Flights(), 0)
+
+	// no result caching by default
+	val, err, shared := g.Do("key", func() (int, error) { return 2, nil })
+	assert.NoErr(t, err)
+	assert.Eq(t, 2, val)
+	assert.False(t, shared)
+	assert.Eq(t, 0, g.Cached())
+}
+
+func TestGroup_ttl(t *testing.T) {
+	g := sflight.New[string](sflight.WithTTL(50 * time.Millisecond))
+	var calls int32
+	fn := func() (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return "val", nil
+	}
+
+	val, _, shared := g.Do("key", fn)
+	assert.Eq(t, "val", val)
+	assert.False(t, shared)
+
+	// from the cache
+	val, _, shared = g.Do("key", fn)
+	assert.Eq(t, "val", val)
+	assert.True(t, shared)
+	assert.Eq(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Eq(t, 1, g.Cached())
+
+	// expired
+	time.Sleep(60 * time.Millisecond)
+	g.Do("key", fn)
+	assert.Eq(t, int32(2), atomic.LoadInt32(&calls))
+
+	// forget
+	g.Forget("key")
+	assert.Eq(t, 0, g.Cached())
+	g.Do("key", fn)
+	assert.Eq(t, int32(3), atomic.LoadInt32(&calls))
+
+	// errors are not cached
+	errFn := errors.New("fail")
+	for i := 0; i < 2; i++ {
+		_, err, _ := g.Do("err", func() (string, error) {
+			atomic.AddInt32(&calls, 1)
+			return "", errFn
+		})
+		assert.ErrIs(t, err, errFn)
+	}
+	assert.Eq(t, int32(5), atomic.LoadInt32(&calls))
+}
+
+func TestGroup_DoCtx(t *testing.T) {
+	var g sflight.Group[int]
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	done := make(chan result, 1)
+	go func() {
+		val, err, shared := g.DoCtx(context.Background(), "key", func(ctx context.Context) (int, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+		done <- result{val, err, shared}
+	}()
+	<-started
+
+	// the waiting call is canceled
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err, _ := g.DoCtx(ctx, "key", func(ctx context.Context) (int, error) {
+		return 2, nil
+	})
+	assert.ErrIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	r := <-done
+	assert.NoErr(t, r.err)
+	assert.Eq(t, 1, r.val)
+	assert.True(t, r.shared)
+}
+
+func TestGroup_panic(t *testing.T) {
+	var g sflight.Group[int]
+	_, err, _ := g.Do("key", func() (int, error) {
+		panic("oops")
+	})
+	assert.ErrMsg(t, err, "sflight: call panic: oops")
+	assert.False(t, g.InFlight("key"))
+}
+
+func TestGroup_Forget(t *testing.T) {
+	g := sflight.New[int](sflight.WithTTL(time.Minute))
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	done := make(chan int, 1)
+	go func() {
+		val, _, _ := g.Do("key", func() (int, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+		done <- val
+	}()
+	<-started
+
+	// a new call after Forget executes again
+	g.Forget("key")
+	assert.False(t, g.InFlight("key"))
+	val, _, _ := g.Do("key", func() (int, error) { return 2, nil })
+	assert.Eq(t, 2, val)
+
+	// the result of the forgotten call is not cached
+	close(release)
+	assert.Eq(t, 1, <-done)
+	val, _, _ = g.Do("key", func() (int, error) { return 3, nil })
+	assert.Eq(t, 2, val)
+}
+
+func TestGroup_lcache(t *testing.T) {
+	g := sflight.New[any]()
+	c := lcache.New(lcache.WithFlightGroup(g))
+
+	var calls int32
+	release := make(chan struct{})
+	loader := func(key string) (any, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "val-" + key, nil
+	}
+
+	vals := make(chan any, 5)
+	for i := 0; i < 5; i++ {
+		go func() {
+			val, _ := c.GetOrLoad("key", 0, loader)
+			vals <- val
+		}()
+	}
+	for !g.InFlight("key") {
+		time.Sleep(time.Millisecond)
+	}
+
+	close(release)
+	for i := 0; i < 5; i++ {
+		assert.Eq(t, "val-key", <-vals)
+	}
+	assert.Eq(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Eq(t, "val-key", c.Val("key"))
+}
@@ -0,0 +1,279 @@
+// Package topk provides a heavy-hitters tracker, finds the top K frequent keys of
+// a stream in fixed memory.
+//
+// The key counts are estimated by a count-min sketch, and the top K candidates are
+// kept in a min-heap. The estimates never underestimate, may overestimate by the
+// hash collisions. With WithDecay, the counts are decayed periodically, so the
+// recent keys are ranked higher. eg: trending items.
+//
+// Usage:
+//
+//	tr := topk.New(10, topk.WithDecay(time.Minute, 0.5))
+//	tr.Add("item:1001")
+//	tr.AddN("item:1002", 3)
+//
+//	for _, it := range tr.Top(5) {
+//		fmt.Println(it.Key, it.Count)
+//	}
+//
+//	// track the accessed keys of a cache
+//	c := lcache.New(lcache.WithKeyTracker(tr))
+package topk
+
+import (
+	"cmp"
+	"container/heap"
+	"hash/fnv"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Options for the tracker
+type Options struct {
+	// Width number of the counters per row of the sketch. default is max(K*16, 256)
+	Width int
+	// Depth number of the rows of the sketch. default is 4
+	Depth int
+	// DecayInterval decay the counts on every interval, 0 to disable. see WithDecay
+	DecayInterval time.Duration
+	// DecayFactor multiply the counts by it on decay, range (0, 1)
+	DecayFactor float64
+}
+
+// OptionFn option func for New
+type OptionFn func(o *Options)
+
+// WithSketchSize set the size of the count-min sketch. The larger width, the less
+// overestimate; the more depth, the less chance of a large overestimate.
+func WithSketchSize(width, depth int) OptionFn {
+	return func(o *Options) {
+		o.Width = width
+		o.Depth = depth
+	}
+}
+
+// WithDecay multiply the counts by factor on every interval, the recent keys are ranked
+// higher. The decay is applied lazily on Add and Top, no background goroutine.
+// Panics if factor not in range (0, 1).
+func WithDecay(interval time.Duration, factor float64) OptionFn {
+	if factor <= 0 || factor >= 1 {
+		panic("topk: decay factor must be in range (0, 1)")
+	}
+
+	return func(o *Options) {
+		o.DecayInterval = interval
+		o.DecayFactor = factor
+	}
+}
+
+// Item a key and its estimated count
+type Item struct {
+	Key   string
+	Count uint64
+}
+
+// entry 最小堆中的候选 key
+type entry struct {
+	Item
+	index int
+}
+
+// minHeap 按计数排序的最小堆
+type minHeap []*entry
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].Count < h[j].Count }
+func (h minHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *minHeap) Push(x any) {
+	e := x.(*entry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *minHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
+
+// Tracker track the top K frequent keys, it is goroutine-safe.
+type Tracker struct {
+	opt Options
+	k   int
+	mu  sync.Mutex
+	// sketch count-min sketch, depth 行 width 列
+	sketch [][]uint64
+	heap   minHeap
+	index  map[string]*entry
+	// decayAt 下次衰减的时间
+	decayAt time.Time
+}
+
+// New create a tracker for the top k keys, panics if k <= 0.
+func New(k int, optFns ...OptionFn) *Tracker {
+	if k <= 0 {
+		panic("topk: k must be greater than 0")
+	}
+
+	t := &Tracker{opt: Options{Width: max(k*16, 256), Depth: 4}, k: k, index: make(map[string]*entry, k)}
+	for _, fn := range optFns {
+		fn(&t.opt)
+	}
+	t.opt.Width = max(t.opt.Width, 1)
+	t.opt.Depth = max(t.opt.Depth, 1)
+
+	t.sketch = make([][]uint64, t.opt.Depth)
+	for i := range t.sketch {
+		t.sketch[i] = make([]uint64, t.opt.Width)
+	}
+	if t.opt.DecayInterval > 0 {
+		t.decayAt = time.Now().Add(t.opt.DecayInterval)
+	}
+	return t
+}
+
+// K get the number of the tracked top keys
+func (t *Tracker) K() int { return t.k }
+
+// Add the key once
+func (t *Tracker) Add(key string) { t.AddN(key, 1) }
+
+// AddN add the key n times, returns the estimated count of the key.
+func (t *Tracker) AddN(key string, n uint64) uint64 {
+	h1, h2 := hashes(key)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.maybeDecay()
+
+	est := uint64(math.MaxUint64)
+	for i, row := range t.sketch {
+		j := (h1 + uint64(i)*h2) % uint64(len(row))
+		row[j] += n
+		est = min(est, row[j])
+	}
+
+	if e, ok := t.index[key]; ok {
+		e.Count = est
+		heap.Fix(&t.heap, e.index)
+		return est
+	}
+
+	if len(t.heap) < t.k {
+		e := &entry{Item: Item{Key: key, Count: est}}
+		heap.Push(&t.heap, e)
+		t.index[key] = e
+	} else if est > t.heap[0].Count {
+		// 替换计数最小的候选
+		e := t.heap[0]
+		delete(t.index, e.Key)
+		e.Key, e.Count = key, est
+		heap.Fix(&t.heap, 0)
+		t.index[key] = e
+	}
+	return est
+}
+
+// Count get the estimated count of the key, it may overestimate.
+func (t *Tracker) Count(key string) uint64 {
+	h1, h2 := hashes(key)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.maybeDecay()
+
+	est := uint64(math.MaxUint64)
+	for i, row := range t.sketch {
+		est = min(est, row[(h1+uint64(i)*h2)%uint64(len(row))])
+	}
+	return est
+}
+
+// Top get the top n keys by the estimated count in descending order, n <= 0 or
+// greater than K for all tracked top keys.
+func (t *Tracker) Top(n int) []Item {
+	t.mu.Lock()
+	t.maybeDecay()
+	items := make([]Item, 0, len(t.heap))
+	for _, e := range t.heap {
+		if e.Count > 0 {
+			items = append(items, e.Item)
+		}
+	}
+	t.mu.Unlock()
+
+	slices.SortFunc(items, func(a, b Item) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Key, b.Key))
+	})
+	if n > 0 && len(items) > n {
+		items = items[:n]
+	}
+	return items
+}
+
+// Decay multiply all counts by the factor now, factor in range [0, 1).
+func (t *Tracker) Decay(factor float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.decay(factor)
+}
+
+// Reset clear all counts
+func (t *Tracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, row := range t.sketch {
+		clear(row)
+	}
+	t.heap = t.heap[:0]
+	clear(t.index)
+}
+
+// maybeDecay 到达衰减时间时衰减，跳过的间隔一并衰减. 需持有锁
+func (t *Tracker) maybeDecay() {
+	if t.opt.DecayInterval <= 0 {
+		return
+	}
+
+	now := time.Now()
+	if now.Before(t.decayAt) {
+		return
+	}
+	times := int(now.Sub(t.decayAt)/t.opt.DecayInterval) + 1
+	t.decayAt = t.decayAt.Add(time.Duration(times) * t.opt.DecayInterval)
+	t.decay(math.Pow(t.opt.DecayFactor, float64(times)))
+}
+
+// decay 按比例缩小计数. 缩放是单调的，堆的顺序不变. 需持有锁
+func (t *Tracker) decay(factor float64) {
+	for _, row := range t.sketch {
+		for j, c := range row {
+			row[j] = uint64(float64(c) * factor)
+		}
+	}
+	for _, e := range t.heap {
+		e.Count = uint64(float64(e.Count) * factor)
+	}
+}
+
+// hashes 双重 hash: 第 i 行的位置为 h1 + i*h2
+func hashes(key string) (h1, h2 uint64) {
+	hs := fnv.New64a()
+	_, _ = hs.Write([]byte(key))
+	h := hs.Sum64()
+	// splitmix64 finalizer, 打散 FNV 结果的高位
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h & math.MaxUint32, h>>32 | 1
+}
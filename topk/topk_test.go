@@ -0,0 +1,113 @@
+package topk_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/lcache"
+	"github.com/gookit/ext/topk"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestTracker_Top(t *testing.T) {
+	tr := topk.New(3)
+	assert.Eq(t, 3, tr.K())
+	assert.Len(t, tr.Top(0), 0)
+
+	for i := 0; i < 100; i++ {
+		tr.Add("hot")
+		if i%2 == 0 {
+			tr.Add("warm")
+		}
+		if i%10 == 0 {
+			tr.Add("cool")
+		}
+		// long tail
+		tr.Add(fmt.Sprintf("tail-%d", i))
+	}
+	assert.Eq(t, uint64(13), tr.AddN("cool", 3))
+
+	top := tr.Top(2)
+	assert.Eq(t, []topk.Item{{Key: "hot", Count: 100}, {Key: "warm", Count: 50}}, top)
+	top = tr.Top(0)
+	assert.Len(t, top, 3)
+	assert.Eq(t, "cool", top[2].Key)
+	assert.Eq(t, uint64(100), tr.Count("hot"))
+	assert.Eq(t, uint64(0), tr.Count("none"))
+
+	tr.Reset()
+	assert.Len(t, tr.Top(0), 0)
+	assert.Eq(t, uint64(0), tr.Count("hot"))
+
+	assert.Panics(t, func() {
+		topk.New(0)
+	})
+}
+
+func TestTracker_replace(t *testing.T) {
+	tr := topk.New(2, topk.WithSketchSize(1024, 4))
+	tr.AddN("a", 5)
+	tr.AddN("b", 2)
+	// c becomes hotter than b, replaces it
+	tr.AddN("c", 3)
+	assert.Eq(t, []topk.Item{{Key: "a", Count: 5}, {Key: "c", Count: 3}}, tr.Top(0))
+
+	// the count of b is kept in the sketch
+	tr.AddN("b", 2)
+	assert.Eq(t, []topk.Item{{Key: "a", Count: 5}, {Key: "b", Count: 4}}, tr.Top(0))
+}
+
+func TestTracker_Decay(t *testing.T) {
+	tr := topk.New(3)
+	tr.AddN("a", 10)
+	tr.AddN("b", 1)
+	tr.Decay(0.5)
+	assert.Eq(t, []topk.Item{{Key: "a", Count: 5}}, tr.Top(0))
+	assert.Eq(t, uint64(0), tr.Count("b"))
+
+	tr = topk.New(3, topk.WithDecay(50*time.Millisecond, 0.5))
+	tr.AddN("old", 8)
+	time.Sleep(110 * time.Millisecond)
+	// decayed twice
+	assert.Eq(t, uint64(2), tr.Count("old"))
+	tr.AddN("new", 3)
+	assert.Eq(t, "new", tr.Top(1)[0].Key)
+
+	assert.Panics(t, func() {
+		topk.WithDecay(time.Second, 1)
+	})
+}
+
+func TestTracker_concurrent(t *testing.T) {
+	tr := topk.New(5)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				tr.Add(fmt.Sprintf("key-%d", j%10))
+			}
+		}()
+	}
+	wg.Wait()
+
+	top := tr.Top(0)
+	assert.Len(t, top, 5)
+	assert.Eq(t, uint64(800), top[0].Count)
+}
+
+func TestTracker_cache(t *testing.T) {
+	tr := topk.New(2)
+	c := lcache.New(lcache.WithKeyTracker(tr))
+	c.Set("a", 1, 0)
+	for i := 0; i < 3; i++ {
+		c.Get("a")
+		c.Namespace("users").Get("1")
+	}
+	c.Get("b")
+
+	assert.Eq(t, []topk.Item{{Key: "a", Count: 3}, {Key: "users:1", Count: 3}}, tr.Top(0))
+}
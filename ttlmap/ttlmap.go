@@ -0,0 +1,223 @@
+// Package ttlmap provides a lightweight generic map with per-entry TTL.
+//
+// No LRU, no persistence and no background goroutine by default: the expired entries
+// are invisible to the reads, and removed by the amortized sweep on writing, or by
+// Cleanup and AutoCleanup.
+//
+// Usage:
+//
+//	m := ttlmap.New[string, int](time.Minute)
+//	m.Set("a", 1)
+//	m.SetTTL("b", 2, 5*time.Second)
+//	val, ok := m.Get("a")
+//
+//	// optional background cleanup
+//	stop := m.AutoCleanup(time.Minute)
+//	defer stop()
+package ttlmap
+
+import (
+	"sync"
+	"time"
+)
+
+// minSweep 触发写入时清理的最小数量
+const minSweep = 64
+
+// item 数据项. exp 过期时间 unix nano, 0 表示永不过期
+type item[V any] struct {
+	val V
+	exp int64
+}
+
+func (it *item[V]) expired(now int64) bool { return it.exp > 0 && it.exp <= now }
+
+// Map a generic map with per-entry TTL, it is goroutine-safe.
+// The zero value is an empty map without default TTL, ready to use.
+type Map[K comparable, V any] struct {
+	mu    sync.RWMutex
+	items map[K]item[V]
+	ttl   time.Duration
+	// sweepAt 数量达到该值时，写入时清理过期数据
+	sweepAt int
+}
+
+// New create a map with the default TTL for Set, ttl <= 0 means never expire.
+func New[K comparable, V any](ttl time.Duration) *Map[K, V] {
+	return &Map[K, V]{ttl: ttl}
+}
+
+// Set the value of the key with the default TTL
+func (m *Map[K, V]) Set(key K, val V) { m.SetTTL(key, val, m.ttl) }
+
+// SetTTL set the value of the key with ttl, ttl <= 0 means never expire.
+func (m *Map[K, V]) SetTTL(key K, val V, ttl time.Duration) {
+	now := time.Now().UnixNano()
+	var exp int64
+	if ttl > 0 {
+		exp = now + int64(ttl)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.items == nil {
+		m.items = make(map[K]item[V])
+	}
+	m.items[key] = item[V]{val: val, exp: exp}
+
+	// 数量翻倍时清理一次，均摊 O(1)
+	if len(m.items) >= max(m.sweepAt, minSweep) {
+		m.sweep(now)
+		m.sweepAt = 2 * len(m.items)
+	}
+}
+
+// Get the value of the key, returns false if not exists or expired.
+func (m *Map[K, V]) Get(key K) (val V, ok bool) {
+	m.mu.RLock()
+	it, ok := m.items[key]
+	m.mu.RUnlock()
+
+	if !ok {
+		return val, false
+	}
+	if it.expired(time.Now().UnixNano()) {
+		m.deleteExpired(key)
+		return val, false
+	}
+	return it.val, true
+}
+
+// Has check the key exists and not expired
+func (m *Map[K, V]) Has(key K) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+// TTL get the remaining TTL of the key, 0 for never expire.
+// returns false if the key does not exist or expired.
+func (m *Map[K, V]) TTL(key K) (time.Duration, bool) {
+	m.mu.RLock()
+	it, ok := m.items[key]
+	m.mu.RUnlock()
+
+	now := time.Now().UnixNano()
+	if !ok || it.expired(now) {
+		return 0, false
+	}
+	if it.exp == 0 {
+		return 0, true
+	}
+	return time.Duration(it.exp - now), true
+}
+
+// Delete the key, returns false if not exists or expired.
+func (m *Map[K, V]) Delete(key K) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	it, ok := m.items[key]
+	if ok {
+		delete(m.items, key)
+	}
+	return ok && !it.expired(time.Now().UnixNano())
+}
+
+// deleteExpired 删除已过期的 key. 重新检查，避免删除期间新写入的数据
+func (m *Map[K, V]) deleteExpired(key K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if it, ok := m.items[key]; ok && it.expired(time.Now().UnixNano()) {
+		delete(m.items, key)
+	}
+}
+
+// Len get the number of entries, includes the expired ones not removed yet.
+func (m *Map[K, V]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.items)
+}
+
+// Range calls fn for each not expired entry, stop iteration if fn returns false.
+// The order is not specified.
+//
+// It iterates a snapshot of the entries, so fn can safely call the map methods.
+func (m *Map[K, V]) Range(fn func(key K, val V) bool) {
+	type pair struct {
+		key K
+		val V
+	}
+
+	now := time.Now().UnixNano()
+	m.mu.RLock()
+	pairs := make([]pair, 0, len(m.items))
+	for key, it := range m.items {
+		if !it.expired(now) {
+			pairs = append(pairs, pair{key: key, val: it.val})
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, p := range pairs {
+		if !fn(p.key, p.val) {
+			return
+		}
+	}
+}
+
+// Keys get the keys of the not expired entries, the order is not specified.
+func (m *Map[K, V]) Keys() []K {
+	var keys []K
+	m.Range(func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Clear remove all entries
+func (m *Map[K, V]) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items, m.sweepAt = nil, 0
+}
+
+// Cleanup remove the expired entries, returns the number of removed.
+func (m *Map[K, V]) Cleanup() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.sweep(time.Now().UnixNano())
+}
+
+// AutoCleanup start a goroutine to remove the expired entries on every interval,
+// returns a func to stop it.
+func (m *Map[K, V]) AutoCleanup(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				m.Cleanup()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// sweep 删除过期数据. 需持有锁
+func (m *Map[K, V]) sweep(now int64) (n int) {
+	for key, it := range m.items {
+		if it.expired(now) {
+			delete(m.items, key)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,97 @@
+package ttlmap_test
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gookit/ext/ttlmap"
+	"github.com/gookit/goutil/testutil/assert"
+)
+
+func TestMap(t *testing.T) {
+	m := ttlmap.New[string, int](20 * time.Millisecond)
+	m.Set("a", 1)
+	m.SetTTL("b", 2, 0)
+	m.SetTTL("c", 3, time.Minute)
+
+	val, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Eq(t, 1, val)
+	assert.True(t, m.Has("b"))
+	assert.Eq(t, 3, m.Len())
+
+	ttl, ok := m.TTL("b")
+	assert.True(t, ok)
+	assert.Eq(t, time.Duration(0), ttl)
+	ttl, ok = m.TTL("c")
+	assert.True(t, ok)
+	assert.Gt(t, ttl, 59*time.Second)
+
+	keys := m.Keys()
+	slices.Sort(keys)
+	assert.Eq(t, []string{"a", "b", "c"}, keys)
+
+	// expired
+	time.Sleep(25 * time.Millisecond)
+	_, ok = m.Get("a")
+	assert.False(t, ok)
+	_, ok = m.TTL("a")
+	assert.False(t, ok)
+	assert.Eq(t, 2, m.Len())
+
+	var n int
+	m.Range(func(key string, val int) bool {
+		n++
+		return false
+	})
+	assert.Eq(t, 1, n)
+
+	assert.True(t, m.Delete("b"))
+	assert.False(t, m.Delete("b"))
+	m.Clear()
+	assert.Eq(t, 0, m.Len())
+	_, ok = m.Get("c")
+	assert.False(t, ok)
+}
+
+func TestMap_zero(t *testing.T) {
+	var m ttlmap.Map[int, string]
+	_, ok := m.Get(1)
+	assert.False(t, ok)
+	assert.False(t, m.Delete(1))
+	assert.Eq(t, 0, m.Cleanup())
+
+	m.Set(1, "a")
+	val, ok := m.Get(1)
+	assert.True(t, ok)
+	assert.Eq(t, "a", val)
+}
+
+func TestMap_cleanup(t *testing.T) {
+	m := ttlmap.New[string, int](time.Millisecond)
+	for i := 0; i < 10; i++ {
+		m.Set(strconv.Itoa(i), i)
+	}
+	time.Sleep(2 * time.Millisecond)
+	assert.Eq(t, 10, m.Len())
+	assert.Eq(t, 10, m.Cleanup())
+
+	// the expired entries are swept on writing
+	for i := 0; i < 200; i++ {
+		m.Set(strconv.Itoa(i), i)
+		if i%50 == 0 {
+			time.Sleep(2 * time.Millisecond)
+		}
+	}
+	assert.Lt(t, m.Len(), 200)
+
+	// background cleanup
+	m.SetTTL("keep", 1, 0)
+	stop := m.AutoCleanup(5 * time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	stop()
+	stop()
+	assert.Eq(t, 1, m.Len())
+}